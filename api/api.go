@@ -0,0 +1,36 @@
+// Package api embeds the OpenAPI specification of the classification
+// server's HTTP endpoints and the JSON Schema of its request log.
+package api
+
+import (
+	_ "embed"
+	"fmt"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Spec is the raw OpenAPI 3 document (YAML)
+//
+//go:embed openapi.yaml
+var Spec []byte
+
+// LogEntrySchema is the JSON Schema (draft 2020-12) of a request log line
+//
+//go:embed schema/log-entry.schema.json
+var LogEntrySchema []byte
+
+// LogEntrySchemaID is the $id of LogEntrySchema
+const LogEntrySchemaID = "https://github.com/muliwe/go-client-classifier/api/schema/log-entry.schema.json"
+
+// Load parses and validates the embedded OpenAPI document
+func Load() (*openapi3.T, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(Spec)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	return doc, nil
+}
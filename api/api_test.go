@@ -0,0 +1,13 @@
+package api
+
+import "testing"
+
+// Tests are in tests/unit/openapi_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestAPIPackage(t *testing.T) {
+	// Verify package is testable
+	if len(Spec) == 0 {
+		t.Error("Spec should be embedded")
+	}
+}
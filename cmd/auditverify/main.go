@@ -0,0 +1,93 @@
+// Command auditverify checks a request log written in audit mode: that
+// no entry was altered, inserted or removed since it was logged, and that
+// the signed checkpoints match the chain:
+//
+//	auditverify -key audit.pub logs/requests.jsonl
+//
+// Checkpoints are read from <log>.checkpoints. Without -key only the hash
+// chain is checked, which proves consistency but not who wrote the log.
+// The exit status is 1 when verification fails.
+package main
+
+import (
+	"crypto/ed25519"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+func main() {
+	keyFile := flag.String("key", "", "PEM Ed25519 public key the checkpoints are signed with")
+	jsonOut := flag.Bool("json", false, "Print the report as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] requests.jsonl...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var pub ed25519.PublicKey
+	if *keyFile != "" {
+		var err error
+		if pub, err = logger.LoadVerifyKey(*keyFile); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	failed := false
+	for _, path := range flag.Args() {
+		report, err := verify(path, pub)
+		switch {
+		case *jsonOut:
+			out := struct {
+				Log string `json:"log"`
+				logger.AuditReport
+				Error string `json:"error,omitempty"`
+			}{Log: path, AuditReport: report}
+			if err != nil {
+				out.Error = err.Error()
+			}
+			data, _ := json.Marshal(out)
+			fmt.Println(string(data))
+		case err != nil:
+			fmt.Printf("%s: FAILED: %v\n", path, err)
+		default:
+			fmt.Printf("%s: OK: %d audited entries (head seq %d), %d signed checkpoints (last at seq %d), %d entries before audit mode\n",
+				path, report.Audited, report.Head.Seq, report.Checkpoints, report.Signed, report.Unaudited)
+		}
+		failed = failed || err != nil
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// verify checks one log and, with a key, its checkpoint file
+func verify(path string, pub ed25519.PublicKey) (logger.AuditReport, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return logger.AuditReport{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	var checkpoints io.Reader
+	if pub != nil {
+		cf, err := os.Open(logger.CheckpointPath(path))
+		if err != nil {
+			return logger.AuditReport{}, err
+		}
+		defer func() { _ = cf.Close() }()
+		checkpoints = cf
+	}
+	return logger.VerifyAudit(f, checkpoints, pub)
+}
@@ -0,0 +1,85 @@
+// Command calibrate fits a confidence calibration on a labeled dataset and
+// writes it as JSON:
+//
+//	calibrate -data data/labeled.jsonl -out calibration.json
+//	calibrate -data data/labeled.jsonl -out calibration.json -method isotonic
+//	calibrate -data data/labeled.jsonl -out calibration.json -model model.json
+//
+// The dataset is classified with the same backend and threshold the
+// server runs, so refit after changing them. Serve the calibration with
+// CALIBRATION_FILE=calibration.json, and check it on held-out data with
+// evaluate -calibration calibration.json.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/evaluate"
+	"github.com/muliwe/go-client-classifier/internal/model"
+)
+
+func main() {
+	dataFile := flag.String("data", "data/labeled.jsonl", "Labeled dataset (JSONL)")
+	out := flag.String("out", "calibration.json", "Calibration file to write")
+	method := flag.String("method", calibration.MethodPlatt, "Calibration method: platt or isotonic")
+	version := flag.String("version", time.Now().UTC().Format("2006-01-02"), "Version recorded in the calibration")
+	threshold := flag.Int("threshold", classifier.DefaultConfig().Threshold, "Classification threshold")
+	modelFile := flag.String("model", "", "Calibrate the ml backend with this model instead of the heuristic")
+	flag.Parse()
+
+	samples, err := dataset.ReadFile(*dataFile)
+	if err != nil {
+		log.Fatalf("Error reading dataset: %v", err)
+	}
+	if len(samples) == 0 {
+		log.Fatalf("Error: dataset %s is empty", *dataFile)
+	}
+
+	opts := []classifier.Option{classifier.WithThreshold(*threshold)}
+	if *modelFile != "" {
+		m, err := model.Load(*modelFile)
+		if err != nil {
+			log.Fatalf("Error loading model: %v", err)
+		}
+		opts = append(opts, classifier.WithModel(m))
+	}
+	c := classifier.New(opts...)
+
+	classified := make([]calibration.Sample, len(samples))
+	for i, s := range samples {
+		result := c.Classify(s.Fingerprint)
+		classified[i] = calibration.Sample{Confidence: result.Confidence, Correct: result.Classification == s.Label}
+	}
+	cal, err := calibration.Fit(classified, *method)
+	if err != nil {
+		log.Fatalf("Error fitting calibration: %v", err)
+	}
+	cal.Version = *version
+	cal.Backend = c.Backend()
+
+	data, err := json.MarshalIndent(cal, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding calibration: %v", err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("Error writing calibration: %v", err)
+	}
+
+	calibrated := make([]calibration.Sample, len(classified))
+	for i, s := range classified {
+		calibrated[i] = calibration.Sample{Confidence: cal.Apply(s.Confidence), Correct: s.Correct}
+	}
+	fmt.Printf("Fitted %s calibration of the %s backend on %d samples\n", cal.Method, cal.Backend, len(samples))
+	fmt.Printf("ECE %.3f -> %.3f, Brier %.3f -> %.3f\n",
+		calibration.ECE(classified, evaluate.CalibrationBins), calibration.ECE(calibrated, evaluate.CalibrationBins),
+		calibration.Brier(classified), calibration.Brier(calibrated))
+	fmt.Printf("Calibration written to %s\n", *out)
+}
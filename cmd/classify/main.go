@@ -0,0 +1,213 @@
+// Command classify classifies a single request offline and prints the
+// result with its signal breakdown, without running the server.
+//
+// The request can be described by flags, a raw HTTP request file, a HAR
+// entry, or a JSONL log line written by the server:
+//
+//	classify -ua "curl/8.0.1" -H "Accept: */*"
+//	classify -request req.txt
+//	classify -har session.har -entry 3
+//	classify -log logs/requests.jsonl -line 42
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/har"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// headerFlags collects repeated -H "Name: value" flags
+type headerFlags []string
+
+func (h *headerFlags) String() string { return strings.Join(*h, ", ") }
+
+func (h *headerFlags) Set(v string) error {
+	if !strings.Contains(v, ":") {
+		return fmt.Errorf("header must be in 'Name: value' form: %q", v)
+	}
+	*h = append(*h, v)
+	return nil
+}
+
+func main() {
+	var headers headerFlags
+	ua := flag.String("ua", "", "User-Agent header")
+	method := flag.String("method", "GET", "Request method")
+	proto := flag.String("proto", "HTTP/1.1", "Request protocol (HTTP/1.1, HTTP/2.0)")
+	path := flag.String("path", "/", "Request path")
+	flag.Var(&headers, "H", "Request header 'Name: value' (repeatable)")
+	requestFile := flag.String("request", "", "Raw HTTP request file ('-' for stdin)")
+	harFile := flag.String("har", "", "HAR file")
+	entry := flag.Int("entry", 0, "HAR entry index (0-based)")
+	logFile := flag.String("log", "", "JSONL log file written by the server")
+	line := flag.Int("line", 1, "Log line number (1-based)")
+	threshold := flag.Int("threshold", classifier.DefaultConfig().Threshold, "Classification threshold")
+	jsonOut := flag.Bool("json", false, "Print full result as JSON")
+	flag.Parse()
+
+	fp, err := loadFingerprint(*requestFile, *harFile, *entry, *logFile, *line, func() *http.Request {
+		return requestFromFlags(*method, *proto, *path, *ua, headers)
+	})
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	clf := classifier.New(classifier.Config{Threshold: *threshold})
+	result := clf.Classify(fp)
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(result); err != nil {
+			log.Fatalf("Error encoding result: %v", err)
+		}
+		return
+	}
+	printResult(os.Stdout, result)
+}
+
+// loadFingerprint builds the fingerprint from the selected input source
+func loadFingerprint(requestFile, harFile string, entry int, logFile string, line int, fromFlags func() *http.Request) (fingerprint.Fingerprint, error) {
+	collector := fingerprint.NewCollector()
+
+	switch {
+	case requestFile != "":
+		req, err := readRawRequest(requestFile)
+		if err != nil {
+			return fingerprint.Fingerprint{}, err
+		}
+		return collector.Collect(req), nil
+
+	case harFile != "":
+		req, err := readHAREntry(harFile, entry)
+		if err != nil {
+			return fingerprint.Fingerprint{}, err
+		}
+		return collector.Collect(req), nil
+
+	case logFile != "":
+		return readLogLine(logFile, line)
+
+	default:
+		return collector.Collect(fromFlags()), nil
+	}
+}
+
+// requestFromFlags builds a request from command-line flags
+func requestFromFlags(method, proto, path, ua string, headers headerFlags) *http.Request {
+	req, err := http.NewRequest(method, path, http.NoBody)
+	if err != nil {
+		log.Fatalf("Error: invalid request: %v", err)
+	}
+	req.Proto, req.ProtoMajor, req.ProtoMinor = har.NormalizeHTTPVersion(proto)
+	if ua != "" {
+		req.Header.Set("User-Agent", ua)
+	}
+	for _, h := range headers {
+		name, value, _ := strings.Cut(h, ":")
+		req.Header.Add(strings.TrimSpace(name), strings.TrimSpace(value))
+	}
+	return req
+}
+
+// readRawRequest parses a raw HTTP/1.x request from a file or stdin
+func readRawRequest(path string) (*http.Request, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		r = f
+	}
+
+	// The sniffer keeps the header names in the order and case written
+	var sniffer fingerprint.HeaderSniffer
+	req, err := http.ReadRequest(bufio.NewReader(io.TeeReader(r, &sniffer)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid raw request: %w", err)
+	}
+	return req.WithContext(context.WithValue(req.Context(), fingerprint.ContextKeyHeaderOrder, fingerprint.HeaderOrderSource(&sniffer))), nil
+}
+
+// readHAREntry loads the request of a single HAR entry
+func readHAREntry(path string, index int) (*http.Request, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	doc, err := har.Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(doc.Log.Entries) {
+		return nil, fmt.Errorf("entry %d out of range (HAR has %d entries)", index, len(doc.Log.Entries))
+	}
+	return doc.Log.Entries[index].Request.HTTPRequest()
+}
+
+// readLogLine loads the fingerprint recorded on a JSONL log line
+func readLogLine(path string, line int) (fingerprint.Fingerprint, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return fingerprint.Fingerprint{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := logger.NewReader(f)
+	for {
+		entry, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return fingerprint.Fingerprint{}, errors.New("log line out of range")
+		}
+		if err != nil {
+			return fingerprint.Fingerprint{}, err
+		}
+		if r.Line() == line {
+			return entry.Fingerprint, nil
+		}
+		if r.Line() > line {
+			return fingerprint.Fingerprint{}, fmt.Errorf("log line %d is blank", line)
+		}
+	}
+}
+
+// printResult writes a human-readable result with signal breakdown
+func printResult(w io.Writer, result fingerprint.ClassificationResult) {
+	s := result.Signals
+	fp := result.Fingerprint
+
+	fmt.Fprintf(w, "Classification:  %s (confidence %.2f)\n", result.Classification, result.Confidence)
+	fmt.Fprintf(w, "Score:           %+d (browser %d, bot %d)\n", result.Score, s.BrowserScore, s.BotScore)
+	fmt.Fprintf(w, "Reason:          %s\n", result.Reason)
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "User-Agent:      %s\n", fp.HTTP.UserAgent)
+	fmt.Fprintf(w, "HTTP:            %s %s (%d headers)\n", fp.HTTP.Version, fp.HTTP.Method, fp.HTTP.HeaderCount)
+	fmt.Fprintf(w, "JA4H:            %s\n", fp.HTTP.JA4HHash)
+	if fp.TLS.Available {
+		fmt.Fprintf(w, "TLS:             %s %s\n", fp.TLS.Version, fp.TLS.ALPN)
+		fmt.Fprintf(w, "JA3:             %s\n", fp.TLS.JA3Hash)
+		fmt.Fprintf(w, "JA4:             %s\n", fp.TLS.JA4Hash)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Breakdown:")
+	for _, part := range strings.SplitAfter(s.ScoreBreakdown.String(), "] ") {
+		fmt.Fprintf(w, "  %s\n", strings.TrimSpace(part))
+	}
+}
@@ -0,0 +1,67 @@
+//go:build cgo
+
+// Command cshared builds the classifier as a C shared library for
+// in-process use from nginx/OpenResty modules and non-Go applications:
+//
+//	go build -buildmode=c-shared -o bin/libclassifier.so ./cmd/cshared
+//
+// This produces libclassifier.so and libclassifier.h exporting:
+//
+//	char *Classify(char *requestJSON);            // RequestMetadata JSON -> ClassificationResult JSON
+//	char *ClassifyFingerprint(char *fingerprintJSON);
+//	void  ClassifierFree(char *result);
+//
+// Returned strings are allocated with malloc and must be released with
+// ClassifierFree. Errors are returned as {"error": "..."}. All functions
+// are safe to call from multiple threads.
+package main
+
+/*
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"unsafe"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/edge"
+)
+
+var engine = edge.New(classifier.DefaultConfig())
+
+// Classify classifies a request described by RequestMetadata JSON
+//
+//export Classify
+func Classify(input *C.char) *C.char {
+	return call(engine.ClassifyRequestJSON, input)
+}
+
+// ClassifyFingerprint classifies a Fingerprint JSON document
+//
+//export ClassifyFingerprint
+func ClassifyFingerprint(input *C.char) *C.char {
+	return call(engine.ClassifyFingerprintJSON, input)
+}
+
+// ClassifierFree releases a string returned by Classify or ClassifyFingerprint
+//
+//export ClassifierFree
+func ClassifierFree(result *C.char) {
+	C.free(unsafe.Pointer(result))
+}
+
+// call adapts a JSON-in/JSON-out function to the C calling convention
+func call(fn func([]byte) ([]byte, error), input *C.char) *C.char {
+	if input == nil {
+		return C.CString(`{"error":"input is NULL"}`)
+	}
+	out, err := fn([]byte(C.GoString(input)))
+	if err != nil {
+		out = edge.ErrorJSON(err)
+	}
+	return C.CString(string(out))
+}
+
+// main is required by -buildmode=c-shared but never runs
+func main() {}
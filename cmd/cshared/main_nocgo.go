@@ -0,0 +1,13 @@
+//go:build !cgo
+
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "cshared: requires cgo (CGO_ENABLED=1) and -buildmode=c-shared")
+	os.Exit(2)
+}
@@ -0,0 +1,124 @@
+// Command evaluate measures classifier accuracy on a labeled dataset and
+// prints precision/recall/F1, the confusion matrix and a per-signal
+// ablation table:
+//
+//	evaluate -data data/labeled.jsonl
+//	evaluate -data data/labeled.jsonl -threshold 2 -json
+//	evaluate -data data/labeled.jsonl -model model.json
+//	evaluate -data data/holdout.jsonl -calibration calibration.json
+//
+// Datasets are written by cmd/label, models by cmd/train and calibrations
+// by cmd/calibrate.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"text/tabwriter"
+
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/evaluate"
+	"github.com/muliwe/go-client-classifier/internal/model"
+)
+
+func main() {
+	dataFile := flag.String("data", "data/labeled.jsonl", "Labeled dataset (JSONL)")
+	threshold := flag.Int("threshold", classifier.DefaultConfig().Threshold, "Classification threshold")
+	jsonOut := flag.Bool("json", false, "Print the full report as JSON")
+	modelFile := flag.String("model", "", "Evaluate the ml backend with this model instead of the heuristic")
+	calFile := flag.String("calibration", "", "Report confidence mapped by this calibration")
+	top := flag.Int("top", 0, "Only show the N most impactful signals in the ablation table (0 for all)")
+	flag.Parse()
+
+	samples, err := dataset.ReadFile(*dataFile)
+	if err != nil {
+		log.Fatalf("Error reading dataset: %v", err)
+	}
+	if len(samples) == 0 {
+		log.Fatalf("Error: dataset %s is empty", *dataFile)
+	}
+
+	opts := []classifier.Option{classifier.WithThreshold(*threshold)}
+	if *modelFile != "" {
+		m, err := model.Load(*modelFile)
+		if err != nil {
+			log.Fatalf("Error loading model: %v", err)
+		}
+		opts = append(opts, classifier.WithModel(m))
+	}
+	if *calFile != "" {
+		cal, err := calibration.Load(*calFile)
+		if err != nil {
+			log.Fatalf("Error loading calibration: %v", err)
+		}
+		opts = append(opts, classifier.WithCalibration(cal))
+	}
+	report := evaluate.Run(samples, classifier.NewConfig(opts...))
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Error encoding report: %v", err)
+		}
+		return
+	}
+	printReport(os.Stdout, report, *top)
+}
+
+// printReport writes the report as aligned tables
+func printReport(w io.Writer, r evaluate.Report, top int) {
+	fmt.Fprintf(w, "Samples:   %d\n", r.Samples)
+	if r.Backend == classifier.BackendML {
+		fmt.Fprintf(w, "Backend:   %s\n", r.Backend)
+	} else {
+		fmt.Fprintf(w, "Threshold: %d\n", r.Threshold)
+	}
+	fmt.Fprintf(w, "Accuracy:  %.3f\n", r.Accuracy)
+	fmt.Fprintf(w, "ECE:       %.3f (Brier %.3f)\n\n", r.ECE, r.Brier)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLASS\tPRECISION\tRECALL\tF1\tSUPPORT")
+	for _, m := range r.Classes {
+		fmt.Fprintf(tw, "%s\t%.3f\t%.3f\t%.3f\t%d\n", m.Class, m.Precision, m.Recall, m.F1, m.Support)
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintln(w, "\nConfusion matrix (rows: labeled, columns: predicted)")
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	for _, pred := range evaluate.Classes {
+		fmt.Fprintf(tw, "\t%s", pred)
+	}
+	fmt.Fprintln(tw)
+	for _, actual := range evaluate.Classes {
+		fmt.Fprint(tw, actual)
+		for _, pred := range evaluate.Classes {
+			fmt.Fprintf(tw, "\t%d", r.Confusion[actual][pred])
+		}
+		fmt.Fprintln(tw)
+	}
+	_ = tw.Flush()
+
+	ablation := r.Ablation
+	if top > 0 && top < len(ablation) {
+		ablation = ablation[:top]
+	}
+	if len(ablation) == 0 {
+		return
+	}
+
+	fmt.Fprintf(w, "\nSignal ablation (baseline bot F1 %.3f)\n", r.Class(classifier.ClassificationBot).F1)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "SIGNAL\tSIDE\tWEIGHT\tFIRED\tFLIPPED\tACCURACY\tBOT F1\tΔF1")
+	for _, a := range ablation {
+		fmt.Fprintf(tw, "%s\t%s\t%+d\t%d\t%d\t%.3f\t%.3f\t%+.3f\n",
+			a.Signal, a.Side, a.Weight, a.Fired, a.Flipped, a.Accuracy, a.BotF1, a.DeltaF1)
+	}
+	_ = tw.Flush()
+}
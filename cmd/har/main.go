@@ -0,0 +1,155 @@
+// Command har reports how the classifier scores every entry of
+// browser-exported HAR files, to check that legitimate web apps (XHR,
+// fetch, service workers, beacons) are not penalized as bots:
+//
+//	har session.har
+//	har -bots -ruleset rules/example.yaml session.har
+//	har -json session.har > report.json
+//
+// A HAR recorded by a real browser should classify every entry as browser,
+// so bot verdicts are listed with the rules that caused them. The exit
+// status is 1 when any entry is classified as bot and -fail is set.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/har"
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
+)
+
+// fileReport is the JSON report of a single HAR file
+type fileReport struct {
+	File    string            `json:"file"`
+	Summary har.Summary       `json:"summary"`
+	Entries []har.EntryResult `json:"entries"`
+}
+
+func main() {
+	threshold := flag.Int("threshold", classifier.DefaultConfig().Threshold, "Classification threshold")
+	rulesetFile := flag.String("ruleset", "", "Ruleset file to apply (overrides -threshold)")
+	botsOnly := flag.Bool("bots", false, "Only list entries classified as bot")
+	jsonOut := flag.Bool("json", false, "Print the full report as JSON")
+	fail := flag.Bool("fail", false, "Exit with status 1 if any entry is classified as bot")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] file.har...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := classifier.NewConfig(classifier.WithThreshold(*threshold))
+	if *rulesetFile != "" {
+		rs, err := ruleset.LoadFile(*rulesetFile)
+		if err != nil {
+			log.Fatalf("Error loading ruleset: %v", err)
+		}
+		if issues := rs.Lint(); ruleset.HasErrors(issues) {
+			log.Fatalf("Error: ruleset %s has lint errors, run rulecheck", *rulesetFile)
+		}
+		cfg = rs.Config()
+	}
+	c := classifier.New(cfg)
+
+	var reports []fileReport
+	bots := 0
+	for _, path := range flag.Args() {
+		doc, err := readHAR(path)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+		entries, summary := har.Analyze(doc, c)
+		bots += summary.Bot
+		reports = append(reports, fileReport{File: path, Summary: summary, Entries: entries})
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(reports); err != nil {
+			log.Fatalf("Error encoding report: %v", err)
+		}
+	} else {
+		for _, r := range reports {
+			printReport(os.Stdout, r, *botsOnly)
+		}
+	}
+
+	if *fail && bots > 0 {
+		os.Exit(1)
+	}
+}
+
+// readHAR parses a HAR file
+func readHAR(path string) (*har.HAR, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return har.Parse(f)
+}
+
+// printReport writes the entry table and summary of one file
+func printReport(w io.Writer, r fileReport, botsOnly bool) {
+	s := r.Summary
+	fmt.Fprintf(w, "== %s\n", r.File)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "#\tCLASS\tSCORE\tTYPE\tMETHOD\tSTATUS\tURL\tBOT RULES")
+	for _, e := range r.Entries {
+		if botsOnly && e.Classification != classifier.ClassificationBot {
+			continue
+		}
+		class := e.Classification
+		if e.Error != "" {
+			class = "error"
+		}
+		fmt.Fprintf(tw, "%d\t%s\t%+d\t%s\t%s\t%d\t%s\t%s\n",
+			e.Index, class, e.Score, e.ResourceType, e.Method, e.Status, shorten(e.URL, 60), strings.Join(e.BotRules, " "))
+	}
+	_ = tw.Flush()
+
+	fmt.Fprintf(w, "\n%d entries: %d browser, %d bot", s.Entries, s.Browser, s.Bot)
+	if s.Errors > 0 {
+		fmt.Fprintf(w, ", %d unparseable", s.Errors)
+	}
+	fmt.Fprintln(w)
+	if s.Bot == 0 {
+		fmt.Fprintln(w)
+		return
+	}
+
+	fmt.Fprintln(w, "\nBot verdicts by resource type:")
+	for _, t := range s.Types {
+		if t.Bot > 0 {
+			fmt.Fprintf(w, "  %-12s %d/%d\n", t.ResourceType, t.Bot, t.Total)
+		}
+	}
+	fmt.Fprintln(w, "\nRules behind bot verdicts:")
+	for _, rc := range s.Rules {
+		fmt.Fprintf(w, "  %-20s %d\n", rc.Rule, rc.Count)
+	}
+	fmt.Fprintln(w)
+}
+
+// shorten truncates s to n characters with an ellipsis
+func shorten(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n-3] + "..."
+}
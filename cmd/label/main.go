@@ -0,0 +1,190 @@
+// Command label walks through sampled entries of a server request log,
+// shows a fingerprint summary for each, and records human labels into a
+// dataset file for training and evaluation:
+//
+//	label -log logs/requests.jsonl -out data/labeled.jsonl -n 50
+//
+// At each prompt enter b (browser), o (bot), s (skip) or q (quit). Any text
+// after the key is stored as a note, e.g. "o headless chrome". Labels are
+// appended as they are entered, and entries already present in the
+// dataset are skipped, so a session can be resumed.
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+func main() {
+	logFile := flag.String("log", "logs/requests.jsonl", "JSONL log file written by the server")
+	outFile := flag.String("out", "data/labeled.jsonl", "Dataset file to append labels to")
+	n := flag.Int("n", 50, "Number of entries to sample (0 for all)")
+	seed := flag.Uint64("seed", 0, "Sampling seed (0 for random)")
+	only := flag.String("only", "", "Only sample entries classified as browser or bot")
+	flag.Parse()
+
+	if *only != "" && !dataset.ValidLabel(*only) {
+		log.Fatalf("Error: -only must be %q or %q", classifier.ClassificationBrowser, classifier.ClassificationBot)
+	}
+
+	existing, err := dataset.ReadFile(*outFile)
+	if err != nil {
+		log.Fatalf("Error reading dataset: %v", err)
+	}
+
+	if *seed == 0 {
+		*seed = uint64(time.Now().UnixNano())
+	}
+	entries, err := sample(*logFile, *n, *only, dataset.Labeled(existing), rand.New(rand.NewPCG(*seed, *seed)))
+	if err != nil {
+		log.Fatalf("Error reading log: %v", err)
+	}
+	if len(entries) == 0 {
+		fmt.Println("No unlabeled entries to review")
+		return
+	}
+
+	w, err := dataset.Append(*outFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	counts, err := run(os.Stdin, os.Stdout, w, entries, *logFile)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	fmt.Printf("\nLabeled %d (browser %d, bot %d), skipped %d -> %s\n",
+		counts.browser+counts.bot, counts.browser, counts.bot, counts.skipped, *outFile)
+}
+
+// sample reservoir-samples up to n unlabeled entries from the log, in log order
+func sample(path string, n int, only string, labeled map[string]bool, rng *rand.Rand) ([]logger.LogEntry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+
+	type indexed struct {
+		line  int
+		entry logger.LogEntry
+	}
+	var picked []indexed
+	seen := 0
+
+	r := logger.NewReader(f)
+	for {
+		entry, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if labeled[entry.RequestID] || (only != "" && entry.Classification != only) {
+			continue
+		}
+		seen++
+		switch {
+		case n <= 0 || len(picked) < n:
+			picked = append(picked, indexed{r.Line(), entry})
+		default:
+			if j := rng.IntN(seen); j < n {
+				picked[j] = indexed{r.Line(), entry}
+			}
+		}
+	}
+
+	sort.Slice(picked, func(i, j int) bool { return picked[i].line < picked[j].line })
+	entries := make([]logger.LogEntry, len(picked))
+	for i, p := range picked {
+		entries[i] = p.entry
+	}
+	return entries, nil
+}
+
+// tally counts the decisions made in a session
+type tally struct {
+	browser, bot, skipped int
+}
+
+// run prompts for a label for each entry and appends it to the dataset
+func run(in io.Reader, out io.Writer, w *dataset.Writer, entries []logger.LogEntry, source string) (tally, error) {
+	var counts tally
+	scanner := bufio.NewScanner(in)
+
+	for i, entry := range entries {
+		fmt.Fprintf(out, "\n[%d/%d] %s  %s\n", i+1, len(entries), entry.Timestamp.Format(time.RFC3339), entry.RequestID)
+		printEntry(out, entry)
+
+		for {
+			fmt.Fprint(out, "Label [b]rowser / b[o]t / [s]kip / [q]uit: ")
+			if !scanner.Scan() {
+				return counts, scanner.Err()
+			}
+			key, note, _ := strings.Cut(strings.TrimSpace(scanner.Text()), " ")
+
+			var label string
+			switch strings.ToLower(key) {
+			case "b":
+				label = classifier.ClassificationBrowser
+				counts.browser++
+			case "o":
+				label = classifier.ClassificationBot
+				counts.bot++
+			case "s":
+				counts.skipped++
+			case "q":
+				return counts, nil
+			default:
+				continue
+			}
+
+			if label != "" {
+				if err := w.Write(dataset.Sample{
+					RequestID:   entry.RequestID,
+					Label:       label,
+					Fingerprint: entry.Fingerprint,
+					Predicted:   entry.Classification,
+					Source:      source,
+					Note:        strings.TrimSpace(note),
+				}); err != nil {
+					return counts, fmt.Errorf("failed to write label: %w", err)
+				}
+			}
+			break
+		}
+	}
+	return counts, nil
+}
+
+// printEntry writes the fingerprint summary shown before each prompt
+func printEntry(w io.Writer, entry logger.LogEntry) {
+	fp := entry.Fingerprint
+
+	fmt.Fprintf(w, "Predicted:       %s (confidence %.2f, score %+d)\n", entry.Classification, entry.Confidence, entry.Score)
+	fmt.Fprintf(w, "Reason:          %s\n", entry.Reason)
+	fmt.Fprintf(w, "Remote:          %s\n", entry.RemoteAddr)
+	fmt.Fprintf(w, "User-Agent:      %s\n", fp.HTTP.UserAgent)
+	fmt.Fprintf(w, "HTTP:            %s %s %s (%d headers)\n", fp.HTTP.Version, fp.HTTP.Method, fp.HTTP.Path, fp.HTTP.HeaderCount)
+	fmt.Fprintf(w, "Header order:    %s\n", strings.Join(fp.HTTP.HeaderOrder, ", "))
+	fmt.Fprintf(w, "Accept-Language: %s\n", fp.HTTP.AcceptLang)
+	if fp.TLS.Available {
+		fmt.Fprintf(w, "TLS:             %s %s (%d ciphers, %d extensions)\n", fp.TLS.Version, fp.TLS.ALPN, fp.TLS.CipherSuitesCount, fp.TLS.ExtensionsCount)
+		fmt.Fprintf(w, "JA4:             %s\n", fp.TLS.JA4Hash)
+	}
+}
@@ -0,0 +1,165 @@
+// Command logq queries the server's JSONL request logs without jq:
+//
+//	logq -since 1h -class bot logs/requests.jsonl
+//	logq -ip 203.0.113.0/24 -ua python -limit 0 logs/*.jsonl
+//	logq -since 2026-10-01 -count-by crawler logs/requests.jsonl
+//	logq -ja4 t13d1516h2 -json logs/requests.jsonl
+//
+// Without -count-by the most recent matching entries are listed; with it
+// the matches are counted by class, crawler, ip, ja3, ja4, ua, browser, os
+// or hour.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/logq"
+)
+
+// maxUALen truncates User-Agents in the entry table
+const maxUALen = 60
+
+func main() {
+	since := flag.String("since", "", "Only entries at or after this time (RFC 3339, YYYY-MM-DD or a duration like 24h)")
+	until := flag.String("until", "", "Only entries before this time (same formats as -since)")
+	class := flag.String("class", "", "Only entries classified as browser or bot")
+	ip := flag.String("ip", "", "Only entries from this client IP or CIDR prefix")
+	ja3 := flag.String("ja3", "", "Only entries with this JA3 hash")
+	ja4 := flag.String("ja4", "", "Only entries whose JA4 starts with this value")
+	ua := flag.String("ua", "", "Only entries whose User-Agent contains this text (case-insensitive)")
+	countBy := flag.String("count-by", "", "Count entries by "+strings.Join(logq.GroupFields, ", "))
+	limit := flag.Int("limit", 50, "Number of most recent entries or largest groups to show (0 for all)")
+	jsonOut := flag.Bool("json", false, "Print the result as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] requests.jsonl...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	q := logq.Query{
+		Filter: logq.Filter{
+			Class:     *class,
+			IP:        *ip,
+			JA3:       *ja3,
+			JA4:       *ja4,
+			UserAgent: *ua,
+		},
+		GroupBy: *countBy,
+		Limit:   *limit,
+	}
+	now := time.Now()
+	var err error
+	if *since != "" {
+		if q.Filter.Since, err = logq.ParseTime(*since, now); err != nil {
+			log.Fatalf("Error: -since: %v", err)
+		}
+	}
+	if *until != "" {
+		if q.Filter.Until, err = logq.ParseTime(*until, now); err != nil {
+			log.Fatalf("Error: -until: %v", err)
+		}
+	}
+
+	r, err := logq.New(q)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	for _, path := range flag.Args() {
+		if err := addLog(r, path); err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+	}
+	res := r.Result()
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(res); err != nil {
+			log.Fatalf("Error encoding result: %v", err)
+		}
+		return
+	}
+	if res.GroupBy != "" {
+		printGroups(os.Stdout, res)
+	} else {
+		printEntries(os.Stdout, res)
+	}
+}
+
+// addLog queries every entry of a log file
+func addLog(r *logq.Runner, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return r.AddLog(f)
+}
+
+// printEntries writes matching entries as a table
+func printEntries(w io.Writer, res logq.Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TIME\tCLASS\tCONF\tSCORE\tREMOTE\tJA4\tUSER-AGENT")
+	for _, e := range res.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%.2f\t%+d\t%s\t%s\t%s\n",
+			e.Timestamp.Format(time.RFC3339), e.Classification, e.Confidence, e.Score,
+			e.RemoteAddr, orDash(e.Fingerprint.TLS.JA4Hash), orDash(truncate(e.Fingerprint.HTTP.UserAgent, maxUALen)))
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%s\n", summary(res, len(res.Entries)))
+}
+
+// printGroups writes the group counts as a table
+func printGroups(w io.Writer, res logq.Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tCOUNT\tBOT\tBROWSER\tFIRST SEEN\tLAST SEEN\n", strings.ToUpper(res.GroupBy))
+	for _, g := range res.Groups {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%s\t%s\n", g.Key, g.Count, g.Bot, g.Browser,
+			g.FirstSeen.Format(time.RFC3339), g.LastSeen.Format(time.RFC3339))
+	}
+	_ = tw.Flush()
+	fmt.Fprintf(w, "\n%s\n", summary(res, len(res.Groups)))
+}
+
+// summary describes how many rows were shown out of the matches
+func summary(res logq.Result, shown int) string {
+	s := fmt.Sprintf("%d of %d entries matched", res.Matched, res.Scanned)
+	if res.GroupBy != "" {
+		return fmt.Sprintf("%s in %d groups, %d shown", s, res.TotalGroups, shown)
+	}
+	if shown < res.Matched {
+		return fmt.Sprintf("%s, %d most recent shown", s, shown)
+	}
+	return s
+}
+
+// truncate shortens s to n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-3]) + "..."
+}
+
+// orDash returns "-" for empty values
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,109 @@
+// Command pcap reconstructs client fingerprints from packet captures and
+// writes them as JSONL for offline or batch classification:
+//
+//	pcap capture.pcapng > fingerprints.jsonl
+//	pcap -classify -kind http capture.pcap
+//
+// TLS ClientHellos yield JA3/JA4 TLS fingerprints; plaintext HTTP/1.x
+// requests yield HTTP fingerprints with JA4H. Each line is a Fingerprint
+// object with the capture timestamp, record kind and client/server
+// addresses added, so it can be posted to /v1/classify/fingerprint as is.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/pcap"
+)
+
+// classified is a record with the classifier's verdict
+type classified struct {
+	pcap.Record
+	Classification string  `json:"classification"`
+	Confidence     float64 `json:"confidence"`
+	Score          int     `json:"score"`
+	Reason         string  `json:"reason"`
+}
+
+func main() {
+	output := flag.String("o", "", "Output file (default stdout)")
+	kind := flag.String("kind", "", "Only emit records of this kind: tls or http")
+	classify := flag.Bool("classify", false, "Add the classification of each record")
+	quiet := flag.Bool("q", false, "Do not print capture statistics to stderr")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] capture.pcap[ng]...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *kind != "" && *kind != pcap.KindTLS && *kind != pcap.KindHTTP {
+		log.Fatalf("Error: -kind must be %q or %q", pcap.KindTLS, pcap.KindHTTP)
+	}
+
+	out := os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		defer func() { _ = f.Close() }()
+		out = f
+	}
+	enc := json.NewEncoder(out)
+
+	var c *classifier.Classifier
+	if *classify {
+		c = classifier.New()
+	}
+
+	for _, path := range flag.Args() {
+		records, stats, err := extractFile(path)
+		if err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+		if !*quiet {
+			fmt.Fprintf(os.Stderr, "%s: %d packets, %d TCP segments, %d streams, %d ClientHellos, %d HTTP requests, %d malformed\n",
+				path, stats.Packets, stats.TCPSegments, stats.Streams, stats.ClientHellos, stats.HTTPRequests, stats.Malformed)
+		}
+
+		for _, rec := range records {
+			if *kind != "" && rec.Kind != *kind {
+				continue
+			}
+			var v any = rec
+			if c != nil {
+				result := c.Classify(rec.Fingerprint)
+				v = classified{
+					Record:         rec,
+					Classification: result.Classification,
+					Confidence:     result.Confidence,
+					Score:          result.Score,
+					Reason:         result.Reason,
+				}
+			}
+			if err := enc.Encode(v); err != nil {
+				log.Fatalf("Error writing output: %v", err)
+			}
+		}
+	}
+}
+
+// extractFile extracts the records of a single capture file
+func extractFile(path string) ([]pcap.Record, pcap.Stats, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, pcap.Stats{}, err
+	}
+	defer func() { _ = f.Close() }()
+	return pcap.Extract(f)
+}
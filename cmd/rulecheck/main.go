@@ -0,0 +1,106 @@
+// Command rulecheck lints classifier ruleset files and runs the
+// declarative test cases stored next to them:
+//
+//	rulecheck rules/example.yaml
+//	rulecheck -strict -v rules/*.yaml
+//
+// For rules/site.yaml, test cases are read from rules/site_test.yaml when
+// that file exists. The exit status is 1 when any ruleset has lint errors
+// or failing tests (or warnings with -strict).
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
+)
+
+func main() {
+	strict := flag.Bool("strict", false, "Treat lint warnings as errors")
+	verbose := flag.Bool("v", false, "Print passing test cases and score breakdowns")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] ruleset.yaml...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ok := true
+	for _, path := range flag.Args() {
+		if !check(os.Stdout, path, *strict, *verbose) {
+			ok = false
+		}
+	}
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// check lints one ruleset and runs its tests, reporting whether it passed
+func check(w io.Writer, path string, strict, verbose bool) bool {
+	fmt.Fprintf(w, "== %s\n", path)
+
+	rs, err := ruleset.LoadFile(path)
+	if err != nil {
+		fmt.Fprintf(w, "error: %v\n", err)
+		return false
+	}
+	issues := rs.Lint()
+
+	testPath := ruleset.TestFile(path)
+	cases, err := ruleset.LoadTests(testPath)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+		cases = nil
+	case err != nil:
+		fmt.Fprintf(w, "error: %v\n", err)
+		return false
+	default:
+		issues = append(issues, rs.LintTests(cases)...)
+	}
+
+	for _, issue := range issues {
+		fmt.Fprintln(w, issue)
+	}
+	failed := ruleset.HasErrors(issues) || (strict && len(issues) > 0)
+	if failed {
+		return false
+	}
+
+	if cases == nil {
+		fmt.Fprintf(w, "ok (lint only, no %s)\n", testPath)
+		return true
+	}
+
+	passed := 0
+	for _, res := range ruleset.Run(rs.Config(), cases) {
+		switch {
+		case res.Error != "":
+			fmt.Fprintf(w, "FAIL %s: %s\n", res.Name, res.Error)
+		case !res.Passed:
+			fmt.Fprintf(w, "FAIL %s: expected %s, got %s (score %+d)", res.Name, res.Expected, res.Got, res.Score)
+			if len(res.Missing) > 0 {
+				fmt.Fprintf(w, "; rules not fired: %s", strings.Join(res.Missing, ", "))
+			}
+			fmt.Fprintf(w, "\n     %s\n", res.Breakdown)
+		default:
+			passed++
+			if verbose {
+				fmt.Fprintf(w, "PASS %s (%s, score %+d)\n     %s\n", res.Name, res.Got, res.Score, res.Breakdown)
+			}
+		}
+	}
+
+	fmt.Fprintf(w, "%d/%d tests passed\n", passed, len(cases))
+	return passed == len(cases)
+}
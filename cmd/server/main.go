@@ -1,10 +1,36 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/muliwe/go-client-classifier/internal/anonymize"
+	"github.com/muliwe/go-client-classifier/internal/asn"
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/challenge"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/crawlerverify"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/events"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/geoip"
+	"github.com/muliwe/go-client-classifier/internal/honeypot"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/membudget"
+	"github.com/muliwe/go-client-classifier/internal/model"
+	"github.com/muliwe/go-client-classifier/internal/privaterelay"
+	"github.com/muliwe/go-client-classifier/internal/privatetoken"
+	"github.com/muliwe/go-client-classifier/internal/reputation"
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
 	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/internal/systemd"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
 )
 
 func main() {
@@ -15,11 +41,536 @@ func main() {
 		cfg.Addr = ":" + port
 	}
 
+	// Graceful shutdown: DRAIN_DELAY keeps serving while /health reports
+	// draining, SHUTDOWN_TIMEOUT bounds the wait for in-flight requests
+	if d := os.Getenv("DRAIN_DELAY"); d != "" {
+		delay, err := time.ParseDuration(d)
+		if err != nil {
+			log.Fatalf("Invalid DRAIN_DELAY: %v", err)
+		}
+		cfg.DrainDelay = delay
+	}
+	if t := os.Getenv("SHUTDOWN_TIMEOUT"); t != "" {
+		timeout, err := time.ParseDuration(t)
+		if err != nil {
+			log.Fatalf("Invalid SHUTDOWN_TIMEOUT: %v", err)
+		}
+		cfg.ShutdownTimeout = timeout
+	}
+
+	// Enable the gRPC classification service
+	if port := os.Getenv("GRPC_PORT"); port != "" {
+		cfg.GRPCAddr = ":" + port
+	}
+
+	// Serve sockets passed by systemd socket activation: the one named
+	// "grpc" (FileDescriptorName=grpc) serves gRPC, the other HTTP(S)
+	sockets, err := systemd.Listeners()
+	if err != nil {
+		log.Fatalf("Failed to use systemd sockets: %v", err)
+	}
+	for _, l := range sockets {
+		switch {
+		case l.Name == "grpc" && cfg.GRPCListener == nil:
+			cfg.GRPCListener = l
+		case l.Name != "grpc" && cfg.Listener == nil:
+			cfg.Listener = l.Listener // The socket itself, for TCP_FINGERPRINT
+		default:
+			log.Fatalf("Unexpected systemd socket %s: pass one HTTP socket and at most one named grpc", l.Name)
+		}
+	}
+
 	// Enable debug endpoint in development
 	if os.Getenv("DEBUG") == "true" {
 		cfg.EnableDebug = true
 	}
 
+	// Stream classifications to live tail clients (exposes client IPs and fingerprints)
+	if os.Getenv("STREAM") == "true" {
+		cfg.EnableStream = true
+	}
+
+	// Emit a Cloudflare-compatible 1-99 bot score header (e.g. BOT_SCORE_HEADER=Cf-Bot-Score)
+	if name := os.Getenv("BOT_SCORE_HEADER"); name != "" {
+		cfg.BotScoreHeader = name
+	}
+
+	// Validate log entries against the published schema (catches drift in CI/staging)
+	if os.Getenv("LOG_VALIDATE") == "true" {
+		cfg.LoggerConfig.Validate = true
+	}
+
+	// Data-minimization log profile (LOG_PROFILE=minimal): no client
+	// address, headers, User-Agent, path or cookies
+	if profile := os.Getenv("LOG_PROFILE"); profile != "" {
+		p, err := logger.ParseProfile(profile)
+		if err != nil {
+			log.Fatalf("Invalid LOG_PROFILE: %v", err)
+		}
+		cfg.LoggerConfig.Profile = p
+	}
+
+	// Tamper-evident audit log (AUDIT_LOG=true): entries are hash-chained
+	// and, with AUDIT_SIGNING_KEY (PEM Ed25519), the chain head is signed
+	// every AUDIT_CHECKPOINT_EVERY entries (default 1000) and on shutdown
+	if os.Getenv("AUDIT_LOG") == "true" {
+		cfg.LoggerConfig.Audit.Enabled = true
+		if path := os.Getenv("AUDIT_SIGNING_KEY"); path != "" {
+			key, err := logger.LoadSigningKey(path)
+			if err != nil {
+				log.Fatalf("Failed to load AUDIT_SIGNING_KEY: %v", err)
+			}
+			cfg.LoggerConfig.Audit.SigningKey = key
+		}
+		if v := os.Getenv("AUDIT_CHECKPOINT_EVERY"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n <= 0 {
+				log.Fatalf("Invalid AUDIT_CHECKPOINT_EVERY: %q", v)
+			}
+			cfg.LoggerConfig.Audit.CheckpointEvery = n
+		}
+	}
+
+	// Private Access Tokens: issuer name and its saved issuer directory
+	// (https://<issuer>/.well-known/private-token-issuer-directory)
+	if issuer, keys := os.Getenv("PRIVATE_TOKEN_ISSUER"), os.Getenv("PRIVATE_TOKEN_KEYS"); issuer != "" && keys != "" {
+		issuers, err := privatetoken.LoadDirectory(issuer, keys)
+		if err != nil {
+			log.Fatalf("Failed to load Private Access Token keys: %v", err)
+		}
+		var origins []string
+		if o := os.Getenv("PRIVATE_TOKEN_ORIGINS"); o != "" {
+			origins = strings.Split(o, ",")
+		}
+		cfg.PrivateTokens, err = privatetoken.NewVerifier(privatetoken.Config{Issuers: issuers, Origins: origins})
+		if err != nil {
+			log.Fatalf("Failed to create Private Access Token verifier: %v", err)
+		}
+	}
+
+	// Challenge tokens: clients redeeming a valid Private Access Token or
+	// solving the CAPTCHA get a signed clf_challenge cookie (CHALLENGE_TTL,
+	// default 1h) and are not challenged again while it is valid
+	if key := os.Getenv("CHALLENGE_KEY"); key != "" {
+		ccfg := challenge.Config{Key: []byte(key)}
+		if ttl := os.Getenv("CHALLENGE_TTL"); ttl != "" {
+			d, err := time.ParseDuration(ttl)
+			if err != nil {
+				log.Fatalf("Invalid CHALLENGE_TTL: %v", err)
+			}
+			ccfg.TTL = d
+		}
+		signer, err := challenge.NewSigner(ccfg)
+		if err != nil {
+			log.Fatalf("Failed to configure challenge tokens: %v", err)
+		}
+		cfg.ChallengeTokens = signer
+	}
+
+	// CAPTCHA for bots navigating to pages: CAPTCHA_PROVIDER=turnstile,
+	// hcaptcha or recaptcha with its site key and secret; solved sessions are remembered
+	// for CAPTCHA_TTL (default 24h)
+	if provider := os.Getenv("CAPTCHA_PROVIDER"); provider != "" {
+		v, err := captcha.New(captcha.Config{
+			Provider: captcha.Provider(provider),
+			SiteKey:  os.Getenv("CAPTCHA_SITE_KEY"),
+			Secret:   os.Getenv("CAPTCHA_SECRET"),
+		})
+		if err != nil {
+			log.Fatalf("Failed to configure CAPTCHA: %v", err)
+		}
+		cfg.Captcha = v
+		if ttl := os.Getenv("CAPTCHA_TTL"); ttl != "" {
+			if cfg.CaptchaStore.TTL, err = time.ParseDuration(ttl); err != nil {
+				log.Fatalf("Invalid CAPTCHA_TTL: %v", err)
+			}
+		}
+	}
+
+	// Known bot and browser JA3/JA4 fingerprints (JSONL, see
+	// internal/fingerprint/known), added to the built-in ones and reloaded
+	// on SIGHUP
+	cfg.FingerprintDB = os.Getenv("FINGERPRINT_DB")
+
+	// iCloud Private Relay egress ranges: a file saved from
+	// privaterelay.RangesURL, an http(s) URL, or "apple" for RangesURL
+	if path := os.Getenv("PRIVATE_RELAY_RANGES"); path != "" {
+		var ranges *privaterelay.Ranges
+		var err error
+		if path == "apple" {
+			path = privaterelay.RangesURL
+		}
+		if strings.HasPrefix(path, "http://") || strings.HasPrefix(path, "https://") {
+			ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+			ranges, err = privaterelay.Fetch(ctx, path)
+			cancel()
+		} else {
+			ranges, err = privaterelay.Load(path)
+		}
+		if err != nil {
+			log.Fatalf("Failed to load Private Relay ranges: %v", err)
+		}
+		cfg.PrivateRelay = ranges
+	}
+
+	// Country of client addresses for the geo/language signals, from a
+	// country CSV (first,last,country or network,country rows)
+	if path := os.Getenv("GEOIP_DB"); path != "" {
+		db, err := geoip.Load(path)
+		if err != nil {
+			log.Fatalf("Failed to load GeoIP database: %v", err)
+		}
+		cfg.GeoIP = db
+	}
+
+	// Autonomous system of client addresses for the datacenter signal, from
+	// an IP-to-ASN CSV; HOSTING_ASNS replaces the built-in hosting
+	// providers and is reloaded on SIGHUP
+	if path := os.Getenv("ASN_DB"); path != "" {
+		db, err := asn.Load(path)
+		if err != nil {
+			log.Fatalf("Failed to load ASN database: %v", err)
+		}
+		cfg.ASN = db
+		cfg.HostingASNs = os.Getenv("HOSTING_ASNS")
+	}
+
+	// VPN, proxy and Tor lists for the anonymized network signal:
+	// REPUTATION_LISTS=vpn=vpn.txt,tor=https://.../exits.txt, one prefix or
+	// address per line
+	if lists := os.Getenv("REPUTATION_LISTS"); lists != "" {
+		provider := reputation.NewLists()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		for _, entry := range strings.Split(lists, ",") {
+			category, src, ok := strings.Cut(strings.TrimSpace(entry), "=")
+			if !ok {
+				log.Fatalf("Invalid REPUTATION_LISTS entry %q, want category=path or URL", entry)
+			}
+			if err := provider.Load(ctx, category, src); err != nil {
+				log.Fatalf("Failed to load reputation list: %v", err)
+			}
+		}
+		cancel()
+		cfg.Reputation = provider
+	}
+
+	// Verify crawler User-Agents: VERIFY_CRAWLERS=true checks reverse DNS,
+	// CRAWLER_RANGES=GPTBot=gptbot.json,... the operators' published ranges
+	if os.Getenv("VERIFY_CRAWLERS") == "true" {
+		verifier := crawlerverify.New(nil)
+		if r := os.Getenv("CRAWLER_RANGES"); r != "" {
+			for _, entry := range strings.Split(r, ",") {
+				name, path, ok := strings.Cut(strings.TrimSpace(entry), "=")
+				if !ok {
+					log.Fatalf("Invalid CRAWLER_RANGES entry %q, want crawler=path", entry)
+				}
+				prefixes, err := crawlerverify.LoadRanges(path)
+				if err != nil {
+					log.Fatalf("Failed to load crawler ranges: %v", err)
+				}
+				if err := verifier.SetRanges(name, prefixes); err != nil {
+					log.Fatalf("Invalid CRAWLER_RANGES: %v", err)
+				}
+			}
+		}
+		cfg.CrawlerVerifier = verifier
+	}
+
+	// Ruleset for the default classifier: pattern lists, weights, threshold
+	// and custom scoring rules, checked like rulecheck does
+	if path := os.Getenv("RULESET"); path != "" {
+		rs, err := ruleset.LoadFile(path)
+		if err != nil {
+			log.Fatalf("Failed to load ruleset: %v", err)
+		}
+		if issues := rs.Lint(); ruleset.HasErrors(issues) {
+			for _, issue := range issues {
+				log.Print(issue)
+			}
+			log.Fatalf("Ruleset %s has lint errors", path)
+		}
+		cfg.ClassifierCfg = classifier.NewConfig(cfg.ClassifierCfg,
+			classifier.WithThreshold(rs.Policy.Threshold), classifier.WithRules(rs.Rules()))
+		cfg.RulesetVersion = rs.Version
+	}
+
+	// Rule weight overrides for this deployment (WEIGHTS=sec-fetch=4,http1.1=0),
+	// applied over the ruleset's and over tenant rulesets
+	if w := os.Getenv("WEIGHTS"); w != "" {
+		weights, err := classifier.ParseWeights(w)
+		if err != nil {
+			log.Fatalf("Invalid WEIGHTS: %v", err)
+		}
+		rules := fingerprint.DefaultRules()
+		if cfg.ClassifierCfg.Rules != nil {
+			rules = *cfg.ClassifierCfg.Rules
+		}
+		for name := range weights {
+			if _, ok := rules.Lookup(name); !ok {
+				log.Fatalf("Invalid WEIGHTS: unknown rule %q", name)
+			}
+		}
+		cfg.ClassifierCfg = classifier.NewConfig(cfg.ClassifierCfg, classifier.WithWeights(weights))
+	}
+
+	// Reuse verdicts of identical clients (same JA3/JA4, JA4H and User-Agent)
+	// for VERDICT_CACHE_TTL: VERDICT_CACHE=10000 caches up to 10000 of them
+	if n := os.Getenv("VERDICT_CACHE"); n != "" {
+		size, err := strconv.Atoi(n)
+		if err != nil || size < 0 {
+			log.Fatalf("Invalid VERDICT_CACHE %q, want a number of entries", n)
+		}
+		var ttl time.Duration
+		if t := os.Getenv("VERDICT_CACHE_TTL"); t != "" {
+			if ttl, err = time.ParseDuration(t); err != nil {
+				log.Fatalf("Invalid VERDICT_CACHE_TTL: %v", err)
+			}
+		}
+		cfg.ClassifierCfg = classifier.NewConfig(cfg.ClassifierCfg, classifier.WithVerdictCache(size, ttl))
+	}
+
+	// Scoring backend: CLASSIFIER_BACKEND=ml decides with the model in
+	// MODEL_FILE (written by cmd/train) instead of the rule threshold, and
+	// falls back to the heuristic without one
+	backend := os.Getenv("CLASSIFIER_BACKEND")
+	if !classifier.ValidBackend(backend) {
+		log.Fatalf("Invalid CLASSIFIER_BACKEND %q, want %s or %s", backend, classifier.BackendHeuristic, classifier.BackendML)
+	}
+	if backend == classifier.BackendML {
+		if path := os.Getenv("MODEL_FILE"); path != "" {
+			m, err := model.Load(path)
+			if err != nil {
+				log.Fatalf("Failed to load model: %v", err)
+			}
+			cfg.ClassifierCfg = classifier.NewConfig(cfg.ClassifierCfg, classifier.WithModel(m))
+			log.Printf("ML backend: model %s (threshold %.2f)", m.Version, m.Threshold)
+		} else {
+			log.Printf("CLASSIFIER_BACKEND=ml without MODEL_FILE, using the heuristic backend")
+		}
+	}
+
+	// Confidence calibration: CALIBRATION_FILE (written by cmd/calibrate)
+	// maps raw confidence to the precision observed on labeled data. It
+	// must have been fitted with the backend in use.
+	if path := os.Getenv("CALIBRATION_FILE"); path != "" {
+		cal, err := calibration.Load(path)
+		if err != nil {
+			log.Fatalf("Failed to load calibration: %v", err)
+		}
+		backend := classifier.BackendHeuristic
+		if cfg.ClassifierCfg.Backend == classifier.BackendML && cfg.ClassifierCfg.Model != nil {
+			backend = classifier.BackendML
+		}
+		if cal.Backend != "" && cal.Backend != backend {
+			log.Fatalf("CALIBRATION_FILE was fitted for the %s backend, but the %s backend is in use", cal.Backend, backend)
+		}
+		cfg.ClassifierCfg = classifier.NewConfig(cfg.ClassifierCfg, classifier.WithCalibration(cal))
+		log.Printf("Confidence calibration: %s %s", cal.Method, cal.Version)
+	}
+
+	// Enrichment time budgets protecting the latency target: ENRICH_TIMEOUT
+	// bounds all lookups of a request, ENRICH_BUDGETS single ones
+	// (private-relay=1ms,challenge=500us); lookups out of time are skipped
+	// and the result is marked partial
+	if timeout := os.Getenv("ENRICH_TIMEOUT"); timeout != "" {
+		d, err := time.ParseDuration(timeout)
+		if err != nil {
+			log.Fatalf("Invalid ENRICH_TIMEOUT: %v", err)
+		}
+		cfg.ClassifierCfg.EnrichmentTimeout = d
+	}
+	if b := os.Getenv("ENRICH_BUDGETS"); b != "" {
+		budgets, err := classifier.ParseEnrichmentBudgets(b)
+		if err != nil {
+			log.Fatalf("Invalid ENRICH_BUDGETS: %v", err)
+		}
+		cfg.ClassifierCfg.EnrichmentBudgets = budgets
+	}
+
+	// Anonymize client IPs before logging: IP_ANONYMIZE=truncate (/24, /48),
+	// hmac (keyed with IP_HMAC_KEY) or truncate,hmac
+	if mode := os.Getenv("IP_ANONYMIZE"); mode != "" {
+		var acfg anonymize.Config
+		for _, m := range strings.Split(mode, ",") {
+			switch strings.TrimSpace(m) {
+			case "truncate":
+				acfg.Truncate = true
+			case "hmac":
+				acfg.HMACKey = []byte(os.Getenv("IP_HMAC_KEY"))
+				if len(acfg.HMACKey) == 0 {
+					log.Fatalf("IP_ANONYMIZE=hmac requires IP_HMAC_KEY")
+				}
+			default:
+				log.Fatalf("Unknown IP_ANONYMIZE mode %q (use truncate, hmac or truncate,hmac)", m)
+			}
+		}
+		anon, err := anonymize.New(acfg)
+		if err != nil {
+			log.Fatalf("Failed to configure IP anonymization: %v", err)
+		}
+		cfg.IPAnonymizer = anon
+	}
+
+	// Aggregate per-client state by subnet: IP_KEY_PREFIX_V4 (default 32)
+	// and IP_KEY_PREFIX_V6 (default 64)
+	for env, bits := range map[string]*int{
+		"IP_KEY_PREFIX_V4": &cfg.IPKeys.IPv4Prefix,
+		"IP_KEY_PREFIX_V6": &cfg.IPKeys.IPv6Prefix,
+	} {
+		if v := os.Getenv(env); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				log.Fatalf("Invalid %s: %v", env, err)
+			}
+			*bits = n
+		}
+	}
+
+	// Enforce the Crawl-delay of a robots.txt (also served at /robots.txt),
+	// with optional per-crawler overrides (CRAWL_DELAYS=gptbot=10s,ccbot=5s)
+	if path := os.Getenv("ROBOTS_TXT"); path != "" {
+		var overrides crawldelay.Delays
+		if o := os.Getenv("CRAWL_DELAYS"); o != "" {
+			var err error
+			if overrides, err = crawldelay.ParseOverrides(o); err != nil {
+				log.Fatalf("Invalid CRAWL_DELAYS: %v", err)
+			}
+		}
+		patterns := fingerprint.DefaultRules().AICrawlerPatterns
+		if r := cfg.ClassifierCfg.Rules; r != nil && r.AICrawlerPatterns != nil {
+			patterns = r.AICrawlerPatterns
+		}
+		pacer, err := crawldelay.Load(path, overrides, patterns)
+		if err != nil {
+			log.Fatalf("Failed to load robots.txt: %v", err)
+		}
+		cfg.CrawlDelay = pacer
+
+		// Echo the robots.txt Content-Signal policies as response headers
+		cfg.AIPolicyHeaders = os.Getenv("AI_POLICY_HEADERS") == "true"
+	}
+
+	// Honeypot traps, disallowed at /robots.txt; clients requesting one are
+	// classified bot on later requests (HONEYPOT_PATHS=/old-admin/,/feed-all)
+	if paths := os.Getenv("HONEYPOT_PATHS"); paths != "" {
+		traps, err := honeypot.New(strings.Split(paths, ","))
+		if err != nil {
+			log.Fatalf("Invalid HONEYPOT_PATHS: %v", err)
+		}
+		cfg.Honeypot = traps
+	}
+
+	// Remember bot verdicts per address and JA4 with exponential decay
+	// (half-life REPEAT_OFFENDER_HALF_LIFE, default 6h), scoring clients
+	// caught repeatedly as repeat offenders on their return
+	if os.Getenv("REPEAT_OFFENDERS") == "true" {
+		cfg.RepeatOffenders = true
+		if hl := os.Getenv("REPEAT_OFFENDER_HALF_LIFE"); hl != "" {
+			d, err := time.ParseDuration(hl)
+			if err != nil {
+				log.Fatalf("Invalid REPEAT_OFFENDER_HALF_LIFE: %v", err)
+			}
+			cfg.OffenderCfg.HalfLife = d
+		}
+	}
+
+	// Capture sampled full fingerprints for dataset building; toggle at
+	// runtime via /v1/admin/capture with ADMIN_TOKEN
+	if path := os.Getenv("CAPTURE_FILE"); path != "" {
+		cfg.Capture.Path = path
+		cfg.Capture.Enabled = os.Getenv("CAPTURE") == "true"
+		cfg.Capture.Raw = os.Getenv("CAPTURE_RAW") == "true"
+		cfg.Capture.SampleRate = 0.01
+		if rate := os.Getenv("CAPTURE_RATE"); rate != "" {
+			r, err := strconv.ParseFloat(rate, 64)
+			if err != nil {
+				log.Fatalf("Invalid CAPTURE_RATE: %v", err)
+			}
+			cfg.Capture.SampleRate = r
+		}
+	}
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+
+	// Operator corrections posted to /v1/feedback (with ADMIN_TOKEN) are
+	// appended to FEEDBACK_FILE as labeled samples for cmd/train and
+	// cmd/evaluate; FEEDBACK_ADJUST=true also lets them lower the weights
+	// of the rules behind a misclassification
+	if path := os.Getenv("FEEDBACK_FILE"); path != "" {
+		w, err := dataset.Append(path)
+		if err != nil {
+			log.Fatalf("Failed to open feedback file: %v", err)
+		}
+		defer func() { _ = w.Close() }()
+		cfg.ClassifierCfg.Feedback = w
+	}
+	cfg.ClassifierCfg.FeedbackAdjust = os.Getenv("FEEDBACK_ADJUST") == "true"
+
+	// Tenants with their own API keys, hosts, rulesets and rate limits
+	if path := os.Getenv("TENANTS"); path != "" {
+		reg, err := tenant.Load(path, cfg.ClassifierCfg)
+		if err != nil {
+			log.Fatalf("Failed to load tenants: %v", err)
+		}
+		cfg.Tenants = reg
+	}
+
+	// Bound stateful stores by a memory budget: MEMORY_BUDGET=auto (a quarter
+	// of GOMEMLIMIT) or a size such as 64MiB; stores shrink near GOMEMLIMIT
+	if budget := os.Getenv("MEMORY_BUDGET"); budget != "" {
+		var mcfg membudget.Config
+		if budget != "auto" {
+			n, err := membudget.ParseBytes(budget)
+			if err != nil {
+				log.Fatalf("Invalid MEMORY_BUDGET: %v", err)
+			}
+			mcfg.Bytes = n
+		}
+		b, err := membudget.New(mcfg)
+		if err != nil {
+			log.Fatalf("Failed to configure memory budget: %v", err)
+		}
+		cfg.MemoryBudget = b
+	}
+
+	// Publish classified, blocked and challenged events to NATS
+	// (EVENTS_NATS_URL=nats://host:4222) and/or a Kafka REST Proxy
+	// (EVENTS_KAFKA_REST_URL=http://host:8082)
+	var sinks []events.Sink
+	if u := os.Getenv("EVENTS_NATS_URL"); u != "" {
+		subject := "classifier.events"
+		if s := os.Getenv("EVENTS_NATS_SUBJECT"); s != "" {
+			subject = s
+		}
+		n, err := events.NewNATS(u, subject)
+		if err != nil {
+			log.Fatalf("Failed to configure NATS events: %v", err)
+		}
+		sinks = append(sinks, n)
+	}
+	if u := os.Getenv("EVENTS_KAFKA_REST_URL"); u != "" {
+		topic := "classifier-events"
+		if t := os.Getenv("EVENTS_KAFKA_TOPIC"); t != "" {
+			topic = t
+		}
+		k, err := events.NewKafkaREST(u, topic)
+		if err != nil {
+			log.Fatalf("Failed to configure Kafka events: %v", err)
+		}
+		sinks = append(sinks, k)
+	}
+	if len(sinks) > 0 {
+		cfg.Events = events.NewBus()
+		for _, s := range sinks {
+			cfg.Events.AddSink(s, events.DefaultBuffer)
+		}
+	}
+
+	// JA4T from the SYN the kernel saves for each connection (Linux)
+	cfg.TCPFingerprint = os.Getenv("TCP_FINGERPRINT") == "true"
+
+	// HTTP/3 on the same port over UDP, with QUIC fingerprinting (needs TLS)
+	cfg.HTTP3 = os.Getenv("HTTP3") == "true"
+
 	// TLS configuration from environment
 	tlsCert := os.Getenv("TLS_CERT")
 	tlsKey := os.Getenv("TLS_KEY")
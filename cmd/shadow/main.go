@@ -0,0 +1,177 @@
+// Command shadow replays request logs through two classifier configs and
+// reports the requests whose classification flips, with the rules that
+// changed for each, to make ruleset reviews concrete:
+//
+//	shadow -candidate rules/new.yaml logs/requests.jsonl
+//	shadow -base rules/current.yaml -candidate rules/new.yaml -limit 50 logs/*.jsonl
+//	shadow -candidate rules/new.yaml -json logs/requests.jsonl > diff.json
+//
+// An empty -base or -candidate uses the built-in rules. The exit status
+// is 1 when any request flips and -fail is set.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
+	"github.com/muliwe/go-client-classifier/internal/shadow"
+)
+
+func main() {
+	baseFile := flag.String("base", "", "Ruleset of the current config (default: built-in rules)")
+	candidateFile := flag.String("candidate", "", "Ruleset of the proposed config (default: built-in rules)")
+	limit := flag.Int("limit", 20, "Number of flipped requests to list (0 for all)")
+	jsonOut := flag.Bool("json", false, "Print the full report as JSON")
+	fail := flag.Bool("fail", false, "Exit with status 1 if any request flips")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] requests.jsonl...\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	base, err := loadConfig(*baseFile)
+	if err != nil {
+		log.Fatalf("Error loading base ruleset: %v", err)
+	}
+	candidate, err := loadConfig(*candidateFile)
+	if err != nil {
+		log.Fatalf("Error loading candidate ruleset: %v", err)
+	}
+
+	c := shadow.New(base, candidate)
+	for _, path := range flag.Args() {
+		if err := addLog(c, path); err != nil {
+			log.Fatalf("Error reading %s: %v", path, err)
+		}
+	}
+	report := c.Report()
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			log.Fatalf("Error encoding report: %v", err)
+		}
+	} else {
+		printReport(os.Stdout, report, label(*baseFile), label(*candidateFile), *limit)
+	}
+
+	if *fail && report.Flipped > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadConfig returns the classifier config of a ruleset file, or the
+// default config for an empty path
+func loadConfig(path string) (classifier.Config, error) {
+	if path == "" {
+		return classifier.DefaultConfig(), nil
+	}
+	rs, err := ruleset.LoadFile(path)
+	if err != nil {
+		return classifier.Config{}, err
+	}
+	if issues := rs.Lint(); ruleset.HasErrors(issues) {
+		return classifier.Config{}, fmt.Errorf("ruleset %s has lint errors, run rulecheck", path)
+	}
+	return rs.Config(), nil
+}
+
+// label names a config in the text report
+func label(path string) string {
+	if path == "" {
+		return "built-in"
+	}
+	return path
+}
+
+// addLog compares every entry of a log file
+func addLog(c *shadow.Comparer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	return c.AddLog(path, f)
+}
+
+// printReport writes the summary, the rule impact table and the flipped
+// requests
+func printReport(w io.Writer, r shadow.Report, base, candidate string, limit int) {
+	fmt.Fprintf(w, "Base:          %s (threshold %d)\n", base, r.BaseThreshold)
+	fmt.Fprintf(w, "Candidate:     %s (threshold %d)\n", candidate, r.CandidateThreshold)
+	fmt.Fprintf(w, "Requests:      %d\n", r.Requests)
+	pct := 0.0
+	if r.Requests > 0 {
+		pct = float64(r.Flipped) * 100 / float64(r.Requests)
+	}
+	fmt.Fprintf(w, "Flipped:       %d (%.2f%%): %d browser -> bot, %d bot -> browser\n", r.Flipped, pct, r.ToBot, r.ToBrowser)
+	fmt.Fprintf(w, "Score changed: %d without flipping\n", r.ScoreChanged)
+
+	if len(r.Rules) > 0 {
+		fmt.Fprintln(w, "\nRules behind the flips")
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "RULE\tSIDE\tCHANGE\tWEIGHT\tFLIPS\tTO BOT\tTO BROWSER")
+		for _, imp := range r.Rules {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%d\t%d\t%d\n", imp.Rule, imp.Side, imp.Change,
+				weightChange(imp.Change, imp.BaseWeight, imp.CandidateWeight), imp.Flips, imp.ToBot, imp.ToBrowser)
+		}
+		_ = tw.Flush()
+	}
+
+	if len(r.Flips) == 0 {
+		return
+	}
+	flips := r.Flips
+	if limit > 0 && limit < len(flips) {
+		flips = flips[:limit]
+		fmt.Fprintf(w, "\nFlipped requests (first %d of %d)\n", limit, len(r.Flips))
+	} else {
+		fmt.Fprintln(w, "\nFlipped requests")
+	}
+	for _, f := range flips {
+		id := f.RequestID
+		if id == "" {
+			id = "-"
+		}
+		fmt.Fprintf(w, "%s:%d %s: %s -> %s (score %+d -> %+d)\n", f.File, f.Line, id, f.From, f.To, f.BaseScore, f.CandidateScore)
+		fmt.Fprintf(w, "  user-agent: %s\n", f.UserAgent)
+		if f.ThresholdOnly {
+			fmt.Fprintln(w, "  changes:    threshold only")
+		} else if len(f.Changes) > 0 {
+			parts := make([]string, len(f.Changes))
+			for i, ch := range f.Changes {
+				parts[i] = fmt.Sprintf("%s %s %s", ch.Change, ch.Rule, weightChange(ch.Change, ch.BaseWeight, ch.CandidateWeight))
+			}
+			fmt.Fprintf(w, "  changes:    %s\n", strings.Join(parts, ", "))
+		}
+		fmt.Fprintf(w, "  base:       %s\n", f.BaseBreakdown)
+		fmt.Fprintf(w, "  candidate:  %s\n", f.CandidateBreakdown)
+	}
+}
+
+// weightChange formats the points of a rule change
+func weightChange(change string, base, candidate int) string {
+	switch change {
+	case shadow.ChangeAdded:
+		return fmt.Sprintf("(%+d)", candidate)
+	case shadow.ChangeRemoved:
+		return fmt.Sprintf("(%+d)", base)
+	default:
+		return fmt.Sprintf("(%+d -> %+d)", base, candidate)
+	}
+}
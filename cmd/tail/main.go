@@ -0,0 +1,326 @@
+// Command tail follows classifications live, from the server's event
+// stream or from its JSONL request log, and prints the ones matching the
+// filters for on-call investigation:
+//
+//	tail -class bot -min-confidence 0.9 -crawler gptbot https://localhost:8443/v1/stream
+//	tail -class bot logs/requests.jsonl
+//	tail -all -v logs/requests.jsonl
+//
+// The stream requires the server to run with STREAM=true and is
+// reconnected when it drops. A log file is followed like tail -f, starting
+// at its end unless -all is set. Output is colored when writing to a
+// terminal.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// pollInterval is how often a followed log file is checked for new lines
+const pollInterval = 250 * time.Millisecond
+
+// reconnectDelay is the pause before reconnecting a dropped stream
+const reconnectDelay = 2 * time.Second
+
+// ANSI color codes
+const (
+	colorReset = "\033[0m"
+	colorRed   = "\033[31m"
+	colorGreen = "\033[32m"
+	colorDim   = "\033[2m"
+)
+
+// filter selects the entries to print
+type filter struct {
+	class         string
+	minConfidence float64
+	crawler       string // lowercase User-Agent substring
+}
+
+// match reports whether an entry passes the filter
+func (f filter) match(e logger.LogEntry) bool {
+	if f.class != "" && e.Classification != f.class {
+		return false
+	}
+	if e.Confidence < f.minConfidence {
+		return false
+	}
+	if f.crawler != "" && !strings.Contains(strings.ToLower(e.Fingerprint.HTTP.UserAgent), f.crawler) {
+		return false
+	}
+	return true
+}
+
+// printer formats matching entries
+type printer struct {
+	w       io.Writer
+	color   bool
+	verbose bool
+}
+
+func main() {
+	class := flag.String("class", "", "Only show entries classified as browser or bot")
+	minConfidence := flag.Float64("min-confidence", 0, "Only show entries with at least this confidence")
+	crawler := flag.String("crawler", "", "Only show entries whose User-Agent contains this text (case-insensitive)")
+	all := flag.Bool("all", false, "Print a log file from its beginning before following it")
+	verbose := flag.Bool("v", false, "Also print the reason and score breakdown")
+	noColor := flag.Bool("no-color", false, "Disable colored output")
+	insecure := flag.Bool("insecure", false, "Skip TLS certificate verification of the stream")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] stream-url|requests.jsonl\n", filepath.Base(os.Args[0]))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *class != "" && *class != classifier.ClassificationBrowser && *class != classifier.ClassificationBot {
+		log.Fatalf("Error: -class must be %q or %q", classifier.ClassificationBrowser, classifier.ClassificationBot)
+	}
+
+	f := filter{class: *class, minConfidence: *minConfidence, crawler: strings.ToLower(*crawler)}
+	p := printer{w: os.Stdout, color: !*noColor && useColor(os.Stdout), verbose: *verbose}
+	emit := func(e logger.LogEntry) {
+		if f.match(e) {
+			p.print(e)
+		}
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	source := flag.Arg(0)
+	var err error
+	if strings.HasPrefix(source, "http://") || strings.HasPrefix(source, "https://") {
+		err = followStream(ctx, source, *insecure, emit)
+	} else {
+		err = followFile(ctx, source, *all, emit)
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		log.Fatalf("Error: %v", err)
+	}
+}
+
+// useColor reports whether f is a terminal and NO_COLOR is unset
+func useColor(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// followStream prints events of the server stream, reconnecting until
+// ctx is canceled
+func followStream(ctx context.Context, url string, insecure bool, emit func(logger.LogEntry)) error {
+	client := &http.Client{}
+	if insecure {
+		client.Transport = &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}} //nolint:gosec
+	}
+	for {
+		err := readStream(ctx, client, url, emit)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var fatal fatalError
+		if errors.As(err, &fatal) {
+			return err
+		}
+		log.Printf("Stream disconnected: %v, reconnecting in %s", err, reconnectDelay)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(reconnectDelay):
+		}
+	}
+}
+
+// fatalError is a stream error that reconnecting cannot fix
+type fatalError struct{ error }
+
+// readStream reads Server-Sent Events until the connection ends
+func readStream(ctx context.Context, client *http.Client, url string, emit func(logger.LogEntry)) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fatalError{err}
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return fatalError{fmt.Errorf("%s: 404 Not Found, is the server running with STREAM=true?", url)}
+	case resp.StatusCode != http.StatusOK:
+		return fmt.Errorf("%s: %s", url, resp.Status)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	var data bytes.Buffer
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		switch {
+		case len(line) == 0:
+			if data.Len() > 0 {
+				decode(data.Bytes(), emit)
+				data.Reset()
+			}
+		case bytes.HasPrefix(line, []byte("data:")):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.Write(bytes.TrimPrefix(bytes.TrimPrefix(line, []byte("data:")), []byte(" ")))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+	return io.ErrUnexpectedEOF
+}
+
+// followFile prints entries appended to a log file until ctx is canceled.
+// A truncated or replaced file is reopened from its beginning.
+func followFile(ctx context.Context, path string, fromStart bool, emit func(logger.LogEntry)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+
+	var offset int64
+	if !fromStart {
+		if offset, err = f.Seek(0, io.SeekEnd); err != nil {
+			return err
+		}
+	}
+	r := bufio.NewReader(f)
+	var partial []byte
+
+	for {
+		line, err := r.ReadBytes('\n')
+		offset += int64(len(line))
+		if err == nil {
+			if len(partial) > 0 {
+				line = append(partial, line...)
+				partial = nil
+			}
+			decode(bytes.TrimSpace(line), emit)
+			continue
+		}
+		if !errors.Is(err, io.EOF) {
+			return err
+		}
+		// Keep an incomplete last line until the writer finishes it
+		partial = append(partial, line...)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pollInterval):
+		}
+
+		reopen, err := replaced(f, path, offset)
+		if err != nil {
+			return err
+		}
+		if reopen {
+			nf, err := os.Open(path)
+			if err != nil {
+				continue // Rotation in progress
+			}
+			_ = f.Close()
+			f, offset, partial = nf, 0, nil
+		}
+		r.Reset(f)
+	}
+}
+
+// replaced reports whether the file at path was truncated below offset or
+// replaced by another file since f was opened
+func replaced(f *os.File, path string, offset int64) (bool, error) {
+	cur, err := f.Stat()
+	if err != nil {
+		return false, err
+	}
+	if cur.Size() < offset {
+		return true, nil
+	}
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false, nil // Rotation in progress
+	}
+	return !os.SameFile(cur, fi), nil
+}
+
+// decode emits a JSON log entry, reporting malformed ones
+func decode(data []byte, emit func(logger.LogEntry)) {
+	if len(data) == 0 {
+		return
+	}
+	var e logger.LogEntry
+	if err := json.Unmarshal(data, &e); err != nil {
+		log.Printf("Skipping invalid entry: %v", err)
+		return
+	}
+	emit(e)
+}
+
+// print writes one line per entry, plus the reason and breakdown when
+// verbose
+func (p printer) print(e logger.LogEntry) {
+	class := strings.ToUpper(e.Classification)
+	if p.color {
+		switch e.Classification {
+		case classifier.ClassificationBot:
+			class = colorRed + fmt.Sprintf("%-7s", class) + colorReset
+		case classifier.ClassificationBrowser:
+			class = colorGreen + fmt.Sprintf("%-7s", class) + colorReset
+		}
+	} else {
+		class = fmt.Sprintf("%-7s", class)
+	}
+
+	ua := e.Fingerprint.HTTP.UserAgent
+	if ua == "" {
+		ua = "(no user-agent)"
+	}
+	fmt.Fprintf(p.w, "%s %s %.2f %+3d %-21s %-8s %s\n",
+		e.Timestamp.Local().Format("15:04:05.000"), class, e.Confidence, e.Score,
+		e.RemoteAddr, e.Fingerprint.HTTP.Version, ua)
+
+	if !p.verbose {
+		return
+	}
+	details := fmt.Sprintf("  id %s, %s\n  %s\n", e.RequestID, e.Reason, e.Signals.ScoreBreakdown.String())
+	if e.Fingerprint.TLS.JA4Hash != "" {
+		details += fmt.Sprintf("  ja4 %s\n", e.Fingerprint.TLS.JA4Hash)
+	}
+	if p.color {
+		details = colorDim + details + colorReset
+	}
+	fmt.Fprint(p.w, details)
+}
@@ -0,0 +1,76 @@
+// Command train fits a model for the ml scoring backend on a labeled
+// dataset and writes it as JSON:
+//
+//	train -data data/labeled.jsonl -out model.json
+//	train -data data/labeled.jsonl -out model.json -epochs 2000 -l2 0.01
+//
+// Serve it with CLASSIFIER_BACKEND=ml MODEL_FILE=model.json, and compare
+// it with the heuristic using evaluate -model model.json.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/model"
+)
+
+func main() {
+	dataFile := flag.String("data", "data/labeled.jsonl", "Labeled dataset (JSONL)")
+	out := flag.String("out", "model.json", "Model file to write")
+	version := flag.String("version", time.Now().UTC().Format("2006-01-02"), "Version recorded in the model")
+	epochs := flag.Int("epochs", 500, "Gradient descent passes over the dataset")
+	rate := flag.Float64("rate", 0.5, "Learning rate")
+	l2 := flag.Float64("l2", 0.001, "L2 regularization strength")
+	flag.Parse()
+
+	samples, err := dataset.ReadFile(*dataFile)
+	if err != nil {
+		log.Fatalf("Error reading dataset: %v", err)
+	}
+	if len(samples) == 0 {
+		log.Fatalf("Error: dataset %s is empty", *dataFile)
+	}
+
+	examples := make([]model.Example, len(samples))
+	for i, s := range samples {
+		examples[i] = model.Example{
+			Signals: fingerprint.ExtractSignals(s.Fingerprint),
+			Browser: s.Label == classifier.ClassificationBrowser,
+		}
+	}
+	m, err := model.Train(examples, model.TrainOptions{
+		Version:      *version,
+		Epochs:       *epochs,
+		LearningRate: *rate,
+		L2:           *l2,
+	})
+	if err != nil {
+		log.Fatalf("Error training model: %v", err)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		log.Fatalf("Error encoding model: %v", err)
+	}
+	if err := os.WriteFile(*out, append(data, '\n'), 0o644); err != nil {
+		log.Fatalf("Error writing model: %v", err)
+	}
+
+	correct := 0
+	for _, e := range examples {
+		if (m.Predict(e.Signals) >= m.Threshold) == e.Browser {
+			correct++
+		}
+	}
+	fmt.Printf("Trained on %d samples: %d weighted signals, training accuracy %.3f\n",
+		len(examples), len(m.Weights), float64(correct)/float64(len(examples)))
+	fmt.Printf("Model written to %s\n", *out)
+}
@@ -0,0 +1,44 @@
+//go:build js && wasm
+
+// Command wasm builds the HTTP-only classifier core for JavaScript
+// runtimes (browsers, Cloudflare Workers, Deno).
+//
+// After instantiation with wasm_exec.js it registers two global functions
+// taking and returning JSON strings:
+//
+//	classifyRequest('{"method":"GET","proto":"HTTP/2.0","headers":{...}}')
+//	classifyFingerprint('{"http":{...}}')
+//
+// Errors are returned as '{"error":"..."}'.
+package main
+
+import (
+	"syscall/js"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/edge"
+)
+
+func main() {
+	engine := edge.New(classifier.DefaultConfig())
+
+	js.Global().Set("classifyRequest", jsFunc(engine.ClassifyRequestJSON))
+	js.Global().Set("classifyFingerprint", jsFunc(engine.ClassifyFingerprintJSON))
+
+	// Keep the Go runtime alive for callbacks
+	select {}
+}
+
+// jsFunc adapts a JSON-in/JSON-out function to a JS callable
+func jsFunc(fn func([]byte) ([]byte, error)) js.Func {
+	return js.FuncOf(func(this js.Value, args []js.Value) any {
+		if len(args) < 1 || args[0].Type() != js.TypeString {
+			return `{"error":"expected a JSON string argument"}`
+		}
+		out, err := fn([]byte(args[0].String()))
+		if err != nil {
+			return string(edge.ErrorJSON(err))
+		}
+		return string(out)
+	})
+}
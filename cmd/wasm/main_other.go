@@ -0,0 +1,16 @@
+//go:build !(js && wasm) && !wasip1
+
+// Command wasm is the edge-runtime build of the classifier core. It only
+// does useful work when built for GOOS=js or GOOS=wasip1 with GOARCH=wasm;
+// see main_js.go and main_wasip1.go.
+package main
+
+import (
+	"fmt"
+	"os"
+)
+
+func main() {
+	fmt.Fprintln(os.Stderr, "wasm: build with GOOS=js GOARCH=wasm or GOOS=wasip1 GOARCH=wasm")
+	os.Exit(2)
+}
@@ -0,0 +1,42 @@
+//go:build wasip1
+
+// Command wasm builds the HTTP-only classifier core for WASI runtimes
+// (Fastly Compute, wasmtime, wazero, TinyGo-based hosts).
+//
+// It reads one JSON document from stdin and writes the classification
+// result as JSON to stdout. The input is a RequestMetadata object, or a
+// Fingerprint when invoked with the "fingerprint" argument:
+//
+//	echo '{"method":"GET","headers":{"User-Agent":["curl/8.0"]}}' | wasmtime classifier.wasm
+//	wasmtime classifier.wasm fingerprint < fingerprint.json
+package main
+
+import (
+	"io"
+	"os"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/edge"
+)
+
+func main() {
+	engine := edge.New(classifier.DefaultConfig())
+
+	classify := engine.ClassifyRequestJSON
+	if len(os.Args) > 1 && os.Args[1] == "fingerprint" {
+		classify = engine.ClassifyFingerprintJSON
+	}
+
+	input, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		_, _ = os.Stdout.Write(edge.ErrorJSON(err))
+		os.Exit(1)
+	}
+
+	out, err := classify(input)
+	if err != nil {
+		_, _ = os.Stdout.Write(edge.ErrorJSON(err))
+		os.Exit(1)
+	}
+	_, _ = os.Stdout.Write(out)
+}
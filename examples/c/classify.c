@@ -0,0 +1,17 @@
+// Minimal example of calling the classifier shared library from C.
+//
+//   task build:cshared
+//   cc -o bin/classify-c examples/c/classify.c -Ibin -Lbin -lclassifier
+//   LD_LIBRARY_PATH=bin ./bin/classify-c
+
+#include <stdio.h>
+#include "libclassifier.h"
+
+int main(void) {
+    char *result = Classify(
+        "{\"method\":\"GET\",\"proto\":\"HTTP/1.1\","
+        "\"headers\":{\"User-Agent\":[\"curl/8.0.1\"],\"Accept\":[\"*/*\"]}}");
+    printf("%s", result);
+    ClassifierFree(result);
+    return 0;
+}
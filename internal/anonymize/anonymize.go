@@ -0,0 +1,107 @@
+// Package anonymize pseudonymizes client IP addresses before they are
+// logged or stored, for GDPR-friendly deployments.
+//
+// Addresses are truncated to a network prefix (/24 for IPv4 and /48 for
+// IPv6 by default), replaced by a keyed HMAC, or both. Either way the same
+// client maps to the same value, so per-client state such as session
+// timing keeps working on the anonymized key.
+package anonymize
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// Default prefix lengths kept when truncating
+const (
+	DefaultIPv4Prefix = 24
+	DefaultIPv6Prefix = 48
+)
+
+// hashPrefix prefixes HMAC pseudonyms so they are not mistaken for addresses
+const hashPrefix = "h:"
+
+// Config selects how addresses are anonymized
+type Config struct {
+	Truncate   bool   // Zero the host bits beyond the prefix lengths
+	IPv4Prefix int    // IPv4 prefix length kept when truncating (default 24)
+	IPv6Prefix int    // IPv6 prefix length kept when truncating (default 48)
+	HMACKey    []byte // Replace the (truncated) address by its HMAC-SHA256 when set
+}
+
+// Anonymizer rewrites client addresses according to a Config
+type Anonymizer struct {
+	cfg Config
+}
+
+// New creates an anonymizer. At least one of Truncate and HMACKey is required.
+func New(cfg Config) (*Anonymizer, error) {
+	if !cfg.Truncate && len(cfg.HMACKey) == 0 {
+		return nil, errors.New("anonymize: Truncate or HMACKey is required")
+	}
+	if cfg.IPv4Prefix == 0 {
+		cfg.IPv4Prefix = DefaultIPv4Prefix
+	}
+	if cfg.IPv6Prefix == 0 {
+		cfg.IPv6Prefix = DefaultIPv6Prefix
+	}
+	if cfg.IPv4Prefix < 0 || cfg.IPv4Prefix > 32 {
+		return nil, fmt.Errorf("anonymize: invalid IPv4 prefix /%d", cfg.IPv4Prefix)
+	}
+	if cfg.IPv6Prefix < 0 || cfg.IPv6Prefix > 128 {
+		return nil, fmt.Errorf("anonymize: invalid IPv6 prefix /%d", cfg.IPv6Prefix)
+	}
+	return &Anonymizer{cfg: cfg}, nil
+}
+
+// IP returns the anonymized form of an address: the truncated address
+// (e.g. "203.0.113.0"), or "h:" and 32 hex digits when hashing
+func (a *Anonymizer) IP(addr netip.Addr) string {
+	addr = addr.Unmap()
+	if a.cfg.Truncate {
+		bits := a.cfg.IPv6Prefix
+		if addr.Is4() {
+			bits = a.cfg.IPv4Prefix
+		}
+		if p, err := addr.Prefix(bits); err == nil {
+			addr = p.Addr()
+		}
+	}
+	if len(a.cfg.HMACKey) == 0 {
+		return addr.String()
+	}
+	return a.hash(addr.String())
+}
+
+// Addr anonymizes a remote address ("ip:port" or a bare IP), dropping the
+// port. Values that are not IP addresses are hashed when a key is set and
+// dropped otherwise.
+func (a *Anonymizer) Addr(remoteAddr string) string {
+	if remoteAddr == "" {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		if len(a.cfg.HMACKey) == 0 {
+			return ""
+		}
+		return a.hash(host)
+	}
+	return a.IP(addr.WithZone(""))
+}
+
+// hash returns the HMAC pseudonym of s
+func (a *Anonymizer) hash(s string) string {
+	mac := hmac.New(sha256.New, a.cfg.HMACKey)
+	mac.Write([]byte(s))
+	return hashPrefix + hex.EncodeToString(mac.Sum(nil)[:16])
+}
@@ -0,0 +1,13 @@
+package anonymize
+
+import "testing"
+
+// Tests are in tests/unit/anonymize_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestAnonymizePackage(t *testing.T) {
+	// Verify package is testable
+	if _, err := New(Config{Truncate: true}); err != nil {
+		t.Errorf("New() error = %v", err)
+	}
+}
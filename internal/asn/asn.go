@@ -0,0 +1,241 @@
+// Package asn looks up the autonomous system of client addresses and
+// whether it belongs to a hosting or datacenter provider. Browsers are
+// used from residential and mobile networks; scrapers mostly run on rented
+// servers. Lookups go through the Locator interface; DB reads IP-to-ASN
+// CSV exports such as DB-IP's or MaxMind's GeoLite2 ASN. The hosting
+// providers come from a built-in list of their ASNs that a file can
+// replace.
+package asn
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// AS is an autonomous system
+type AS struct {
+	Number uint32 // e.g. 24940
+	Org    string // e.g. "Hetzner Online GmbH" (empty when not listed)
+}
+
+// Locator returns the autonomous system announcing an address
+type Locator interface {
+	Lookup(addr netip.Addr) (AS, bool)
+}
+
+// asRange is one contiguous block of addresses announced by an AS
+type asRange struct {
+	first, last netip.Addr
+	as          AS
+}
+
+// DB is an IP-to-ASN database of address ranges, sorted for binary search
+type DB struct {
+	ranges []asRange
+}
+
+// Parse reads an IP-to-ASN CSV. Each row is either first,last,asn[,org]
+// (DB-IP) or network,asn[,org] (GeoLite2 ASN); ASNs may carry an "AS"
+// prefix. Blank lines, # comments and a header row are skipped, as are
+// rows of AS 0 (not routed).
+func Parse(r io.Reader) (*DB, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.Comment = '#'
+
+	db := &DB{}
+	line := 0
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rg, err := parseRow(rec)
+		if err != nil {
+			if line == 1 {
+				continue // Header row
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if rg.as.Number != 0 {
+			db.ranges = append(db.ranges, rg)
+		}
+	}
+	if len(db.ranges) == 0 {
+		return nil, errors.New("no address ranges")
+	}
+	slices.SortFunc(db.ranges, func(a, b asRange) int { return a.first.Compare(b.first) })
+	return db, nil
+}
+
+// parseRow parses a first,last,asn[,org] or network,asn[,org] row
+func parseRow(rec []string) (asRange, error) {
+	for i := range rec {
+		rec[i] = strings.TrimSpace(rec[i])
+	}
+	if len(rec) >= 3 {
+		first, err1 := netip.ParseAddr(rec[0])
+		last, err2 := netip.ParseAddr(rec[1])
+		if err1 == nil && err2 == nil {
+			if first.Is4() != last.Is4() || last.Less(first) {
+				return asRange{}, fmt.Errorf("invalid range %s-%s", first, last)
+			}
+			return newRange(first.Unmap(), last.Unmap(), rec[2:])
+		}
+	}
+	if len(rec) < 2 {
+		return asRange{}, errors.New("want first,last,asn or network,asn")
+	}
+	p, err := netip.ParsePrefix(rec[0])
+	if err != nil {
+		return asRange{}, err
+	}
+	p = p.Masked()
+	return newRange(p.Addr(), lastAddr(p), rec[1:])
+}
+
+// newRange parses the asn[,org] columns of a range
+func newRange(first, last netip.Addr, rec []string) (asRange, error) {
+	n, err := ParseNumber(rec[0])
+	if err != nil {
+		return asRange{}, err
+	}
+	rg := asRange{first: first, last: last, as: AS{Number: n}}
+	if len(rec) > 1 {
+		rg.as.Org = rec[1]
+	}
+	return rg, nil
+}
+
+// ParseNumber parses an ASN, with or without the "AS" prefix
+func ParseNumber(s string) (uint32, error) {
+	s = strings.TrimSpace(s)
+	if len(s) > 2 && strings.EqualFold(s[:2], "AS") {
+		s = s[2:]
+	}
+	n, err := strconv.ParseUint(s, 10, 32)
+	if err != nil {
+		return 0, fmt.Errorf("invalid ASN %q", s)
+	}
+	return uint32(n), nil
+}
+
+// lastAddr returns the last address of a prefix
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// Load reads an IP-to-ASN CSV from a file
+func Load(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	db, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Len returns the number of address ranges
+func (db *DB) Len() int {
+	return len(db.ranges)
+}
+
+// Lookup returns the AS of the range containing addr. Ranges are expected
+// not to overlap.
+func (db *DB) Lookup(addr netip.Addr) (AS, bool) {
+	addr = addr.Unmap()
+	i, found := slices.BinarySearchFunc(db.ranges, addr, func(r asRange, a netip.Addr) int {
+		return cmp.Compare(r.first.Compare(a), 0)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || db.ranges[i].last.Less(addr) || db.ranges[i].first.Is4() != addr.Is4() {
+		return AS{}, false
+	}
+	return db.ranges[i].as, true
+}
+
+// Enricher sets the client's AS, and the hosting provider operating it,
+// from a Locator. It implements classifier.Enricher.
+type Enricher struct {
+	locator Locator
+	hosting atomic.Pointer[HostingList]
+}
+
+// NewEnricher returns an enricher looking addresses up with l and hosting
+// providers in hosting (DefaultHosting when nil)
+func NewEnricher(l Locator, hosting *HostingList) *Enricher {
+	e := &Enricher{locator: l}
+	e.SetHosting(hosting)
+	return e
+}
+
+// SetHosting replaces the hosting provider list (DefaultHosting when nil).
+// It is safe to call while requests are enriched.
+func (e *Enricher) SetHosting(hosting *HostingList) {
+	if hosting == nil {
+		hosting = DefaultHosting()
+	}
+	e.hosting.Store(hosting)
+}
+
+// Hosting returns the hosting provider list in use
+func (e *Enricher) Hosting() *HostingList {
+	return e.hosting.Load()
+}
+
+// Name identifies the lookup in ClassificationResult.Incomplete
+func (e *Enricher) Name() string {
+	return "asn"
+}
+
+// Enrich records the AS of the remote address in fp.Network, with the
+// hosting provider when the list names it. Loopback and private
+// addresses, such as a load balancer's, are not looked up.
+func (e *Enricher) Enrich(_ context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() {
+		return nil
+	}
+	as, ok := e.locator.Lookup(addr)
+	if !ok {
+		return nil
+	}
+	fp.Network.ASN = int(as.Number)
+	fp.Network.ASOrg = as.Org
+	fp.Network.Hosting, _ = e.hosting.Load().Provider(as.Number)
+	return nil
+}
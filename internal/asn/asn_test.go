@@ -0,0 +1,13 @@
+package asn
+
+import "testing"
+
+// Tests are in tests/unit/asn_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestAsnPackage(t *testing.T) {
+	// Verify package is testable
+	if DefaultHosting().Len() == 0 {
+		t.Error("built-in hosting list should not be empty")
+	}
+}
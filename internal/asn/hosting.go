@@ -0,0 +1,93 @@
+package asn
+
+import (
+	"bufio"
+	_ "embed"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// HostingList maps the ASNs of hosting and datacenter providers to the
+// provider. It is never modified once built, so enrichers share it.
+type HostingList struct {
+	byASN map[uint32]string
+}
+
+//go:embed hosting_asns.txt
+var builtinHosting string
+
+// defaultHosting is built from the embedded list on first use
+var defaultHosting = sync.OnceValue(func() *HostingList {
+	l, err := ParseHosting(strings.NewReader(builtinHosting))
+	if err != nil {
+		panic(fmt.Sprintf("hosting_asns.txt: %v", err))
+	}
+	return l
+})
+
+// DefaultHosting returns the built-in hosting providers: the large clouds
+// and the budget hosts scrapers rent from
+func DefaultHosting() *HostingList {
+	return defaultHosting()
+}
+
+// ParseHosting reads a hosting provider list, one "ASN provider" per line
+// (e.g. "AS24940 Hetzner"), with # comments. An ASN listed twice keeps its
+// last provider.
+func ParseHosting(r io.Reader) (*HostingList, error) {
+	l := &HostingList{byASN: map[uint32]string{}}
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s, _, _ := strings.Cut(scanner.Text(), "#")
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		num, provider, _ := strings.Cut(s, " ")
+		n, err := ParseNumber(num)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if provider = strings.TrimSpace(provider); provider == "" {
+			provider = "AS" + num
+		}
+		l.byASN[n] = provider
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(l.byASN) == 0 {
+		return nil, errors.New("no hosting ASNs")
+	}
+	return l, nil
+}
+
+// LoadHosting reads a hosting provider list from a file
+func LoadHosting(path string) (*HostingList, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	l, err := ParseHosting(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return l, nil
+}
+
+// Len returns the number of listed ASNs
+func (l *HostingList) Len() int {
+	return len(l.byASN)
+}
+
+// Provider returns the hosting provider operating an AS
+func (l *HostingList) Provider(asn uint32) (string, bool) {
+	p, ok := l.byASN[asn]
+	return p, ok
+}
@@ -0,0 +1,72 @@
+// Package buildinfo reports the version and source revision the binary
+// was built from.
+//
+// Release builds inject them with the linker:
+//
+//	go build -ldflags "-X github.com/muliwe/go-client-classifier/internal/buildinfo.version=v0.5.0 \
+//	  -X github.com/muliwe/go-client-classifier/internal/buildinfo.commit=$(git rev-parse HEAD) \
+//	  -X github.com/muliwe/go-client-classifier/internal/buildinfo.date=$(date -u +%Y-%m-%dT%H:%M:%SZ)"
+//
+// Values not injected are taken from the module and VCS information the Go
+// toolchain embeds (go install module@version, or go build in a git
+// checkout).
+package buildinfo
+
+import (
+	"runtime"
+	"runtime/debug"
+	"sync"
+)
+
+// Set with -ldflags "-X ..."
+var (
+	version string
+	commit  string
+	date    string
+)
+
+// DevVersion is reported by builds without version information
+const DevVersion = "dev"
+
+// Info describes the running binary
+type Info struct {
+	Version   string `json:"version"`
+	Commit    string `json:"commit,omitempty"`     // Source revision
+	BuildDate string `json:"build_date,omitempty"` // Injected build time, or the commit time
+	Modified  bool   `json:"modified,omitempty"`   // Built from a tree with uncommitted changes
+	GoVersion string `json:"go_version"`
+}
+
+// Get returns the build information of the running binary
+var Get = sync.OnceValue(func() Info {
+	info := Info{Version: version, Commit: commit, BuildDate: date, GoVersion: runtime.Version()}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if info.Version == "" && bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.Version = bi.Main.Version
+		}
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				if info.Commit == "" {
+					info.Commit = s.Value
+				}
+			case "vcs.time":
+				if info.BuildDate == "" {
+					info.BuildDate = s.Value
+				}
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	if info.Version == "" {
+		info.Version = DevVersion
+	}
+	return info
+})
+
+// Version returns the version of the running binary
+func Version() string {
+	return Get().Version
+}
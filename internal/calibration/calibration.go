@@ -0,0 +1,141 @@
+// Package calibration maps the classifier's raw confidence to the
+// precision observed on labeled data, so that decisions reported with
+// confidence 0.9 are right about 90% of the time. Calibrations are fitted
+// by cmd/calibrate and stored as JSON:
+//
+//	{"version": "2026-10-15", "backend": "heuristic", "method": "platt", "a": 9.1, "b": -5.2}
+//
+//	{"version": "2026-10-15", "backend": "heuristic", "method": "isotonic",
+//	 "points": [{"raw": 0.5, "calibrated": 0.62}, {"raw": 0.99, "calibrated": 0.98}]}
+package calibration
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"slices"
+)
+
+// Calibration methods
+const (
+	MethodPlatt    = "platt"    // Logistic curve sigmoid(a × raw + b)
+	MethodIsotonic = "isotonic" // Non-decreasing piecewise-linear map
+)
+
+// Calibration maps raw confidence to calibrated confidence
+type Calibration struct {
+	Version string `json:"version"`
+	Backend string `json:"backend,omitempty"` // Scoring backend the raw confidences came from
+	Method  string `json:"method"`
+
+	// Platt scaling parameters
+	A float64 `json:"a,omitempty"`
+	B float64 `json:"b,omitempty"`
+
+	// Isotonic map, by increasing raw confidence
+	Points []Point `json:"points,omitempty"`
+}
+
+// Point maps one raw confidence of an isotonic calibration
+type Point struct {
+	Raw        float64 `json:"raw"`
+	Calibrated float64 `json:"calibrated"`
+}
+
+// Sample is a classified example: the confidence reported and whether the
+// decision matched the label
+type Sample struct {
+	Confidence float64
+	Correct    bool
+}
+
+// Load reads a calibration file
+func Load(path string) (*Calibration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	c, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return c, nil
+}
+
+// Parse decodes and validates a JSON calibration
+func Parse(data []byte) (*Calibration, error) {
+	var c Calibration
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, err
+	}
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// Validate checks the parameters of the calibration's method
+func (c *Calibration) Validate() error {
+	switch c.Method {
+	case MethodPlatt:
+		if !finite(c.A) || !finite(c.B) {
+			return errors.New("platt parameters are not finite numbers")
+		}
+		if c.A < 0 {
+			return errors.New("platt parameter a is negative, so confidence would fall as raw confidence rises")
+		}
+	case MethodIsotonic:
+		if len(c.Points) == 0 {
+			return errors.New("isotonic calibration has no points")
+		}
+		for i, p := range c.Points {
+			if !finite(p.Raw) || p.Calibrated < 0 || p.Calibrated > 1 {
+				return fmt.Errorf("point %d is not a raw confidence mapped to a probability", i)
+			}
+			if i > 0 && (p.Raw <= c.Points[i-1].Raw || p.Calibrated < c.Points[i-1].Calibrated) {
+				return fmt.Errorf("point %d does not increase on the one before it", i)
+			}
+		}
+	default:
+		return fmt.Errorf("unknown method %q, want %s or %s", c.Method, MethodPlatt, MethodIsotonic)
+	}
+	return nil
+}
+
+// Apply returns the calibrated confidence of a raw confidence
+func (c *Calibration) Apply(raw float64) float64 {
+	if c.Method == MethodPlatt {
+		return sigmoid(c.A*raw + c.B)
+	}
+
+	ps := c.Points
+	i, _ := slices.BinarySearchFunc(ps, raw, func(p Point, raw float64) int {
+		switch {
+		case p.Raw < raw:
+			return -1
+		case p.Raw > raw:
+			return 1
+		}
+		return 0
+	})
+	switch {
+	case i == 0:
+		return ps[0].Calibrated
+	case i == len(ps):
+		return ps[len(ps)-1].Calibrated
+	}
+	lo, hi := ps[i-1], ps[i]
+	return lo.Calibrated + (hi.Calibrated-lo.Calibrated)*(raw-lo.Raw)/(hi.Raw-lo.Raw)
+}
+
+// sigmoid maps log-odds to a probability
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// finite reports whether v is neither NaN nor infinite
+func finite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
@@ -0,0 +1,14 @@
+package calibration
+
+import "testing"
+
+// Tests are in tests/unit/calibration_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestCalibrationPackage(t *testing.T) {
+	// Verify package is testable
+	c := Calibration{Method: MethodPlatt, A: 1}
+	if err := c.Validate(); err != nil {
+		t.Errorf("Validate() error = %v", err)
+	}
+}
@@ -0,0 +1,161 @@
+package calibration
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+)
+
+// Fit fits a calibration with the given method to classified samples
+func Fit(samples []Sample, method string) (*Calibration, error) {
+	if len(samples) == 0 {
+		return nil, errors.New("no samples")
+	}
+	switch method {
+	case MethodPlatt:
+		a, b := fitPlatt(samples)
+		return &Calibration{Method: MethodPlatt, A: a, B: b}, nil
+	case MethodIsotonic:
+		return &Calibration{Method: MethodIsotonic, Points: fitIsotonic(samples)}, nil
+	}
+	return nil, fmt.Errorf("unknown method %q, want %s or %s", method, MethodPlatt, MethodIsotonic)
+}
+
+// fitPlatt fits sigmoid(a × raw + b) by Newton's method on the log loss,
+// with Platt's smoothed targets so that a separable dataset does not drive
+// the parameters to infinity. a is kept non-negative.
+func fitPlatt(samples []Sample) (a, b float64) {
+	var pos, neg float64
+	for _, s := range samples {
+		if s.Correct {
+			pos++
+		} else {
+			neg++
+		}
+	}
+	hi, lo := (pos+1)/(pos+2), 1/(neg+2)
+	b = math.Log((pos + 1) / (neg + 1))
+
+	for range 100 {
+		// Gradient and Hessian of the log loss in (a, b)
+		var ga, gb, haa, hab, hbb float64
+		for _, s := range samples {
+			t := lo
+			if s.Correct {
+				t = hi
+			}
+			p := sigmoid(a*s.Confidence + b)
+			d := p - t
+			w := max(p*(1-p), 1e-12)
+			ga += d * s.Confidence
+			gb += d
+			haa += w * s.Confidence * s.Confidence
+			hab += w * s.Confidence
+			hbb += w
+		}
+		haa += 1e-9 // Regularize when all raw confidences are equal
+		det := haa*hbb - hab*hab
+		if det <= 0 {
+			break
+		}
+		da := (hbb*ga - hab*gb) / det
+		db := (haa*gb - hab*ga) / det
+		a, b = a-da, b-db
+		if math.Abs(da) < 1e-9 && math.Abs(db) < 1e-9 {
+			break
+		}
+	}
+	if a < 0 {
+		// Raw confidence does not predict correctness: report the base rate
+		return 0, math.Log(hi*pos+lo*neg) - math.Log(pos+neg-(hi*pos+lo*neg))
+	}
+	return a, b
+}
+
+// fitIsotonic fits a non-decreasing map by pool adjacent violators over
+// the samples sorted by raw confidence. Each pooled block becomes a point
+// at its mean raw confidence.
+func fitIsotonic(samples []Sample) []Point {
+	sorted := slices.SortedFunc(slices.Values(samples), func(x, y Sample) int {
+		return cmp.Compare(x.Confidence, y.Confidence)
+	})
+
+	type block struct {
+		rawSum, correct, n float64
+	}
+	var blocks []block
+	for i, s := range sorted {
+		c := 0.0
+		if s.Correct {
+			c = 1
+		}
+		// Equal raw confidences always share a block
+		if i > 0 && s.Confidence == sorted[i-1].Confidence {
+			last := &blocks[len(blocks)-1]
+			last.rawSum += s.Confidence
+			last.correct += c
+			last.n++
+		} else {
+			blocks = append(blocks, block{rawSum: s.Confidence, correct: c, n: 1})
+		}
+		for len(blocks) > 1 {
+			last, prev := blocks[len(blocks)-1], blocks[len(blocks)-2]
+			if prev.correct/prev.n <= last.correct/last.n {
+				break
+			}
+			blocks = blocks[:len(blocks)-1]
+			blocks[len(blocks)-1] = block{prev.rawSum + last.rawSum, prev.correct + last.correct, prev.n + last.n}
+		}
+	}
+
+	points := make([]Point, len(blocks))
+	for i, bl := range blocks {
+		points[i] = Point{Raw: bl.rawSum / bl.n, Calibrated: bl.correct / bl.n}
+	}
+	return points
+}
+
+// ECE returns the expected calibration error: the gap between confidence
+// and accuracy within equal-width confidence bins, averaged by sample
+func ECE(samples []Sample, bins int) float64 {
+	if len(samples) == 0 || bins <= 0 {
+		return 0
+	}
+	conf := make([]float64, bins)
+	correct := make([]float64, bins)
+	n := make([]float64, bins)
+	for _, s := range samples {
+		i := min(bins-1, max(0, int(s.Confidence*float64(bins))))
+		conf[i] += s.Confidence
+		if s.Correct {
+			correct[i]++
+		}
+		n[i]++
+	}
+	var ece float64
+	for i := range bins {
+		if n[i] > 0 {
+			ece += math.Abs(conf[i]-correct[i]) / float64(len(samples))
+		}
+	}
+	return ece
+}
+
+// Brier returns the mean squared error of confidence as a prediction of
+// correctness
+func Brier(samples []Sample) float64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, s := range samples {
+		t := 0.0
+		if s.Correct {
+			t = 1
+		}
+		sum += (s.Confidence - t) * (s.Confidence - t)
+	}
+	return sum / float64(len(samples))
+}
@@ -0,0 +1,13 @@
+package captcha
+
+import "testing"
+
+// Tests are in tests/unit/captcha_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestCaptchaPackage(t *testing.T) {
+	// Verify package is testable
+	if SafeReturn("//evil.example") != "/" {
+		t.Error("SafeReturn should reject protocol-relative URLs")
+	}
+}
@@ -0,0 +1,56 @@
+package captcha
+
+import (
+	"html/template"
+	"io"
+)
+
+// pageTemplate is the challenge page: the provider widget in a form
+// posting back to the challenge endpoint
+var pageTemplate = template.Must(template.New("captcha").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<meta name="robots" content="noindex">
+<title>Checking your browser</title>
+<script src="{{.Script}}" async defer></script>
+</head>
+<body>
+<main>
+<h1>Checking your browser</h1>
+<p>Please complete the check below to continue.</p>
+{{if .Error}}<p role="alert">{{.Error}}</p>{{end}}
+<form method="POST" action="{{.Action}}">
+<input type="hidden" name="return" value="{{.Return}}">
+<div class="{{.Widget}}" data-sitekey="{{.SiteKey}}"></div>
+<button type="submit">Continue</button>
+</form>
+</main>
+</body>
+</html>
+`))
+
+// pageData fills pageTemplate
+type pageData struct {
+	Script  string
+	Widget  string
+	SiteKey string
+	Action  string
+	Return  string
+	Error   string
+}
+
+// RenderPage writes the challenge page. The form posts the widget response
+// to action; returnTo is where a verified client is sent afterwards, and
+// errMsg is shown after a failed attempt (empty for none).
+func (v *Verifier) RenderPage(w io.Writer, action, returnTo, errMsg string) error {
+	return pageTemplate.Execute(w, pageData{
+		Script:  v.provider.widget.ScriptURL,
+		Widget:  v.provider.widget.Class,
+		SiteKey: v.cfg.SiteKey,
+		Action:  action,
+		Return:  SafeReturn(returnTo),
+		Error:   errMsg,
+	})
+}
@@ -0,0 +1,131 @@
+package captcha
+
+import (
+	"slices"
+	"sync"
+	"time"
+)
+
+// StoreConfig holds verified session store configuration
+type StoreConfig struct {
+	TTL         time.Duration // How long a solved challenge is honored (default 24h)
+	MaxSessions int           // Maximum number of verified sessions (oldest evicted first)
+}
+
+// DefaultStoreConfig returns default verified session store configuration
+func DefaultStoreConfig() StoreConfig {
+	return StoreConfig{
+		TTL:         24 * time.Hour,
+		MaxSessions: 10000,
+	}
+}
+
+// Store records sessions that solved a challenge. Sessions are identified
+// by the same keys as session.Tracker (client address and User-Agent).
+type Store struct {
+	mu       sync.Mutex
+	cfg      StoreConfig
+	verified map[string]time.Time // Session key -> verification time
+}
+
+// NewStore creates an empty verified session store
+func NewStore(cfg StoreConfig) *Store {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultStoreConfig().TTL
+	}
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = DefaultStoreConfig().MaxSessions
+	}
+	return &Store{cfg: cfg, verified: make(map[string]time.Time)}
+}
+
+// MarkVerified records that the session solved a challenge at now
+func (s *Store) MarkVerified(key string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.verified[key]; !exists && len(s.verified) >= s.cfg.MaxSessions {
+		s.evict(now)
+	}
+	s.verified[key] = now
+}
+
+// Verified reports whether the session solved a challenge within the TTL
+func (s *Store) Verified(key string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	at, ok := s.verified[key]
+	if !ok {
+		return false
+	}
+	if now.Sub(at) > s.cfg.TTL {
+		delete(s.verified, key)
+		return false
+	}
+	return true
+}
+
+// Len returns the number of verified sessions, including expired ones not
+// yet evicted
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.verified)
+}
+
+// EntryBytes estimates the memory held per verified session: its key,
+// timestamp and map overhead
+func (s *Store) EntryBytes() int {
+	return 224
+}
+
+// SetCapacity changes the maximum number of verified sessions, evicting the
+// oldest verifications beyond it
+func (s *Store) SetCapacity(n int) {
+	if n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg.MaxSessions = n
+	excess := len(s.verified) - n
+	if excess <= 0 {
+		return
+	}
+	keys := make([]string, 0, len(s.verified))
+	for k := range s.verified {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return s.verified[a].Compare(s.verified[b])
+	})
+	for _, k := range keys[:excess] {
+		delete(s.verified, k)
+	}
+}
+
+// evict removes expired sessions, or the oldest verification if none have
+// expired. Caller must hold the lock.
+func (s *Store) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	removed := false
+
+	for k, at := range s.verified {
+		if now.Sub(at) > s.cfg.TTL {
+			delete(s.verified, k)
+			removed = true
+			continue
+		}
+		if oldestKey == "" || at.Before(oldest) {
+			oldestKey = k
+			oldest = at
+		}
+	}
+
+	if !removed && oldestKey != "" {
+		delete(s.verified, oldestKey)
+	}
+}
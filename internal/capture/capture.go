@@ -0,0 +1,150 @@
+// Package capture samples classified traffic into a separate file for
+// building labeled datasets.
+//
+// Capture files are JSONL request logs: each Record is a full log entry,
+// regardless of the log profile, optionally followed by the raw request.
+// They can be labeled with cmd/label like any request log.
+package capture
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// Record is one captured request
+type Record struct {
+	logger.LogEntry
+	Request *fingerprint.RequestMetadata `json:"request,omitempty"` // Raw request (when Raw is set)
+}
+
+// Config holds capture settings
+type Config struct {
+	Path       string  // Capture file, appended to
+	Enabled    bool    // Capture from the start (otherwise enable at runtime)
+	SampleRate float64 // Fraction of classified requests captured, 0-1
+	Raw        bool    // Also store the raw request (method, path, all headers)
+}
+
+// State is the runtime state of a Capturer
+type State struct {
+	Enabled    bool    `json:"enabled"`
+	SampleRate float64 `json:"sample_rate"`
+	Raw        bool    `json:"raw"`
+	Captured   int64   `json:"captured"` // Records written since start
+	Path       string  `json:"path"`
+}
+
+// Capturer writes sampled records to a capture file. Its settings can be
+// changed while it runs.
+type Capturer struct {
+	mu       sync.Mutex
+	file     *os.File
+	enc      *json.Encoder
+	enabled  atomic.Bool
+	rate     atomic.Uint64 // math.Float64bits of the sample rate
+	raw      atomic.Bool
+	captured atomic.Int64
+}
+
+// ValidRate reports whether rate is a sample fraction between 0 and 1
+func ValidRate(rate float64) bool {
+	return rate >= 0 && rate <= 1
+}
+
+// New opens the capture file
+func New(cfg Config) (*Capturer, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("capture: path is required")
+	}
+	if !ValidRate(cfg.SampleRate) {
+		return nil, fmt.Errorf("capture: sample rate %v is not between 0 and 1", cfg.SampleRate)
+	}
+	if dir := filepath.Dir(cfg.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	file, err := os.OpenFile(cfg.Path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &Capturer{file: file, enc: json.NewEncoder(file)}
+	c.enabled.Store(cfg.Enabled)
+	c.rate.Store(math.Float64bits(cfg.SampleRate))
+	c.raw.Store(cfg.Raw)
+	return c, nil
+}
+
+// Sample reports whether the next request should be captured
+func (c *Capturer) Sample() bool {
+	if !c.enabled.Load() {
+		return false
+	}
+	rate := math.Float64frombits(c.rate.Load())
+	return rate >= 1 || rand.Float64() < rate
+}
+
+// Raw reports whether raw requests are captured
+func (c *Capturer) Raw() bool {
+	return c.raw.Load()
+}
+
+// Write appends a record to the capture file
+func (c *Capturer) Write(rec Record) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.enc.Encode(rec); err != nil {
+		return err
+	}
+	c.captured.Add(1)
+	return nil
+}
+
+// SetEnabled starts or stops capturing
+func (c *Capturer) SetEnabled(enabled bool) {
+	c.enabled.Store(enabled)
+}
+
+// SetSampleRate changes the captured fraction of requests
+func (c *Capturer) SetSampleRate(rate float64) error {
+	if !ValidRate(rate) {
+		return fmt.Errorf("sample rate %v is not between 0 and 1", rate)
+	}
+	c.rate.Store(math.Float64bits(rate))
+	return nil
+}
+
+// SetRaw enables or disables raw request capture
+func (c *Capturer) SetRaw(raw bool) {
+	c.raw.Store(raw)
+}
+
+// State returns the current settings and counters
+func (c *Capturer) State() State {
+	return State{
+		Enabled:    c.enabled.Load(),
+		SampleRate: math.Float64frombits(c.rate.Load()),
+		Raw:        c.raw.Load(),
+		Captured:   c.captured.Load(),
+		Path:       c.file.Name(),
+	}
+}
+
+// Close closes the capture file
+func (c *Capturer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.file.Close()
+}
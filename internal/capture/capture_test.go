@@ -0,0 +1,13 @@
+package capture
+
+import "testing"
+
+// Tests are in tests/unit/capture_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestCapturePackage(t *testing.T) {
+	// Verify package is testable
+	if !ValidRate(0.5) || ValidRate(2) {
+		t.Error("ValidRate should accept 0-1 only")
+	}
+}
@@ -0,0 +1,174 @@
+// Package challenge issues and verifies signed challenge tokens. A client
+// that passes a challenge (a Private Access Token, a CAPTCHA) is handed a
+// short-lived token bound to its fingerprint; on later requests the token
+// is checked for its signature, expiry and binding, so the client does not
+// have to be challenged again.
+//
+// Tokens are HMAC-SHA256 signed and carried in the clf_challenge cookie or
+// the X-Challenge-Token header:
+//
+//	base64url(version | expiry (unix seconds, big-endian) | binding | mac)
+package challenge
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Transport of tokens
+const (
+	CookieName = "clf_challenge"
+	Header     = "X-Challenge-Token"
+)
+
+// DefaultTTL is how long issued tokens are valid when Config.TTL is unset
+const DefaultTTL = time.Hour
+
+// Token layout
+const (
+	tokenVersion = 1
+	bindingLen   = 16
+	macLen       = sha256.Size
+	payloadLen   = 1 + 8 + bindingLen
+	tokenLen     = payloadLen + macLen
+)
+
+// Verification errors
+var (
+	ErrMalformed       = errors.New("malformed challenge token")
+	ErrBadSignature    = errors.New("invalid challenge token signature")
+	ErrExpired         = errors.New("challenge token expired")
+	ErrBindingMismatch = errors.New("challenge token issued to a different client")
+)
+
+// Config holds signer configuration
+type Config struct {
+	Key []byte        // HMAC key (at least 16 bytes)
+	TTL time.Duration // Token lifetime (default 1h)
+}
+
+// Signer issues and verifies challenge tokens. It implements
+// classifier.Enricher, recording the outcome in Fingerprint.HTTP.ChallengeToken.
+type Signer struct {
+	key []byte
+	ttl time.Duration
+}
+
+// NewSigner creates a signer with the configured key
+func NewSigner(cfg Config) (*Signer, error) {
+	if len(cfg.Key) < 16 {
+		return nil, errors.New("challenge key must be at least 16 bytes")
+	}
+	if cfg.TTL < 0 {
+		return nil, errors.New("challenge TTL must not be negative")
+	}
+	if cfg.TTL == 0 {
+		cfg.TTL = DefaultTTL
+	}
+	return &Signer{key: append([]byte(nil), cfg.Key...), ttl: cfg.TTL}, nil
+}
+
+// TTL returns the lifetime of issued tokens
+func (s *Signer) TTL() time.Duration {
+	return s.ttl
+}
+
+// Issue returns a token for the client with fingerprint fp, valid until now+TTL
+func (s *Signer) Issue(fp fingerprint.Fingerprint, now time.Time) string {
+	buf := make([]byte, payloadLen, tokenLen)
+	buf[0] = tokenVersion
+	binary.BigEndian.PutUint64(buf[1:9], uint64(now.Add(s.ttl).Unix()))
+	b := binding(fp)
+	copy(buf[9:], b[:])
+	buf = append(buf, s.mac(buf)...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Verify checks a token's signature, expiry and binding to fp
+func (s *Signer) Verify(token string, fp fingerprint.Fingerprint, now time.Time) error {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != tokenLen || buf[0] != tokenVersion {
+		return ErrMalformed
+	}
+	if !hmac.Equal(buf[payloadLen:], s.mac(buf[:payloadLen])) {
+		return ErrBadSignature
+	}
+	if now.Unix() >= int64(binary.BigEndian.Uint64(buf[1:9])) {
+		return ErrExpired
+	}
+	b := binding(fp)
+	if !hmac.Equal(buf[9:payloadLen], b[:]) {
+		return ErrBindingMismatch
+	}
+	return nil
+}
+
+// Cookie returns the cookie carrying token
+func (s *Signer) Cookie(token string) *http.Cookie {
+	return &http.Cookie{
+		Name:     CookieName,
+		Value:    token,
+		Path:     "/",
+		MaxAge:   int(s.ttl.Seconds()),
+		HttpOnly: true,
+		Secure:   true,
+		SameSite: http.SameSiteLaxMode,
+	}
+}
+
+// FromRequest returns the token presented in the X-Challenge-Token header
+// or, failing that, the clf_challenge cookie
+func FromRequest(r *http.Request) (string, bool) {
+	if t := r.Header.Get(Header); t != "" {
+		return t, true
+	}
+	if c, err := r.Cookie(CookieName); err == nil && c.Value != "" {
+		return c.Value, true
+	}
+	return "", false
+}
+
+// Name identifies the signer in ClassificationResult.Incomplete
+func (s *Signer) Name() string {
+	return "challenge"
+}
+
+// Enrich verifies a token presented with r. Requests without a token are
+// left unmarked; invalid tokens are recorded in fp, not returned as errors.
+func (s *Signer) Enrich(_ context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	token, ok := FromRequest(r)
+	if !ok {
+		return nil
+	}
+	if err := s.Verify(token, *fp, time.Now()); err != nil {
+		fp.HTTP.ChallengeToken = fingerprint.ChallengeTokenFail
+		return nil
+	}
+	fp.HTTP.ChallengeToken = fingerprint.ChallengeTokenPass
+	return nil
+}
+
+// mac signs a token payload
+func (s *Signer) mac(payload []byte) []byte {
+	m := hmac.New(sha256.New, s.key)
+	m.Write(payload)
+	return m.Sum(nil)
+}
+
+// binding hashes the parts of a fingerprint a token is bound to: the TLS
+// client (JA4) and the User-Agent. JA4H is left out because it covers the
+// cookies, which change when the token itself is set.
+func binding(fp fingerprint.Fingerprint) [bindingLen]byte {
+	sum := sha256.Sum256([]byte(fp.TLS.JA4Hash + "\n" + fp.HTTP.UserAgent))
+	var b [bindingLen]byte
+	copy(b[:], sum[:])
+	return b
+}
@@ -0,0 +1,13 @@
+package challenge
+
+import "testing"
+
+// Tests are in tests/unit/challenge_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestChallengePackage(t *testing.T) {
+	// Verify package is testable
+	if CookieName == "" {
+		t.Error("CookieName should not be empty")
+	}
+}
@@ -0,0 +1,31 @@
+package classifier
+
+import (
+	"math"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// BotScoreHeader is the conventional response header for BotScore
+const BotScoreHeader = "Cf-Bot-Score"
+
+// Cloudflare-compatible bot score bounds. Scores below BotScoreLikelyHuman
+// are likely automated, matching the 1-29 / 30-99 split of Cloudflare's
+// bot score so existing thresholds keep the classifier's decision.
+const (
+	BotScoreMin         = 1
+	BotScoreLikelyHuman = 30
+	BotScoreMax         = 99
+)
+
+// BotScore maps a result to Cloudflare's 1-99 bot score: 1 is certainly
+// automated, 99 certainly human. Bot results score 1-29 and browser
+// results 30-99, lower confidence landing nearer the boundary.
+func BotScore(r fingerprint.ClassificationResult) int {
+	// Confidence is in [0.5, 0.99]; rescale to [0, 1]
+	strength := max(0, min(1, (r.Confidence-0.5)/0.49))
+	if r.Classification == ClassificationBot {
+		return BotScoreLikelyHuman - 1 - int(math.Round(strength*float64(BotScoreLikelyHuman-1-BotScoreMin)))
+	}
+	return BotScoreLikelyHuman + int(math.Round(strength*float64(BotScoreMax-BotScoreLikelyHuman)))
+}
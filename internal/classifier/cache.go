@@ -0,0 +1,162 @@
+package classifier
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"maps"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// DefaultCacheTTL is how long a cached verdict is reused when
+// Config.CacheTTL is not set
+const DefaultCacheTTL = 5 * time.Minute
+
+// CacheStats counts verdict cache lookups
+type CacheStats struct {
+	Hits     uint64 `json:"hits"`
+	Misses   uint64 `json:"misses"`
+	Bypassed uint64 `json:"bypassed"` // Fingerprints without a hash, never cached
+	Size     int    `json:"size"`
+	Capacity int    `json:"capacity"`
+}
+
+// VerdictCache is an LRU cache of classification results by
+// fingerprint.VerdictKey, with a TTL. Entries remember the rule state they
+// were classified with and miss once rules or threshold change. It is a
+// membudget.Store.
+type VerdictCache struct {
+	ttl time.Duration
+
+	mu       sync.Mutex
+	capacity int
+	order    *list.List // Front is most recently used
+	entries  map[[sha256.Size]byte]*list.Element
+
+	hits, misses, bypassed atomic.Uint64
+}
+
+// cachedVerdict is a cached result without its request-specific fields
+type cachedVerdict struct {
+	key     [sha256.Size]byte
+	state   *ruleState
+	expires time.Time
+	result  fingerprint.ClassificationResult
+}
+
+func newVerdictCache(capacity int, ttl time.Duration) *VerdictCache {
+	if ttl <= 0 {
+		ttl = DefaultCacheTTL
+	}
+	return &VerdictCache{
+		ttl:      ttl,
+		capacity: capacity,
+		order:    list.New(),
+		entries:  make(map[[sha256.Size]byte]*list.Element),
+	}
+}
+
+// get returns the verdict cached for key under state, if still fresh
+func (c *VerdictCache) get(key [sha256.Size]byte, state *ruleState, now time.Time) (fingerprint.ClassificationResult, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	el, ok := c.entries[key]
+	if ok {
+		v := el.Value.(*cachedVerdict)
+		if v.state == state && now.Before(v.expires) {
+			c.order.MoveToFront(el)
+			c.hits.Add(1)
+			return v.result, true
+		}
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	c.misses.Add(1)
+	return fingerprint.ClassificationResult{}, false
+}
+
+// put caches a result classified under state
+func (c *VerdictCache) put(key [sha256.Size]byte, state *ruleState, result fingerprint.ClassificationResult, now time.Time) {
+	result.RequestID = ""
+	result.Timestamp = time.Time{}
+	result.Fingerprint = fingerprint.Fingerprint{}
+	v := &cachedVerdict{key: key, state: state, expires: now.Add(c.ttl), result: result}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if el, ok := c.entries[key]; ok {
+		el.Value = v
+		c.order.MoveToFront(el)
+		return
+	}
+	c.entries[key] = c.order.PushFront(v)
+	c.evict(c.capacity)
+}
+
+// evict removes the least recently used entries beyond n; callers hold mu
+func (c *VerdictCache) evict(n int) {
+	for c.order.Len() > n {
+		el := c.order.Back()
+		c.order.Remove(el)
+		delete(c.entries, el.Value.(*cachedVerdict).key)
+	}
+}
+
+// TTL returns how long verdicts are reused
+func (c *VerdictCache) TTL() time.Duration {
+	return c.ttl
+}
+
+// Len returns the number of cached verdicts
+func (c *VerdictCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+// EntryBytes estimates the memory held per cached verdict: the result's
+// signals, breakdown and class confidences, list element and map entry
+func (c *VerdictCache) EntryBytes() int {
+	return 2048
+}
+
+// SetCapacity bounds the cache to n verdicts, evicting the least recently
+// used beyond it
+func (c *VerdictCache) SetCapacity(n int) {
+	if n <= 0 {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.capacity = n
+	c.evict(n)
+}
+
+// Stats returns the lookup counters and size
+func (c *VerdictCache) Stats() CacheStats {
+	c.mu.Lock()
+	size, capacity := c.order.Len(), c.capacity
+	c.mu.Unlock()
+	return CacheStats{
+		Hits:     c.hits.Load(),
+		Misses:   c.misses.Load(),
+		Bypassed: c.bypassed.Load(),
+		Size:     size,
+		Capacity: capacity,
+	}
+}
+
+// reuse completes a cached verdict for a new request
+func reuse(cached fingerprint.ClassificationResult, fp fingerprint.Fingerprint, id string, now time.Time) fingerprint.ClassificationResult {
+	cached.RequestID = id
+	cached.Timestamp = now
+	cached.Fingerprint = fp
+	cached.Signals.ScoreBreakdown = slices.Clone(cached.Signals.ScoreBreakdown)
+	cached.FlipSet = slices.Clone(cached.FlipSet)
+	cached.ClassConfidence = maps.Clone(cached.ClassConfidence)
+	return cached
+}
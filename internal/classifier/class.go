@@ -0,0 +1,140 @@
+package classifier
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Client classes refining the browser/bot decision
+const (
+	ClassBrowser         = "browser"
+	ClassAICrawler       = "ai_crawler"       // AI training and answer-engine crawlers
+	ClassSearchBot       = "search_bot"       // Search engine crawlers (Googlebot, Bingbot)
+	ClassHeadlessBrowser = "headless_browser" // Automated browsers (Puppeteer, Playwright, HeadlessChrome)
+	ClassHTTPLibrary     = "http_library"     // Scripts and HTTP clients (curl, python-requests)
+	ClassUnknown         = "unknown"          // Bots of no recognizable kind
+)
+
+// Classes lists every client class. Bot classes are in order of
+// specificity: when several match, the first is reported.
+var Classes = []string{
+	ClassBrowser,
+	ClassAICrawler,
+	ClassSearchBot,
+	ClassHeadlessBrowser,
+	ClassHTTPLibrary,
+	ClassUnknown,
+}
+
+// headlessMarkers are User-Agent substrings of automated browsers
+var headlessMarkers = []string{
+	"headless",
+	"puppeteer",
+	"playwright",
+	"selenium",
+	"phantomjs",
+}
+
+// libraryTokens are lowercase product tokens HTTP clients start their
+// User-Agent with, e.g. "curl/8.0.1" or "python-requests/2.31"
+var libraryTokens = []string{
+	"curl/",
+	"wget/",
+	"python-requests/",
+	"python-urllib/",
+	"python-httpx/",
+	"python/",
+	"aiohttp/",
+	"go-http-client/",
+	"httpie/",
+	"postmanruntime/",
+	"insomnia/",
+	"axios/",
+	"node", // node-fetch, and undici fetch sending just "node"
+	"undici",
+	"got ",
+	"okhttp/",
+	"apache-httpclient/",
+	"java/",
+	"libwww-perl/",
+	"guzzlehttp/",
+	"ruby",
+	"dart/",
+	"reqwest/",
+	"scrapy/",
+}
+
+// botClasses returns the bot classes the User-Agent and signals point
+// to, most specific first
+func botClasses(fp fingerprint.Fingerprint, s fingerprint.Signals) []string {
+	ua := strings.ToLower(fp.HTTP.UserAgent)
+	found := map[string]bool{}
+
+	// A documented crawler's category takes precedence over the AI
+	// patterns: PerplexityBot matches both but crawls for search
+	crawler := false
+	for _, c := range fingerprint.KnownCrawlers() {
+		if ua == "" || !strings.Contains(ua, strings.ToLower(c.Name)) {
+			continue
+		}
+		crawler = true
+		switch c.Category {
+		case fingerprint.CategoryAITraining, fingerprint.CategoryAIFetch:
+			found[ClassAICrawler] = true
+		case fingerprint.CategorySearch:
+			found[ClassSearchBot] = true
+		}
+	}
+	if !crawler && s.UserAgentIsAICrawler {
+		found[ClassAICrawler] = true
+	}
+	found[ClassHeadlessBrowser] = slices.ContainsFunc(headlessMarkers, func(m string) bool {
+		return strings.Contains(ua, m)
+	})
+	found[ClassHTTPLibrary] = slices.ContainsFunc(libraryTokens, func(t string) bool {
+		return strings.HasPrefix(ua, t)
+	})
+
+	var classes []string
+	for _, c := range Classes {
+		if found[c] {
+			classes = append(classes, c)
+		}
+	}
+	return classes
+}
+
+// clientClass returns the class of a classified request and the
+// confidence of every class. The browser class gets the probability of
+// the browser decision; the rest is shared by the bot classes the
+// request matches, or goes to unknown when it matches none. The class is
+// browser exactly when the decision is, and otherwise the most specific
+// bot class matched.
+func clientClass(fp fingerprint.Fingerprint, s fingerprint.Signals, classification string, confidence float64) (string, map[string]float64) {
+	pBrowser := 1 - confidence
+	if classification == ClassificationBrowser {
+		pBrowser = confidence
+	}
+
+	conf := make(map[string]float64, len(Classes))
+	for _, c := range Classes {
+		conf[c] = 0
+	}
+	conf[ClassBrowser] = pBrowser
+
+	matched := botClasses(fp, s)
+	if len(matched) == 0 {
+		matched = []string{ClassUnknown}
+	}
+	share := (1 - pBrowser) / float64(len(matched))
+	for _, c := range matched {
+		conf[c] = share
+	}
+
+	if classification == ClassificationBrowser {
+		return ClassBrowser, conf
+	}
+	return matched[0], conf
+}
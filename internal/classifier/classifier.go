@@ -1,9 +1,16 @@
 package classifier
 
 import (
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/muliwe/go-client-classifier/internal/calibration"
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/model"
 
 	"github.com/google/uuid"
 )
@@ -13,9 +20,28 @@ const (
 	ClassificationBot     = "bot"
 )
 
-// Classifier performs client classification based on fingerprint signals
+// Classifier performs client classification based on fingerprint signals.
+// Rules and threshold can be replaced while requests are classified: they
+// are read through an atomic pointer, never under a lock.
 type Classifier struct {
+	state      atomic.Pointer[ruleState]
+	collector  *fingerprint.Collector
+	enrichment Enrichment
+	cache      *VerdictCache // nil when caching is disabled
+	scorers    []NamedScorer
+
+	calibration *calibration.Calibration // nil reports raw confidence
+
+	feedbackStore  FeedbackStore
+	feedbackAdjust bool
+}
+
+// ruleState is the scoring configuration a request is classified with.
+// It is never modified once published; updates swap in a new copy.
+type ruleState struct {
 	threshold int // Score threshold for classification
+	rules     fingerprint.Rules
+	model     *model.Model // Decides instead of the threshold when set
 }
 
 // Config holds classifier configuration
@@ -24,6 +50,44 @@ type Config struct {
 	// Positive net score (browser - bot) >= threshold = browser
 	// Otherwise = bot
 	Threshold int
+
+	// Rules overrides the built-in User-Agent patterns and rule weights (nil = defaults)
+	Rules *fingerprint.Rules
+
+	// Weights overrides the points of rules by name, on top of Rules, e.g.
+	// {"sec-fetch": 4, "http1.1": 0}. A weight of 0 disables the rule.
+	Weights map[string]int
+
+	// Backend selects how signals are scored: BackendHeuristic (default)
+	// or BackendML, which decides with Model and falls back to the
+	// heuristic when no model is set
+	Backend string
+	Model   *model.Model
+
+	// Calibration maps raw confidence to the precision observed on labeled
+	// data (nil = raw confidence)
+	Calibration *calibration.Calibration
+
+	// Scorers add their points after the rules, in order. Scorers that read
+	// fingerprint fields outside fingerprint.VerdictKey need CacheSize 0.
+	Scorers []NamedScorer
+
+	// CacheSize enables a verdict cache of up to CacheSize results keyed by
+	// fingerprint.VerdictKey, reused for CacheTTL (0 = DefaultCacheTTL)
+	CacheSize int
+	CacheTTL  time.Duration
+
+	// Feedback stores corrections submitted with Classifier.Feedback, and
+	// FeedbackAdjust lets them lower the weights of misleading rules
+	Feedback       FeedbackStore
+	FeedbackAdjust bool
+
+	// Enrichers run in order by ClassifyRequest after collection
+	Enrichers []Enricher
+	// EnrichmentTimeout bounds all enrichers of a request (0 = ctx deadline only)
+	EnrichmentTimeout time.Duration
+	// EnrichmentBudgets bounds single enrichers by name within the timeout
+	EnrichmentBudgets map[string]time.Duration
 }
 
 // DefaultConfig returns default classifier configuration
@@ -33,28 +97,161 @@ func DefaultConfig() Config {
 	}
 }
 
-// New creates a new classifier
-func New(cfg Config) *Classifier {
-	return &Classifier{
-		threshold: cfg.Threshold,
+// New creates a new classifier from DefaultConfig and the given options
+func New(opts ...Option) *Classifier {
+	cfg := NewConfig(opts...)
+	rules := fingerprint.DefaultRules()
+	if cfg.Rules != nil {
+		rules = cfg.Rules.Clone()
+	}
+	if len(cfg.Weights) > 0 {
+		if rules.Weights == nil {
+			rules.Weights = make(map[string]int, len(cfg.Weights))
+		}
+		maps.Copy(rules.Weights, cfg.Weights)
+	}
+	c := &Classifier{
+		collector: fingerprint.NewCollector(),
+		enrichment: Enrichment{
+			Enrichers: cfg.Enrichers,
+			Timeout:   cfg.EnrichmentTimeout,
+			Budgets:   maps.Clone(cfg.EnrichmentBudgets),
+		},
+		scorers:        slices.Clone(cfg.Scorers),
+		calibration:    cfg.Calibration,
+		feedbackStore:  cfg.Feedback,
+		feedbackAdjust: cfg.FeedbackAdjust,
+	}
+	if cfg.CacheSize > 0 {
+		c.cache = newVerdictCache(cfg.CacheSize, cfg.CacheTTL)
+	}
+	st := &ruleState{threshold: cfg.Threshold, rules: rules}
+	if cfg.Backend == BackendML {
+		st.model = cfg.Model
 	}
+	c.state.Store(st)
+	return c
+}
+
+// Backend returns the scoring backend in use
+func (c *Classifier) Backend() string {
+	if c.state.Load().model != nil {
+		return BackendML
+	}
+	return BackendHeuristic
+}
+
+// Model returns the model of the ml backend, or nil for the heuristic
+func (c *Classifier) Model() *model.Model {
+	return c.state.Load().model
 }
 
-// Classify analyzes a fingerprint and returns classification result
+// Calibration returns the confidence calibration, or nil when confidence
+// is reported raw
+func (c *Classifier) Calibration() *calibration.Calibration {
+	return c.calibration
+}
+
+// Rules returns a copy of the rules in use
+func (c *Classifier) Rules() fingerprint.Rules {
+	return c.state.Load().rules.Clone()
+}
+
+// Threshold returns the net score cutoff in use
+func (c *Classifier) Threshold() int {
+	return c.state.Load().threshold
+}
+
+// SetRules replaces the User-Agent patterns and rule weights. Requests
+// already being classified finish with the previous rules; rules is
+// copied, so the caller may reuse it.
+func (c *Classifier) SetRules(rules fingerprint.Rules) {
+	rules = rules.Clone()
+	c.update(func(st *ruleState) { st.rules = rules })
+}
+
+// SetFingerprints replaces the database of known JA3/JA4 fingerprints
+// (nil = fingerprint.DefaultFingerprintDB), keeping the other rules
+func (c *Classifier) SetFingerprints(db *fingerprint.FingerprintDB) {
+	c.update(func(st *ruleState) {
+		st.rules = st.rules.Clone()
+		st.rules.Fingerprints = db
+	})
+}
+
+// SetThreshold replaces the net score cutoff for browser classification
+func (c *Classifier) SetThreshold(threshold int) {
+	c.update(func(st *ruleState) { st.threshold = threshold })
+}
+
+// update publishes a copy of the current state changed by fn, retrying
+// when another update wins the race so neither change is lost
+func (c *Classifier) update(fn func(*ruleState)) {
+	for {
+		old := c.state.Load()
+		next := *old
+		fn(&next)
+		if c.state.CompareAndSwap(old, &next) {
+			return
+		}
+	}
+}
+
+// Cache returns the verdict cache, or nil when caching is disabled
+func (c *Classifier) Cache() *VerdictCache {
+	return c.cache
+}
+
+// Classify analyzes a fingerprint and returns classification result. With
+// a verdict cache, clients seen within the TTL get the cached verdict
+// without signal extraction.
 func (c *Classifier) Classify(fp fingerprint.Fingerprint) fingerprint.ClassificationResult {
-	signals := fingerprint.ExtractSignals(fp)
+	st := c.state.Load()
+	if c.cache == nil {
+		return c.classify(st, fp)
+	}
+	key, ok := fingerprint.VerdictKey(fp)
+	if !ok {
+		c.cache.bypassed.Add(1)
+		return c.classify(st, fp)
+	}
+	now := time.Now().UTC()
+	if cached, hit := c.cache.get(key, st, now); hit {
+		return reuse(cached, fp, uuid.New().String(), now)
+	}
+	result := c.classify(st, fp)
+	c.cache.put(key, st, result, now)
+	return result
+}
+
+// classify scores a fingerprint with the given rule state
+func (c *Classifier) classify(st *ruleState, fp fingerprint.Fingerprint) fingerprint.ClassificationResult {
+	signals := fingerprint.ExtractSignalsWithRules(fp, st.rules)
+	extra := runScorers(c.scorers, fp, &signals)
 	netScore := signals.BrowserScore - signals.BotScore
 
 	classification := ClassificationBot
 	var reason string
-	if netScore >= c.threshold {
+	if netScore >= st.threshold {
 		classification = ClassificationBrowser
-		reason = c.browserReason(signals)
+		reason = c.browserReason(signals, extra.browser)
 	} else {
-		reason = c.botReason(signals)
+		reason = c.botReason(signals, extra.bot)
 	}
 
 	confidence := c.calculateConfidence(signals, netScore)
+	margin := netScore - st.threshold
+	flips := flipSet(signals, margin)
+	if st.model != nil {
+		d := classifyML(st.model, signals)
+		classification, confidence, reason = d.classification, d.confidence, d.reason
+		margin, flips = d.margin, d.flipSet
+	}
+	if c.calibration != nil {
+		// Kept in the raw range, which bot scores are rescaled from
+		confidence = max(0.5, min(0.99, c.calibration.Apply(confidence)))
+	}
+	class, classConfidence := clientClass(fp, signals, classification, confidence)
 
 	return fingerprint.ClassificationResult{
 		RequestID:      uuid.New().String(),
@@ -65,31 +262,59 @@ func (c *Classifier) Classify(fp fingerprint.Fingerprint) fingerprint.Classifica
 		Signals:        signals,
 		Score:          netScore,
 		Reason:         reason,
+		Margin:         margin,
+		FlipSet:        flips,
+
+		Class:           class,
+		ClassConfidence: classConfidence,
 	}
 }
 
-// browserReason generates explanation for browser classification
-func (c *Classifier) browserReason(s fingerprint.Signals) string {
+// browserReason generates explanation for browser classification, ending
+// with the reasons of custom scorers
+func (c *Classifier) browserReason(s fingerprint.Signals, extra []string) string {
 	reasons := []string{}
 
+	if s.HasValidPrivateToken {
+		reasons = append(reasons, "valid Private Access Token")
+	}
+	if s.ChallengeTokenPassed {
+		reasons = append(reasons, "passed challenge token")
+	}
+	if s.CaptchaSolved {
+		reasons = append(reasons, "solved a CAPTCHA")
+	}
+	if s.IsCORSPreflight {
+		reasons = append(reasons, "browser CORS preflight")
+	}
 	if s.HasSecFetchHeaders {
 		reasons = append(reasons, "has Sec-Fetch headers")
 	}
 	if s.IsHTTP2 {
 		reasons = append(reasons, "uses HTTP/2")
 	}
+	if s.IsHTTP3 {
+		reasons = append(reasons, "uses HTTP/3")
+	}
 	if s.UserAgentIsBrowser {
 		reasons = append(reasons, "browser User-Agent")
 	}
+	if s.KnownBrowserFingerprint {
+		reasons = append(reasons, "known "+s.KnownFingerprintClient+" TLS fingerprint")
+	}
 	if s.HasBrowserHeaders {
 		reasons = append(reasons, "has browser-specific headers")
 	}
+	if s.TLSIntercepted {
+		reasons = append(reasons, "behind TLS-intercepting proxy")
+	}
 	if s.HasJA4HFingerprint && s.JA4HConsistentSignal {
 		reasons = append(reasons, "consistent JA4H fingerprint")
 	}
 	if s.JA4HHighHeaderCount {
 		reasons = append(reasons, "high header count (JA4H)")
 	}
+	reasons = append(reasons, extra...)
 
 	if len(reasons) == 0 {
 		return "Classified as browser based on overall signal score"
@@ -105,37 +330,113 @@ func (c *Classifier) browserReason(s fingerprint.Signals) string {
 	return result
 }
 
-// botReason generates explanation for bot classification
-func (c *Classifier) botReason(s fingerprint.Signals) string {
+// botReason generates explanation for bot classification, ending with the
+// reasons of custom scorers
+func (c *Classifier) botReason(s fingerprint.Signals, extra []string) string {
 	reasons := []string{}
 
+	if s.ChallengeTokenFailed {
+		reasons = append(reasons, "invalid challenge token")
+	}
+	if s.HoneypotHit {
+		reasons = append(reasons, "requested a honeypot trap")
+	}
+	if s.RepeatOffender {
+		reasons = append(reasons, "repeat offender")
+	}
 	if s.UserAgentIsBot {
-		reasons = append(reasons, "bot User-Agent pattern")
+		reasons = append(reasons, "bot User-Agent pattern"+quoteList(s.MatchedBotPatterns))
 	}
 	if s.UserAgentIsAICrawler {
-		reasons = append(reasons, "AI/LLM crawler pattern")
+		reasons = append(reasons, "AI/LLM crawler pattern"+quoteList(s.MatchedAICrawlerPatterns))
 	}
-	if s.LowHeaderCount {
+	if s.SpoofedCrawler {
+		reasons = append(reasons, "spoofed "+s.ClaimedCrawler+" (address not the operator's)")
+	}
+	if s.DatacenterASN {
+		reasons = append(reasons, "datacenter network ("+s.HostingProvider+")")
+	}
+	if s.AnonymizedNetwork {
+		reasons = append(reasons, "anonymized network ("+s.Anonymizer+")")
+	}
+	if s.OutdatedBrowser {
+		reasons = append(reasons, "browser "+strconv.Itoa(s.BrowserVersionLag)+" major releases out of date")
+	}
+	if s.LowHeaderCount && !s.IsCORSPreflight {
 		reasons = append(reasons, "low header count")
 	}
+	if s.MalformedPreflight {
+		reasons = append(reasons, "malformed CORS preflight")
+	}
+	if s.IsHeadRequest {
+		reasons = append(reasons, "HEAD request")
+	}
 	if !s.HasUserAgent {
 		reasons = append(reasons, "missing User-Agent")
 	}
 	if !s.HasSecFetchHeaders && !s.HasAcceptLanguage {
 		reasons = append(reasons, "missing browser headers")
 	}
-	if s.MissingTypicalHeader {
+	if s.MissingTypicalHeader && !s.IsCORSPreflight {
 		reasons = append(reasons, "missing typical headers")
 	}
+	if s.SpoofedReferer {
+		reasons = append(reasons, "spoofed Referer")
+	}
+	if s.FetchMetadataMismatch {
+		reasons = append(reasons, "Sec-Fetch metadata contradicted by "+s.FetchMetadataConflict)
+	}
+	for _, name := range s.SpoofingCombos {
+		if c, ok := fingerprint.LookupImpossibleCombination(name); ok {
+			reasons = append(reasons, "impossible headers: "+c.Explanation)
+		}
+	}
+	if s.NonCanonicalHeaderCase {
+		reasons = append(reasons, "non-browser header casing")
+	}
+	if s.ClientHintsMismatch {
+		reasons = append(reasons, "User-Agent contradicted by "+s.ClientHintsConflict)
+	}
+	if s.TCPOSMismatch {
+		reasons = append(reasons, "TCP stack of another OS than the User-Agent")
+	}
+	if s.ColocatedClient {
+		reasons = append(reasons, "handshake latency of a colocated client")
+	}
+	if s.LibraryExtensionOrder {
+		reasons = append(reasons, "TLS extension order of "+s.ExtensionOrderStack)
+	}
+	if s.TLSImpersonation {
+		reasons = append(reasons, "TLS fingerprint of "+s.TLSStack+" under a browser User-Agent")
+	}
+	if s.KnownBotFingerprint {
+		reasons = append(reasons, "known "+s.KnownFingerprintClient+" TLS fingerprint")
+	}
 	if s.HasJA4HFingerprint && !s.JA4HConsistentSignal {
 		reasons = append(reasons, "inconsistent JA4H fingerprint")
 	}
 	if s.JA4HMissingLanguage {
 		reasons = append(reasons, "no Accept-Language (JA4H)")
 	}
-	if s.JA4HLowHeaderCount {
+	if s.JA4HLowHeaderCount && !s.IsCORSPreflight {
 		reasons = append(reasons, "low header count (JA4H)")
 	}
+	if s.RegularTiming {
+		reasons = append(reasons, "machine-regular request timing")
+	}
+	if s.SubHumanInterval {
+		reasons = append(reasons, "sub-human request intervals")
+	}
+	if s.HighRequestRate {
+		reasons = append(reasons, "high request rate")
+	}
+	if s.BurstPattern {
+		reasons = append(reasons, "request burst")
+	}
+	if s.ContentOnly {
+		reasons = append(reasons, "pages without assets")
+	}
+	reasons = append(reasons, extra...)
 
 	if len(reasons) == 0 {
 		return "Classified as bot based on overall signal score"
@@ -179,3 +480,16 @@ func (c *Classifier) calculateConfidence(s fingerprint.Signals, netScore int) fl
 
 	return confidence
 }
+
+// quoteList renders matched patterns for a reason, e.g. ` ("bot", "headless")`.
+// Signals replayed from older logs have none and render as "".
+func quoteList(items []string) string {
+	if len(items) == 0 {
+		return ""
+	}
+	quoted := make([]string, len(items))
+	for i, item := range items {
+		quoted[i] = strconv.Quote(item)
+	}
+	return " (" + strings.Join(quoted, ", ") + ")"
+}
@@ -0,0 +1,100 @@
+package classifier
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// ErrFeedbackDisabled is returned by Feedback when the classifier neither
+// stores feedback nor adjusts weights from it
+var ErrFeedbackDisabled = errors.New("feedback is not enabled")
+
+// Feedback is an operator's correction of a classification
+type Feedback struct {
+	RequestID   string
+	Label       string // Correct classification: browser or bot
+	Fingerprint fingerprint.Fingerprint
+	Predicted   string // Classification reported at the time (re-classified when empty)
+	Note        string
+}
+
+// FeedbackStore records corrected samples for review and retraining
+type FeedbackStore interface {
+	Store(Feedback) error
+}
+
+// FeedbackResult reports what a correction changed
+type FeedbackResult struct {
+	Predicted string         `json:"predicted"`
+	Stored    bool           `json:"stored"`
+	Adjusted  map[string]int `json:"adjusted,omitempty"` // New weights of the rules lowered
+}
+
+// FeedbackEnabled reports whether Feedback stores or learns from corrections
+func (c *Classifier) FeedbackEnabled() bool {
+	return c.feedbackStore != nil || c.feedbackAdjust
+}
+
+// Feedback records a correction. With a store it is kept as a labeled
+// sample; with weight adjustment a misclassification lowers by one point
+// each fired rule that argued for the wrong decision, never below one, so
+// repeated corrections wear down a misleading rule without disabling it.
+// Weights are not adjusted with the ml backend: retrain the model from
+// the stored samples instead.
+func (c *Classifier) Feedback(f Feedback) (FeedbackResult, error) {
+	if !c.FeedbackEnabled() {
+		return FeedbackResult{}, ErrFeedbackDisabled
+	}
+	if f.Label != ClassificationBrowser && f.Label != ClassificationBot {
+		return FeedbackResult{}, fmt.Errorf("invalid label %q, want %s or %s", f.Label, ClassificationBrowser, ClassificationBot)
+	}
+	result := c.classify(c.state.Load(), f.Fingerprint)
+	if f.Predicted == "" {
+		f.Predicted = result.Classification
+	}
+
+	var res FeedbackResult
+	res.Predicted = f.Predicted
+	if c.feedbackStore != nil {
+		if err := c.feedbackStore.Store(f); err != nil {
+			return res, fmt.Errorf("failed to store feedback: %w", err)
+		}
+		res.Stored = true
+	}
+	if c.feedbackAdjust && c.Backend() == BackendHeuristic && f.Label != result.Classification {
+		res.Adjusted = c.lowerWeights(result.Signals, f.Label)
+	}
+	return res, nil
+}
+
+// lowerWeights takes a point off every fired rule that scored against
+// label, returning the new weights of the rules changed
+func (c *Classifier) lowerWeights(s fingerprint.Signals, label string) map[string]int {
+	browser, bot := s.ScoreBreakdown.Rules()
+	wrong := browser
+	if label == ClassificationBrowser {
+		wrong = bot
+	}
+
+	var adjusted map[string]int
+	c.update(func(st *ruleState) {
+		adjusted = map[string]int{}
+		rules := st.rules.Clone()
+		for _, name := range wrong {
+			if w := rules.Weight(name); w > 1 {
+				if rules.Weights == nil {
+					rules.Weights = map[string]int{}
+				}
+				rules.Weights[name] = w - 1
+				adjusted[name] = w - 1
+			}
+		}
+		st.rules = rules
+	})
+	if len(adjusted) == 0 {
+		return nil
+	}
+	return adjusted
+}
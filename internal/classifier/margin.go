@@ -0,0 +1,42 @@
+package classifier
+
+import (
+	"slices"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// flipSet returns the fewest fired rules whose removal would flip the
+// decision, heaviest first. A browser decision flips when the removed
+// browser rules outweigh the margin; a bot decision when the removed bot
+// rules make up for it. Rules weigh the points they scored, so custom
+// scorers count too. It returns nil when no set of fired rules can.
+func flipSet(s fingerprint.Signals, margin int) []string {
+	browser, bot := s.ScoreBreakdown.Scores()
+	candidates, need := bot, -margin // Bot: removed weight must reach -margin
+	if margin >= 0 {
+		candidates, need = browser, margin+1 // Browser: must exceed margin
+	}
+
+	// Heaviest first; ties keep breakdown order
+	candidates = slices.Clone(candidates)
+	slices.SortStableFunc(candidates, func(a, b fingerprint.RuleScore) int {
+		return b.Weight - a.Weight
+	})
+
+	var set []string
+	removed := 0
+	for _, rs := range candidates {
+		if removed >= need {
+			break
+		}
+		if rs.Weight > 0 {
+			set = append(set, rs.Name)
+			removed += rs.Weight
+		}
+	}
+	if removed < need {
+		return nil
+	}
+	return set
+}
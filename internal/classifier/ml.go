@@ -0,0 +1,109 @@
+package classifier
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/model"
+)
+
+// Scoring backends
+const (
+	BackendHeuristic = "heuristic" // Weighted rules and threshold
+	BackendML        = "ml"        // Trained model (Config.Model)
+)
+
+// ValidBackend reports whether name is a known scoring backend
+func ValidBackend(name string) bool {
+	return name == "" || name == BackendHeuristic || name == BackendML
+}
+
+// mlDecision is the outcome of scoring signals with a model
+type mlDecision struct {
+	classification string
+	confidence     float64
+	reason         string
+	margin         int // P(browser) minus the threshold, in percentage points
+	flipSet        []string
+}
+
+// classifyML decides with a trained model. Confidence is the model's
+// probability of the decision, clamped to the heuristic's 0.5-0.99 range.
+func classifyML(m *model.Model, s fingerprint.Signals) mlDecision {
+	p := m.Predict(s)
+	d := mlDecision{
+		classification: ClassificationBot,
+		confidence:     1 - p,
+		margin:         int(math.Round((p - m.Threshold) * 100)),
+	}
+	if p >= m.Threshold {
+		d.classification = ClassificationBrowser
+		d.confidence = p
+	} else if d.margin >= 0 {
+		d.margin = -1 // Below the threshold by less than half a point
+	}
+	d.confidence = max(0.5, min(0.99, d.confidence))
+
+	contributions := m.Contributions(s)
+	d.flipSet = mlFlipSet(m, d.classification, contributions)
+	d.reason = mlReason(m, p, d.classification, contributions)
+	return d
+}
+
+// towards reports whether a contribution supports the classification
+func towards(c model.Contribution, classification string) bool {
+	if classification == ClassificationBrowser {
+		return c.Value > 0
+	}
+	return c.Value < 0
+}
+
+// mlFlipSet returns the fewest signals whose absence would flip the
+// model's decision, strongest first, or nil when none can
+func mlFlipSet(m *model.Model, classification string, contributions []model.Contribution) []string {
+	threshold := m.LogitThreshold()
+	z := m.Intercept
+	for _, c := range contributions {
+		z += c.Value
+	}
+	flipped := func() bool { return (z >= threshold) != (classification == ClassificationBrowser) }
+
+	var set []string
+	for _, c := range contributions {
+		if flipped() {
+			return set
+		}
+		if towards(c, classification) {
+			set = append(set, c.Signal)
+			z -= c.Value
+		}
+	}
+	if flipped() {
+		return set
+	}
+	return nil
+}
+
+// mlReason explains a model decision by its strongest supporting signals
+func mlReason(m *model.Model, p float64, classification string, contributions []model.Contribution) string {
+	var top []string
+	for _, c := range contributions {
+		if len(top) == 3 {
+			break
+		}
+		if towards(c, classification) {
+			top = append(top, fmt.Sprintf("%s (%+.2f)", c.Signal, c.Value))
+		}
+	}
+	reason := "Model"
+	if m.Version != "" {
+		reason += " " + m.Version
+	}
+	reason += fmt.Sprintf(" P(browser)=%.2f", p)
+	if len(top) > 0 {
+		reason += ", " + classification + " indicators: " + strings.Join(top, ", ")
+	}
+	return reason
+}
@@ -0,0 +1,163 @@
+package classifier
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/model"
+)
+
+// Option configures a Classifier.
+//
+// Config is itself an Option that replaces the whole configuration, so
+// New(cfg) keeps working and can be combined with functional options
+// applied after it: New(cfg, WithThreshold(2)).
+type Option interface {
+	apply(*Config)
+}
+
+// optionFunc adapts a function to the Option interface
+type optionFunc func(*Config)
+
+func (f optionFunc) apply(cfg *Config) { f(cfg) }
+
+func (c Config) apply(cfg *Config) { *cfg = c }
+
+// WithThreshold sets the net score cutoff for browser classification
+func WithThreshold(threshold int) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Threshold = threshold
+	})
+}
+
+// WithRules replaces the built-in User-Agent patterns and rule weights
+func WithRules(rules fingerprint.Rules) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Rules = &rules
+	})
+}
+
+// WithWeights overrides the points of rules by name, keeping earlier
+// overrides of other rules. A weight of 0 disables the rule.
+func WithWeights(weights map[string]int) Option {
+	return optionFunc(func(cfg *Config) {
+		merged := make(map[string]int, len(cfg.Weights)+len(weights))
+		maps.Copy(merged, cfg.Weights)
+		maps.Copy(merged, weights)
+		cfg.Weights = merged
+	})
+}
+
+// ParseWeights parses rule weights written as name=points pairs separated
+// by commas, e.g. "sec-fetch=4,http1.1=0"
+func ParseWeights(s string) (map[string]int, error) {
+	weights := map[string]int{}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid weight %q, want name=points", pair)
+		}
+		w, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight %q for %s", value, name)
+		}
+		weights[name] = w
+	}
+	return weights, nil
+}
+
+// WithBackend selects the scoring backend, BackendHeuristic or BackendML
+func WithBackend(backend string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Backend = backend
+	})
+}
+
+// WithModel scores signals with a trained model, selecting BackendML
+func WithModel(m *model.Model) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Backend = BackendML
+		cfg.Model = m
+	})
+}
+
+// WithCalibration reports confidence mapped by a fitted calibration
+func WithCalibration(cal *calibration.Calibration) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Calibration = cal
+	})
+}
+
+// WithScorer adds a custom scorer whose points show in the score
+// breakdown under name. Scorers run in the order they are added.
+func WithScorer(name string, s Scorer) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Scorers = append(slices.Clip(cfg.Scorers), NamedScorer{Name: name, Scorer: s})
+	})
+}
+
+// WithVerdictCache caches up to size verdicts for ttl (0 = DefaultCacheTTL)
+func WithVerdictCache(size int, ttl time.Duration) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.CacheSize = size
+		cfg.CacheTTL = ttl
+	})
+}
+
+// WithFeedback stores corrections submitted with Classifier.Feedback
+func WithFeedback(store FeedbackStore) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Feedback = store
+	})
+}
+
+// WithFeedbackAdjust lets corrections lower the weights of the rules
+// behind a misclassification
+func WithFeedbackAdjust(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.FeedbackAdjust = enabled
+	})
+}
+
+// WithEnrichers appends enrichers run by ClassifyRequest
+func WithEnrichers(enrichers ...Enricher) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Enrichers = append(cfg.Enrichers, enrichers...)
+	})
+}
+
+// WithEnrichmentTimeout bounds the time spent in enrichers per request
+func WithEnrichmentTimeout(d time.Duration) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.EnrichmentTimeout = d
+	})
+}
+
+// WithEnrichmentBudget bounds the time spent in the named enricher per
+// request. An enricher exceeding its budget is skipped and the next one
+// runs with the remaining enrichment time.
+func WithEnrichmentBudget(name string, d time.Duration) Option {
+	return optionFunc(func(cfg *Config) {
+		budgets := make(map[string]time.Duration, len(cfg.EnrichmentBudgets)+1)
+		maps.Copy(budgets, cfg.EnrichmentBudgets)
+		budgets[name] = d
+		cfg.EnrichmentBudgets = budgets
+	})
+}
+
+// NewConfig applies options on top of DefaultConfig
+func NewConfig(opts ...Option) Config {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(&cfg)
+		}
+	}
+	return cfg
+}
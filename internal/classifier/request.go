@@ -0,0 +1,48 @@
+package classifier
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Enricher adds data from external lookups (reverse DNS, GeoIP, ...)
+// to a collected fingerprint. Implementations must return promptly
+// once ctx is done.
+type Enricher interface {
+	// Name identifies the enricher in ClassificationResult.Incomplete
+	Name() string
+	// Enrich adds lookup results for r to fp
+	Enrich(ctx context.Context, r *http.Request, fp *fingerprint.Fingerprint) error
+}
+
+// ClassifyRequest collects the fingerprint of r, runs the configured
+// enrichers within ctx, the enrichment timeout and per-enricher budgets,
+// and classifies the result. Enrichers that fail or run out of time are
+// skipped and listed in Incomplete, and the result is marked Partial.
+func (c *Classifier) ClassifyRequest(ctx context.Context, r *http.Request) fingerprint.ClassificationResult {
+	result, _ := c.ClassifyRequestTimed(ctx, r)
+	return result
+}
+
+// ClassifyRequestTimed is ClassifyRequest with the time spent collecting,
+// enriching and classifying
+func (c *Classifier) ClassifyRequestTimed(ctx context.Context, r *http.Request) (fingerprint.ClassificationResult, Timings) {
+	var t Timings
+	start := time.Now()
+	fp := c.collector.Collect(r)
+	t.Collect = time.Since(start)
+
+	incomplete := c.enrichment.Run(ctx, r, &fp, &t)
+
+	start = time.Now()
+	result := c.Classify(fp)
+	if len(incomplete) > 0 {
+		result.Partial = true
+		result.Incomplete = incomplete
+	}
+	t.Classify = time.Since(start)
+	return result, t
+}
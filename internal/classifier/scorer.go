@@ -0,0 +1,85 @@
+package classifier
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Scorer adds company-specific evidence to the built-in rules. Its points
+// are added to the browser and bot scores before the threshold is applied,
+// and reasons explain them in the result. Score is called for every
+// classified request and must be safe for concurrent use.
+type Scorer interface {
+	Score(fp fingerprint.Fingerprint) (browserPts, botPts int, reasons []string)
+}
+
+// ScorerFunc adapts a function to the Scorer interface
+type ScorerFunc func(fp fingerprint.Fingerprint) (browserPts, botPts int, reasons []string)
+
+// Score calls f(fp)
+func (f ScorerFunc) Score(fp fingerprint.Fingerprint) (browserPts, botPts int, reasons []string) {
+	return f(fp)
+}
+
+// NamedScorer is a scorer registered under the name its points are shown
+// with in the score breakdown
+type NamedScorer struct {
+	Name   string
+	Scorer Scorer
+}
+
+// scorerReasons are the reasons of the scorers that fired, by the side
+// their points went to
+type scorerReasons struct {
+	browser, bot []string
+}
+
+// runScorers adds the points of the scorers to s, after the built-in and
+// ruleset rules, and returns their reasons. Points below one are ignored.
+// A scorer's reasons go with the side it gave more points to.
+func runScorers(scorers []NamedScorer, fp fingerprint.Fingerprint, s *fingerprint.Signals) scorerReasons {
+	var sr scorerReasons
+	fired := false
+	for _, ns := range scorers {
+		browserPts, botPts, reasons := ns.Scorer.Score(fp)
+		browserPts, botPts = max(0, browserPts), max(0, botPts)
+		if browserPts > 0 {
+			s.BrowserScore += browserPts
+			s.ScoreBreakdown = append(s.ScoreBreakdown, fingerprint.SignalContribution{
+				Name: ns.Name, Direction: fingerprint.DirectionBrowser, Weight: browserPts,
+			})
+		}
+		if botPts > 0 {
+			s.BotScore += botPts
+			s.ScoreBreakdown = append(s.ScoreBreakdown, fingerprint.SignalContribution{
+				Name: ns.Name, Direction: fingerprint.DirectionBot, Weight: botPts,
+			})
+		}
+		switch {
+		case browserPts == 0 && botPts == 0:
+			continue
+		case browserPts >= botPts:
+			sr.browser = append(sr.browser, reasons...)
+		default:
+			sr.bot = append(sr.bot, reasons...)
+		}
+		fired = true
+	}
+	if fired {
+		// Browser contributions stay ahead of bot ones
+		slices.SortStableFunc(s.ScoreBreakdown, func(a, b fingerprint.SignalContribution) int {
+			return cmp.Compare(directionOrder(a.Direction), directionOrder(b.Direction))
+		})
+	}
+	return sr
+}
+
+// directionOrder sorts browser contributions first
+func directionOrder(direction string) int {
+	if direction == fingerprint.DirectionBot {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,170 @@
+package classifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Stages of a classification, in order
+const (
+	StageCollect  = "collect"
+	StageEnrich   = "enrich"
+	StageClassify = "classify"
+	StageLog      = "log"
+)
+
+// Stages lists the stage names of a Timings breakdown, in order
+var Stages = []string{StageCollect, StageEnrich, StageClassify, StageLog}
+
+// Timings is the per-stage timing breakdown of one classification
+type Timings struct {
+	Collect  time.Duration
+	Enrich   time.Duration
+	Classify time.Duration
+	Log      time.Duration
+
+	// Enrichers holds the time spent in each enricher that ran, by name
+	Enrichers map[string]time.Duration
+}
+
+// Stage returns the duration of the named stage
+func (t Timings) Stage(name string) time.Duration {
+	switch name {
+	case StageCollect:
+		return t.Collect
+	case StageEnrich:
+		return t.Enrich
+	case StageClassify:
+		return t.Classify
+	case StageLog:
+		return t.Log
+	}
+	return 0
+}
+
+// Total returns the sum of all stages
+func (t Timings) Total() time.Duration {
+	return t.Collect + t.Enrich + t.Classify + t.Log
+}
+
+// MarshalJSON renders durations as fractional milliseconds
+func (t Timings) MarshalJSON() ([]byte, error) {
+	type timingsJSON struct {
+		CollectMs   float64            `json:"collect_ms"`
+		EnrichMs    float64            `json:"enrich_ms"`
+		ClassifyMs  float64            `json:"classify_ms"`
+		LogMs       float64            `json:"log_ms"`
+		TotalMs     float64            `json:"total_ms"`
+		EnrichersMs map[string]float64 `json:"enrichers_ms,omitempty"`
+	}
+	out := timingsJSON{
+		CollectMs:  ms(t.Collect),
+		EnrichMs:   ms(t.Enrich),
+		ClassifyMs: ms(t.Classify),
+		LogMs:      ms(t.Log),
+		TotalMs:    ms(t.Total()),
+	}
+	if len(t.Enrichers) > 0 {
+		out.EnrichersMs = make(map[string]float64, len(t.Enrichers))
+		for name, d := range t.Enrichers {
+			out.EnrichersMs[name] = ms(d)
+		}
+	}
+	return json.Marshal(out)
+}
+
+// ms converts a duration to fractional milliseconds
+func ms(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+// ParseEnrichmentBudgets parses per-enricher budgets written as
+// name=duration pairs separated by commas, e.g. "private-relay=1ms,dns=2ms"
+func ParseEnrichmentBudgets(s string) (map[string]time.Duration, error) {
+	budgets := map[string]time.Duration{}
+	for _, pair := range strings.Split(s, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid enrichment budget %q, want name=duration", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid enrichment budget %q for %s", value, name)
+		}
+		budgets[name] = d
+	}
+	return budgets, nil
+}
+
+// Enrichment runs enrichers within a time budget. Timeout bounds all
+// enrichers of a request; Budgets bound single enrichers by name, so a
+// slow DNS or GeoIP lookup is skipped without starving the ones after it.
+type Enrichment struct {
+	Enrichers []Enricher
+	Timeout   time.Duration            // 0 = ctx deadline only
+	Budgets   map[string]time.Duration // Per enricher name (0 or missing = no own budget)
+}
+
+// Run runs the enrichers in order on fp and returns the names of those
+// that failed or ran out of time. Their changes are discarded. Time spent
+// is added to t when it is non-nil.
+func (e Enrichment) Run(ctx context.Context, r *http.Request, fp *fingerprint.Fingerprint, t *Timings) []string {
+	if len(e.Enrichers) == 0 {
+		return nil
+	}
+	start := time.Now()
+	if t != nil {
+		defer func() { t.Enrich += time.Since(start) }()
+	}
+
+	if e.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, e.Timeout)
+		defer cancel()
+	}
+
+	var incomplete []string
+	for _, en := range e.Enrichers {
+		if ctx.Err() != nil {
+			incomplete = append(incomplete, en.Name())
+			continue
+		}
+		if !e.runOne(ctx, en, r, fp, t) {
+			incomplete = append(incomplete, en.Name())
+		}
+	}
+	return incomplete
+}
+
+// runOne runs one enricher within its budget and reports whether it
+// completed
+func (e Enrichment) runOne(ctx context.Context, en Enricher, r *http.Request, fp *fingerprint.Fingerprint, t *Timings) bool {
+	name := en.Name()
+	if budget := e.Budgets[name]; budget > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, budget)
+		defer cancel()
+	}
+	start := time.Now()
+
+	// Enrich a copy so a failed lookup cannot leave half-written data
+	enriched := *fp
+	err := en.Enrich(ctx, r, &enriched)
+	if t != nil {
+		if t.Enrichers == nil {
+			t.Enrichers = make(map[string]time.Duration, len(e.Enrichers))
+		}
+		t.Enrichers[name] += time.Since(start)
+	}
+	if err != nil || ctx.Err() != nil {
+		return false
+	}
+	*fp = enriched
+	return true
+}
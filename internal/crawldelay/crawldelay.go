@@ -0,0 +1,326 @@
+// Package crawldelay enforces the Crawl-delay a site declares in its
+// robots.txt on the crawlers it names.
+//
+// Crawl-delay is the minimum time between two requests of a crawler. A
+// named group (User-agent: GPTBot) applies to User-Agents containing that
+// token; the "*" group applies to identified AI crawlers, each paced on
+// its own. Other clients are never paced, since they cannot be told apart
+// from one another.
+//
+// The Content-Signal lines of the same groups (search=yes, ai-train=no)
+// declare how content may be used; they are echoed as response headers to
+// the crawlers they apply to.
+package crawldelay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Wildcard is the robots.txt user-agent matching every crawler
+const Wildcard = "*"
+
+// Delays maps lowercased robots.txt user-agent tokens to their Crawl-delay
+type Delays map[string]time.Duration
+
+// ContentSignals maps lowercased robots.txt user-agent tokens to their
+// Content-Signal value, e.g. "search=yes, ai-train=no"
+type ContentSignals map[string]string
+
+// ParseRobots reads the Crawl-delay of each user-agent group of a robots.txt
+func ParseRobots(r io.Reader) (Delays, error) {
+	delays := Delays{}
+	err := scanGroups(r, func(line int, field, value string, group []string) error {
+		if field != "crawl-delay" {
+			return nil
+		}
+		secs, err := strconv.ParseFloat(value, 64)
+		if err != nil || secs < 0 || math.IsInf(secs, 0) {
+			return fmt.Errorf("line %d: invalid crawl-delay %q", line, value)
+		}
+		for _, agent := range group {
+			delays[agent] = time.Duration(secs * float64(time.Second))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return delays, nil
+}
+
+// ParseContentSignals reads the Content-Signal of each user-agent group of
+// a robots.txt
+func ParseContentSignals(r io.Reader) (ContentSignals, error) {
+	signals := ContentSignals{}
+	err := scanGroups(r, func(line int, field, value string, group []string) error {
+		if field != "content-signal" {
+			return nil
+		}
+		for _, pair := range strings.Split(value, ",") {
+			k, v, ok := strings.Cut(strings.TrimSpace(pair), "=")
+			if !ok || k == "" || (v != "yes" && v != "no") {
+				return fmt.Errorf("line %d: invalid content-signal %q", line, value)
+			}
+		}
+		for _, agent := range group {
+			signals[agent] = value
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return signals, nil
+}
+
+// scanGroups calls fn for each rule of a robots.txt with the lowercased
+// field name and the user-agents of its group
+func scanGroups(r io.Reader, fn func(line int, field, value string, group []string) error) error {
+	var group []string
+	inRules := false
+
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		text, _, _ := strings.Cut(scanner.Text(), "#")
+		field, value, ok := strings.Cut(text, ":")
+		if !ok {
+			continue
+		}
+		field = strings.ToLower(strings.TrimSpace(field))
+		value = strings.TrimSpace(value)
+
+		switch field {
+		case "user-agent":
+			// A user-agent line after rules starts a new group
+			if inRules {
+				group, inRules = nil, false
+			}
+			if value != "" {
+				group = append(group, strings.ToLower(value))
+			}
+		default:
+			inRules = true
+			if err := fn(line, field, value, group); err != nil {
+				return err
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// Pacer enforces crawl delays
+type Pacer struct {
+	delays     Delays
+	agents     []string // named agent tokens, longest first
+	aiPatterns []string // lowercased AI crawler User-Agent patterns the wildcard applies to
+	robots     []byte
+
+	signals      ContentSignals
+	signalAgents []string // named agent tokens with a content signal, longest first
+
+	mu   sync.Mutex
+	next map[string]time.Time // earliest next request per crawler and pool
+}
+
+// NewPacer creates a pacer for delays. The wildcard delay applies to
+// User-Agents containing one of aiCrawlerPatterns (lowercase).
+func NewPacer(delays Delays, aiCrawlerPatterns []string) *Pacer {
+	p := &Pacer{
+		delays:     delays,
+		aiPatterns: aiCrawlerPatterns,
+		next:       map[string]time.Time{},
+	}
+	for agent, d := range delays {
+		if agent != Wildcard && d > 0 {
+			p.agents = append(p.agents, agent)
+		}
+	}
+	sortAgents(p.agents)
+	return p
+}
+
+// sortAgents orders agent tokens so the most specific matches first, e.g.
+// "googlebot-image" before "googlebot"
+func sortAgents(agents []string) {
+	slices.SortFunc(agents, func(a, b string) int {
+		if len(a) != len(b) {
+			return len(b) - len(a)
+		}
+		return strings.Compare(a, b)
+	})
+}
+
+// SetContentSignals sets the Content-Signal policies reported by
+// ContentSignal
+func (p *Pacer) SetContentSignals(signals ContentSignals) {
+	p.signals = signals
+	p.signalAgents = nil
+	for agent := range signals {
+		if agent != Wildcard {
+			p.signalAgents = append(p.signalAgents, agent)
+		}
+	}
+	sortAgents(p.signalAgents)
+}
+
+// Load reads a robots.txt file, applies overrides and creates a pacer
+// that also serves the file (see Robots)
+func Load(path string, overrides Delays, aiCrawlerPatterns []string) (*Pacer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	delays, err := ParseRobots(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	signals, err := ParseContentSignals(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	for agent, d := range overrides {
+		delays[strings.ToLower(agent)] = d
+	}
+	p := NewPacer(delays, aiCrawlerPatterns)
+	p.SetContentSignals(signals)
+	p.robots = data
+	return p, nil
+}
+
+// ParseOverrides parses "agent=delay" pairs separated by commas, e.g.
+// "gptbot=10s,ccbot=2.5"; bare numbers are seconds
+func ParseOverrides(s string) (Delays, error) {
+	delays := Delays{}
+	for _, pair := range strings.Split(s, ",") {
+		agent, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok || agent == "" {
+			return nil, fmt.Errorf("invalid crawl delay %q, want agent=delay", pair)
+		}
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			secs, ferr := strconv.ParseFloat(value, 64)
+			if ferr != nil {
+				return nil, fmt.Errorf("invalid crawl delay %q for %s", value, agent)
+			}
+			d = time.Duration(secs * float64(time.Second))
+		}
+		if d < 0 {
+			return nil, fmt.Errorf("negative crawl delay for %s", agent)
+		}
+		delays[strings.ToLower(agent)] = d
+	}
+	return delays, nil
+}
+
+// Robots returns the robots.txt the pacer was loaded from, or nil
+func (p *Pacer) Robots() []byte {
+	return p.robots
+}
+
+// Crawler returns the crawler a User-Agent identifies and its delay. It
+// reports false for clients without a crawl delay.
+func (p *Pacer) Crawler(userAgent string) (string, time.Duration, bool) {
+	ua := strings.ToLower(userAgent)
+	for _, agent := range p.agents {
+		if strings.Contains(ua, agent) {
+			return agent, p.delays[agent], true
+		}
+	}
+	if d := p.delays[Wildcard]; d > 0 {
+		for _, pattern := range p.aiPatterns {
+			if strings.Contains(ua, pattern) {
+				return pattern, d, true
+			}
+		}
+	}
+	return "", 0, false
+}
+
+// ContentSignal returns the Content-Signal that applies to a User-Agent:
+// that of the most specific named group it contains, else that of the "*"
+// group. It returns "" when no group declares one.
+func (p *Pacer) ContentSignal(userAgent string) string {
+	ua := strings.ToLower(userAgent)
+	for _, agent := range p.signalAgents {
+		if strings.Contains(ua, agent) {
+			return p.signals[agent]
+		}
+	}
+	return p.signals[Wildcard]
+}
+
+// RobotsTag returns the X-Robots-Tag directives equivalent to a
+// Content-Signal: "noai, noimageai" when it opts out of AI training,
+// "" otherwise
+func RobotsTag(signal string) string {
+	for _, pair := range strings.Split(signal, ",") {
+		if k, v, _ := strings.Cut(strings.TrimSpace(pair), "="); k == "ai-train" && v == "no" {
+			return "noai, noimageai"
+		}
+	}
+	return ""
+}
+
+// Decision is the outcome of a crawler's request under its crawl delay
+type Decision struct {
+	Crawler string        // Agent token or AI crawler pattern the User-Agent matched
+	Delay   time.Duration // Crawl-delay of the crawler
+	Allowed bool
+	Wait    time.Duration // Until the crawler's next request is allowed
+}
+
+// Allow records a request of the crawler a User-Agent identifies at now.
+// When the crawler is ahead of its crawl delay it reports false and the
+// time to wait; the rejected request does not count.
+func (p *Pacer) Allow(userAgent string, now time.Time) (time.Duration, bool) {
+	d, ok := p.Take(userAgent, now)
+	if !ok || d.Allowed {
+		return 0, true
+	}
+	return d.Wait, false
+}
+
+// Take records a request of the crawler a User-Agent identifies at now,
+// like Allow, and reports the decision. It reports false for clients
+// without a crawl delay.
+func (p *Pacer) Take(userAgent string, now time.Time) (Decision, bool) {
+	return p.TakeIn(userAgent, "", now)
+}
+
+// TakeIn is Take with a separate crawl delay budget per pool, so that
+// senders told apart from the crawler, such as those failing its
+// verification, cannot spend the budget of the genuine one. The empty
+// pool is the one Take uses.
+func (p *Pacer) TakeIn(userAgent, pool string, now time.Time) (Decision, bool) {
+	crawler, delay, ok := p.Crawler(userAgent)
+	if !ok {
+		return Decision{}, false
+	}
+	d := Decision{Crawler: crawler, Delay: delay}
+	key := crawler
+	if pool != "" {
+		key += "|" + pool
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if next := p.next[key]; now.Before(next) {
+		d.Wait = next.Sub(now)
+		return d, true
+	}
+	p.next[key] = now.Add(delay)
+	d.Allowed, d.Wait = true, delay
+	return d, true
+}
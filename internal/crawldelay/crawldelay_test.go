@@ -0,0 +1,13 @@
+package crawldelay
+
+import "testing"
+
+// Tests are in tests/unit/crawldelay_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestCrawldelayPackage(t *testing.T) {
+	// Verify package is testable
+	if NewPacer(Delays{}, nil) == nil {
+		t.Error("NewPacer should not return nil")
+	}
+}
@@ -0,0 +1,93 @@
+package crawlerverify
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"os"
+	"strings"
+)
+
+// rangesFile is the JSON format operators publish crawler ranges in
+// (Google, OpenAI, Perplexity)
+type rangesFile struct {
+	Prefixes []struct {
+		IPv4Prefix string `json:"ipv4Prefix"`
+		IPv6Prefix string `json:"ipv6Prefix"`
+	} `json:"prefixes"`
+}
+
+// ParseRanges reads crawler IP ranges, either in the published JSON
+// format ({"prefixes":[{"ipv4Prefix":"..."}]}) or as one prefix or address
+// per line, with # comments
+func ParseRanges(r io.Reader) ([]netip.Prefix, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	var prefixes []netip.Prefix
+	if trimmed := bytes.TrimSpace(data); len(trimmed) > 0 && trimmed[0] == '{' {
+		var f rangesFile
+		if err := json.Unmarshal(trimmed, &f); err != nil {
+			return nil, err
+		}
+		for i, p := range f.Prefixes {
+			s := p.IPv4Prefix + p.IPv6Prefix
+			prefix, err := netip.ParsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("prefixes[%d]: %w", i, err)
+			}
+			prefixes = append(prefixes, prefix.Masked())
+		}
+	} else {
+		scanner := bufio.NewScanner(bytes.NewReader(data))
+		line := 0
+		for scanner.Scan() {
+			line++
+			s, _, _ := strings.Cut(scanner.Text(), "#")
+			if s = strings.TrimSpace(s); s == "" {
+				continue
+			}
+			prefix, err := parsePrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	if len(prefixes) == 0 {
+		return nil, errors.New("no IP ranges")
+	}
+	return prefixes, nil
+}
+
+// parsePrefix parses a prefix, or an address as a single-address prefix
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	return p.Masked(), err
+}
+
+// LoadRanges reads crawler IP ranges from a file
+func LoadRanges(path string) ([]netip.Prefix, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	prefixes, err := ParseRanges(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return prefixes, nil
+}
@@ -0,0 +1,256 @@
+// Package crawlerverify checks that requests whose User-Agent names a
+// documented crawler come from its operator: by reverse and forward DNS
+// for crawlers verified that way, by published IP ranges for the others.
+// A claim that fails is recorded as spoofed; crawlers without a published
+// method, and ranges not loaded, are not checked.
+package crawlerverify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Resolver looks up host names. *net.Resolver implements it.
+type Resolver interface {
+	LookupAddr(ctx context.Context, addr string) ([]string, error)
+	LookupHost(ctx context.Context, host string) ([]string, error)
+}
+
+// crawlerDomains are the domains the reverse DNS of crawler addresses
+// falls under, as documented by their operators
+var crawlerDomains = map[string][]string{
+	"Googlebot":   {"googlebot.com", "google.com", "googleusercontent.com"},
+	"GoogleOther": {"googlebot.com", "google.com", "googleusercontent.com"},
+	"Bingbot":     {"search.msn.com"},
+	"Applebot":    {"applebot.apple.com"},
+	"YandexBot":   {"yandex.ru", "yandex.net", "yandex.com"},
+	"Baiduspider": {"baidu.com", "baidu.jp"},
+	"Amazonbot":   {"crawl.amazonbot.amazon"},
+}
+
+// DefaultTTL is how long a verification outcome is reused for an address
+const DefaultTTL = time.Hour
+
+// maxCached bounds the outcomes remembered
+const maxCached = 10000
+
+// Verifier verifies crawler claims and remembers the outcome per address
+// and crawler for DefaultTTL
+type Verifier struct {
+	resolver Resolver
+	ranges   map[string][]netip.Prefix // Published ranges by crawler name
+
+	mu     sync.Mutex
+	cached map[cacheKey]outcome
+	now    func() time.Time
+}
+
+type cacheKey struct {
+	crawler string
+	addr    netip.Addr
+}
+
+type outcome struct {
+	verified bool
+	expires  time.Time
+}
+
+// New returns a verifier resolving names with resolver
+// (net.DefaultResolver when nil)
+func New(resolver Resolver) *Verifier {
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	return &Verifier{
+		resolver: resolver,
+		ranges:   map[string][]netip.Prefix{},
+		cached:   map[cacheKey]outcome{},
+		now:      time.Now,
+	}
+}
+
+// SetRanges sets the published IP ranges of a crawler verified by them.
+// It is not safe to call while requests are verified.
+func (v *Verifier) SetRanges(crawler string, prefixes []netip.Prefix) error {
+	c, ok := lookupCrawler(crawler)
+	if !ok {
+		return fmt.Errorf("unknown crawler %q", crawler)
+	}
+	if c.Verification != fingerprint.VerifyIPRanges {
+		return fmt.Errorf("%s is verified by %s, not IP ranges", c.Name, c.Verification)
+	}
+	v.ranges[c.Name] = prefixes
+	return nil
+}
+
+// Ranges returns the number of crawlers with IP ranges set
+func (v *Verifier) Ranges() int {
+	return len(v.ranges)
+}
+
+// Verify reports whether addr belongs to crawler c. checked is false when
+// the verifier has no way to tell: no published method, or ranges not set.
+// DNS failures other than a missing name are returned as errors.
+func (v *Verifier) Verify(ctx context.Context, c fingerprint.KnownCrawler, addr netip.Addr) (verified, checked bool, err error) {
+	addr = addr.Unmap()
+	switch c.Verification {
+	case fingerprint.VerifyIPRanges:
+		prefixes, ok := v.ranges[c.Name]
+		if !ok {
+			return false, false, nil
+		}
+		for _, p := range prefixes {
+			if p.Contains(addr) {
+				return true, true, nil
+			}
+		}
+		return false, true, nil
+	case fingerprint.VerifyReverseDNS:
+		domains, ok := crawlerDomains[c.Name]
+		if !ok {
+			return false, false, nil
+		}
+		key := cacheKey{c.Name, addr}
+		if verified, ok := v.cachedOutcome(key); ok {
+			return verified, true, nil
+		}
+		verified, err := v.reverseDNS(ctx, addr, domains)
+		if err != nil {
+			return false, false, err
+		}
+		v.remember(key, verified)
+		return verified, true, nil
+	}
+	return false, false, nil
+}
+
+// reverseDNS reports whether a PTR name of addr falls under one of the
+// domains and resolves back to addr
+func (v *Verifier) reverseDNS(ctx context.Context, addr netip.Addr, domains []string) (bool, error) {
+	names, err := v.resolver.LookupAddr(ctx, addr.String())
+	if err != nil {
+		return false, notFound(err)
+	}
+	for _, name := range names {
+		name = strings.TrimSuffix(strings.ToLower(name), ".")
+		if !underDomain(name, domains) {
+			continue
+		}
+		hosts, err := v.resolver.LookupHost(ctx, name)
+		if err != nil {
+			if err := notFound(err); err != nil {
+				return false, err
+			}
+			continue
+		}
+		for _, h := range hosts {
+			if a, err := netip.ParseAddr(h); err == nil && a.Unmap() == addr {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// notFound returns nil for a missing DNS name, which fails verification,
+// and err for failures that leave the outcome unknown
+func notFound(err error) error {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return nil
+	}
+	return err
+}
+
+// underDomain reports whether name is a subdomain of one of the domains
+func underDomain(name string, domains []string) bool {
+	for _, d := range domains {
+		if strings.HasSuffix(name, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+// cachedOutcome returns a remembered, unexpired outcome
+func (v *Verifier) cachedOutcome(key cacheKey) (bool, bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	o, ok := v.cached[key]
+	if !ok || v.now().After(o.expires) {
+		return false, false
+	}
+	return o.verified, true
+}
+
+// remember stores an outcome, dropping expired ones (or, failing that,
+// all) when the cache is full
+func (v *Verifier) remember(key cacheKey, verified bool) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := v.now()
+	if len(v.cached) >= maxCached {
+		for k, o := range v.cached {
+			if now.After(o.expires) {
+				delete(v.cached, k)
+			}
+		}
+		if len(v.cached) >= maxCached {
+			clear(v.cached)
+		}
+	}
+	v.cached[key] = outcome{verified: verified, expires: now.Add(DefaultTTL)}
+}
+
+// Name identifies the lookup in ClassificationResult.Incomplete
+func (v *Verifier) Name() string {
+	return "crawler-verification"
+}
+
+// Enrich verifies the crawler the User-Agent claims against the remote
+// address, recording the claim and the outcome. Loopback and private
+// addresses, such as a load balancer's, are not checked. It implements
+// classifier.Enricher.
+func (v *Verifier) Enrich(ctx context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	c, ok := fingerprint.ClaimedCrawler(fp.HTTP.UserAgent)
+	if !ok {
+		return nil
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() {
+		return nil
+	}
+	verified, checked, err := v.Verify(ctx, c, addr)
+	if err != nil || !checked {
+		return err
+	}
+	fp.Network.ClaimedCrawler = c.Name
+	fp.Network.CrawlerVerification = fingerprint.CrawlerSpoofed
+	if verified {
+		fp.Network.CrawlerVerification = fingerprint.CrawlerVerified
+	}
+	return nil
+}
+
+// lookupCrawler finds a known crawler by name, ignoring case
+func lookupCrawler(name string) (fingerprint.KnownCrawler, bool) {
+	for _, c := range fingerprint.KnownCrawlers() {
+		if strings.EqualFold(c.Name, name) {
+			return c, true
+		}
+	}
+	return fingerprint.KnownCrawler{}, false
+}
@@ -0,0 +1,13 @@
+package crawlerverify
+
+import "testing"
+
+// Tests are in tests/unit/crawlerverify_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestCrawlerverifyPackage(t *testing.T) {
+	// Verify package is testable
+	if New(nil).Name() == "" {
+		t.Error("Name() should not be empty")
+	}
+}
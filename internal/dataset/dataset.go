@@ -0,0 +1,156 @@
+// Package dataset reads and writes human-labeled fingerprints used to
+// train and evaluate the classifier.
+//
+// A dataset is a JSONL file with one Sample per line. Samples are
+// appended as they are labeled, so an interrupted labeling session keeps
+// everything recorded so far.
+package dataset
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// maxLineBytes bounds a single JSONL dataset line
+const maxLineBytes = 4 << 20
+
+// Sample is a fingerprint with a human-assigned label
+type Sample struct {
+	RequestID   string                  `json:"request_id,omitempty"`
+	Label       string                  `json:"label"`
+	Fingerprint fingerprint.Fingerprint `json:"fingerprint"`
+	Predicted   string                  `json:"predicted,omitempty"` // classification recorded in the source log
+	Source      string                  `json:"source,omitempty"`    // file the sample was taken from, or FeedbackSource
+	Note        string                  `json:"note,omitempty"`
+	LabeledAt   time.Time               `json:"labeled_at"`
+}
+
+// ValidLabel reports whether label is a classification the dataset accepts
+func ValidLabel(label string) bool {
+	return label == classifier.ClassificationBrowser || label == classifier.ClassificationBot
+}
+
+// Validate checks that the sample carries a known label
+func (s Sample) Validate() error {
+	if !ValidLabel(s.Label) {
+		return fmt.Errorf("invalid label %q", s.Label)
+	}
+	return nil
+}
+
+// Read decodes all samples from a JSONL stream, skipping blank lines
+func Read(r io.Reader) ([]Sample, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+
+	var samples []Sample
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var s Sample
+		if err := json.Unmarshal(data, &s); err != nil {
+			return nil, fmt.Errorf("invalid sample on line %d: %w", line, err)
+		}
+		if err := s.Validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		samples = append(samples, s)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return samples, nil
+}
+
+// ReadFile loads a dataset file. A missing file is an empty dataset.
+func ReadFile(path string) ([]Sample, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return Read(f)
+}
+
+// FeedbackSource is the Source of samples recorded from classifier feedback
+const FeedbackSource = "feedback"
+
+// Writer appends samples to a dataset file. It is safe for concurrent
+// use and stores classifier feedback as a classifier.FeedbackStore.
+type Writer struct {
+	mu   sync.Mutex
+	file *os.File
+	enc  *json.Encoder
+}
+
+// Append opens path for appending, creating it and its directory if needed
+func Append(path string) (*Writer, error) {
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("failed to create dataset directory: %w", err)
+		}
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open dataset: %w", err)
+	}
+	return &Writer{file: f, enc: json.NewEncoder(f)}, nil
+}
+
+// Write validates and appends a single sample
+func (w *Writer) Write(s Sample) error {
+	if err := s.Validate(); err != nil {
+		return err
+	}
+	if s.LabeledAt.IsZero() {
+		s.LabeledAt = time.Now().UTC()
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.enc.Encode(s)
+}
+
+// Store appends a correction submitted with Classifier.Feedback
+func (w *Writer) Store(f classifier.Feedback) error {
+	return w.Write(Sample{
+		RequestID:   f.RequestID,
+		Label:       f.Label,
+		Fingerprint: f.Fingerprint,
+		Predicted:   f.Predicted,
+		Source:      FeedbackSource,
+		Note:        f.Note,
+	})
+}
+
+// Close closes the underlying file
+func (w *Writer) Close() error {
+	return w.file.Close()
+}
+
+// Labeled returns the set of request IDs present in samples
+func Labeled(samples []Sample) map[string]bool {
+	ids := make(map[string]bool, len(samples))
+	for _, s := range samples {
+		if s.RequestID != "" {
+			ids[s.RequestID] = true
+		}
+	}
+	return ids
+}
@@ -0,0 +1,13 @@
+package dataset
+
+import "testing"
+
+// Tests are in tests/unit/dataset_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestDatasetPackage(t *testing.T) {
+	// Verify package is testable
+	if !ValidLabel("bot") {
+		t.Error("ValidLabel(bot) should be true")
+	}
+}
@@ -0,0 +1,67 @@
+// Package edge provides a JSON-in/JSON-out classification API for
+// embedding the classifier in edge runtimes (WASM, cgo exports).
+//
+// Edge runtimes terminate TLS before handing requests to user code, so
+// only HTTP-level signals (headers, JA4H) are available here.
+package edge
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Engine classifies requests described as JSON
+type Engine struct {
+	collector  *fingerprint.Collector
+	classifier *classifier.Classifier
+}
+
+// New creates a new edge engine
+func New(cfg classifier.Config) *Engine {
+	return &Engine{
+		collector:  fingerprint.NewCollector(),
+		classifier: classifier.New(cfg),
+	}
+}
+
+// ClassifyRequestJSON classifies a request described by RequestMetadata JSON
+// and returns the ClassificationResult as JSON
+func (e *Engine) ClassifyRequestJSON(input []byte) ([]byte, error) {
+	var meta fingerprint.RequestMetadata
+	if err := json.Unmarshal(input, &meta); err != nil {
+		return nil, fmt.Errorf("invalid request JSON: %w", err)
+	}
+
+	req, err := meta.HTTPRequest(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	result := e.classifier.Classify(e.collector.Collect(req))
+	return json.Marshal(result)
+}
+
+// ClassifyFingerprintJSON classifies a Fingerprint JSON document
+// and returns the ClassificationResult as JSON
+func (e *Engine) ClassifyFingerprintJSON(input []byte) ([]byte, error) {
+	var fp fingerprint.Fingerprint
+	if err := json.Unmarshal(input, &fp); err != nil {
+		return nil, fmt.Errorf("invalid fingerprint JSON: %w", err)
+	}
+
+	result := e.classifier.Classify(fp)
+	return json.Marshal(result)
+}
+
+// ErrorJSON renders an error as a JSON object for callers across the
+// WASM/C boundary that cannot receive Go errors
+func ErrorJSON(err error) []byte {
+	out, _ := json.Marshal(struct {
+		Error string `json:"error"`
+	}{Error: err.Error()})
+	return out
+}
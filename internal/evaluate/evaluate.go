@@ -0,0 +1,220 @@
+// Package evaluate measures classifier accuracy against a labeled dataset.
+//
+// It reports per-class precision, recall and F1, the confusion matrix, how
+// well confidence matches accuracy, and a per-signal ablation showing how
+// the bot F1 score changes when each scoring rule is removed.
+package evaluate
+
+import (
+	"sort"
+
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// CalibrationBins is the number of confidence bins of the ECE
+const CalibrationBins = 10
+
+// Classes lists the labels evaluated, in report order
+var Classes = []string{classifier.ClassificationBrowser, classifier.ClassificationBot}
+
+// Confusion counts samples by actual label, then predicted classification
+type Confusion map[string]map[string]int
+
+// ClassMetrics holds one-vs-rest metrics for a single class
+type ClassMetrics struct {
+	Class     string  `json:"class"`
+	Precision float64 `json:"precision"`
+	Recall    float64 `json:"recall"`
+	F1        float64 `json:"f1"`
+	Support   int     `json:"support"` // samples labeled with this class
+}
+
+// Ablation reports the effect of removing one scoring rule
+type Ablation struct {
+	Signal   string  `json:"signal"`
+	Side     string  `json:"side"` // class the rule scores towards
+	Weight   int     `json:"weight"`
+	Fired    int     `json:"fired"`   // samples where the rule contributed
+	Flipped  int     `json:"flipped"` // samples whose classification changes without it
+	Accuracy float64 `json:"accuracy"`
+	BotF1    float64 `json:"bot_f1"`
+	DeltaF1  float64 `json:"delta_f1"` // BotF1 minus the baseline bot F1
+}
+
+// Report is the result of an evaluation run
+type Report struct {
+	Samples   int            `json:"samples"`
+	Backend   string         `json:"backend"`
+	Threshold int            `json:"threshold"`
+	Accuracy  float64        `json:"accuracy"`
+	Classes   []ClassMetrics `json:"classes"`
+	Confusion Confusion      `json:"confusion"`
+	Ablation  []Ablation     `json:"ablation,omitempty"`
+
+	// Calibration of the reported confidence against accuracy: expected
+	// calibration error over CalibrationBins bins and Brier score
+	ECE   float64 `json:"ece"`
+	Brier float64 `json:"brier"`
+}
+
+// Class returns the metrics of the named class
+func (r Report) Class(name string) ClassMetrics {
+	for _, m := range r.Classes {
+		if m.Class == name {
+			return m
+		}
+	}
+	return ClassMetrics{Class: name}
+}
+
+// scored is a sample reduced to what metrics and ablation need
+type scored struct {
+	label         string
+	net           int
+	contributions fingerprint.Breakdown
+}
+
+// Run classifies every sample with a classifier built from cfg and
+// computes the report, including per-signal ablation. Ablation removes
+// scoring rules, so it is left out when a model decides.
+func Run(samples []dataset.Sample, cfg classifier.Config) Report {
+	c := classifier.New(cfg)
+
+	rows := make([]scored, len(samples))
+	predicted := make([]string, len(samples))
+	confidence := make([]calibration.Sample, len(samples))
+	for i, s := range samples {
+		result := c.Classify(s.Fingerprint)
+		confidence[i] = calibration.Sample{Confidence: result.Confidence, Correct: result.Classification == s.Label}
+		rows[i] = scored{
+			label:         s.Label,
+			net:           result.Score,
+			contributions: result.Signals.ScoreBreakdown,
+		}
+		predicted[i] = result.Classification
+	}
+
+	report := metrics(rows, predicted)
+	report.Backend = c.Backend()
+	report.Threshold = cfg.Threshold
+	report.ECE = calibration.ECE(confidence, CalibrationBins)
+	report.Brier = calibration.Brier(confidence)
+	if report.Backend == classifier.BackendML {
+		return report
+	}
+	report.Ablation = ablate(rows, predicted, cfg.Threshold, report.Class(classifier.ClassificationBot).F1)
+	return report
+}
+
+// metrics computes accuracy, per-class metrics and the confusion matrix
+func metrics(rows []scored, predicted []string) Report {
+	confusion := Confusion{}
+	for _, actual := range Classes {
+		confusion[actual] = map[string]int{}
+		for _, pred := range Classes {
+			confusion[actual][pred] = 0
+		}
+	}
+
+	correct := 0
+	for i, row := range rows {
+		if confusion[row.label] == nil {
+			confusion[row.label] = map[string]int{}
+		}
+		confusion[row.label][predicted[i]]++
+		if row.label == predicted[i] {
+			correct++
+		}
+	}
+
+	report := Report{Samples: len(rows), Confusion: confusion}
+	if len(rows) > 0 {
+		report.Accuracy = float64(correct) / float64(len(rows))
+	}
+
+	for _, class := range Classes {
+		tp := confusion[class][class]
+		predictedAs, support := 0, 0
+		for _, other := range Classes {
+			predictedAs += confusion[other][class]
+			support += confusion[class][other]
+		}
+
+		m := ClassMetrics{Class: class, Support: support}
+		if predictedAs > 0 {
+			m.Precision = float64(tp) / float64(predictedAs)
+		}
+		if support > 0 {
+			m.Recall = float64(tp) / float64(support)
+		}
+		if m.Precision+m.Recall > 0 {
+			m.F1 = 2 * m.Precision * m.Recall / (m.Precision + m.Recall)
+		}
+		report.Classes = append(report.Classes, m)
+	}
+	return report
+}
+
+// ablate recomputes metrics with each scoring rule removed in turn.
+// Scores are additive, so dropping a rule only shifts the net score by its
+// weight. Results are sorted by the largest loss in bot F1 first.
+func ablate(rows []scored, predicted []string, threshold int, baseF1 float64) []Ablation {
+	type rule struct{ name, side string }
+	weights := map[rule]int{}
+	for _, row := range rows {
+		for _, c := range row.contributions {
+			weights[rule{c.Name, c.Direction}] = c.Weight
+		}
+	}
+
+	var results []Ablation
+	ablated := make([]string, len(rows))
+	for r, weight := range weights {
+		fired, flipped := 0, 0
+		for i, row := range rows {
+			ablated[i] = predicted[i]
+			for _, c := range row.contributions {
+				if c.Name != r.name || c.Direction != r.side {
+					continue
+				}
+				fired++
+				net := row.net - c.Weight
+				if c.Direction == fingerprint.DirectionBot {
+					net = row.net + c.Weight
+				}
+				ablated[i] = classifier.ClassificationBot
+				if net >= threshold {
+					ablated[i] = classifier.ClassificationBrowser
+				}
+				if ablated[i] != predicted[i] {
+					flipped++
+				}
+				break
+			}
+		}
+
+		m := metrics(rows, ablated)
+		botF1 := m.Class(classifier.ClassificationBot).F1
+		results = append(results, Ablation{
+			Signal:   r.name,
+			Side:     r.side,
+			Weight:   weight,
+			Fired:    fired,
+			Flipped:  flipped,
+			Accuracy: m.Accuracy,
+			BotF1:    botF1,
+			DeltaF1:  botF1 - baseF1,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].DeltaF1 != results[j].DeltaF1 {
+			return results[i].DeltaF1 < results[j].DeltaF1
+		}
+		return results[i].Signal < results[j].Signal
+	})
+	return results
+}
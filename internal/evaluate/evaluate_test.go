@@ -0,0 +1,13 @@
+package evaluate
+
+import "testing"
+
+// Tests are in tests/unit/evaluate_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestEvaluatePackage(t *testing.T) {
+	// Verify package is testable
+	if len(Classes) != 2 {
+		t.Error("Classes should list browser and bot")
+	}
+}
@@ -0,0 +1,208 @@
+// Package events publishes the classifier's decisions to other systems.
+//
+// A Bus fans events out to in-process subscribers (channels) and to sinks
+// that forward them out of process (NATS, Kafka REST Proxy). Publishing
+// never blocks classification: each subscriber and sink has a bounded
+// queue, and events for one that falls behind are dropped and counted.
+package events
+
+import (
+	"errors"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// DefaultBuffer is the number of events queued per subscriber or sink
+const DefaultBuffer = 1024
+
+// Type identifies what happened to a request
+type Type string
+
+// Event types
+const (
+	Classified Type = "classified" // Every classification result
+	Blocked    Type = "blocked"    // Results classified as bot
+	Challenged Type = "challenged" // Bots sent a CAPTCHA or Private Access Token challenge
+
+	// ReputationThresholdCrossed is published when a client's accumulated
+	// reputation crosses a configured threshold
+	ReputationThresholdCrossed Type = "reputation_threshold_crossed"
+)
+
+// Challenge actions of Challenged events
+const (
+	ActionCaptcha      = "captcha"
+	ActionPrivateToken = "private_token"
+)
+
+// Event is a structured record of a detector decision
+type Event struct {
+	Type           Type      `json:"type"`
+	Time           time.Time `json:"time"`
+	RequestID      string    `json:"request_id,omitempty"`
+	Tenant         string    `json:"tenant,omitempty"`
+	ClientAddr     string    `json:"client_addr,omitempty"` // As logged (anonymized when enabled)
+	Classification string    `json:"classification,omitempty"`
+	Confidence     float64   `json:"confidence,omitempty"`
+	BotScore       int       `json:"bot_score,omitempty"` // Cloudflare-compatible 1-99
+	Reason         string    `json:"reason,omitempty"`
+	JA4            string    `json:"ja4,omitempty"`
+	Action         string    `json:"action,omitempty"` // Challenge kind for Challenged events
+}
+
+// New creates an event of the given type for a classification result
+func New(typ Type, result fingerprint.ClassificationResult) Event {
+	return Event{
+		Type:           typ,
+		Time:           result.Timestamp,
+		RequestID:      result.RequestID,
+		Classification: result.Classification,
+		Confidence:     result.Confidence,
+		BotScore:       classifier.BotScore(result),
+		Reason:         result.Reason,
+		JA4:            result.Fingerprint.TLS.JA4Hash,
+	}
+}
+
+// Sink forwards events out of process. Send is called from a single
+// goroutine per sink, in publishing order.
+type Sink interface {
+	Send(e Event) error
+	Close() error
+}
+
+// Bus fans out events to subscribers and sinks
+type Bus struct {
+	mu      sync.Mutex
+	subs    map[chan Event]struct{}
+	sinks   []*sinkQueue
+	closed  bool
+	dropped atomic.Int64
+	wg      sync.WaitGroup
+}
+
+// sinkQueue is the bounded queue in front of a sink
+type sinkQueue struct {
+	sink Sink
+	ch   chan Event
+}
+
+// NewBus creates an event bus without subscribers or sinks
+func NewBus() *Bus {
+	return &Bus{subs: map[chan Event]struct{}{}}
+}
+
+// Subscribe returns a channel receiving every event published from now on,
+// and a function that unsubscribes it. Events are dropped while the
+// channel's buffer is full. The channel is closed on unsubscribe and when
+// the bus is closed.
+func (b *Bus) Subscribe(buffer int) (<-chan Event, func()) {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	ch := make(chan Event, buffer)
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		close(ch)
+		return ch, func() {}
+	}
+	b.subs[ch] = struct{}{}
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subs[ch]; ok {
+			delete(b.subs, ch)
+			close(ch)
+		}
+	}
+}
+
+// AddSink forwards events to s from a goroutine of its own, queueing up
+// to buffer events. The sink is closed with the bus.
+func (b *Bus) AddSink(s Sink, buffer int) {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	q := &sinkQueue{sink: s, ch: make(chan Event, buffer)}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.sinks = append(b.sinks, q)
+	b.wg.Go(func() { b.drain(q) })
+}
+
+// drain sends queued events to a sink until its queue is closed. Failures
+// are logged once per streak so an unreachable broker does not flood the
+// log.
+func (b *Bus) drain(q *sinkQueue) {
+	failing := false
+	for e := range q.ch {
+		err := q.sink.Send(e)
+		if err != nil && !failing {
+			log.Printf("Event sink error: %v", err)
+		} else if err == nil && failing {
+			log.Printf("Event sink recovered")
+		}
+		failing = err != nil
+	}
+}
+
+// Publish sends an event to every subscriber and sink without blocking
+func (b *Bus) Publish(e Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		return
+	}
+	for ch := range b.subs {
+		select {
+		case ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+	for _, q := range b.sinks {
+		select {
+		case q.ch <- e:
+		default:
+			b.dropped.Add(1)
+		}
+	}
+}
+
+// Dropped returns the number of events dropped for subscribers or sinks
+// that fell behind
+func (b *Bus) Dropped() int64 {
+	return b.dropped.Load()
+}
+
+// Close stops publishing, closes subscriber channels, waits for sinks to
+// send their queued events and closes them
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	if b.closed {
+		b.mu.Unlock()
+		return nil
+	}
+	b.closed = true
+	for ch := range b.subs {
+		close(ch)
+	}
+	b.subs = nil
+	for _, q := range b.sinks {
+		close(q.ch)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	var errs []error
+	for _, q := range b.sinks {
+		errs = append(errs, q.sink.Close())
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,80 @@
+package events
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// kafkaContentType is the REST Proxy v2 embedded-JSON media type
+const kafkaContentType = "application/vnd.kafka.json.v2+json"
+
+// KafkaREST produces events as JSON records to a Kafka topic through a
+// Confluent-compatible REST Proxy. Records are keyed by client address so
+// a client's events stay in order on one partition.
+type KafkaREST struct {
+	url    string
+	client *http.Client
+}
+
+// kafkaRecords is the REST Proxy produce request body
+type kafkaRecords struct {
+	Records []kafkaRecord `json:"records"`
+}
+
+type kafkaRecord struct {
+	Key   string `json:"key,omitempty"`
+	Value Event  `json:"value"`
+}
+
+// NewKafkaREST creates a Kafka sink for a REST Proxy base URL and topic
+func NewKafkaREST(baseURL, topic string) (*KafkaREST, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("events: invalid Kafka REST Proxy URL %q", baseURL)
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("events: Kafka topic is required")
+	}
+	return &KafkaREST{
+		url:    strings.TrimSuffix(baseURL, "/") + "/topics/" + url.PathEscape(topic),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}, nil
+}
+
+// Send produces an event
+func (k *KafkaREST) Send(e Event) error {
+	body, err := json.Marshal(kafkaRecords{Records: []kafkaRecord{{Key: e.ClientAddr, Value: e}}})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, k.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", kafkaContentType)
+	req.Header.Set("Accept", "application/vnd.kafka.v2+json")
+
+	resp, err := k.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("kafka produce: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("kafka produce: %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
+
+// Close releases idle connections
+func (k *KafkaREST) Close() error {
+	k.client.CloseIdleConnections()
+	return nil
+}
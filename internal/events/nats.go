@@ -0,0 +1,175 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// natsDefaultPort is the NATS client port
+const natsDefaultPort = "4222"
+
+// natsTimeout bounds connecting and writing to the NATS server
+const natsTimeout = 5 * time.Second
+
+// NATS publishes events as JSON to a subject of a NATS server, speaking
+// the core text protocol (CONNECT, PUB, PING/PONG). It connects lazily
+// and reconnects on the next event after a failure. TLS is not supported.
+type NATS struct {
+	addr    string
+	subject string
+	connect []byte // CONNECT line sent after the server's INFO
+
+	mu   sync.Mutex
+	conn net.Conn
+	w    *bufio.Writer
+}
+
+// NewNATS creates a NATS sink for a server URL (nats://[user:pass@]host[:port],
+// or nats://token@host) and subject
+func NewNATS(rawURL, subject string) (*NATS, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("events: invalid NATS URL: %w", err)
+	}
+	if u.Scheme != "nats" || u.Host == "" {
+		return nil, fmt.Errorf("events: invalid NATS URL %q, want nats://host[:port]", rawURL)
+	}
+	if subject == "" || strings.ContainsAny(subject, " \t\r\n") {
+		return nil, fmt.Errorf("events: invalid NATS subject %q", subject)
+	}
+
+	addr := u.Host
+	if u.Port() == "" {
+		addr = net.JoinHostPort(u.Hostname(), natsDefaultPort)
+	}
+	opts := map[string]any{
+		"verbose":  false,
+		"pedantic": false,
+		"lang":     "go",
+		"name":     "go-client-classifier",
+	}
+	if u.User != nil {
+		if pass, ok := u.User.Password(); ok {
+			opts["user"], opts["pass"] = u.User.Username(), pass
+		} else {
+			opts["auth_token"] = u.User.Username()
+		}
+	}
+	line, err := json.Marshal(opts)
+	if err != nil {
+		return nil, err
+	}
+	return &NATS{
+		addr:    addr,
+		subject: subject,
+		connect: append(append([]byte("CONNECT "), line...), "\r\n"...),
+	}, nil
+}
+
+// Send publishes an event
+func (n *NATS) Send(e Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		if err := n.dial(); err != nil {
+			return err
+		}
+	}
+	_ = n.conn.SetWriteDeadline(time.Now().Add(natsTimeout))
+	fmt.Fprintf(n.w, "PUB %s %d\r\n", n.subject, len(data))
+	_, _ = n.w.Write(data)
+	_, _ = n.w.WriteString("\r\n")
+	if err := n.w.Flush(); err != nil {
+		n.reset()
+		return fmt.Errorf("nats publish: %w", err)
+	}
+	return nil
+}
+
+// dial connects and performs the handshake. n.mu must be held.
+func (n *NATS) dial() error {
+	conn, err := net.DialTimeout("tcp", n.addr, natsTimeout)
+	if err != nil {
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	_ = conn.SetDeadline(time.Now().Add(natsTimeout))
+	r := bufio.NewReader(conn)
+	info, err := r.ReadString('\n')
+	if err != nil || !strings.HasPrefix(info, "INFO ") {
+		_ = conn.Close()
+		return errors.New("nats connect: no INFO from server")
+	}
+	if strings.Contains(info, `"tls_required":true`) {
+		_ = conn.Close()
+		return errors.New("nats connect: server requires TLS")
+	}
+	if _, err := conn.Write(n.connect); err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("nats connect: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+
+	n.conn, n.w = conn, bufio.NewWriter(conn)
+	go n.read(conn, r)
+	return nil
+}
+
+// read answers server PINGs and drops the connection on errors, until
+// the connection is closed
+func (n *NATS) read(conn net.Conn, r *bufio.Reader) {
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			break
+		}
+		switch {
+		case strings.HasPrefix(line, "PING"):
+			n.mu.Lock()
+			if n.conn == conn {
+				_, _ = n.w.WriteString("PONG\r\n")
+				_ = n.w.Flush()
+			}
+			n.mu.Unlock()
+		case strings.HasPrefix(line, "-ERR"):
+			log.Printf("NATS server error: %s", strings.TrimSpace(line))
+		}
+	}
+	n.mu.Lock()
+	if n.conn == conn {
+		n.reset()
+	}
+	n.mu.Unlock()
+}
+
+// reset closes the connection so the next Send reconnects. n.mu must be
+// held.
+func (n *NATS) reset() {
+	if n.conn != nil {
+		_ = n.conn.Close()
+	}
+	n.conn, n.w = nil, nil
+}
+
+// Close closes the connection
+func (n *NATS) Close() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.conn == nil {
+		return nil
+	}
+	err := n.conn.Close()
+	n.conn, n.w = nil, nil
+	return err
+}
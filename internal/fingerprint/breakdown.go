@@ -0,0 +1,134 @@
+package fingerprint
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+)
+
+// Directions a scoring rule can push the decision
+const (
+	DirectionBrowser = "browser"
+	DirectionBot     = "bot"
+)
+
+// SignalContribution is a scoring rule that fired for a request
+type SignalContribution struct {
+	Name      string `json:"name"`      // Rule name, e.g. "sec-fetch"
+	Direction string `json:"direction"` // DirectionBrowser or DirectionBot
+	Weight    int    `json:"weight"`    // Points in effect when the request was scored
+}
+
+// Breakdown lists the rules that fired: browser rules first, then bot
+// rules, each in scoring order. It encodes as a JSON array (never null)
+// and also decodes the text form of older logs,
+// "BROWSER[http2(+2) sec-fetch(+3)] BOT[bot-ua(+3)]".
+type Breakdown []SignalContribution
+
+// RuleScore is a fired rule with the points it scored
+type RuleScore struct {
+	Name   string `json:"name"`
+	Weight int    `json:"weight"`
+}
+
+// ParseBreakdown reads a breakdown in the text form of older logs
+func ParseBreakdown(text string) Breakdown {
+	var b Breakdown
+	direction := DirectionBrowser
+	for _, field := range strings.Fields(text) {
+		if rest, ok := strings.CutPrefix(field, "BROWSER["); ok {
+			direction, field = DirectionBrowser, rest
+		} else if rest, ok := strings.CutPrefix(field, "BOT["); ok {
+			direction, field = DirectionBot, rest
+		}
+		field = strings.TrimSuffix(field, "]")
+		open := strings.LastIndex(field, "(")
+		if open <= 0 {
+			continue
+		}
+		w, _ := strconv.Atoi(strings.TrimSuffix(field[open+1:], ")"))
+		b = append(b, SignalContribution{Name: field[:open], Direction: direction, Weight: w})
+	}
+	return b
+}
+
+// String renders the breakdown in its text form, e.g.
+// "BROWSER[http2(+2) sec-fetch(+3)] BOT[bot-ua(+3)]"
+func (b Breakdown) String() string {
+	var sb strings.Builder
+	for i, direction := range []string{DirectionBrowser, DirectionBot} {
+		if i > 0 {
+			sb.WriteString("] ")
+		}
+		sb.WriteString(strings.ToUpper(direction) + "[")
+		first := true
+		for _, c := range b {
+			if c.Direction != direction {
+				continue
+			}
+			if !first {
+				sb.WriteByte(' ')
+			}
+			first = false
+			sign := "+"
+			if c.Weight < 0 {
+				sign = ""
+			}
+			sb.WriteString(c.Name + "(" + sign + strconv.Itoa(c.Weight) + ")")
+		}
+	}
+	sb.WriteByte(']')
+	return sb.String()
+}
+
+// Rules returns the names of the fired rules, split by direction
+func (b Breakdown) Rules() (browser, bot []string) {
+	for _, c := range b {
+		if c.Direction == DirectionBot {
+			bot = append(bot, c.Name)
+		} else {
+			browser = append(browser, c.Name)
+		}
+	}
+	return browser, bot
+}
+
+// Scores returns the fired rules with their points, split by direction
+func (b Breakdown) Scores() (browser, bot []RuleScore) {
+	for _, c := range b {
+		rs := RuleScore{Name: c.Name, Weight: c.Weight}
+		if c.Direction == DirectionBot {
+			bot = append(bot, rs)
+		} else {
+			browser = append(browser, rs)
+		}
+	}
+	return browser, bot
+}
+
+// MarshalJSON encodes the breakdown as an array, empty rather than null
+func (b Breakdown) MarshalJSON() ([]byte, error) {
+	if b == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal([]SignalContribution(b))
+}
+
+// UnmarshalJSON decodes an array of contributions or the text form
+func (b *Breakdown) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte(`"`)) {
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return err
+		}
+		*b = ParseBreakdown(text)
+		return nil
+	}
+	var cs []SignalContribution
+	if err := json.Unmarshal(data, &cs); err != nil {
+		return err
+	}
+	*b = cs
+	return nil
+}
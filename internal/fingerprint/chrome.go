@@ -0,0 +1,147 @@
+package fingerprint
+
+import (
+	"strconv"
+	"strings"
+)
+
+// ChromeVersion is the Chrome version a request claims.
+//
+// Since User-Agent reduction (Chrome 101-113) Chrome sends a frozen
+// "Chrome/<major>.0.0.0" version and one fixed platform token per OS, so
+// only the major version and the OS family can be read from the
+// User-Agent. The full version is only available from the
+// Sec-CH-UA-Full-Version-List client hint.
+type ChromeVersion struct {
+	Major           int    // Major version
+	Full            string // Full version, e.g. "124.0.6367.91" ("124.0.0.0" when reduced and no hint)
+	Reduced         bool   // User-Agent carries the frozen minor version
+	FrozenPlatform  bool   // Platform token is a fixed reduction value; its OS version is meaningless
+	FromClientHints bool   // Major and Full come from Sec-CH-UA-Full-Version-List
+}
+
+// BrandVersion is one entry of a Sec-CH-UA or Sec-CH-UA-Full-Version-List header
+type BrandVersion struct {
+	Brand   string `json:"brand"`
+	Version string `json:"version,omitempty"`
+}
+
+// frozenPlatforms are the platform tokens of reduced Chrome User-Agents
+var frozenPlatforms = []string{
+	"(Windows NT 10.0; Win64; x64)",
+	"(Macintosh; Intel Mac OS X 10_15_7)",
+	"(X11; Linux x86_64)",
+	"(X11; CrOS x86_64 14541.0.0)",
+	"(Linux; Android 10; K)",
+	"(Fuchsia)",
+}
+
+// chromeBrands are the client hint brands naming the Chrome version, in preference order
+var chromeBrands = []string{"Google Chrome", "Chromium"}
+
+// ChromeVersion returns the Chrome version claimed by the User-Agent,
+// refined by Sec-CH-UA-Full-Version-List when the client sent it
+func (h HTTPFingerprint) ChromeVersion() (ChromeVersion, bool) {
+	return ParseChromeVersion(h.UserAgent, h.Headers["sec-ch-ua-full-version-list"])
+}
+
+// ParseChromeVersion parses the Chrome version of a User-Agent and an
+// optional Sec-CH-UA-Full-Version-List value. It reports false if the
+// User-Agent does not claim Chrome.
+func ParseChromeVersion(userAgent, fullVersionList string) (ChromeVersion, bool) {
+	i := strings.Index(userAgent, "Chrome/")
+	if i < 0 {
+		return ChromeVersion{}, false
+	}
+	full := userAgent[i+len("Chrome/"):]
+	if end := strings.IndexAny(full, " ;)"); end >= 0 {
+		full = full[:end]
+	}
+	majorStr, rest, _ := strings.Cut(full, ".")
+	major, err := strconv.Atoi(majorStr)
+	if err != nil {
+		return ChromeVersion{}, false
+	}
+
+	v := ChromeVersion{
+		Major:   major,
+		Full:    full,
+		Reduced: rest == "0.0.0",
+	}
+	for _, p := range frozenPlatforms {
+		if strings.Contains(userAgent, p) {
+			v.FrozenPlatform = true
+			break
+		}
+	}
+
+	brands := ParseBrandList(fullVersionList)
+	for _, want := range chromeBrands {
+		for _, b := range brands {
+			if b.Brand != want {
+				continue
+			}
+			m, err := strconv.Atoi(strings.SplitN(b.Version, ".", 2)[0])
+			if err != nil {
+				continue
+			}
+			v.Major, v.Full, v.FromClientHints = m, b.Version, true
+			return v, true
+		}
+	}
+	return v, true
+}
+
+// IsGreaseBrand reports whether a client hint brand is a GREASE
+// placeholder such as "Not/A)Brand"
+func IsGreaseBrand(brand string) bool {
+	return strings.HasPrefix(brand, "Not") && strings.HasSuffix(brand, "Brand")
+}
+
+// ParseBrandList parses a Sec-CH-UA style structured header list, e.g.
+// `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`,
+// dropping GREASE brands
+func ParseBrandList(header string) []BrandVersion {
+	var brands []BrandVersion
+	for _, item := range splitOutsideQuotes(header, ',') {
+		params := splitOutsideQuotes(item, ';')
+		b := BrandVersion{Brand: unquote(params[0])}
+		for _, p := range params[1:] {
+			if k, val, ok := strings.Cut(p, "="); ok && strings.TrimSpace(k) == "v" {
+				b.Version = unquote(val)
+			}
+		}
+		if b.Brand == "" || IsGreaseBrand(b.Brand) {
+			continue
+		}
+		brands = append(brands, b)
+	}
+	return brands
+}
+
+// splitOutsideQuotes splits s on sep, ignoring separators inside quoted strings
+func splitOutsideQuotes(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
+
+// unquote trims whitespace and surrounding quotes of a structured header string
+func unquote(s string) string {
+	s = strings.TrimSpace(s)
+	if len(s) >= 2 && s[0] == '"' && s[len(s)-1] == '"' {
+		s = strings.ReplaceAll(s[1:len(s)-1], `\"`, `"`)
+	}
+	return s
+}
@@ -0,0 +1,106 @@
+package fingerprint
+
+import (
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// parseClientHints reads the User-Agent Client Hints of a request. Brands
+// are parsed with ParseBrandList, strings are unquoted and Mobile is the
+// structured boolean "?1".
+func parseClientHints(h http.Header) ClientHints {
+	return ClientHints{
+		Brands:          ParseBrandList(h.Get("Sec-CH-UA")),
+		FullVersionList: ParseBrandList(h.Get("Sec-CH-UA-Full-Version-List")),
+		Platform:        unquote(h.Get("Sec-CH-UA-Platform")),
+		PlatformVersion: unquote(h.Get("Sec-CH-UA-Platform-Version")),
+		Mobile:          strings.TrimSpace(h.Get("Sec-CH-UA-Mobile")) == "?1",
+		Model:           unquote(h.Get("Sec-CH-UA-Model")),
+		Arch:            unquote(h.Get("Sec-CH-UA-Arch")),
+	}
+}
+
+// Sent reports whether the request carried any of the parsed hints
+func (c ClientHints) Sent() bool {
+	return len(c.Brands) > 0 || len(c.FullVersionList) > 0 || c.Platform != "" ||
+		c.PlatformVersion != "" || c.Mobile || c.Model != "" || c.Arch != ""
+}
+
+// platformTokens are the User-Agent tokens of each Sec-CH-UA-Platform
+// value. Chrome's "request desktop site" on Android sends a Linux
+// User-Agent, so Android accepts both.
+var platformTokens = map[string][]string{
+	"Windows":   {"Windows NT"},
+	"macOS":     {"Macintosh"},
+	"Linux":     {"Linux"},
+	"Android":   {"Android", "Linux"},
+	"Chrome OS": {"CrOS"},
+	"Fuchsia":   {"Fuchsia"},
+}
+
+// desktopPlatforms never report a device model
+var desktopPlatforms = []string{"Windows", "macOS", "Linux", "Chrome OS"}
+
+// clientHintsMismatch returns the lowercased name of the Client Hint that
+// contradicts the User-Agent, or "" when they agree or were not sent. Only
+// Chromium-based browsers send hints, and they derive both from the same
+// values.
+func clientHintsMismatch(h HTTPFingerprint) string {
+	ch, ua := h.ClientHints, h.UserAgent
+	if !ch.Sent() {
+		return ""
+	}
+	uaVersion, chromium := ParseChromeVersion(ua, "")
+	if !chromium {
+		return "sec-ch-ua"
+	}
+	major := brandMajor(ch.Brands)
+	if major > 0 && major != uaVersion.Major {
+		return "sec-ch-ua"
+	}
+	if full := brandMajor(ch.FullVersionList); full > 0 && full != uaVersion.Major {
+		return "sec-ch-ua-full-version-list"
+	}
+	if tokens, ok := platformTokens[ch.Platform]; ok && !containsAny(ua, tokens) {
+		return "sec-ch-ua-platform"
+	}
+	if ch.Platform == "Linux" && strings.Contains(ua, "Android") {
+		return "sec-ch-ua-platform"
+	}
+	if mobileUA := strings.Contains(ua, " Mobile"); ch.Mobile && !mobileUA ||
+		!ch.Mobile && mobileUA && strings.Contains(ua, "Android") {
+		return "sec-ch-ua-mobile"
+	}
+	if ch.Model != "" && slices.Contains(desktopPlatforms, ch.Platform) {
+		return "sec-ch-ua-model"
+	}
+	return ""
+}
+
+// brandMajor returns the major version of the Chromium brand of a brand
+// list, or 0 when it has none
+func brandMajor(brands []BrandVersion) int {
+	for _, want := range chromeBrands {
+		for _, b := range brands {
+			if b.Brand != want {
+				continue
+			}
+			if m, err := strconv.Atoi(strings.SplitN(b.Version, ".", 2)[0]); err == nil {
+				return m
+			}
+		}
+	}
+	return 0
+}
+
+// containsAny reports whether s contains any of the substrings
+func containsAny(s string, substrs []string) bool {
+	for _, sub := range substrs {
+		if strings.Contains(s, sub) {
+			return true
+		}
+	}
+	return false
+}
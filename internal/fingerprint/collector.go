@@ -4,6 +4,7 @@ import (
 	"crypto/tls"
 	"fmt"
 	"net/http"
+	"slices"
 	"strings"
 
 	"github.com/psanford/tlsfingerprint"
@@ -28,12 +29,32 @@ func NewCollector() *Collector {
 // Collect extracts fingerprint from an HTTP request
 func (c *Collector) Collect(r *http.Request) Fingerprint {
 	fp := Fingerprint{
-		TLS:  c.collectTLS(r),
-		HTTP: c.collectHTTP(r),
+		TLS:     c.collectTLS(r),
+		HTTP:    c.collectHTTP(r),
+		Network: NetworkFingerprint{Local: isLocalAddr(r.RemoteAddr)},
 	}
 
-	// Compute JA4H fingerprint
-	fp.HTTP.JA4HHash = JA4H(r)
+	// SYN saved by the server's listener, when TCP fingerprinting is on
+	if tcp, ok := r.Context().Value(ContextKeyTCP).(TCPFingerprint); ok {
+		fp.TCP = tcp
+	}
+
+	// Transport parameters from the QUIC Initial packets, for HTTP/3
+	if quic, ok := r.Context().Value(ContextKeyQUIC).(QUICFingerprint); ok {
+		fp.QUIC = quic
+	}
+
+	// Handshake latency measured by the server's listener, for JA4L
+	if src, ok := r.Context().Value(ContextKeyLatency).(LatencySource); ok && r.TLS != nil {
+		applyLatency(&fp.TLS, src.HandshakeLatency(), fp.TCP.TTL)
+	}
+
+	// Compute JA4H fingerprint, from the wire header order when captured
+	if names := fp.HTTP.RawHeaderNames; len(names) > 0 {
+		fp.HTTP.JA4HHash = JA4HOrdered(r, names)
+	} else {
+		fp.HTTP.JA4HHash = JA4H(r)
+	}
 
 	return fp
 }
@@ -56,29 +77,80 @@ func (c *Collector) collectTLS(r *http.Request) TLSFingerprint {
 
 	// Try to get ClientHello fingerprint from context (set by fingerprintlistener)
 	if clientHelloFP := c.getClientHelloFingerprint(r); clientHelloFP != nil {
-		// Populate fields from ClientHello
-		fp.CipherSuitesCount = len(clientHelloFP.CipherSuites)
-		fp.ExtensionsCount = len(clientHelloFP.Extensions)
-		fp.HasSessionTicket = containsExtension(clientHelloFP.Extensions, 35) // session_ticket extension
-
-		// Supported versions from ClientHello
-		fp.SupportedVersions = formatTLSVersions(clientHelloFP.Version, clientHelloFP.RawVersion)
+		applyClientHello(&fp, clientHelloFP)
+	}
 
-		// Signature schemes
-		fp.SignatureSchemes = formatSignatureSchemes(clientHelloFP.SignatureAlgorithms)
+	// ServerHello recorded by the server's listener during the handshake
+	if src, ok := r.Context().Value(ContextKeyServerHello).(ServerHelloSource); ok {
+		if sh := src.ServerHello(); sh != nil {
+			applyServerHello(&fp, sh)
+		}
+	}
 
-		// Supported groups (elliptic curves)
-		fp.SupportedGroups = formatSupportedGroups(clientHelloFP.SupportedGroups)
+	return fp
+}
 
-		// JA3/JA4 fingerprints
-		fp.JA3Hash = clientHelloFP.JA3Hash()
-		fp.JA4Hash = clientHelloFP.JA4String()
+// ClientHelloFingerprint builds a TLS fingerprint from a ClientHello seen
+// outside a live connection, e.g. in a packet capture. Nothing was
+// negotiated, so Version is the highest version offered and ALPN is the
+// client's first preference.
+func ClientHelloFingerprint(ch *tlsfingerprint.Fingerprint) TLSFingerprint {
+	fp := TLSFingerprint{Available: true}
+	fp.Version = tlsVersionName(ch.Version)
+	if len(ch.ALPNProtocols) > 0 {
+		fp.ALPN = ch.ALPNProtocols[0]
+	}
+	applyClientHello(&fp, ch)
+	return fp
+}
 
-		// Check for early data extension (0-RTT)
-		fp.HasEarlyData = containsExtension(clientHelloFP.Extensions, 42) // early_data extension
+// applyClientHello populates fields offered in the ClientHello
+func applyClientHello(fp *TLSFingerprint, clientHelloFP *tlsfingerprint.Fingerprint) {
+	fp.CipherSuitesCount = len(clientHelloFP.CipherSuites)
+	fp.ExtensionsCount = len(clientHelloFP.Extensions)
+	fp.Extensions = make([]int, len(clientHelloFP.Extensions))
+	for i, e := range clientHelloFP.Extensions {
+		fp.Extensions[i] = int(e)
 	}
+	fp.HasSessionTicket = containsExtension(clientHelloFP.Extensions, 35) // session_ticket extension
 
-	return fp
+	// Supported versions from ClientHello
+	fp.SupportedVersions = formatTLSVersions(clientHelloFP.Version, clientHelloFP.RawVersion)
+
+	// Signature schemes
+	fp.SignatureSchemes = formatSignatureSchemes(clientHelloFP.SignatureAlgorithms)
+
+	// Supported groups (elliptic curves)
+	fp.SupportedGroups = formatSupportedGroups(clientHelloFP.SupportedGroups)
+
+	// JA3/JA4 fingerprints
+	fp.JA3Hash = clientHelloFP.JA3Hash()
+	fp.JA4Hash = JA4(clientHelloFP)
+	fp.JA4Raw = JA4Raw(clientHelloFP)
+
+	// Check for early data extension (0-RTT)
+	fp.HasEarlyData = containsExtension(clientHelloFP.Extensions, 42) // early_data extension
+
+	// Middlebox anomalies: browsers send GREASE (Firefox excepted) and
+	// dropped DHE suites years ago, OpenSSL-based proxies do neither
+	fp.GREASECiphers = slices.ContainsFunc(clientHelloFP.CipherSuites, isGREASE)
+	fp.GREASEExtensions = slices.ContainsFunc(clientHelloFP.Extensions, isGREASE)
+	fp.NoGREASE = !fp.GREASECiphers && !fp.GREASEExtensions
+	fp.LegacyCiphers = slices.ContainsFunc(clientHelloFP.CipherSuites, isLegacyCipher)
+
+	// Chrome and Firefox send ECH, GREASE when the server has no config
+	fp.ECH = containsExtension(clientHelloFP.Extensions, 0xfe0d) // encrypted_client_hello extension
+}
+
+// isLegacyCipher reports whether a cipher suite is a finite-field DHE suite
+// or TLS_EMPTY_RENEGOTIATION_INFO_SCSV, neither of which current browsers
+// offer
+func isLegacyCipher(c uint16) bool {
+	switch c {
+	case 0x0033, 0x0039, 0x0067, 0x006b, 0x009e, 0x009f, 0xccaa, 0x00ff:
+		return true
+	}
+	return false
 }
 
 // getClientHelloFingerprint retrieves the ClientHello fingerprint from request context
@@ -202,12 +274,14 @@ func (c *Collector) collectHTTP(r *http.Request) HTTPFingerprint {
 		Version:     r.Proto,
 		Method:      r.Method,
 		Path:        r.URL.Path,
+		Host:        r.Host,
 		Headers:     make(map[string]string),
 		HeaderOrder: make([]string, 0, len(r.Header)),
 		HeaderCount: len(r.Header),
 	}
 
-	// Collect headers in order (Go 1.21+ preserves order)
+	// http.Header is a map, so the order is only known when the server's
+	// listener captured it; otherwise sort for stable output
 	for key, values := range r.Header {
 		lowerKey := strings.ToLower(key)
 		fp.HeaderOrder = append(fp.HeaderOrder, lowerKey)
@@ -215,9 +289,16 @@ func (c *Collector) collectHTTP(r *http.Request) HTTPFingerprint {
 			fp.Headers[lowerKey] = values[0]
 		}
 	}
+	slices.Sort(fp.HeaderOrder)
+	if src, ok := r.Context().Value(ContextKeyHeaderOrder).(HeaderOrderSource); ok {
+		if names := src.HeaderNames(r); names != nil {
+			applyHeaderNames(&fp, names)
+		}
+	}
 
 	// Extract specific headers
 	fp.UserAgent = r.Header.Get("User-Agent")
+	fp.UserAgentParsed = ParseUserAgent(fp.UserAgent)
 	fp.Accept = r.Header.Get("Accept")
 	fp.AcceptLang = r.Header.Get("Accept-Language")
 	fp.AcceptEnc = r.Header.Get("Accept-Encoding")
@@ -231,6 +312,7 @@ func (c *Collector) collectHTTP(r *http.Request) HTTPFingerprint {
 	fp.SecFetchDest = r.Header.Get("Sec-Fetch-Dest")
 	fp.SecFetchUser = r.Header.Get("Sec-Fetch-User")
 	fp.SecChUA = r.Header.Get("Sec-CH-UA")
+	fp.ClientHints = parseClientHints(r.Header)
 
 	// Boolean checks
 	fp.HasCookies = r.Header.Get("Cookie") != ""
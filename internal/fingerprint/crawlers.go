@@ -0,0 +1,106 @@
+package fingerprint
+
+import (
+	"slices"
+	"strings"
+)
+
+// Crawler categories
+const (
+	CategoryAITraining = "ai-training" // Collects content to train models
+	CategoryAIFetch    = "ai-fetch"    // Fetches pages for AI answers and agents
+	CategorySearch     = "search"      // Indexes pages for a search engine
+	CategoryMonitoring = "monitoring"  // Checks uptime and performance
+)
+
+// Ways an operator lets sites verify that a request is from its crawler
+const (
+	VerifyReverseDNS = "reverse-dns" // Reverse then forward DNS of the client IP
+	VerifyIPRanges   = "ip-ranges"   // Published list of crawler IP ranges
+	VerifyNone       = "none"        // No published method; the User-Agent can be spoofed
+)
+
+// KnownCrawler describes a crawler by its User-Agent product token
+type KnownCrawler struct {
+	Name         string `json:"name"` // Product token, as named in robots.txt
+	Operator     string `json:"operator"`
+	Category     string `json:"category"`
+	Verification string `json:"verification"`
+}
+
+// knownCrawlers lists the crawlers documented by their operators, in
+// category order
+var knownCrawlers = []KnownCrawler{
+	{"GPTBot", "OpenAI", CategoryAITraining, VerifyIPRanges},
+	{"ClaudeBot", "Anthropic", CategoryAITraining, VerifyNone},
+	{"CCBot", "Common Crawl", CategoryAITraining, VerifyIPRanges},
+	{"GoogleOther", "Google", CategoryAITraining, VerifyReverseDNS},
+	{"Bytespider", "ByteDance", CategoryAITraining, VerifyNone},
+	{"Amazonbot", "Amazon", CategoryAITraining, VerifyReverseDNS},
+	{"Meta-ExternalAgent", "Meta", CategoryAITraining, VerifyNone},
+	{"Diffbot", "Diffbot", CategoryAITraining, VerifyNone},
+	{"AI2Bot", "Allen Institute for AI", CategoryAITraining, VerifyNone},
+
+	{"ChatGPT-User", "OpenAI", CategoryAIFetch, VerifyIPRanges},
+	{"Claude-Web", "Anthropic", CategoryAIFetch, VerifyNone},
+	{"Meta-ExternalFetcher", "Meta", CategoryAIFetch, VerifyNone},
+	{"cohere-ai", "Cohere", CategoryAIFetch, VerifyNone},
+	{"YouBot", "You.com", CategoryAIFetch, VerifyNone},
+	{"Phind", "Phind", CategoryAIFetch, VerifyNone},
+
+	{"OAI-SearchBot", "OpenAI", CategorySearch, VerifyIPRanges},
+	{"PerplexityBot", "Perplexity", CategorySearch, VerifyIPRanges},
+	{"Googlebot", "Google", CategorySearch, VerifyReverseDNS},
+	{"Bingbot", "Microsoft", CategorySearch, VerifyReverseDNS},
+	{"Applebot", "Apple", CategorySearch, VerifyReverseDNS},
+	{"DuckDuckBot", "DuckDuckGo", CategorySearch, VerifyIPRanges},
+	{"YandexBot", "Yandex", CategorySearch, VerifyReverseDNS},
+	{"Baiduspider", "Baidu", CategorySearch, VerifyReverseDNS},
+	{"iaskspider", "iAsk.AI", CategorySearch, VerifyNone},
+
+	{"UptimeRobot", "UptimeRobot", CategoryMonitoring, VerifyIPRanges},
+	{"Pingdom.com_bot", "SolarWinds Pingdom", CategoryMonitoring, VerifyIPRanges},
+}
+
+// KnownCrawlers returns the directory of documented crawlers
+func KnownCrawlers() []KnownCrawler {
+	return slices.Clone(knownCrawlers)
+}
+
+// ClaimedCrawler returns the known crawler whose product token a
+// User-Agent contains, ignoring case
+func ClaimedCrawler(ua string) (KnownCrawler, bool) {
+	lower := strings.ToLower(ua)
+	for _, c := range knownCrawlers {
+		if strings.Contains(lower, strings.ToLower(c.Name)) {
+			return c, true
+		}
+	}
+	return KnownCrawler{}, false
+}
+
+// RecognizedCrawler is a known crawler with the patterns that recognize it
+type RecognizedCrawler struct {
+	KnownCrawler
+	Patterns  []string `json:"patterns"`   // Bot patterns its product token contains
+	AICrawler bool     `json:"ai_crawler"` // Token also matches an AI crawler pattern
+}
+
+// Crawlers returns the known crawlers these rules classify as bots by
+// User-Agent. Crawlers no bot pattern matches are left out.
+func (r Rules) Crawlers() []RecognizedCrawler {
+	var recognized []RecognizedCrawler
+	for _, c := range knownCrawlers {
+		token := strings.ToLower(c.Name)
+		patterns := matchingPatterns(token, r.BotPatterns)
+		if len(patterns) == 0 {
+			continue
+		}
+		recognized = append(recognized, RecognizedCrawler{
+			KnownCrawler: c,
+			Patterns:     patterns,
+			AICrawler:    len(matchingPatterns(token, r.AICrawlerPatterns)) > 0,
+		})
+	}
+	return recognized
+}
@@ -0,0 +1,232 @@
+package fingerprint
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CustomRule is a scoring rule defined in configuration rather than code.
+// It fires when a signal or fingerprint field satisfies its condition.
+type CustomRule struct {
+	ScoringRule
+
+	// Signal names the value tested: a Signals field by its JSON name
+	// ("has_sec_fetch_headers"), or a fingerprint field prefixed by its
+	// section ("http.header_count", "tls.ja4_hash", "http.headers.x-api-key")
+	Signal string
+
+	// Condition compares the value: "== v", "!= v", "< n", "<= n", "> n",
+	// ">= n" or "contains s". Empty means true, non-zero or non-empty.
+	Condition string
+
+	match func(Signals, Fingerprint) bool
+}
+
+// NewCustomRule compiles a rule testing signal against condition. Rules
+// must be built with it: a zero CustomRule never fires.
+func NewCustomRule(rule ScoringRule, signal, condition string) (CustomRule, error) {
+	if rule.Name == "" {
+		return CustomRule{}, errors.New("rule has no name")
+	}
+	if strings.ContainsAny(rule.Name, " \t()[]") {
+		return CustomRule{}, fmt.Errorf("rule name %q contains whitespace, parentheses or brackets", rule.Name)
+	}
+	get, typ, err := signalAccessor(signal)
+	if err != nil {
+		return CustomRule{}, err
+	}
+	test, err := compileCondition(typ, condition)
+	if err != nil {
+		return CustomRule{}, fmt.Errorf("signal %q: %w", signal, err)
+	}
+	return CustomRule{
+		ScoringRule: rule,
+		Signal:      signal,
+		Condition:   condition,
+		match: func(s Signals, fp Fingerprint) bool {
+			return test(get(s, fp))
+		},
+	}, nil
+}
+
+// Matches reports whether the rule fires for the signals and fingerprint
+func (r CustomRule) Matches(s Signals, fp Fingerprint) bool {
+	return r.match != nil && r.match(s, fp)
+}
+
+// computedSignals are filled in from the scores and cannot be tested by
+// the rules that make them up
+var computedSignals = []string{"browser_score", "bot_score", "score_breakdown"}
+
+// fingerprintSections maps signal prefixes to Fingerprint fields
+var fingerprintSections = map[string]string{
+	"tls":     "TLS",
+	"http":    "HTTP",
+	"session": "Session",
+	"network": "Network",
+}
+
+// signalAccessor resolves a signal name to a function reading its value
+func signalAccessor(signal string) (func(Signals, Fingerprint) reflect.Value, reflect.Type, error) {
+	section, rest, nested := strings.Cut(signal, ".")
+	if !nested {
+		if slices.Contains(computedSignals, signal) {
+			return nil, nil, fmt.Errorf("signal %q is computed from the rules", signal)
+		}
+		f, ok := jsonField(reflect.TypeFor[Signals](), signal)
+		if !ok {
+			return nil, nil, fmt.Errorf("unknown signal %q", signal)
+		}
+		return func(s Signals, _ Fingerprint) reflect.Value {
+			return reflect.ValueOf(s).FieldByIndex(f.Index)
+		}, f.Type, nil
+	}
+
+	fieldName, ok := fingerprintSections[section]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown signal section %q", section)
+	}
+	sf, _ := reflect.TypeFor[Fingerprint]().FieldByName(fieldName)
+	name, key, keyed := strings.Cut(rest, ".")
+	f, ok := jsonField(sf.Type, name)
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown signal %q", signal)
+	}
+	index := append(slices.Clone(sf.Index), f.Index...)
+	get := func(_ Signals, fp Fingerprint) reflect.Value {
+		return reflect.ValueOf(fp).FieldByIndex(index)
+	}
+	if f.Type.Kind() != reflect.Map {
+		if keyed {
+			return nil, nil, fmt.Errorf("signal %q: %s is not a map", signal, name)
+		}
+		return get, f.Type, nil
+	}
+	if !keyed || f.Type.Key().Kind() != reflect.String {
+		return nil, nil, fmt.Errorf("signal %q: name a key of %s, e.g. %s.%s.<key>", signal, name, section, name)
+	}
+	keyValue := reflect.ValueOf(strings.ToLower(key))
+	zero := reflect.Zero(f.Type.Elem())
+	return func(s Signals, fp Fingerprint) reflect.Value {
+		if v := get(s, fp).MapIndex(keyValue); v.IsValid() {
+			return v
+		}
+		return zero
+	}, f.Type.Elem(), nil
+}
+
+// jsonField finds the field of a struct type with the given JSON name
+func jsonField(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		tag, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if tag == name && tag != "" && tag != "-" {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// compileCondition parses a condition for values of type t
+func compileCondition(t reflect.Type, condition string) (func(reflect.Value) bool, error) {
+	condition = strings.TrimSpace(condition)
+	if condition == "" {
+		return func(v reflect.Value) bool { return !v.IsZero() }, nil
+	}
+	op, operand, _ := strings.Cut(condition, " ")
+	operand = strings.TrimSpace(operand)
+	if strings.HasPrefix(operand, `"`) {
+		s, err := strconv.Unquote(operand)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quoted value %s", operand)
+		}
+		operand = s
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		want, err := strconv.ParseBool(operand)
+		if err != nil {
+			return nil, fmt.Errorf("%q needs true or false", condition)
+		}
+		return equality(op, condition, func(v reflect.Value) bool { return v.Bool() == want })
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		want, err := strconv.ParseFloat(operand, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%q needs a number", condition)
+		}
+		return ordering(op, condition, want)
+
+	case reflect.String:
+		if op == "contains" {
+			return func(v reflect.Value) bool { return strings.Contains(v.String(), operand) }, nil
+		}
+		return equality(op, condition, func(v reflect.Value) bool { return v.String() == operand })
+
+	case reflect.Slice:
+		if t.Elem().Kind() != reflect.String || op != "contains" {
+			break
+		}
+		return func(v reflect.Value) bool {
+			for i := range v.Len() {
+				if v.Index(i).String() == operand {
+					return true
+				}
+			}
+			return false
+		}, nil
+	}
+	return nil, fmt.Errorf("condition %q does not apply to %s values", condition, t)
+}
+
+// equality builds an == or != test from eq
+func equality(op, condition string, eq func(reflect.Value) bool) (func(reflect.Value) bool, error) {
+	switch op {
+	case "==":
+		return eq, nil
+	case "!=":
+		return func(v reflect.Value) bool { return !eq(v) }, nil
+	}
+	return nil, fmt.Errorf("condition %q needs == or !=", condition)
+}
+
+// ordering builds a numeric comparison against want
+func ordering(op, condition string, want float64) (func(reflect.Value) bool, error) {
+	var cmp func(float64) bool
+	switch op {
+	case "==":
+		cmp = func(n float64) bool { return n == want }
+	case "!=":
+		cmp = func(n float64) bool { return n != want }
+	case "<":
+		cmp = func(n float64) bool { return n < want }
+	case "<=":
+		cmp = func(n float64) bool { return n <= want }
+	case ">":
+		cmp = func(n float64) bool { return n > want }
+	case ">=":
+		cmp = func(n float64) bool { return n >= want }
+	default:
+		return nil, fmt.Errorf("condition %q needs ==, !=, <, <=, > or >=", condition)
+	}
+	return func(v reflect.Value) bool { return cmp(number(v)) }, nil
+}
+
+// number returns a numeric value as float64
+func number(v reflect.Value) float64 {
+	switch {
+	case v.CanInt():
+		return float64(v.Int())
+	case v.CanUint():
+		return float64(v.Uint())
+	default:
+		return v.Float()
+	}
+}
@@ -0,0 +1,45 @@
+package fingerprint
+
+import "slices"
+
+// libraryExtensionOrders are the orders in which TLS libraries write every
+// ClientHello extension they support. A ClientHello sends some of them,
+// in this order. Chrome shuffles its extensions and Firefox and Safari
+// send ones neither list has, so browsers never fit.
+var libraryExtensionOrders = []struct {
+	stack string
+	order []int
+}{
+	{tlsStackGo, []int{0, 11, 35, 65281, 23, 18, 42, 57, 65037, 5, 10, 13, 50, 16, 43, 44, 51, 45, 41}},
+	{tlsStackOpenSSL, []int{0, 1, 12, 11, 10, 35, 5, 13172, 16, 14, 22, 18, 23, 50, 49, 19, 20, 13, 43, 45, 51, 44, 27, 42, 47, 21, 41}},
+}
+
+// minOrderedExtensions is the number of extensions under which an order
+// says too little to name a stack
+const minOrderedExtensions = 6
+
+// extensionOrderStack returns the TLS library whose extension order a
+// ClientHello follows, or "" when it matches none. GREASE is skipped.
+func extensionOrderStack(exts []int) string {
+	exts = slices.DeleteFunc(slices.Clone(exts), func(e int) bool { return isGREASE(uint16(e)) })
+	if len(exts) < minOrderedExtensions {
+		return ""
+	}
+	for _, lib := range libraryExtensionOrders {
+		if isSubsequence(exts, lib.order) {
+			return lib.stack
+		}
+	}
+	return ""
+}
+
+// isSubsequence reports whether s appears in order within of
+func isSubsequence(s, of []int) bool {
+	i := 0
+	for _, v := range of {
+		if i < len(s) && s[i] == v {
+			i++
+		}
+	}
+	return i == len(s)
+}
@@ -0,0 +1,50 @@
+package fingerprint
+
+import (
+	"slices"
+	"strings"
+)
+
+// navigationDests are the Sec-Fetch-Dest values of navigation requests
+var navigationDests = []string{"document", "iframe", "frame", "fencedframe", "embed", "object"}
+
+// destAccept is what the Accept header browsers send for a Sec-Fetch-Dest
+// always contains
+var destAccept = map[string]string{
+	"document": "text/html",
+	"iframe":   "text/html",
+	"frame":    "text/html",
+	"image":    "image/",
+	"style":    "text/css",
+}
+
+// fetchMetadataMismatch returns the lowercased name of the header that
+// contradicts the request's Sec-Fetch metadata, or "" when they agree or
+// no Sec-Fetch headers were sent. Browsers set Sec-Fetch-Mode,
+// Sec-Fetch-Dest and the Accept of navigations and subresources together,
+// so they cannot disagree; scripts setting them by hand often do.
+func fetchMetadataMismatch(h HTTPFingerprint) string {
+	mode, dest := h.SecFetchMode, h.SecFetchDest
+	if mode == "" && dest == "" {
+		return ""
+	}
+	navigate := mode == "navigate"
+	switch {
+	case navigate && dest != "" && !slices.Contains(navigationDests, dest):
+		return "sec-fetch-dest"
+	case !navigate && mode != "" && destAccept[dest] == "text/html":
+		return "sec-fetch-mode"
+	case navigate && strings.EqualFold(h.Headers["x-requested-with"], "XMLHttpRequest"):
+		return "x-requested-with" // XMLHttpRequest cannot navigate
+	case h.SecFetchUser != "" && !navigate:
+		return "sec-fetch-user" // Only sent on user-activated navigations
+	}
+	want := destAccept[dest]
+	if want == "" && navigate && dest == "" {
+		want = "text/html"
+	}
+	if want != "" && !strings.Contains(strings.ToLower(h.Accept), want) {
+		return "accept"
+	}
+	return ""
+}
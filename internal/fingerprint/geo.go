@@ -0,0 +1,102 @@
+package fingerprint
+
+import (
+	"slices"
+	"strings"
+)
+
+// countryLanguages lists the languages, besides English, that browsers in
+// a country are commonly set to: official and widely spoken languages.
+// Countries not listed are not judged.
+var countryLanguages = map[string][]string{
+	"AE": {"ar", "hi", "ur"},
+	"AR": {"es"},
+	"AT": {"de"},
+	"AU": {},
+	"BD": {"bn"},
+	"BE": {"nl", "fr", "de"},
+	"BG": {"bg"},
+	"BR": {"pt"},
+	"BY": {"be", "ru"},
+	"CA": {"fr"},
+	"CH": {"de", "fr", "it", "rm"},
+	"CL": {"es"},
+	"CN": {"zh"},
+	"CO": {"es"},
+	"CZ": {"cs", "sk"},
+	"DE": {"de"},
+	"DK": {"da"},
+	"EE": {"et", "ru"},
+	"EG": {"ar"},
+	"ES": {"es", "ca", "gl", "eu"},
+	"FI": {"fi", "sv"},
+	"FR": {"fr"},
+	"GB": {"cy", "gd"},
+	"GR": {"el"},
+	"HK": {"zh"},
+	"HR": {"hr"},
+	"HU": {"hu"},
+	"ID": {"id", "jv"},
+	"IE": {"ga"},
+	"IL": {"he", "ar", "ru"},
+	"IN": {"hi", "bn", "te", "mr", "ta", "ur", "gu", "kn", "ml", "pa"},
+	"IR": {"fa"},
+	"IT": {"it"},
+	"JP": {"ja"},
+	"KR": {"ko"},
+	"KZ": {"kk", "ru"},
+	"LT": {"lt", "ru"},
+	"LV": {"lv", "ru"},
+	"MX": {"es"},
+	"MY": {"ms", "zh", "ta"},
+	"NG": {"ha", "yo", "ig"},
+	"NL": {"nl"},
+	"NO": {"nb", "nn", "no"},
+	"NZ": {"mi"},
+	"PE": {"es"},
+	"PH": {"fil", "tl"},
+	"PK": {"ur"},
+	"PL": {"pl"},
+	"PT": {"pt"},
+	"RO": {"ro", "hu"},
+	"RS": {"sr"},
+	"RU": {"ru"},
+	"SA": {"ar"},
+	"SE": {"sv"},
+	"SG": {"zh", "ms", "ta"},
+	"SI": {"sl"},
+	"SK": {"sk", "cs", "hu"},
+	"TH": {"th"},
+	"TR": {"tr"},
+	"TW": {"zh"},
+	"UA": {"uk", "ru"},
+	"US": {"es"},
+	"VN": {"vi"},
+	"ZA": {"af", "zu", "xh"},
+}
+
+// extractGeoSignals compares the client's country, when an enricher
+// looked it up, with its Accept-Language. The request is a mismatch when
+// no accepted language fits the country: none is English, spoken there,
+// or tagged with the country as region. English fits everywhere, since
+// many users keep their browser's default.
+func extractGeoSignals(s *Signals, fp Fingerprint) {
+	country := strings.ToUpper(fp.Network.Country)
+	spoken, known := countryLanguages[country]
+	if !known || fp.HTTP.AcceptLang == "" {
+		return
+	}
+	judged := false
+	for _, tag := range strings.Split(fp.HTTP.AcceptLang, ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		lang, region, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+		if lang == "" || lang == "*" {
+			continue
+		}
+		judged = true
+		if lang == "en" || slices.Contains(spoken, lang) || strings.EqualFold(region, country) {
+			return
+		}
+	}
+	s.GeoLanguageMismatch = judged
+}
@@ -0,0 +1,137 @@
+package fingerprint
+
+import (
+	"bufio"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// GoldenVersion identifies the revision of the embedded golden corpus.
+// It changes whenever entries are added, removed or recaptured.
+const GoldenVersion = "2026.10"
+
+// Golden corpus families
+const (
+	GoldenBrowser   = "browser"
+	GoldenLibrary   = "library"
+	GoldenAICrawler = "ai_crawler"
+)
+
+// GoldenFingerprint is a curated real-world fingerprint of a known client.
+// Source records how it was obtained: browser TLS comes from uTLS presets
+// of the same release, library TLS and headers from captures of the real
+// client, and AI crawlers carry HTTP signals only.
+type GoldenFingerprint struct {
+	ID          string      `json:"id"`     // Unique, e.g. "chrome-131-windows"
+	Family      string      `json:"family"` // GoldenBrowser, GoldenLibrary or GoldenAICrawler
+	Client      string      `json:"client"`
+	Version     string      `json:"version,omitempty"`
+	Platform    string      `json:"platform,omitempty"`
+	Expect      string      `json:"expect"` // Ground truth: "browser" or "bot"
+	Source      string      `json:"source"`
+	Fingerprint Fingerprint `json:"fingerprint"`
+}
+
+// goldenFiles are the embedded corpus files, in Golden order
+var goldenFiles = []string{"golden/browsers.jsonl", "golden/libraries.jsonl", "golden/ai_crawlers.jsonl"}
+
+//go:embed golden/*.jsonl
+var goldenFS embed.FS
+
+// maxGoldenLineBytes bounds a single JSONL corpus line
+const maxGoldenLineBytes = 1 << 20
+
+// Golden returns the embedded corpus: browsers, then libraries, then AI
+// crawlers. Each call returns a fresh copy that callers may modify.
+func Golden() ([]GoldenFingerprint, error) {
+	var all []GoldenFingerprint
+	seen := map[string]bool{}
+	for _, name := range goldenFiles {
+		f, err := goldenFS.Open(name)
+		if err != nil {
+			return nil, err
+		}
+		entries, err := ReadGolden(f)
+		_ = f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		for _, e := range entries {
+			if seen[e.ID] {
+				return nil, fmt.Errorf("%s: duplicate id %q", name, e.ID)
+			}
+			seen[e.ID] = true
+		}
+		all = append(all, entries...)
+	}
+	return all, nil
+}
+
+// GoldenFamily returns the embedded corpus entries of one family
+func GoldenFamily(family string) ([]GoldenFingerprint, error) {
+	all, err := Golden()
+	if err != nil {
+		return nil, err
+	}
+	var out []GoldenFingerprint
+	for _, e := range all {
+		if e.Family == family {
+			out = append(out, e)
+		}
+	}
+	return out, nil
+}
+
+// ReadGolden decodes a corpus in the JSONL format of the embedded files,
+// skipping blank lines. Entries must have a unique id, a known family and
+// an expected classification.
+func ReadGolden(r io.Reader) ([]GoldenFingerprint, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxGoldenLineBytes)
+
+	var entries []GoldenFingerprint
+	seen := map[string]bool{}
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var e GoldenFingerprint
+		if err := json.Unmarshal(data, &e); err != nil {
+			return nil, fmt.Errorf("invalid entry on line %d: %w", line, err)
+		}
+		if err := e.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if seen[e.ID] {
+			return nil, fmt.Errorf("line %d: duplicate id %q", line, e.ID)
+		}
+		seen[e.ID] = true
+		entries = append(entries, e)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// validate checks the required fields of an entry
+func (e GoldenFingerprint) validate() error {
+	if e.ID == "" {
+		return errors.New("missing id")
+	}
+	switch e.Family {
+	case GoldenBrowser, GoldenLibrary, GoldenAICrawler:
+	default:
+		return fmt.Errorf("%s: unknown family %q", e.ID, e.Family)
+	}
+	if e.Expect != "browser" && e.Expect != "bot" {
+		return fmt.Errorf("%s: expect must be browser or bot, got %q", e.ID, e.Expect)
+	}
+	return nil
+}
@@ -0,0 +1,198 @@
+package fingerprint
+
+import (
+	"bytes"
+	"net/http"
+	"net/textproto"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ContextKeyHeaderOrder is the key for the HeaderOrderSource of the
+// request's connection
+const ContextKeyHeaderOrder TLSFingerprintContextKey = "header_order"
+
+// HeaderOrderSource returns the header names of a request as sent on the
+// wire, or nil when they were not captured
+type HeaderOrderSource interface {
+	HeaderNames(r *http.Request) []string
+}
+
+const (
+	maxSniffedLine    = 8 << 10 // Longer lines are skipped
+	maxSniffedHeaders = 100     // Header names kept per request
+	maxSniffedBlocks  = 4       // Recent requests kept, for pipelining
+)
+
+// movedHeaders are sent as headers but removed from http.Request.Header
+// by net/http
+var movedHeaders = map[string]bool{
+	"Host":              true,
+	"Transfer-Encoding": true,
+	"Expect":            true,
+	"Trailer":           true,
+}
+
+// headerBlock is the request line and header names of one request
+type headerBlock struct {
+	method, target string
+	names          []string
+}
+
+// HeaderSniffer records the header names of HTTP/1.x requests, in wire
+// order and case, from the bytes read off a connection. net/http keeps
+// headers in a map under canonical names, losing both.
+//
+// Bodies are skipped by Content-Length; after a chunked body the sniffer
+// resynchronizes on the next request line. A connection that turns out to
+// speak HTTP/2 is ignored.
+type HeaderSniffer struct {
+	mu       sync.Mutex
+	line     []byte
+	overflow bool  // Discarding a line longer than maxSniffedLine
+	skip     int64 // Body bytes still to skip
+	inBlock  bool  // Between a request line and the blank line ending it
+	stopped  bool
+	block    headerBlock
+	bodySize int64
+	recent   []headerBlock
+}
+
+// Write feeds bytes read from the connection; it never fails
+func (s *HeaderSniffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := len(p)
+	for len(p) > 0 && !s.stopped {
+		if s.skip > 0 {
+			k := int(min(s.skip, int64(len(p))))
+			s.skip -= int64(k)
+			p = p[k:]
+			continue
+		}
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			s.buffer(p)
+			return n, nil
+		}
+		s.buffer(p[:i])
+		if !s.overflow {
+			s.parseLine(string(bytes.TrimSuffix(s.line, []byte{'\r'})))
+		}
+		s.line, s.overflow = s.line[:0], false
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+// buffer appends to the current line unless it grew too long
+func (s *HeaderSniffer) buffer(p []byte) {
+	if s.overflow || len(s.line)+len(p) > maxSniffedLine {
+		s.line, s.overflow = s.line[:0], true
+		return
+	}
+	s.line = append(s.line, p...)
+}
+
+// parseLine handles one line of a request line or header block
+func (s *HeaderSniffer) parseLine(line string) {
+	if !s.inBlock {
+		method, rest, ok1 := strings.Cut(line, " ")
+		target, proto, ok2 := strings.Cut(rest, " ")
+		switch {
+		case !ok1 || !ok2 || method == "" || target == "":
+		case strings.HasPrefix(proto, "HTTP/1."):
+			s.inBlock, s.block, s.bodySize = true, headerBlock{method: method, target: target}, 0
+		case strings.HasPrefix(proto, "HTTP/2"):
+			s.stopped = true // HTTP/2 connection preface
+		}
+		return
+	}
+	if line == "" {
+		s.recent = append(s.recent, s.block)
+		if len(s.recent) > maxSniffedBlocks {
+			s.recent = slices.Delete(s.recent, 0, 1)
+		}
+		s.inBlock, s.skip = false, s.bodySize
+		return
+	}
+	name, value, ok := strings.Cut(line, ":")
+	if !ok || name == "" || strings.ContainsAny(name, " \t") || len(s.block.names) >= maxSniffedHeaders {
+		return // Folded continuation line or malformed
+	}
+	s.block.names = append(s.block.names, name)
+	if strings.EqualFold(name, "Content-Length") {
+		if n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64); err == nil && n > 0 {
+			s.bodySize = n
+		}
+	}
+}
+
+// HeaderNames returns the header names of the most recent request matching
+// r's request line and header set, or nil when none was seen
+func (s *HeaderSniffer) HeaderNames(r *http.Request) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, b := range slices.Backward(s.recent) {
+		if b.method == r.Method && b.target == r.RequestURI && sameHeaderSet(b.names, r.Header) {
+			return slices.Clone(b.names)
+		}
+	}
+	return nil
+}
+
+// sameHeaderSet reports whether names are the headers of h, allowing for
+// the ones net/http moves out of the header map
+func sameHeaderSet(names []string, h http.Header) bool {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := textproto.CanonicalMIMEHeaderKey(name)
+		if _, ok := h[key]; !ok && !movedHeaders[key] {
+			return false
+		}
+		seen[key] = true
+	}
+	for key := range h {
+		if !seen[key] {
+			return false
+		}
+	}
+	return true
+}
+
+// caseCheckedHeaders are the headers every browser sends in canonical case
+// over HTTP/1.x. Client Hints are left out: Chrome sends them lowercase.
+var caseCheckedHeaders = []string{
+	"Host", "User-Agent", "Accept", "Accept-Language", "Accept-Encoding",
+	"Connection", "Referer", "Cookie",
+}
+
+// nonCanonicalHeaderCase reports whether an HTTP/1.x request was captured
+// with a common header name not in canonical case, e.g. "user-agent" from
+// an HTTP/2-first client library or "HOST" from a hand-written request
+func nonCanonicalHeaderCase(h HTTPFingerprint) bool {
+	if !strings.HasPrefix(h.Version, "HTTP/1.") {
+		return false
+	}
+	for _, name := range h.RawHeaderNames {
+		key := textproto.CanonicalMIMEHeaderKey(name)
+		if key != name && slices.Contains(caseCheckedHeaders, key) {
+			return true
+		}
+	}
+	return false
+}
+
+// applyHeaderNames records the header names captured on the wire and
+// replaces HeaderOrder with their order
+func applyHeaderNames(h *HTTPFingerprint, names []string) {
+	h.RawHeaderNames = names
+	h.HeaderOrder = h.HeaderOrder[:0]
+	for _, name := range names {
+		if lower := strings.ToLower(name); !slices.Contains(h.HeaderOrder, lower) {
+			h.HeaderOrder = append(h.HeaderOrder, lower)
+		}
+	}
+}
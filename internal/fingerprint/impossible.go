@@ -0,0 +1,117 @@
+package fingerprint
+
+import (
+	"slices"
+	"strings"
+)
+
+// ImpossibleCombination is a combination of request properties no real
+// client of the kind claimed produces
+type ImpossibleCombination struct {
+	Name        string // e.g. "sec-fetch-http1.0"
+	Explanation string // Why no browser sends it, for reasons and audits
+	matches     func(fp Fingerprint) bool
+}
+
+// impossibleCombinations is the table header_spoofing checks, in the order
+// spoofing_combos lists them
+var impossibleCombinations = []ImpossibleCombination{
+	{
+		Name:        "sec-fetch-http1.0",
+		Explanation: "Sec-Fetch headers on HTTP/1.0",
+		matches: func(fp Fingerprint) bool {
+			return hasSecFetch(fp.HTTP) && fp.HTTP.Version == "HTTP/1.0"
+		},
+	},
+	{
+		Name:        "sec-fetch-no-user-agent",
+		Explanation: "Sec-Fetch headers without a User-Agent",
+		matches: func(fp Fingerprint) bool {
+			return hasSecFetch(fp.HTTP) && fp.HTTP.UserAgent == ""
+		},
+	},
+	{
+		Name:        "chrome-no-client-hints",
+		Explanation: "Chrome User-Agent without Sec-CH-UA on HTTP/2 or HTTP/3",
+		matches: func(fp Fingerprint) bool {
+			h2 := fp.HTTP.Version == "HTTP/2.0" || fp.HTTP.Version == "HTTP/3.0" ||
+				fp.TLS.ALPN == "h2" || fp.TLS.ALPN == "h3"
+			return h2 && fp.HTTP.SecChUA == "" && sendsClientHints(fp.HTTP.UserAgent)
+		},
+	},
+	{
+		Name:        "br-plaintext-http1.0",
+		Explanation: "Accept-Encoding: br on plaintext HTTP/1.0",
+		matches: func(fp Fingerprint) bool {
+			return fp.HTTP.Version == "HTTP/1.0" && !fp.TLS.Available && acceptsBrotli(fp.HTTP.AcceptEnc)
+		},
+	},
+	{
+		Name:        "upgrade-insecure-subresource",
+		Explanation: "Upgrade-Insecure-Requests outside a navigation",
+		matches: func(fp Fingerprint) bool {
+			mode := fp.HTTP.SecFetchMode
+			return mode != "" && mode != "navigate" && fp.HTTP.Headers["upgrade-insecure-requests"] != ""
+		},
+	},
+}
+
+// clientHintsMinMajor is the first Chrome and Edge release sending
+// Sec-CH-UA by default
+const clientHintsMinMajor = 90
+
+// ImpossibleCombinations returns the built-in impossible combinations
+func ImpossibleCombinations() []ImpossibleCombination {
+	return slices.Clone(impossibleCombinations)
+}
+
+// LookupImpossibleCombination returns the built-in combination with the
+// given name
+func LookupImpossibleCombination(name string) (ImpossibleCombination, bool) {
+	for _, c := range impossibleCombinations {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ImpossibleCombination{}, false
+}
+
+// impossibleCombos returns the names of the impossible combinations a
+// request matches, or nil
+func impossibleCombos(fp Fingerprint) []string {
+	var names []string
+	for _, c := range impossibleCombinations {
+		if c.matches(fp) {
+			names = append(names, c.Name)
+		}
+	}
+	return names
+}
+
+// hasSecFetch reports whether any Sec-Fetch header was sent
+func hasSecFetch(h HTTPFingerprint) bool {
+	return h.SecFetchSite != "" || h.SecFetchMode != "" || h.SecFetchDest != "" || h.SecFetchUser != ""
+}
+
+// sendsClientHints reports whether a User-Agent claims a desktop or
+// Android Chrome or Edge recent enough to always send Sec-CH-UA over a
+// secure connection. Chrome on iOS is WebKit and Android WebView sends no
+// hints before M116, so both are left out.
+func sendsClientHints(ua string) bool {
+	p := ParseUserAgent(ua)
+	if p.Family != "Chrome" && p.Family != "Edge" || p.OS == "iOS" || strings.Contains(ua, "; wv)") {
+		return false
+	}
+	return p.Major >= clientHintsMinMajor
+}
+
+// acceptsBrotli reports whether an Accept-Encoding lists br
+func acceptsBrotli(acceptEncoding string) bool {
+	for _, coding := range strings.Split(acceptEncoding, ",") {
+		name, _, _ := strings.Cut(coding, ";")
+		if strings.EqualFold(strings.TrimSpace(name), "br") {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,143 @@
+package fingerprint
+
+import (
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/psanford/tlsfingerprint"
+)
+
+// JA4 computes the JA4 TLS client fingerprint of a ClientHello.
+// Format: {protocol}{version}{sni}{ciphers}{extensions}{alpn}_{cipher hash}_{extension hash}
+//
+// Example: t13d1516h2_8daaf6152771_e5627efa2ab1
+//
+// Reference: https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4.md
+func JA4(ch *tlsfingerprint.Fingerprint) string {
+	j := newJA4Parts(ch)
+	return fmt.Sprintf("%s_%s_%s", j.a, ja4Hash(j.ciphers), ja4Hash(j.extensions))
+}
+
+// JA4Raw computes the raw JA4 fingerprint (ja4_r): JA4 with the sorted
+// cipher and extension lists in place of their hashes, so fingerprints
+// that differ in a single value can be told apart.
+//
+// Example: t13d1516h2_002f,0035,009c,..._0005,000a,000b,..._0403,0804,...
+func JA4Raw(ch *tlsfingerprint.Fingerprint) string {
+	j := newJA4Parts(ch)
+	return fmt.Sprintf("%s_%s_%s", j.a, j.ciphers, j.extensions)
+}
+
+// ja4Parts are the sections of a JA4 fingerprint before hashing
+type ja4Parts struct {
+	a          string // Human-readable prefix, e.g. t13d1516h2
+	ciphers    string // Sorted cipher suites in hex
+	extensions string // Sorted extensions, "_" and signature algorithms in offered order
+}
+
+// newJA4Parts computes the JA4 sections. GREASE values are ignored
+// throughout; SNI and ALPN count as extensions but are left out of the
+// extension list, which the prefix already records them in. ClientHellos
+// carrying QUIC transport parameters came over QUIC ("q") rather than TCP.
+func newJA4Parts(ch *tlsfingerprint.Fingerprint) ja4Parts {
+	var ciphers, exts, sigAlgs []string
+	for _, c := range ch.CipherSuites {
+		if !isGREASE(c) {
+			ciphers = append(ciphers, fmt.Sprintf("%04x", c))
+		}
+	}
+	extCount := 0
+	for _, e := range ch.Extensions {
+		if isGREASE(e) {
+			continue
+		}
+		extCount++
+		if e != 0x0000 && e != 0x0010 { // server_name, ALPN
+			exts = append(exts, fmt.Sprintf("%04x", e))
+		}
+	}
+	for _, s := range ch.SignatureAlgorithms {
+		if !isGREASE(s) {
+			sigAlgs = append(sigAlgs, fmt.Sprintf("%04x", s))
+		}
+	}
+	slices.Sort(ciphers)
+	slices.Sort(exts)
+
+	protocol := "t"
+	if containsExtension(ch.Extensions, extQUICTransportParameters) {
+		protocol = "q"
+	}
+	sni := "i"
+	if ch.HasSNI {
+		sni = "d"
+	}
+	extensions := strings.Join(exts, ",")
+	if len(sigAlgs) > 0 {
+		extensions += "_" + strings.Join(sigAlgs, ",")
+	}
+	return ja4Parts{
+		a: fmt.Sprintf("%s%s%s%02d%02d%s", protocol, ja4Version(ch.Version), sni,
+			min(len(ciphers), 99), min(extCount, 99), ja4ALPN(ch.ALPNProtocols)),
+		ciphers:    strings.Join(ciphers, ","),
+		extensions: extensions,
+	}
+}
+
+// ja4Version codes the highest TLS version offered, taken from
+// supported_versions when present
+func ja4Version(v uint16) string {
+	switch v {
+	case 0x0304:
+		return "13"
+	case 0x0303:
+		return "12"
+	case 0x0302:
+		return "11"
+	case 0x0301:
+		return "10"
+	case 0x0300:
+		return "s3"
+	case 0x0002:
+		return "s2"
+	case 0xfeff:
+		return "d1"
+	case 0xfefd:
+		return "d2"
+	case 0xfefc:
+		return "d3"
+	}
+	return "00"
+}
+
+// ja4ALPN codes the first ALPN value by its first and last characters,
+// or by the first and last hex digits of its bytes when either character
+// is not alphanumeric. "00" means no ALPN.
+func ja4ALPN(protocols []string) string {
+	if len(protocols) == 0 || protocols[0] == "" {
+		return "00"
+	}
+	p := protocols[0]
+	first, last := p[0], p[len(p)-1]
+	if !isAlphanumeric(first) || !isAlphanumeric(last) {
+		h := hex.EncodeToString([]byte(p))
+		return h[:1] + h[len(h)-1:]
+	}
+	return string([]byte{first, last})
+}
+
+// isAlphanumeric reports whether b is an ASCII letter or digit
+func isAlphanumeric(b byte) bool {
+	return b >= '0' && b <= '9' || b >= 'a' && b <= 'z' || b >= 'A' && b <= 'Z'
+}
+
+// ja4Hash returns the truncated SHA256 of a JA4 list, or zeros when the
+// list is empty
+func ja4Hash(list string) string {
+	if list == "" {
+		return strings.Repeat("0", 12)
+	}
+	return truncatedSHA256(list)
+}
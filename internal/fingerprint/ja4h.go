@@ -21,6 +21,13 @@ func JA4H(req *http.Request) string {
 	return fmt.Sprintf("%s_%s_%s_%s", a, b, c, d)
 }
 
+// JA4HOrdered computes JA4H with JA4H_b as the specification defines it:
+// the hash of the header names in the order and case they were sent.
+// names is the request's header names as captured on the wire.
+func JA4HOrdered(req *http.Request, names []string) string {
+	return fmt.Sprintf("%s_%s_%s_%s", JA4H_a(req), ja4hHeaderNames(names), JA4H_c(req), JA4H_d(req))
+}
+
 // JA4H_a computes the human-readable part of JA4H fingerprint.
 // Format: {method}{version}{cookie}{referer}{header_count}{language}
 //
@@ -40,7 +47,8 @@ func JA4H_a(req *http.Request) string {
 // SHA256 hash of sorted header names + sorted header values, truncated to 12 hex chars.
 //
 // Note: Official spec uses original header order, but Go's http.Header is a map
-// and doesn't preserve order reliably. We use sorted headers for consistency.
+// and doesn't preserve order reliably. We use sorted headers for consistency;
+// JA4HOrdered follows the spec when the wire order was captured.
 func JA4H_b(req *http.Request) string {
 	if len(req.Header) == 0 {
 		return strings.Repeat("0", 12)
@@ -70,6 +78,21 @@ func JA4H_b(req *http.Request) string {
 	return truncatedSHA256(data)
 }
 
+// ja4hHeaderNames hashes header names in wire order, excluding Cookie and
+// Referer
+func ja4hHeaderNames(names []string) string {
+	kept := make([]string, 0, len(names))
+	for _, name := range names {
+		if lower := strings.ToLower(name); lower != "cookie" && lower != "referer" {
+			kept = append(kept, name)
+		}
+	}
+	if len(kept) == 0 {
+		return strings.Repeat("0", 12)
+	}
+	return truncatedSHA256(strings.Join(kept, ","))
+}
+
 // JA4H_c computes the cookie names fingerprint.
 // SHA256 hash of sorted cookie names, truncated to 12 hex chars.
 // Returns "000000000000" if no cookies present.
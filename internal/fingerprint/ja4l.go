@@ -0,0 +1,68 @@
+package fingerprint
+
+import (
+	"net/netip"
+	"strconv"
+	"time"
+)
+
+// ContextKeyLatency is the key for the LatencySource of the request's
+// connection
+const ContextKeyLatency TLSFingerprintContextKey = "latency"
+
+// LatencySource returns the client's latency measured during the TLS
+// handshake, or 0 when it was not measured
+type LatencySource interface {
+	HandshakeLatency() time.Duration
+}
+
+// colocatedLatency is the JA4L latency, in microseconds, under which a
+// client is taken to be in the server's datacenter or region. Residential
+// and mobile access networks alone add more.
+const colocatedLatency = 1000
+
+// JA4L computes the JA4L-C client latency fingerprint.
+// Format: {latency}_{ttl}
+//
+// Example: 11324_117
+//
+// The latency is the client's one-way latency in microseconds: half the
+// time from our ServerHello flight to the client's next one. It includes
+// the client's handshake processing, which the TCP-based measurement of
+// the specification does not. The TTL is that of the client's SYN, "00"
+// when no SYN was recorded.
+//
+// Reference: https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4L.md
+func JA4L(latency time.Duration, ttl int) string {
+	t := "00"
+	if ttl > 0 {
+		t = strconv.Itoa(ttl)
+	}
+	return strconv.FormatInt(latency.Microseconds(), 10) + "_" + t
+}
+
+// applyLatency records a handshake latency measurement
+func applyLatency(fp *TLSFingerprint, latency time.Duration, ttl int) {
+	if latency <= 0 {
+		return
+	}
+	fp.LatencyMicros = int(latency.Microseconds())
+	fp.JA4L = JA4L(latency, ttl)
+}
+
+// colocatedClient reports whether a public client answered the handshake
+// faster than any access network allows
+func colocatedClient(fp Fingerprint) bool {
+	return fp.TLS.JA4L != "" && !fp.Network.Local && fp.TLS.LatencyMicros < colocatedLatency
+}
+
+// isLocalAddr reports whether a host:port is a loopback, private or
+// link-local address, such as a load balancer's or a developer's
+func isLocalAddr(hostport string) bool {
+	ap, err := netip.ParseAddrPort(hostport)
+	if err != nil {
+		return false
+	}
+	a := ap.Addr().Unmap()
+	return a.IsLoopback() || a.IsPrivate() || a.IsLinkLocalUnicast()
+}
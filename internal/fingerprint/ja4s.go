@@ -0,0 +1,146 @@
+package fingerprint
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ContextKeyServerHello is the key for the ServerHelloSource of the
+// request's connection
+const ContextKeyServerHello TLSFingerprintContextKey = "server_hello"
+
+// ServerHelloSource returns the ServerHello sent on a connection, or nil
+// while it has not been seen
+type ServerHelloSource interface {
+	ServerHello() *ServerHello
+}
+
+// ServerHello holds the fields of a TLS ServerHello that JA3S and JA4S
+// are computed from
+type ServerHello struct {
+	LegacyVersion uint16   // Version in the ServerHello header (at most TLS 1.2)
+	Version       uint16   // Selected version, from supported_versions when present
+	CipherSuite   uint16   // Selected cipher suite
+	Extensions    []uint16 // Extensions in the order sent
+	ALPN          string   // Selected protocol, when sent in the ServerHello (TLS 1.2)
+}
+
+// helloRetryRandom marks a TLS 1.3 HelloRetryRequest (RFC 8446, 4.1.3)
+var helloRetryRandom = []byte{
+	0xcf, 0x21, 0xad, 0x74, 0xe5, 0x9a, 0x61, 0x11, 0xbe, 0x1d, 0x8c, 0x02, 0x1e, 0x65, 0xb8, 0x91,
+	0xc2, 0xa2, 0x11, 0x16, 0x7a, 0xbb, 0x8c, 0x5e, 0x07, 0x9e, 0x09, 0xe2, 0xc8, 0xa8, 0x33, 0x9c,
+}
+
+// ErrHelloRetryRequest is returned by ParseServerHello for a TLS 1.3
+// HelloRetryRequest, which is followed by the actual ServerHello
+var ErrHelloRetryRequest = errors.New("HelloRetryRequest")
+
+// ParseServerHello parses a TLS handshake record that starts with a
+// ServerHello
+func ParseServerHello(record []byte) (*ServerHello, error) {
+	if len(record) < 5 || record[0] != 0x16 {
+		return nil, errors.New("not a TLS handshake record")
+	}
+	body := record[5:]
+	if n := int(record[3])<<8 | int(record[4]); len(body) > n {
+		body = body[:n]
+	}
+	if len(body) < 4 || body[0] != 0x02 {
+		return nil, errors.New("not a ServerHello")
+	}
+	if n := int(body[1])<<16 | int(body[2])<<8 | int(body[3]); len(body) >= 4+n {
+		body = body[4 : 4+n]
+	} else {
+		return nil, errors.New("ServerHello truncated")
+	}
+
+	// legacy_version(2) random(32) session_id(1+n) cipher_suite(2) compression(1)
+	if len(body) < 35 || len(body) < 35+int(body[34])+3 {
+		return nil, errors.New("ServerHello truncated")
+	}
+	if string(body[2:34]) == string(helloRetryRandom) {
+		return nil, ErrHelloRetryRequest
+	}
+	sh := &ServerHello{LegacyVersion: uint16(body[0])<<8 | uint16(body[1])}
+	sh.Version = sh.LegacyVersion
+	off := 35 + int(body[34])
+	sh.CipherSuite = uint16(body[off])<<8 | uint16(body[off+1])
+	off += 3
+
+	if len(body) < off+2 {
+		return sh, nil // No extensions
+	}
+	exts := body[off+2:]
+	if n := int(body[off])<<8 | int(body[off+1]); len(exts) >= n {
+		exts = exts[:n]
+	} else {
+		return nil, errors.New("ServerHello extensions truncated")
+	}
+	for len(exts) >= 4 {
+		typ := uint16(exts[0])<<8 | uint16(exts[1])
+		n := int(exts[2])<<8 | int(exts[3])
+		if len(exts) < 4+n {
+			return nil, errors.New("ServerHello extension truncated")
+		}
+		data := exts[4 : 4+n]
+		sh.Extensions = append(sh.Extensions, typ)
+		switch typ {
+		case 0x002b: // supported_versions
+			if n == 2 {
+				sh.Version = uint16(data[0])<<8 | uint16(data[1])
+			}
+		case 0x0010: // ALPN: list length(2), protocol length(1), protocol
+			if n >= 3 && int(data[2]) <= n-3 {
+				sh.ALPN = string(data[3 : 3+int(data[2])])
+			}
+		}
+		exts = exts[4+n:]
+	}
+	return sh, nil
+}
+
+// JA3S computes the JA3S server fingerprint: the MD5 of
+// "{version},{cipher},{extensions}" in decimal, extensions in sent order.
+//
+// Reference: https://github.com/salesforce/ja3
+func JA3S(sh *ServerHello) string {
+	exts := make([]string, len(sh.Extensions))
+	for i, e := range sh.Extensions {
+		exts[i] = strconv.Itoa(int(e))
+	}
+	s := fmt.Sprintf("%d,%d,%s", sh.LegacyVersion, sh.CipherSuite, strings.Join(exts, "-"))
+	sum := md5.Sum([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+// JA4S computes the JA4S server fingerprint.
+// Format: {protocol}{version}{extensions}{alpn}_{cipher}_{extension hash}
+//
+// Example: t130200_1301_234ea6891581
+//
+// The ALPN is taken from the ServerHello as seen on the wire, so TLS 1.3
+// connections, which send it encrypted, report "00".
+//
+// Reference: https://github.com/FoxIO-LLC/ja4/blob/main/technical_details/JA4S.md
+func JA4S(sh *ServerHello) string {
+	exts := make([]string, len(sh.Extensions))
+	for i, e := range sh.Extensions {
+		exts[i] = fmt.Sprintf("%04x", e)
+	}
+	var alpn []string
+	if sh.ALPN != "" {
+		alpn = []string{sh.ALPN}
+	}
+	return fmt.Sprintf("t%s%02d%s_%04x_%s", ja4Version(sh.Version), min(len(exts), 99), ja4ALPN(alpn),
+		sh.CipherSuite, ja4Hash(strings.Join(exts, ",")))
+}
+
+// applyServerHello populates the server fingerprints
+func applyServerHello(fp *TLSFingerprint, sh *ServerHello) {
+	fp.JA3SHash = JA3S(sh)
+	fp.JA4SHash = JA4S(sh)
+}
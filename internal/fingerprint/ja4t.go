@@ -0,0 +1,173 @@
+package fingerprint
+
+import (
+	"encoding/binary"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// ContextKeyTCP is the key for the TCPFingerprint of the request's
+// connection
+const ContextKeyTCP TLSFingerprintContextKey = "tcp_fingerprint"
+
+// TCP option kinds
+const (
+	tcpOptEOL         = 0
+	tcpOptNOP         = 1
+	tcpOptMSS         = 2
+	tcpOptWindowScale = 3
+	tcpOptSACKPerm    = 4
+	tcpOptTimestamps  = 8
+)
+
+// ParseTCPSYN parses a client SYN: an IPv4 or IPv6 header followed by the
+// TCP header, as saved by the kernel (TCP_SAVED_SYN) or captured
+func ParseTCPSYN(packet []byte) (TCPFingerprint, error) {
+	var fp TCPFingerprint
+	if len(packet) < 1 {
+		return TCPFingerprint{}, errors.New("empty packet")
+	}
+	var tcp []byte
+	switch packet[0] >> 4 {
+	case 4:
+		ihl := int(packet[0]&0x0f) * 4
+		if len(packet) < 20 || ihl < 20 || len(packet) < ihl || packet[9] != 6 {
+			return TCPFingerprint{}, errors.New("not an IPv4 TCP packet")
+		}
+		fp.TTL = int(packet[8])
+		tcp = packet[ihl:]
+	case 6:
+		// Extension headers are not followed; TCP must be the next header
+		if len(packet) < 40 || packet[6] != 6 {
+			return TCPFingerprint{}, errors.New("not an IPv6 TCP packet")
+		}
+		fp.TTL = int(packet[7])
+		tcp = packet[40:]
+	default:
+		return TCPFingerprint{}, errors.New("not an IP packet")
+	}
+
+	if len(tcp) < 20 {
+		return TCPFingerprint{}, errors.New("TCP header truncated")
+	}
+	if tcp[13]&0x12 != 0x02 {
+		return TCPFingerprint{}, errors.New("not a SYN")
+	}
+	offset := int(tcp[12]>>4) * 4
+	if offset < 20 || len(tcp) < offset {
+		return TCPFingerprint{}, errors.New("TCP header truncated")
+	}
+	fp.WindowSize = int(binary.BigEndian.Uint16(tcp[14:16]))
+
+	mss, scale := "00", "00"
+	opts := tcp[20:offset]
+	for len(opts) > 0 {
+		kind := opts[0]
+		fp.Options = append(fp.Options, int(kind))
+		if kind == tcpOptEOL || kind == tcpOptNOP {
+			opts = opts[1:]
+			continue
+		}
+		if len(opts) < 2 || int(opts[1]) < 2 || len(opts) < int(opts[1]) {
+			return TCPFingerprint{}, errors.New("TCP option truncated")
+		}
+		data := opts[2:opts[1]]
+		switch {
+		case kind == tcpOptMSS && len(data) == 2:
+			fp.MSS = int(binary.BigEndian.Uint16(data))
+			mss = strconv.Itoa(fp.MSS)
+		case kind == tcpOptWindowScale && len(data) == 1:
+			fp.WindowScale = int(data[0])
+			scale = strconv.Itoa(fp.WindowScale)
+		}
+		opts = opts[opts[1]:]
+	}
+
+	kinds := "00"
+	if len(fp.Options) > 0 {
+		s := make([]string, len(fp.Options))
+		for i, k := range fp.Options {
+			s[i] = strconv.Itoa(k)
+		}
+		kinds = strings.Join(s, "-")
+	}
+	fp.Available = true
+	fp.JA4T = strconv.Itoa(fp.WindowSize) + "_" + kinds + "_" + mss + "_" + scale
+	return fp, nil
+}
+
+// TCP stacks told apart by their SYN
+const (
+	tcpOSWindows = "windows"
+	tcpOSLinux   = "linux" // Also Android and ChromeOS
+	tcpOSApple   = "apple" // macOS and iOS
+)
+
+// SYN option layouts of the default stacks
+var (
+	linuxSYNOptions = []int{tcpOptMSS, tcpOptSACKPerm, tcpOptTimestamps, tcpOptNOP, tcpOptWindowScale}
+	appleSYNOptions = []int{tcpOptMSS, tcpOptNOP, tcpOptWindowScale, tcpOptNOP, tcpOptNOP, tcpOptTimestamps, tcpOptSACKPerm}
+)
+
+// tcpOS guesses the operating system that sent a SYN from its initial TTL
+// and option layout, or returns "" when it matches none of the defaults.
+// Windows starts at TTL 128 and sends no timestamps; Linux and Apple start
+// at 64 and order their options differently.
+func tcpOS(tcp TCPFingerprint) string {
+	if !tcp.Available || tcp.TTL == 0 {
+		return ""
+	}
+	switch initialTTL(tcp.TTL) {
+	case 128:
+		if !slices.Contains(tcp.Options, tcpOptTimestamps) {
+			return tcpOSWindows
+		}
+	case 64:
+		switch {
+		case hasPrefix(tcp.Options, linuxSYNOptions):
+			return tcpOSLinux
+		case hasPrefix(tcp.Options, appleSYNOptions):
+			return tcpOSApple
+		}
+	}
+	return ""
+}
+
+// initialTTL rounds a received TTL up to the common initial values
+func initialTTL(ttl int) int {
+	switch {
+	case ttl <= 64:
+		return 64
+	case ttl <= 128:
+		return 128
+	}
+	return 255
+}
+
+// hasPrefix reports whether s starts with prefix
+func hasPrefix(s, prefix []int) bool {
+	return len(s) >= len(prefix) && slices.Equal(s[:len(prefix)], prefix)
+}
+
+// userAgentOS returns the TCP stack of the operating system a User-Agent
+// claims, or "" when it names none
+func userAgentOS(ua ParsedUserAgent) string {
+	switch ua.OS {
+	case "Windows":
+		return tcpOSWindows
+	case "iOS", "macOS":
+		return tcpOSApple
+	case "Android", "Linux", "Chrome OS":
+		return tcpOSLinux
+	}
+	return ""
+}
+
+// tcpOSMismatch reports whether the SYN came from another operating system
+// than the User-Agent claims
+func tcpOSMismatch(tcp TCPFingerprint, ua ParsedUserAgent) bool {
+	synOS, uaOS := tcpOS(tcp), userAgentOS(ua)
+	return synOS != "" && uaOS != "" && synOS != uaOS
+}
@@ -0,0 +1,135 @@
+package fingerprint
+
+import (
+	"bufio"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// KnownFingerprint is a JA3 hash or JA4 fingerprint of a known client
+type KnownFingerprint struct {
+	Hash   string `json:"hash"`   // JA3 hash or JA4 fingerprint, lowercase
+	Label  string `json:"label"`  // "bot" or "browser"
+	Client string `json:"client"` // e.g. "curl 7.88", "Chrome"
+}
+
+// FingerprintDB indexes known fingerprints by hash. It is never modified
+// once built, so classifiers share it.
+type FingerprintDB struct {
+	byHash map[string]KnownFingerprint
+}
+
+// knownFingerprintPattern matches JA3 hashes and JA4 fingerprints
+var knownFingerprintPattern = regexp.MustCompile(`^([0-9a-f]{32}|[tqd][0-9a-z]{9}_[0-9a-f]{12}_[0-9a-f]{12})$`)
+
+//go:embed known/fingerprints.jsonl
+var knownFS embed.FS
+
+// defaultFingerprintDB is built from the embedded list on first use
+var defaultFingerprintDB = sync.OnceValue(func() *FingerprintDB {
+	f, err := knownFS.Open("known/fingerprints.jsonl")
+	if err != nil {
+		panic(err)
+	}
+	defer func() { _ = f.Close() }()
+	db, err := ReadFingerprintDB(f)
+	if err != nil {
+		panic(fmt.Sprintf("known/fingerprints.jsonl: %v", err))
+	}
+	return db
+})
+
+// DefaultFingerprintDB returns the built-in fingerprints: the HTTP
+// libraries and browsers of the golden corpus
+func DefaultFingerprintDB() *FingerprintDB {
+	return defaultFingerprintDB()
+}
+
+// ReadFingerprintDB decodes known fingerprints in JSONL, one object per
+// line, skipping blank lines. A hash listed twice keeps its last entry.
+func ReadFingerprintDB(r io.Reader) (*FingerprintDB, error) {
+	scanner := bufio.NewScanner(r)
+	db := &FingerprintDB{byHash: map[string]KnownFingerprint{}}
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var k KnownFingerprint
+		if err := json.Unmarshal(data, &k); err != nil {
+			return nil, fmt.Errorf("invalid entry on line %d: %w", line, err)
+		}
+		k.Hash = strings.ToLower(k.Hash)
+		if err := k.validate(); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		db.byHash[k.Hash] = k
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+// LoadFingerprintDB reads known fingerprints from a file on top of the
+// built-in ones, which its entries override
+func LoadFingerprintDB(path string) (*FingerprintDB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	db, err := ReadFingerprintDB(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return DefaultFingerprintDB().Merge(db), nil
+}
+
+// validate checks the hash format and label of an entry
+func (k KnownFingerprint) validate() error {
+	if !knownFingerprintPattern.MatchString(k.Hash) {
+		return fmt.Errorf("%q is neither a JA3 hash nor a JA4 fingerprint", k.Hash)
+	}
+	if k.Label != "bot" && k.Label != "browser" {
+		return fmt.Errorf("%s: label must be bot or browser, got %q", k.Hash, k.Label)
+	}
+	if k.Client == "" {
+		return errors.New(k.Hash + ": missing client")
+	}
+	return nil
+}
+
+// Merge returns the fingerprints of db and other, other's winning
+func (db *FingerprintDB) Merge(other *FingerprintDB) *FingerprintDB {
+	merged := &FingerprintDB{byHash: maps.Clone(db.byHash)}
+	maps.Copy(merged.byHash, other.byHash)
+	return merged
+}
+
+// Len returns the number of known fingerprints
+func (db *FingerprintDB) Len() int {
+	return len(db.byHash)
+}
+
+// Lookup returns the known fingerprint matching the JA4 or, failing that,
+// the JA3 of a ClientHello
+func (db *FingerprintDB) Lookup(tls TLSFingerprint) (KnownFingerprint, bool) {
+	if k, ok := db.byHash[strings.ToLower(tls.JA4Hash)]; ok && tls.JA4Hash != "" {
+		return k, true
+	}
+	if k, ok := db.byHash[strings.ToLower(tls.JA3Hash)]; ok && tls.JA3Hash != "" {
+		return k, true
+	}
+	return KnownFingerprint{}, false
+}
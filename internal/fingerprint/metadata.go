@@ -0,0 +1,83 @@
+package fingerprint
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// RequestMetadata describes an HTTP request observed elsewhere (a remote
+// service, an edge runtime, a log), used to rebuild it for the collector.
+// TLS details cannot be carried, so only HTTP-level signals are available.
+type RequestMetadata struct {
+	Method     string              `json:"method"`
+	Proto      string              `json:"proto"`       // e.g. "HTTP/1.1", "HTTP/2.0"
+	Host       string              `json:"host"`        // Host header value
+	Path       string              `json:"path"`        // Request path including query
+	Headers    map[string][]string `json:"headers"`     // Request headers
+	RemoteAddr string              `json:"remote_addr"` // Client address (ip:port)
+}
+
+// MetadataFromRequest captures the metadata of an HTTP request
+func MetadataFromRequest(r *http.Request) RequestMetadata {
+	return RequestMetadata{
+		Method:     r.Method,
+		Proto:      r.Proto,
+		Host:       r.Host,
+		Path:       r.URL.RequestURI(),
+		Headers:    r.Header,
+		RemoteAddr: r.RemoteAddr,
+	}
+}
+
+// HTTPRequest reconstructs an *http.Request from the metadata
+func (m RequestMetadata) HTTPRequest(ctx context.Context) (*http.Request, error) {
+	method := m.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+	path := m.Path
+	if path == "" {
+		path = "/"
+	}
+	if !strings.HasPrefix(path, "/") {
+		return nil, fmt.Errorf("path must start with '/': %q", path)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, path, http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	proto := m.Proto
+	if proto == "" {
+		proto = "HTTP/1.1"
+	}
+	major, minor, ok := http.ParseHTTPVersion(proto)
+	if !ok {
+		// HTTP/2 and HTTP/3 are reported without a minor version by some servers
+		switch proto {
+		case "HTTP/2":
+			major, minor = 2, 0
+		case "HTTP/3":
+			major, minor = 3, 0
+		default:
+			return nil, fmt.Errorf("invalid proto: %q", proto)
+		}
+		proto += ".0"
+	}
+	req.Proto, req.ProtoMajor, req.ProtoMinor = proto, major, minor
+
+	req.Header = make(http.Header, len(m.Headers))
+	for name, values := range m.Headers {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	req.Host = m.Host
+	req.RemoteAddr = m.RemoteAddr
+	req.ContentLength = 0
+
+	return req, nil
+}
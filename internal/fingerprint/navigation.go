@@ -0,0 +1,47 @@
+package fingerprint
+
+import (
+	"path"
+	"strings"
+)
+
+// Navigation realism limits. A browser loading pages also fetches their
+// stylesheets, scripts, images and favicon; a scraper fetches the pages
+// alone, one after another.
+const (
+	MinNavigationPages = 5    // Page requests in a session before navigation is judged
+	RapidNavigationMs  = 2000 // Mean gap between requests below which browsing is rapid
+)
+
+// assetExtensions are the file extensions of page subresources
+var assetExtensions = map[string]bool{
+	".css": true, ".js": true, ".mjs": true, ".map": true, ".json": true,
+	".png": true, ".jpg": true, ".jpeg": true, ".gif": true, ".webp": true, ".avif": true, ".svg": true, ".ico": true,
+	".woff": true, ".woff2": true, ".ttf": true, ".otf": true,
+	".mp4": true, ".webm": true, ".mp3": true,
+}
+
+// IsAssetRequest reports whether a request fetches a page's subresource
+// rather than a page: by Sec-Fetch-Dest when the client sends it, else by
+// the file extension of the path (favicon.ico included) or an Accept for
+// images or stylesheets only
+func IsAssetRequest(h HTTPFingerprint) bool {
+	if h.SecFetchDest != "" {
+		return IsSubresourceFetch(h)
+	}
+	p, _, _ := strings.Cut(h.Path, "?")
+	if assetExtensions[strings.ToLower(path.Ext(p))] {
+		return true
+	}
+	accept := strings.ToLower(h.Accept)
+	return strings.HasPrefix(accept, "image/") || strings.HasPrefix(accept, "text/css")
+}
+
+// contentOnlyNavigation reports whether a session requested several pages
+// in rapid sequence without fetching a single asset. Sessions whose
+// assets are served from elsewhere, such as a CDN, look the same, which
+// is why the pages must also come faster than anyone reads them.
+func contentOnlyNavigation(sess SessionFingerprint) bool {
+	return sess.PageRequests >= MinNavigationPages && sess.AssetRequests == 0 &&
+		sess.MeanIntervalMs < RapidNavigationMs
+}
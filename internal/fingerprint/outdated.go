@@ -0,0 +1,36 @@
+package fingerprint
+
+// DefaultMaxBrowserLag is how many major releases a browser may trail the
+// newest before it counts as outdated: about two years of four-week
+// releases
+const DefaultMaxBrowserLag = 24
+
+// latestBrowserMajors are the newest stable major versions of the browser
+// families that ship every four weeks, as of this release. Families on a
+// yearly cadence (Safari, Samsung Internet) would take decades to trail by
+// a useful number of releases and are left out.
+var latestBrowserMajors = map[string]int{
+	"Chrome":   141,
+	"Chromium": 141,
+	"Edge":     141,
+	"Firefox":  143,
+}
+
+// browserVersionLag returns how many major releases a User-Agent's browser
+// trails the newest of its family, or 0 when it is current, newer than the
+// table or not a tracked family
+func browserVersionLag(ua ParsedUserAgent) int {
+	latest, ok := latestBrowserMajors[ua.Family]
+	if !ok || ua.Major == 0 {
+		return 0
+	}
+	return max(latest-ua.Major, 0)
+}
+
+// maxBrowserLag returns the configured lag, or DefaultMaxBrowserLag
+func (r Rules) maxBrowserLag() int {
+	if r.MaxBrowserLag > 0 {
+		return r.MaxBrowserLag
+	}
+	return DefaultMaxBrowserLag
+}
@@ -0,0 +1,128 @@
+package fingerprint
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ContextKeyQUIC is the key for the QUICFingerprint of the request's
+// connection
+const ContextKeyQUIC TLSFingerprintContextKey = "quic_fingerprint"
+
+// extQUICTransportParameters is the ClientHello extension only QUIC
+// clients send
+const extQUICTransportParameters = 57
+
+// QUIC transport parameter IDs (RFC 9000, section 18.2)
+const (
+	quicMaxIdleTimeout        = 0x01
+	quicMaxUDPPayloadSize     = 0x03
+	quicInitialMaxData        = 0x04
+	quicInitialMaxStreamsBidi = 0x08
+	quicInitialMaxStreamsUni  = 0x09
+)
+
+// ParseQUICClientHello parses the transport parameters of a ClientHello
+// record read from the Initial packets of QUIC version
+func ParseQUICClientHello(record []byte, version uint32) (QUICFingerprint, error) {
+	params, ok := clientHelloExtension(record, extQUICTransportParameters)
+	if !ok {
+		return QUICFingerprint{}, errors.New("no QUIC transport parameters")
+	}
+	fp := QUICFingerprint{Available: true, Version: quicVersionName(version)}
+	for len(params) > 0 {
+		id, n := quicVarint(params)
+		if n == 0 {
+			return QUICFingerprint{}, errors.New("transport parameter truncated")
+		}
+		params = params[n:]
+		length, n := quicVarint(params)
+		if n == 0 || uint64(len(params)-n) < length {
+			return QUICFingerprint{}, errors.New("transport parameter truncated")
+		}
+		value := params[n : n+int(length)]
+		params = params[n+int(length):]
+
+		fp.TransportParameters = append(fp.TransportParameters, int(id))
+		v, _ := quicVarint(value)
+		switch id {
+		case quicMaxIdleTimeout:
+			fp.MaxIdleTimeout = int(v)
+		case quicMaxUDPPayloadSize:
+			fp.MaxUDPPayloadSize = int(v)
+		case quicInitialMaxData:
+			fp.InitialMaxData = int(v)
+		case quicInitialMaxStreamsBidi:
+			fp.InitialMaxStreamsBidi = int(v)
+		case quicInitialMaxStreamsUni:
+			fp.InitialMaxStreamsUni = int(v)
+		}
+	}
+	return fp, nil
+}
+
+// quicVersionName names the QUIC versions of RFC 9000 and RFC 9369
+func quicVersionName(v uint32) string {
+	switch v {
+	case 0x00000001:
+		return "v1"
+	case 0x6b3343cf:
+		return "v2"
+	}
+	return fmt.Sprintf("0x%08x", v)
+}
+
+// quicVarint reads a QUIC variable-length integer, returning its value
+// and size, or a size of 0 when b is too short
+func quicVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	n := 1 << (b[0] >> 6)
+	if len(b) < n {
+		return 0, 0
+	}
+	v := uint64(b[0] & 0x3f)
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+	return v, n
+}
+
+// clientHelloExtension returns the data of extension typ in a ClientHello
+// record
+func clientHelloExtension(record []byte, typ uint16) ([]byte, bool) {
+	// record header(5) + handshake header(4) + version(2) + random(32)
+	p := 5 + 4 + 2 + 32
+	if len(record) < p+1 {
+		return nil, false
+	}
+	p += 1 + int(record[p]) // session id
+	if len(record) < p+2 {
+		return nil, false
+	}
+	p += 2 + (int(record[p])<<8 | int(record[p+1])) // cipher suites
+	if len(record) < p+1 {
+		return nil, false
+	}
+	p += 1 + int(record[p]) // compression methods
+	if len(record) < p+2 {
+		return nil, false
+	}
+	end := min(p+2+(int(record[p])<<8|int(record[p+1])), len(record))
+	p += 2
+
+	for p+4 <= end {
+		extType := uint16(record[p])<<8 | uint16(record[p+1])
+		extLen := int(record[p+2])<<8 | int(record[p+3])
+		p += 4
+		if p+extLen > end {
+			return nil, false
+		}
+		if extType == typ {
+			return record[p : p+extLen], true
+		}
+		p += extLen
+	}
+	return nil, false
+}
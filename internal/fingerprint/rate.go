@@ -0,0 +1,49 @@
+package fingerprint
+
+// Request rate limits. Rates count page requests only (see
+// IsSubresourceFetch), so a browser fetching a page's images and scripts
+// does not add to them.
+const (
+	HighAddrRequestRate = 120 // Page requests per minute from one address
+	HighJA4RequestRate  = 600 // Page requests per minute with one JA4 no browser has
+	BurstRequests       = 10  // Page requests within one second
+)
+
+// IsSubresourceFetch reports whether a request is a browser's fetch of a
+// subresource or an API call from a page (Sec-Fetch-Dest other than
+// document or iframe), which page loads send in bursts and rate tracking
+// leaves out
+func IsSubresourceFetch(h HTTPFingerprint) bool {
+	return h.SecFetchDest != "" && h.SecFetchDest != "document" && h.SecFetchDest != "iframe"
+}
+
+// extractRateSignals flags request volume no person browsing produces.
+// Address rates are not judged for local addresses, which are a proxy's,
+// nor for Private Relay egresses shared by many users; JA4 rates not for
+// browsers' fingerprints, which every visitor on that browser shares, nor
+// for intercepting proxies.
+func extractRateSignals(s *Signals, fp Fingerprint) {
+	r := fp.Rate
+	addr := !fp.Network.Local && !fp.Network.PrivateRelay
+	ja4 := !s.KnownBrowserFingerprint && !s.TLSIntercepted
+	s.HighRequestRate = addr && r.AddrRequests > HighAddrRequestRate || ja4 && r.JA4Requests > HighJA4RequestRate
+	s.BurstPattern = addr && r.AddrBurst > BurstRequests || ja4 && r.JA4Burst > BurstRequests
+}
+
+// rateKey summarizes the rate limits a request exceeds, for VerdictKey
+func rateKey(r RateFingerprint) string {
+	key := []byte("----")
+	if r.AddrRequests > HighAddrRequestRate {
+		key[0] = 'a'
+	}
+	if r.AddrBurst > BurstRequests {
+		key[1] = 'b'
+	}
+	if r.JA4Requests > HighJA4RequestRate {
+		key[2] = 'j'
+	}
+	if r.JA4Burst > BurstRequests {
+		key[3] = 'k'
+	}
+	return string(key)
+}
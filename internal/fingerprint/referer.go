@@ -0,0 +1,59 @@
+package fingerprint
+
+import (
+	"net"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// refererSchemes are the schemes browsers send in Referer. Android apps
+// opening links in a browser send android-app:// referrers.
+var refererSchemes = []string{"http", "https", "android-app"}
+
+// extractRefererSignals checks that a Referer is one a browser could have
+// sent. Browsers serialize the referrer URL themselves: it is absolute,
+// has at least a "/" path ("https://google.com/", never
+// "https://google.com"), and carries no fragment or credentials. Its
+// origin must also agree with Sec-Fetch-Site.
+func extractRefererSignals(s *Signals, h HTTPFingerprint) {
+	ref := h.Headers["referer"]
+	if ref == "" {
+		return
+	}
+	s.SpoofedReferer = !plausibleReferer(ref, h.Host, h.SecFetchSite)
+}
+
+// plausibleReferer reports whether a browser could send ref to host with
+// the given Sec-Fetch-Site. Origin checks need the request host and are
+// skipped without it; ports are ignored.
+func plausibleReferer(ref, host, site string) bool {
+	u, err := url.Parse(ref)
+	if err != nil || !slices.Contains(refererSchemes, u.Scheme) || u.Host == "" {
+		return false
+	}
+	if u.Path == "" || u.User != nil || strings.Contains(ref, "#") {
+		return false
+	}
+
+	switch site {
+	case "none":
+		// Typed URLs and bookmarks are sent without a Referer
+		return false
+	case "same-origin":
+		return host == "" || strings.EqualFold(u.Hostname(), hostname(host))
+	case "cross-site":
+		// An HTTPS page of the same host is same-origin, or same-site for
+		// a plain HTTP request; browsers drop the Referer on downgrade
+		return host == "" || u.Scheme != "https" || !strings.EqualFold(u.Hostname(), hostname(host))
+	}
+	return true
+}
+
+// hostname strips the port from a Host header value
+func hostname(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		return h
+	}
+	return strings.Trim(host, "[]")
+}
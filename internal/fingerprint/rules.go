@@ -0,0 +1,210 @@
+package fingerprint
+
+import (
+	"maps"
+	"slices"
+)
+
+// ScoringRule describes one rule of the scoring model
+type ScoringRule struct {
+	Name   string // Name shown in Signals.ScoreBreakdown, e.g. "sec-fetch"
+	Bot    bool   // Scores towards bot instead of browser
+	Weight int    // Default points added when the rule fires
+}
+
+// scoringRules lists every rule calculateScores can fire, in breakdown order
+var scoringRules = []ScoringRule{
+	// Browser-positive
+	{Name: "http2", Weight: 2},
+	{Name: "http3", Weight: 2},
+	{Name: "sec-fetch", Weight: 3},
+	{Name: "accept-lang", Weight: 1},
+	{Name: "browser-headers", Weight: 1},
+	{Name: "browser-ua", Weight: 2},
+	{Name: "sec-ch-ua", Weight: 2},
+	{Name: "cookies", Weight: 1},
+	{Name: "headers>=10", Weight: 1},
+	{Name: "modern-tls", Weight: 1},
+	{Name: "high-ciphers", Weight: 2},
+	{Name: "session-ticket", Weight: 1},
+	{Name: "multi-groups", Weight: 1},
+	{Name: "tls-ext>=10", Weight: 1},
+	{Name: "grease", Weight: 1},
+	{Name: "ech", Weight: 1},
+	{Name: "ja4h-headers>=10", Weight: 1},
+	{Name: "ja4h-referer", Weight: 1},
+	{Name: "ja4h-consistent", Weight: 1},
+	{Name: "private-token", Weight: 5},
+	{Name: "challenge-pass", Weight: 6},
+	{Name: "captcha-pass", Weight: 6},
+	{Name: "cors-preflight", Weight: 2},
+	{Name: "known-browser-fp", Weight: 3},
+
+	// Bot-positive
+	{Name: "bot-ua", Bot: true, Weight: 3},
+	{Name: "ai-crawler", Bot: true, Weight: 2},
+	{Name: "spoofed-crawler", Bot: true, Weight: 8},
+	{Name: "outdated-browser", Bot: true, Weight: 2},
+	{Name: "low-headers", Bot: true, Weight: 2},
+	{Name: "missing-typical", Bot: true, Weight: 1},
+	{Name: "no-ua", Bot: true, Weight: 2},
+	{Name: "http1.1", Bot: true, Weight: 1},
+	{Name: "accept-*/*-", Bot: true, Weight: 1},
+	{Name: "no-accept-lang", Bot: true, Weight: 1},
+	{Name: "spoofed-referer", Bot: true, Weight: 2},
+	{Name: "fetch-metadata-mismatch", Bot: true, Weight: 2},
+	{Name: "spoofing", Bot: true, Weight: 3},
+	{Name: "header-case", Bot: true, Weight: 2},
+	{Name: "client-hints-mismatch", Bot: true, Weight: 3},
+	{Name: "geo-lang-mismatch", Bot: true, Weight: 1},
+	{Name: "datacenter", Bot: true, Weight: 2},
+	{Name: "anonymized-network", Bot: true, Weight: 2},
+	{Name: "tcp-os-mismatch", Bot: true, Weight: 2},
+	{Name: "colocated", Bot: true, Weight: 1},
+	{Name: "library-ext-order", Bot: true, Weight: 2},
+	{Name: "tls-impersonation", Bot: true, Weight: 4},
+	{Name: "known-bot-fp", Bot: true, Weight: 6},
+	{Name: "low-ciphers", Bot: true, Weight: 1},
+	{Name: "few-tls-ext", Bot: true, Weight: 1},
+	{Name: "no-session", Bot: true, Weight: 1},
+	{Name: "ja4h-no-lang", Bot: true, Weight: 1},
+	{Name: "ja4h-low-headers", Bot: true, Weight: 1},
+	{Name: "ja4h-inconsistent", Bot: true, Weight: 2},
+	{Name: "regular-timing", Bot: true, Weight: 2},
+	{Name: "sub-human-gaps", Bot: true, Weight: 1},
+	{Name: "high-rate", Bot: true, Weight: 3},
+	{Name: "burst", Bot: true, Weight: 2},
+	{Name: "content-only", Bot: true, Weight: 2},
+	{Name: "bad-preflight", Bot: true, Weight: 2},
+	{Name: "head", Bot: true, Weight: 1},
+	{Name: "challenge-fail", Bot: true, Weight: 4},
+	{Name: "honeypot", Bot: true, Weight: 40},
+	{Name: "repeat-offender", Bot: true, Weight: 4},
+	{Name: "geo-lang-corroborated", Bot: true, Weight: 2},
+}
+
+// defaultWeights indexes scoringRules by name
+var defaultWeights = func() map[string]int {
+	m := make(map[string]int, len(scoringRules))
+	for _, r := range scoringRules {
+		m[r.Name] = r.Weight
+	}
+	return m
+}()
+
+// ScoringRules returns the built-in scoring rules with their default weights
+func ScoringRules() []ScoringRule {
+	return slices.Clone(scoringRules)
+}
+
+// LookupScoringRule returns the built-in rule with the given name
+func LookupScoringRule(name string) (ScoringRule, bool) {
+	for _, r := range scoringRules {
+		if r.Name == name {
+			return r, true
+		}
+	}
+	return ScoringRule{}, false
+}
+
+// Rules holds the User-Agent patterns and rule weights used to extract
+// signals and scores. Patterns are lowercase substrings of the User-Agent.
+type Rules struct {
+	BotPatterns       []string
+	AICrawlerPatterns []string
+	BrowserPatterns   []string
+
+	// InterceptorFingerprints lists JA3 hashes and JA4 fingerprints of
+	// TLS-intercepting proxies, compared case-insensitively. None are
+	// built in: they differ per product version and deployment.
+	InterceptorFingerprints []string
+
+	// Fingerprints lists the JA3/JA4 of known bots and browsers (nil =
+	// DefaultFingerprintDB). It is shared, not copied, by Clone.
+	Fingerprints *FingerprintDB
+
+	// MaxBrowserLag is how many major releases a browser User-Agent may
+	// trail the newest of its family before outdated-browser fires (0 =
+	// DefaultMaxBrowserLag)
+	MaxBrowserLag int
+
+	// Weights overrides default rule weights by rule name.
+	// A weight of 0 disables the rule.
+	Weights map[string]int
+
+	// Custom lists rules defined in configuration, scored after the
+	// built-in rules of their side
+	Custom []CustomRule
+}
+
+// defaultRules is shared by ExtractSignals and must not be modified
+var defaultRules = Rules{
+	BotPatterns:       botPatterns,
+	AICrawlerPatterns: aiCrawlerPatterns,
+	BrowserPatterns:   browserPatterns,
+}
+
+// DefaultRules returns a copy of the built-in patterns with default weights
+func DefaultRules() Rules {
+	return Rules{
+		BotPatterns:       slices.Clone(botPatterns),
+		AICrawlerPatterns: slices.Clone(aiCrawlerPatterns),
+		BrowserPatterns:   slices.Clone(browserPatterns),
+	}
+}
+
+// Clone returns a deep copy of the rules
+func (r Rules) Clone() Rules {
+	return Rules{
+		BotPatterns:       slices.Clone(r.BotPatterns),
+		AICrawlerPatterns: slices.Clone(r.AICrawlerPatterns),
+		BrowserPatterns:   slices.Clone(r.BrowserPatterns),
+		Weights:           maps.Clone(r.Weights),
+		Custom:            slices.Clone(r.Custom),
+
+		InterceptorFingerprints: slices.Clone(r.InterceptorFingerprints),
+		Fingerprints:            r.Fingerprints,
+		MaxBrowserLag:           r.MaxBrowserLag,
+	}
+}
+
+// Weight returns the points the named rule adds when it fires
+func (r Rules) Weight(name string) int {
+	if w, ok := r.Weights[name]; ok {
+		return w
+	}
+	for _, c := range r.Custom {
+		if c.Name == name {
+			return c.Weight
+		}
+	}
+	return defaultWeights[name]
+}
+
+// Lookup returns the custom or built-in rule with the given name
+func (r Rules) Lookup(name string) (ScoringRule, bool) {
+	for _, c := range r.Custom {
+		if c.Name == name {
+			return c.ScoringRule, true
+		}
+	}
+	return LookupScoringRule(name)
+}
+
+// scoreSheet accumulates the points and breakdown of one side
+type scoreSheet struct {
+	rules     Rules
+	direction string
+	score     int
+	fired     Breakdown
+}
+
+// add records a fired rule, skipping rules disabled with a zero weight
+func (s *scoreSheet) add(name string) {
+	w := s.rules.Weight(name)
+	if w == 0 {
+		return
+	}
+	s.score += w
+	s.fired = append(s.fired, SignalContribution{Name: name, Direction: s.direction, Weight: w})
+}
@@ -1,6 +1,10 @@
 package fingerprint
 
-import "strings"
+import (
+	"net/http"
+	"slices"
+	"strings"
+)
 
 // Known bot User-Agent patterns
 var botPatterns = []string{
@@ -97,10 +101,16 @@ var browserPatterns = []string{
 
 // ExtractSignals analyzes fingerprint and extracts classification signals
 func ExtractSignals(fp Fingerprint) Signals {
+	return ExtractSignalsWithRules(fp, defaultRules)
+}
+
+// ExtractSignalsWithRules extracts signals using the given patterns and weights
+func ExtractSignalsWithRules(fp Fingerprint, rules Rules) Signals {
 	s := Signals{}
 
 	// TLS signals (from ClientHello fingerprint)
 	s.IsHTTP2 = fp.HTTP.Version == "HTTP/2.0" || fp.TLS.ALPN == "h2"
+	s.IsHTTP3 = fp.HTTP.Version == "HTTP/3.0" || fp.TLS.ALPN == "h3"
 	s.HasModernTLS = fp.TLS.Version == "TLS 1.2" || fp.TLS.Version == "TLS 1.3"
 	s.HasALPN = fp.TLS.ALPN != ""
 	s.HighCipherCount = fp.TLS.CipherSuitesCount > 10 // Browsers typically have 15-20
@@ -108,6 +118,10 @@ func ExtractSignals(fp Fingerprint) Signals {
 	s.HasTLSFingerprint = fp.TLS.JA3Hash != "" || fp.TLS.JA4Hash != ""
 	s.HasMultipleGroups = len(fp.TLS.SupportedGroups) >= 3 // Browsers support multiple curves
 	s.HasModernCiphers = fp.TLS.Version == "TLS 1.3" && fp.TLS.CipherSuitesCount > 0
+	s.HasGREASECiphers = fp.TLS.GREASECiphers
+	s.HasGREASEExtensions = fp.TLS.GREASEExtensions
+	s.HasGREASEGroups = slices.Contains(fp.TLS.SupportedGroups, "GREASE")
+	s.HasECH = fp.TLS.ECH
 
 	// HTTP signals
 	s.HasSecFetchHeaders = fp.HTTP.SecFetchSite != "" ||
@@ -118,6 +132,11 @@ func ExtractSignals(fp Fingerprint) Signals {
 	s.HasAccept = fp.HTTP.Accept != ""
 	s.HasAcceptEncoding = fp.HTTP.AcceptEnc != ""
 	s.HasSecClientHints = fp.HTTP.SecChUA != ""
+	extractRefererSignals(&s, fp.HTTP)
+	s.FetchMetadataConflict = fetchMetadataMismatch(fp.HTTP)
+	s.FetchMetadataMismatch = s.FetchMetadataConflict != ""
+	s.SpoofingCombos = impossibleCombos(fp)
+	s.HeaderSpoofing = len(s.SpoofingCombos) > 0
 
 	// JA4H signals (HTTP fingerprint)
 	s.HasJA4HFingerprint = fp.HTTP.JA4HHash != ""
@@ -125,23 +144,171 @@ func ExtractSignals(fp Fingerprint) Signals {
 		extractJA4HSignals(&s, fp.HTTP.JA4HHash, fp)
 	}
 
+	// Network signals (looked up by an enricher before extraction)
+	s.FromPrivateRelay = fp.Network.PrivateRelay
+	s.ClaimedCrawler = fp.Network.ClaimedCrawler
+	s.SpoofedCrawler = fp.Network.CrawlerVerification == CrawlerSpoofed
+	if fp.Network.Hosting != "" && !fp.Network.PrivateRelay && fp.Network.CrawlerVerification != CrawlerVerified {
+		s.DatacenterASN = true
+		s.HostingProvider = fp.Network.Hosting
+	}
+	if fp.Network.Anonymizer != "" && !fp.Network.PrivateRelay {
+		s.AnonymizedNetwork = true
+		s.Anonymizer = fp.Network.Anonymizer
+	}
+	extractGeoSignals(&s, fp)
+
+	// Attestation signals (verified by an enricher before extraction)
+	s.HasValidPrivateToken = fp.HTTP.PrivateToken == PrivateTokenValid
+	s.ChallengeTokenPassed = fp.HTTP.ChallengeToken == ChallengeTokenPass
+	s.ChallengeTokenFailed = fp.HTTP.ChallengeToken == ChallengeTokenFail
+	s.CaptchaSolved = fp.Session.HumanVerified
+
+	// Request method signals
+	extractMethodSignals(&s, fp.HTTP)
+
 	// User-Agent analysis
 	uaLower := strings.ToLower(fp.HTTP.UserAgent)
-	s.UserAgentIsBot = containsAny(uaLower, botPatterns)
-	s.UserAgentIsAICrawler = containsAny(uaLower, aiCrawlerPatterns)
-	s.UserAgentIsBrowser = containsAny(uaLower, browserPatterns) && !s.UserAgentIsBot
+	s.MatchedBotPatterns = matchingPatterns(uaLower, rules.BotPatterns)
+	s.MatchedAICrawlerPatterns = matchingPatterns(uaLower, rules.AICrawlerPatterns)
+	s.MatchedBrowserPatterns = matchingPatterns(uaLower, rules.BrowserPatterns)
+	s.UserAgentIsBot = len(s.MatchedBotPatterns) > 0
+	s.UserAgentIsAICrawler = len(s.MatchedAICrawlerPatterns) > 0
+	s.UserAgentIsBrowser = len(s.MatchedBrowserPatterns) > 0 && !s.UserAgentIsBot
+	if s.UserAgentIsBrowser {
+		s.BrowserVersionLag = browserVersionLag(ParseUserAgent(fp.HTTP.UserAgent))
+		s.OutdatedBrowser = s.BrowserVersionLag > rules.maxBrowserLag()
+	}
+
+	// TLS interception (needs the User-Agent verdict)
+	if fp.TLS.Available {
+		s.TLSIntercepted = tlsIntercepted(s, fp.TLS, uaLower, rules)
+	}
+
+	// TCP stack against the claimed OS, unless a proxy or relay opened the
+	// connection (needs the User-Agent and TLS verdicts)
+	if fp.TCP.Available && s.UserAgentIsBrowser && !s.TLSIntercepted && !s.FromPrivateRelay {
+		s.TCPOSMismatch = tcpOSMismatch(fp.TCP, ParseUserAgent(fp.HTTP.UserAgent))
+	}
+
+	// Handshake answered from next to the server, unless by a proxy or
+	// relay, which may well be hosted there
+	s.ColocatedClient = s.UserAgentIsBrowser && !s.TLSIntercepted && !s.FromPrivateRelay && colocatedClient(fp)
+
+	// ClientHello extension order of a TLS library under a browser
+	// User-Agent, unless a proxy sent it
+	if s.UserAgentIsBrowser && !s.TLSIntercepted {
+		s.ExtensionOrderStack = extensionOrderStack(fp.TLS.Extensions)
+		s.LibraryExtensionOrder = s.ExtensionOrderStack != ""
+	}
+
+	// TLS stack against the one the browser of the User-Agent ships,
+	// unless a proxy sent the ClientHello
+	if fp.TLS.Available {
+		s.TLSStack = tlsStack(fp.TLS)
+		s.TLSImpersonation = s.UserAgentIsBrowser && !s.TLSIntercepted &&
+			tlsImpersonation(s.TLSStack, ParseUserAgent(fp.HTTP.UserAgent))
+	}
+
+	// JA3/JA4 listed in the fingerprint database, unless a proxy sent the
+	// ClientHello
+	if fp.TLS.Available && !s.TLSIntercepted {
+		extractKnownFingerprint(&s, fp.TLS, rules)
+	}
+
+	// Header names as sent (needs the User-Agent verdict)
+	s.NonCanonicalHeaderCase = s.UserAgentIsBrowser && nonCanonicalHeaderCase(fp.HTTP)
+
+	// Client Hints against the User-Agent they should agree with
+	if s.UserAgentIsBrowser {
+		s.ClientHintsConflict = clientHintsMismatch(fp.HTTP)
+		s.ClientHintsMismatch = s.ClientHintsConflict != ""
+	}
 
 	// Header analysis
 	s.LowHeaderCount = fp.HTTP.HeaderCount < 5
 	s.HasBrowserHeaders = s.HasSecFetchHeaders || s.HasAcceptLanguage
 	s.MissingTypicalHeader = !s.HasAccept || !s.HasAcceptEncoding
 
+	// Behavioral analysis (session timing, request rates, past offenses)
+	if fp.Session.Available {
+		extractSessionSignals(&s, fp.Session)
+	}
+	if fp.Rate.Available {
+		extractRateSignals(&s, fp)
+	}
+	s.HoneypotHit = fp.Offender.Trapped
+	s.RepeatOffender = fp.Offender.RepeatOffender
+
 	// Calculate scores with breakdown
-	s.BrowserScore, s.BotScore, s.ScoreBreakdown = calculateScores(s, fp)
+	s.BrowserScore, s.BotScore, s.ScoreBreakdown = calculateScores(s, fp, rules)
 
 	return s
 }
 
+// extractMethodSignals recognizes CORS preflights and HEAD requests.
+// A browser preflight always carries Origin and
+// Access-Control-Request-Method and, in browsers sending Sec-Fetch
+// headers, Sec-Fetch-Mode: cors. Preflights are small by design, so their
+// header shape must not be read as a low-header bot.
+func extractMethodSignals(s *Signals, h HTTPFingerprint) {
+	switch h.Method {
+	case http.MethodHead:
+		s.IsHeadRequest = true
+	case http.MethodOptions:
+		requestMethod := h.Headers["access-control-request-method"]
+		_, requestHeaders := h.Headers["access-control-request-headers"]
+		if requestMethod == "" && !requestHeaders {
+			return // Plain OPTIONS, not a preflight
+		}
+		origin := h.Headers["origin"]
+		if origin == "" || requestMethod == "" || (h.SecFetchMode != "" && h.SecFetchMode != "cors") {
+			s.MalformedPreflight = true
+			return
+		}
+		s.IsCORSPreflight = true
+	}
+}
+
+// extractKnownFingerprint looks the ClientHello up in the fingerprint
+// database. A browser's fingerprint only counts under a browser
+// User-Agent: curl-impersonate and uTLS clients naming themselves get no
+// credit for it.
+func extractKnownFingerprint(s *Signals, tls TLSFingerprint, rules Rules) {
+	db := rules.Fingerprints
+	if db == nil {
+		db = DefaultFingerprintDB()
+	}
+	k, ok := db.Lookup(tls)
+	if !ok {
+		return
+	}
+	switch {
+	case k.Label == "bot":
+		s.KnownBotFingerprint = true
+	case s.UserAgentIsBrowser:
+		s.KnownBrowserFingerprint = true
+	default:
+		return
+	}
+	s.KnownFingerprintClient = k.Client
+}
+
+// tlsIntercepted reports whether a browser request arrived through a
+// TLS-intercepting proxy (Zscaler, Netskope, antivirus HTTPS scanning):
+// the HTTP layer is the browser's, but the ClientHello is the proxy's.
+// Chromium and WebKit always send GREASE, so its absence under their
+// User-Agent points at a middlebox, as do DHE suites no browser offers.
+func tlsIntercepted(s Signals, tls TLSFingerprint, uaLower string, rules Rules) bool {
+	if !s.UserAgentIsBrowser || !s.HasSecFetchHeaders || (!s.HasSecClientHints && !s.HasAcceptLanguage) {
+		return false
+	}
+	known := slices.ContainsFunc(rules.InterceptorFingerprints, func(f string) bool {
+		return f != "" && (strings.EqualFold(f, tls.JA3Hash) || strings.EqualFold(f, tls.JA4Hash))
+	})
+	return known || tls.LegacyCiphers || (tls.NoGREASE && strings.Contains(uaLower, "applewebkit/"))
+}
+
 // extractJA4HSignals parses JA4H fingerprint and extracts signals
 // JA4H format: {method}{version}{cookie}{referer}{header_count}{language}_{hash_b}_{hash_c}_{hash_d}
 // Example: ge20cn14enus_7cf2b917f4b0_000000000000_000000000000
@@ -191,6 +358,24 @@ func extractJA4HSignals(s *Signals, ja4h string, fp Fingerprint) {
 	s.JA4HConsistentSignal = checkJA4HConsistency(s, fp)
 }
 
+// extractSessionSignals flags machine-like inter-request timing.
+// Requires a minimum number of intervals so a couple of quick reloads
+// by a human are not mistaken for automation.
+func extractSessionSignals(s *Signals, sess SessionFingerprint) {
+	if sess.IntervalCount < 4 {
+		return
+	}
+
+	// Low coefficient of variation - scripted loops fire at near-constant rate
+	s.RegularTiming = sess.IntervalJitter < 0.1
+
+	// Mean gap below human reaction time for page navigation
+	s.SubHumanInterval = sess.MeanIntervalMs < 250
+
+	// Pages only, in rapid sequence - browsers fetch assets with pages
+	s.ContentOnly = contentOnlyNavigation(sess)
+}
+
 // parseHeaderCount parses 2-digit header count string
 func parseHeaderCount(s string, result *int) (int, error) {
 	n := 0
@@ -208,8 +393,8 @@ func parseHeaderCount(s string, result *int) (int, error) {
 func checkJA4HConsistency(s *Signals, fp Fingerprint) bool {
 	consistent := true
 
-	// HTTP/2 consistency
-	if s.JA4HIsHTTP2 != s.IsHTTP2 {
+	// HTTP/2 consistency; JA4H counts HTTP/3 as well
+	if s.JA4HIsHTTP2 != (s.IsHTTP2 || s.IsHTTP3) {
 		consistent = false
 	}
 
@@ -233,8 +418,9 @@ func checkJA4HConsistency(s *Signals, fp Fingerprint) bool {
 }
 
 // calculateScores computes browser and bot scores based on signals
-func calculateScores(s Signals, fp Fingerprint) (browserScore, botScore int, breakdown string) {
-	var browserReasons, botReasons []string
+func calculateScores(s Signals, fp Fingerprint, rules Rules) (browserScore, botScore int, breakdown Breakdown) {
+	browser := &scoreSheet{rules: rules, direction: DirectionBrowser}
+	bot := &scoreSheet{rules: rules, direction: DirectionBot}
 
 	// ==========================================
 	// Browser-positive signals
@@ -242,82 +428,86 @@ func calculateScores(s Signals, fp Fingerprint) (browserScore, botScore int, bre
 
 	// HTTP/2 - browsers prefer HTTP/2
 	if s.IsHTTP2 {
-		browserScore += 2
-		browserReasons = append(browserReasons, "http2(+2)")
+		browser.add("http2")
+	}
+
+	// HTTP/3 - only browsers and a few QUIC-capable clients use it
+	if s.IsHTTP3 {
+		browser.add("http3")
 	}
 
 	// Sec-Fetch-* headers - strong browser indicator (cannot be spoofed via JS)
 	if s.HasSecFetchHeaders {
-		browserScore += 3
-		browserReasons = append(browserReasons, "sec-fetch(+3)")
+		browser.add("sec-fetch")
 	}
 
 	// Accept-Language - browsers always send this
 	if s.HasAcceptLanguage {
-		browserScore++
-		browserReasons = append(browserReasons, "accept-lang(+1)")
+		browser.add("accept-lang")
 	}
 
 	// Browser headers combination
 	if s.HasBrowserHeaders {
-		browserScore++
-		browserReasons = append(browserReasons, "browser-headers(+1)")
+		browser.add("browser-headers")
 	}
 
 	// User-Agent looks like browser (without bot patterns)
 	if s.UserAgentIsBrowser && !s.UserAgentIsBot {
-		browserScore += 2
-		browserReasons = append(browserReasons, "browser-ua(+2)")
+		browser.add("browser-ua")
 	}
 
 	// Sec-CH-UA client hints - browser-specific
 	if s.HasSecClientHints {
-		browserScore += 2
-		browserReasons = append(browserReasons, "sec-ch-ua(+2)")
+		browser.add("sec-ch-ua")
 	}
 
 	// Cookies present
 	if fp.HTTP.HasCookies {
-		browserScore++
-		browserReasons = append(browserReasons, "cookies(+1)")
+		browser.add("cookies")
 	}
 
 	// High header count - browsers send many headers
 	if fp.HTTP.HeaderCount >= 10 {
-		browserScore++
-		browserReasons = append(browserReasons, "headers>=10(+1)")
+		browser.add("headers>=10")
 	}
 
 	// Modern TLS
 	if s.HasModernTLS {
-		browserScore++
-		browserReasons = append(browserReasons, "modern-tls(+1)")
+		browser.add("modern-tls")
 	}
 
 	// TLS fingerprint signals (from ClientHello)
 	if s.HasTLSFingerprint {
 		// High cipher suite count - browsers offer 15-20 cipher suites
 		if s.HighCipherCount {
-			browserScore += 2
-			browserReasons = append(browserReasons, "high-ciphers(+2)")
+			browser.add("high-ciphers")
 		}
 
 		// Session ticket support - browsers support session resumption
 		if s.HasSessionSupport {
-			browserScore++
-			browserReasons = append(browserReasons, "session-ticket(+1)")
+			browser.add("session-ticket")
 		}
 
 		// Multiple elliptic curve groups - browsers support several
 		if s.HasMultipleGroups {
-			browserScore++
-			browserReasons = append(browserReasons, "multi-groups(+1)")
+			browser.add("multi-groups")
 		}
 
 		// Extensions count - browsers have many TLS extensions
 		if fp.TLS.ExtensionsCount >= 10 {
-			browserScore++
-			browserReasons = append(browserReasons, "tls-ext>=10(+1)")
+			browser.add("tls-ext>=10")
+		}
+
+		// GREASE everywhere - Chromium and WebKit always send it, Go,
+		// Python and OpenSSL defaults never do
+		if s.HasGREASECiphers && s.HasGREASEExtensions && s.HasGREASEGroups {
+			browser.add("grease")
+		}
+
+		// Encrypted Client Hello - Chrome and Firefox send it, real or
+		// GREASE, HTTP libraries do not
+		if s.HasECH {
+			browser.add("ech")
 		}
 	}
 
@@ -325,93 +515,183 @@ func calculateScores(s Signals, fp Fingerprint) (browserScore, botScore int, bre
 	if s.HasJA4HFingerprint {
 		// High header count from JA4H - browsers send many headers
 		if s.JA4HHighHeaderCount {
-			browserScore++
-			browserReasons = append(browserReasons, "ja4h-headers>=10(+1)")
+			browser.add("ja4h-headers>=10")
 		}
 
 		// Has referer - often present in browser navigation
 		if s.JA4HHasReferer {
-			browserScore++
-			browserReasons = append(browserReasons, "ja4h-referer(+1)")
+			browser.add("ja4h-referer")
 		}
 
 		// Consistent signals - no fingerprint manipulation detected
 		if s.JA4HConsistentSignal {
-			browserScore++
-			browserReasons = append(browserReasons, "ja4h-consistent(+1)")
+			browser.add("ja4h-consistent")
 		}
 	}
 
+	// Private Access Token - an issuer attested a real device or account
+	if s.HasValidPrivateToken {
+		browser.add("private-token")
+	}
+
+	// Challenge token - this client passed a challenge earlier
+	if s.ChallengeTokenPassed {
+		browser.add("challenge-pass")
+	}
+
+	// CAPTCHA - this session solved one
+	if s.CaptchaSolved {
+		browser.add("captcha-pass")
+	}
+
+	// CORS preflight shaped as browsers send it
+	if s.IsCORSPreflight {
+		browser.add("cors-preflight")
+	}
+
+	// JA3/JA4 of a known browser under its User-Agent
+	if s.KnownBrowserFingerprint {
+		browser.add("known-browser-fp")
+	}
+
 	// ==========================================
 	// Bot-positive signals
 	// ==========================================
 
 	// Known bot User-Agent pattern
 	if s.UserAgentIsBot {
-		botScore += 3
-		botReasons = append(botReasons, "bot-ua(+3)")
+		bot.add("bot-ua")
 	}
 
 	// AI/LLM crawler - extra penalty
 	if s.UserAgentIsAICrawler {
-		botScore += 2
-		botReasons = append(botReasons, "ai-crawler(+2)")
+		bot.add("ai-crawler")
+	}
+
+	// Crawler User-Agent from an address its operator does not use
+	if s.SpoofedCrawler {
+		bot.add("spoofed-crawler")
+	}
+
+	// Browser version pinned years ago, as scrapers' User-Agents often are
+	if s.OutdatedBrowser {
+		bot.add("outdated-browser")
 	}
 
-	// Low header count - bots send minimal headers
-	if s.LowHeaderCount {
-		botScore += 2
-		botReasons = append(botReasons, "low-headers(+2)")
+	// Low header count - bots send minimal headers (browser preflights
+	// are small too)
+	if s.LowHeaderCount && !s.IsCORSPreflight {
+		bot.add("low-headers")
 	}
 
-	// Missing typical headers (without Sec-Fetch)
-	if s.MissingTypicalHeader && !s.HasSecFetchHeaders {
-		botScore++
-		botReasons = append(botReasons, "missing-typical(+1)")
+	// Missing typical headers (without Sec-Fetch or in a preflight)
+	if s.MissingTypicalHeader && !s.HasSecFetchHeaders && !s.IsCORSPreflight {
+		bot.add("missing-typical")
 	}
 
 	// Missing User-Agent - very suspicious
 	if !s.HasUserAgent {
-		botScore += 2
-		botReasons = append(botReasons, "no-ua(+2)")
+		bot.add("no-ua")
 	}
 
 	// HTTP/1.1 without H2 - many bots don't support HTTP/2
 	if !s.IsHTTP2 && fp.HTTP.Version == "HTTP/1.1" {
-		botScore++
-		botReasons = append(botReasons, "http1.1(+1)")
+		bot.add("http1.1")
 	}
 
-	// Generic Accept header (*/*) - typical for HTTP libraries
-	if fp.HTTP.Accept == "*/*" {
-		botScore++
-		botReasons = append(botReasons, "accept-*/*-(+1)")
+	// Generic Accept header (*/*) - typical for HTTP libraries, but also
+	// what browsers send in preflights
+	if fp.HTTP.Accept == "*/*" && !s.IsCORSPreflight {
+		bot.add("accept-*/*-")
 	}
 
 	// Missing Accept-Language without Sec-Fetch
 	if !s.HasAcceptLanguage && !s.HasSecFetchHeaders {
-		botScore++
-		botReasons = append(botReasons, "no-accept-lang(+1)")
+		bot.add("no-accept-lang")
 	}
 
-	// TLS fingerprint signals indicating bot
-	if s.HasTLSFingerprint {
+	// Referer set by hand rather than by a browser
+	if s.SpoofedReferer {
+		bot.add("spoofed-referer")
+	}
+
+	// Sec-Fetch headers set by hand, contradicting each other or Accept
+	if s.FetchMetadataMismatch {
+		bot.add("fetch-metadata-mismatch")
+	}
+
+	// Header combinations no real client produces, however many
+	if s.HeaderSpoofing {
+		bot.add("spoofing")
+	}
+
+	// Browser User-Agent on a request whose header casing is a library's
+	if s.NonCanonicalHeaderCase {
+		bot.add("header-case")
+	}
+
+	// Client Hints a browser could not have sent with this User-Agent
+	if s.ClientHintsMismatch {
+		bot.add("client-hints-mismatch")
+	}
+
+	// Accept-Language foreign to the client's country
+	if s.GeoLanguageMismatch {
+		bot.add("geo-lang-mismatch")
+	}
+
+	// Address in a hosting provider's network rather than a residential
+	// or mobile one
+	if s.DatacenterASN {
+		bot.add("datacenter")
+	}
+
+	// Address of a VPN, proxy or Tor exit that scrapers rotate through
+	if s.AnonymizedNetwork {
+		bot.add("anonymized-network")
+	}
+
+	// Browser User-Agent over a server's TCP stack
+	if s.TCPOSMismatch {
+		bot.add("tcp-os-mismatch")
+	}
+
+	// Browser User-Agent answering from the server's datacenter
+	if s.ColocatedClient {
+		bot.add("colocated")
+	}
+
+	// Browser User-Agent over a TLS library's ClientHello
+	if s.LibraryExtensionOrder {
+		bot.add("library-ext-order")
+	}
+
+	// Browser User-Agent over a library's TLS fingerprint
+	if s.TLSImpersonation {
+		bot.add("tls-impersonation")
+	}
+
+	// JA3/JA4 of a known bot client
+	if s.KnownBotFingerprint {
+		bot.add("known-bot-fp")
+	}
+
+	// TLS fingerprint signals indicating bot, unless the ClientHello is a
+	// corporate proxy's rather than the client's
+	if s.HasTLSFingerprint && !s.TLSIntercepted {
 		// Low cipher suite count - simple HTTP clients
 		if fp.TLS.CipherSuitesCount > 0 && fp.TLS.CipherSuitesCount < 10 {
-			botScore++
-			botReasons = append(botReasons, "low-ciphers(+1)")
+			bot.add("low-ciphers")
 		}
 
 		// Few or no TLS extensions
 		if fp.TLS.ExtensionsCount > 0 && fp.TLS.ExtensionsCount < 8 {
-			botScore++
-			botReasons = append(botReasons, "few-tls-ext(+1)")
+			bot.add("few-tls-ext")
 		}
 
 		// No session ticket support
 		if !s.HasSessionSupport && fp.TLS.Available {
-			botScore++
-			botReasons = append(botReasons, "no-session(+1)")
+			bot.add("no-session")
 		}
 	}
 
@@ -419,36 +699,103 @@ func calculateScores(s Signals, fp Fingerprint) (browserScore, botScore int, bre
 	if s.HasJA4HFingerprint {
 		// Missing language in JA4H - bots often don't send Accept-Language
 		if s.JA4HMissingLanguage {
-			botScore++
-			botReasons = append(botReasons, "ja4h-no-lang(+1)")
+			bot.add("ja4h-no-lang")
 		}
 
 		// Low header count from JA4H
-		if s.JA4HLowHeaderCount {
-			botScore++
-			botReasons = append(botReasons, "ja4h-low-headers(+1)")
+		if s.JA4HLowHeaderCount && !s.IsCORSPreflight {
+			bot.add("ja4h-low-headers")
 		}
 
 		// Inconsistent signals - possible fingerprint manipulation/evasion
 		if !s.JA4HConsistentSignal {
-			botScore += 2
-			botReasons = append(botReasons, "ja4h-inconsistent(+2)")
+			bot.add("ja4h-inconsistent")
 		}
 	}
 
-	// Build breakdown string
-	breakdown = "BROWSER[" + strings.Join(browserReasons, " ") + "] "
-	breakdown += "BOT[" + strings.Join(botReasons, " ") + "]"
+	// Behavioral signals (bot-positive)
+	// Machine-regular request timing within a session
+	if s.RegularTiming {
+		bot.add("regular-timing")
+	}
+
+	// Requests arriving faster than a human could navigate
+	if s.SubHumanInterval {
+		bot.add("sub-human-gaps")
+	}
+
+	// More page requests per minute, or per second, than browsing produces
+	if s.HighRequestRate {
+		bot.add("high-rate")
+	}
+	if s.BurstPattern {
+		bot.add("burst")
+	}
+
+	// Pages fetched without the assets a browser loads with them
+	if s.ContentOnly {
+		bot.add("content-only")
+	}
+
+	// Preflight headers no browser sends that way
+	if s.MalformedPreflight {
+		bot.add("bad-preflight")
+	}
+
+	// HEAD requests - browsers do not issue them for pages
+	if s.IsHeadRequest {
+		bot.add("head")
+	}
+
+	// Challenge token that fails verification - forged, expired or
+	// copied from another client
+	if s.ChallengeTokenFailed {
+		bot.add("challenge-fail")
+	}
+
+	// A trap no person sees and robots.txt disallows - outweighs every
+	// browser rule
+	if s.HoneypotHit {
+		bot.add("honeypot")
+	}
+
+	// Recently classified bot, repeatedly, by address or fingerprint
+	if s.RepeatOffender {
+		bot.add("repeat-offender")
+	}
+
+	// Accept-Language foreign to the country weighs more once another rule
+	// worth 2 or more already points to a bot; weak hints such as http1.1
+	// do not corroborate it
+	if s.GeoLanguageMismatch && slices.ContainsFunc(bot.fired, func(c SignalContribution) bool {
+		return c.Name != "geo-lang-mismatch" && c.Weight >= 2
+	}) {
+		bot.add("geo-lang-corroborated")
+	}
+
+	// Custom rules from configuration
+	for _, r := range rules.Custom {
+		if !r.Matches(s, fp) {
+			continue
+		}
+		if r.Bot {
+			bot.add(r.Name)
+		} else {
+			browser.add(r.Name)
+		}
+	}
 
-	return browserScore, botScore, breakdown
+	breakdown = append(browser.fired, bot.fired...)
+	return browser.score, bot.score, breakdown
 }
 
-// containsAny checks if string contains any of the substrings
-func containsAny(s string, substrs []string) bool {
-	for _, substr := range substrs {
-		if strings.Contains(s, substr) {
-			return true
+// matchingPatterns returns the patterns s contains, in list order
+func matchingPatterns(s string, patterns []string) []string {
+	var matched []string
+	for _, p := range patterns {
+		if strings.Contains(s, p) {
+			matched = append(matched, p)
 		}
 	}
-	return false
+	return matched
 }
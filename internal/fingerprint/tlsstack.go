@@ -0,0 +1,99 @@
+package fingerprint
+
+import "strings"
+
+// TLS stacks, named after the library writing the ClientHello
+const (
+	tlsStackChromium = "chromium" // BoringSSL: Chrome, Edge, Opera, Samsung Internet
+	tlsStackFirefox  = "firefox"  // NSS
+	tlsStackSafari   = "safari"   // Apple's: Safari and every iOS browser
+	tlsStackGo       = "go"       // crypto/tls
+	tlsStackOpenSSL  = "openssl"  // curl, Python, Node.js, Ruby, PHP
+	tlsStackGnuTLS   = "gnutls"   // wget
+)
+
+// libraryTLSStacks are the stacks of HTTP libraries rather than browsers
+var libraryTLSStacks = map[string]bool{
+	tlsStackGo:      true,
+	tlsStackOpenSSL: true,
+	tlsStackGnuTLS:  true,
+}
+
+// tlsStackFingerprints maps JA4 cipher hashes (JA4_b), which stay put
+// across browser releases and extension shuffling, and the JA3 hashes of
+// library clients to the stack that sends them
+var tlsStackFingerprints = map[string]string{
+	"8daaf6152771": tlsStackChromium, // Chromium 85 and later
+	"5b57614c22b0": tlsStackFirefox,
+	"a09f3c656075": tlsStackSafari, // Safari 16
+	"2802a3db6c62": tlsStackSafari, // iOS 13 and 14
+
+	"f57a46bbacb6": tlsStackGo,      // crypto/tls defaults
+	"e8f1e7e78f70": tlsStackOpenSSL, // OpenSSL 3 default ciphers (curl)
+	"85036bcba153": tlsStackOpenSSL, // Python ssl
+	"a33745022dd6": tlsStackOpenSSL, // Node.js
+	"723694b0fccc": tlsStackGnuTLS,
+
+	"03117a8ed39ef02427ebbc39f121275c": tlsStackGo,      // Go-http-client
+	"0149f47eabf9a20d0893e2a44e5a6323": tlsStackOpenSSL, // curl
+	"93c7d42c0df602fb91589311534831f5": tlsStackOpenSSL, // Python urllib
+	"1a28e69016765d92e3b381168d68922c": tlsStackOpenSSL, // Node.js fetch
+	"bb4f9fef542ff6b4b29aa653bf0c1d31": tlsStackGnuTLS,  // wget
+}
+
+// browserTLSStacks are the stacks browser families have shipped since
+// their minimum major version. Older releases, and families missing here,
+// are not vouched for. Every browser on iOS runs on WebKit.
+var browserTLSStacks = []struct {
+	family   string
+	minMajor int
+	stack    string
+}{
+	{"Chrome", 80, tlsStackChromium},
+	{"Edge", 79, tlsStackChromium}, // EdgeHTML used Windows' Schannel
+	{"Opera", 67, tlsStackChromium},
+	{"Samsung Internet", 13, tlsStackChromium},
+	{"Yandex", 20, tlsStackChromium},
+	{"Vivaldi", 3, tlsStackChromium},
+	{"Chromium", 80, tlsStackChromium},
+	{"Firefox", 78, tlsStackFirefox},
+	{"Safari", 13, tlsStackSafari},
+}
+
+// tlsStack names the stack of a ClientHello by its JA4 cipher hash or JA3
+// hash, falling back to the extension order of a library
+func tlsStack(tls TLSFingerprint) string {
+	if parts := strings.Split(tls.JA4Hash, "_"); len(parts) == 3 {
+		if stack, ok := tlsStackFingerprints[parts[1]]; ok {
+			return stack
+		}
+	}
+	if stack, ok := tlsStackFingerprints[strings.ToLower(tls.JA3Hash)]; ok {
+		return stack
+	}
+	return extensionOrderStack(tls.Extensions)
+}
+
+// expectedTLSStack returns the stack the browser a User-Agent names ships
+// with, or "" when the database does not know it
+func expectedTLSStack(ua ParsedUserAgent) string {
+	if ua.OS == "iOS" && ua.Family != "" {
+		return tlsStackSafari
+	}
+	for _, b := range browserTLSStacks {
+		if b.family == ua.Family {
+			if ua.Major < b.minMajor {
+				return ""
+			}
+			return b.stack
+		}
+	}
+	return ""
+}
+
+// tlsImpersonation reports whether a library's ClientHello arrived under
+// the User-Agent of a browser known to ship another stack
+func tlsImpersonation(stack string, ua ParsedUserAgent) bool {
+	expected := expectedTLSStack(ua)
+	return libraryTLSStacks[stack] && expected != "" && expected != stack
+}
@@ -4,8 +4,14 @@ import "time"
 
 // Fingerprint contains all collected signals from a request
 type Fingerprint struct {
-	TLS  TLSFingerprint  `json:"tls"`
-	HTTP HTTPFingerprint `json:"http"`
+	TLS      TLSFingerprint      `json:"tls"`
+	HTTP     HTTPFingerprint     `json:"http"`
+	Session  SessionFingerprint  `json:"session"`
+	Rate     RateFingerprint     `json:"rate"`
+	Offender OffenderFingerprint `json:"offender"`
+	Network  NetworkFingerprint  `json:"network"`
+	TCP      TCPFingerprint      `json:"tcp"`
+	QUIC     QUICFingerprint     `json:"quic"`
 }
 
 // TLSFingerprint contains TLS-level signals
@@ -23,39 +29,213 @@ type TLSFingerprint struct {
 	HasEarlyData       bool     `json:"has_early_data"`      // 0-RTT support
 	JA3Hash            string   `json:"ja3_hash,omitempty"`  // JA3 fingerprint hash
 	JA4Hash            string   `json:"ja4_hash,omitempty"`  // JA4 fingerprint hash
+	JA4Raw             string   `json:"ja4_r,omitempty"`     // JA4 with the sorted lists unhashed
+	JA3SHash           string   `json:"ja3s_hash,omitempty"` // JA3S fingerprint of our ServerHello
+	JA4SHash           string   `json:"ja4s_hash,omitempty"` // JA4S fingerprint of our ServerHello
 	CertificateRequest bool     `json:"certificate_request"` // Client cert requested
 	Available          bool     `json:"available"`           // TLS info was available
+
+	// ClientHello anomalies typical of TLS-intercepting proxies (false when
+	// the ClientHello was not captured)
+	NoGREASE      bool `json:"no_grease,omitempty"`      // No GREASE values in cipher suites or extensions
+	LegacyCiphers bool `json:"legacy_ciphers,omitempty"` // Offers DHE suites or the renegotiation SCSV
+
+	// GREASE values (RFC 8701) offered, as Chromium and WebKit always do.
+	// GREASE in supported groups shows as "GREASE" in SupportedGroups.
+	GREASECiphers    bool `json:"grease_ciphers,omitempty"`    // In cipher suites
+	GREASEExtensions bool `json:"grease_extensions,omitempty"` // In extensions
+
+	// Extension IDs in the order sent, GREASE values included (nil when
+	// the ClientHello was not captured)
+	Extensions []int `json:"extensions,omitempty"`
+
+	// Encrypted Client Hello offered, either to encrypt the real
+	// ClientHello or as GREASE when the server published no ECH config
+	ECH bool `json:"ech,omitempty"`
+
+	// Handshake timing (0 and empty when not measured)
+	LatencyMicros int    `json:"latency_us,omitempty"` // Client's one-way latency in microseconds
+	JA4L          string `json:"ja4l,omitempty"`       // JA4L-C latency fingerprint
+}
+
+// TCPFingerprint contains the client's TCP SYN, which shows the operating
+// system's network stack whatever the TLS and HTTP layers imitate
+type TCPFingerprint struct {
+	Available   bool   `json:"available"`              // SYN was captured
+	WindowSize  int    `json:"window_size,omitempty"`  // Initial receive window
+	Options     []int  `json:"options,omitempty"`      // Option kinds in the order sent
+	MSS         int    `json:"mss,omitempty"`          // Maximum segment size
+	WindowScale int    `json:"window_scale,omitempty"` // Window scale shift
+	TTL         int    `json:"ttl,omitempty"`          // IP TTL or hop limit as received
+	JA4T        string `json:"ja4t,omitempty"`         // JA4T fingerprint
+}
+
+// QUICFingerprint contains the QUIC transport parameters of an HTTP/3
+// client's ClientHello, which each QUIC library sets differently
+type QUICFingerprint struct {
+	Available             bool   `json:"available"`                          // Request came over QUIC
+	Version               string `json:"version,omitempty"`                  // QUIC version (e.g., "v1")
+	TransportParameters   []int  `json:"transport_parameters,omitempty"`     // Parameter IDs in the order sent
+	MaxIdleTimeout        int    `json:"max_idle_timeout,omitempty"`         // Milliseconds
+	MaxUDPPayloadSize     int    `json:"max_udp_payload_size,omitempty"`     // Largest datagram accepted
+	InitialMaxData        int    `json:"initial_max_data,omitempty"`         // Connection flow control window
+	InitialMaxStreamsBidi int    `json:"initial_max_streams_bidi,omitempty"` // Bidirectional streams allowed
+	InitialMaxStreamsUni  int    `json:"initial_max_streams_uni,omitempty"`  // Unidirectional streams allowed
 }
 
 // HTTPFingerprint contains HTTP-level signals
 type HTTPFingerprint struct {
-	Version       string            `json:"version"`             // HTTP version (HTTP/1.1, HTTP/2)
-	Method        string            `json:"method"`              // Request method
-	Path          string            `json:"path"`                // Request path
-	Headers       map[string]string `json:"headers"`             // All headers (lowercased keys)
-	HeaderOrder   []string          `json:"header_order"`        // Order of headers as received
-	HeaderCount   int               `json:"header_count"`        // Total header count
-	UserAgent     string            `json:"user_agent"`          // User-Agent header
-	Accept        string            `json:"accept"`              // Accept header
-	AcceptLang    string            `json:"accept_lang"`         // Accept-Language header
-	AcceptEnc     string            `json:"accept_enc"`          // Accept-Encoding header
-	Connection    string            `json:"connection"`          // Connection header
-	SecFetchSite  string            `json:"sec_fetch_site"`      // Sec-Fetch-Site header
-	SecFetchMode  string            `json:"sec_fetch_mode"`      // Sec-Fetch-Mode header
-	SecFetchDest  string            `json:"sec_fetch_dest"`      // Sec-Fetch-Dest header
-	SecFetchUser  string            `json:"sec_fetch_user"`      // Sec-Fetch-User header
-	SecChUA       string            `json:"sec_ch_ua"`           // Sec-CH-UA header
-	HasCookies    bool              `json:"has_cookies"`         // Has Cookie header
-	HasReferer    bool              `json:"has_referer"`         // Has Referer header
-	ContentType   string            `json:"content_type"`        // Content-Type header
-	ContentLength int64             `json:"content_length"`      // Content-Length value
-	JA4HHash      string            `json:"ja4h_hash,omitempty"` // JA4H HTTP fingerprint hash
+	Version       string            `json:"version"`                 // HTTP version (HTTP/1.1, HTTP/2)
+	Method        string            `json:"method"`                  // Request method
+	Path          string            `json:"path"`                    // Request path
+	Headers       map[string]string `json:"headers"`                 // All headers (lowercased keys)
+	HeaderOrder   []string          `json:"header_order"`            // Lowercased names, in wire order when captured, else sorted
+	HeaderCount   int               `json:"header_count"`            // Total header count
+	UserAgent     string            `json:"user_agent"`              // User-Agent header
+	Accept        string            `json:"accept"`                  // Accept header
+	AcceptLang    string            `json:"accept_lang"`             // Accept-Language header
+	AcceptEnc     string            `json:"accept_enc"`              // Accept-Encoding header
+	Connection    string            `json:"connection"`              // Connection header
+	SecFetchSite  string            `json:"sec_fetch_site"`          // Sec-Fetch-Site header
+	SecFetchMode  string            `json:"sec_fetch_mode"`          // Sec-Fetch-Mode header
+	SecFetchDest  string            `json:"sec_fetch_dest"`          // Sec-Fetch-Dest header
+	SecFetchUser  string            `json:"sec_fetch_user"`          // Sec-Fetch-User header
+	SecChUA       string            `json:"sec_ch_ua"`               // Sec-CH-UA header
+	HasCookies    bool              `json:"has_cookies"`             // Has Cookie header
+	HasReferer    bool              `json:"has_referer"`             // Has Referer header
+	ContentType   string            `json:"content_type"`            // Content-Type header
+	ContentLength int64             `json:"content_length"`          // Content-Length value
+	JA4HHash      string            `json:"ja4h_hash,omitempty"`     // JA4H HTTP fingerprint hash
+	PrivateToken  string            `json:"private_token,omitempty"` // Private Access Token outcome: "valid" or "invalid"
+
+	// Challenge token outcome: "pass" or "fail" (empty = no token presented)
+	ChallengeToken string `json:"challenge_token,omitempty"`
+
+	// Host header, for checking the Referer origin against the request
+	Host string `json:"host,omitempty"`
+
+	// Header names in wire order and case, when captured from an HTTP/1.x
+	// connection
+	RawHeaderNames []string `json:"raw_header_names,omitempty"`
+
+	// User-Agent Client Hints (Sec-CH-UA-*), parsed
+	ClientHints ClientHints `json:"client_hints,omitzero"`
+
+	// User-Agent parsed into browser family, version, OS and device
+	UserAgentParsed ParsedUserAgent `json:"user_agent_parsed,omitzero"`
+}
+
+// ParsedUserAgent is the client a User-Agent names
+type ParsedUserAgent struct {
+	Family    string `json:"family,omitempty"`     // Browser or client, e.g. "Chrome", "Safari", "curl", "GPTBot"
+	Major     int    `json:"major,omitempty"`      // Major version of the family
+	OS        string `json:"os,omitempty"`         // "Windows", "macOS", "iOS", "Android", "Chrome OS" or "Linux"
+	OSVersion string `json:"os_version,omitempty"` // e.g. "10", "14", "17.5"; frozen in reduced Chrome User-Agents
+	Device    string `json:"device,omitempty"`     // "desktop", "mobile", "tablet" or "bot"
+}
+
+// ClientHints contains the User-Agent Client Hints of a request. Chromium
+// browsers send Sec-CH-UA, -Mobile and -Platform on every request and the
+// others once a site asks for them with Accept-CH.
+type ClientHints struct {
+	Brands          []BrandVersion `json:"brands,omitempty"`            // Sec-CH-UA, without GREASE brands
+	FullVersionList []BrandVersion `json:"full_version_list,omitempty"` // Sec-CH-UA-Full-Version-List
+	Platform        string         `json:"platform,omitempty"`          // Sec-CH-UA-Platform, e.g. "Windows"
+	PlatformVersion string         `json:"platform_version,omitempty"`  // Sec-CH-UA-Platform-Version
+	Mobile          bool           `json:"mobile,omitempty"`            // Sec-CH-UA-Mobile is ?1
+	Model           string         `json:"model,omitempty"`             // Sec-CH-UA-Model (Android devices)
+	Arch            string         `json:"arch,omitempty"`              // Sec-CH-UA-Arch, e.g. "x86" or "arm"
+}
+
+// Private Access Token outcomes recorded in HTTPFingerprint.PrivateToken
+const (
+	PrivateTokenValid   = "valid"
+	PrivateTokenInvalid = "invalid"
+)
+
+// Challenge token outcomes recorded in HTTPFingerprint.ChallengeToken
+const (
+	ChallengeTokenPass = "pass"
+	ChallengeTokenFail = "fail"
+)
+
+// Crawler verification outcomes recorded in NetworkFingerprint.CrawlerVerification
+const (
+	CrawlerVerified = "verified"
+	CrawlerSpoofed  = "spoofed"
+)
+
+// SessionFingerprint contains behavioral timing signals across requests
+// from the same client session
+type SessionFingerprint struct {
+	RequestCount     int     `json:"request_count"`      // Requests observed in this session
+	IntervalCount    int     `json:"interval_count"`     // Inter-request intervals in the window
+	MeanIntervalMs   float64 `json:"mean_interval_ms"`   // Mean gap between requests
+	MinIntervalMs    float64 `json:"min_interval_ms"`    // Shortest gap between requests
+	IntervalStdDevMs float64 `json:"interval_stddev_ms"` // Standard deviation of gaps
+	IntervalJitter   float64 `json:"interval_jitter"`    // Coefficient of variation (stddev / mean)
+	PageRequests     int     `json:"page_requests"`      // Page requests observed in this session
+	AssetRequests    int     `json:"asset_requests"`     // Subresource requests (stylesheets, scripts, images, favicon)
+	HumanVerified    bool    `json:"human_verified"`     // The session solved a CAPTCHA
+	Available        bool    `json:"available"`          // Session tracking was available
+}
+
+// RateFingerprint contains the request rates of the client's address and
+// JA4 fingerprint over a sliding one-minute window
+type RateFingerprint struct {
+	AddrRequests int  `json:"addr_requests"` // Requests from the client address in the last minute
+	AddrBurst    int  `json:"addr_burst"`    // Requests from the client address in the current second
+	JA4Requests  int  `json:"ja4_requests"`  // Requests with the JA4 fingerprint in the last minute
+	JA4Burst     int  `json:"ja4_burst"`     // Requests with the JA4 fingerprint in the current second
+	Available    bool `json:"available"`     // Rate tracking was available
+}
+
+// OffenderFingerprint contains the offenses on record for the client's
+// address or JA4 fingerprint
+type OffenderFingerprint struct {
+	Trapped        bool    `json:"trapped"`              // Requested a honeypot trap
+	TrapPath       string  `json:"trap_path,omitempty"`  // The trap requested
+	Reputation     float64 `json:"reputation,omitempty"` // Past bot verdicts, decayed exponentially with their age
+	RepeatOffender bool    `json:"repeat_offender"`      // Reputation at or above the store's threshold
+}
+
+// NetworkFingerprint contains signals about the client's network origin
+type NetworkFingerprint struct {
+	PrivateRelay bool   `json:"private_relay"`           // Remote address is an iCloud Private Relay egress
+	RelayCountry string `json:"relay_country,omitempty"` // Country the relay egress serves
+
+	// Country is the client's ISO 3166-1 alpha-2 country, set by a GeoIP
+	// enricher (empty = not looked up)
+	Country string `json:"country,omitempty"`
+
+	// Local is set for loopback, private and link-local remote addresses,
+	// such as a load balancer's in front of the server
+	Local bool `json:"local,omitempty"`
+
+	// ClaimedCrawler is the documented crawler the User-Agent names and
+	// CrawlerVerification the outcome of checking the address against it
+	// (both empty = not checked)
+	ClaimedCrawler      string `json:"claimed_crawler,omitempty"`
+	CrawlerVerification string `json:"crawler_verification,omitempty"`
+
+	// ASN and ASOrg are the autonomous system announcing the address, set
+	// by an ASN enricher (0 = not looked up), and Hosting the hosting or
+	// datacenter provider operating it (empty = not a listed provider)
+	ASN     int    `json:"asn,omitempty"`
+	ASOrg   string `json:"as_org,omitempty"`
+	Hosting string `json:"hosting,omitempty"`
+
+	// Anonymizer is the category of the VPN, proxy or Tor list an IP
+	// reputation provider found the address in, e.g. "vpn" (empty = not
+	// listed or not looked up)
+	Anonymizer string `json:"anonymizer,omitempty"`
 }
 
 // Signals contains extracted classification signals
 type Signals struct {
 	// TLS signals (from ClientHello)
 	IsHTTP2           bool `json:"is_http2"`
+	IsHTTP3           bool `json:"is_http3"`            // Request came over HTTP/3 (QUIC)
 	HasModernTLS      bool `json:"has_modern_tls"`      // TLS 1.2+
 	HasALPN           bool `json:"has_alpn"`            // ALPN negotiated
 	HighCipherCount   bool `json:"high_cipher_count"`   // > 10 cipher suites (browsers typically have 15-20)
@@ -64,6 +244,11 @@ type Signals struct {
 	HasMultipleGroups bool `json:"has_multiple_groups"` // Multiple elliptic curve groups (browsers)
 	HasModernCiphers  bool `json:"has_modern_ciphers"`  // Has TLS 1.3 cipher suites
 
+	HasGREASECiphers    bool `json:"has_grease_ciphers"`    // GREASE cipher suite offered
+	HasGREASEExtensions bool `json:"has_grease_extensions"` // GREASE extension sent
+	HasGREASEGroups     bool `json:"has_grease_groups"`     // GREASE supported group offered
+	HasECH              bool `json:"has_ech"`               // Encrypted Client Hello extension sent
+
 	// HTTP signals
 	HasSecFetchHeaders bool `json:"has_sec_fetch_headers"` // Has Sec-Fetch-* headers
 	HasAcceptLanguage  bool `json:"has_accept_language"`   // Has Accept-Language
@@ -71,6 +256,18 @@ type Signals struct {
 	HasAccept          bool `json:"has_accept"`            // Has Accept header
 	HasAcceptEncoding  bool `json:"has_accept_encoding"`   // Has Accept-Encoding
 	HasSecClientHints  bool `json:"has_sec_ch_ua"`         // Has Sec-CH-UA headers
+	SpoofedReferer     bool `json:"spoofed_referer"`       // Referer no browser would send (malformed, templated or contradicting Sec-Fetch-Site)
+
+	FetchMetadataMismatch bool   `json:"fetch_metadata_mismatch"`           // Sec-Fetch-Mode, Sec-Fetch-Dest and Accept disagree
+	FetchMetadataConflict string `json:"fetch_metadata_conflict,omitempty"` // The contradicting header, e.g. "accept"
+
+	HeaderSpoofing bool     `json:"header_spoofing"`           // Matches an impossible header combination
+	SpoofingCombos []string `json:"spoofing_combos,omitempty"` // Names of the combinations, e.g. "sec-fetch-http1.0"
+
+	NonCanonicalHeaderCase bool `json:"non_canonical_header_case"` // Browser UA, but HTTP/1.x header names no browser would case that way
+
+	ClientHintsMismatch bool   `json:"client_hints_mismatch"`           // Client Hints contradict the User-Agent
+	ClientHintsConflict string `json:"client_hints_conflict,omitempty"` // The contradicting hint, e.g. "sec-ch-ua-platform"
 
 	// JA4H signals (HTTP fingerprint)
 	HasJA4HFingerprint   bool   `json:"has_ja4h_fingerprint"`   // JA4H fingerprint available
@@ -83,6 +280,44 @@ type Signals struct {
 	JA4HIsHTTP2          bool   `json:"ja4h_is_http2"`          // JA4H indicates HTTP/2
 	JA4HConsistentSignal bool   `json:"ja4h_consistent_signal"` // JA4H signals match HTTP signals
 
+	// Network signals
+	FromPrivateRelay bool `json:"from_private_relay"` // iCloud Private Relay egress (datacenter IP, real Safari user)
+	TLSIntercepted   bool `json:"tls_intercepted"`    // Browser HTTP layer behind a TLS-intercepting proxy (corporate MITM)
+
+	SpoofedCrawler bool   `json:"spoofed_crawler"`           // UA names a crawler whose reverse DNS or IP ranges the address fails
+	ClaimedCrawler string `json:"claimed_crawler,omitempty"` // The crawler the UA names, when its address was checked
+
+	DatacenterASN   bool   `json:"datacenter_asn"`             // Address in a hosting provider's AS (not Private Relay or a verified crawler)
+	HostingProvider string `json:"hosting_provider,omitempty"` // The provider, when DatacenterASN
+
+	AnonymizedNetwork bool   `json:"anonymized_network"`   // Address on a VPN, proxy or Tor list (not Private Relay)
+	Anonymizer        string `json:"anonymizer,omitempty"` // The list's category, when AnonymizedNetwork
+
+	GeoLanguageMismatch bool `json:"geo_language_mismatch"` // No Accept-Language fits the GeoIP country
+	TCPOSMismatch       bool `json:"tcp_os_mismatch"`       // TCP SYN from another OS than the User-Agent claims
+	ColocatedClient     bool `json:"colocated_client"`      // Browser UA answering the handshake from the server's datacenter
+
+	LibraryExtensionOrder bool   `json:"library_extension_order"`         // Browser UA, but a TLS library's ClientHello extension order
+	ExtensionOrderStack   string `json:"extension_order_stack,omitempty"` // The library, "go" or "openssl"
+
+	TLSStack         string `json:"tls_stack,omitempty"` // Stack of the ClientHello, e.g. "chromium", "go", "openssl"
+	TLSImpersonation bool   `json:"tls_impersonation"`   // Browser UA over a library's TLS stack
+
+	KnownBotFingerprint     bool   `json:"known_bot_fingerprint"`              // JA3/JA4 listed as a bot's
+	KnownBrowserFingerprint bool   `json:"known_browser_fingerprint"`          // JA3/JA4 listed as a browser's, under a browser UA
+	KnownFingerprintClient  string `json:"known_fingerprint_client,omitempty"` // The listed client, e.g. "curl 7.88"
+
+	// Attestation signals
+	HasValidPrivateToken bool `json:"has_valid_private_token"` // Redeemed a verified Private Access Token
+	ChallengeTokenPassed bool `json:"challenge_token_passed"`  // Presented a valid challenge token bound to this client
+	ChallengeTokenFailed bool `json:"challenge_token_failed"`  // Presented a forged, expired or transplanted challenge token
+	CaptchaSolved        bool `json:"captcha_solved"`          // The session solved a CAPTCHA
+
+	// Request method signals
+	IsCORSPreflight    bool `json:"is_cors_preflight"`   // OPTIONS with Origin and Access-Control-Request-Method, shaped as browsers send it
+	MalformedPreflight bool `json:"malformed_preflight"` // OPTIONS with Access-Control-Request-* headers browsers would not send that way
+	IsHeadRequest      bool `json:"is_head_request"`     // HEAD request (link checkers, uptime monitors, curl -I)
+
 	// Heuristic signals
 	UserAgentIsBot       bool `json:"ua_is_bot"`        // UA contains bot indicators
 	UserAgentIsAICrawler bool `json:"ua_is_ai_crawler"` // UA contains AI/LLM crawler indicators
@@ -91,10 +326,27 @@ type Signals struct {
 	HasBrowserHeaders    bool `json:"has_browser_headers"`
 	MissingTypicalHeader bool `json:"missing_typical_header"` // Missing expected headers
 
+	OutdatedBrowser   bool `json:"outdated_browser"`              // Browser UA more major releases behind the newest than Rules.MaxBrowserLag
+	BrowserVersionLag int  `json:"browser_version_lag,omitempty"` // Major releases the browser UA trails the newest of its family
+
+	// User-Agent patterns that matched, for auditing over-broad substrings
+	MatchedBotPatterns       []string `json:"matched_bot_patterns,omitempty"`
+	MatchedAICrawlerPatterns []string `json:"matched_ai_crawler_patterns,omitempty"`
+	MatchedBrowserPatterns   []string `json:"matched_browser_patterns,omitempty"`
+
+	// Behavioral signals (from session timing, request rates and past offenses)
+	RegularTiming    bool `json:"regular_timing"`     // Machine-regular inter-request intervals (low jitter)
+	SubHumanInterval bool `json:"sub_human_interval"` // Inter-request gaps faster than human interaction
+	HighRequestRate  bool `json:"high_request_rate"`  // Address, or a fingerprint no browser has, over the per-minute limit
+	BurstPattern     bool `json:"burst_pattern"`      // Burst of requests within one second from one address or fingerprint
+	ContentOnly      bool `json:"content_only"`       // Pages requested in rapid sequence without any of their assets
+	HoneypotHit      bool `json:"honeypot_hit"`       // Address or fingerprint requested a honeypot trap
+	RepeatOffender   bool `json:"repeat_offender"`    // Address or fingerprint with a history of bot verdicts
+
 	// Computed
-	BrowserScore   int    `json:"browser_score"`   // Score towards browser classification
-	BotScore       int    `json:"bot_score"`       // Score towards bot classification
-	ScoreBreakdown string `json:"score_breakdown"` // Detailed scoring explanation
+	BrowserScore   int       `json:"browser_score"`   // Score towards browser classification
+	BotScore       int       `json:"bot_score"`       // Score towards bot classification
+	ScoreBreakdown Breakdown `json:"score_breakdown"` // Rules that fired, with their points
 }
 
 // ClassificationResult contains the final classification
@@ -107,4 +359,20 @@ type ClassificationResult struct {
 	Signals        Signals     `json:"signals"`
 	Score          int         `json:"score"` // Net score (positive = browser, negative = bot)
 	Reason         string      `json:"reason"`
+	Partial        bool        `json:"partial,omitempty"`    // Some enrichment lookups did not complete
+	Incomplete     []string    `json:"incomplete,omitempty"` // Enrichers that failed or timed out
+
+	// Margin is the net score minus the classifier threshold: >= 0 for
+	// browser, < 0 for bot. FlipSet lists the fewest fired rules whose
+	// removal would flip the decision (empty when none can). With the ml
+	// backend, Margin is P(browser) minus the model threshold in
+	// percentage points and FlipSet names signals rather than rules.
+	Margin  int      `json:"margin"`
+	FlipSet []string `json:"flip_set,omitempty"`
+
+	// Class refines Classification into browser, ai_crawler, search_bot,
+	// headless_browser, http_library or unknown; ClassConfidence holds the
+	// confidence of every class, summing to 1
+	Class           string             `json:"class,omitempty"`
+	ClassConfidence map[string]float64 `json:"class_confidence,omitempty"`
 }
@@ -0,0 +1,163 @@
+package fingerprint
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Device types of a ParsedUserAgent
+const (
+	DeviceDesktop = "desktop"
+	DeviceMobile  = "mobile"
+	DeviceTablet  = "tablet"
+	DeviceBot     = "bot"
+)
+
+// uaBrowsers are the product tokens naming a browser family, most
+// specific first: Chromium-based browsers also send "Chrome/", and most
+// browsers "Safari/"
+var uaBrowsers = []struct{ token, family string }{
+	{"Edg/", "Edge"},
+	{"EdgA/", "Edge"},
+	{"EdgiOS/", "Edge"},
+	{"OPR/", "Opera"},
+	{"SamsungBrowser/", "Samsung Internet"},
+	{"YaBrowser/", "Yandex"},
+	{"Vivaldi/", "Vivaldi"},
+	{"HeadlessChrome/", "HeadlessChrome"},
+	{"CriOS/", "Chrome"},
+	{"FxiOS/", "Firefox"},
+	{"Firefox/", "Firefox"},
+	{"Chromium/", "Chromium"},
+	{"Chrome/", "Chrome"},
+}
+
+// windowsVersions maps Windows NT versions to release names. Windows 11
+// still reports NT 10.0.
+var windowsVersions = map[string]string{
+	"10.0": "10",
+	"6.3":  "8.1",
+	"6.2":  "8",
+	"6.1":  "7",
+	"6.0":  "Vista",
+	"5.1":  "XP",
+}
+
+// ParseUserAgent parses a User-Agent into the browser or client it names,
+// its major version, operating system and device type. Unrecognized parts
+// are left empty; clients other than browsers are named after their first
+// product token, or the one following "compatible;" for crawlers.
+func ParseUserAgent(ua string) ParsedUserAgent {
+	var p ParsedUserAgent
+	if ua == "" {
+		return p
+	}
+	p.OS, p.OSVersion = uaOS(ua)
+	p.Family, p.Major = uaFamily(ua)
+
+	lower := strings.ToLower(ua)
+	switch {
+	case strings.Contains(lower, "bot") || strings.Contains(lower, "crawl") || strings.Contains(lower, "spider"):
+		p.Device = DeviceBot
+	case strings.Contains(ua, "iPad") || strings.Contains(ua, "Tablet") ||
+		p.OS == "Android" && !strings.Contains(ua, "Mobile"):
+		p.Device = DeviceTablet
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "Mobile"):
+		p.Device = DeviceMobile
+	case p.OS == "Windows" || p.OS == "macOS" || p.OS == "Linux" || p.OS == "Chrome OS":
+		p.Device = DeviceDesktop
+	}
+	return p
+}
+
+// uaFamily returns the client family and major version of a User-Agent
+func uaFamily(ua string) (string, int) {
+	if v, ok := tokenVersion(ua, "MSIE "); ok {
+		return "Internet Explorer", v
+	}
+	if strings.Contains(ua, "Trident/") {
+		v, _ := tokenVersion(ua, "rv:")
+		return "Internet Explorer", v
+	}
+	// Crawlers name themselves in a comment, often after a browser's tokens
+	if _, rest, ok := strings.Cut(ua, "compatible; "); ok {
+		if name, version, ok := strings.Cut(productToken(rest), "/"); ok && name != "" {
+			return name, majorVersion(version)
+		}
+	}
+	for _, b := range uaBrowsers {
+		if v, ok := tokenVersion(ua, b.token); ok {
+			return b.family, v
+		}
+	}
+	if strings.Contains(ua, "Safari/") {
+		v, _ := tokenVersion(ua, "Version/")
+		return "Safari", v
+	}
+	if name, version, ok := strings.Cut(productToken(ua), "/"); ok && name != "Mozilla" {
+		return name, majorVersion(version)
+	}
+	return "", 0
+}
+
+// uaOS returns the operating system and its version named by a User-Agent
+func uaOS(ua string) (string, string) {
+	switch {
+	case strings.Contains(ua, "Windows NT "):
+		v, _, _ := strings.Cut(afterToken(ua, "Windows NT "), ";")
+		v, _, _ = strings.Cut(v, ")")
+		return "Windows", windowsVersions[v]
+	case strings.Contains(ua, "iPhone") || strings.Contains(ua, "iPad"):
+		v, _, _ := strings.Cut(afterToken(ua, "OS "), " ")
+		return "iOS", strings.ReplaceAll(v, "_", ".")
+	case strings.Contains(ua, "Macintosh"):
+		v, _, _ := strings.Cut(afterToken(ua, "Mac OS X "), ")")
+		v, _, _ = strings.Cut(v, ";")
+		return "macOS", strings.ReplaceAll(v, "_", ".")
+	case strings.Contains(ua, "Android"):
+		v, _, _ := strings.Cut(afterToken(ua, "Android "), ";")
+		v, _, _ = strings.Cut(v, ")")
+		return "Android", v
+	case strings.Contains(ua, "CrOS"):
+		return "Chrome OS", ""
+	case strings.Contains(ua, "Linux"):
+		return "Linux", ""
+	}
+	return "", ""
+}
+
+// tokenVersion returns the major version following token in ua
+func tokenVersion(ua, token string) (int, bool) {
+	i := strings.Index(ua, token)
+	if i < 0 {
+		return 0, false
+	}
+	return majorVersion(ua[i+len(token):]), true
+}
+
+// majorVersion parses the leading number of a version such as "124.0.1"
+func majorVersion(v string) int {
+	end := 0
+	for end < len(v) && v[end] >= '0' && v[end] <= '9' {
+		end++
+	}
+	n, _ := strconv.Atoi(v[:end])
+	return n
+}
+
+// afterToken returns what follows token in ua, or ""
+func afterToken(ua, token string) string {
+	if i := strings.Index(ua, token); i >= 0 {
+		return ua[i+len(token):]
+	}
+	return ""
+}
+
+// productToken returns the first "name/version" product of s
+func productToken(s string) string {
+	s = strings.TrimSpace(s)
+	if end := strings.IndexAny(s, " ;)("); end >= 0 {
+		s = s[:end]
+	}
+	return s
+}
@@ -0,0 +1,68 @@
+package fingerprint
+
+import (
+	"crypto/sha256"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// VerdictKey identifies the clients a classification can be reused for:
+// the JA3, JA4 and JA4H hashes and the User-Agent, plus every signal input
+// of a single request those do not capture (see the keyed parts below).
+// ok is false when the fingerprint has no hash to tell clients apart by.
+func VerdictKey(fp Fingerprint) (key [sha256.Size]byte, ok bool) {
+	if fp.TLS.JA3Hash == "" && fp.TLS.JA4Hash == "" && fp.HTTP.JA4HHash == "" {
+		return key, false
+	}
+
+	// Signals derived from values that vary between requests of a client
+	var s Signals
+	extractRefererSignals(&s, fp.HTTP)
+	if fp.Session.Available {
+		extractSessionSignals(&s, fp.Session)
+	}
+
+	h := sha256.New()
+	for _, part := range []string{
+		fp.TLS.JA3Hash,
+		fp.TLS.JA4Hash,
+		greaseKey(fp.TLS),
+		fp.HTTP.JA4HHash,
+		fp.HTTP.UserAgent,
+		fp.HTTP.Method,
+		fp.HTTP.AcceptLang,
+		fp.HTTP.PrivateToken,
+		fp.HTTP.ChallengeToken,
+		strconv.FormatBool(fp.Network.PrivateRelay),
+		fp.Network.RelayCountry,
+		fp.Network.Country,
+		fp.Network.CrawlerVerification,
+		fp.Network.Hosting,
+		fp.Network.Anonymizer,
+		tcpOS(fp.TCP),
+		strconv.FormatBool(colocatedClient(fp)),
+		clientHintsMismatch(fp.HTTP),
+		fetchMetadataMismatch(fp.HTTP),
+		strings.Join(impossibleCombos(fp), ","),
+		strconv.FormatBool(s.SpoofedReferer),
+		strconv.FormatBool(s.RegularTiming),
+		strconv.FormatBool(s.SubHumanInterval),
+		strconv.FormatBool(s.ContentOnly),
+		rateKey(fp.Rate),
+		strconv.FormatBool(fp.Offender.Trapped),
+		strconv.FormatBool(fp.Offender.RepeatOffender),
+		strconv.FormatBool(fp.Session.HumanVerified),
+	} {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	h.Sum(key[:0])
+	return key, true
+}
+
+// greaseKey records where a ClientHello carried GREASE values
+func greaseKey(tls TLSFingerprint) string {
+	return strconv.FormatBool(tls.GREASECiphers) + strconv.FormatBool(tls.GREASEExtensions) +
+		strconv.FormatBool(slices.Contains(tls.SupportedGroups, "GREASE"))
+}
@@ -0,0 +1,188 @@
+// Package geoip looks up the country of client addresses for the
+// geo/language consistency signals. Lookups go through the Locator
+// interface, so deployments can plug in any GeoIP database; DB reads
+// country CSV exports such as DB-IP Lite's, or network,country lists.
+// Without a locator nothing is looked up and the signals stay off.
+package geoip
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Locator returns the ISO 3166-1 alpha-2 country of an address
+type Locator interface {
+	Country(addr netip.Addr) (string, bool)
+}
+
+// ipRange is one contiguous block of addresses in a country
+type ipRange struct {
+	first, last netip.Addr
+	country     string
+}
+
+// DB is a country database of address ranges, sorted for binary search
+type DB struct {
+	ranges []ipRange
+}
+
+// Parse reads a country CSV. Each row is either first,last,country
+// (DB-IP Lite) or network,country; blank lines, # comments and a header
+// row are skipped, and trailing columns ignored.
+func Parse(r io.Reader) (*DB, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+	cr.Comment = '#'
+
+	db := &DB{}
+	line := 0
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		rg, err := parseRow(rec)
+		if err != nil {
+			if line == 1 {
+				continue // Header row
+			}
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		db.ranges = append(db.ranges, rg)
+	}
+	if len(db.ranges) == 0 {
+		return nil, errors.New("no address ranges")
+	}
+	slices.SortFunc(db.ranges, func(a, b ipRange) int { return a.first.Compare(b.first) })
+	return db, nil
+}
+
+// parseRow parses a first,last,country or network,country row
+func parseRow(rec []string) (ipRange, error) {
+	for i := range rec {
+		rec[i] = strings.TrimSpace(rec[i])
+	}
+	if len(rec) >= 3 {
+		first, err1 := netip.ParseAddr(rec[0])
+		last, err2 := netip.ParseAddr(rec[1])
+		if err1 == nil && err2 == nil {
+			if first.Is4() != last.Is4() || last.Less(first) {
+				return ipRange{}, fmt.Errorf("invalid range %s-%s", first, last)
+			}
+			return newRange(first.Unmap(), last.Unmap(), rec[2])
+		}
+	}
+	if len(rec) < 2 {
+		return ipRange{}, errors.New("want first,last,country or network,country")
+	}
+	p, err := netip.ParsePrefix(rec[0])
+	if err != nil {
+		return ipRange{}, err
+	}
+	p = p.Masked()
+	return newRange(p.Addr(), lastAddr(p), rec[1])
+}
+
+// newRange validates the country code of a range
+func newRange(first, last netip.Addr, country string) (ipRange, error) {
+	if len(country) != 2 {
+		return ipRange{}, fmt.Errorf("invalid country code %q", country)
+	}
+	return ipRange{first: first, last: last, country: strings.ToUpper(country)}, nil
+}
+
+// lastAddr returns the last address of a prefix
+func lastAddr(p netip.Prefix) netip.Addr {
+	b := p.Addr().AsSlice()
+	for i := p.Bits(); i < len(b)*8; i++ {
+		b[i/8] |= 0x80 >> (i % 8)
+	}
+	a, _ := netip.AddrFromSlice(b)
+	return a
+}
+
+// Load reads a country CSV from a file
+func Load(path string) (*DB, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	db, err := Parse(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return db, nil
+}
+
+// Len returns the number of address ranges
+func (db *DB) Len() int {
+	return len(db.ranges)
+}
+
+// Country returns the country of the range containing addr. Ranges are
+// expected not to overlap.
+func (db *DB) Country(addr netip.Addr) (string, bool) {
+	addr = addr.Unmap()
+	i, found := slices.BinarySearchFunc(db.ranges, addr, func(r ipRange, a netip.Addr) int {
+		return cmp.Compare(r.first.Compare(a), 0)
+	})
+	if !found {
+		i--
+	}
+	if i < 0 || db.ranges[i].last.Less(addr) || db.ranges[i].first.Is4() != addr.Is4() {
+		return "", false
+	}
+	return db.ranges[i].country, true
+}
+
+// Enricher sets the client's country from a Locator. It implements
+// classifier.Enricher.
+type Enricher struct {
+	locator Locator
+}
+
+// NewEnricher returns an enricher looking countries up with l
+func NewEnricher(l Locator) *Enricher {
+	return &Enricher{locator: l}
+}
+
+// Name identifies the lookup in ClassificationResult.Incomplete
+func (e *Enricher) Name() string {
+	return "geoip"
+}
+
+// Enrich records the country of the remote address in fp.Network.Country.
+// Loopback and private addresses, such as a load balancer's, are left
+// without one.
+func (e *Enricher) Enrich(_ context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() {
+		return nil
+	}
+	if country, ok := e.locator.Country(addr); ok {
+		fp.Network.Country = country
+	}
+	return nil
+}
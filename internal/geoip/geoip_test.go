@@ -0,0 +1,13 @@
+package geoip
+
+import "testing"
+
+// Tests are in tests/unit/geoip_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestGeoipPackage(t *testing.T) {
+	// Verify package is testable
+	if NewEnricher(&DB{}).Name() == "" {
+		t.Error("Name() should not be empty")
+	}
+}
@@ -0,0 +1,129 @@
+package har
+
+import (
+	"sort"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// EntryResult is the classification of a single HAR entry
+type EntryResult struct {
+	Index          int      `json:"index"`
+	Method         string   `json:"method"`
+	URL            string   `json:"url"`
+	HTTPVersion    string   `json:"http_version"`
+	ResourceType   string   `json:"resource_type,omitempty"`
+	Status         int      `json:"status"`
+	Classification string   `json:"classification,omitempty"`
+	Confidence     float64  `json:"confidence,omitempty"`
+	Score          int      `json:"score"`
+	BotRules       []string `json:"bot_rules,omitempty"` // Bot-side rules that fired
+	Error          string   `json:"error,omitempty"`
+}
+
+// RuleCount is how often a rule fired on bot-classified entries
+type RuleCount struct {
+	Rule  string `json:"rule"`
+	Count int    `json:"count"`
+}
+
+// TypeSummary counts entries of one resource type
+type TypeSummary struct {
+	ResourceType string `json:"resource_type"`
+	Total        int    `json:"total"`
+	Bot          int    `json:"bot"`
+}
+
+// Summary aggregates the results of a HAR file
+type Summary struct {
+	Entries int           `json:"entries"`
+	Browser int           `json:"browser"`
+	Bot     int           `json:"bot"`
+	Errors  int           `json:"errors"`
+	Rules   []RuleCount   `json:"rules,omitempty"` // Bot rules behind bot verdicts, most frequent first
+	Types   []TypeSummary `json:"types,omitempty"` // Per resource type, most bot verdicts first
+}
+
+// Analyze classifies every entry of a HAR document. HAR files carry no
+// TLS details, so only HTTP-level signals contribute to the scores.
+func Analyze(doc *HAR, c *classifier.Classifier) ([]EntryResult, Summary) {
+	collector := fingerprint.NewCollector()
+	results := make([]EntryResult, 0, len(doc.Log.Entries))
+	summary := Summary{Entries: len(doc.Log.Entries)}
+	rules := map[string]int{}
+	types := map[string]*TypeSummary{}
+
+	for i, e := range doc.Log.Entries {
+		res := EntryResult{
+			Index:        i,
+			Method:       e.Request.Method,
+			URL:          e.Request.URL,
+			HTTPVersion:  e.Request.HTTPVersion,
+			ResourceType: e.ResourceType,
+			Status:       e.Response.Status,
+		}
+
+		req, err := e.Request.HTTPRequest()
+		if err != nil {
+			res.Error = err.Error()
+			summary.Errors++
+			results = append(results, res)
+			continue
+		}
+
+		result := c.Classify(collector.Collect(req))
+		res.Classification = result.Classification
+		res.Confidence = result.Confidence
+		res.Score = result.Score
+		_, res.BotRules = result.Signals.ScoreBreakdown.Rules()
+		results = append(results, res)
+
+		typeName := e.ResourceType
+		if typeName == "" {
+			typeName = "other"
+		}
+		ts := types[typeName]
+		if ts == nil {
+			ts = &TypeSummary{ResourceType: typeName}
+			types[typeName] = ts
+		}
+		ts.Total++
+
+		if result.Classification == classifier.ClassificationBot {
+			summary.Bot++
+			ts.Bot++
+			for _, r := range res.BotRules {
+				rules[r]++
+			}
+		} else {
+			summary.Browser++
+		}
+	}
+
+	for rule, n := range rules {
+		summary.Rules = append(summary.Rules, RuleCount{Rule: rule, Count: n})
+	}
+	sort.Slice(summary.Rules, func(i, j int) bool {
+		if summary.Rules[i].Count != summary.Rules[j].Count {
+			return summary.Rules[i].Count > summary.Rules[j].Count
+		}
+		return summary.Rules[i].Rule < summary.Rules[j].Rule
+	})
+
+	for _, ts := range types {
+		summary.Types = append(summary.Types, *ts)
+	}
+	sort.Slice(summary.Types, func(i, j int) bool {
+		a, b := summary.Types[i], summary.Types[j]
+		if a.Bot != b.Bot {
+			return a.Bot > b.Bot
+		}
+		if a.Total != b.Total {
+			return a.Total > b.Total
+		}
+		return a.ResourceType < b.ResourceType
+	})
+
+	return results, summary
+}
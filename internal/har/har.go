@@ -0,0 +1,118 @@
+// Package har parses HTTP Archive (HAR 1.2) files exported by browsers
+// and converts their entries into *http.Request values for the collector.
+package har
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// HAR is the top-level HAR document
+type HAR struct {
+	Log Log `json:"log"`
+}
+
+// Log contains the recorded entries
+type Log struct {
+	Version string  `json:"version"`
+	Creator Creator `json:"creator"`
+	Entries []Entry `json:"entries"`
+}
+
+// Creator identifies the exporting application
+type Creator struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// Entry is a single request/response pair
+type Entry struct {
+	StartedDateTime string   `json:"startedDateTime"`
+	Request         Request  `json:"request"`
+	Response        Response `json:"response"`
+	ResourceType    string   `json:"_resourceType,omitempty"` // Chrome-specific resource type
+}
+
+// Request is the recorded request
+type Request struct {
+	Method      string   `json:"method"`
+	URL         string   `json:"url"`
+	HTTPVersion string   `json:"httpVersion"`
+	Headers     []Header `json:"headers"`
+}
+
+// Response is the recorded response (only the fields we report on)
+type Response struct {
+	Status int `json:"status"`
+}
+
+// Header is a single name/value header pair
+type Header struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Parse decodes a HAR document
+func Parse(r io.Reader) (*HAR, error) {
+	var h HAR
+	if err := json.NewDecoder(r).Decode(&h); err != nil {
+		return nil, fmt.Errorf("invalid HAR: %w", err)
+	}
+	return &h, nil
+}
+
+// HTTPRequest converts the recorded request into an *http.Request.
+// HTTP/2 pseudo-headers (":authority", ":method", ...) are dropped.
+func (r Request) HTTPRequest() (*http.Request, error) {
+	u, err := url.Parse(r.URL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request URL: %w", err)
+	}
+
+	method := r.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequest(method, u.String(), http.NoBody)
+	if err != nil {
+		return nil, fmt.Errorf("invalid request: %w", err)
+	}
+
+	req.Proto, req.ProtoMajor, req.ProtoMinor = NormalizeHTTPVersion(r.HTTPVersion)
+
+	for _, h := range r.Headers {
+		if strings.HasPrefix(h.Name, ":") {
+			if h.Name == ":authority" {
+				req.Host = h.Value
+			}
+			continue
+		}
+		if strings.EqualFold(h.Name, "Host") {
+			req.Host = h.Value
+			continue
+		}
+		req.Header.Add(h.Name, h.Value)
+	}
+
+	return req, nil
+}
+
+// NormalizeHTTPVersion maps HAR httpVersion values ("http/2.0", "h2",
+// "HTTP/1.1", "h3") to Go's Proto representation
+func NormalizeHTTPVersion(v string) (proto string, major, minor int) {
+	switch strings.ToLower(strings.TrimSpace(v)) {
+	case "h2", "http/2", "http/2.0":
+		return "HTTP/2.0", 2, 0
+	case "h3", "http/3", "http/3.0":
+		return "HTTP/3.0", 3, 0
+	case "http/1.0":
+		return "HTTP/1.0", 1, 0
+	default:
+		return "HTTP/1.1", 1, 1
+	}
+}
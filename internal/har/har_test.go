@@ -0,0 +1,13 @@
+package har
+
+import "testing"
+
+// Tests are in tests/unit/har_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestHARPackage(t *testing.T) {
+	// Verify package is testable
+	if proto, _, _ := NormalizeHTTPVersion("h2"); proto != "HTTP/2.0" {
+		t.Errorf("NormalizeHTTPVersion(h2) = %q, want HTTP/2.0", proto)
+	}
+}
@@ -0,0 +1,113 @@
+// Package honeypot sets traps for clients that ignore robots.txt: paths
+// linked only invisibly from pages and disallowed for every crawler. No
+// person sees the links and no well-behaved crawler follows them, so a
+// client requesting a trap is a scraper.
+package honeypot
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"html"
+	"slices"
+	"strings"
+)
+
+// Traps is a set of trap paths. A path ending in "/" traps everything
+// below it.
+type Traps struct {
+	paths []string
+}
+
+// New returns the traps at the given paths, which must be absolute and
+// may not be the site root
+func New(paths []string) (*Traps, error) {
+	t := &Traps{}
+	for _, p := range paths {
+		p = strings.TrimSpace(p)
+		switch {
+		case p == "":
+			continue
+		case !strings.HasPrefix(p, "/") || p == "/":
+			return nil, fmt.Errorf("invalid trap path %q: want an absolute path below /", p)
+		case strings.ContainsAny(p, " \t{}?#"):
+			return nil, fmt.Errorf("invalid trap path %q", p)
+		}
+		if !slices.Contains(t.paths, p) {
+			t.paths = append(t.paths, p)
+		}
+	}
+	if len(t.paths) == 0 {
+		return nil, errors.New("no trap paths")
+	}
+	return t, nil
+}
+
+// Paths returns the trap paths
+func (t *Traps) Paths() []string {
+	return slices.Clone(t.paths)
+}
+
+// Match returns the trap a request path falls into
+func (t *Traps) Match(path string) (string, bool) {
+	for _, p := range t.paths {
+		if path == p || strings.HasSuffix(p, "/") && strings.HasPrefix(path, p) {
+			return p, true
+		}
+	}
+	return "", false
+}
+
+// Links returns the HTML of links to the traps that browsers do not show
+// and assistive technology skips, for embedding in site pages
+func (t *Traps) Links() string {
+	var b strings.Builder
+	for _, p := range t.paths {
+		fmt.Fprintf(&b, `<a href="%s" rel="nofollow" style="display:none" aria-hidden="true" tabindex="-1"></a>`+"\n", html.EscapeString(p))
+	}
+	return b.String()
+}
+
+// Robots returns robots.txt with the traps disallowed in every user-agent
+// group, so that crawlers with a group of their own are kept out too. A
+// robots.txt without groups, or none at all, gets a "User-agent: *" group.
+func (t *Traps) Robots(robots []byte) []byte {
+	var b bytes.Buffer
+	disallow := func() {
+		for _, p := range t.paths {
+			fmt.Fprintf(&b, "Disallow: %s\n", p)
+		}
+	}
+
+	groups := 0
+	inAgents := false // Within the user-agent lines that open a group
+	for line := range bytes.Lines(robots) {
+		if !bytes.HasSuffix(line, []byte("\n")) {
+			line = append(line, '\n')
+		}
+		field, _, ok := bytes.Cut(line, []byte(":"))
+		field = bytes.ToLower(bytes.TrimSpace(field))
+		if !ok || bytes.HasPrefix(field, []byte("#")) {
+			// Blank lines and comments neither open nor close a group
+			b.Write(line)
+			continue
+		}
+		agent := bytes.Equal(field, []byte("user-agent"))
+		if inAgents && !agent {
+			disallow()
+		}
+		b.Write(line)
+		if agent && !inAgents {
+			groups++
+		}
+		inAgents = agent
+	}
+	if inAgents {
+		disallow()
+	}
+	if groups == 0 {
+		b.WriteString("User-agent: *\n")
+		disallow()
+	}
+	return b.Bytes()
+}
@@ -0,0 +1,13 @@
+package honeypot
+
+import "testing"
+
+// Tests are in tests/unit/honeypot_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestHoneypotPackage(t *testing.T) {
+	// Verify package is testable
+	if _, err := New([]string{"/trap"}); err != nil {
+		t.Errorf("New: %v", err)
+	}
+}
@@ -0,0 +1,98 @@
+// Package ipkey normalizes client addresses into keys for per-client state.
+//
+// A single IPv6 host usually owns a whole /64 and can rotate through it at
+// will, so tracking per address is trivially evaded. Keys aggregate
+// addresses to a network prefix instead (/64 for IPv6 by default, the full
+// address for IPv4), after normalizing IPv4-mapped IPv6 addresses, zones
+// and spelling variants of the same address.
+package ipkey
+
+import (
+	"fmt"
+	"net"
+	"net/netip"
+)
+
+// Default prefix lengths state is aggregated at
+const (
+	DefaultIPv4Prefix = 32
+	DefaultIPv6Prefix = 64
+)
+
+// Config holds the prefix lengths addresses are aggregated at
+type Config struct {
+	IPv4Prefix int // IPv4 prefix length (default 32, one key per address)
+	IPv6Prefix int // IPv6 prefix length (default 64, one key per subnet)
+}
+
+// DefaultConfig returns the default prefix lengths
+func DefaultConfig() Config {
+	return Config{IPv4Prefix: DefaultIPv4Prefix, IPv6Prefix: DefaultIPv6Prefix}
+}
+
+// Keyer maps client addresses to the keys of their subnets
+type Keyer struct {
+	cfg Config
+}
+
+// New creates a keyer, defaulting zero prefix lengths
+func New(cfg Config) (*Keyer, error) {
+	if cfg.IPv4Prefix == 0 {
+		cfg.IPv4Prefix = DefaultIPv4Prefix
+	}
+	if cfg.IPv6Prefix == 0 {
+		cfg.IPv6Prefix = DefaultIPv6Prefix
+	}
+	if cfg.IPv4Prefix < 0 || cfg.IPv4Prefix > 32 {
+		return nil, fmt.Errorf("ipkey: invalid IPv4 prefix /%d", cfg.IPv4Prefix)
+	}
+	if cfg.IPv6Prefix < 0 || cfg.IPv6Prefix > 128 {
+		return nil, fmt.Errorf("ipkey: invalid IPv6 prefix /%d", cfg.IPv6Prefix)
+	}
+	return &Keyer{cfg: cfg}, nil
+}
+
+// Config returns the keyer's prefix lengths
+func (k *Keyer) Config() Config {
+	return k.cfg
+}
+
+// Prefix returns the subnet of a remote address ("ip:port" or a bare IP),
+// and false when it is not an IP address
+func (k *Keyer) Prefix(remoteAddr string) (netip.Prefix, bool) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.WithZone("").Unmap()
+	bits := k.cfg.IPv6Prefix
+	if addr.Is4() {
+		bits = k.cfg.IPv4Prefix
+	}
+	p, err := addr.Prefix(bits)
+	return p, err == nil
+}
+
+// Key returns the state key of a remote address: the bare address for
+// full-length prefixes (e.g. "203.0.113.7"), the subnet otherwise (e.g.
+// "2001:db8:1:2::/64"). Values that are not IP addresses are returned
+// unchanged.
+func (k *Keyer) Key(remoteAddr string) string {
+	p, ok := k.Prefix(remoteAddr)
+	if !ok {
+		return remoteAddr
+	}
+	return String(p)
+}
+
+// String formats a prefix as a key, omitting the length of single addresses
+func String(p netip.Prefix) string {
+	if p.IsSingleIP() {
+		return p.Addr().String()
+	}
+	return p.String()
+}
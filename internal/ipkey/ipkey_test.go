@@ -0,0 +1,13 @@
+package ipkey
+
+import "testing"
+
+// Tests are in tests/unit/ipkey_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestIPKeyPackage(t *testing.T) {
+	// Verify package is testable
+	if _, err := New(Config{}); err != nil {
+		t.Errorf("New() error = %v", err)
+	}
+}
@@ -0,0 +1,345 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"time"
+)
+
+// DefaultCheckpointEvery is the number of audited entries between signed
+// checkpoints
+const DefaultCheckpointEvery = 1000
+
+// auditMarker starts the audit record at the end of an audited line
+var auditMarker = []byte(`,"audit":`)
+
+// AuditConfig enables the tamper-evident audit mode: every entry is
+// chained to the previous one by hash and the chain head is periodically
+// signed into <log>.checkpoints
+type AuditConfig struct {
+	Enabled         bool
+	SigningKey      ed25519.PrivateKey // Signs checkpoints; none are written when nil
+	CheckpointEvery int                // Entries between checkpoints (default DefaultCheckpointEvery)
+}
+
+// AuditRecord chains a log entry to the entry before it
+type AuditRecord struct {
+	Seq  uint64 `json:"seq"`  // Position in the chain, from 1
+	Prev string `json:"prev"` // Hash of the previous entry, empty for the first
+	Hash string `json:"hash"` // SHA-256 of Prev and the line without its audit record
+}
+
+// Checkpoint is a signed statement of the chain head at Seq
+type Checkpoint struct {
+	Seq       uint64    `json:"seq"`
+	Hash      string    `json:"hash"`
+	Timestamp time.Time `json:"timestamp"`
+	Signature string    `json:"signature"` // Base64 Ed25519 signature of the checkpoint message
+}
+
+// CheckpointPath returns the checkpoint file of a log
+func CheckpointPath(logPath string) string {
+	return logPath + ".checkpoints"
+}
+
+// message is the byte string a checkpoint signature covers
+func (c Checkpoint) message() []byte {
+	return []byte("go-client-classifier audit checkpoint\n" +
+		strconv.FormatUint(c.Seq, 10) + "\n" + c.Hash + "\n" + c.Timestamp.UTC().Format(time.RFC3339Nano))
+}
+
+// chainHash hashes a line, without its audit record, onto prev
+func chainHash(prev string, body []byte) string {
+	h := sha256.New()
+	h.Write([]byte(prev))
+	h.Write(body)
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// auditChain appends hash-chained entries and writes checkpoints
+type auditChain struct {
+	head        AuditRecord
+	key         ed25519.PrivateKey
+	every       int
+	checkpoints *os.File
+	lastSigned  uint64
+}
+
+// newAuditChain resumes the chain at the end of the log at logPath
+func newAuditChain(cfg AuditConfig, logPath string) (*auditChain, error) {
+	head, err := lastAuditRecord(logPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resume audit chain: %w", err)
+	}
+	c := &auditChain{head: head, key: cfg.SigningKey, every: cfg.CheckpointEvery, lastSigned: head.Seq}
+	if c.every <= 0 {
+		c.every = DefaultCheckpointEvery
+	}
+	if c.key != nil {
+		c.checkpoints, err = os.OpenFile(CheckpointPath(logPath), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+// seal returns the encoded entry with its audit record, and advances the
+// chain
+func (c *auditChain) seal(entry LogEntry) ([]byte, error) {
+	entry.Audit = nil
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return nil, err
+	}
+	rec := AuditRecord{Seq: c.head.Seq + 1, Prev: c.head.Hash, Hash: chainHash(c.head.Hash, body)}
+	recJSON, err := json.Marshal(rec)
+	if err != nil {
+		return nil, err
+	}
+	line := make([]byte, 0, len(body)+len(auditMarker)+len(recJSON)+2)
+	line = append(line, body[:len(body)-1]...)
+	line = append(line, auditMarker...)
+	line = append(line, recJSON...)
+	line = append(line, '}', '\n')
+	c.head = rec
+	return line, nil
+}
+
+// checkpoint signs the chain head when force is set or every entries
+// have been added since the last checkpoint
+func (c *auditChain) checkpoint(force bool) error {
+	if c.checkpoints == nil || c.head.Seq == c.lastSigned {
+		return nil
+	}
+	if !force && c.head.Seq-c.lastSigned < uint64(c.every) {
+		return nil
+	}
+	cp := Checkpoint{Seq: c.head.Seq, Hash: c.head.Hash, Timestamp: time.Now().UTC()}
+	cp.Signature = base64.StdEncoding.EncodeToString(ed25519.Sign(c.key, cp.message()))
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	if _, err := c.checkpoints.Write(append(data, '\n')); err != nil {
+		return err
+	}
+	c.lastSigned = c.head.Seq
+	return nil
+}
+
+// close writes a final checkpoint and closes the checkpoint file
+func (c *auditChain) close() error {
+	if c.checkpoints == nil {
+		return nil
+	}
+	return errors.Join(c.checkpoint(true), c.checkpoints.Close())
+}
+
+// lastAuditRecord returns the audit record of the last line of a log, or
+// the zero record when the log is empty or its last entry is not audited
+func lastAuditRecord(path string) (AuditRecord, error) {
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return AuditRecord{}, nil
+	}
+	if err != nil {
+		return AuditRecord{}, err
+	}
+	defer func() { _ = f.Close() }()
+
+	st, err := f.Stat()
+	if err != nil {
+		return AuditRecord{}, err
+	}
+	offset := max(st.Size()-maxLineBytes, 0)
+	tail := make([]byte, st.Size()-offset)
+	if _, err := f.ReadAt(tail, offset); err != nil && !errors.Is(err, io.EOF) {
+		return AuditRecord{}, err
+	}
+	tail = bytes.TrimRight(tail, "\n")
+	if len(tail) == 0 {
+		return AuditRecord{}, nil
+	}
+	line := tail[bytes.LastIndexByte(tail, '\n')+1:]
+	_, rec, ok, err := splitAudit(line)
+	if err != nil || !ok {
+		return AuditRecord{}, err
+	}
+	return rec, nil
+}
+
+// splitAudit separates an audited line into the hashed body and its audit
+// record; ok is false for lines without one
+func splitAudit(line []byte) (body []byte, rec AuditRecord, ok bool, err error) {
+	i := bytes.LastIndex(line, auditMarker)
+	if i < 0 || !bytes.HasSuffix(line, []byte("}")) {
+		return line, AuditRecord{}, false, nil
+	}
+	if err := json.Unmarshal(line[i+len(auditMarker):len(line)-1], &rec); err != nil {
+		return nil, AuditRecord{}, false, fmt.Errorf("invalid audit record: %w", err)
+	}
+	body = make([]byte, 0, i+1)
+	body = append(body, line[:i]...)
+	body = append(body, '}')
+	return body, rec, true, nil
+}
+
+// AuditReport summarizes a verified audit log
+type AuditReport struct {
+	Unaudited   int         `json:"unaudited"`   // Entries logged before audit mode was enabled
+	Audited     int         `json:"audited"`     // Entries whose chain verified
+	Head        AuditRecord `json:"head"`        // Last audited entry
+	Checkpoints int         `json:"checkpoints"` // Checkpoints whose signature and hash verified
+	Signed      uint64      `json:"signed"`      // Seq of the last verified checkpoint
+}
+
+// VerifyAudit checks the hash chain of a log and, when checkpoints is not
+// nil, that every checkpoint is signed by pub and matches the chain. It
+// fails on the first altered, inserted or removed entry, and on a log
+// shorter than its last checkpoint.
+func VerifyAudit(log, checkpoints io.Reader, pub ed25519.PublicKey) (AuditReport, error) {
+	var report AuditReport
+	signed := map[uint64]Checkpoint{}
+	if checkpoints != nil {
+		var err error
+		if signed, err = readCheckpoints(checkpoints, pub); err != nil {
+			return report, err
+		}
+	}
+
+	scanner := bufio.NewScanner(log)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+		body, rec, ok, err := splitAudit(line)
+		if err != nil {
+			return report, fmt.Errorf("line %d: %w", lineNo, err)
+		}
+		if !ok {
+			if report.Audited > 0 {
+				return report, fmt.Errorf("line %d: entry without audit record inside the chain", lineNo)
+			}
+			report.Unaudited++
+			continue
+		}
+		if rec.Seq != report.Head.Seq+1 || rec.Prev != report.Head.Hash {
+			return report, fmt.Errorf("line %d: chain broken: seq %d follows seq %d", lineNo, rec.Seq, report.Head.Seq)
+		}
+		if chainHash(rec.Prev, body) != rec.Hash {
+			return report, fmt.Errorf("line %d: entry seq %d was altered", lineNo, rec.Seq)
+		}
+		report.Audited++
+		report.Head = rec
+
+		if cp, ok := signed[rec.Seq]; ok {
+			if cp.Hash != rec.Hash {
+				return report, fmt.Errorf("line %d: entry seq %d does not match its signed checkpoint", lineNo, rec.Seq)
+			}
+			report.Checkpoints++
+			report.Signed = rec.Seq
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return report, err
+	}
+	if report.Audited == 0 && report.Unaudited > 0 {
+		return report, errors.New("log has no audited entries")
+	}
+	if report.Checkpoints < len(signed) {
+		return report, fmt.Errorf("log ends at seq %d before a signed checkpoint: entries were removed", report.Head.Seq)
+	}
+	return report, nil
+}
+
+// readCheckpoints reads a checkpoint file, verifying every signature
+func readCheckpoints(r io.Reader, pub ed25519.PublicKey) (map[uint64]Checkpoint, error) {
+	if len(pub) != ed25519.PublicKeySize {
+		return nil, errors.New("checkpoints need an Ed25519 public key")
+	}
+	signed := map[uint64]Checkpoint{}
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var cp Checkpoint
+		if err := json.Unmarshal(scanner.Bytes(), &cp); err != nil {
+			return nil, fmt.Errorf("checkpoint %d: %w", lineNo, err)
+		}
+		sig, err := base64.StdEncoding.DecodeString(cp.Signature)
+		if err != nil || !ed25519.Verify(pub, cp.message(), sig) {
+			return nil, fmt.Errorf("checkpoint %d (seq %d): invalid signature", lineNo, cp.Seq)
+		}
+		signed[cp.Seq] = cp
+	}
+	return signed, scanner.Err()
+}
+
+// LoadSigningKey reads a PEM PKCS #8 Ed25519 private key, as written by
+// `openssl genpkey -algorithm ed25519`
+func LoadSigningKey(path string) (ed25519.PrivateKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	priv, ok := key.(ed25519.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 private key", path)
+	}
+	return priv, nil
+}
+
+// LoadVerifyKey reads a PEM PKIX Ed25519 public key, as written by
+// `openssl pkey -pubout`
+func LoadVerifyKey(path string) (ed25519.PublicKey, error) {
+	block, err := readPEM(path)
+	if err != nil {
+		return nil, err
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	pub, ok := key.(ed25519.PublicKey)
+	if !ok {
+		return nil, fmt.Errorf("%s: not an Ed25519 public key", path)
+	}
+	return pub, nil
+}
+
+// readPEM reads the first PEM block of a file
+func readPEM(path string) (*pem.Block, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM data", path)
+	}
+	return block, nil
+}
@@ -2,6 +2,7 @@ package logger
 
 import (
 	"encoding/json"
+	"errors"
 	"io"
 	"os"
 	"path/filepath"
@@ -23,14 +24,25 @@ type LogEntry struct {
 	Score          int                     `json:"score"`
 	Reason         string                  `json:"reason"`
 	ResponseTimeMs int64                   `json:"response_time_ms"`
+	CFBotScore     int                     `json:"cf_bot_score,omitempty"` // Cloudflare-compatible 1-99 score (when enabled)
+	Tenant         string                  `json:"tenant,omitempty"`       // Tenant the request was attributed to
+
+	// Hash chain link, in audit mode; always encoded last
+	Audit *AuditRecord `json:"audit,omitempty"`
 }
 
 // Logger handles structured JSON logging
 type Logger struct {
-	mu      sync.Mutex
-	file    *os.File
-	encoder *json.Encoder
-	writers []io.Writer
+	mu       sync.Mutex
+	file     *os.File
+	encoder  *json.Encoder
+	writers  []io.Writer
+	validate bool
+	profile  Profile
+
+	// Audit mode
+	writer io.Writer
+	audit  *auditChain
 }
 
 // Config holds logger configuration
@@ -38,6 +50,13 @@ type Config struct {
 	LogDir   string // Directory for log files
 	FileName string // Log file name (default: requests.jsonl)
 	Stdout   bool   // Also write to stdout
+	Validate bool   // Reject entries that do not match the published schema
+
+	// Fields recorded per request (default: ProfileFull)
+	Profile Profile
+
+	// Tamper-evident hash chain and signed checkpoints (disabled by default)
+	Audit AuditConfig
 }
 
 // DefaultConfig returns default logger configuration
@@ -51,6 +70,11 @@ func DefaultConfig() Config {
 
 // New creates a new logger instance
 func New(cfg Config) (*Logger, error) {
+	profile, err := ParseProfile(string(cfg.Profile))
+	if err != nil {
+		return nil, err
+	}
+
 	// Ensure log directory exists
 	if err := os.MkdirAll(cfg.LogDir, 0o755); err != nil {
 		return nil, err
@@ -76,24 +100,63 @@ func New(cfg Config) (*Logger, error) {
 		writer = io.MultiWriter(writers...)
 	}
 
+	var audit *auditChain
+	if cfg.Audit.Enabled {
+		if audit, err = newAuditChain(cfg.Audit, logPath); err != nil {
+			_ = file.Close()
+			return nil, err
+		}
+	}
+
 	return &Logger{
-		file:    file,
-		encoder: json.NewEncoder(writer),
-		writers: writers,
+		file:     file,
+		encoder:  json.NewEncoder(writer),
+		writers:  writers,
+		validate: cfg.Validate,
+		profile:  profile,
+		writer:   writer,
+		audit:    audit,
 	}, nil
 }
 
-// Log writes a classification result to the log
+// Log writes a classification result to the log, minimized under
+// ProfileMinimal. With Config.Validate set, entries that do not match the
+// published schema are rejected and not written. In audit mode the entry
+// is chained to the previous one and a checkpoint signed when due.
 func (l *Logger) Log(entry LogEntry) error {
+	if l.profile == ProfileMinimal {
+		entry = entry.Minimize()
+	}
+	if l.validate {
+		if err := ValidateEntry(entry); err != nil {
+			return err
+		}
+	}
+
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
-	return l.encoder.Encode(entry)
+	if l.audit == nil {
+		return l.encoder.Encode(entry)
+	}
+	line, err := l.audit.seal(entry)
+	if err != nil {
+		return err
+	}
+	if _, err := l.writer.Write(line); err != nil {
+		return err
+	}
+	return l.audit.checkpoint(false)
 }
 
 // LogResult logs a ClassificationResult with additional metadata
 func (l *Logger) LogResult(result fingerprint.ClassificationResult, remoteAddr string, responseTimeMs int64) error {
-	entry := LogEntry{
+	return l.Log(NewEntry(result, remoteAddr, responseTimeMs))
+}
+
+// NewEntry builds the log entry of a ClassificationResult
+func NewEntry(result fingerprint.ClassificationResult, remoteAddr string, responseTimeMs int64) LogEntry {
+	return LogEntry{
 		Timestamp:      result.Timestamp,
 		RequestID:      result.RequestID,
 		RemoteAddr:     remoteAddr,
@@ -105,7 +168,6 @@ func (l *Logger) LogResult(result fingerprint.ClassificationResult, remoteAddr s
 		Reason:         result.Reason,
 		ResponseTimeMs: responseTimeMs,
 	}
-	return l.Log(entry)
 }
 
 // Close closes the logger
@@ -113,10 +175,19 @@ func (l *Logger) Close() error {
 	l.mu.Lock()
 	defer l.mu.Unlock()
 
+	var err error
+	if l.audit != nil {
+		err = l.audit.close()
+	}
 	if l.file != nil {
-		return l.file.Close()
+		err = errors.Join(err, l.file.Close())
 	}
-	return nil
+	return err
+}
+
+// Profile returns the logger's profile
+func (l *Logger) Profile() Profile {
+	return l.profile
 }
 
 // LogPath returns the path to the log file
@@ -0,0 +1,82 @@
+package logger
+
+import (
+	"fmt"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Profile selects how much of each request a log entry records
+type Profile string
+
+// Log profiles
+const (
+	// ProfileFull records the complete fingerprint (default)
+	ProfileFull Profile = "full"
+	// ProfileMinimal records the classification, scores, fingerprint
+	// hashes and coarse metadata only: no client address, headers,
+	// User-Agent (only its parsed family, version, OS and device), path,
+	// cookies or SNI
+	ProfileMinimal Profile = "minimal"
+)
+
+// ParseProfile parses a profile name; empty selects ProfileFull
+func ParseProfile(s string) (Profile, error) {
+	switch Profile(s) {
+	case "", ProfileFull:
+		return ProfileFull, nil
+	case ProfileMinimal:
+		return ProfileMinimal, nil
+	}
+	return "", fmt.Errorf("unknown log profile %q (use full or minimal)", s)
+}
+
+// Minimize strips an entry down to ProfileMinimal
+func (e LogEntry) Minimize() LogEntry {
+	tls, http := e.Fingerprint.TLS, e.Fingerprint.HTTP
+	e.RemoteAddr = ""
+	e.Fingerprint = fingerprint.Fingerprint{
+		TLS: fingerprint.TLSFingerprint{
+			Version:           tls.Version,
+			CipherSuite:       tls.CipherSuite,
+			ALPN:              tls.ALPN,
+			CipherSuitesCount: tls.CipherSuitesCount,
+			ExtensionsCount:   tls.ExtensionsCount,
+			HasSessionTicket:  tls.HasSessionTicket,
+			HasEarlyData:      tls.HasEarlyData,
+			JA3Hash:           tls.JA3Hash,
+			JA4Hash:           tls.JA4Hash,
+			JA4Raw:            tls.JA4Raw,
+			JA3SHash:          tls.JA3SHash,
+			JA4SHash:          tls.JA4SHash,
+			Available:         tls.Available,
+			NoGREASE:          tls.NoGREASE,
+			LegacyCiphers:     tls.LegacyCiphers,
+			GREASECiphers:     tls.GREASECiphers,
+			GREASEExtensions:  tls.GREASEExtensions,
+			Extensions:        tls.Extensions,
+			ECH:               tls.ECH,
+			LatencyMicros:     tls.LatencyMicros,
+			JA4L:              tls.JA4L,
+		},
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:      http.Version,
+			Method:       http.Method,
+			HeaderCount:  http.HeaderCount,
+			HasCookies:   http.HasCookies,
+			HasReferer:   http.HasReferer,
+			JA4HHash:     http.JA4HHash,
+			PrivateToken: http.PrivateToken,
+
+			ChallengeToken:  http.ChallengeToken,
+			UserAgentParsed: http.UserAgentParsed,
+		},
+		Session:  e.Fingerprint.Session,
+		Rate:     e.Fingerprint.Rate,
+		Offender: e.Fingerprint.Offender,
+		Network:  e.Fingerprint.Network,
+		TCP:      e.Fingerprint.TCP,
+		QUIC:     e.Fingerprint.QUIC,
+	}
+	return e
+}
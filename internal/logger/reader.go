@@ -0,0 +1,113 @@
+package logger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// maxLineBytes bounds a single JSONL log line
+const maxLineBytes = 4 << 20
+
+// findChunkBytes is how much of the log FindEntry reads at a time
+const findChunkBytes = 64 << 10
+
+// Reader reads log entries from a JSONL request log
+type Reader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewReader creates a reader over a JSONL request log
+func NewReader(r io.Reader) *Reader {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineBytes)
+	return &Reader{scanner: scanner}
+}
+
+// Next returns the next log entry, skipping blank lines.
+// It returns io.EOF after the last entry.
+func (r *Reader) Next() (LogEntry, error) {
+	for r.scanner.Scan() {
+		r.line++
+		data := r.scanner.Bytes()
+		if len(data) == 0 {
+			continue
+		}
+		var entry LogEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return LogEntry{}, fmt.Errorf("invalid log line %d: %w", r.line, err)
+		}
+		return entry, nil
+	}
+	if err := r.scanner.Err(); err != nil {
+		return LogEntry{}, err
+	}
+	return LogEntry{}, io.EOF
+}
+
+// Line returns the 1-based line number of the last entry read
+func (r *Reader) Line() int {
+	return r.line
+}
+
+// FindEntry returns the last entry of the JSONL log at path with the
+// request ID. It reads the file backwards, so recent decisions are found
+// without decoding the whole log, and skips lines that do not decode,
+// such as a torn trailing write.
+func FindEntry(path, requestID string) (LogEntry, bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return LogEntry{}, false, err
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return LogEntry{}, false, err
+	}
+
+	needle, _ := json.Marshal(requestID)
+	buf := make([]byte, findChunkBytes)
+	var tail []byte // Start of the line the previous chunk began within
+	for end := info.Size(); end > 0; {
+		n := min(end, findChunkBytes)
+		end -= n
+		if _, err := f.ReadAt(buf[:n], end); err != nil {
+			return LogEntry{}, false, err
+		}
+		data := append(buf[:n:n], tail...)
+
+		// Unless this is the start of the file, the text before the
+		// first newline continues in the next chunk read
+		start := 0
+		if end > 0 {
+			i := bytes.IndexByte(data, '\n')
+			if i < 0 {
+				tail = nil
+				if len(data) <= maxLineBytes {
+					tail = bytes.Clone(data)
+				}
+				continue
+			}
+			start = i + 1
+			tail = bytes.Clone(data[:i])
+		}
+
+		for lines := data[start:]; len(lines) > 0; {
+			i := bytes.LastIndexByte(lines, '\n')
+			line := lines[i+1:]
+			lines = lines[:max(i, 0)]
+			if !bytes.Contains(line, needle) {
+				continue
+			}
+			var entry LogEntry
+			if json.Unmarshal(line, &entry) == nil && entry.RequestID == requestID {
+				return entry, true, nil
+			}
+		}
+	}
+	return LogEntry{}, false, nil
+}
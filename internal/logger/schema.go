@@ -0,0 +1,52 @@
+package logger
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+
+	"github.com/muliwe/go-client-classifier/api"
+)
+
+// entrySchema compiles the published log entry schema once
+var entrySchema = sync.OnceValues(func() (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(api.LogEntrySchema))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse log entry schema: %w", err)
+	}
+	c := jsonschema.NewCompiler()
+	c.AssertFormat()
+	if err := c.AddResource(api.LogEntrySchemaID, doc); err != nil {
+		return nil, fmt.Errorf("failed to load log entry schema: %w", err)
+	}
+	return c.Compile(api.LogEntrySchemaID)
+})
+
+// ValidateJSON checks a single encoded log line against the published
+// log entry schema (api/schema/log-entry.schema.json)
+func ValidateJSON(data []byte) error {
+	sch, err := entrySchema()
+	if err != nil {
+		return err
+	}
+	inst, err := jsonschema.UnmarshalJSON(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("invalid log entry JSON: %w", err)
+	}
+	if err := sch.Validate(inst); err != nil {
+		return fmt.Errorf("log entry does not match schema: %w", err)
+	}
+	return nil
+}
+
+// ValidateEntry checks a log entry against the published log entry schema
+func ValidateEntry(entry LogEntry) error {
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("failed to encode log entry: %w", err)
+	}
+	return ValidateJSON(data)
+}
@@ -0,0 +1,342 @@
+// Package logq queries JSONL request logs by time range, classification,
+// client IP, TLS fingerprint and User-Agent, and aggregates the matches.
+package logq
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/netip"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// Fields entries can be grouped by
+const (
+	ByClass   = "class"
+	ByCrawler = "crawler"
+	ByIP      = "ip"
+	ByJA3     = "ja3"
+	ByJA4     = "ja4"
+	ByUA      = "ua"
+	ByBrowser = "browser"
+	ByOS      = "os"
+	ByHour    = "hour"
+)
+
+// GroupFields lists the valid Query.GroupBy values
+var GroupFields = []string{ByClass, ByCrawler, ByIP, ByJA3, ByJA4, ByUA, ByBrowser, ByOS, ByHour}
+
+// NoValue is the group key of entries without a value for the field
+const NoValue = "(none)"
+
+// Filter selects log entries. Zero fields match every entry.
+type Filter struct {
+	Since     time.Time // Inclusive
+	Until     time.Time // Exclusive
+	Class     string    // "browser" or "bot"
+	IP        string    // Client address or CIDR prefix
+	JA3       string    // JA3 hash
+	JA4       string    // JA4 hash or prefix, e.g. "t13d1516h2"
+	UserAgent string    // Case-insensitive User-Agent substring
+}
+
+// Query is a filter with an optional aggregation
+type Query struct {
+	Filter  Filter
+	GroupBy string // One of GroupFields, empty to list entries
+	Limit   int    // Most recent entries or largest groups returned (0 for all)
+}
+
+// Group counts the matching entries sharing a value of the GroupBy field
+type Group struct {
+	Key       string    `json:"key"`
+	Count     int       `json:"count"`
+	Browser   int       `json:"browser"`
+	Bot       int       `json:"bot"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+// Result holds the entries or groups matching a query
+type Result struct {
+	Scanned     int               `json:"scanned"`
+	Matched     int               `json:"matched"`
+	GroupBy     string            `json:"group_by,omitempty"`
+	TotalGroups int               `json:"total_groups,omitempty"` // Groups before Limit
+	Groups      []Group           `json:"groups,omitempty"`
+	Entries     []logger.LogEntry `json:"entries,omitempty"`
+}
+
+// Runner applies a query to one or more logs
+type Runner struct {
+	q      Query
+	prefix netip.Prefix // Parsed Filter.IP
+	ua     string       // Lowercase Filter.UserAgent
+	key    func(logger.LogEntry) string
+	rules  fingerprint.Rules
+
+	scanned int
+	matched int
+	entries []logger.LogEntry
+	groups  map[string]*Group
+}
+
+// New validates a query and returns a runner for it
+func New(q Query) (*Runner, error) {
+	f := q.Filter
+	if f.Class != "" && f.Class != classifier.ClassificationBrowser && f.Class != classifier.ClassificationBot {
+		return nil, fmt.Errorf("class must be %q or %q, got %q", classifier.ClassificationBrowser, classifier.ClassificationBot, f.Class)
+	}
+	if !f.Since.IsZero() && !f.Until.IsZero() && !f.Until.After(f.Since) {
+		return nil, errors.New("until must be after since")
+	}
+
+	r := &Runner{q: q, ua: strings.ToLower(f.UserAgent), rules: fingerprint.DefaultRules(), groups: map[string]*Group{}}
+	if f.IP != "" {
+		p, err := parsePrefix(f.IP)
+		if err != nil {
+			return nil, err
+		}
+		r.prefix = p
+	}
+	if q.GroupBy != "" {
+		r.key = r.groupKey(q.GroupBy)
+		if r.key == nil {
+			return nil, fmt.Errorf("unknown group field %q, want one of %s", q.GroupBy, strings.Join(GroupFields, ", "))
+		}
+	}
+	return r, nil
+}
+
+// Run applies a query to a single log
+func Run(log io.Reader, q Query) (Result, error) {
+	r, err := New(q)
+	if err != nil {
+		return Result{}, err
+	}
+	if err := r.AddLog(log); err != nil {
+		return Result{}, err
+	}
+	return r.Result(), nil
+}
+
+// AddLog applies the query to every entry of a JSONL request log
+func (r *Runner) AddLog(log io.Reader) error {
+	lr := logger.NewReader(log)
+	for {
+		e, err := lr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		r.Add(e)
+	}
+}
+
+// Add applies the query to one entry
+func (r *Runner) Add(e logger.LogEntry) {
+	r.scanned++
+	if !r.match(e) {
+		return
+	}
+	r.matched++
+
+	if r.key == nil {
+		r.entries = append(r.entries, e)
+		// Keep the most recent Limit entries without growing unbounded
+		if limit := r.q.Limit; limit > 0 && len(r.entries) >= 2*limit {
+			r.entries = append(r.entries[:0], r.entries[len(r.entries)-limit:]...)
+		}
+		return
+	}
+
+	k := r.key(e)
+	g := r.groups[k]
+	if g == nil {
+		g = &Group{Key: k, FirstSeen: e.Timestamp, LastSeen: e.Timestamp}
+		r.groups[k] = g
+	}
+	g.Count++
+	switch e.Classification {
+	case classifier.ClassificationBrowser:
+		g.Browser++
+	case classifier.ClassificationBot:
+		g.Bot++
+	}
+	if e.Timestamp.Before(g.FirstSeen) {
+		g.FirstSeen = e.Timestamp
+	}
+	if e.Timestamp.After(g.LastSeen) {
+		g.LastSeen = e.Timestamp
+	}
+}
+
+// Result returns the matches so far: the most recent entries in log
+// order, or the groups by descending count
+func (r *Runner) Result() Result {
+	res := Result{Scanned: r.scanned, Matched: r.matched, GroupBy: r.q.GroupBy}
+	limit := r.q.Limit
+
+	if r.key == nil {
+		entries := r.entries
+		if limit > 0 && len(entries) > limit {
+			entries = entries[len(entries)-limit:]
+		}
+		res.Entries = append([]logger.LogEntry(nil), entries...)
+		return res
+	}
+
+	for _, g := range r.groups {
+		res.Groups = append(res.Groups, *g)
+	}
+	sort.Slice(res.Groups, func(i, j int) bool {
+		if res.Groups[i].Count != res.Groups[j].Count {
+			return res.Groups[i].Count > res.Groups[j].Count
+		}
+		return res.Groups[i].Key < res.Groups[j].Key
+	})
+	res.TotalGroups = len(res.Groups)
+	if limit > 0 && len(res.Groups) > limit {
+		res.Groups = res.Groups[:limit]
+	}
+	return res
+}
+
+// match reports whether an entry passes the filter
+func (r *Runner) match(e logger.LogEntry) bool {
+	f := r.q.Filter
+	if !f.Since.IsZero() && e.Timestamp.Before(f.Since) {
+		return false
+	}
+	if !f.Until.IsZero() && !e.Timestamp.Before(f.Until) {
+		return false
+	}
+	if f.Class != "" && e.Classification != f.Class {
+		return false
+	}
+	if r.prefix.IsValid() {
+		addr, err := netip.ParseAddr(remoteIP(e.RemoteAddr))
+		if err != nil || !r.prefix.Contains(addr.Unmap()) {
+			return false
+		}
+	}
+	if f.JA3 != "" && !strings.EqualFold(e.Fingerprint.TLS.JA3Hash, f.JA3) {
+		return false
+	}
+	if f.JA4 != "" && !strings.HasPrefix(e.Fingerprint.TLS.JA4Hash, f.JA4) {
+		return false
+	}
+	if r.ua != "" && !strings.Contains(strings.ToLower(e.Fingerprint.HTTP.UserAgent), r.ua) {
+		return false
+	}
+	return true
+}
+
+// groupKey returns the key function of a group field, or nil if unknown
+func (r *Runner) groupKey(field string) func(logger.LogEntry) string {
+	switch field {
+	case ByClass:
+		return func(e logger.LogEntry) string { return orNone(e.Classification) }
+	case ByCrawler:
+		return func(e logger.LogEntry) string { return orNone(r.crawler(e.Fingerprint.HTTP.UserAgent)) }
+	case ByIP:
+		return func(e logger.LogEntry) string { return orNone(remoteIP(e.RemoteAddr)) }
+	case ByJA3:
+		return func(e logger.LogEntry) string { return orNone(e.Fingerprint.TLS.JA3Hash) }
+	case ByJA4:
+		return func(e logger.LogEntry) string { return orNone(e.Fingerprint.TLS.JA4Hash) }
+	case ByUA:
+		return func(e logger.LogEntry) string { return orNone(e.Fingerprint.HTTP.UserAgent) }
+	case ByBrowser:
+		return func(e logger.LogEntry) string { return orNone(browserKey(e.Fingerprint.HTTP.UserAgent)) }
+	case ByOS:
+		return func(e logger.LogEntry) string {
+			return orNone(fingerprint.ParseUserAgent(e.Fingerprint.HTTP.UserAgent).OS)
+		}
+	case ByHour:
+		return func(e logger.LogEntry) string { return e.Timestamp.UTC().Truncate(time.Hour).Format(time.RFC3339) }
+	}
+	return nil
+}
+
+// browserKey names the client family and major version of a User-Agent,
+// e.g. "Chrome 131"
+func browserKey(ua string) string {
+	p := fingerprint.ParseUserAgent(ua)
+	if p.Major == 0 {
+		return p.Family
+	}
+	return p.Family + " " + strconv.Itoa(p.Major)
+}
+
+// crawler names the client of a User-Agent by the first AI crawler or bot
+// pattern it contains, or returns "" for other clients
+func (r *Runner) crawler(ua string) string {
+	ua = strings.ToLower(ua)
+	for _, patterns := range [][]string{r.rules.AICrawlerPatterns, r.rules.BotPatterns} {
+		for _, p := range patterns {
+			if strings.Contains(ua, p) {
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// ParseTime parses an RFC 3339 timestamp, a date (2006-01-02, UTC) or a
+// duration before now such as "90m" or "24h"
+func ParseTime(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, s); err == nil {
+		return t, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q, want RFC 3339, YYYY-MM-DD or a duration like 24h", s)
+}
+
+// parsePrefix parses an address or CIDR prefix
+func parsePrefix(s string) (netip.Prefix, error) {
+	if strings.Contains(s, "/") {
+		p, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("invalid IP prefix %q: %w", s, err)
+		}
+		return p.Masked(), nil
+	}
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("invalid IP %q: %w", s, err)
+	}
+	addr = addr.Unmap()
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// remoteIP strips the port from a logged remote address
+func remoteIP(remoteAddr string) string {
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		return host
+	}
+	return remoteAddr
+}
+
+// orNone returns NoValue for empty keys
+func orNone(s string) string {
+	if s == "" {
+		return NoValue
+	}
+	return s
+}
@@ -0,0 +1,13 @@
+package logq
+
+import "testing"
+
+// Tests are in tests/unit/logq_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestLogqPackage(t *testing.T) {
+	// Verify package is testable
+	if len(GroupFields) == 0 {
+		t.Error("GroupFields should not be empty")
+	}
+}
@@ -0,0 +1,221 @@
+// Package membudget sizes in-memory stores (session timing, verified
+// CAPTCHA sessions, ...) to a memory budget, so enabling stateful features
+// cannot run a small container out of memory.
+//
+// The budget is an explicit byte count or a fraction of the soft memory
+// limit (GOMEMLIMIT), split evenly between registered stores and turned
+// into an entry capacity with each store's per-entry estimate. When the Go
+// runtime's memory use nears the limit, stores shrink to half their size
+// until the pressure is gone.
+package membudget
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"runtime/debug"
+	"runtime/metrics"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// minCapacity is the smallest capacity a store is given or shrunk to
+const minCapacity = 100
+
+// Store is an in-memory store whose size can be bounded
+type Store interface {
+	Len() int          // Current number of entries
+	EntryBytes() int   // Estimated memory per entry, in bytes
+	SetCapacity(n int) // Bound the store to n entries, evicting the excess
+}
+
+// Config holds memory budget configuration
+type Config struct {
+	// Bytes is the memory shared by all stores. When 0, Fraction of
+	// GOMEMLIMIT is used.
+	Bytes int64
+	// Fraction of GOMEMLIMIT given to stores when Bytes is 0 (default 0.25)
+	Fraction float64
+	// Pressure is the share of GOMEMLIMIT in use above which stores
+	// shrink (default 0.9)
+	Pressure float64
+	// Interval between memory checks in Run (default 10s)
+	Interval time.Duration
+}
+
+// Budget distributes a memory budget between stores
+type Budget struct {
+	bytes    int64
+	memLimit int64 // GOMEMLIMIT (math.MaxInt64 when unset)
+	pressure float64
+	interval time.Duration
+
+	mu     sync.Mutex
+	stores []*entry
+	shrunk bool
+}
+
+// entry is a registered store and its nominal capacity
+type entry struct {
+	name     string
+	store    Store
+	capacity int
+}
+
+// StoreState reports a registered store's size and capacity
+type StoreState struct {
+	Name     string `json:"name"`
+	Len      int    `json:"len"`
+	Capacity int    `json:"capacity"`
+}
+
+// New creates a budget. It fails when neither Bytes nor GOMEMLIMIT is set.
+func New(cfg Config) (*Budget, error) {
+	if cfg.Bytes < 0 || cfg.Fraction < 0 || cfg.Fraction > 1 || cfg.Pressure < 0 || cfg.Pressure > 1 {
+		return nil, errors.New("memory budget bytes must not be negative, fraction and pressure must be between 0 and 1")
+	}
+	if cfg.Fraction == 0 {
+		cfg.Fraction = 0.25
+	}
+	if cfg.Pressure == 0 {
+		cfg.Pressure = 0.9
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = 10 * time.Second
+	}
+
+	memLimit := debug.SetMemoryLimit(-1)
+	bytes := cfg.Bytes
+	if bytes == 0 {
+		if memLimit == math.MaxInt64 {
+			return nil, errors.New("no memory budget: set GOMEMLIMIT or an explicit budget")
+		}
+		bytes = int64(float64(memLimit) * cfg.Fraction)
+	}
+	return &Budget{bytes: bytes, memLimit: memLimit, pressure: cfg.Pressure, interval: cfg.Interval}, nil
+}
+
+// Bytes returns the memory shared by all stores
+func (b *Budget) Bytes() int64 {
+	return b.bytes
+}
+
+// Register bounds s by an even share of the budget, resizing the stores
+// registered before it
+func (b *Budget) Register(name string, s Store) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stores = append(b.stores, &entry{name: name, store: s})
+	share := b.bytes / int64(len(b.stores))
+	for _, e := range b.stores {
+		e.capacity = max(minCapacity, int(share/int64(max(1, e.store.EntryBytes()))))
+		if !b.shrunk {
+			e.store.SetCapacity(e.capacity)
+		}
+	}
+}
+
+// Check applies memory pressure given the bytes in use: above the
+// pressure threshold of GOMEMLIMIT stores shrink to half their current
+// size; once use falls below 80% of the threshold they get their nominal
+// capacity back. It reports whether the stores are shrunk.
+func (b *Budget) Check(inUse int64) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	threshold := float64(b.memLimit) * b.pressure
+	switch {
+	case float64(inUse) > threshold:
+		for _, e := range b.stores {
+			e.store.SetCapacity(max(minCapacity, min(e.capacity, e.store.Len()/2)))
+		}
+		b.shrunk = true
+	case b.shrunk && float64(inUse) < threshold*0.8:
+		for _, e := range b.stores {
+			e.store.SetCapacity(e.capacity)
+		}
+		b.shrunk = false
+	}
+	return b.shrunk
+}
+
+// Run checks memory use every interval until ctx is done. Without
+// GOMEMLIMIT there is no limit to be under pressure from, and Run returns.
+func (b *Budget) Run(ctx context.Context) {
+	if b.memLimit == math.MaxInt64 {
+		return
+	}
+	ticker := time.NewTicker(b.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			b.Check(InUse())
+		}
+	}
+}
+
+// States returns the registered stores in registration order
+func (b *Budget) States() []StoreState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	states := make([]StoreState, 0, len(b.stores))
+	for _, e := range b.stores {
+		states = append(states, StoreState{Name: e.name, Len: e.store.Len(), Capacity: e.capacity})
+	}
+	return states
+}
+
+// byteUnits are the size suffixes accepted by ParseBytes, in the same
+// notation as GOMEMLIMIT
+var byteUnits = []struct {
+	suffix string
+	scale  int64
+}{
+	{"KiB", 1 << 10},
+	{"MiB", 1 << 20},
+	{"GiB", 1 << 30},
+	{"TiB", 1 << 40},
+	{"B", 1},
+}
+
+// ParseBytes parses a size such as 64MiB or 1048576, in the notation of
+// GOMEMLIMIT
+func ParseBytes(s string) (int64, error) {
+	digits, scale := s, int64(1)
+	for _, u := range byteUnits {
+		if rest, ok := strings.CutSuffix(s, u.suffix); ok {
+			digits, scale = rest, u.scale
+			break
+		}
+	}
+	n, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/scale {
+		return 0, fmt.Errorf("invalid size %q (use bytes or a KiB, MiB, GiB or TiB suffix)", s)
+	}
+	return n * scale, nil
+}
+
+// InUse returns the memory the Go runtime holds against GOMEMLIMIT: all
+// mapped memory minus heap memory released to the OS
+func InUse() int64 {
+	samples := []metrics.Sample{
+		{Name: "/memory/classes/total:bytes"},
+		{Name: "/memory/classes/heap/released:bytes"},
+	}
+	metrics.Read(samples)
+	var v [2]uint64
+	for i, s := range samples {
+		if s.Value.Kind() == metrics.KindUint64 {
+			v[i] = s.Value.Uint64()
+		}
+	}
+	return int64(v[0] - v[1])
+}
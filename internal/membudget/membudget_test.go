@@ -0,0 +1,13 @@
+package membudget
+
+import "testing"
+
+// Tests are in tests/unit/membudget_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestMembudgetPackage(t *testing.T) {
+	// Verify package is testable
+	if InUse() <= 0 {
+		t.Error("InUse should report memory in use")
+	}
+}
@@ -0,0 +1,192 @@
+// Package model implements the machine-learning scoring backend: a
+// logistic regression over the boolean Signals, loaded from a JSON model
+// file written by cmd/train.
+//
+//	{
+//	  "version": "2026-10-15",
+//	  "intercept": -0.4,
+//	  "threshold": 0.5,
+//	  "weights": {"has_sec_fetch_headers": 2.1, "ua_is_bot": -3.7}
+//	}
+//
+// Weights are keyed by the JSON name of the signal; positive weights point
+// towards browser. Signals without a weight count for nothing.
+package model
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// DefaultThreshold is the browser probability cutoff of models without one
+const DefaultThreshold = 0.5
+
+// Model is a logistic regression predicting the probability that a
+// request comes from a browser
+type Model struct {
+	Version   string             `json:"version"`
+	Intercept float64            `json:"intercept"`
+	Threshold float64            `json:"threshold,omitempty"` // Browser when P(browser) >= Threshold (0 = DefaultThreshold)
+	Weights   map[string]float64 `json:"weights"`
+
+	weights []float64 // Weights in features order
+}
+
+// Contribution is the part of a prediction's log-odds due to one signal
+type Contribution struct {
+	Signal string
+	Value  float64 // Positive towards browser, negative towards bot
+}
+
+// feature is a boolean Signals field a model can weigh
+type feature struct {
+	name  string
+	index int
+}
+
+// features lists the boolean Signals fields, in declaration order
+var features = func() []feature {
+	t := reflect.TypeFor[fingerprint.Signals]()
+	var fs []feature
+	for i := range t.NumField() {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if f.Type.Kind() != reflect.Bool || name == "" || name == "-" {
+			continue
+		}
+		fs = append(fs, feature{name: name, index: i})
+	}
+	return fs
+}()
+
+// Features returns the JSON names of the signals a model can weigh
+func Features() []string {
+	names := make([]string, len(features))
+	for i, f := range features {
+		names[i] = f.name
+	}
+	return names
+}
+
+// Load reads a model file
+func Load(path string) (*Model, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return m, nil
+}
+
+// Parse decodes and validates a JSON model
+func Parse(data []byte) (*Model, error) {
+	var m Model
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	if err := m.compile(); err != nil {
+		return nil, err
+	}
+	return &m, nil
+}
+
+// compile checks the model and orders its weights by feature
+func (m *Model) compile() error {
+	if math.IsNaN(m.Intercept) || math.IsInf(m.Intercept, 0) {
+		return errors.New("intercept is not a finite number")
+	}
+	if m.Threshold == 0 {
+		m.Threshold = DefaultThreshold
+	}
+	if m.Threshold <= 0 || m.Threshold >= 1 {
+		return fmt.Errorf("threshold %v is not between 0 and 1", m.Threshold)
+	}
+	known := Features()
+	for name, w := range m.Weights {
+		if !slices.Contains(known, name) {
+			return fmt.Errorf("unknown signal %q", name)
+		}
+		if math.IsNaN(w) || math.IsInf(w, 0) {
+			return fmt.Errorf("weight of %s is not a finite number", name)
+		}
+	}
+	m.weights = make([]float64, len(features))
+	for i, f := range features {
+		m.weights[i] = m.Weights[f.name]
+	}
+	return nil
+}
+
+// Predict returns the probability that the signals come from a browser
+func (m *Model) Predict(s fingerprint.Signals) float64 {
+	return sigmoid(m.logit(vector(s)))
+}
+
+// Contributions returns the signals that moved the prediction, strongest
+// first
+func (m *Model) Contributions(s fingerprint.Signals) []Contribution {
+	x := vector(s)
+	var cs []Contribution
+	for i, f := range features {
+		if v := m.weights[i] * x[i]; v != 0 {
+			cs = append(cs, Contribution{Signal: f.name, Value: v})
+		}
+	}
+	slices.SortStableFunc(cs, func(a, b Contribution) int {
+		return cmpAbs(b.Value, a.Value)
+	})
+	return cs
+}
+
+// LogitThreshold returns the threshold as log-odds
+func (m *Model) LogitThreshold() float64 {
+	return math.Log(m.Threshold / (1 - m.Threshold))
+}
+
+// logit returns the log-odds of a browser for a feature vector
+func (m *Model) logit(x []float64) float64 {
+	z := m.Intercept
+	for i, v := range x {
+		z += m.weights[i] * v
+	}
+	return z
+}
+
+// vector returns the signals as 0/1 features
+func vector(s fingerprint.Signals) []float64 {
+	v := reflect.ValueOf(s)
+	x := make([]float64, len(features))
+	for i, f := range features {
+		if v.Field(f.index).Bool() {
+			x[i] = 1
+		}
+	}
+	return x
+}
+
+// sigmoid maps log-odds to a probability
+func sigmoid(z float64) float64 {
+	return 1 / (1 + math.Exp(-z))
+}
+
+// cmpAbs compares a and b by magnitude
+func cmpAbs(a, b float64) int {
+	switch a, b = math.Abs(a), math.Abs(b); {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,13 @@
+package model
+
+import "testing"
+
+// Tests are in tests/unit/model_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestModelPackage(t *testing.T) {
+	// Verify package is testable
+	if len(Features()) == 0 {
+		t.Error("Features() should not be empty")
+	}
+}
@@ -0,0 +1,77 @@
+package model
+
+import (
+	"errors"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Example is a labeled training example
+type Example struct {
+	Signals fingerprint.Signals
+	Browser bool
+}
+
+// TrainOptions tunes gradient descent
+type TrainOptions struct {
+	Version      string  // Version recorded in the model
+	Epochs       int     // Passes over the examples (default 500)
+	LearningRate float64 // Step size (default 0.5)
+	L2           float64 // L2 regularization strength (default 0.001)
+}
+
+// Train fits a model to the examples by batch gradient descent on the
+// regularized log loss
+func Train(examples []Example, opts TrainOptions) (*Model, error) {
+	if len(examples) == 0 {
+		return nil, errors.New("no training examples")
+	}
+	if opts.Epochs <= 0 {
+		opts.Epochs = 500
+	}
+	if opts.LearningRate <= 0 {
+		opts.LearningRate = 0.5
+	}
+	if opts.L2 < 0 {
+		return nil, errors.New("L2 regularization must not be negative")
+	}
+	if opts.L2 == 0 {
+		opts.L2 = 0.001
+	}
+
+	xs := make([][]float64, len(examples))
+	ys := make([]float64, len(examples))
+	for i, e := range examples {
+		xs[i] = vector(e.Signals)
+		if e.Browser {
+			ys[i] = 1
+		}
+	}
+
+	m := &Model{Version: opts.Version, Threshold: DefaultThreshold, weights: make([]float64, len(features))}
+	n := float64(len(examples))
+	grad := make([]float64, len(features))
+	for range opts.Epochs {
+		clear(grad)
+		var gradIntercept float64
+		for i, x := range xs {
+			err := sigmoid(m.logit(x)) - ys[i]
+			gradIntercept += err
+			for j, v := range x {
+				grad[j] += err * v
+			}
+		}
+		m.Intercept -= opts.LearningRate * gradIntercept / n
+		for j := range m.weights {
+			m.weights[j] -= opts.LearningRate * (grad[j]/n + opts.L2*m.weights[j])
+		}
+	}
+
+	m.Weights = make(map[string]float64)
+	for i, f := range features {
+		if m.weights[i] != 0 {
+			m.Weights[f.name] = m.weights[i]
+		}
+	}
+	return m, nil
+}
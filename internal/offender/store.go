@@ -0,0 +1,243 @@
+// Package offender remembers clients caught misbehaving, by a honeypot
+// trap or by bot verdicts, by their address and JA4 fingerprint, so that
+// their later requests are classified with that history. Bot verdicts add
+// up to a reputation score that decays exponentially, so a scraper caught
+// repeatedly is recognized on its next visit while a one-off verdict
+// fades.
+package offender
+
+import (
+	"math"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Config holds repeat-offender store configuration
+type Config struct {
+	TTL        time.Duration // How long an offense is remembered (default 24h)
+	MaxEntries int           // Maximum number of addresses and fingerprints remembered (oldest evicted first)
+	HalfLife   time.Duration // Time for the weight of a bot verdict to halve (default 6h)
+	Threshold  float64       // Reputation from which a client is a repeat offender (default 3)
+}
+
+// DefaultConfig returns default repeat-offender store configuration
+func DefaultConfig() Config {
+	return Config{
+		TTL:        24 * time.Hour,
+		MaxEntries: 50000,
+		HalfLife:   6 * time.Hour,
+		Threshold:  3,
+	}
+}
+
+// Store records offenses per client address and per JA4. Addresses are
+// identified by the same keys as rate.Tracker (subnet keys, anonymized
+// when anonymization is enabled).
+type Store struct {
+	mu      sync.Mutex
+	cfg     Config
+	entries map[string]offense // "addr|" or "ja4|" + key -> offenses
+}
+
+// offense is the last honeypot trap a client requested and its decayed
+// bot verdicts
+type offense struct {
+	trap      string
+	trappedAt time.Time
+	score     float64   // Bot verdicts, decayed to at
+	at        time.Time // Last offense of either kind
+}
+
+// New creates an empty repeat-offender store
+func New(cfg Config) *Store {
+	if cfg.TTL <= 0 {
+		cfg.TTL = DefaultConfig().TTL
+	}
+	if cfg.MaxEntries <= 0 {
+		cfg.MaxEntries = DefaultConfig().MaxEntries
+	}
+	if cfg.HalfLife <= 0 {
+		cfg.HalfLife = DefaultConfig().HalfLife
+	}
+	if cfg.Threshold <= 0 {
+		cfg.Threshold = DefaultConfig().Threshold
+	}
+	return &Store{cfg: cfg, entries: make(map[string]offense)}
+}
+
+// MarkTrapped records that the client at addr, with the JA4 fingerprint
+// ja4, requested the honeypot trap at now. An empty addr or ja4 is not
+// recorded: callers leave out keys shared by innocent clients.
+func (s *Store) MarkTrapped(addr, ja4, trap string, now time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys(addr, ja4) {
+		o := s.current(key, now)
+		o.trap, o.trappedAt, o.at = trap, now, now
+		s.put(key, o)
+	}
+}
+
+// RecordBot adds a bot verdict for the client at addr with the JA4
+// fingerprint ja4 at now, and reports whether it lifted the reputation of
+// either to the threshold. Empty keys are not recorded, as for
+// MarkTrapped.
+func (s *Store) RecordBot(addr, ja4 string, now time.Time) (crossed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range keys(addr, ja4) {
+		o := s.current(key, now)
+		before := round(o.score)
+		o.score++
+		o.at = now
+		s.put(key, o)
+		if before < s.cfg.Threshold && round(o.score) >= s.cfg.Threshold {
+			crossed = true
+		}
+	}
+	return crossed
+}
+
+// Lookup returns the offenses on record for a client address or JA4
+// fingerprint within the TTL, with the higher of their reputations
+func (s *Store) Lookup(addr, ja4 string, now time.Time) fingerprint.OffenderFingerprint {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var f fingerprint.OffenderFingerprint
+	for _, key := range keys(addr, ja4) {
+		o, ok := s.entries[key]
+		if !ok {
+			continue
+		}
+		if now.Sub(o.at) > s.cfg.TTL {
+			delete(s.entries, key)
+			continue
+		}
+		if !f.Trapped && o.trap != "" && now.Sub(o.trappedAt) <= s.cfg.TTL {
+			f.Trapped = true
+			f.TrapPath = o.trap
+		}
+		f.Reputation = max(f.Reputation, s.decay(o, now))
+	}
+	f.Reputation = round(f.Reputation)
+	f.RepeatOffender = f.Reputation >= s.cfg.Threshold
+	return f
+}
+
+// current returns the offenses of a key with the reputation decayed to
+// now, or none when it is unknown or expired. Caller must hold the lock.
+func (s *Store) current(key string, now time.Time) offense {
+	o, ok := s.entries[key]
+	if !ok || now.Sub(o.at) > s.cfg.TTL {
+		return offense{}
+	}
+	o.score = s.decay(o, now)
+	return o
+}
+
+// decay returns the reputation of an offense at now: each half-life since
+// the last verdict halves it
+func (s *Store) decay(o offense, now time.Time) float64 {
+	if o.score == 0 || !now.After(o.at) {
+		return o.score
+	}
+	return o.score * math.Exp2(-float64(now.Sub(o.at))/float64(s.cfg.HalfLife))
+}
+
+// round rounds a reputation to the two decimals it is reported and
+// compared with the threshold in, so that verdicts seconds apart count in
+// full
+func round(score float64) float64 {
+	return math.Round(score*100) / 100
+}
+
+// keys returns the entry keys of a client address and JA4, leaving out
+// empty ones
+func keys(addr, ja4 string) []string {
+	var k []string
+	if addr != "" {
+		k = append(k, "addr|"+addr)
+	}
+	if ja4 != "" {
+		k = append(k, "ja4|"+ja4)
+	}
+	return k
+}
+
+// Len returns the number of addresses and fingerprints on record,
+// including expired ones not yet evicted
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.entries)
+}
+
+// EntryBytes estimates the memory held per address or fingerprint: its
+// key, trap path, timestamps, reputation and map overhead
+func (s *Store) EntryBytes() int {
+	return 256
+}
+
+// SetCapacity changes the maximum number of entries, evicting the oldest
+// offenses beyond it
+func (s *Store) SetCapacity(n int) {
+	if n <= 0 {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.cfg.MaxEntries = n
+	excess := len(s.entries) - n
+	if excess <= 0 {
+		return
+	}
+	keys := make([]string, 0, len(s.entries))
+	for k := range s.entries {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return s.entries[a].at.Compare(s.entries[b].at)
+	})
+	for _, k := range keys[:excess] {
+		delete(s.entries, k)
+	}
+}
+
+// put stores an offense, evicting to make room. Caller must hold the lock.
+func (s *Store) put(key string, o offense) {
+	if _, exists := s.entries[key]; !exists && len(s.entries) >= s.cfg.MaxEntries {
+		s.evict(o.at)
+	}
+	s.entries[key] = o
+}
+
+// evict removes expired offenses, or the oldest offense if none have
+// expired. Caller must hold the lock.
+func (s *Store) evict(now time.Time) {
+	var oldestKey string
+	var oldest time.Time
+	removed := false
+
+	for k, o := range s.entries {
+		if now.Sub(o.at) > s.cfg.TTL {
+			delete(s.entries, k)
+			removed = true
+			continue
+		}
+		if oldestKey == "" || o.at.Before(oldest) {
+			oldestKey = k
+			oldest = o.at
+		}
+	}
+
+	if !removed && oldestKey != "" {
+		delete(s.entries, oldestKey)
+	}
+}
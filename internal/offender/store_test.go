@@ -0,0 +1,14 @@
+package offender
+
+import "testing"
+
+// Tests are in tests/unit/honeypot_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestOffenderPackage(t *testing.T) {
+	// Verify package is testable
+	s := New(DefaultConfig())
+	if s == nil {
+		t.Error("New should not return nil")
+	}
+}
@@ -0,0 +1,133 @@
+package pcap
+
+import (
+	"encoding/binary"
+	"net/netip"
+	"time"
+)
+
+// TCP flags
+const (
+	flagSYN = 0x02
+	flagACK = 0x10
+)
+
+// segment is a decoded TCP segment
+type segment struct {
+	ts      time.Time
+	src     netip.AddrPort
+	dst     netip.AddrPort
+	seq     uint32
+	flags   uint8
+	payload []byte
+	syn     []byte // IP and TCP headers of a client SYN, for JA4T
+}
+
+// decodeTCP extracts the TCP segment carried by a captured frame.
+// Non-IP, non-TCP and fragmented packets are reported as not ok.
+func decodeTCP(pkt Packet) (segment, bool) {
+	ip, ok := linkPayload(pkt.LinkType, pkt.Data)
+	if !ok || len(ip) < 1 {
+		return segment{}, false
+	}
+
+	var src, dst netip.Addr
+	var tcp []byte
+	var ipHeader int
+	switch ip[0] >> 4 {
+	case 4:
+		if len(ip) < 20 {
+			return segment{}, false
+		}
+		ihl := int(ip[0]&0x0f) * 4
+		total := int(binary.BigEndian.Uint16(ip[2:4]))
+		fragment := binary.BigEndian.Uint16(ip[6:8]) & 0x3fff // MF flag or offset
+		if ip[9] != 6 || fragment != 0 || ihl < 20 || len(ip) < ihl {
+			return segment{}, false
+		}
+		if total >= ihl && total < len(ip) {
+			ip = ip[:total] // strip Ethernet padding
+		}
+		src = netip.AddrFrom4([4]byte(ip[12:16]))
+		dst = netip.AddrFrom4([4]byte(ip[16:20]))
+		tcp, ipHeader = ip[ihl:], ihl
+	case 6:
+		if len(ip) < 40 {
+			return segment{}, false
+		}
+		payloadLen := int(binary.BigEndian.Uint16(ip[4:6]))
+		// Extension headers are not followed; TCP must be the next header
+		if ip[6] != 6 {
+			return segment{}, false
+		}
+		src = netip.AddrFrom16([16]byte(ip[8:24]))
+		dst = netip.AddrFrom16([16]byte(ip[24:40]))
+		tcp, ipHeader = ip[40:], 40
+		if payloadLen < len(tcp) {
+			tcp = tcp[:payloadLen]
+		}
+	default:
+		return segment{}, false
+	}
+
+	if len(tcp) < 20 {
+		return segment{}, false
+	}
+	offset := int(tcp[12]>>4) * 4
+	if offset < 20 || len(tcp) < offset {
+		return segment{}, false
+	}
+	var syn []byte
+	if tcp[13]&(flagSYN|flagACK) == flagSYN {
+		syn = ip[:ipHeader+offset]
+	}
+	return segment{
+		ts:      pkt.Timestamp,
+		src:     netip.AddrPortFrom(src, binary.BigEndian.Uint16(tcp[0:2])),
+		dst:     netip.AddrPortFrom(dst, binary.BigEndian.Uint16(tcp[2:4])),
+		seq:     binary.BigEndian.Uint32(tcp[4:8]),
+		flags:   tcp[13],
+		payload: tcp[offset:],
+		syn:     syn,
+	}, true
+}
+
+// linkPayload strips the link-layer header, returning the IP packet
+func linkPayload(linkType uint32, data []byte) ([]byte, bool) {
+	var etherType uint16
+	switch linkType {
+	case LinkTypeEthernet:
+		if len(data) < 14 {
+			return nil, false
+		}
+		etherType = binary.BigEndian.Uint16(data[12:14])
+		data = data[14:]
+		// 802.1Q / 802.1ad VLAN tags
+		for (etherType == 0x8100 || etherType == 0x88a8) && len(data) >= 4 {
+			etherType = binary.BigEndian.Uint16(data[2:4])
+			data = data[4:]
+		}
+	case LinkTypeLinuxSLL:
+		if len(data) < 16 {
+			return nil, false
+		}
+		etherType = binary.BigEndian.Uint16(data[14:16])
+		data = data[16:]
+	case LinkTypeNull:
+		// 4-byte address family in host byte order; the IP version nibble
+		// identifies the packet either way
+		if len(data) < 4 {
+			return nil, false
+		}
+		return data[4:], true
+	case LinkTypeRaw:
+		return data, true
+	default:
+		return nil, false
+	}
+
+	if etherType != 0x0800 && etherType != 0x86dd {
+		return nil, false
+	}
+	return data, true
+}
@@ -0,0 +1,282 @@
+package pcap
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/psanford/tlsfingerprint"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Record kinds
+const (
+	KindTLS  = "tls"  // TLS ClientHello
+	KindHTTP = "http" // Plaintext HTTP/1.x request
+)
+
+// Record is a fingerprint reconstructed from a capture. The embedded
+// Fingerprint is flattened into the JSON object, so a record line also
+// decodes as a plain Fingerprint.
+type Record struct {
+	fingerprint.Fingerprint
+	Timestamp time.Time `json:"timestamp"`
+	Kind      string    `json:"kind"`
+	Client    string    `json:"client"` // ip:port
+	Server    string    `json:"server"` // ip:port
+}
+
+// Stats counts what was seen while extracting
+type Stats struct {
+	Packets      int `json:"packets"`
+	TCPSegments  int `json:"tcp_segments"`
+	Streams      int `json:"streams"`
+	ClientHellos int `json:"client_hellos"`
+	HTTPRequests int `json:"http_requests"`
+	Malformed    int `json:"malformed"` // Streams that looked like TLS/HTTP but failed to parse
+}
+
+// Extract reads a capture and returns the fingerprints of every TLS
+// ClientHello and plaintext HTTP/1.x request, ordered by capture time
+func Extract(r io.Reader) ([]Record, Stats, error) {
+	var stats Stats
+	rd, err := NewReader(r)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	asm := newAssembler()
+	for {
+		pkt, err := rd.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, stats, err
+		}
+		stats.Packets++
+		seg, ok := decodeTCP(pkt)
+		if !ok {
+			continue
+		}
+		stats.TCPSegments++
+		asm.add(seg)
+	}
+
+	streams := asm.streams()
+	byKey := make(map[flowKey][]*stream, len(streams))
+	for _, s := range streams {
+		byKey[s.key] = append(byKey[s.key], s)
+	}
+
+	collector := fingerprint.NewCollector()
+	var records []Record
+	for _, s := range streams {
+		data := s.assemble()
+		if len(data.data) == 0 {
+			continue
+		}
+		stats.Streams++
+
+		switch {
+		case isClientHello(data.data):
+			rec, err := clientHelloRecord(s, data, reply(byKey, s))
+			if err != nil {
+				stats.Malformed++
+				continue
+			}
+			stats.ClientHellos++
+			records = append(records, rec)
+		case isHTTPRequest(data.data):
+			recs, err := httpRecords(collector, s, data)
+			stats.HTTPRequests += len(recs)
+			if err != nil {
+				stats.Malformed++
+			}
+			records = append(records, recs...)
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Timestamp.Before(records[j].Timestamp) })
+	return records, stats, nil
+}
+
+// isClientHello reports whether data starts with a TLS handshake record
+// carrying a ClientHello
+func isClientHello(data []byte) bool {
+	return len(data) >= 6 && data[0] == 0x16 && data[1] == 0x03 && data[5] == 0x01
+}
+
+// httpMethods are request methods recognized at the start of a stream
+var httpMethods = []string{"GET ", "POST ", "HEAD ", "PUT ", "DELETE ", "OPTIONS ", "PATCH ", "CONNECT ", "TRACE "}
+
+// isHTTPRequest reports whether data starts with an HTTP/1.x request line
+func isHTTPRequest(data []byte) bool {
+	for _, m := range httpMethods {
+		if bytes.HasPrefix(data, []byte(m)) {
+			return true
+		}
+	}
+	return false
+}
+
+// reply returns the stream of the server's side of the connection of s:
+// the first one in the opposite direction that started no earlier
+func reply(byKey map[flowKey][]*stream, s *stream) *stream {
+	for _, r := range byKey[flowKey{src: s.key.dst, dst: s.key.src}] {
+		if !r.first.Before(s.first) {
+			return r
+		}
+	}
+	return nil
+}
+
+// clientHelloRecord fingerprints the ClientHello at the start of a stream,
+// and the ServerHello answering it and the client's latency when the reply
+// stream was captured
+func clientHelloRecord(s *stream, data assembled, replyStream *stream) (Record, error) {
+	ch, err := tlsfingerprint.ParseClientHello(data.data)
+	if err != nil {
+		return Record{}, err
+	}
+	tlsFP := fingerprint.ClientHelloFingerprint(ch)
+	tlsFP.ServerName = serverName(data.data)
+	tcpFP := s.tcpFingerprint()
+	if replyStream != nil {
+		replyData := replyStream.assemble()
+		if sh, err := fingerprint.ParseServerHello(replyData.data); err == nil {
+			tlsFP.JA3SHash = fingerprint.JA3S(sh)
+			tlsFP.JA4SHash = fingerprint.JA4S(sh)
+			if latency := handshakeLatency(data, replyData); latency > 0 {
+				tlsFP.LatencyMicros = int(latency.Microseconds())
+				tlsFP.JA4L = fingerprint.JA4L(latency, tcpFP.TTL)
+			}
+		}
+	}
+
+	return Record{
+		Fingerprint: fingerprint.Fingerprint{TLS: tlsFP, TCP: tcpFP},
+		Timestamp:   data.timeAt(0),
+		Kind:        KindTLS,
+		Client:      s.key.src.String(),
+		Server:      s.key.dst.String(),
+	}, nil
+}
+
+// httpRecords fingerprints every request of a plaintext HTTP/1.x stream.
+// Requests parsed before an error are returned along with it.
+func httpRecords(collector *fingerprint.Collector, s *stream, data assembled) ([]Record, error) {
+	src := bytes.NewReader(data.data)
+	br := bufio.NewReader(src)
+	var records []Record
+	for {
+		offset := int(src.Size()) - src.Len() - br.Buffered()
+		req, err := http.ReadRequest(br)
+		if errors.Is(err, io.EOF) {
+			return records, nil
+		}
+		if err != nil {
+			return records, fmt.Errorf("request %d: %w", len(records)+1, err)
+		}
+		req.RemoteAddr = s.key.src.String()
+
+		// Header names as sent, from the raw header block
+		var sniffer fingerprint.HeaderSniffer
+		head := data.data[offset:]
+		if i := bytes.Index(head, []byte("\r\n\r\n")); i >= 0 {
+			head = head[:i+4]
+		}
+		_, _ = sniffer.Write(head)
+		req = req.WithContext(context.WithValue(req.Context(), fingerprint.ContextKeyHeaderOrder, fingerprint.HeaderOrderSource(&sniffer)))
+
+		// Segments may be captured out of order; keep stream order
+		ts := data.timeAt(offset)
+		if n := len(records); n > 0 && ts.Before(records[n-1].Timestamp) {
+			ts = records[n-1].Timestamp
+		}
+		fp := collector.Collect(req)
+		fp.TCP = s.tcpFingerprint()
+		records = append(records, Record{
+			Fingerprint: fp,
+			Timestamp:   ts,
+			Kind:        KindHTTP,
+			Client:      s.key.src.String(),
+			Server:      s.key.dst.String(),
+		})
+
+		// Skip the body to reach the next pipelined or keep-alive request
+		if _, err := io.Copy(io.Discard, req.Body); err != nil {
+			return records, nil // body truncated by the capture
+		}
+		_ = req.Body.Close()
+	}
+}
+
+// serverName returns the SNI host name offered in a ClientHello record
+func serverName(record []byte) string {
+	// record header(5) + handshake header(4) + version(2) + random(32)
+	p := 5 + 4 + 2 + 32
+	if len(record) < p+1 {
+		return ""
+	}
+	p += 1 + int(record[p]) // session id
+	if len(record) < p+2 {
+		return ""
+	}
+	p += 2 + (int(record[p])<<8 | int(record[p+1])) // cipher suites
+	if len(record) < p+1 {
+		return ""
+	}
+	p += 1 + int(record[p]) // compression methods
+	if len(record) < p+2 {
+		return ""
+	}
+	end := p + 2 + (int(record[p])<<8 | int(record[p+1]))
+	p += 2
+	if end > len(record) {
+		end = len(record)
+	}
+
+	for p+4 <= end {
+		extType := int(record[p])<<8 | int(record[p+1])
+		extLen := int(record[p+2])<<8 | int(record[p+3])
+		p += 4
+		if p+extLen > end {
+			return ""
+		}
+		if extType == 0 && extLen >= 5 {
+			// server_name_list length(2), name_type(1), name length(2), name
+			ext := record[p : p+extLen]
+			nameLen := int(ext[3])<<8 | int(ext[4])
+			if ext[2] == 0 && 5+nameLen <= len(ext) {
+				return strings.ToLower(string(ext[5 : 5+nameLen]))
+			}
+			return ""
+		}
+		p += extLen
+	}
+	return ""
+}
+
+// handshakeLatency estimates the client's one-way latency as half the time
+// from the server's first flight to the client's next one, which follows
+// the ClientHello record. It returns 0 when either was not captured.
+func handshakeLatency(client, server assembled) time.Duration {
+	n := 5 + (int(client.data[3])<<8 | int(client.data[4]))
+	if n >= len(client.data) || len(server.data) == 0 {
+		return 0
+	}
+	sent, answered := server.timeAt(0), client.timeAt(n)
+	if sent.IsZero() || !answered.After(sent) {
+		return 0
+	}
+	return answered.Sub(sent) / 2
+}
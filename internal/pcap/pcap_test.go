@@ -0,0 +1,16 @@
+package pcap
+
+import (
+	"strings"
+	"testing"
+)
+
+// Tests are in tests/unit/pcap_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestPcapPackage(t *testing.T) {
+	// Verify package is testable
+	if _, err := NewReader(strings.NewReader("")); err != ErrFormat {
+		t.Errorf("NewReader(empty) error = %v, want ErrFormat", err)
+	}
+}
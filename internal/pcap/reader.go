@@ -0,0 +1,265 @@
+// Package pcap extracts client fingerprints from packet captures.
+//
+// It reads classic libpcap and pcapng files, reassembles the client side of
+// each TCP connection, and turns TLS ClientHellos and plaintext HTTP/1.x
+// requests into fingerprints, so traffic can be analyzed when the server
+// was not inline. Only what the client sent is used; nothing is decrypted.
+package pcap
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// Link-layer header types (LINKTYPE_* values)
+const (
+	LinkTypeNull     = 0   // BSD loopback
+	LinkTypeEthernet = 1   // IEEE 802.3 Ethernet
+	LinkTypeRaw      = 101 // Raw IPv4/IPv6
+	LinkTypeLinuxSLL = 113 // Linux "any" device cooked capture
+)
+
+// File magic numbers
+const (
+	magicMicros    = 0xa1b2c3d4
+	magicNanos     = 0xa1b23c4d
+	magicNG        = 0x0a0d0d0a // pcapng Section Header Block type
+	byteOrderMagic = 0x1a2b3c4d
+)
+
+// pcapng block types
+const (
+	blockInterface    = 0x00000001
+	blockSimplePacket = 0x00000003
+	blockEnhanced     = 0x00000006
+)
+
+// maxBlockBytes bounds a single record or block to guard against corrupt files
+const maxBlockBytes = 16 << 20
+
+// ErrFormat is returned for files that are neither pcap nor pcapng
+var ErrFormat = errors.New("not a pcap or pcapng file")
+
+// Packet is a captured link-layer frame
+type Packet struct {
+	Timestamp time.Time
+	LinkType  uint32
+	Data      []byte
+}
+
+// iface describes a pcapng interface
+type iface struct {
+	linkType uint32
+	tsUnit   time.Duration // duration of one timestamp tick
+}
+
+// Reader reads packets from a pcap or pcapng stream
+type Reader struct {
+	r     *bufio.Reader
+	order binary.ByteOrder
+	ng    bool
+
+	// classic pcap
+	linkType uint32
+	tsUnit   time.Duration
+
+	// pcapng
+	ifaces []iface
+}
+
+// NewReader detects the capture format from the file header
+func NewReader(r io.Reader) (*Reader, error) {
+	br := bufio.NewReaderSize(r, 64*1024)
+	head, err := br.Peek(4)
+	if err != nil {
+		return nil, ErrFormat
+	}
+
+	rd := &Reader{r: br}
+	if binary.LittleEndian.Uint32(head) == magicNG {
+		rd.ng = true
+		return rd, nil
+	}
+
+	var hdr [24]byte
+	if _, err := io.ReadFull(br, hdr[:]); err != nil {
+		return nil, ErrFormat
+	}
+	for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+		switch order.Uint32(hdr[0:4]) {
+		case magicMicros:
+			rd.order, rd.tsUnit = order, time.Microsecond
+		case magicNanos:
+			rd.order, rd.tsUnit = order, time.Nanosecond
+		default:
+			continue
+		}
+		rd.linkType = order.Uint32(hdr[20:24]) & 0x0fffffff
+		return rd, nil
+	}
+	return nil, ErrFormat
+}
+
+// Next returns the next packet, or io.EOF at the end of the capture
+func (rd *Reader) Next() (Packet, error) {
+	if rd.ng {
+		return rd.nextNG()
+	}
+
+	var hdr [16]byte
+	if _, err := io.ReadFull(rd.r, hdr[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return Packet{}, io.EOF // truncated final record
+		}
+		return Packet{}, err
+	}
+	sec := rd.order.Uint32(hdr[0:4])
+	frac := rd.order.Uint32(hdr[4:8])
+	capLen := rd.order.Uint32(hdr[8:12])
+	if capLen > maxBlockBytes {
+		return Packet{}, fmt.Errorf("record length %d exceeds limit", capLen)
+	}
+
+	data := make([]byte, capLen)
+	if _, err := io.ReadFull(rd.r, data); err != nil {
+		return Packet{}, io.EOF
+	}
+	return Packet{
+		Timestamp: time.Unix(int64(sec), int64(frac)*int64(rd.tsUnit)).UTC(),
+		LinkType:  rd.linkType,
+		Data:      data,
+	}, nil
+}
+
+// nextNG returns the next packet block of a pcapng stream, processing
+// section and interface blocks along the way
+func (rd *Reader) nextNG() (Packet, error) {
+	for {
+		var hdr [8]byte
+		if _, err := io.ReadFull(rd.r, hdr[:]); err != nil {
+			if errors.Is(err, io.ErrUnexpectedEOF) {
+				return Packet{}, io.EOF
+			}
+			return Packet{}, err
+		}
+
+		blockType := binary.LittleEndian.Uint32(hdr[0:4])
+		if blockType == magicNG {
+			// Section header: the byte order magic follows the length
+			var bom [4]byte
+			if _, err := io.ReadFull(rd.r, bom[:]); err != nil {
+				return Packet{}, io.EOF
+			}
+			switch {
+			case binary.LittleEndian.Uint32(bom[:]) == byteOrderMagic:
+				rd.order = binary.LittleEndian
+			case binary.BigEndian.Uint32(bom[:]) == byteOrderMagic:
+				rd.order = binary.BigEndian
+			default:
+				return Packet{}, ErrFormat
+			}
+			rd.ifaces = nil
+			total := rd.order.Uint32(hdr[4:8])
+			if total < 12 || total > maxBlockBytes {
+				return Packet{}, fmt.Errorf("invalid section header length %d", total)
+			}
+			if _, err := rd.r.Discard(int(total) - 12); err != nil {
+				return Packet{}, io.EOF
+			}
+			continue
+		}
+		if rd.order == nil {
+			return Packet{}, ErrFormat
+		}
+
+		blockType = rd.order.Uint32(hdr[0:4])
+		total := rd.order.Uint32(hdr[4:8])
+		if total < 12 || total > maxBlockBytes {
+			return Packet{}, fmt.Errorf("invalid block length %d", total)
+		}
+		body := make([]byte, total-8)
+		if _, err := io.ReadFull(rd.r, body); err != nil {
+			return Packet{}, io.EOF
+		}
+		body = body[:len(body)-4] // trailing block length
+
+		switch blockType {
+		case blockInterface:
+			rd.ifaces = append(rd.ifaces, rd.parseInterface(body))
+		case blockEnhanced:
+			if pkt, ok := rd.parseEnhanced(body); ok {
+				return pkt, nil
+			}
+		case blockSimplePacket:
+			if len(body) < 4 || len(rd.ifaces) == 0 {
+				continue
+			}
+			return Packet{LinkType: rd.ifaces[0].linkType, Data: body[4:]}, nil
+		}
+	}
+}
+
+// parseInterface reads the link type and timestamp resolution of an
+// Interface Description Block
+func (rd *Reader) parseInterface(body []byte) iface {
+	ifc := iface{tsUnit: time.Microsecond}
+	if len(body) < 8 {
+		return ifc
+	}
+	ifc.linkType = uint32(rd.order.Uint16(body[0:2]))
+
+	// Options: code(2) length(2) value padded to 4 bytes
+	opts := body[8:]
+	for len(opts) >= 4 {
+		code := rd.order.Uint16(opts[0:2])
+		length := int(rd.order.Uint16(opts[2:4]))
+		if code == 0 || 4+length > len(opts) {
+			break
+		}
+		if code == 9 && length >= 1 { // if_tsresol
+			res := opts[4]
+			unit := time.Duration(1)
+			if res&0x80 == 0 {
+				exp := int(res & 0x7f)
+				unit = time.Second
+				for range exp {
+					unit /= 10
+				}
+			}
+			if unit > 0 {
+				ifc.tsUnit = unit
+			}
+		}
+		opts = opts[4+(length+3)&^3:]
+	}
+	return ifc
+}
+
+// parseEnhanced decodes an Enhanced Packet Block
+func (rd *Reader) parseEnhanced(body []byte) (Packet, bool) {
+	if len(body) < 20 {
+		return Packet{}, false
+	}
+	id := rd.order.Uint32(body[0:4])
+	if int(id) >= len(rd.ifaces) {
+		return Packet{}, false
+	}
+	ifc := rd.ifaces[id]
+	ts := uint64(rd.order.Uint32(body[4:8]))<<32 | uint64(rd.order.Uint32(body[8:12]))
+	capLen := int(rd.order.Uint32(body[12:16]))
+	if 20+capLen > len(body) {
+		return Packet{}, false
+	}
+
+	ticksPerSec := uint64(time.Second / ifc.tsUnit)
+	sec, frac := ts/ticksPerSec, ts%ticksPerSec
+	return Packet{
+		Timestamp: time.Unix(int64(sec), int64(frac)*int64(ifc.tsUnit)).UTC(),
+		LinkType:  ifc.linkType,
+		Data:      body[20 : 20+capLen],
+	}, true
+}
@@ -0,0 +1,165 @@
+package pcap
+
+import (
+	"bytes"
+	"net/netip"
+	"sort"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// maxStreamBytes bounds the client payload kept per TCP connection
+const maxStreamBytes = 4 << 20
+
+// flowKey identifies one direction of a TCP connection
+type flowKey struct {
+	src, dst netip.AddrPort
+}
+
+// chunk is a payload fragment at a sequence offset
+type chunk struct {
+	offset uint32 // relative to the initial sequence number
+	ts     time.Time
+	data   []byte
+}
+
+// stream collects the payload sent in one direction of a connection
+type stream struct {
+	key     flowKey
+	isn     uint32 // sequence number of the first payload byte
+	hasISN  bool   // isn was taken from a SYN
+	first   time.Time
+	chunks  []chunk
+	size    int
+	minSeq  uint32
+	started bool
+	syn     []byte // Client SYN headers
+}
+
+// add records a segment; payload before a known ISN is ignored
+func (s *stream) add(seg segment) {
+	if seg.flags&flagSYN != 0 {
+		s.isn, s.hasISN = seg.seq+1, true
+		if seg.syn != nil {
+			s.syn = bytes.Clone(seg.syn)
+		}
+		if s.first.IsZero() {
+			s.first = seg.ts
+		}
+		return
+	}
+	if len(seg.payload) == 0 || s.size >= maxStreamBytes {
+		return
+	}
+	if s.first.IsZero() {
+		s.first = seg.ts
+	}
+	// Without a SYN, the lowest sequence number seen is the start. Compare
+	// with serial arithmetic so wrap-around is handled.
+	if !s.started || int32(seg.seq-s.minSeq) < 0 {
+		s.minSeq = seg.seq
+	}
+	s.started = true
+
+	data := make([]byte, len(seg.payload))
+	copy(data, seg.payload)
+	s.chunks = append(s.chunks, chunk{offset: seg.seq, ts: seg.ts, data: data})
+	s.size += len(data)
+}
+
+// assembled is the reassembled, contiguous prefix of a stream
+type assembled struct {
+	data []byte
+	// marks maps byte offsets in data to capture timestamps, in order
+	marks []chunk
+}
+
+// timeAt returns the capture time of the segment holding byte offset n
+func (a assembled) timeAt(n int) time.Time {
+	i := sort.Search(len(a.marks), func(i int) bool { return int(a.marks[i].offset) > n })
+	if i == 0 {
+		return time.Time{}
+	}
+	return a.marks[i-1].ts
+}
+
+// assemble orders the payload by sequence number, dropping retransmitted
+// bytes and stopping at the first gap
+func (s *stream) assemble() assembled {
+	start := s.minSeq
+	if s.hasISN {
+		start = s.isn
+	}
+
+	chunks := make([]chunk, 0, len(s.chunks))
+	for _, c := range s.chunks {
+		rel := c.offset - start
+		if int32(rel) < 0 {
+			continue // before the stream start (e.g. data on a reused port)
+		}
+		chunks = append(chunks, chunk{offset: rel, ts: c.ts, data: c.data})
+	}
+	sort.SliceStable(chunks, func(i, j int) bool { return chunks[i].offset < chunks[j].offset })
+
+	var out assembled
+	for _, c := range chunks {
+		end := uint32(len(out.data))
+		if c.offset > end {
+			break // missing segment
+		}
+		skip := end - c.offset
+		if int(skip) >= len(c.data) {
+			continue // fully retransmitted
+		}
+		out.marks = append(out.marks, chunk{offset: end, ts: c.ts})
+		out.data = append(out.data, c.data[skip:]...)
+	}
+	return out
+}
+
+// assembler groups segments into per-direction streams. A SYN on a known
+// flow starts a new connection (port reuse).
+type assembler struct {
+	open map[flowKey]*stream
+	done []*stream
+}
+
+func newAssembler() *assembler {
+	return &assembler{open: map[flowKey]*stream{}}
+}
+
+// add routes a segment to its stream
+func (a *assembler) add(seg segment) {
+	key := flowKey{src: seg.src, dst: seg.dst}
+	s := a.open[key]
+	if s != nil && seg.flags&flagSYN != 0 && seg.flags&flagACK == 0 && (s.started || s.hasISN && s.isn != seg.seq+1) {
+		a.done = append(a.done, s)
+		s = nil
+	}
+	if s == nil {
+		s = &stream{key: key}
+		a.open[key] = s
+	}
+	s.add(seg)
+}
+
+// streams returns all streams in order of their first packet
+func (a *assembler) streams() []*stream {
+	all := append([]*stream(nil), a.done...)
+	for _, s := range a.open {
+		all = append(all, s)
+	}
+	sort.SliceStable(all, func(i, j int) bool { return all[i].first.Before(all[j].first) })
+	return all
+}
+
+// tcpFingerprint returns the JA4T fingerprint of the stream's SYN, when
+// the capture has it
+func (s *stream) tcpFingerprint() fingerprint.TCPFingerprint {
+	tcp, err := fingerprint.ParseTCPSYN(s.syn)
+	if err != nil {
+		return fingerprint.TCPFingerprint{}
+	}
+	return tcp
+}
@@ -0,0 +1,13 @@
+package privaterelay
+
+import "testing"
+
+// Tests are in tests/unit/privaterelay_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestPrivaterelayPackage(t *testing.T) {
+	// Verify package is testable
+	if RangesURL == "" {
+		t.Error("RangesURL should not be empty")
+	}
+}
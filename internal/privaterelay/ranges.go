@@ -0,0 +1,162 @@
+// Package privaterelay recognizes Apple iCloud Private Relay egress
+// addresses. Relay traffic leaves from datacenter IPs but carries genuine
+// Safari users, so it is marked distinctly instead of looking like hosting.
+package privaterelay
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// RangesURL is where Apple publishes the egress ranges
+const RangesURL = "https://mask-api.icloud.com/egress-ip-ranges.csv"
+
+// Egress is one published egress range and the location it serves
+type Egress struct {
+	Prefix  netip.Prefix
+	Country string // ISO 3166-1 country code, e.g. "GB"
+	Region  string // ISO 3166-2 region code, e.g. "GB-EN"
+	City    string
+}
+
+// Ranges is a set of egress ranges indexed for lookup by address
+type Ranges struct {
+	byPrefix map[netip.Prefix]Egress
+	bits     []int // Distinct prefix lengths, longest first
+}
+
+// Parse reads egress ranges in Apple's CSV format:
+// prefix,country,region,city (trailing columns ignored)
+func Parse(r io.Reader) (*Ranges, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	rg := &Ranges{byPrefix: map[netip.Prefix]Egress{}}
+	line := 0
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if len(rec) == 0 || strings.TrimSpace(rec[0]) == "" || strings.HasPrefix(rec[0], "#") {
+			continue
+		}
+		p, err := netip.ParsePrefix(strings.TrimSpace(rec[0]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		e := Egress{Prefix: p.Masked()}
+		if len(rec) > 1 {
+			e.Country = rec[1]
+		}
+		if len(rec) > 2 {
+			e.Region = rec[2]
+		}
+		if len(rec) > 3 {
+			e.City = rec[3]
+		}
+		rg.add(e)
+	}
+	if len(rg.byPrefix) == 0 {
+		return nil, errors.New("no egress ranges")
+	}
+	return rg, nil
+}
+
+// Load reads egress ranges from a file
+func Load(path string) (*Ranges, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return Parse(f)
+}
+
+// Fetch downloads egress ranges from url, usually RangesURL
+func Fetch(ctx context.Context, url string) (*Ranges, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: unexpected status %s", url, resp.Status)
+	}
+	return Parse(resp.Body)
+}
+
+// add indexes an egress range
+func (rg *Ranges) add(e Egress) {
+	rg.byPrefix[e.Prefix] = e
+	if !slices.Contains(rg.bits, e.Prefix.Bits()) {
+		rg.bits = append(rg.bits, e.Prefix.Bits())
+		slices.SortFunc(rg.bits, func(a, b int) int { return b - a })
+	}
+}
+
+// Len returns the number of egress ranges
+func (rg *Ranges) Len() int {
+	return len(rg.byPrefix)
+}
+
+// Lookup returns the most specific egress range containing addr
+func (rg *Ranges) Lookup(addr netip.Addr) (Egress, bool) {
+	addr = addr.Unmap()
+	for _, bits := range rg.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+		p, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if e, ok := rg.byPrefix[p]; ok {
+			return e, true
+		}
+	}
+	return Egress{}, false
+}
+
+// Name identifies the lookup in ClassificationResult.Incomplete
+func (rg *Ranges) Name() string {
+	return "private-relay"
+}
+
+// Enrich marks requests from Private Relay egress addresses. It
+// implements classifier.Enricher.
+func (rg *Ranges) Enrich(_ context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return nil
+	}
+	if e, ok := rg.Lookup(addr); ok {
+		fp.Network.PrivateRelay = true
+		fp.Network.RelayCountry = e.Country
+	}
+	return nil
+}
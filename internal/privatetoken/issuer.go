@@ -0,0 +1,112 @@
+package privatetoken
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// RSA key algorithm identifiers accepted in token keys
+var (
+	oidRSAEncryption = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 1}
+	oidRSASSAPSS     = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 1, 10}
+)
+
+// Issuer is a token issuer key trusted by the verifier
+type Issuer struct {
+	Name      string // Issuer name sent in challenges, e.g. "demo-pat.issuer.cloudflare.com"
+	PublicKey *rsa.PublicKey
+	TokenKey  []byte         // DER SubjectPublicKeyInfo as published by the issuer
+	KeyID     [keyIDLen]byte // SHA-256 of TokenKey
+}
+
+// NewIssuer parses a token key: a DER SubjectPublicKeyInfo of an RSA key
+// with the RSASSA-PSS or rsaEncryption algorithm identifier
+func NewIssuer(name string, tokenKey []byte) (Issuer, error) {
+	if name == "" {
+		return Issuer{}, errors.New("issuer name is required")
+	}
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	rest, err := asn1.Unmarshal(tokenKey, &spki)
+	if err != nil {
+		return Issuer{}, fmt.Errorf("invalid token key: %w", err)
+	}
+	if len(rest) > 0 {
+		return Issuer{}, errors.New("invalid token key: trailing data")
+	}
+	if !spki.Algorithm.Algorithm.Equal(oidRSASSAPSS) && !spki.Algorithm.Algorithm.Equal(oidRSAEncryption) {
+		return Issuer{}, fmt.Errorf("unsupported token key algorithm %v", spki.Algorithm.Algorithm)
+	}
+	pub, err := x509.ParsePKCS1PublicKey(spki.PublicKey.Bytes)
+	if err != nil {
+		return Issuer{}, fmt.Errorf("invalid token key: %w", err)
+	}
+	return Issuer{
+		Name:      name,
+		PublicKey: pub,
+		TokenKey:  tokenKey,
+		KeyID:     sha256.Sum256(tokenKey),
+	}, nil
+}
+
+// directory is the issuer directory served at
+// /.well-known/private-token-issuer-directory
+type directory struct {
+	TokenKeys []struct {
+		TokenType uint16 `json:"token-type"`
+		TokenKey  string `json:"token-key"`
+	} `json:"token-keys"`
+}
+
+// ReadDirectory returns the type 0x0002 keys of an issuer directory
+func ReadDirectory(name string, r io.Reader) ([]Issuer, error) {
+	var d directory
+	if err := json.NewDecoder(r).Decode(&d); err != nil {
+		return nil, fmt.Errorf("invalid issuer directory: %w", err)
+	}
+	var issuers []Issuer
+	for _, k := range d.TokenKeys {
+		if k.TokenType != TypeBlindRSA {
+			continue
+		}
+		der, err := decodeBase64(k.TokenKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid token key encoding: %w", err)
+		}
+		iss, err := NewIssuer(name, der)
+		if err != nil {
+			return nil, err
+		}
+		issuers = append(issuers, iss)
+	}
+	if len(issuers) == 0 {
+		return nil, fmt.Errorf("issuer directory of %s has no type 0x0002 keys", name)
+	}
+	return issuers, nil
+}
+
+// LoadDirectory reads an issuer directory saved to a file
+func LoadDirectory(name, path string) ([]Issuer, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return ReadDirectory(name, f)
+}
+
+// encodedKey returns the token key as sent in challenges
+func (i Issuer) encodedKey() string {
+	return base64.URLEncoding.EncodeToString(i.TokenKey)
+}
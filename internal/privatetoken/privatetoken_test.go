@@ -0,0 +1,13 @@
+package privatetoken
+
+import "testing"
+
+// Tests are in tests/unit/privatetoken_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestPrivatetokenPackage(t *testing.T) {
+	// Verify package is testable
+	if Scheme == "" {
+		t.Error("Scheme should not be empty")
+	}
+}
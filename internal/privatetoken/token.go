@@ -0,0 +1,135 @@
+// Package privatetoken verifies Privacy Pass tokens (RFC 9577, RFC 9578),
+// including Apple's Private Access Tokens. Clients that hold a device or
+// account attestation redeem a publicly verifiable token (type 0x0002,
+// blind RSA) issued for a challenge the origin sent in a
+// WWW-Authenticate: PrivateToken header.
+package privatetoken
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Scheme is the HTTP authentication scheme of challenges and tokens
+const Scheme = "PrivateToken"
+
+// TypeBlindRSA is the publicly verifiable token type (RSABSSA-SHA384-PSS-Deterministic)
+const TypeBlindRSA uint16 = 0x0002
+
+// Field sizes of a type 0x0002 token
+const (
+	nonceLen  = 32
+	digestLen = 32
+	keyIDLen  = 32
+	headerLen = 2 + nonceLen + digestLen + keyIDLen
+)
+
+// Challenge is a TokenChallenge an origin sends to request a token
+type Challenge struct {
+	TokenType         uint16
+	IssuerName        string
+	RedemptionContext []byte   // Empty or 32 bytes binding the token to one challenge
+	OriginInfo        []string // Origin names the token is valid for (empty for any)
+}
+
+// Marshal encodes the challenge in its wire format
+func (c Challenge) Marshal() []byte {
+	origins := strings.Join(c.OriginInfo, ",")
+	b := make([]byte, 0, 2+2+len(c.IssuerName)+1+len(c.RedemptionContext)+2+len(origins))
+	b = binary.BigEndian.AppendUint16(b, c.TokenType)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(c.IssuerName)))
+	b = append(b, c.IssuerName...)
+	b = append(b, byte(len(c.RedemptionContext)))
+	b = append(b, c.RedemptionContext...)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(origins)))
+	b = append(b, origins...)
+	return b
+}
+
+// Digest returns the SHA-256 digest a token for this challenge carries
+func (c Challenge) Digest() [digestLen]byte {
+	return sha256.Sum256(c.Marshal())
+}
+
+// Token is a redeemed type 0x0002 token
+type Token struct {
+	TokenType       uint16
+	Nonce           [nonceLen]byte
+	ChallengeDigest [digestLen]byte
+	TokenKeyID      [keyIDLen]byte
+	Authenticator   []byte // RSA-PSS signature over the fields above
+}
+
+// ParseToken decodes a token in its wire format
+func ParseToken(b []byte) (Token, error) {
+	if len(b) < 2 {
+		return Token{}, errors.New("token too short")
+	}
+	var t Token
+	t.TokenType = binary.BigEndian.Uint16(b)
+	if t.TokenType != TypeBlindRSA {
+		return Token{}, fmt.Errorf("unsupported token type 0x%04x", t.TokenType)
+	}
+	if len(b) <= headerLen {
+		return Token{}, errors.New("token too short")
+	}
+	copy(t.Nonce[:], b[2:])
+	copy(t.ChallengeDigest[:], b[2+nonceLen:])
+	copy(t.TokenKeyID[:], b[2+nonceLen+digestLen:])
+	t.Authenticator = b[headerLen:]
+	return t, nil
+}
+
+// Marshal encodes the token in its wire format
+func (t Token) Marshal() []byte {
+	b := t.input()
+	return append(b, t.Authenticator...)
+}
+
+// input returns the signed part of the token
+func (t Token) input() []byte {
+	b := make([]byte, 0, headerLen+len(t.Authenticator))
+	b = binary.BigEndian.AppendUint16(b, t.TokenType)
+	b = append(b, t.Nonce[:]...)
+	b = append(b, t.ChallengeDigest[:]...)
+	b = append(b, t.TokenKeyID[:]...)
+	return b
+}
+
+// Authorization returns the Authorization header value redeeming the token
+func (t Token) Authorization() string {
+	return Scheme + ` token="` + base64.URLEncoding.EncodeToString(t.Marshal()) + `"`
+}
+
+// TokenFromAuthorization extracts the token of an Authorization header
+// using the PrivateToken scheme. It reports false for other schemes.
+func TokenFromAuthorization(header string) (Token, bool, error) {
+	scheme, params, _ := strings.Cut(strings.TrimSpace(header), " ")
+	if !strings.EqualFold(scheme, Scheme) {
+		return Token{}, false, nil
+	}
+	for _, p := range strings.Split(params, ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(p), "=")
+		if !ok || !strings.EqualFold(strings.TrimSpace(name), "token") {
+			continue
+		}
+		raw, err := decodeBase64(strings.Trim(strings.TrimSpace(value), `"`))
+		if err != nil {
+			return Token{}, true, fmt.Errorf("invalid token encoding: %w", err)
+		}
+		t, err := ParseToken(raw)
+		return t, true, err
+	}
+	return Token{}, true, errors.New("missing token parameter")
+}
+
+// decodeBase64 decodes base64url with or without padding, also
+// accepting the standard alphabet some clients send
+func decodeBase64(s string) ([]byte, error) {
+	s = strings.NewReplacer("+", "-", "/", "_").Replace(strings.TrimRight(s, "="))
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,161 @@
+package privatetoken
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// saltLen is the RSA-PSS salt length of RSABSSA-SHA384-PSS-Deterministic
+const saltLen = 48
+
+// Verification errors
+var (
+	ErrUnknownKey       = errors.New("token key not trusted")
+	ErrUnknownChallenge = errors.New("token was not issued for this origin")
+	ErrBadSignature     = errors.New("invalid token signature")
+	ErrReplayed         = errors.New("token already redeemed")
+)
+
+// Config holds verifier configuration
+type Config struct {
+	Issuers []Issuer
+
+	// Origins restricts accepted tokens to challenges naming these origins.
+	// Empty accepts tokens from challenges without origin info only.
+	Origins []string
+
+	// ReplayWindow is how long redeemed nonces are remembered (default 1h)
+	ReplayWindow time.Duration
+	// MaxNonces bounds the remembered nonces per window (default 100000)
+	MaxNonces int
+}
+
+// Verifier issues challenges and verifies redeemed tokens. It implements
+// classifier.Enricher, recording the outcome in Fingerprint.HTTP.PrivateToken.
+type Verifier struct {
+	issuers    map[[keyIDLen]byte]Issuer
+	challenges map[[digestLen]byte]bool
+	header     string // WWW-Authenticate value
+
+	mu        sync.Mutex
+	window    time.Duration
+	maxNonces int
+	rotated   time.Time
+	current   map[[nonceLen]byte]struct{}
+	previous  map[[nonceLen]byte]struct{}
+}
+
+// NewVerifier creates a verifier trusting the configured issuers
+func NewVerifier(cfg Config) (*Verifier, error) {
+	if len(cfg.Issuers) == 0 {
+		return nil, errors.New("at least one issuer is required")
+	}
+	if cfg.ReplayWindow <= 0 {
+		cfg.ReplayWindow = time.Hour
+	}
+	if cfg.MaxNonces <= 0 {
+		cfg.MaxNonces = 100000
+	}
+
+	v := &Verifier{
+		issuers:    map[[keyIDLen]byte]Issuer{},
+		challenges: map[[digestLen]byte]bool{},
+		window:     cfg.ReplayWindow,
+		maxNonces:  cfg.MaxNonces,
+		rotated:    time.Now(),
+		current:    map[[nonceLen]byte]struct{}{},
+	}
+	var header []string
+	seen := map[string]bool{}
+	for _, iss := range cfg.Issuers {
+		v.issuers[iss.KeyID] = iss
+		c := Challenge{TokenType: TypeBlindRSA, IssuerName: iss.Name, OriginInfo: cfg.Origins}
+		v.challenges[c.Digest()] = true
+		if !seen[iss.Name] {
+			seen[iss.Name] = true
+			header = append(header, Scheme+` challenge="`+base64.URLEncoding.EncodeToString(c.Marshal())+`", token-key="`+iss.encodedKey()+`"`)
+		}
+	}
+	v.header = strings.Join(header, ", ")
+	return v, nil
+}
+
+// Challenge returns the WWW-Authenticate header value requesting a token
+// from each configured issuer
+func (v *Verifier) Challenge() string {
+	return v.header
+}
+
+// Verify checks a token's key, challenge and signature, and rejects
+// nonces already redeemed within the replay window
+func (v *Verifier) Verify(t Token) error {
+	iss, ok := v.issuers[t.TokenKeyID]
+	if !ok {
+		return ErrUnknownKey
+	}
+	if !v.challenges[t.ChallengeDigest] {
+		return ErrUnknownChallenge
+	}
+	digest := sha512.Sum384(t.input())
+	opts := &rsa.PSSOptions{SaltLength: saltLen, Hash: crypto.SHA384}
+	if err := rsa.VerifyPSS(iss.PublicKey, crypto.SHA384, digest[:], t.Authenticator, opts); err != nil {
+		return ErrBadSignature
+	}
+	if !v.redeem(t.Nonce, time.Now()) {
+		return ErrReplayed
+	}
+	return nil
+}
+
+// redeem records a nonce and reports whether it was unseen
+func (v *Verifier) redeem(nonce [nonceLen]byte, now time.Time) bool {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	// Two generations: a nonce is remembered for one to two windows
+	if now.Sub(v.rotated) >= v.window || len(v.current) >= v.maxNonces {
+		v.previous, v.current = v.current, map[[nonceLen]byte]struct{}{}
+		v.rotated = now
+	}
+	if _, ok := v.current[nonce]; ok {
+		return false
+	}
+	if _, ok := v.previous[nonce]; ok {
+		return false
+	}
+	v.current[nonce] = struct{}{}
+	return true
+}
+
+// Name identifies the verifier in ClassificationResult.Incomplete
+func (v *Verifier) Name() string {
+	return "private-token"
+}
+
+// Enrich verifies a token redeemed in the Authorization header of r.
+// Invalid tokens are recorded in fp, not returned as errors.
+func (v *Verifier) Enrich(_ context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	t, ok, err := TokenFromAuthorization(r.Header.Get("Authorization"))
+	if !ok {
+		return nil
+	}
+	if err == nil {
+		err = v.Verify(t)
+	}
+	if err != nil {
+		fp.HTTP.PrivateToken = fingerprint.PrivateTokenInvalid
+		return nil
+	}
+	fp.HTTP.PrivateToken = fingerprint.PrivateTokenValid
+	return nil
+}
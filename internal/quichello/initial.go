@@ -0,0 +1,281 @@
+package quichello
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// QUIC versions whose Initial packets are decrypted
+const (
+	Version1 = 0x00000001 // RFC 9000
+	Version2 = 0x6b3343cf // RFC 9369
+)
+
+// initialSalts are the salts Initial secrets are extracted with, by version
+var initialSalts = map[uint32][]byte{
+	Version1: mustHex("38762cf7f55934b34d179ae6a4c80cadccbb7f0a"),
+	Version2: mustHex("0dede3def700a6db819381be6e269dcbf9bd2ed9"),
+}
+
+// Frame types found in client Initial packets
+const (
+	framePadding = 0x00
+	framePing    = 0x01
+	frameAck     = 0x02
+	frameAckECN  = 0x03
+	frameCrypto  = 0x06
+	frameClose   = 0x1c
+)
+
+// maxConnectionIDs is the longest connection ID of QUIC versions 1 and 2
+const maxConnectionIDs = 20
+
+// cryptoFrame is the data of a CRYPTO frame at its stream offset
+type cryptoFrame struct {
+	offset uint64
+	data   []byte
+}
+
+// clientInitial returns the QUIC version and CRYPTO frames of the Initial
+// packets coalesced in a datagram. Other packets, and Initials that do not
+// decrypt with the client's keys, e.g. ones a server sent, are skipped.
+func clientInitial(datagram []byte) (uint32, []cryptoFrame) {
+	var version uint32
+	var frames []cryptoFrame
+	for len(datagram) > 0 {
+		v, payload, rest, ok := openInitial(datagram)
+		if !ok {
+			break
+		}
+		if payload != nil {
+			if f, ok := parseFrames(payload); ok {
+				version = v
+				frames = append(frames, f...)
+			}
+		}
+		datagram = rest
+	}
+	return version, frames
+}
+
+// openInitial decrypts the long header packet at the start of b when it
+// is an Initial, returning its payload (nil for other packets) and the
+// packets coalesced after it. ok is false when b holds no long header
+// packet of a known version.
+func openInitial(b []byte) (version uint32, payload, rest []byte, ok bool) {
+	if len(b) < 7 || b[0]&0xc0 != 0xc0 {
+		return 0, nil, nil, false
+	}
+	version = binary.BigEndian.Uint32(b[1:5])
+	salt, known := initialSalts[version]
+	if !known {
+		return 0, nil, nil, false
+	}
+	p := 5
+	dcidLen := int(b[p])
+	if dcidLen > maxConnectionIDs || len(b) < p+1+dcidLen+1 {
+		return 0, nil, nil, false
+	}
+	dcid := b[p+1 : p+1+dcidLen]
+	p += 1 + dcidLen
+	scidLen := int(b[p])
+	if scidLen > maxConnectionIDs || len(b) < p+1+scidLen {
+		return 0, nil, nil, false
+	}
+	p += 1 + scidLen
+
+	// Packet types were renumbered in version 2
+	initialType, retryType := byte(0), byte(3)
+	if version == Version2 {
+		initialType, retryType = 1, 0
+	}
+	typ := b[0] >> 4 & 0x03
+	isInitial := typ == initialType
+	if isInitial {
+		tokenLen, n := readVarint(b[p:])
+		if n == 0 || uint64(len(b)-p-n) < tokenLen {
+			return 0, nil, nil, false
+		}
+		p += n + int(tokenLen)
+	} else if typ == retryType {
+		return 0, nil, nil, false // Retry, which has no length and is never coalesced
+	}
+	length, n := readVarint(b[p:])
+	if n == 0 || uint64(len(b)-p-n) < length {
+		return 0, nil, nil, false
+	}
+	pnOffset := p + n
+	end := pnOffset + int(length)
+	rest = b[end:]
+	if !isInitial {
+		return version, nil, rest, true
+	}
+	payload = decryptInitial(b[:end], pnOffset, dcid, salt, version)
+	return version, payload, rest, true
+}
+
+// decryptInitial removes header protection from a copy of the Initial
+// packet and decrypts its payload with the client's Initial keys. It
+// returns nil when the packet does not authenticate.
+func decryptInitial(packet []byte, pnOffset int, dcid, salt []byte, version uint32) []byte {
+	if len(packet) < pnOffset+4+aes.BlockSize {
+		return nil
+	}
+	key, iv, hp, err := clientKeys(dcid, salt, version)
+	if err != nil {
+		return nil
+	}
+	hpBlock, err := aes.NewCipher(hp)
+	if err != nil {
+		return nil
+	}
+	mask := make([]byte, aes.BlockSize)
+	hpBlock.Encrypt(mask, packet[pnOffset+4:pnOffset+4+aes.BlockSize])
+
+	header := make([]byte, pnOffset+4)
+	copy(header, packet)
+	header[0] ^= mask[0] & 0x0f
+	pnLen := int(header[0]&0x03) + 1
+	var pn uint64
+	for i := range pnLen {
+		header[pnOffset+i] ^= mask[1+i]
+		pn = pn<<8 | uint64(header[pnOffset+i])
+	}
+	header = header[:pnOffset+pnLen]
+
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil
+	}
+	nonce := make([]byte, len(iv))
+	copy(nonce, iv)
+	for i := range 8 {
+		nonce[len(nonce)-1-i] ^= byte(pn >> (8 * i))
+	}
+	payload, err := aead.Open(nil, nonce, packet[pnOffset+pnLen:], header)
+	if err != nil {
+		return nil
+	}
+	return payload
+}
+
+// clientKeys derives the AEAD key, IV and header protection key of the
+// client's Initial packets
+func clientKeys(dcid, salt []byte, version uint32) (key, iv, hp []byte, err error) {
+	initial, err := hkdf.Extract(sha256.New, dcid, salt)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	client, err := expandLabel(initial, "client in", sha256.Size)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	prefix := "quic "
+	if version == Version2 {
+		prefix = "quicv2 "
+	}
+	if key, err = expandLabel(client, prefix+"key", 16); err != nil {
+		return nil, nil, nil, err
+	}
+	if iv, err = expandLabel(client, prefix+"iv", 12); err != nil {
+		return nil, nil, nil, err
+	}
+	if hp, err = expandLabel(client, prefix+"hp", 16); err != nil {
+		return nil, nil, nil, err
+	}
+	return key, iv, hp, nil
+}
+
+// expandLabel is HKDF-Expand-Label of TLS 1.3 with an empty context
+func expandLabel(secret []byte, label string, length int) ([]byte, error) {
+	label = "tls13 " + label
+	info := []byte{byte(length >> 8), byte(length), byte(len(label))}
+	info = append(info, label...)
+	info = append(info, 0)
+	return hkdf.Expand(sha256.New, secret, string(info), length)
+}
+
+// parseFrames returns the CRYPTO frames of a decrypted Initial payload.
+// ok is false when it holds a frame a client Initial cannot carry.
+func parseFrames(b []byte) (frames []cryptoFrame, ok bool) {
+	for len(b) > 0 {
+		typ := b[0]
+		b = b[1:]
+		switch typ {
+		case framePadding, framePing:
+		case frameAck, frameAckECN:
+			// Largest acknowledged, delay, range count, first range
+			var fields [4]uint64
+			for i := range fields {
+				v, n := readVarint(b)
+				if n == 0 {
+					return nil, false
+				}
+				fields[i], b = v, b[n:]
+			}
+			skip := 2 * fields[2]
+			if typ == frameAckECN {
+				skip += 3
+			}
+			for range skip {
+				_, n := readVarint(b)
+				if n == 0 {
+					return nil, false
+				}
+				b = b[n:]
+			}
+		case frameCrypto:
+			offset, n := readVarint(b)
+			if n == 0 {
+				return nil, false
+			}
+			b = b[n:]
+			length, n := readVarint(b)
+			if n == 0 || uint64(len(b)-n) < length {
+				return nil, false
+			}
+			b = b[n:]
+			frames = append(frames, cryptoFrame{offset: offset, data: b[:length]})
+			b = b[length:]
+		case frameClose:
+			return frames, true
+		default:
+			return nil, false
+		}
+	}
+	return frames, true
+}
+
+// readVarint reads a QUIC variable-length integer, returning its value
+// and size, or a size of 0 when b is too short
+func readVarint(b []byte) (uint64, int) {
+	if len(b) == 0 {
+		return 0, 0
+	}
+	n := 1 << (b[0] >> 6)
+	if len(b) < n {
+		return 0, 0
+	}
+	v := uint64(b[0] & 0x3f)
+	for _, c := range b[1:n] {
+		v = v<<8 | uint64(c)
+	}
+	return v, n
+}
+
+// mustHex decodes a hex constant
+func mustHex(s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		panic(err)
+	}
+	return b
+}
@@ -0,0 +1,220 @@
+// Package quichello records the TLS ClientHello that opened each QUIC
+// connection, for fingerprinting HTTP/3 clients (JA4 with the "q" prefix,
+// QUIC transport parameters).
+//
+// QUIC carries the TLS handshake in CRYPTO frames of Initial packets,
+// protected with keys anyone can derive from the Destination Connection ID
+// the client picked (RFC 9001, section 5.2). The recorder wraps the
+// server's UDP socket, decrypts the client's Initial packets as they are
+// read and reassembles the ClientHello, which spans several packets when
+// it carries post-quantum key shares. Packets are passed on unchanged.
+package quichello
+
+import (
+	"net"
+	"sync"
+	"time"
+)
+
+// Limits bounding the memory of ClientHellos whose connection never
+// reaches the server
+const (
+	maxEntries  = 4096
+	maxHello    = 16 << 10
+	entryExpiry = 10 * time.Second
+)
+
+// Hello is the ClientHello of a QUIC connection
+type Hello struct {
+	Version uint32 // QUIC version of the Initial packets
+	Message []byte // ClientHello handshake message, from its type byte
+}
+
+// Record returns the ClientHello as a TLS handshake record, the form
+// tlsfingerprint.ParseClientHello expects
+func (h Hello) Record() []byte {
+	n := len(h.Message)
+	return append([]byte{0x16, 0x03, 0x01, byte(n >> 8), byte(n)}, h.Message...)
+}
+
+// Recorder keeps the ClientHello of every QUIC connection read through
+// the packet conns it wraps, until taken
+type Recorder struct {
+	mu      sync.Mutex
+	pending map[string]*assembly // By remote address, until complete
+	hellos  map[string]entry     // By remote address
+}
+
+// entry is a complete ClientHello waiting to be taken
+type entry struct {
+	hello Hello
+	at    time.Time
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{
+		pending: make(map[string]*assembly),
+		hellos:  make(map[string]entry),
+	}
+}
+
+// PacketConn returns pc recording the ClientHellos of the QUIC Initial
+// packets read from it. Only ReadFrom is intercepted, so quic-go reads
+// through it rather than through ReadMsgUDP.
+func (r *Recorder) PacketConn(pc net.PacketConn) net.PacketConn {
+	return &packetConn{PacketConn: pc, r: r}
+}
+
+// Take returns the ClientHello of the connection from addr and forgets it
+func (r *Recorder) Take(addr net.Addr) (Hello, bool) {
+	if addr == nil {
+		return Hello{}, false
+	}
+	key := addr.String()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	e, ok := r.hellos[key]
+	delete(r.hellos, key)
+	return e.hello, ok
+}
+
+// Len returns the number of ClientHellos recorded and not yet taken
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.hellos)
+}
+
+// record adds the CRYPTO frame data of a client Initial packet from key
+func (r *Recorder) record(key string, version uint32, frames []cryptoFrame, now time.Time) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, done := r.hellos[key]; done {
+		return // Retransmission
+	}
+	a := r.pending[key]
+	if a == nil {
+		if len(r.pending) >= maxEntries {
+			r.prune(now)
+			if len(r.pending) >= maxEntries {
+				return
+			}
+		}
+		a = &assembly{version: version, started: now}
+		r.pending[key] = a
+	}
+	for _, f := range frames {
+		a.add(f.offset, f.data)
+	}
+	msg, ok := a.message()
+	if !ok {
+		return
+	}
+	delete(r.pending, key)
+	if len(r.hellos) >= maxEntries {
+		r.prune(now)
+		if len(r.hellos) >= maxEntries {
+			return
+		}
+	}
+	r.hellos[key] = entry{hello: Hello{Version: a.version, Message: msg}, at: now}
+}
+
+// prune drops entries older than entryExpiry
+func (r *Recorder) prune(now time.Time) {
+	for k, a := range r.pending {
+		if now.Sub(a.started) > entryExpiry {
+			delete(r.pending, k)
+		}
+	}
+	for k, e := range r.hellos {
+		if now.Sub(e.at) > entryExpiry {
+			delete(r.hellos, k)
+		}
+	}
+}
+
+// assembly reassembles the CRYPTO stream of a connection's Initial packets
+type assembly struct {
+	version uint32
+	started time.Time
+	data    []byte
+	filled  []bool
+}
+
+// add copies data at offset of the CRYPTO stream, ignoring bytes past
+// maxHello
+func (a *assembly) add(offset uint64, data []byte) {
+	if offset >= maxHello {
+		return
+	}
+	end := min(offset+uint64(len(data)), maxHello)
+	if int(end) > len(a.data) {
+		a.data = append(a.data, make([]byte, int(end)-len(a.data))...)
+		a.filled = append(a.filled, make([]bool, int(end)-len(a.filled))...)
+	}
+	copy(a.data[offset:end], data)
+	for i := offset; i < end; i++ {
+		a.filled[i] = true
+	}
+}
+
+// message returns the ClientHello once all of it was received
+func (a *assembly) message() ([]byte, bool) {
+	if !a.complete(4) || a.data[0] != 0x01 {
+		return nil, false
+	}
+	n := 4 + (int(a.data[1])<<16 | int(a.data[2])<<8 | int(a.data[3]))
+	if !a.complete(n) {
+		return nil, false
+	}
+	return a.data[:n:n], true
+}
+
+// complete reports whether the first n bytes were received
+func (a *assembly) complete(n int) bool {
+	if len(a.filled) < n {
+		return false
+	}
+	for _, ok := range a.filled[:n] {
+		if !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// packetConn records the ClientHellos of the packets it reads
+type packetConn struct {
+	net.PacketConn
+	r *Recorder
+}
+
+// ReadFrom reads a datagram and records the ClientHello data of the
+// client Initial packets in it
+func (c *packetConn) ReadFrom(p []byte) (int, net.Addr, error) {
+	n, addr, err := c.PacketConn.ReadFrom(p)
+	if n > 0 && addr != nil {
+		if version, frames := clientInitial(p[:n]); len(frames) > 0 {
+			c.r.record(addr.String(), version, frames, time.Now())
+		}
+	}
+	return n, addr, err
+}
+
+// SetReadBuffer sets the socket receive buffer, which quic-go enlarges
+func (c *packetConn) SetReadBuffer(bytes int) error {
+	if s, ok := c.PacketConn.(interface{ SetReadBuffer(int) error }); ok {
+		return s.SetReadBuffer(bytes)
+	}
+	return nil
+}
+
+// SetWriteBuffer sets the socket send buffer, which quic-go enlarges
+func (c *packetConn) SetWriteBuffer(bytes int) error {
+	if s, ok := c.PacketConn.(interface{ SetWriteBuffer(int) error }); ok {
+		return s.SetWriteBuffer(bytes)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package quichello
+
+import "testing"
+
+// Tests are in tests/unit/quichello_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestQuichelloPackage(t *testing.T) {
+	// Verify package is testable
+	r := NewRecorder()
+	if _, ok := r.Take(nil); ok || r.Len() != 0 {
+		t.Error("new Recorder should be empty")
+	}
+}
@@ -0,0 +1,165 @@
+// Package rate counts requests per client address and per JA4
+// fingerprint over a sliding one-minute window of one-second buckets.
+// Scrapers that pass every per-request check still give themselves away
+// by volume: hundreds of pages a minute from one address, or a rare TLS
+// stack hammering the site from many.
+package rate
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// buckets is the number of one-second buckets in the window
+const buckets = 60
+
+// Config holds rate tracker configuration
+type Config struct {
+	MaxKeys int // Maximum number of tracked addresses and fingerprints (least recently seen evicted first)
+}
+
+// DefaultConfig returns default rate tracker configuration
+func DefaultConfig() Config {
+	return Config{MaxKeys: 50000}
+}
+
+// Tracker counts requests per key over a sliding window
+type Tracker struct {
+	mu      sync.Mutex
+	cfg     Config
+	windows map[string]*window
+}
+
+// window holds the request counts of one key, one bucket per second
+type window struct {
+	counts [buckets]uint32
+	last   int64 // Unix second of the latest request
+}
+
+// New creates a new rate tracker
+func New(cfg Config) *Tracker {
+	if cfg.MaxKeys <= 0 {
+		cfg.MaxKeys = DefaultConfig().MaxKeys
+	}
+	return &Tracker{
+		cfg:     cfg,
+		windows: make(map[string]*window),
+	}
+}
+
+// Observe records a request from a client address (already keyed, e.g.
+// by subnet or anonymized) with a JA4 fingerprint (empty without TLS) and
+// returns the request rates of both
+func (t *Tracker) Observe(addr, ja4 string, now time.Time) fingerprint.RateFingerprint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r := fingerprint.RateFingerprint{Available: true}
+	r.AddrRequests, r.AddrBurst = t.add("addr|"+addr, now)
+	if ja4 != "" {
+		r.JA4Requests, r.JA4Burst = t.add("ja4|"+ja4, now)
+	}
+	return r
+}
+
+// add counts a request for key and returns the requests in the window and
+// in the current second. Caller must hold the lock.
+func (t *Tracker) add(key string, now time.Time) (requests, burst int) {
+	sec := now.Unix()
+	w, ok := t.windows[key]
+	if !ok {
+		if len(t.windows) >= t.cfg.MaxKeys {
+			t.evict(sec)
+		}
+		w = &window{last: sec}
+		t.windows[key] = w
+	}
+	w.advance(sec)
+	w.counts[sec%buckets]++
+	for _, c := range w.counts {
+		requests += int(c)
+	}
+	return requests, int(w.counts[sec%buckets])
+}
+
+// advance clears the buckets of the seconds since the latest request
+func (w *window) advance(sec int64) {
+	if sec <= w.last {
+		return // Same second, or a clock step back
+	}
+	if sec-w.last >= buckets {
+		w.counts = [buckets]uint32{}
+	} else {
+		for s := w.last + 1; s <= sec; s++ {
+			w.counts[s%buckets] = 0
+		}
+	}
+	w.last = sec
+}
+
+// Len returns the number of tracked addresses and fingerprints
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.windows)
+}
+
+// EntryBytes estimates the memory held per key: its buckets, key and map
+// overhead
+func (t *Tracker) EntryBytes() int {
+	return buckets*4 + 256
+}
+
+// SetCapacity changes the maximum number of tracked keys, evicting the
+// least recently seen keys beyond it
+func (t *Tracker) SetCapacity(n int) {
+	if n <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cfg.MaxKeys = n
+	excess := len(t.windows) - n
+	if excess <= 0 {
+		return
+	}
+	keys := make([]string, 0, len(t.windows))
+	for k := range t.windows {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return cmp.Compare(t.windows[a].last, t.windows[b].last)
+	})
+	for _, k := range keys[:excess] {
+		delete(t.windows, k)
+	}
+}
+
+// evict removes keys without requests in the window, or the least
+// recently seen key if all are active. Caller must hold the lock.
+func (t *Tracker) evict(sec int64) {
+	var oldestKey string
+	var oldest int64
+	removed := false
+
+	for k, w := range t.windows {
+		if sec-w.last >= buckets {
+			delete(t.windows, k)
+			removed = true
+			continue
+		}
+		if oldestKey == "" || w.last < oldest {
+			oldestKey = k
+			oldest = w.last
+		}
+	}
+
+	if !removed && oldestKey != "" {
+		delete(t.windows, oldestKey)
+	}
+}
@@ -0,0 +1,14 @@
+package rate
+
+import "testing"
+
+// Tests are in tests/unit/rate_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestRatePackage(t *testing.T) {
+	// Verify package is testable
+	tr := New(DefaultConfig())
+	if tr == nil {
+		t.Error("New should not return nil")
+	}
+}
@@ -0,0 +1,139 @@
+package reputation
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+)
+
+// Lists is the default Provider: CIDR lists of anonymizing networks, each
+// loaded under a category. An address in several lists takes the category
+// of its most specific prefix.
+type Lists struct {
+	byPrefix map[netip.Prefix]string // Category by prefix
+	bits     []int                   // Distinct prefix lengths, longest first
+}
+
+// NewLists returns empty lists
+func NewLists() *Lists {
+	return &Lists{byPrefix: map[netip.Prefix]string{}}
+}
+
+// Add lists prefixes under a category, e.g. "vpn". It is not safe to call
+// while addresses are looked up.
+func (l *Lists) Add(category string, prefixes []netip.Prefix) {
+	for _, p := range prefixes {
+		p = p.Masked()
+		l.byPrefix[p] = category
+		if !slices.Contains(l.bits, p.Bits()) {
+			l.bits = append(l.bits, p.Bits())
+			slices.SortFunc(l.bits, func(a, b int) int { return b - a })
+		}
+	}
+}
+
+// Load reads a list from a file, or from an http(s) URL, and adds it
+// under category
+func (l *Lists) Load(ctx context.Context, category, src string) error {
+	prefixes, err := fetchList(ctx, src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", src, err)
+	}
+	l.Add(category, prefixes)
+	return nil
+}
+
+// fetchList reads a list from a file or URL
+func fetchList(ctx context.Context, src string) ([]netip.Prefix, error) {
+	if !strings.HasPrefix(src, "http://") && !strings.HasPrefix(src, "https://") {
+		f, err := os.Open(src)
+		if err != nil {
+			return nil, err
+		}
+		defer func() { _ = f.Close() }()
+		return ParseList(f)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return ParseList(resp.Body)
+}
+
+// ParseList reads one prefix or address per line, with # comments
+func ParseList(r io.Reader) ([]netip.Prefix, error) {
+	var prefixes []netip.Prefix
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		s, _, _ := strings.Cut(scanner.Text(), "#")
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+		p, err := parsePrefix(s)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		prefixes = append(prefixes, p)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(prefixes) == 0 {
+		return nil, errors.New("no IP ranges")
+	}
+	return prefixes, nil
+}
+
+// parsePrefix parses a prefix, or an address as a single-address prefix
+func parsePrefix(s string) (netip.Prefix, error) {
+	if !strings.Contains(s, "/") {
+		addr, err := netip.ParseAddr(s)
+		if err != nil {
+			return netip.Prefix{}, err
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+	p, err := netip.ParsePrefix(s)
+	return p.Masked(), err
+}
+
+// Len returns the number of listed prefixes
+func (l *Lists) Len() int {
+	return len(l.byPrefix)
+}
+
+// Lookup reports whether addr is in a list, with the list's category
+func (l *Lists) Lookup(_ context.Context, addr netip.Addr) (Result, error) {
+	addr = addr.Unmap()
+	for _, bits := range l.bits {
+		if bits > addr.BitLen() {
+			continue
+		}
+		p, err := addr.Prefix(bits)
+		if err != nil {
+			continue
+		}
+		if category, ok := l.byPrefix[p]; ok {
+			return Result{Anonymized: true, Category: category}, nil
+		}
+	}
+	return Result{}, nil
+}
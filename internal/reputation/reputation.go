@@ -0,0 +1,135 @@
+// Package reputation looks up client addresses in IP reputation sources:
+// VPN, anonymous proxy and Tor exit lists. Browsers behind them are real
+// users as often as not, but scrapers rotate through them to spread their
+// requests, so a listed address leans bot. Sources implement Provider;
+// Lists, the default, reads CIDR lists from files or URLs. Lookups are
+// cached per address.
+package reputation
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/netip"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Result is what a provider knows about an address
+type Result struct {
+	Anonymized bool   // Address is a VPN, proxy or Tor exit
+	Category   string // e.g. "vpn", "proxy", "tor" (empty when not anonymized)
+}
+
+// Provider looks up the reputation of an address. Implementations backed
+// by remote services should honor ctx.
+type Provider interface {
+	Lookup(ctx context.Context, addr netip.Addr) (Result, error)
+}
+
+// DefaultTTL is how long a lookup result is reused for an address
+const DefaultTTL = 10 * time.Minute
+
+// maxCached bounds the results remembered
+const maxCached = 10000
+
+// Enricher records the reputation of client addresses from a Provider,
+// remembering results for DefaultTTL. It implements classifier.Enricher.
+type Enricher struct {
+	provider Provider
+
+	mu     sync.Mutex
+	cached map[netip.Addr]cachedResult
+	now    func() time.Time
+}
+
+type cachedResult struct {
+	result  Result
+	expires time.Time
+}
+
+// NewEnricher returns an enricher looking addresses up with p
+func NewEnricher(p Provider) *Enricher {
+	return &Enricher{
+		provider: p,
+		cached:   map[netip.Addr]cachedResult{},
+		now:      time.Now,
+	}
+}
+
+// Lookup returns the reputation of addr, from the cache when fresh.
+// Provider errors are returned and not cached.
+func (e *Enricher) Lookup(ctx context.Context, addr netip.Addr) (Result, error) {
+	addr = addr.Unmap()
+	if res, ok := e.cachedResult(addr); ok {
+		return res, nil
+	}
+	res, err := e.provider.Lookup(ctx, addr)
+	if err != nil {
+		return Result{}, err
+	}
+	e.remember(addr, res)
+	return res, nil
+}
+
+// cachedResult returns a remembered, unexpired result
+func (e *Enricher) cachedResult(addr netip.Addr) (Result, bool) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	c, ok := e.cached[addr]
+	if !ok || e.now().After(c.expires) {
+		return Result{}, false
+	}
+	return c.result, true
+}
+
+// remember stores a result, dropping expired ones (or, failing that, all)
+// when the cache is full
+func (e *Enricher) remember(addr netip.Addr, res Result) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	now := e.now()
+	if len(e.cached) >= maxCached {
+		for a, c := range e.cached {
+			if now.After(c.expires) {
+				delete(e.cached, a)
+			}
+		}
+		if len(e.cached) >= maxCached {
+			clear(e.cached)
+		}
+	}
+	e.cached[addr] = cachedResult{result: res, expires: now.Add(DefaultTTL)}
+}
+
+// Name identifies the lookup in ClassificationResult.Incomplete
+func (e *Enricher) Name() string {
+	return "ip-reputation"
+}
+
+// Enrich records the anonymizer category of the remote address in
+// fp.Network.Anonymizer. Loopback and private addresses, such as a load
+// balancer's, are not looked up.
+func (e *Enricher) Enrich(ctx context.Context, r *http.Request, fp *fingerprint.Fingerprint) error {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || addr.IsLoopback() || addr.IsPrivate() || addr.IsLinkLocalUnicast() {
+		return nil
+	}
+	res, err := e.Lookup(ctx, addr)
+	if err != nil {
+		return err
+	}
+	if res.Anonymized {
+		fp.Network.Anonymizer = res.Category
+		if fp.Network.Anonymizer == "" {
+			fp.Network.Anonymizer = "anonymizer"
+		}
+	}
+	return nil
+}
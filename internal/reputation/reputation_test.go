@@ -0,0 +1,13 @@
+package reputation
+
+import "testing"
+
+// Tests are in tests/unit/reputation_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestReputationPackage(t *testing.T) {
+	// Verify package is testable
+	if NewEnricher(NewLists()).Name() == "" {
+		t.Error("Name() should not be empty")
+	}
+}
@@ -0,0 +1,161 @@
+package ruleset
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Issue severities
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Issue is a problem found by Lint
+type Issue struct {
+	Severity string `json:"severity"`
+	Path     string `json:"path"` // e.g. "weights.sec-fetch" or "patterns.bot[3]"
+	Message  string `json:"message"`
+}
+
+func (i Issue) String() string {
+	return fmt.Sprintf("%s: %s: %s", i.Severity, i.Path, i.Message)
+}
+
+// HasErrors reports whether any issue is an error
+func HasErrors(issues []Issue) bool {
+	for _, i := range issues {
+		if i.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Lint checks the ruleset for unknown rule names, custom rules that do not
+// compile, patterns that can never match and rules that conflict with each
+// other
+func (rs Ruleset) Lint() []Issue {
+	var issues []Issue
+	add := func(severity, path, format string, args ...any) {
+		issues = append(issues, Issue{Severity: severity, Path: path, Message: fmt.Sprintf(format, args...)})
+	}
+
+	rules := rs.Rules()
+	names := map[string]bool{}
+	for i, r := range rs.Custom {
+		path := fmt.Sprintf("rules[%d]", i)
+		if _, err := r.compile(); err != nil {
+			add(SeverityError, path, "%v", err)
+			continue
+		}
+		switch {
+		case names[r.Name]:
+			add(SeverityError, path, "duplicate rule name %q", r.Name)
+		case isBuiltin(r.Name):
+			add(SeverityError, path, "rule name %q is a built-in rule; change its weight under weights instead", r.Name)
+		case r.Weight < 0:
+			add(SeverityWarning, path, "negative weight %d scores towards the opposite class", r.Weight)
+		case r.Weight == 0:
+			add(SeverityWarning, path, "weight 0 disables the rule")
+		}
+		names[r.Name] = true
+	}
+
+	for _, name := range sortedKeys(rs.Weights) {
+		path := "weights." + name
+		weight := rs.Weights[name]
+		if _, ok := rules.Lookup(name); !ok {
+			add(SeverityError, path, "unknown rule %q", name)
+			continue
+		}
+		switch {
+		case weight < 0:
+			add(SeverityWarning, path, "negative weight %d scores towards the opposite class", weight)
+		case weight == 0:
+			add(SeverityWarning, path, "weight 0 disables the rule")
+		}
+	}
+
+	if rs.Policy.MaxBrowserLag < 0 {
+		add(SeverityError, "policy.max_browser_lag", "negative lag %d; use weights.outdated-browser: 0 to disable the rule", rs.Policy.MaxBrowserLag)
+	}
+
+	lists := []struct {
+		name     string
+		patterns []string
+	}{
+		{"bot", rules.BotPatterns},
+		{"ai_crawler", rules.AICrawlerPatterns},
+		{"browser", rules.BrowserPatterns},
+	}
+	for _, list := range lists {
+		seen := map[string]bool{}
+		for i, p := range list.patterns {
+			path := fmt.Sprintf("patterns.%s[%d]", list.name, i)
+			switch {
+			case strings.TrimSpace(p) == "":
+				add(SeverityError, path, "empty pattern matches every User-Agent")
+			case p != strings.ToLower(p):
+				add(SeverityError, path, "pattern %q is not lowercase and never matches the lowercased User-Agent", p)
+			case seen[p]:
+				add(SeverityWarning, path, "duplicate pattern %q", p)
+			}
+			seen[p] = true
+		}
+	}
+
+	seen := map[string]bool{}
+	for i, f := range rules.InterceptorFingerprints {
+		path := fmt.Sprintf("patterns.tls_interceptor[%d]", i)
+		key := strings.ToLower(strings.TrimSpace(f))
+		switch {
+		case key == "":
+			add(SeverityError, path, "empty TLS fingerprint")
+		case seen[key]:
+			add(SeverityWarning, path, "duplicate TLS fingerprint %q", f)
+		}
+		seen[key] = true
+	}
+
+	// A UA matching any bot pattern is never a browser UA, so a browser
+	// pattern containing a bot pattern can never fire browser-ua
+	for i, p := range rules.BrowserPatterns {
+		if bot := firstContained(p, rules.BotPatterns); bot != "" {
+			add(SeverityError, fmt.Sprintf("patterns.browser[%d]", i),
+				"browser pattern %q contains bot pattern %q and can never match", p, bot)
+		}
+	}
+
+	// AI crawlers are expected to also be flagged by bot-ua. Only checked
+	// when the ruleset overrides one of the lists involved.
+	if rs.Patterns.AICrawler == nil && rs.Patterns.Bot == nil {
+		return issues
+	}
+	for i, p := range rules.AICrawlerPatterns {
+		if p != "" && firstContained(p, rules.BotPatterns) == "" {
+			add(SeverityWarning, fmt.Sprintf("patterns.ai_crawler[%d]", i),
+				"AI crawler pattern %q is not covered by any bot pattern", p)
+		}
+	}
+
+	return issues
+}
+
+// isBuiltin reports whether name is a built-in scoring rule
+func isBuiltin(name string) bool {
+	_, ok := fingerprint.LookupScoringRule(name)
+	return ok
+}
+
+// firstContained returns the first non-empty pattern that s contains
+func firstContained(s string, patterns []string) string {
+	for _, p := range patterns {
+		if p != "" && strings.Contains(s, p) {
+			return p
+		}
+	}
+	return ""
+}
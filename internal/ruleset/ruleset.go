@@ -0,0 +1,155 @@
+// Package ruleset loads, lints and tests classifier rulesets.
+//
+// A ruleset is a YAML (or JSON) file that overrides the built-in
+// User-Agent patterns, rule weights and classification policy, and adds
+// rules of its own:
+//
+//	version: "2026-10"
+//	policy:
+//	  threshold: 1
+//	patterns:
+//	  bot: [curl, python, headless]
+//	weights:
+//	  sec-fetch: 4
+//	  http1.1: 0
+//	rules:
+//	  - name: api-no-cookies
+//	    label: bot
+//	    signal: http.has_cookies
+//	    condition: "== false"
+//	    weight: 2
+//
+// Omitted pattern lists keep the built-in defaults and omitted weights keep
+// their default points. Declarative test cases live next to the ruleset in
+// a file with a _test.yaml suffix (see TestFile).
+package ruleset
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Ruleset is a parsed ruleset file
+type Ruleset struct {
+	Version  string         `yaml:"version"`
+	Policy   Policy         `yaml:"policy"`
+	Patterns Patterns       `yaml:"patterns"`
+	Weights  map[string]int `yaml:"weights"`
+	Custom   []Rule         `yaml:"rules"`
+}
+
+// Rule is a scoring rule defined by the ruleset. It adds Weight to the
+// Label side when Signal satisfies Condition (see fingerprint.CustomRule).
+type Rule struct {
+	Name      string `yaml:"name"`
+	Label     string `yaml:"label"` // browser or bot
+	Signal    string `yaml:"signal"`
+	Condition string `yaml:"condition"`
+	Weight    int    `yaml:"weight"`
+}
+
+// compile builds the fingerprint rule
+func (r Rule) compile() (fingerprint.CustomRule, error) {
+	if r.Label != classifier.ClassificationBrowser && r.Label != classifier.ClassificationBot {
+		return fingerprint.CustomRule{}, fmt.Errorf("label must be %q or %q, got %q",
+			classifier.ClassificationBrowser, classifier.ClassificationBot, r.Label)
+	}
+	rule := fingerprint.ScoringRule{Name: r.Name, Bot: r.Label == classifier.ClassificationBot, Weight: r.Weight}
+	return fingerprint.NewCustomRule(rule, r.Signal, r.Condition)
+}
+
+// Policy holds the classification decision settings
+type Policy struct {
+	// Threshold is the net score (browser - bot) at or above which a
+	// request is classified as browser
+	Threshold int `yaml:"threshold"`
+
+	// MaxBrowserLag is how many major releases a browser User-Agent may
+	// trail the newest before outdated-browser fires (0 = the default)
+	MaxBrowserLag int `yaml:"max_browser_lag"`
+}
+
+// Patterns replaces the built-in User-Agent pattern lists.
+// A nil list keeps the default; an explicitly empty list disables matching.
+type Patterns struct {
+	Bot       []string `yaml:"bot"`
+	AICrawler []string `yaml:"ai_crawler"`
+	Browser   []string `yaml:"browser"`
+
+	// TLSInterceptor lists JA3 hashes or JA4 fingerprints of corporate
+	// TLS-intercepting proxies
+	TLSInterceptor []string `yaml:"tls_interceptor"`
+}
+
+// Parse decodes a ruleset, rejecting unknown keys
+func Parse(data []byte) (Ruleset, error) {
+	var rs Ruleset
+	if err := decodeStrict(data, &rs); err != nil {
+		return Ruleset{}, err
+	}
+	return rs, nil
+}
+
+// LoadFile reads and parses a ruleset file
+func LoadFile(path string) (Ruleset, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Ruleset{}, err
+	}
+	rs, err := Parse(data)
+	if err != nil {
+		return Ruleset{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return rs, nil
+}
+
+// Rules returns the fingerprint rules described by the ruleset
+func (rs Ruleset) Rules() fingerprint.Rules {
+	rules := fingerprint.DefaultRules()
+	if rs.Patterns.Bot != nil {
+		rules.BotPatterns = rs.Patterns.Bot
+	}
+	if rs.Patterns.AICrawler != nil {
+		rules.AICrawlerPatterns = rs.Patterns.AICrawler
+	}
+	if rs.Patterns.Browser != nil {
+		rules.BrowserPatterns = rs.Patterns.Browser
+	}
+	rules.InterceptorFingerprints = rs.Patterns.TLSInterceptor
+	rules.Weights = rs.Weights
+	rules.MaxBrowserLag = rs.Policy.MaxBrowserLag
+	// Rules that do not compile are reported by Lint and left out
+	for _, r := range rs.Custom {
+		if c, err := r.compile(); err == nil {
+			rules.Custom = append(rules.Custom, c)
+		}
+	}
+	return rules
+}
+
+// Config returns a classifier configuration applying the ruleset
+func (rs Ruleset) Config() classifier.Config {
+	return classifier.NewConfig(
+		classifier.WithThreshold(rs.Policy.Threshold),
+		classifier.WithRules(rs.Rules()),
+	)
+}
+
+// decodeStrict decodes a single YAML document, failing on unknown keys.
+// An empty document decodes to the zero value.
+func decodeStrict(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
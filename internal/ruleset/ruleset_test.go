@@ -0,0 +1,13 @@
+package ruleset
+
+import "testing"
+
+// Tests are in tests/unit/ruleset_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestRulesetPackage(t *testing.T) {
+	// Verify package is testable
+	if got := TestFile("rules/site.yaml"); got != "rules/site_test.yaml" {
+		t.Errorf("TestFile() = %q, want rules/site_test.yaml", got)
+	}
+}
@@ -0,0 +1,169 @@
+package ruleset
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// TestCase is a declarative expectation for a single request
+type TestCase struct {
+	Name    string            `yaml:"name"`
+	Method  string            `yaml:"method"`  // Default GET
+	Proto   string            `yaml:"proto"`   // Default HTTP/1.1
+	Path    string            `yaml:"path"`    // Default /
+	Headers map[string]string `yaml:"headers"` // Request headers
+	Expect  string            `yaml:"expect"`  // browser or bot
+	Fires   []string          `yaml:"fires"`   // Rules that must appear in the breakdown
+}
+
+// testFile is the layout of a _test.yaml file
+type testFile struct {
+	Tests []TestCase `yaml:"tests"`
+}
+
+// TestResult is the outcome of running one test case
+type TestResult struct {
+	Name      string                `json:"name"`
+	Passed    bool                  `json:"passed"`
+	Expected  string                `json:"expected"`
+	Got       string                `json:"got"`
+	Score     int                   `json:"score"`
+	Missing   []string              `json:"missing,omitempty"` // Expected rules that did not fire
+	Breakdown fingerprint.Breakdown `json:"breakdown"`
+	Error     string                `json:"error,omitempty"`
+}
+
+// TestFile returns the test case file belonging to a ruleset file,
+// e.g. rules/site.yaml -> rules/site_test.yaml
+func TestFile(rulesetPath string) string {
+	for _, ext := range []string{".yaml", ".yml"} {
+		if base, ok := strings.CutSuffix(rulesetPath, ext); ok {
+			return base + "_test" + ext
+		}
+	}
+	return rulesetPath + "_test.yaml"
+}
+
+// ParseTests decodes test cases, rejecting unknown keys
+func ParseTests(data []byte) ([]TestCase, error) {
+	var f testFile
+	if err := decodeStrict(data, &f); err != nil {
+		return nil, err
+	}
+	return f.Tests, nil
+}
+
+// LoadTests reads and parses a test case file
+func LoadTests(path string) ([]TestCase, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	cases, err := ParseTests(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cases, nil
+}
+
+// LintTests checks test cases for missing names, unknown expectations and
+// unknown rule names, knowing only the built-in rules
+func LintTests(cases []TestCase) []Issue {
+	return Ruleset{}.LintTests(cases)
+}
+
+// LintTests checks test cases against the built-in rules and the rules the
+// ruleset defines
+func (rs Ruleset) LintTests(cases []TestCase) []Issue {
+	rules := rs.Rules()
+	var issues []Issue
+	names := map[string]bool{}
+	for i, tc := range cases {
+		path := fmt.Sprintf("tests[%d]", i)
+		if tc.Name == "" {
+			issues = append(issues, Issue{SeverityError, path, "missing name"})
+		} else if names[tc.Name] {
+			issues = append(issues, Issue{SeverityWarning, path, fmt.Sprintf("duplicate name %q", tc.Name)})
+		}
+		names[tc.Name] = true
+
+		if !dataset.ValidLabel(tc.Expect) {
+			issues = append(issues, Issue{SeverityError, path + ".expect",
+				fmt.Sprintf("expect must be %q or %q, got %q", classifier.ClassificationBrowser, classifier.ClassificationBot, tc.Expect)})
+		}
+		for j, name := range tc.Fires {
+			if _, ok := rules.Lookup(name); !ok {
+				issues = append(issues, Issue{SeverityError, fmt.Sprintf("%s.fires[%d]", path, j), fmt.Sprintf("unknown rule %q", name)})
+			}
+		}
+	}
+	return issues
+}
+
+// Run classifies every test case with cfg and compares the outcome
+// against its expectations
+func Run(cfg classifier.Config, cases []TestCase) []TestResult {
+	c := classifier.New(cfg)
+	collector := fingerprint.NewCollector()
+
+	results := make([]TestResult, 0, len(cases))
+	for _, tc := range cases {
+		res := TestResult{Name: tc.Name, Expected: tc.Expect}
+
+		req, err := tc.request()
+		if err != nil {
+			res.Error = err.Error()
+			results = append(results, res)
+			continue
+		}
+
+		result := c.Classify(collector.Collect(req))
+		res.Got = result.Classification
+		res.Score = result.Score
+		res.Breakdown = result.Signals.ScoreBreakdown
+
+		browser, bot := result.Signals.ScoreBreakdown.Rules()
+		for _, name := range tc.Fires {
+			if !slices.Contains(browser, name) && !slices.Contains(bot, name) {
+				res.Missing = append(res.Missing, name)
+			}
+		}
+		res.Passed = res.Got == tc.Expect && len(res.Missing) == 0
+		results = append(results, res)
+	}
+	return results
+}
+
+// request builds the HTTP request described by the test case
+func (tc TestCase) request() (*http.Request, error) {
+	headers := make(map[string][]string, len(tc.Headers))
+	for name, value := range tc.Headers {
+		headers[name] = []string{value}
+	}
+	meta := fingerprint.RequestMetadata{
+		Method:  tc.Method,
+		Proto:   tc.Proto,
+		Path:    tc.Path,
+		Headers: headers,
+	}
+	return meta.HTTPRequest(context.Background())
+}
+
+// sortedKeys returns the keys of m in lexical order
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
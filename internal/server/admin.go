@@ -0,0 +1,73 @@
+package server
+
+import (
+	"crypto/subtle"
+	"net/http"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/capture"
+)
+
+// CaptureUpdate changes capture settings; omitted fields are unchanged
+type CaptureUpdate struct {
+	Enabled    *bool    `json:"enabled,omitempty"`
+	SampleRate *float64 `json:"sample_rate,omitempty"`
+	Raw        *bool    `json:"raw,omitempty"`
+}
+
+// SetCapture samples classified requests into a capture file
+func (h *Handler) SetCapture(c *capture.Capturer) {
+	h.capture = c
+}
+
+// SetAdminToken enables the admin endpoints for requests carrying
+// Authorization: Bearer <token> (empty disables them)
+func (h *Handler) SetAdminToken(token string) {
+	h.adminToken = token
+}
+
+// authorizeAdmin checks the admin bearer token, responding with a 401
+// problem and reporting false when it is missing or wrong
+func (h *Handler) authorizeAdmin(w http.ResponseWriter, r *http.Request) bool {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if ok && h.adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(h.adminToken)) == 1 {
+		return true
+	}
+	w.Header().Set("WWW-Authenticate", `Bearer realm="admin"`)
+	writeProblem(w, r, http.StatusUnauthorized, CodeUnauthorized, "a valid admin bearer token is required")
+	return false
+}
+
+// HandleCapture reports (GET) or changes (PUT) the capture settings
+func (h *Handler) HandleCapture(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var u CaptureUpdate
+		if err := decodeJSONBody(w, r, &u); err != nil {
+			badBody(w, r, err)
+			return
+		}
+		if u.SampleRate != nil {
+			if err := h.capture.SetSampleRate(*u.SampleRate); err != nil {
+				writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+				return
+			}
+		}
+		if u.Raw != nil {
+			h.capture.SetRaw(*u.Raw)
+		}
+		if u.Enabled != nil {
+			h.capture.SetEnabled(*u.Enabled)
+		}
+	default:
+		methodNotAllowed(w, r, "GET, PUT")
+		return
+	}
+
+	writeJSON(w, h.capture.State())
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"bytes"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+)
+
+// captchaPath is the CAPTCHA page and its verification callback
+const captchaPath = "/challenge"
+
+// maxCaptchaFormBytes bounds the verification form body
+const maxCaptchaFormBytes = 64 << 10
+
+// SetCaptcha redirects bots navigating to a page to a CAPTCHA and
+// remembers the sessions that solve it in store
+func (h *Handler) SetCaptcha(v *captcha.Verifier, store *captcha.Store) {
+	h.captcha = v
+	h.verified = store
+}
+
+// captchaVerified reports whether the session of r solved the CAPTCHA
+func (h *Handler) captchaVerified(r *http.Request) bool {
+	return h.verified != nil && h.verified.Verified(h.sessionKey(r), time.Now())
+}
+
+// redirectToCaptcha sends page navigations to the CAPTCHA page and reports
+// whether it did; API clients asking for JSON get the usual response
+func (h *Handler) redirectToCaptcha(w http.ResponseWriter, r *http.Request) bool {
+	if h.captcha == nil || r.Method != http.MethodGet || !strings.Contains(r.Header.Get("Accept"), "text/html") {
+		return false
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	http.Redirect(w, r, captchaPath+"?return="+url.QueryEscape(r.URL.RequestURI()), http.StatusSeeOther)
+	return true
+}
+
+// HandleCaptcha renders the CAPTCHA page (GET) and validates the widget
+// response with the provider (POST). Clients that pass are marked verified
+// and, when challenge tokens are enabled, handed one before being sent back.
+func (h *Handler) HandleCaptcha(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		h.renderCaptcha(w, r, http.StatusOK, r.URL.Query().Get("return"), "")
+	case http.MethodPost:
+		r.Body = http.MaxBytesReader(w, r.Body, maxCaptchaFormBytes)
+		if err := r.ParseForm(); err != nil {
+			writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, "invalid form body")
+			return
+		}
+		returnTo := r.PostForm.Get("return")
+
+		// The provider may check the client address; it is withheld when
+		// addresses are anonymized
+		remoteIP := ""
+		if h.anon == nil {
+			if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+				remoteIP = host
+			}
+		}
+		if err := h.captcha.Verify(r.Context(), r.PostForm.Get(h.captcha.ResponseField()), remoteIP); err != nil {
+			if !h.quiet {
+				log.Printf("[%s] CAPTCHA verification failed: %v", h.clientAddr(r.RemoteAddr), err)
+			}
+			h.renderCaptcha(w, r, http.StatusForbidden, returnTo, "The check was not completed. Please try again.")
+			return
+		}
+
+		h.verified.MarkVerified(h.sessionKey(r), time.Now())
+		h.issueChallengeToken(w, h.collector.Collect(r))
+		w.Header().Set("Cache-Control", "no-store")
+		http.Redirect(w, r, captcha.SafeReturn(returnTo), http.StatusSeeOther)
+	default:
+		methodNotAllowed(w, r, "GET, POST")
+	}
+}
+
+// renderCaptcha writes the CAPTCHA page with the given status
+func (h *Handler) renderCaptcha(w http.ResponseWriter, r *http.Request, status int, returnTo, errMsg string) {
+	var buf bytes.Buffer
+	if err := h.captcha.RenderPage(&buf, r.URL.Path, returnTo, errMsg); err != nil {
+		log.Printf("Error rendering CAPTCHA page: %v", err)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "failed to render the CAPTCHA page")
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		log.Printf("Error writing CAPTCHA page: %v", err)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/muliwe/go-client-classifier/internal/events"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+// CrawlersResponse lists the crawlers the detector recognizes
+type CrawlersResponse struct {
+	Crawlers []CrawlerEntry `json:"crawlers"`
+	Tenant   string         `json:"tenant,omitempty"` // Tenant whose rules were used (empty = server defaults)
+}
+
+// CrawlerEntry is a recognized crawler and how the server treats it
+type CrawlerEntry struct {
+	fingerprint.RecognizedCrawler
+	Policy CrawlerPolicy `json:"policy"`
+}
+
+// CrawlerPolicy is what the server currently does with a crawler's
+// requests classified as bot
+type CrawlerPolicy struct {
+	CrawlDelaySeconds float64 `json:"crawl_delay_seconds,omitempty"` // Enforced robots.txt Crawl-delay
+	ContentSignal     string  `json:"content_signal,omitempty"`      // robots.txt Content-Signal that applies
+	Challenge         string  `json:"challenge,omitempty"`           // captcha or private_token
+}
+
+// crawlerPolicy returns the current policy for a crawler's product token
+func (h *Handler) crawlerPolicy(name string) CrawlerPolicy {
+	var p CrawlerPolicy
+	if h.crawl != nil {
+		if _, d, ok := h.crawl.Crawler(name); ok {
+			p.CrawlDelaySeconds = d.Seconds()
+		}
+		p.ContentSignal = h.crawl.ContentSignal(name)
+	}
+	switch {
+	case h.captcha != nil:
+		p.Challenge = events.ActionCaptcha
+	case h.tokens != nil:
+		p.Challenge = events.ActionPrivateToken
+	}
+	return p
+}
+
+// HandleCrawlers lists the known crawlers that the rules of the request's
+// tenant recognize, with their current policy
+func (h *Handler) HandleCrawlers(w http.ResponseWriter, r *http.Request) {
+	sc, err := h.statsScope(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnknownAPIKey, "the "+tenant.APIKeyHeader+" header does not match any tenant")
+		return
+	}
+	resp := CrawlersResponse{Crawlers: []CrawlerEntry{}, Tenant: sc.tenant}
+	for _, c := range sc.classifier.Rules().Crawlers() {
+		resp.Crawlers = append(resp.Crawlers, CrawlerEntry{RecognizedCrawler: c, Policy: h.crawlerPolicy(c.Name)})
+	}
+
+	w.Header()["Content-Type"] = jsonContentType
+	if err := encodeJSON(w, resp); err != nil {
+		log.Printf("Error encoding crawlers response: %v", err)
+	}
+}
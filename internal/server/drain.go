@@ -0,0 +1,160 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// drainProgressInterval is how often a drain logs what it is waiting for
+const drainProgressInterval = time.Second
+
+// drainBody is the health check response while the server drains
+var drainBody = mustEncode(HealthResponse{Status: "draining", Version: version})
+
+// drainState accounts for the requests and connections a shutdown waits for
+type drainState struct {
+	draining atomic.Bool
+	inFlight atomic.Int64 // HTTP requests being handled
+	conns    atomic.Int64 // Open HTTP connections
+}
+
+// track counts the requests being handled by next
+func (d *drainState) track(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		d.inFlight.Add(1)
+		defer d.inFlight.Add(-1)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// connState counts open connections; it is an http.Server ConnState hook
+func (d *drainState) connState(_ net.Conn, state http.ConnState) {
+	switch state {
+	case http.StateNew:
+		d.conns.Add(1)
+	case http.StateHijacked, http.StateClosed:
+		d.conns.Add(-1)
+	}
+}
+
+// StartDrain makes health checks report draining, so load balancers stop
+// routing new requests here, and ends live streams
+func (h *Handler) StartDrain() {
+	if h.drain.draining.Swap(true) {
+		return
+	}
+	if h.stream != nil {
+		h.stream.close()
+	}
+}
+
+// Draining reports whether StartDrain was called
+func (h *Handler) Draining() bool {
+	return h.drain.draining.Load()
+}
+
+// InFlight returns the number of HTTP requests being handled
+func (h *Handler) InFlight() int64 {
+	return h.drain.inFlight.Load()
+}
+
+// Shutdown drains the server: health checks report draining for
+// DrainDelay, then every listener stops accepting and in-flight requests
+// finish. Connections still busy when ctx ends are force-closed. Event
+// sinks, the capture file and logs are flushed either way; the error
+// reports a forced close.
+func (s *Server) Shutdown(ctx context.Context) error {
+	s.handler.StartDrain()
+	if d := s.cfg.DrainDelay; d > 0 {
+		log.Printf("Draining: reporting unhealthy for %s before closing listeners", d)
+		select {
+		case <-time.After(d):
+		case <-ctx.Done():
+		}
+	}
+
+	// Stop accepting on all listeners; idle connections close at once and
+	// busy ones after their request
+	httpDone := make(chan error, 1)
+	go func() { httpDone <- s.shutdownHTTP(ctx) }()
+	grpcDone := make(chan struct{})
+	if s.grpcServer != nil {
+		go func() {
+			s.grpcServer.GracefulStop()
+			close(grpcDone)
+		}()
+	} else {
+		close(grpcDone)
+	}
+
+	forced := s.waitDrained(ctx, httpDone, grpcDone)
+	// Closed only now, so Serve sees the shutdown and returns
+	// http.ErrServerClosed
+	if s.listener != nil {
+		_ = s.listener.Close()
+	}
+	if s.quicConn != nil {
+		_ = s.quicConn.Close()
+	}
+
+	log.Printf("Draining: flushing event sinks, capture and logs")
+	closeEvents(s.cfg.Events)
+	closeLoggers(s.tenantLogs)
+	closeCapture(s.capture)
+	if err := s.logger.Close(); err != nil {
+		log.Printf("Error closing logger: %v", err)
+	}
+
+	if forced {
+		return errors.New("shutdown deadline exceeded: stragglers were force-closed")
+	}
+	return nil
+}
+
+// waitDrained waits for the HTTP and gRPC servers to finish their
+// requests, logging progress, and force-closes them when ctx ends. It
+// reports whether it had to.
+func (s *Server) waitDrained(ctx context.Context, httpDone <-chan error, grpcDone <-chan struct{}) bool {
+	ticker := time.NewTicker(drainProgressInterval)
+	defer ticker.Stop()
+
+	forced := false
+	forceClose := func() {
+		if forced {
+			return
+		}
+		forced = true
+		log.Printf("Draining: deadline reached, force-closing %d connections with %d requests in flight",
+			s.handler.drain.conns.Load(), s.handler.InFlight())
+		_ = s.httpServer.Close()
+		if s.grpcServer != nil {
+			s.grpcServer.Stop()
+		}
+	}
+
+	deadline := ctx.Done()
+	for httpDone != nil || grpcDone != nil {
+		select {
+		case err := <-httpDone:
+			// Shutdown gives up at the deadline without closing busy
+			// connections
+			if err != nil {
+				forceClose()
+			}
+			httpDone = nil
+		case <-grpcDone:
+			grpcDone = nil
+		case <-ticker.C:
+			log.Printf("Draining: %d requests in flight on %d connections", s.handler.InFlight(), s.handler.drain.conns.Load())
+		case <-deadline:
+			forceClose()
+			deadline = nil
+		}
+	}
+	return forced
+}
@@ -0,0 +1,222 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+// Shared Content-Type header values, assigned directly to the header map
+// to skip the per-request slice allocation of Header.Set
+var (
+	jsonContentType    = []string{"application/json"}
+	problemContentType = []string{"application/problem+json"}
+)
+
+// healthBody is the pre-rendered health check response
+var healthBody = mustEncode(HealthResponse{Status: "ok", Version: version})
+
+// jsonEncoder is a json.Encoder writing into its own buffer, pooled so
+// responses do not allocate a new encoder and buffer per request
+type jsonEncoder struct {
+	buf bytes.Buffer
+	enc *json.Encoder
+}
+
+var encoderPool = sync.Pool{
+	New: func() any {
+		e := &jsonEncoder{}
+		e.enc = json.NewEncoder(&e.buf)
+		return e
+	},
+}
+
+// maxPooledBuffer is the largest buffer returned to the pools; bigger ones
+// (e.g. a large batch response) are left to the garbage collector
+const maxPooledBuffer = 64 << 10
+
+// encodeJSON writes v to w as JSON followed by a newline, as
+// json.NewEncoder(w).Encode(v) does, with a pooled encoder
+func encodeJSON(w http.ResponseWriter, v any) error {
+	e := encoderPool.Get().(*jsonEncoder)
+	defer func() {
+		if e.buf.Cap() <= maxPooledBuffer {
+			e.buf.Reset()
+			encoderPool.Put(e)
+		}
+	}()
+
+	if err := e.enc.Encode(v); err != nil {
+		return err
+	}
+	_, err := w.Write(e.buf.Bytes())
+	return err
+}
+
+// bufPool holds buffers for responses with hand-written marshalers
+var bufPool = sync.Pool{
+	New: func() any {
+		b := make([]byte, 0, 512)
+		return &b
+	},
+}
+
+// writeResponse writes the classification response body with a pooled
+// buffer
+func writeResponse(w http.ResponseWriter, resp Response) error {
+	bp := bufPool.Get().(*[]byte)
+	b := append(resp.AppendJSON((*bp)[:0]), '\n')
+	_, err := w.Write(b)
+	if cap(b) <= maxPooledBuffer {
+		*bp = b
+		bufPool.Put(bp)
+	}
+	return err
+}
+
+// mustEncode renders a static response body at startup
+func mustEncode(v any) []byte {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	return append(b, '\n')
+}
+
+// MarshalJSON encodes the response without reflection
+func (r Response) MarshalJSON() ([]byte, error) {
+	return r.AppendJSON(nil), nil
+}
+
+// AppendJSON appends the JSON encoding of the response to dst, byte for
+// byte as encoding/json renders it, without allocating when dst has room.
+// NaN and infinite confidences, which encoding/json rejects, are written
+// as 0.
+func (r Response) AppendJSON(dst []byte) []byte {
+	dst = append(dst, `{"classification":`...)
+	dst = appendJSONString(dst, r.Classification)
+	dst = append(dst, `,"confidence":`...)
+	dst = appendJSONFloat(dst, r.Confidence)
+	dst = append(dst, `,"message":`...)
+	dst = appendJSONString(dst, r.Message)
+	dst = append(dst, `,"request_id":`...)
+	dst = appendJSONString(dst, r.RequestID)
+	dst = append(dst, `,"timestamp":"`...)
+	dst = r.Timestamp.AppendFormat(dst, time.RFC3339Nano)
+	dst = append(dst, `","version":`...)
+	dst = appendJSONString(dst, r.Version)
+	if r.Class != "" {
+		dst = append(dst, `,"class":`...)
+		dst = appendJSONString(dst, r.Class)
+	}
+	if len(r.ClassConfidence) > 0 {
+		dst = append(dst, `,"class_confidence":`...)
+		dst = appendJSONFloatMap(dst, r.ClassConfidence)
+	}
+	return append(dst, '}')
+}
+
+// appendJSONFloatMap appends m as a JSON object with its keys sorted, as
+// encoding/json orders map keys. Maps of up to 8 keys, such as the class
+// confidences, are sorted without allocating.
+func appendJSONFloatMap(dst []byte, m map[string]float64) []byte {
+	var small [8]string
+	keys := small[:0]
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+
+	dst = append(dst, '{')
+	for i, k := range keys {
+		if i > 0 {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, k)
+		dst = append(dst, ':')
+		dst = appendJSONFloat(dst, m[k])
+	}
+	return append(dst, '}')
+}
+
+// appendJSONFloat appends f formatted as encoding/json formats float64s
+func appendJSONFloat(dst []byte, f float64) []byte {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return append(dst, '0')
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, 64)
+	if format == 'e' {
+		// Shorten e-09 to e-9, as encoding/json does
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// appendJSONString appends s as a quoted JSON string with the escaping of
+// encoding/json: HTML characters, U+2028 and U+2029 are escaped and
+// invalid UTF-8 is replaced with U+FFFD
+func appendJSONString(dst []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+
+	dst = append(dst, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		if c := s[i]; c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' && c != '<' && c != '>' && c != '&' {
+				i++
+				continue
+			}
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = utf8.AppendRune(dst, utf8.RuneError)
+			i += size
+			start = i
+			continue
+		}
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hex[r&0xF])
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	dst = append(dst, s[start:]...)
+	return append(dst, '"')
+}
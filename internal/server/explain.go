@@ -0,0 +1,191 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// ExplainResponse explains a logged classification decision
+type ExplainResponse struct {
+	RequestID      string                  `json:"request_id"`
+	Timestamp      time.Time               `json:"timestamp"`
+	Tenant         string                  `json:"tenant,omitempty"`
+	Classification string                  `json:"classification"`
+	Confidence     float64                 `json:"confidence"`
+	Score          int                     `json:"score"`
+	Reason         string                  `json:"reason"`
+	BrowserRules   []fingerprint.RuleScore `json:"browser_rules"` // Fired rules with the weights then in effect
+	BotRules       []fingerprint.RuleScore `json:"bot_rules"`
+	Patterns       MatchedPatterns         `json:"matched_patterns"`
+	Policy         *CrawlerPolicy          `json:"policy,omitempty"` // Current policy for bots (bot decisions only)
+	Explanation    string                  `json:"explanation"`      // The above as text
+}
+
+// MatchedPatterns lists the User-Agent patterns a request matched
+type MatchedPatterns struct {
+	Bot       []string `json:"bot,omitempty"`
+	AICrawler []string `json:"ai_crawler,omitempty"`
+	Browser   []string `json:"browser,omitempty"`
+}
+
+// errNotLogged is returned when the scope writes no decision log
+var errNotLogged = errors.New("decisions are not logged")
+
+// HandleExplain explains the logged decision for the request ID in the
+// path. A tenant's API key searches that tenant's log and the admin token
+// the default log. ?format=text returns only the text explanation.
+func (h *Handler) HandleExplain(w http.ResponseWriter, r *http.Request) {
+	sc, ok := h.keyScope(w, r)
+	if !ok {
+		return
+	}
+	id := r.PathValue("request_id")
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "json" && format != "text" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "format must be json or text")
+		return
+	}
+
+	entry, found, err := findDecision(sc.logger, id)
+	switch {
+	case errors.Is(err, errNotLogged):
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, "decisions are not logged, so none can be explained")
+		return
+	case err != nil:
+		log.Printf("Error reading decision log: %v", err)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "the decision log could not be read")
+		return
+	case !found:
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, "no logged decision has request ID "+strconv.Quote(id))
+		return
+	}
+
+	resp := h.explain(entry)
+	if format == "text" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, resp.Explanation)
+		return
+	}
+	writeJSON(w, resp)
+}
+
+// findDecision returns the last entry of l's log with the request ID
+func findDecision(l *logger.Logger, id string) (logger.LogEntry, bool, error) {
+	if l == nil || l.LogPath() == "" {
+		return logger.LogEntry{}, false, errNotLogged
+	}
+	return logger.FindEntry(l.LogPath(), id)
+}
+
+// explain builds the explanation of a logged decision
+func (h *Handler) explain(e logger.LogEntry) ExplainResponse {
+	s := e.Signals
+	resp := ExplainResponse{
+		RequestID:      e.RequestID,
+		Timestamp:      e.Timestamp,
+		Tenant:         e.Tenant,
+		Classification: e.Classification,
+		Confidence:     e.Confidence,
+		Score:          e.Score,
+		Reason:         e.Reason,
+		Patterns: MatchedPatterns{
+			Bot:       s.MatchedBotPatterns,
+			AICrawler: s.MatchedAICrawlerPatterns,
+			Browser:   s.MatchedBrowserPatterns,
+		},
+	}
+	resp.BrowserRules, resp.BotRules = s.ScoreBreakdown.Scores()
+	if resp.BrowserRules == nil {
+		resp.BrowserRules = []fingerprint.RuleScore{}
+	}
+	if resp.BotRules == nil {
+		resp.BotRules = []fingerprint.RuleScore{}
+	}
+	if e.Classification == classifier.ClassificationBot {
+		p := h.crawlerPolicy(e.Fingerprint.HTTP.UserAgent)
+		resp.Policy = &p
+	}
+	resp.Explanation = explanationText(resp)
+	return resp
+}
+
+// explanationText renders an explanation for support responses
+func explanationText(e ExplainResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Request %s at %s was classified as %s with confidence %.2f (net score %+d).\n",
+		e.RequestID, e.Timestamp.UTC().Format(time.RFC3339), e.Classification, e.Confidence, e.Score)
+	if e.Reason != "" {
+		fmt.Fprintf(&b, "Reason: %s.\n", e.Reason)
+	}
+	fmt.Fprintf(&b, "Browser evidence: %s.\n", ruleList(e.BrowserRules))
+	fmt.Fprintf(&b, "Bot evidence: %s.\n", ruleList(e.BotRules))
+	for _, p := range []struct {
+		kind     string
+		patterns []string
+	}{
+		{"bot", e.Patterns.Bot},
+		{"AI crawler", e.Patterns.AICrawler},
+		{"browser", e.Patterns.Browser},
+	} {
+		if len(p.patterns) > 0 {
+			fmt.Fprintf(&b, "User-Agent matched %s patterns:%s.\n", p.kind, quoteList(p.patterns))
+		}
+	}
+	if e.Policy != nil {
+		fmt.Fprintf(&b, "Current policy for this client as a bot: %s.\n", policyText(*e.Policy))
+	}
+	return b.String()
+}
+
+// ruleList renders fired rules as "name (+w), ...", or "none"
+func ruleList(rules []fingerprint.RuleScore) string {
+	if len(rules) == 0 {
+		return "none"
+	}
+	parts := make([]string, len(rules))
+	for i, rs := range rules {
+		parts[i] = fmt.Sprintf("%s (%+d)", rs.Name, rs.Weight)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// quoteList renders patterns as ` "a", "b"`
+func quoteList(patterns []string) string {
+	var b strings.Builder
+	for i, p := range patterns {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteByte(' ')
+		b.WriteString(strconv.Quote(p))
+	}
+	return b.String()
+}
+
+// policyText renders a crawler policy, or "allowed" when nothing applies
+func policyText(p CrawlerPolicy) string {
+	var parts []string
+	if p.Challenge != "" {
+		parts = append(parts, p.Challenge+" challenge")
+	}
+	if p.CrawlDelaySeconds > 0 {
+		parts = append(parts, "crawl delay "+strconv.FormatFloat(p.CrawlDelaySeconds, 'f', -1, 64)+"s")
+	}
+	if p.ContentSignal != "" {
+		parts = append(parts, "content signal "+p.ContentSignal)
+	}
+	if len(parts) == 0 {
+		return "allowed"
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,86 @@
+package server
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+// FeedbackRequest corrects the classification of a logged request
+type FeedbackRequest struct {
+	RequestID string `json:"request_id"`
+	Label     string `json:"label"` // Correct classification: browser or bot
+	Note      string `json:"note,omitempty"`
+}
+
+// FeedbackResponse reports a recorded correction
+type FeedbackResponse struct {
+	RequestID string `json:"request_id"`
+	Label     string `json:"label"`
+	classifier.FeedbackResult
+}
+
+// HandleFeedback records an operator's correction of a logged decision,
+// found in the log of the request's tenant
+func (h *Handler) HandleFeedback(w http.ResponseWriter, r *http.Request) {
+	if !h.authorizeAdmin(w, r) {
+		return
+	}
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, "POST")
+		return
+	}
+	var req FeedbackRequest
+	if err := decodeJSONBody(w, r, &req); err != nil {
+		badBody(w, r, err)
+		return
+	}
+	if req.RequestID == "" {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "request_id is required")
+		return
+	}
+	if req.Label != classifier.ClassificationBrowser && req.Label != classifier.ClassificationBot {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "label must be browser or bot")
+		return
+	}
+	sc, err := h.statsScope(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnknownAPIKey, "the "+tenant.APIKeyHeader+" header does not match any tenant")
+		return
+	}
+
+	entry, found, err := findDecision(sc.logger, req.RequestID)
+	switch {
+	case errors.Is(err, errNotLogged):
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, "decisions are not logged, so none can be corrected")
+		return
+	case err != nil:
+		log.Printf("Error reading decision log: %v", err)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "the decision log could not be read")
+		return
+	case !found:
+		writeProblem(w, r, http.StatusNotFound, CodeNotFound, "no logged decision has request ID "+strconv.Quote(req.RequestID))
+		return
+	}
+
+	res, err := sc.classifier.Feedback(classifier.Feedback{
+		RequestID:   req.RequestID,
+		Label:       req.Label,
+		Fingerprint: entry.Fingerprint,
+		Predicted:   entry.Classification,
+		Note:        req.Note,
+	})
+	if err != nil {
+		log.Printf("Error recording feedback: %v", err)
+		writeProblem(w, r, http.StatusInternalServerError, CodeInternal, "the feedback could not be recorded")
+		return
+	}
+	if len(res.Adjusted) > 0 && !h.quiet {
+		log.Printf("Feedback on %s (%s, was %s) lowered rule weights: %v", req.RequestID, req.Label, res.Predicted, res.Adjusted)
+	}
+	writeJSON(w, FeedbackResponse{RequestID: req.RequestID, Label: req.Label, FeedbackResult: res})
+}
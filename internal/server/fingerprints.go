@@ -0,0 +1,74 @@
+package server
+
+import (
+	"context"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/muliwe/go-client-classifier/internal/asn"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// SetFingerprints replaces the known JA3/JA4 fingerprints of the default
+// classifier and of every tenant's
+func (h *Handler) SetFingerprints(db *fingerprint.FingerprintDB) {
+	h.classifier.SetFingerprints(db)
+	if h.tenants != nil {
+		for _, t := range h.tenants.Tenants() {
+			t.Classifier.SetFingerprints(db)
+		}
+	}
+}
+
+// ReloadFingerprints reads the FingerprintDB file again and applies it.
+// On error the fingerprints in use are kept.
+func (s *Server) ReloadFingerprints() error {
+	if s.cfg.FingerprintDB == "" {
+		return nil
+	}
+	db, err := fingerprint.LoadFingerprintDB(s.cfg.FingerprintDB)
+	if err != nil {
+		return err
+	}
+	s.handler.SetFingerprints(db)
+	log.Printf("Known fingerprints: %d (%s)", db.Len(), s.cfg.FingerprintDB)
+	return nil
+}
+
+// ReloadHostingASNs reads the HostingASNs file again and applies it. On
+// error the list in use is kept.
+func (s *Server) ReloadHostingASNs() error {
+	if s.cfg.HostingASNs == "" || s.handler.networks == nil {
+		return nil
+	}
+	hosting, err := asn.LoadHosting(s.cfg.HostingASNs)
+	if err != nil {
+		return err
+	}
+	s.handler.networks.SetHosting(hosting)
+	log.Printf("Hosting ASNs: %d (%s)", hosting.Len(), s.cfg.HostingASNs)
+	return nil
+}
+
+// reloadOnHangup reloads the fingerprint database and hosting ASNs on
+// SIGHUP until ctx ends
+func (s *Server) reloadOnHangup(ctx context.Context) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			if err := s.ReloadFingerprints(); err != nil {
+				log.Printf("Failed to reload known fingerprints, keeping the previous ones: %v", err)
+			}
+			if err := s.ReloadHostingASNs(); err != nil {
+				log.Printf("Failed to reload hosting ASNs, keeping the previous ones: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+	"github.com/muliwe/go-client-classifier/pkg/pb/classifierv1"
+)
+
+// maxStreamInFlight limits concurrently classified messages per stream
+const maxStreamInFlight = 64
+
+// GRPCService implements the classifier.v1.ClassifierService gRPC API
+// on top of the HTTP handler's collector, classifier and logger
+type GRPCService struct {
+	classifierv1.UnimplementedClassifierServiceServer
+	handler *Handler
+}
+
+// NewGRPCService creates a gRPC service backed by the handler
+func NewGRPCService(h *Handler) *GRPCService {
+	return &GRPCService{handler: h}
+}
+
+// Register registers the service on a gRPC server
+func (g *GRPCService) Register(s grpc.ServiceRegistrar) {
+	classifierv1.RegisterClassifierServiceServer(s, g)
+}
+
+// Classify classifies a single request or fingerprint
+func (g *GRPCService) Classify(ctx context.Context, req *classifierv1.ClassifyRequest) (*classifierv1.ClassifyResponse, error) {
+	sc, err := g.scope(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var result fingerprint.ClassificationResult
+
+	switch in := req.GetInput().(type) {
+	case *classifierv1.ClassifyRequest_Request:
+		result, err = g.classifyMetadata(ctx, sc, in.Request)
+	case *classifierv1.ClassifyRequest_Fingerprint:
+		result = g.handler.classifyFingerprint(sc, classifierv1.ToFingerprint(in.Fingerprint), time.Now())
+	default:
+		err = errors.New("request or fingerprint is required")
+	}
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return &classifierv1.ClassifyResponse{Result: classifierv1.FromResult(result)}, nil
+}
+
+// ClassifyStream classifies streamed requests concurrently and sends each
+// decision as soon as it is ready. Invalid messages are answered with an
+// error response; the stream stays open.
+func (g *GRPCService) ClassifyStream(stream grpc.BidiStreamingServer[classifierv1.ClassifyStreamRequest, classifierv1.ClassifyStreamResponse]) error {
+	ctx := stream.Context()
+
+	var (
+		sendMu  sync.Mutex
+		sendErr error
+		wg      sync.WaitGroup
+	)
+	send := func(resp *classifierv1.ClassifyStreamResponse) {
+		sendMu.Lock()
+		defer sendMu.Unlock()
+		if sendErr == nil {
+			sendErr = stream.Send(resp)
+		}
+	}
+
+	sem := make(chan struct{}, maxStreamInFlight)
+	defer wg.Wait()
+
+	for {
+		req, err := stream.Recv()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-sem }()
+			send(g.classifyStreamed(ctx, req))
+		}()
+	}
+}
+
+// classifyStreamed classifies a single streamed message
+func (g *GRPCService) classifyStreamed(ctx context.Context, req *classifierv1.ClassifyStreamRequest) *classifierv1.ClassifyStreamResponse {
+	resp := &classifierv1.ClassifyStreamResponse{Id: req.GetId()}
+
+	sc, err := g.scope(ctx)
+	if err != nil {
+		resp.Error = status.Convert(err).Message()
+		return resp
+	}
+
+	var result fingerprint.ClassificationResult
+
+	switch in := req.GetInput().(type) {
+	case *classifierv1.ClassifyStreamRequest_Request:
+		result, err = g.classifyMetadata(ctx, sc, in.Request)
+	case *classifierv1.ClassifyStreamRequest_Fingerprint:
+		result = g.handler.classifyFingerprint(sc, classifierv1.ToFingerprint(in.Fingerprint), time.Now())
+	default:
+		err = errors.New("request or fingerprint is required")
+	}
+	if err != nil {
+		resp.Error = err.Error()
+		return resp
+	}
+
+	resp.Result = classifierv1.FromResult(result)
+	return resp
+}
+
+// classifyMetadata classifies protobuf request metadata
+func (g *GRPCService) classifyMetadata(ctx context.Context, sc *scope, m *classifierv1.RequestMetadata) (fingerprint.ClassificationResult, error) {
+	return g.handler.classifyMetadata(ctx, sc, classifierv1.ToRequestMetadata(m), time.Now())
+}
+
+// scope resolves the caller's tenant from the x-api-key metadata key.
+// Calls without a key use the server defaults.
+func (g *GRPCService) scope(ctx context.Context) (*scope, error) {
+	h := g.handler
+	if h.tenants == nil {
+		return h.defaultScope(), nil
+	}
+	var t *tenant.Tenant
+	var key string
+	if keys := metadata.ValueFromIncomingContext(ctx, strings.ToLower(tenant.APIKeyHeader)); len(keys) > 0 {
+		key = keys[0]
+		if t = h.tenants.ByAPIKey(key); t == nil {
+			return nil, status.Error(codes.Unauthenticated, tenant.ErrUnknownAPIKey.Error())
+		}
+	}
+	sc, _, err := h.scopeFor(t, key)
+	if err != nil {
+		return nil, status.Error(codes.ResourceExhausted, err.Error())
+	}
+	return sc, nil
+}
@@ -3,16 +3,35 @@ package server
 import (
 	"encoding/json"
 	"log"
+	"net"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/muliwe/go-client-classifier/api"
+	"github.com/muliwe/go-client-classifier/internal/anonymize"
+	"github.com/muliwe/go-client-classifier/internal/asn"
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/capture"
+	"github.com/muliwe/go-client-classifier/internal/challenge"
 	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/crawlerverify"
+	"github.com/muliwe/go-client-classifier/internal/events"
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/geoip"
+	"github.com/muliwe/go-client-classifier/internal/honeypot"
+	"github.com/muliwe/go-client-classifier/internal/ipkey"
 	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/offender"
+	"github.com/muliwe/go-client-classifier/internal/privaterelay"
+	"github.com/muliwe/go-client-classifier/internal/privatetoken"
+	"github.com/muliwe/go-client-classifier/internal/rate"
+	"github.com/muliwe/go-client-classifier/internal/reputation"
+	"github.com/muliwe/go-client-classifier/internal/session"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
 )
 
-const version = "0.4.0"
-
 // Response represents the API response
 type Response struct {
 	Classification string    `json:"classification"`
@@ -21,6 +40,10 @@ type Response struct {
 	RequestID      string    `json:"request_id"`
 	Timestamp      time.Time `json:"timestamp"`
 	Version        string    `json:"version"`
+
+	// Refined client class and the confidence of every class
+	Class           string             `json:"class,omitempty"`
+	ClassConfidence map[string]float64 `json:"class_confidence,omitempty"`
 }
 
 // HealthResponse represents the health check response
@@ -34,7 +57,42 @@ type Handler struct {
 	collector  *fingerprint.Collector
 	classifier *classifier.Classifier
 	logger     *logger.Logger
+	sessions   *session.Tracker        // optional inter-request timing tracker
+	rates      *rate.Tracker           // optional per-address and per-JA4 request rate tracker
+	stream     *stream                 // optional live feed of log entries
+	tokens     *privatetoken.Verifier  // optional Private Access Token verifier
+	relay      *privaterelay.Ranges    // optional iCloud Private Relay egress ranges
+	crawlers   *crawlerverify.Verifier // optional verification of crawler User-Agents
+	geo        *geoip.Enricher         // optional GeoIP country lookup
+	networks   *asn.Enricher           // optional ASN and hosting provider lookup
+	reputation *reputation.Enricher    // optional VPN, proxy and Tor list lookup
+	challenges *challenge.Signer       // optional challenge token signer
+	enrichment classifier.Enrichment   // tokens, challenges and network lookups, run within their time budgets
+	captcha    *captcha.Verifier       // optional CAPTCHA for bots navigating to pages
+	verified   *captcha.Store          // sessions that solved the CAPTCHA
+	anon       *anonymize.Anonymizer   // optional client IP anonymizer for logs and session keys
+	ipKeys     *ipkey.Keyer            // optional subnet aggregation of session keys (per address when nil)
+	capture    *capture.Capturer       // optional traffic sampler for dataset building
+	crawl      *crawldelay.Pacer       // optional robots.txt Crawl-delay enforcement
+	traps      *honeypot.Traps         // optional honeypot trap paths
+	offenders  *offender.Store         // clients that requested a trap or were classified bot
+	recordBots bool                    // remember bot verdicts in offenders
+	aiHeaders  bool                    // emit the robots.txt Content-Signal as response headers
+	events     *events.Bus             // optional outbound event bus
+	adminToken string                  // bearer token for admin endpoints (empty = disabled)
+	tenants    *tenant.Registry        // optional tenant registry
+	scopes     map[string]*scope       // per-tenant classifiers, logs and stats by tenant ID
+	scoreHdr   string                  // response header for the 1-99 bot score (empty = disabled)
+	ruleset    string                  // version of the default ruleset (empty = built-in rules)
+	features   []string                // enabled optional features reported by /version
+	stats      *stats
+	metrics    *metrics
+	usage      *usage
+	hooks      Hooks
 	quiet      bool // suppress console logging (useful for tests)
+
+	// Requests and connections a graceful shutdown waits for
+	drain drainState
 }
 
 // NewHandler creates a new handler with dependencies
@@ -43,6 +101,9 @@ func NewHandler(c *fingerprint.Collector, cl *classifier.Classifier, l *logger.L
 		collector:  c,
 		classifier: cl,
 		logger:     l,
+		stats:      newStats(),
+		metrics:    newMetrics(),
+		usage:      newUsage(),
 		quiet:      false,
 	}
 }
@@ -52,31 +113,346 @@ func (h *Handler) SetQuiet(quiet bool) {
 	h.quiet = quiet
 }
 
-// HandleClassify handles the main classification endpoint
+// SetSessionTracker enables session timing signals using the given tracker
+func (h *Handler) SetSessionTracker(t *session.Tracker) {
+	h.sessions = t
+}
+
+// SetRateTracker enables request rate signals using the given tracker
+func (h *Handler) SetRateTracker(t *rate.Tracker) {
+	h.rates = t
+}
+
+// SetPrivateTokens enables Private Access Token challenges and verification
+func (h *Handler) SetPrivateTokens(v *privatetoken.Verifier) {
+	h.tokens = v
+	h.updateEnrichers()
+}
+
+// SetPrivateRelay marks requests from the given Private Relay egress ranges
+func (h *Handler) SetPrivateRelay(rg *privaterelay.Ranges) {
+	h.relay = rg
+	h.updateEnrichers()
+}
+
+// SetGeoIP looks up the country of client addresses with l (disabled when
+// nil)
+func (h *Handler) SetGeoIP(l geoip.Locator) {
+	h.geo = nil
+	if l != nil {
+		h.geo = geoip.NewEnricher(l)
+	}
+	h.updateEnrichers()
+}
+
+// SetASN looks up the autonomous system of client addresses with l and
+// marks those of the hosting providers listed (DefaultHosting when nil);
+// disabled when l is nil
+func (h *Handler) SetASN(l asn.Locator, hosting *asn.HostingList) {
+	h.networks = nil
+	if l != nil {
+		h.networks = asn.NewEnricher(l, hosting)
+	}
+	h.updateEnrichers()
+}
+
+// SetReputation looks up client addresses with an IP reputation provider,
+// caching the results (disabled when nil)
+func (h *Handler) SetReputation(p reputation.Provider) {
+	h.reputation = nil
+	if p != nil {
+		h.reputation = reputation.NewEnricher(p)
+	}
+	h.updateEnrichers()
+}
+
+// SetCrawlerVerifier checks the addresses of requests whose User-Agent
+// names a documented crawler
+func (h *Handler) SetCrawlerVerifier(v *crawlerverify.Verifier) {
+	h.crawlers = v
+	h.updateEnrichers()
+}
+
+// SetChallengeTokens issues signed challenge tokens to clients that pass a
+// challenge and verifies the tokens they present on later requests
+func (h *Handler) SetChallengeTokens(s *challenge.Signer) {
+	h.challenges = s
+	h.updateEnrichers()
+}
+
+// SetEnrichmentBudgets bounds the time spent verifying tokens and looking
+// up the client's network per request: timeout for all enrichers,
+// budgets for single ones by name. Enrichers out of time are skipped and
+// the result is marked partial.
+func (h *Handler) SetEnrichmentBudgets(timeout time.Duration, budgets map[string]time.Duration) {
+	h.enrichment.Timeout = timeout
+	h.enrichment.Budgets = budgets
+}
+
+// updateEnrichers lists the configured enrichers in the order they run
+func (h *Handler) updateEnrichers() {
+	var enrichers []classifier.Enricher
+	if h.tokens != nil {
+		enrichers = append(enrichers, h.tokens)
+	}
+	if h.challenges != nil {
+		enrichers = append(enrichers, h.challenges)
+	}
+	if h.relay != nil {
+		enrichers = append(enrichers, h.relay)
+	}
+	if h.geo != nil {
+		enrichers = append(enrichers, h.geo)
+	}
+	if h.networks != nil {
+		enrichers = append(enrichers, h.networks)
+	}
+	if h.reputation != nil {
+		enrichers = append(enrichers, h.reputation)
+	}
+	if h.crawlers != nil {
+		enrichers = append(enrichers, h.crawlers)
+	}
+	h.enrichment.Enrichers = enrichers
+}
+
+// issueChallengeToken hands a token to a client that just passed a
+// challenge, so it is not challenged again until the token expires
+func (h *Handler) issueChallengeToken(w http.ResponseWriter, fp fingerprint.Fingerprint) {
+	if h.challenges == nil {
+		return
+	}
+	http.SetCookie(w, h.challenges.Cookie(h.challenges.Issue(fp, time.Now())))
+}
+
+// SetIPAnonymizer anonymizes client addresses before they are logged,
+// streamed or used as session keys
+func (h *Handler) SetIPAnonymizer(a *anonymize.Anonymizer) {
+	h.anon = a
+}
+
+// SetIPKeyer aggregates per-client state such as session timing and
+// CAPTCHA verification at the keyer's subnet prefixes
+func (h *Handler) SetIPKeyer(k *ipkey.Keyer) {
+	h.ipKeys = k
+}
+
+// clientAddr returns the client address as it may be logged
+func (h *Handler) clientAddr(remoteAddr string) string {
+	if h.anon == nil {
+		return remoteAddr
+	}
+	return h.anon.Addr(remoteAddr)
+}
+
+// SetCrawlDelay paces crawlers by the Crawl-delay of their robots.txt
+// group and serves that robots.txt at /robots.txt
+func (h *Handler) SetCrawlDelay(p *crawldelay.Pacer) {
+	h.crawl = p
+}
+
+// SetAIPolicyHeaders emits the Content-Signal of the client's robots.txt
+// group, and the equivalent X-Robots-Tag, on classify responses. It needs
+// a robots.txt loaded with SetCrawlDelay.
+func (h *Handler) SetAIPolicyHeaders(enabled bool) {
+	h.aiHeaders = enabled
+}
+
+// setAIPolicy sets the AI usage policy headers for a User-Agent
+func (h *Handler) setAIPolicy(w http.ResponseWriter, userAgent string) {
+	if !h.aiHeaders || h.crawl == nil {
+		return
+	}
+	signal := h.crawl.ContentSignal(userAgent)
+	if signal == "" {
+		return
+	}
+	w.Header().Set("Content-Signal", signal)
+	if tag := crawldelay.RobotsTag(signal); tag != "" {
+		w.Header().Set("X-Robots-Tag", tag)
+	}
+}
+
+// SetBotScoreHeader emits the Cloudflare-compatible 1-99 bot score in the
+// named response header and the cf_bot_score log field (empty disables)
+func (h *Handler) SetBotScoreHeader(name string) {
+	h.scoreHdr = name
+}
+
+// setBotScore adds the bot score header to a classification response
+func (h *Handler) setBotScore(w http.ResponseWriter, result fingerprint.ClassificationResult) {
+	if h.scoreHdr != "" {
+		w.Header().Set(h.scoreHdr, strconv.Itoa(classifier.BotScore(result)))
+	}
+}
+
+// classify extracts the fingerprint of r, attaches session timing, CAPTCHA
+// verification, request rates and past offenses if tracking is
+// enabled, runs the enrichers (Private Access Token and
+// challenge token verification, Private Relay lookup) within their time
+// budgets and classifies it with the scope's classifier, recording the
+// time of each stage in t
+func (h *Handler) classify(sc *scope, r *http.Request, t *classifier.Timings) fingerprint.ClassificationResult {
+	start := time.Now()
+	fp := h.collector.Collect(r)
+	if h.sessions != nil {
+		fp.Session = h.sessions.Observe(h.sessionKey(r), start, fingerprint.IsAssetRequest(fp.HTTP))
+	}
+	fp.Session.HumanVerified = h.captchaVerified(r)
+	if h.rates != nil && !fingerprint.IsSubresourceFetch(fp.HTTP) {
+		fp.Rate = h.rates.Observe(h.clientKey(r), fp.TLS.JA4Hash, start)
+	}
+	if h.offenders != nil {
+		fp.Offender = h.offenders.Lookup(h.clientKey(r), fp.TLS.JA4Hash, start)
+		if h.traps != nil {
+			if trap, ok := h.traps.Match(r.URL.Path); ok {
+				fp.Offender.Trapped, fp.Offender.TrapPath = true, trap
+			}
+		}
+	}
+	t.Collect = time.Since(start)
+
+	incomplete := h.enrichment.Run(r.Context(), r, &fp, t)
+
+	start = time.Now()
+	result := sc.classifier.Classify(fp)
+	if len(incomplete) > 0 {
+		result.Partial = true
+		result.Incomplete = incomplete
+	}
+	t.Classify = time.Since(start)
+	return result
+}
+
+// sessionKey identifies the client session of r, by the subnet of its
+// address when subnet keys are enabled, anonymized when anonymization is
+// enabled
+func (h *Handler) sessionKey(r *http.Request) string {
+	if h.ipKeys == nil && h.anon == nil {
+		return session.Key(r)
+	}
+	return session.AddrKey(h.clientKey(r), r.Header.Get("User-Agent"))
+}
+
+// clientKey identifies the client address of r as sessionKey does,
+// without the User-Agent
+func (h *Handler) clientKey(r *http.Request) string {
+	if h.ipKeys != nil {
+		if p, ok := h.ipKeys.Prefix(r.RemoteAddr); ok {
+			addr := ipkey.String(p)
+			if h.anon != nil {
+				addr = h.anon.IP(p.Addr())
+			}
+			return addr
+		}
+	}
+	if h.anon != nil {
+		return h.anon.Addr(r.RemoteAddr)
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// logResult writes the result to the scope's structured log and the live
+// stream, samples it into the capture file, updates stats and metrics,
+// runs hooks and remembers bot verdicts when repeat offenders are tracked.
+// req is the classified request, nil for remote fingerprints.
+func (h *Handler) logResult(sc *scope, result fingerprint.ClassificationResult, remoteAddr string, responseTime int64, req *http.Request) {
+	h.stats.record(result.Classification)
+	if sc.stats != h.stats {
+		sc.stats.record(result.Classification)
+	}
+	h.metrics.record(result)
+	h.usage.recordClassified(sc, result.Classification)
+	h.runHooks(result)
+	h.publishResult(sc, result, remoteAddr)
+	if req != nil {
+		h.recordVerdict(sc, result, remoteAddr, req)
+	}
+	h.captureResult(sc, result, remoteAddr, responseTime, req)
+	if sc.logger == nil && h.stream == nil {
+		return
+	}
+	entry := logger.NewEntry(result, h.clientAddr(remoteAddr), responseTime)
+	entry.Tenant = sc.tenant
+	if h.scoreHdr != "" {
+		entry.CFBotScore = classifier.BotScore(result)
+	}
+	if sc.logger != nil && sc.logger.Profile() == logger.ProfileMinimal {
+		// Minimized entries are also what the live stream sees
+		entry = entry.Minimize()
+	}
+	if sc.logger != nil {
+		if err := sc.logger.Log(entry); err != nil {
+			log.Printf("Error logging result: %v", err)
+		}
+	}
+	if h.stream != nil {
+		h.stream.publish(entry)
+	}
+}
+
+// captureResult writes a full log entry, and the raw request when enabled,
+// to the capture file for sampled requests
+func (h *Handler) captureResult(sc *scope, result fingerprint.ClassificationResult, remoteAddr string, responseTime int64, req *http.Request) {
+	if h.capture == nil || !h.capture.Sample() {
+		return
+	}
+	rec := capture.Record{LogEntry: logger.NewEntry(result, h.clientAddr(remoteAddr), responseTime)}
+	rec.Tenant = sc.tenant
+	if req != nil && h.capture.Raw() {
+		m := fingerprint.MetadataFromRequest(req)
+		m.RemoteAddr = h.clientAddr(m.RemoteAddr)
+		rec.Request = &m
+	}
+	if err := h.capture.Write(rec); err != nil {
+		log.Printf("Error capturing request: %v", err)
+	}
+}
+
+// classifyMethods are the methods the classification endpoint serves
+const classifyMethods = "GET, HEAD, OPTIONS"
+
+// HandleClassify handles the main classification endpoint. HEAD gets the
+// headers of GET; OPTIONS (CORS preflights) gets 204 with the bot score
+// header. Both are classified and logged like GET.
 func (h *Handler) HandleClassify(w http.ResponseWriter, r *http.Request) {
 	startTime := time.Now()
 
-	// Only handle exact root path
-	if r.URL.Path != "/" {
-		http.NotFound(w, r)
+	// Only handle exact root path (/ or /v1/)
+	if unversionedPath(r.URL.Path) != "/" {
+		notFound(w, r)
+		return
+	}
+	switch r.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+	default:
+		methodNotAllowed(w, r, classifyMethods)
 		return
 	}
 
-	// Collect fingerprint
-	fp := h.collector.Collect(r)
+	// Attribute the request to a tenant
+	sc, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
 
-	// Classify request
-	result := h.classifier.Classify(fp)
+	// Collect, enrich and classify
+	var timings classifier.Timings
+	result := h.classify(sc, r, &timings)
+	fp := result.Fingerprint
 
 	// Calculate response time
 	responseTime := time.Since(startTime).Milliseconds()
 
 	// Log the result
-	if h.logger != nil {
-		if err := h.logger.LogResult(result, r.RemoteAddr, responseTime); err != nil {
-			log.Printf("Error logging result: %v", err)
-		}
-	}
+	logStart := time.Now()
+	h.logResult(sc, result, r.RemoteAddr, responseTime, r)
+	timings.Log = time.Since(logStart)
+	h.metrics.recordTimings(timings)
 
 	// Generate message based on classification
 	message := "You appear to be using a browser"
@@ -87,7 +463,7 @@ func (h *Handler) HandleClassify(w http.ResponseWriter, r *http.Request) {
 	// Log to console (unless quiet mode)
 	if !h.quiet {
 		log.Printf("[%s] %s %s - UA: %s - %s (%.2f) - %dms",
-			r.RemoteAddr,
+			h.clientAddr(r.RemoteAddr),
 			r.Method,
 			r.URL.Path,
 			fp.HTTP.UserAgent,
@@ -97,15 +473,72 @@ func (h *Handler) HandleClassify(w http.ResponseWriter, r *http.Request) {
 		)
 	}
 
-	// Send response
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(Response{
+	// Every response, including refusals, states the AI usage policy
+	h.setAIPolicy(w, fp.HTTP.UserAgent)
+
+	// Crawlers ahead of their crawl delay are turned away. Senders failing
+	// crawler verification are paced on their own, so they cannot spend
+	// the genuine crawler's budget.
+	if h.crawl != nil && result.Classification == classifier.ClassificationBot {
+		pool := ""
+		if fp.Network.CrawlerVerification == fingerprint.CrawlerSpoofed {
+			pool = fingerprint.CrawlerSpoofed
+		}
+		if d, ok := h.crawl.TakeIn(fp.HTTP.UserAgent, pool, time.Now()); ok {
+			setCrawlRateLimit(w, d)
+			if !d.Allowed {
+				w.Header().Set("Retry-After", strconv.Itoa(ceilSeconds(d.Wait)))
+				writeProblem(w, r, http.StatusTooManyRequests, CodeRateLimited, "crawler exceeded its robots.txt Crawl-delay")
+				return
+			}
+		}
+	}
+
+	// Bots navigating to a page are sent to the CAPTCHA, unless their
+	// session already solved it
+	isBot := result.Classification == classifier.ClassificationBot
+	verified := isBot && fp.Session.HumanVerified
+	if isBot && !verified && h.redirectToCaptcha(w, r) {
+		h.usage.recordChallenged(sc)
+		h.publishChallenged(sc, result, r.RemoteAddr, events.ActionCaptcha)
+		return
+	}
+
+	// Preflights carry no credentials and fail on any non-2xx status, so
+	// they are answered without a challenge or body
+	if r.Method == http.MethodOptions {
+		w.Header().Set("Allow", classifyMethods)
+		h.setBotScore(w, result)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	// Send response; bots are challenged for a Private Access Token, which
+	// clients only fetch on 401 responses
+	w.Header()["Content-Type"] = jsonContentType
+	h.setBotScore(w, result)
+	if !isBot && fp.HTTP.PrivateToken == fingerprint.PrivateTokenValid {
+		h.issueChallengeToken(w, fp)
+	}
+	if h.tokens != nil && isBot && !verified {
+		w.Header().Set("WWW-Authenticate", h.tokens.Challenge())
+		h.usage.recordChallenged(sc)
+		h.publishChallenged(sc, result, r.RemoteAddr, events.ActionPrivateToken)
+		w.WriteHeader(http.StatusUnauthorized)
+	}
+	if r.Method == http.MethodHead {
+		return
+	}
+	if err := writeResponse(w, Response{
 		Classification: result.Classification,
 		Confidence:     result.Confidence,
 		Message:        message,
 		RequestID:      result.RequestID,
 		Timestamp:      result.Timestamp,
 		Version:        version,
+
+		Class:           result.Class,
+		ClassConfidence: result.ClassConfidence,
 	}); err != nil {
 		log.Printf("Error encoding response: %v", err)
 	}
@@ -113,24 +546,78 @@ func (h *Handler) HandleClassify(w http.ResponseWriter, r *http.Request) {
 
 // HandleHealth handles the health check endpoint
 func (h *Handler) HandleHealth(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	if err := json.NewEncoder(w).Encode(HealthResponse{
-		Status:  "ok",
-		Version: version,
-	}); err != nil {
-		log.Printf("Error encoding health response: %v", err)
+	w.Header()["Content-Type"] = jsonContentType
+	if h.Draining() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		if _, err := w.Write(drainBody); err != nil {
+			log.Printf("Error writing health response: %v", err)
+		}
+		return
+	}
+	if _, err := w.Write(healthBody); err != nil {
+		log.Printf("Error writing health response: %v", err)
+	}
+}
+
+// HandleStats returns classification counters since server start, for
+// the request's tenant when it belongs to one
+func (h *Handler) HandleStats(w http.ResponseWriter, r *http.Request) {
+	sc, err := h.statsScope(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnknownAPIKey, "the "+tenant.APIKeyHeader+" header does not match any tenant")
+		return
+	}
+	snap := sc.stats.snapshot()
+	snap.Tenant = sc.tenant
+
+	w.Header()["Content-Type"] = jsonContentType
+	if err := encodeJSON(w, snap); err != nil {
+		log.Printf("Error encoding stats response: %v", err)
+	}
+}
+
+// HandleRobots serves the robots.txt whose Crawl-delay is enforced, with
+// the honeypot traps disallowed
+func (h *Handler) HandleRobots(w http.ResponseWriter, r *http.Request) {
+	var robots []byte
+	if h.crawl != nil {
+		robots = h.crawl.Robots()
+	}
+	if h.traps != nil {
+		robots = h.traps.Robots(robots)
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if _, err := w.Write(robots); err != nil {
+		log.Printf("Error writing robots.txt: %v", err)
+	}
+}
+
+// HandleOpenAPISpec serves the OpenAPI specification
+func (h *Handler) HandleOpenAPISpec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/yaml")
+	if _, err := w.Write(api.Spec); err != nil {
+		log.Printf("Error writing OpenAPI spec: %v", err)
 	}
 }
 
 // HandleDebug returns detailed fingerprint for debugging (optional endpoint)
 func (h *Handler) HandleDebug(w http.ResponseWriter, r *http.Request) {
-	fp := h.collector.Collect(r)
-	result := h.classifier.Classify(fp)
+	sc, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+	var timings classifier.Timings
+	result := h.classify(sc, r, &timings)
 
 	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Server-Timing", serverTiming(timings))
 	encoder := json.NewEncoder(w)
 	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(result); err != nil {
+	debug := struct {
+		fingerprint.ClassificationResult
+		Timings classifier.Timings `json:"timings"`
+	}{result, timings}
+	if err := encoder.Encode(debug); err != nil {
 		log.Printf("Error encoding debug response: %v", err)
 	}
 }
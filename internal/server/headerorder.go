@@ -0,0 +1,45 @@
+package server
+
+import (
+	"net"
+	"net/http"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// headerOrderListener records the header names of the HTTP/1.x requests
+// read on each plain HTTP connection, before net/http canonicalizes them.
+// Under TLS the http.Server needs the *tls.Conn itself, so the sniffer
+// cannot sit above the decryption and header order is not captured there.
+type headerOrderListener struct {
+	net.Listener
+}
+
+// Accept wraps connections in a headerOrderConn
+func (l headerOrderListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	return &headerOrderConn{Conn: c}, nil
+}
+
+// headerOrderConn feeds the bytes read from the connection to a
+// HeaderSniffer
+type headerOrderConn struct {
+	net.Conn
+
+	sniffer fingerprint.HeaderSniffer
+}
+
+// Read records what net/http reads
+func (c *headerOrderConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	_, _ = c.sniffer.Write(b[:n])
+	return n, err
+}
+
+// HeaderNames returns the header names of r as sent, or nil
+func (c *headerOrderConn) HeaderNames(r *http.Request) []string {
+	return c.sniffer.HeaderNames(r)
+}
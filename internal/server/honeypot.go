@@ -0,0 +1,63 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/honeypot"
+	"github.com/muliwe/go-client-classifier/internal/offender"
+)
+
+// SetHoneypot serves the traps, disallows them at /robots.txt and
+// remembers the clients requesting them in store, which every request is
+// looked up in
+func (h *Handler) SetHoneypot(t *honeypot.Traps, store *offender.Store) {
+	h.traps = t
+	h.offenders = store
+}
+
+// HandleTrap answers requests for a honeypot trap. The request is
+// classified and logged like any other, its client remembered as an
+// offender, and it gets the same 404 as any unknown path.
+func (h *Handler) HandleTrap(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+	sc, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	var timings classifier.Timings
+	result := h.classify(sc, r, &timings)
+	addr, ja4 := h.offenderKeys(r, result)
+	h.offenders.MarkTrapped(addr, ja4, result.Fingerprint.Offender.TrapPath, startTime)
+
+	responseTime := time.Since(startTime).Milliseconds()
+	h.logResult(sc, result, r.RemoteAddr, responseTime, r)
+	if !h.quiet {
+		log.Printf("[%s] %s %s - UA: %s - honeypot trap",
+			h.clientAddr(r.RemoteAddr),
+			r.Method,
+			r.URL.Path,
+			result.Fingerprint.HTTP.UserAgent,
+		)
+	}
+	notFound(w, r)
+}
+
+// offenderKeys returns the client address and JA4 to remember a trapped
+// client by, leaving out what innocent clients share: the address of a
+// load balancer or Private Relay egress, and a JA4 every browser of a
+// kind, or every client of an intercepting proxy, presents
+func (h *Handler) offenderKeys(r *http.Request, result fingerprint.ClassificationResult) (addr, ja4 string) {
+	fp, s := result.Fingerprint, result.Signals
+	if !fp.Network.Local && !fp.Network.PrivateRelay {
+		addr = h.clientKey(r)
+	}
+	if !s.KnownBrowserFingerprint && !s.TLSIntercepted {
+		ja4 = fp.TLS.JA4Hash
+	}
+	return addr, ja4
+}
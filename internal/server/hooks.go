@@ -0,0 +1,101 @@
+package server
+
+import (
+	"log"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/events"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// ResultHook receives a classification result
+type ResultHook func(result fingerprint.ClassificationResult)
+
+// Hooks are callbacks run after each classification, on every endpoint
+// (/, /classify, /classify/fingerprint and gRPC), for custom metrics,
+// caching or enforcement. Hooks run synchronously on the request path
+// and must be registered before the server starts serving.
+type Hooks struct {
+	OnClassified []ResultHook // Called for every result
+	OnBlocked    []ResultHook // Called for results classified as bot
+}
+
+// OnClassified registers a hook called for every classification result
+func (h *Handler) OnClassified(fn ResultHook) {
+	h.hooks.OnClassified = append(h.hooks.OnClassified, fn)
+}
+
+// OnBlocked registers a hook called for results classified as bot
+func (h *Handler) OnBlocked(fn ResultHook) {
+	h.hooks.OnBlocked = append(h.hooks.OnBlocked, fn)
+}
+
+// runHooks calls the registered hooks for a result
+func (h *Handler) runHooks(result fingerprint.ClassificationResult) {
+	for _, fn := range h.hooks.OnClassified {
+		callHook(fn, result)
+	}
+	if result.Classification == classifier.ClassificationBot {
+		for _, fn := range h.hooks.OnBlocked {
+			callHook(fn, result)
+		}
+	}
+}
+
+// callHook runs a hook, recovering from panics so a faulty hook
+// cannot fail the request
+func callHook(fn ResultHook, result fingerprint.ClassificationResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("Hook panic for request %s: %v", result.RequestID, r)
+		}
+	}()
+	fn(result)
+}
+
+// SetEvents publishes classified, blocked and challenged events to a bus
+// (disabled when nil)
+func (h *Handler) SetEvents(b *events.Bus) {
+	h.events = b
+}
+
+// publishResult publishes the classified event of a result, and the
+// blocked event of bots
+func (h *Handler) publishResult(sc *scope, result fingerprint.ClassificationResult, remoteAddr string) {
+	if h.events == nil {
+		return
+	}
+	h.events.Publish(h.newEvent(events.Classified, sc, result, remoteAddr))
+	if result.Classification == classifier.ClassificationBot {
+		h.events.Publish(h.newEvent(events.Blocked, sc, result, remoteAddr))
+	}
+}
+
+// publishChallenged publishes the challenged event of a bot sent a
+// challenge of the given kind
+func (h *Handler) publishChallenged(sc *scope, result fingerprint.ClassificationResult, remoteAddr, action string) {
+	if h.events == nil {
+		return
+	}
+	e := h.newEvent(events.Challenged, sc, result, remoteAddr)
+	e.Action = action
+	h.events.Publish(e)
+}
+
+// publishThresholdCrossed publishes the reputation_threshold_crossed event
+// of a bot whose verdict made it a repeat offender
+func (h *Handler) publishThresholdCrossed(sc *scope, result fingerprint.ClassificationResult, remoteAddr string) {
+	if h.events == nil {
+		return
+	}
+	h.events.Publish(h.newEvent(events.ReputationThresholdCrossed, sc, result, remoteAddr))
+}
+
+// newEvent creates an event for a result of the scope's tenant, with the
+// client address as it may be logged
+func (h *Handler) newEvent(typ events.Type, sc *scope, result fingerprint.ClassificationResult, remoteAddr string) events.Event {
+	e := events.New(typ, result)
+	e.Tenant = sc.tenant
+	e.ClientAddr = h.clientAddr(remoteAddr)
+	return e
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/psanford/tlsfingerprint"
+	"github.com/quic-go/quic-go"
+	"github.com/quic-go/quic-go/http3"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/quichello"
+)
+
+// newHTTP3Server creates the HTTP/3 server for handler. Each connection's
+// context carries the JA3/JA4 and QUIC fingerprints of the ClientHello
+// hellos recorded from its Initial packets.
+func newHTTP3Server(handler http.Handler, idleTimeout time.Duration, hellos *quichello.Recorder) *http3.Server {
+	return &http3.Server{
+		Handler:     handler,
+		IdleTimeout: idleTimeout,
+		ConnContext: func(ctx context.Context, c *quic.Conn) context.Context {
+			hello, ok := hellos.Take(c.RemoteAddr())
+			if !ok {
+				return ctx
+			}
+			record := hello.Record()
+			if ch, err := tlsfingerprint.ParseClientHello(record); err == nil {
+				ctx = TLSFingerprintToContext(ctx, ch)
+			}
+			if q, err := fingerprint.ParseQUICClientHello(record, hello.Version); err == nil {
+				ctx = context.WithValue(ctx, fingerprint.ContextKeyQUIC, q)
+			}
+			return ctx
+		},
+	}
+}
+
+// altSvc advertises HTTP/3 on the responses of next, so browsers switch
+// to QUIC for later requests
+func altSvc(h3 *http3.Server, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = h3.SetQUICHeaders(w.Header())
+		next.ServeHTTP(w, r)
+	})
+}
+
+// startHTTP3 serves HTTP/3 on the UDP port of the TCP listener's address,
+// recording the ClientHello of each QUIC connection
+func (s *Server) startHTTP3(cert tls.Certificate, addr net.Addr) error {
+	pc, err := net.ListenPacket("udp", addr.String())
+	if err != nil {
+		return fmt.Errorf("failed to create UDP listener: %w", err)
+	}
+	s.quicConn = pc
+	s.h3Server.TLSConfig = &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS13,
+	}
+
+	log.Printf("HTTP/3 active on %s (QUIC fingerprinting)", pc.LocalAddr())
+	go func() {
+		if err := s.h3Server.Serve(s.quicHellos.PacketConn(pc)); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			log.Printf("HTTP/3 server error: %v", err)
+		}
+	}()
+	return nil
+}
+
+// shutdownHTTP gracefully shuts down the HTTP/1.1 and HTTP/2 server and,
+// when enabled, the HTTP/3 server, which closes its connections itself
+// when ctx ends
+func (s *Server) shutdownHTTP(ctx context.Context) error {
+	if s.h3Server == nil {
+		return s.httpServer.Shutdown(ctx)
+	}
+	h3Done := make(chan error, 1)
+	go func() { h3Done <- s.h3Server.Shutdown(ctx) }()
+	err := s.httpServer.Shutdown(ctx)
+	if h3Err := <-h3Done; err == nil {
+		err = h3Err
+	}
+	return err
+}
@@ -0,0 +1,225 @@
+package server
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"maps"
+	"math"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Histogram bucket upper bounds
+var (
+	netScoreBuckets   = []float64{-20, -15, -10, -8, -6, -4, -2, -1, 0, 1, 2, 4, 6, 8, 10, 15, 20}
+	confidenceBuckets = []float64{0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 0.99, 1}
+	stageBuckets      = []float64{0.00005, 0.0001, 0.00025, 0.0005, 0.001, 0.0025, 0.005, 0.01, 0.025, 0.1}
+)
+
+// metricClasses are the classification label values, indexing per-class series
+var metricClasses = [...]string{classifier.ClassificationBrowser, classifier.ClassificationBot}
+
+// histogram is a Prometheus histogram with fixed buckets
+type histogram struct {
+	bounds []float64
+	counts []atomic.Uint64 // Non-cumulative count per bucket, plus +Inf
+	sum    atomic.Uint64   // float64 bits
+	count  atomic.Uint64
+}
+
+func newHistogram(bounds []float64) *histogram {
+	return &histogram{bounds: bounds, counts: make([]atomic.Uint64, len(bounds)+1)}
+}
+
+// observe adds a value to the histogram
+func (h *histogram) observe(v float64) {
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+	h.counts[i].Add(1)
+	h.count.Add(1)
+	for {
+		old := h.sum.Load()
+		if h.sum.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+v)) {
+			return
+		}
+	}
+}
+
+// write renders the histogram's series with the given label pair prefix
+func (h *histogram) write(w io.Writer, name, labels string) {
+	var cumulative uint64
+	for i, bound := range h.bounds {
+		cumulative += h.counts[i].Load()
+		fmt.Fprintf(w, "%s_bucket{%s,le=%q} %d\n", name, labels, formatFloat(bound), cumulative)
+	}
+	cumulative += h.counts[len(h.bounds)].Load()
+	fmt.Fprintf(w, "%s_bucket{%s,le=\"+Inf\"} %d\n", name, labels, cumulative)
+	fmt.Fprintf(w, "%s_sum{%s} %s\n", name, labels, formatFloat(math.Float64frombits(h.sum.Load())))
+	fmt.Fprintf(w, "%s_count{%s} %d\n", name, labels, h.count.Load())
+}
+
+// metrics holds score, confidence and rule firing distributions by
+// classification, exported in the Prometheus text format
+type metrics struct {
+	rules      []fingerprint.ScoringRule
+	ruleIndex  map[string]int
+	netScore   [len(metricClasses)]*histogram
+	confidence [len(metricClasses)]*histogram
+	fired      [len(metricClasses)][]atomic.Uint64 // Per rule, in rules order
+
+	// Latency per classification stage, in classifier.Stages order, and
+	// enrichers skipped for failing or running out of time
+	stages  []*histogram
+	mu      sync.Mutex
+	skipped map[string]*atomic.Uint64
+}
+
+func newMetrics() *metrics {
+	m := &metrics{rules: fingerprint.ScoringRules(), ruleIndex: map[string]int{}, skipped: map[string]*atomic.Uint64{}}
+	for range classifier.Stages {
+		m.stages = append(m.stages, newHistogram(stageBuckets))
+	}
+	for i, r := range m.rules {
+		m.ruleIndex[r.Name] = i
+	}
+	for c := range metricClasses {
+		m.netScore[c] = newHistogram(netScoreBuckets)
+		m.confidence[c] = newHistogram(confidenceBuckets)
+		m.fired[c] = make([]atomic.Uint64, len(m.rules))
+	}
+	return m
+}
+
+// record adds a classification result to the distributions
+func (m *metrics) record(result fingerprint.ClassificationResult) {
+	c := 0
+	if result.Classification == classifier.ClassificationBot {
+		c = 1
+	}
+	m.netScore[c].observe(float64(result.Score))
+	m.confidence[c].observe(result.Confidence)
+
+	browser, bot := result.Signals.ScoreBreakdown.Rules()
+	for _, name := range append(browser, bot...) {
+		if i, ok := m.ruleIndex[name]; ok {
+			m.fired[c][i].Add(1)
+		}
+	}
+	for _, name := range result.Incomplete {
+		m.skippedCounter(name).Add(1)
+	}
+}
+
+// skippedCounter returns the skip counter of an enricher, creating it on
+// first use
+func (m *metrics) skippedCounter(name string) *atomic.Uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	n, ok := m.skipped[name]
+	if !ok {
+		n = new(atomic.Uint64)
+		m.skipped[name] = n
+	}
+	return n
+}
+
+// recordTimings adds the stage durations of a classification
+func (m *metrics) recordTimings(t classifier.Timings) {
+	for i, stage := range classifier.Stages {
+		m.stages[i].observe(t.Stage(stage).Seconds())
+	}
+}
+
+// write renders all metrics in the Prometheus text exposition format
+func (m *metrics) write(w io.Writer) {
+	fmt.Fprintln(w, "# HELP classifier_net_score Net score (browser - bot) of classified requests.")
+	fmt.Fprintln(w, "# TYPE classifier_net_score histogram")
+	for c, class := range metricClasses {
+		m.netScore[c].write(w, "classifier_net_score", "classification="+strconv.Quote(class))
+	}
+
+	fmt.Fprintln(w, "# HELP classifier_confidence Confidence of classified requests.")
+	fmt.Fprintln(w, "# TYPE classifier_confidence histogram")
+	for c, class := range metricClasses {
+		m.confidence[c].write(w, "classifier_confidence", "classification="+strconv.Quote(class))
+	}
+
+	fmt.Fprintln(w, "# HELP classifier_rule_fired_total Scoring rules fired, by rule, the side it scores for and the resulting classification.")
+	fmt.Fprintln(w, "# TYPE classifier_rule_fired_total counter")
+	for c, class := range metricClasses {
+		for i, r := range m.rules {
+			side := "browser"
+			if r.Bot {
+				side = "bot"
+			}
+			fmt.Fprintf(w, "classifier_rule_fired_total{rule=%q,side=%q,classification=%q} %d\n", r.Name, side, class, m.fired[c][i].Load())
+		}
+	}
+
+	fmt.Fprintln(w, "# HELP classifier_stage_duration_seconds Time spent per classification stage of GET /v1/ requests.")
+	fmt.Fprintln(w, "# TYPE classifier_stage_duration_seconds histogram")
+	for i, stage := range classifier.Stages {
+		m.stages[i].write(w, "classifier_stage_duration_seconds", "stage="+strconv.Quote(stage))
+	}
+
+	fmt.Fprintln(w, "# HELP classifier_enrichment_skipped_total Enrichers skipped for failing or exceeding their time budget.")
+	fmt.Fprintln(w, "# TYPE classifier_enrichment_skipped_total counter")
+	m.mu.Lock()
+	names := slices.Sorted(maps.Keys(m.skipped))
+	m.mu.Unlock()
+	for _, name := range names {
+		fmt.Fprintf(w, "classifier_enrichment_skipped_total{enricher=%q} %d\n", name, m.skippedCounter(name).Load())
+	}
+}
+
+// writeCacheMetrics renders the verdict cache counters
+func writeCacheMetrics(w io.Writer, st classifier.CacheStats) {
+	fmt.Fprintln(w, "# HELP classifier_verdict_cache_lookups_total Verdict cache lookups by result: hit, miss, or bypass for fingerprints without a hash.")
+	fmt.Fprintln(w, "# TYPE classifier_verdict_cache_lookups_total counter")
+	fmt.Fprintf(w, "classifier_verdict_cache_lookups_total{result=\"hit\"} %d\n", st.Hits)
+	fmt.Fprintf(w, "classifier_verdict_cache_lookups_total{result=\"miss\"} %d\n", st.Misses)
+	fmt.Fprintf(w, "classifier_verdict_cache_lookups_total{result=\"bypass\"} %d\n", st.Bypassed)
+	fmt.Fprintln(w, "# HELP classifier_verdict_cache_entries Verdicts in the cache.")
+	fmt.Fprintln(w, "# TYPE classifier_verdict_cache_entries gauge")
+	fmt.Fprintf(w, "classifier_verdict_cache_entries %d\n", st.Size)
+}
+
+// serverTiming renders a Server-Timing header value for a breakdown
+func serverTiming(t classifier.Timings) string {
+	parts := make([]string, 0, len(classifier.Stages))
+	for _, stage := range classifier.Stages {
+		parts = append(parts, stage+";dur="+strconv.FormatFloat(float64(t.Stage(stage))/float64(time.Millisecond), 'f', 3, 64))
+	}
+	return strings.Join(parts, ", ")
+}
+
+// formatFloat renders a sample value or bucket bound
+func formatFloat(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// HandleMetrics serves score, confidence and rule firing metrics in the
+// Prometheus text format
+func (h *Handler) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	bw := bufio.NewWriter(w)
+	h.metrics.write(bw)
+	if cache := h.classifier.Cache(); cache != nil {
+		writeCacheMetrics(bw, cache.Stats())
+	}
+	if err := bw.Flush(); err != nil {
+		log.Printf("Error writing metrics: %v", err)
+	}
+}
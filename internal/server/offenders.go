@@ -0,0 +1,47 @@
+package server
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/offender"
+)
+
+// SetRepeatOffenders remembers the bot verdicts of classified requests in
+// store, which every request is looked up in. It may share the store of
+// SetHoneypot.
+func (h *Handler) SetRepeatOffenders(store *offender.Store) {
+	h.offenders = store
+	h.recordBots = store != nil
+}
+
+// recordVerdict adds a bot verdict to the reputation of the client of r,
+// publishing reputation_threshold_crossed when it makes the client a
+// repeat offender. Verdicts only the repeat-offender rule tipped to bot
+// are not recorded, so that a client's reputation cannot sustain itself.
+func (h *Handler) recordVerdict(sc *scope, result fingerprint.ClassificationResult, remoteAddr string, r *http.Request) {
+	if !h.recordBots || result.Classification != classifier.ClassificationBot || reputationTipped(result) {
+		return
+	}
+	addr, ja4 := h.offenderKeys(r, result)
+	if h.offenders.RecordBot(addr, ja4, time.Now()) {
+		h.publishThresholdCrossed(sc, result, remoteAddr)
+	}
+}
+
+// reputationTipped reports whether a bot verdict would have been browser
+// without the repeat-offender rule
+func reputationTipped(result fingerprint.ClassificationResult) bool {
+	if !result.Signals.RepeatOffender {
+		return false
+	}
+	_, bot := result.Signals.ScoreBreakdown.Scores()
+	for _, rs := range bot {
+		if rs.Name == "repeat-offender" {
+			return result.Margin+rs.Weight >= 0
+		}
+	}
+	return false
+}
@@ -0,0 +1,393 @@
+package server
+
+import (
+	"net"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/anonymize"
+	"github.com/muliwe/go-client-classifier/internal/asn"
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/capture"
+	"github.com/muliwe/go-client-classifier/internal/challenge"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/crawlerverify"
+	"github.com/muliwe/go-client-classifier/internal/events"
+	"github.com/muliwe/go-client-classifier/internal/geoip"
+	"github.com/muliwe/go-client-classifier/internal/honeypot"
+	"github.com/muliwe/go-client-classifier/internal/ipkey"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/membudget"
+	"github.com/muliwe/go-client-classifier/internal/privaterelay"
+	"github.com/muliwe/go-client-classifier/internal/privatetoken"
+	"github.com/muliwe/go-client-classifier/internal/rate"
+	"github.com/muliwe/go-client-classifier/internal/reputation"
+	"github.com/muliwe/go-client-classifier/internal/session"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+// Option configures a Server.
+//
+// Config is itself an Option that replaces the whole configuration, so
+// New(cfg) keeps working and can be combined with functional options
+// applied after it: New(cfg, WithTLS(cert, key)).
+type Option interface {
+	apply(*Config)
+}
+
+// optionFunc adapts a function to the Option interface
+type optionFunc func(*Config)
+
+func (f optionFunc) apply(cfg *Config) { f(cfg) }
+
+func (c Config) apply(cfg *Config) { *cfg = c }
+
+// WithAddr sets the listen address (e.g. ":8080")
+func WithAddr(addr string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Addr = addr
+	})
+}
+
+// WithTimeouts sets the HTTP server read, write and idle timeouts
+func WithTimeouts(read, write, idle time.Duration) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.ReadTimeout = read
+		cfg.WriteTimeout = write
+		cfg.IdleTimeout = idle
+	})
+}
+
+// WithShutdownTimeout bounds a graceful shutdown: requests still running
+// after d are force-closed (0 = wait for them)
+func WithShutdownTimeout(d time.Duration) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.ShutdownTimeout = d
+	})
+}
+
+// WithDrainDelay keeps accepting requests for d after shutdown starts
+// while health checks report draining, so load balancers stop routing to
+// the server before its listeners close
+func WithDrainDelay(d time.Duration) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.DrainDelay = d
+	})
+}
+
+// WithTLS enables HTTPS with TLS fingerprinting using the given certificate
+func WithTLS(certFile, keyFile string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.TLSEnabled = true
+		cfg.TLSCertFile = certFile
+		cfg.TLSKeyFile = keyFile
+	})
+}
+
+// WithGRPC enables the gRPC classification service on addr
+func WithGRPC(addr string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.GRPCAddr = addr
+	})
+}
+
+// WithRulesetVersion records the version of the ruleset the classifier
+// configuration was built from, reported by /version
+func WithRulesetVersion(v string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.RulesetVersion = v
+	})
+}
+
+// WithListener serves HTTP(S) on a pre-opened listener, such as a socket
+// passed by systemd, instead of binding the listen address
+func WithListener(l net.Listener) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Listener = l
+	})
+}
+
+// WithGRPCListener enables the gRPC classification service on a
+// pre-opened listener
+func WithGRPCListener(l net.Listener) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.GRPCListener = l
+	})
+}
+
+// WithOnClassified registers a hook called for every classification result
+func WithOnClassified(fn ResultHook) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Hooks.OnClassified = append(cfg.Hooks.OnClassified, fn)
+	})
+}
+
+// WithOnBlocked registers a hook called for results classified as bot
+func WithOnBlocked(fn ResultHook) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Hooks.OnBlocked = append(cfg.Hooks.OnBlocked, fn)
+	})
+}
+
+// WithDebug enables or disables the /debug endpoint
+func WithDebug(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.EnableDebug = enabled
+	})
+}
+
+// WithStream enables or disables the live classification stream at /stream
+func WithStream(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.EnableStream = enabled
+	})
+}
+
+// WithPrivateTokens enables Private Access Token challenges and verification
+func WithPrivateTokens(v *privatetoken.Verifier) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.PrivateTokens = v
+	})
+}
+
+// WithChallengeTokens issues signed challenge tokens to clients that pass a
+// challenge and scores the tokens they present on later requests
+func WithChallengeTokens(s *challenge.Signer) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.ChallengeTokens = s
+	})
+}
+
+// WithCaptcha redirects bots navigating to a page to a CAPTCHA; sessions
+// that solve it are remembered in a store configured by store
+func WithCaptcha(v *captcha.Verifier, store captcha.StoreConfig) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Captcha = v
+		cfg.CaptchaStore = store
+	})
+}
+
+// WithMemoryBudget sizes the session tracker and CAPTCHA store to a memory
+// budget and shrinks them under memory pressure
+func WithMemoryBudget(b *membudget.Budget) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.MemoryBudget = b
+	})
+}
+
+// WithFingerprintDB adds the known JA3/JA4 fingerprints of a file to the
+// built-in ones, reloading it on SIGHUP
+func WithFingerprintDB(path string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.FingerprintDB = path
+	})
+}
+
+// WithPrivateRelay marks requests from iCloud Private Relay egress ranges
+func WithPrivateRelay(rg *privaterelay.Ranges) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.PrivateRelay = rg
+	})
+}
+
+// WithGeoIP looks up the country of client addresses for the
+// geo/language signals
+func WithGeoIP(l geoip.Locator) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.GeoIP = l
+	})
+}
+
+// WithASN looks up the autonomous system of client addresses for the
+// datacenter signal
+func WithASN(l asn.Locator) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.ASN = l
+	})
+}
+
+// WithHostingASNs replaces the built-in hosting provider ASNs with those
+// of a file, reloading it on SIGHUP
+func WithHostingASNs(path string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.HostingASNs = path
+	})
+}
+
+// WithReputation looks up client addresses with an IP reputation provider
+// for the anonymized network signal
+func WithReputation(p reputation.Provider) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Reputation = p
+	})
+}
+
+// WithCrawlerVerifier checks requests whose User-Agent names a documented
+// crawler against its operator's reverse DNS or IP ranges
+func WithCrawlerVerifier(v *crawlerverify.Verifier) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.CrawlerVerifier = v
+	})
+}
+
+// WithIPAnonymizer truncates or hashes client IPs before they are logged,
+// streamed or used as session keys
+func WithIPAnonymizer(a *anonymize.Anonymizer) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.IPAnonymizer = a
+	})
+}
+
+// WithEvents publishes classified, blocked and challenged events to a bus,
+// closed when the server shuts down
+func WithEvents(b *events.Bus) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Events = b
+	})
+}
+
+// WithAIPolicyHeaders emits the Content-Signal of the WithCrawlDelay
+// robots.txt, and the equivalent X-Robots-Tag, on classify responses
+func WithAIPolicyHeaders(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.AIPolicyHeaders = enabled
+	})
+}
+
+// WithIPKeys sets the prefix lengths per-client state is aggregated at
+func WithIPKeys(c ipkey.Config) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.IPKeys = c
+	})
+}
+
+// WithCrawlDelay paces identified crawlers by their robots.txt Crawl-delay
+func WithCrawlDelay(p *crawldelay.Pacer) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.CrawlDelay = p
+	})
+}
+
+// WithHoneypot serves honeypot traps and classifies the clients that
+// request one as bots on their later requests
+func WithHoneypot(t *honeypot.Traps) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Honeypot = t
+	})
+}
+
+// WithRepeatOffenders enables or disables remembering bot verdicts, so
+// that clients caught before are classified with that history
+func WithRepeatOffenders(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.RepeatOffenders = enabled
+	})
+}
+
+// WithCapture samples classified requests into a capture file for
+// building labeled datasets
+func WithCapture(cc capture.Config) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Capture = cc
+	})
+}
+
+// WithAdminToken enables the admin endpoints for requests carrying
+// Authorization: Bearer <token>
+func WithAdminToken(token string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.AdminToken = token
+	})
+}
+
+// WithTenants attributes requests to tenants by API key or Host
+func WithTenants(reg *tenant.Registry) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.Tenants = reg
+	})
+}
+
+// WithBotScoreHeader emits the Cloudflare-compatible 1-99 bot score in the
+// named response header (e.g. classifier.BotScoreHeader) and the log
+func WithBotScoreHeader(name string) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.BotScoreHeader = name
+	})
+}
+
+// WithAPIValidation enables or disables OpenAPI request validation
+func WithAPIValidation(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.ValidateAPI = enabled
+	})
+}
+
+// WithLogger sets the request logger configuration
+func WithLogger(lc logger.Config) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.LoggerConfig = lc
+	})
+}
+
+// WithClassifier applies classifier options to the server's classifier
+func WithClassifier(opts ...classifier.Option) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.ClassifierCfg = classifier.NewConfig(append([]classifier.Option{cfg.ClassifierCfg}, opts...)...)
+	})
+}
+
+// WithSessionTracking enables or disables inter-request timing signals
+func WithSessionTracking(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.SessionTracking = enabled
+	})
+}
+
+// WithRateTracking enables or disables per-address and per-JA4 request
+// rate signals
+func WithRateTracking(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.RateTracking = enabled
+	})
+}
+
+// WithTCPFingerprint enables or disables JA4T fingerprinting of the SYN
+// that opened each connection (Linux only; Serve fails elsewhere)
+func WithTCPFingerprint(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.TCPFingerprint = enabled
+	})
+}
+
+// WithHTTP3 enables or disables HTTP/3 on the UDP port of the TLS
+// listener, with QUIC fingerprinting (requires WithTLS)
+func WithHTTP3(enabled bool) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.HTTP3 = enabled
+	})
+}
+
+// WithSessionConfig sets the session tracker configuration
+func WithSessionConfig(sc session.Config) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.SessionCfg = sc
+	})
+}
+
+// WithRateConfig sets the rate tracker configuration
+func WithRateConfig(rc rate.Config) Option {
+	return optionFunc(func(cfg *Config) {
+		cfg.RateCfg = rc
+	})
+}
+
+// newConfig applies options on top of DefaultConfig
+func newConfig(opts []Option) Config {
+	cfg := DefaultConfig()
+	for _, opt := range opts {
+		if opt != nil {
+			opt.apply(&cfg)
+		}
+	}
+	return cfg
+}
@@ -0,0 +1,66 @@
+package server
+
+import (
+	"log"
+	"net/http"
+)
+
+// problemTypeBase is the prefix of problem type URIs; the error code is
+// appended as the fragment (see docs/ERRORS.md)
+const problemTypeBase = "https://github.com/muliwe/go-client-classifier/blob/main/docs/ERRORS.md#"
+
+// Machine-readable error codes
+const (
+	CodeNotFound         = "not_found"
+	CodeMethodNotAllowed = "method_not_allowed"
+	CodeInvalidBody      = "invalid_body"
+	CodePayloadTooLarge  = "payload_too_large"
+	CodeInvalidRequest   = "invalid_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnknownAPIKey    = "unknown_api_key"
+	CodeRateLimited      = "rate_limited"
+	CodeUnauthorized     = "unauthorized"
+	CodeInternal         = "internal_error"
+)
+
+// Problem is an RFC 7807 problem details error body
+type Problem struct {
+	Type     string   `json:"type"`               // URI identifying the problem type
+	Title    string   `json:"title"`              // Short summary of the problem type
+	Status   int      `json:"status"`             // HTTP status code
+	Detail   string   `json:"detail,omitempty"`   // Explanation specific to this occurrence
+	Instance string   `json:"instance,omitempty"` // Request path
+	Code     string   `json:"code"`               // Machine-readable error code
+	Errors   []string `json:"errors,omitempty"`   // Individual validation failures
+}
+
+// writeProblem responds with an application/problem+json error body
+func writeProblem(w http.ResponseWriter, r *http.Request, status int, code, detail string, errs ...string) {
+	p := Problem{
+		Type:     problemTypeBase + code,
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   detail,
+		Instance: r.URL.Path,
+		Code:     code,
+		Errors:   errs,
+	}
+
+	w.Header()["Content-Type"] = problemContentType
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	if err := encodeJSON(w, p); err != nil {
+		log.Printf("Error encoding problem response: %v", err)
+	}
+}
+
+// notFound responds with a 404 problem
+func notFound(w http.ResponseWriter, r *http.Request) {
+	writeProblem(w, r, http.StatusNotFound, CodeNotFound, "no endpoint at "+r.URL.Path)
+}
+
+// methodNotAllowed responds with a 405 problem and the allowed method
+func methodNotAllowed(w http.ResponseWriter, r *http.Request, allowed string) {
+	w.Header().Set("Allow", allowed)
+	writeProblem(w, r, http.StatusMethodNotAllowed, CodeMethodNotAllowed, r.Method+" is not supported, use "+allowed)
+}
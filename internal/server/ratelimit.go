@@ -0,0 +1,39 @@
+package server
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+// setRateLimit writes the RateLimit-Limit, RateLimit-Remaining and
+// RateLimit-Reset headers of the IETF RateLimit header fields draft, and a
+// RateLimit-Policy naming the active policy (e.g. "tenant/shop";q=10;w=5),
+// so clients can pace themselves before they are refused
+func setRateLimit(w http.ResponseWriter, policy string, limit int, window time.Duration, remaining int, reset time.Duration) {
+	hdr := w.Header()
+	hdr.Set("RateLimit-Limit", strconv.Itoa(limit))
+	hdr.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+	hdr.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+	hdr.Set("RateLimit-Policy", strconv.Quote(policy)+";q="+strconv.Itoa(limit)+";w="+strconv.Itoa(ceilSeconds(window)))
+}
+
+// setTenantRateLimit reports a tenant's rate limit
+func setTenantRateLimit(w http.ResponseWriter, tenantID string, st tenant.Status) {
+	setRateLimit(w, "tenant/"+tenantID, st.Limit, st.Window, st.Remaining, st.Reset)
+}
+
+// setCrawlRateLimit reports a crawler's Crawl-delay as a quota of one
+// request per delay
+func setCrawlRateLimit(w http.ResponseWriter, d crawldelay.Decision) {
+	setRateLimit(w, "crawl-delay/"+d.Crawler, 1, d.Delay, 0, d.Wait)
+}
+
+// ceilSeconds rounds a duration up to whole seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,125 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// maxRequestBodyBytes limits the size of remote classification payloads
+const maxRequestBodyBytes = 1 << 20
+
+// ClassifyRequest describes an HTTP request received by a remote service,
+// sent to the detector for classification
+type ClassifyRequest = fingerprint.RequestMetadata
+
+// HandleClassifyRequest classifies a request described by a remote service
+func (h *Handler) HandleClassifyRequest(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var cr ClassifyRequest
+	if err := decodeJSONBody(w, r, &cr); err != nil {
+		badBody(w, r, err)
+		return
+	}
+
+	sc, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	result, err := h.classifyMetadata(r.Context(), sc, cr, startTime)
+	if err != nil {
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, err.Error())
+		return
+	}
+
+	h.setBotScore(w, result)
+	writeJSON(w, result)
+}
+
+// HandleClassifyFingerprint classifies a fingerprint collected by a remote service
+func (h *Handler) HandleClassifyFingerprint(w http.ResponseWriter, r *http.Request) {
+	startTime := time.Now()
+
+	if r.Method != http.MethodPost {
+		methodNotAllowed(w, r, http.MethodPost)
+		return
+	}
+
+	var fp fingerprint.Fingerprint
+	if err := decodeJSONBody(w, r, &fp); err != nil {
+		badBody(w, r, err)
+		return
+	}
+
+	sc, ok := h.scope(w, r)
+	if !ok {
+		return
+	}
+
+	result := h.classifyFingerprint(sc, fp, startTime)
+	h.setBotScore(w, result)
+	writeJSON(w, result)
+}
+
+// classifyMetadata rebuilds, classifies and logs a remotely observed request
+func (h *Handler) classifyMetadata(ctx context.Context, sc *scope, cr ClassifyRequest, startTime time.Time) (fingerprint.ClassificationResult, error) {
+	target, err := cr.HTTPRequest(ctx)
+	if err != nil {
+		return fingerprint.ClassificationResult{}, err
+	}
+
+	var timings classifier.Timings
+	result := h.classify(sc, target, &timings)
+	h.logResult(sc, result, cr.RemoteAddr, time.Since(startTime).Milliseconds(), target)
+	return result, nil
+}
+
+// classifyFingerprint classifies and logs a remotely collected fingerprint
+func (h *Handler) classifyFingerprint(sc *scope, fp fingerprint.Fingerprint, startTime time.Time) fingerprint.ClassificationResult {
+	result := sc.classifier.Classify(fp)
+	h.logResult(sc, result, "", time.Since(startTime).Milliseconds(), nil)
+	return result
+}
+
+// decodeJSONBody decodes a size-limited JSON request body into v
+func decodeJSONBody(w http.ResponseWriter, r *http.Request, v any) error {
+	r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		return fmt.Errorf("invalid JSON body: %w", err)
+	}
+	return nil
+}
+
+// writeJSON encodes v as the JSON response body
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header()["Content-Type"] = jsonContentType
+	if err := encodeJSON(w, v); err != nil {
+		log.Printf("Error encoding response: %v", err)
+	}
+}
+
+// badBody responds with a 400 (or 413 for oversized bodies) problem for
+// a request body that could not be decoded
+func badBody(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, CodePayloadTooLarge,
+			fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	writeProblem(w, r, http.StatusBadRequest, CodeInvalidBody, err.Error())
+}
@@ -0,0 +1,121 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// APIVersion is the current HTTP API version. Routes are served under
+// /v1 and, for existing integrations, at their legacy unversioned paths.
+const APIVersion = "v1"
+
+// apiPrefix is the path prefix of versioned routes
+const apiPrefix = "/" + APIVersion
+
+// NewRouter builds the HTTP routes for the handler. Request bodies are
+// validated when v is non-nil; /debug is registered when debug is set,
+// /stream when streaming is enabled on the handler, /robots.txt when it
+// enforces a loaded robots.txt or sets honeypot traps, the traps
+// themselves, /challenge when it has a CAPTCHA,
+// /admin/capture when it has a capturer and an admin token, and /feedback
+// when it has an admin token and its classifier takes feedback.
+func NewRouter(h *Handler, v *RequestValidator, debug bool) http.Handler {
+	validate := func(next http.HandlerFunc) http.HandlerFunc { return next }
+	if v != nil {
+		validate = v.Wrap
+	}
+
+	mux := http.NewServeMux()
+	handleVersioned(mux, "/", h.HandleClassify)
+	handleVersioned(mux, "/health", h.HandleHealth)
+	handleVersioned(mux, "/version", h.HandleVersion)
+	handleVersioned(mux, "/stats", h.HandleStats)
+	handleVersioned(mux, "/crawlers", h.HandleCrawlers)
+	handleVersioned(mux, "/explain/{request_id}", h.HandleExplain)
+	handleVersioned(mux, "/usage", validate(h.HandleUsage))
+	mux.HandleFunc("/metrics", h.HandleMetrics)
+	handleVersioned(mux, "/openapi.yaml", h.HandleOpenAPISpec)
+	handleVersioned(mux, "/classify", validate(h.HandleClassifyRequest))
+	handleVersioned(mux, "/classify/fingerprint", validate(h.HandleClassifyFingerprint))
+	if debug {
+		handleVersioned(mux, "/debug", h.HandleDebug)
+	}
+	if h.stream != nil {
+		handleVersioned(mux, "/stream", h.HandleStream)
+	}
+	if h.crawl != nil && h.crawl.Robots() != nil || h.traps != nil {
+		mux.HandleFunc("/robots.txt", h.HandleRobots)
+	}
+	if h.traps != nil {
+		for _, p := range h.traps.Paths() {
+			mux.HandleFunc(p, h.HandleTrap)
+		}
+	}
+	if h.captcha != nil {
+		mux.HandleFunc(captchaPath, h.HandleCaptcha)
+	}
+	if h.capture != nil && h.adminToken != "" {
+		handleVersioned(mux, "/admin/capture", validate(h.HandleCapture))
+	}
+	if h.adminToken != "" && h.classifier.FeedbackEnabled() {
+		handleVersioned(mux, "/feedback", validate(h.HandleFeedback))
+	}
+
+	return withAPIVersion(h.drain.track(mux))
+}
+
+// routePaths are the unversioned paths NewRouter may register, and
+// routePrefixes the subtrees it serves or reserves, /v1 among them
+var (
+	routePaths = []string{
+		"/", "/health", "/version", "/stats", "/crawlers", "/usage", "/metrics",
+		"/openapi.yaml", "/classify", "/classify/fingerprint", "/debug", "/stream",
+		"/robots.txt", captchaPath, "/feedback",
+	}
+	routePrefixes = []string{apiPrefix + "/", "/explain/", "/admin/"}
+)
+
+// checkTrapPaths rejects honeypot trap paths that would collide with a
+// server route: equal to one, a subtree containing one, or inside a
+// reserved subtree
+func checkTrapPaths(paths []string) error {
+	for _, p := range paths {
+		for _, route := range routePaths {
+			if p == route || strings.HasSuffix(p, "/") && strings.HasPrefix(route, p) {
+				return fmt.Errorf("honeypot trap %s conflicts with the server route %s", p, route)
+			}
+		}
+		for _, prefix := range routePrefixes {
+			if strings.HasPrefix(p+"/", prefix) || strings.HasSuffix(p, "/") && strings.HasPrefix(prefix, p) {
+				return fmt.Errorf("honeypot trap %s conflicts with the server routes under %s", p, prefix)
+			}
+		}
+	}
+	return nil
+}
+
+// handleVersioned registers a route under /v1 and at its legacy path
+func handleVersioned(mux *http.ServeMux, pattern string, h http.HandlerFunc) {
+	mux.HandleFunc(apiPrefix+pattern, h)
+	mux.HandleFunc(pattern, h)
+}
+
+// withAPIVersion sets the API-Version header on every response
+func withAPIVersion(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("API-Version", APIVersion)
+		next.ServeHTTP(w, r)
+	})
+}
+
+// unversionedPath strips the /v1 prefix from a request path
+func unversionedPath(path string) string {
+	if rest, ok := strings.CutPrefix(path, apiPrefix); ok && (rest == "" || rest[0] == '/') {
+		if rest == "" {
+			return "/"
+		}
+		return rest
+	}
+	return path
+}
@@ -3,20 +3,46 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
 	"github.com/psanford/tlsfingerprint/fingerprintlistener"
-
+	"github.com/quic-go/quic-go/http3"
+	"google.golang.org/grpc"
+
+	"github.com/muliwe/go-client-classifier/api"
+	"github.com/muliwe/go-client-classifier/internal/anonymize"
+	"github.com/muliwe/go-client-classifier/internal/asn"
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/capture"
+	"github.com/muliwe/go-client-classifier/internal/challenge"
 	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/crawlerverify"
+	"github.com/muliwe/go-client-classifier/internal/events"
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/geoip"
+	"github.com/muliwe/go-client-classifier/internal/honeypot"
+	"github.com/muliwe/go-client-classifier/internal/ipkey"
 	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/membudget"
+	"github.com/muliwe/go-client-classifier/internal/offender"
+	"github.com/muliwe/go-client-classifier/internal/privaterelay"
+	"github.com/muliwe/go-client-classifier/internal/privatetoken"
+	"github.com/muliwe/go-client-classifier/internal/quichello"
+	"github.com/muliwe/go-client-classifier/internal/rate"
+	"github.com/muliwe/go-client-classifier/internal/reputation"
+	"github.com/muliwe/go-client-classifier/internal/session"
+	"github.com/muliwe/go-client-classifier/internal/tcpsyn"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
 )
 
 // Config holds server configuration
@@ -26,26 +52,156 @@ type Config struct {
 	WriteTimeout  time.Duration
 	IdleTimeout   time.Duration
 	EnableDebug   bool
+	EnableStream  bool // Serve the live classification stream at /stream
+	ValidateAPI   bool // Validate request bodies against the OpenAPI spec
 	LoggerConfig  logger.Config
 	ClassifierCfg classifier.Config
 
+	// Version of the ruleset ClassifierCfg was built from, reported by
+	// /version (empty = built-in rules)
+	RulesetVersion string
+
+	// Callbacks run after each classification
+	Hooks Hooks
+
+	// Bus receiving classified, blocked and challenged events, closed on
+	// shutdown once in-flight requests finish (disabled when nil)
+	Events *events.Bus
+
+	// Cloudflare-compatible 1-99 bot score in this response header and the
+	// cf_bot_score log field (disabled when empty)
+	BotScoreHeader string
+
+	// gRPC classification service (disabled when empty)
+	GRPCAddr string
+
+	// Pre-opened listeners (e.g. from systemd socket activation) served
+	// instead of binding Addr and GRPCAddr; a GRPCListener also enables
+	// the gRPC service
+	Listener     net.Listener
+	GRPCListener net.Listener
+
+	// Private Access Token challenges and verification (disabled when nil)
+	PrivateTokens *privatetoken.Verifier
+
+	// Signed challenge tokens for clients that passed a challenge (disabled when nil)
+	ChallengeTokens *challenge.Signer
+
+	// CAPTCHA for bots navigating to a page (disabled when nil); sessions
+	// that solve it are remembered per CaptchaStore and not challenged again
+	Captcha      *captcha.Verifier
+	CaptchaStore captcha.StoreConfig
+
+	// File of known JA3/JA4 fingerprints added to the built-in ones,
+	// reloaded on SIGHUP (built-in only when empty)
+	FingerprintDB string
+
+	// iCloud Private Relay egress ranges (disabled when nil)
+	PrivateRelay *privaterelay.Ranges
+
+	// Country lookup of client addresses for the geo/language signals
+	// (disabled when nil)
+	GeoIP geoip.Locator
+
+	// Autonomous system lookup of client addresses for the datacenter
+	// signal (disabled when nil), and a file of hosting provider ASNs
+	// replacing the built-in ones, reloaded on SIGHUP
+	ASN         asn.Locator
+	HostingASNs string
+
+	// IP reputation lookup of client addresses (VPN, proxy and Tor lists)
+	// for the anonymized network signal (disabled when nil)
+	Reputation reputation.Provider
+
+	// Verification of crawler User-Agents by reverse DNS and published IP
+	// ranges (disabled when nil)
+	CrawlerVerifier *crawlerverify.Verifier
+
+	// Client IP anonymization before logging and session tracking (disabled when nil)
+	IPAnonymizer *anonymize.Anonymizer
+
+	// Prefix lengths per-client state (session timing, CAPTCHA verification)
+	// is aggregated at, so clients rotating through an IPv6 subnet keep
+	// one key
+	IPKeys ipkey.Config
+
+	// Crawl-delay enforcement for identified crawlers (disabled when nil)
+	CrawlDelay *crawldelay.Pacer
+
+	// Content-Signal and X-Robots-Tag headers from the CrawlDelay robots.txt
+	AIPolicyHeaders bool
+
+	// Honeypot trap paths, disallowed at /robots.txt; clients requesting
+	// one are remembered per OffenderCfg and classified bot (disabled when
+	// nil)
+	Honeypot    *honeypot.Traps
+	OffenderCfg offender.Config
+
+	// Remember bot verdicts per client address and JA4, decaying per
+	// OffenderCfg, and score clients with a history of them
+	// (repeat_offender)
+	RepeatOffenders bool
+
+	// Sampled capture of full fingerprints for dataset building (disabled
+	// when Capture.Path is empty), toggled at /admin/capture with AdminToken
+	Capture    capture.Config
+	AdminToken string
+
+	// Tenants with their own rulesets, rate limits, stats and log files
+	// (<LogDir>/<tenant>.jsonl); disabled when nil
+	Tenants *tenant.Registry
+
+	// Memory budget sizing the session tracker and CAPTCHA store, shrinking
+	// them under memory pressure (fixed sizes when nil)
+	MemoryBudget *membudget.Budget
+
+	// Session timing tracking (inter-request jitter signals)
+	SessionTracking bool
+	SessionCfg      session.Config
+
+	// Request rate tracking per client address and JA4 fingerprint (rate
+	// and burst signals)
+	RateTracking bool
+	RateCfg      rate.Config
+
+	// TCP SYN fingerprinting (JA4T) of accepted connections; Linux only
+	TCPFingerprint bool
+
+	// HTTP/3 on the UDP port of the TLS listener, with QUIC fingerprinting
+	// of each connection's Initial packets (requires TLS)
+	HTTP3 bool
+
 	// TLS configuration
 	TLSEnabled  bool
 	TLSCertFile string
 	TLSKeyFile  string
+
+	// Graceful shutdown: health checks report draining for DrainDelay
+	// before listeners close, then in-flight requests get the rest of
+	// ShutdownTimeout before they are force-closed
+	ShutdownTimeout time.Duration
+	DrainDelay      time.Duration
 }
 
 // DefaultConfig returns sensible defaults
 func DefaultConfig() Config {
 	return Config{
-		Addr:          ":8080",
-		ReadTimeout:   5 * time.Second,
-		WriteTimeout:  10 * time.Second,
-		IdleTimeout:   120 * time.Second,
-		EnableDebug:   true,
-		LoggerConfig:  logger.DefaultConfig(),
-		ClassifierCfg: classifier.DefaultConfig(),
-		TLSEnabled:    false,
+		Addr:            ":8080",
+		ReadTimeout:     5 * time.Second,
+		WriteTimeout:    10 * time.Second,
+		IdleTimeout:     120 * time.Second,
+		EnableDebug:     true,
+		ValidateAPI:     true,
+		LoggerConfig:    logger.DefaultConfig(),
+		ClassifierCfg:   classifier.DefaultConfig(),
+		SessionTracking: true,
+		SessionCfg:      session.DefaultConfig(),
+		RateTracking:    true,
+		OffenderCfg:     offender.DefaultConfig(),
+		RateCfg:         rate.DefaultConfig(),
+		IPKeys:          ipkey.DefaultConfig(),
+		TLSEnabled:      false,
+		ShutdownTimeout: 30 * time.Second,
 	}
 }
 
@@ -54,12 +210,29 @@ type Server struct {
 	cfg        Config
 	httpServer *http.Server
 	handler    *Handler
+	grpcServer *grpc.Server
 	logger     *logger.Logger
+	tenantLogs map[string]*logger.Logger
+	capture    *capture.Capturer
 	listener   net.Listener
+	syns       *tcpsyn.Recorder
+	h3Server   *http3.Server
+	quicHellos *quichello.Recorder
+	quicConn   net.PacketConn
 }
 
-// New creates a new server instance
-func New(cfg Config) (*Server, error) {
+// New creates a new server instance from DefaultConfig and the given options
+func New(opts ...Option) (*Server, error) {
+	cfg := newConfig(opts)
+	if cfg.HTTP3 && !cfg.TLSEnabled {
+		return nil, errors.New("HTTP/3 requires TLS")
+	}
+	if cfg.Honeypot != nil {
+		if err := checkTrapPaths(cfg.Honeypot.Paths()); err != nil {
+			return nil, err
+		}
+	}
+
 	// Initialize logger
 	l, err := logger.New(cfg.LoggerConfig)
 	if err != nil {
@@ -70,21 +243,160 @@ func New(cfg Config) (*Server, error) {
 	collector := fingerprint.NewCollector()
 	clf := classifier.New(cfg.ClassifierCfg)
 	handler := NewHandler(collector, clf, l)
+	if cache := clf.Cache(); cache != nil && cfg.MemoryBudget != nil {
+		cfg.MemoryBudget.Register("verdicts", cache)
+	}
+	for _, fn := range cfg.Hooks.OnClassified {
+		handler.OnClassified(fn)
+	}
+	for _, fn := range cfg.Hooks.OnBlocked {
+		handler.OnBlocked(fn)
+	}
+	if cfg.SessionTracking {
+		tracker := session.New(cfg.SessionCfg)
+		handler.SetSessionTracker(tracker)
+		if cfg.MemoryBudget != nil {
+			cfg.MemoryBudget.Register("sessions", tracker)
+		}
+	}
+	if cfg.RateTracking {
+		tracker := rate.New(cfg.RateCfg)
+		handler.SetRateTracker(tracker)
+		if cfg.MemoryBudget != nil {
+			cfg.MemoryBudget.Register("rates", tracker)
+		}
+	}
+	handler.SetStreaming(cfg.EnableStream)
+	handler.SetPrivateTokens(cfg.PrivateTokens)
+	handler.SetChallengeTokens(cfg.ChallengeTokens)
+	if cfg.Captcha != nil {
+		store := captcha.NewStore(cfg.CaptchaStore)
+		handler.SetCaptcha(cfg.Captcha, store)
+		if cfg.MemoryBudget != nil {
+			cfg.MemoryBudget.Register("captcha", store)
+		}
+	}
+	handler.SetPrivateRelay(cfg.PrivateRelay)
+	handler.SetGeoIP(cfg.GeoIP)
+	handler.SetReputation(cfg.Reputation)
+	handler.SetCrawlerVerifier(cfg.CrawlerVerifier)
+	handler.SetEnrichmentBudgets(cfg.ClassifierCfg.EnrichmentTimeout, cfg.ClassifierCfg.EnrichmentBudgets)
+	handler.SetIPAnonymizer(cfg.IPAnonymizer)
+	keyer, err := ipkey.New(cfg.IPKeys)
+	if err != nil {
+		return nil, err
+	}
+	handler.SetIPKeyer(keyer)
+	handler.SetAdminToken(cfg.AdminToken)
+	handler.SetCrawlDelay(cfg.CrawlDelay)
+	if cfg.Honeypot != nil || cfg.RepeatOffenders {
+		store := offender.New(cfg.OffenderCfg)
+		if cfg.Honeypot != nil {
+			handler.SetHoneypot(cfg.Honeypot, store)
+		}
+		if cfg.RepeatOffenders {
+			handler.SetRepeatOffenders(store)
+		}
+		if cfg.MemoryBudget != nil {
+			cfg.MemoryBudget.Register("offenders", store)
+		}
+	}
+	handler.SetAIPolicyHeaders(cfg.AIPolicyHeaders)
+	handler.SetEvents(cfg.Events)
+
+	var capturer *capture.Capturer
+	if cfg.Capture.Path != "" {
+		if capturer, err = capture.New(cfg.Capture); err != nil {
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to initialize capture: %w", err)
+		}
+		handler.SetCapture(capturer)
+	}
+	handler.SetBotScoreHeader(cfg.BotScoreHeader)
+	handler.SetRulesetVersion(cfg.RulesetVersion)
+	handler.SetFeatures(enabledFeatures(cfg))
+
+	// Per-tenant log partitions
+	var tenantLogs map[string]*logger.Logger
+	if cfg.Tenants != nil {
+		tenantLogs = make(map[string]*logger.Logger, len(cfg.Tenants.Tenants()))
+		for _, t := range cfg.Tenants.Tenants() {
+			lc := cfg.LoggerConfig
+			lc.FileName = t.ID + ".jsonl"
+			if strings.EqualFold(lc.FileName, cfg.LoggerConfig.FileName) {
+				closeLoggers(tenantLogs)
+				closeCapture(capturer)
+				_ = l.Close()
+				return nil, fmt.Errorf("tenant %s would log to the default log %s", t.ID, lc.FileName)
+			}
+			if t.LogProfile != "" {
+				lc.Profile = logger.Profile(t.LogProfile)
+			}
+			tl, err := logger.New(lc)
+			if err != nil {
+				closeLoggers(tenantLogs)
+				closeCapture(capturer)
+				_ = l.Close()
+				return nil, fmt.Errorf("failed to initialize logger for tenant %s: %w", t.ID, err)
+			}
+			tenantLogs[t.ID] = tl
+		}
+		handler.SetTenants(cfg.Tenants, tenantLogs)
+	}
 
-	// Setup routes
-	mux := http.NewServeMux()
-	mux.HandleFunc("/", handler.HandleClassify)
-	mux.HandleFunc("/health", handler.HandleHealth)
-	if cfg.EnableDebug {
-		mux.HandleFunc("/debug", handler.HandleDebug)
+	// Known fingerprints from a file, on top of the built-in ones
+	if cfg.FingerprintDB != "" {
+		db, err := fingerprint.LoadFingerprintDB(cfg.FingerprintDB)
+		if err != nil {
+			closeLoggers(tenantLogs)
+			closeCapture(capturer)
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to load known fingerprints: %w", err)
+		}
+		handler.SetFingerprints(db)
+	}
+
+	// Autonomous system lookup, with hosting providers from a file
+	if cfg.ASN != nil {
+		var hosting *asn.HostingList
+		if cfg.HostingASNs != "" {
+			hosting, err = asn.LoadHosting(cfg.HostingASNs)
+			if err != nil {
+				closeLoggers(tenantLogs)
+				closeCapture(capturer)
+				_ = l.Close()
+				return nil, fmt.Errorf("failed to load hosting ASNs: %w", err)
+			}
+		}
+		handler.SetASN(cfg.ASN, hosting)
+	}
+
+	// Request validation against the OpenAPI spec
+	var validator *RequestValidator
+	if cfg.ValidateAPI {
+		doc, err := api.Load()
+		if err != nil {
+			closeLoggers(tenantLogs)
+			closeCapture(capturer)
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to load OpenAPI spec: %w", err)
+		}
+		validator, err = NewRequestValidator(doc)
+		if err != nil {
+			closeLoggers(tenantLogs)
+			closeCapture(capturer)
+			_ = l.Close()
+			return nil, fmt.Errorf("failed to create request validator: %w", err)
+		}
 	}
 
 	httpServer := &http.Server{
 		Addr:         cfg.Addr,
-		Handler:      mux,
+		Handler:      NewRouter(handler, validator, cfg.EnableDebug),
 		ReadTimeout:  cfg.ReadTimeout,
 		WriteTimeout: cfg.WriteTimeout,
 		IdleTimeout:  cfg.IdleTimeout,
+		ConnState:    handler.drain.connState,
 	}
 
 	// Configure TLS if enabled
@@ -104,6 +416,10 @@ func New(cfg Config) (*Server, error) {
 				c = tlsConn.NetConn()
 			}
 
+			if shConn, ok := c.(*serverHelloConn); ok {
+				ctx = context.WithValue(ctx, fingerprint.ContextKeyServerHello, fingerprint.ServerHelloSource(shConn))
+				ctx = context.WithValue(ctx, fingerprint.ContextKeyLatency, fingerprint.LatencySource(shConn))
+			}
 			if fpConn, ok := c.(fingerprintlistener.Conn); ok {
 				fp := fpConn.Fingerprint()
 				if fp != nil {
@@ -112,13 +428,57 @@ func New(cfg Config) (*Server, error) {
 			}
 			return ctx
 		}
+	} else {
+		// Plain HTTP: the listener records header names as sent
+		httpServer.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+			if hoConn, ok := c.(*headerOrderConn); ok {
+				ctx = context.WithValue(ctx, fingerprint.ContextKeyHeaderOrder, fingerprint.HeaderOrderSource(hoConn))
+			}
+			return ctx
+		}
+	}
+
+	// SYNs saved by the kernel, for JA4T
+	var syns *tcpsyn.Recorder
+	if cfg.TCPFingerprint {
+		syns = tcpsyn.NewRecorder()
+		connContext := httpServer.ConnContext
+		httpServer.ConnContext = func(ctx context.Context, c net.Conn) context.Context {
+			if syn := syns.SYN(c.RemoteAddr()); syn != nil {
+				if tcp, err := fingerprint.ParseTCPSYN(syn); err == nil {
+					ctx = context.WithValue(ctx, fingerprint.ContextKeyTCP, tcp)
+				}
+			}
+			return connContext(ctx, c)
+		}
+	}
+
+	// HTTP/3 alongside, fingerprinted from the QUIC Initial packets
+	var h3Server *http3.Server
+	var quicHellos *quichello.Recorder
+	if cfg.HTTP3 {
+		quicHellos = quichello.NewRecorder()
+		h3Server = newHTTP3Server(httpServer.Handler, cfg.IdleTimeout, quicHellos)
+		httpServer.Handler = altSvc(h3Server, httpServer.Handler)
+	}
+
+	var grpcServer *grpc.Server
+	if cfg.GRPCAddr != "" || cfg.GRPCListener != nil {
+		grpcServer = grpc.NewServer()
+		NewGRPCService(handler).Register(grpcServer)
 	}
 
 	return &Server{
 		cfg:        cfg,
 		httpServer: httpServer,
+		grpcServer: grpcServer,
 		handler:    handler,
 		logger:     l,
+		tenantLogs: tenantLogs,
+		capture:    capturer,
+		syns:       syns,
+		h3Server:   h3Server,
+		quicHellos: quicHellos,
 	}, nil
 }
 
@@ -128,50 +488,188 @@ func (s *Server) Start() error {
 	done := make(chan os.Signal, 1)
 	signal.Notify(done, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 
+	// Reload the fingerprint database and hosting ASNs on SIGHUP until
+	// shutdown
+	if s.cfg.FingerprintDB != "" || s.cfg.ASN != nil && s.cfg.HostingASNs != "" {
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go s.reloadOnHangup(ctx)
+	}
+
+	// Shrink stateful stores under memory pressure until shutdown
+	if s.cfg.MemoryBudget != nil {
+		ctx, stop := context.WithCancel(context.Background())
+		defer stop()
+		go s.cfg.MemoryBudget.Run(ctx)
+	}
+
 	go func() {
 		protocol := "HTTP"
 		if s.cfg.TLSEnabled {
 			protocol = "HTTPS (TLS fingerprinting enabled)"
 		}
-		log.Printf("Bot Detector Server starting on %s (%s)", s.cfg.Addr, protocol)
-		log.Printf("Endpoints: / (classify), /health (health check)")
+		log.Printf("Bot Detector Server %s starting on %s (%s)", version, listenAddr(s.cfg.Listener, s.cfg.Addr), protocol)
+		log.Printf("Endpoints: /v1/ (classify), /v1/classify, /v1/classify/fingerprint (remote classify), /v1/health (health check), /v1/version, /v1/stats, /v1/explain/{id}, /v1/usage, /metrics (Prometheus)")
+		log.Printf("Legacy unversioned aliases: /, /classify, /classify/fingerprint, /health, /version, /stats, /usage")
 		if s.cfg.EnableDebug {
-			log.Printf("Debug endpoint enabled: /debug")
+			log.Printf("Debug endpoint enabled: /v1/debug")
+		}
+		if s.cfg.EnableStream {
+			log.Printf("Live stream enabled: /v1/stream")
+		}
+		if s.cfg.PrivateTokens != nil {
+			log.Printf("Private Access Tokens enabled")
+		}
+		if s.cfg.ChallengeTokens != nil {
+			log.Printf("Challenge tokens enabled (TTL %s)", s.cfg.ChallengeTokens.TTL())
+		}
+		if s.cfg.Captcha != nil {
+			log.Printf("CAPTCHA challenges enabled (%s): /challenge", s.cfg.Captcha.Provider())
+		}
+		if s.cfg.FingerprintDB != "" {
+			log.Printf("Known fingerprints: %s (reloaded on SIGHUP)", s.cfg.FingerprintDB)
+		}
+		if s.cfg.PrivateRelay != nil {
+			log.Printf("Private Relay egress ranges: %d", s.cfg.PrivateRelay.Len())
+		}
+		if s.cfg.GeoIP != nil {
+			log.Printf("GeoIP country lookup enabled")
+		}
+		if s.cfg.ASN != nil {
+			if s.cfg.HostingASNs != "" {
+				log.Printf("ASN lookup enabled, hosting ASNs: %s (reloaded on SIGHUP)", s.cfg.HostingASNs)
+			} else {
+				log.Printf("ASN lookup enabled (%d built-in hosting ASNs)", asn.DefaultHosting().Len())
+			}
+		}
+		if s.cfg.Reputation != nil {
+			log.Printf("IP reputation lookup enabled")
+		}
+		if s.cfg.CrawlerVerifier != nil {
+			log.Printf("Crawler verification enabled (IP ranges for %d crawlers)", s.cfg.CrawlerVerifier.Ranges())
+		}
+		if s.cfg.IPAnonymizer != nil {
+			log.Printf("Client IP anonymization enabled")
+		}
+		if s.cfg.CrawlDelay != nil {
+			log.Printf("Crawl-delay enforcement enabled")
+		}
+		if s.cfg.AIPolicyHeaders && s.cfg.CrawlDelay != nil {
+			log.Printf("AI policy headers enabled")
+		}
+		if s.cfg.Honeypot != nil {
+			log.Printf("Honeypot traps: %s (disallowed at /robots.txt)", strings.Join(s.cfg.Honeypot.Paths(), ", "))
+		}
+		if s.cfg.RepeatOffenders {
+			log.Printf("Repeat offender tracking enabled")
+		}
+		if b := s.cfg.MemoryBudget; b != nil {
+			for _, st := range b.States() {
+				log.Printf("Memory budget: %s capped at %d entries (%d bytes shared)", st.Name, st.Capacity, b.Bytes())
+			}
+		}
+		if s.capture != nil {
+			st := s.capture.State()
+			log.Printf("Capture: %s (enabled=%t, sample rate %.3f, raw=%t)", st.Path, st.Enabled, st.SampleRate, st.Raw)
+			if s.cfg.AdminToken != "" {
+				log.Printf("Capture admin endpoint enabled: /v1/admin/capture")
+			}
+		}
+		if cache := s.handler.classifier.Cache(); cache != nil {
+			st := cache.Stats()
+			log.Printf("Verdict cache enabled: %d entries, TTL %s", st.Capacity, cache.TTL())
+		}
+		if s.cfg.AdminToken != "" && s.handler.classifier.FeedbackEnabled() {
+			log.Printf("Feedback endpoint enabled: /v1/feedback (weight adjustment %t)", s.cfg.ClassifierCfg.FeedbackAdjust)
+		}
+		if s.cfg.Tenants != nil {
+			log.Printf("Tenants: %d (logs: %s/<tenant>.jsonl)", len(s.cfg.Tenants.Tenants()), s.cfg.LoggerConfig.LogDir)
 		}
 		log.Printf("Logs: %s", s.logger.LogPath())
+		if s.logger.Profile() == logger.ProfileMinimal {
+			log.Printf("Log profile: minimal (no client addresses, headers or User-Agents)")
+		}
+		if a := s.cfg.LoggerConfig.Audit; a.Enabled {
+			if a.SigningKey != nil {
+				log.Printf("Audit log: hash-chained, signed checkpoints in %s", logger.CheckpointPath(s.logger.LogPath()))
+			} else {
+				log.Printf("Audit log: hash-chained, unsigned (set AUDIT_SIGNING_KEY for checkpoints)")
+			}
+		}
 
-		var err error
 		if s.cfg.TLSEnabled {
 			log.Printf("TLS Certificate: %s", s.cfg.TLSCertFile)
-			err = s.startTLS()
-		} else {
-			err = s.httpServer.ListenAndServe()
 		}
-		if err != nil && err != http.ErrServerClosed {
+		if err := s.Serve(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("Server error: %v", err)
 		}
 	}()
 
-	<-done
-	log.Println("Server shutting down...")
+	if s.grpcServer != nil {
+		go func() {
+			log.Printf("gRPC classification service starting on %s", listenAddr(s.cfg.GRPCListener, s.cfg.GRPCAddr))
+			if err := s.startGRPC(); err != nil {
+				log.Fatalf("gRPC server error: %v", err)
+			}
+		}()
+	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	<-done
+	log.Printf("Server shutting down (drain delay %s, timeout %s)...", s.cfg.DrainDelay, s.cfg.ShutdownTimeout)
 
-	if err := s.httpServer.Shutdown(ctx); err != nil {
+	if err := s.Close(); err != nil {
 		return fmt.Errorf("server shutdown failed: %w", err)
 	}
 
-	if s.listener != nil {
-		_ = s.listener.Close()
+	log.Println("Server stopped")
+	return nil
+}
+
+// Serve serves HTTP, or HTTPS with TLS fingerprinting, on Listener or Addr
+// until Shutdown, after which it returns http.ErrServerClosed. Unlike
+// Start it neither handles signals nor serves gRPC.
+func (s *Server) Serve() error {
+	switch {
+	case s.cfg.TLSEnabled:
+		return s.startTLS()
+	default:
+		return s.startHTTP()
 	}
+}
 
-	if err := s.logger.Close(); err != nil {
-		log.Printf("Error closing logger: %v", err)
+// startHTTP serves plain HTTP, recording the wire header order of
+// HTTP/1.x requests
+func (s *Server) startHTTP() error {
+	listener := s.cfg.Listener
+	if listener == nil {
+		addr := s.cfg.Addr
+		if addr == "" {
+			addr = ":http"
+		}
+		var err error
+		if listener, err = net.Listen("tcp", addr); err != nil {
+			return fmt.Errorf("failed to create TCP listener: %w", err)
+		}
 	}
+	listener, err := s.recordSYNs(listener)
+	if err != nil {
+		return err
+	}
+	return s.httpServer.Serve(headerOrderListener{listener})
+}
 
-	log.Println("Server stopped")
-	return nil
+// recordSYNs wraps l to record the SYN of each connection when TCP
+// fingerprinting is enabled
+func (s *Server) recordSYNs(l net.Listener) (net.Listener, error) {
+	if s.syns == nil {
+		return l, nil
+	}
+	wrapped, err := s.syns.Listen(l)
+	if err != nil {
+		return nil, fmt.Errorf("TCP fingerprinting unavailable: %w", err)
+	}
+	log.Printf("TCP fingerprinting active (JA4T)")
+	return wrapped, nil
 }
 
 // startTLS starts the server with TLS and fingerprint listener
@@ -182,14 +680,26 @@ func (s *Server) startTLS() error {
 		return fmt.Errorf("failed to load TLS certificate: %w", err)
 	}
 
-	// Create base TCP listener
-	tcpListener, err := net.Listen("tcp", s.cfg.Addr)
-	if err != nil {
-		return fmt.Errorf("failed to create TCP listener: %w", err)
+	// Create base TCP listener, unless one was passed in
+	tcpListener := s.cfg.Listener
+	if tcpListener == nil {
+		tcpListener, err = net.Listen("tcp", s.cfg.Addr)
+		if err != nil {
+			return fmt.Errorf("failed to create TCP listener: %w", err)
+		}
+	}
+	if tcpListener, err = s.recordSYNs(tcpListener); err != nil {
+		return err
+	}
+	if s.h3Server != nil {
+		if err := s.startHTTP3(cert, tcpListener.Addr()); err != nil {
+			return err
+		}
 	}
 
-	// Wrap with fingerprint listener to capture ClientHello
-	fpListener := fingerprintlistener.NewListener(tcpListener)
+	// Wrap with fingerprint listener to capture ClientHello, and record
+	// the ServerHello we answer with
+	fpListener := serverHelloListener{fingerprintlistener.NewListener(tcpListener)}
 	s.listener = fpListener
 
 	// Configure TLS on the http.Server (not on listener)
@@ -200,19 +710,70 @@ func (s *Server) startTLS() error {
 		NextProtos:   []string{"h2", "http/1.1"},
 	}
 
-	log.Printf("TLS fingerprinting active (JA3/JA4)")
+	log.Printf("TLS fingerprinting active (JA3/JA4, JA3S/JA4S)")
 	// Use ServeTLS which handles TLS on top of our fingerprint listener
 	return s.httpServer.ServeTLS(fpListener, "", "")
 }
 
-// Close gracefully shuts down the server
+// startGRPC serves the gRPC classification service on GRPCListener or
+// GRPCAddr
+func (s *Server) startGRPC() error {
+	lis := s.cfg.GRPCListener
+	if lis == nil {
+		var err error
+		if lis, err = net.Listen("tcp", s.cfg.GRPCAddr); err != nil {
+			return fmt.Errorf("failed to create gRPC listener: %w", err)
+		}
+	}
+	return s.grpcServer.Serve(lis)
+}
+
+// listenAddr describes where a service listens: the pre-opened listener's
+// address, or the address to bind
+func listenAddr(l net.Listener, addr string) string {
+	if l != nil {
+		return l.Addr().String() + " (socket activation)"
+	}
+	return addr
+}
+
+// Close gracefully shuts down the server within ShutdownTimeout (see
+// Shutdown)
 func (s *Server) Close() error {
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	ctx := context.Background()
+	if s.cfg.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.cfg.ShutdownTimeout)
+		defer cancel()
+	}
+	return s.Shutdown(ctx)
+}
 
-	if err := s.httpServer.Shutdown(ctx); err != nil {
-		return err
+// closeEvents closes the event bus, if any, flushing its sinks
+func closeEvents(b *events.Bus) {
+	if b == nil {
+		return
+	}
+	if err := b.Close(); err != nil {
+		log.Printf("Error closing event bus: %v", err)
 	}
+}
 
-	return s.logger.Close()
+// closeCapture closes the capture file, if any
+func closeCapture(c *capture.Capturer) {
+	if c == nil {
+		return
+	}
+	if err := c.Close(); err != nil {
+		log.Printf("Error closing capture file: %v", err)
+	}
+}
+
+// closeLoggers closes per-tenant loggers
+func closeLoggers(logs map[string]*logger.Logger) {
+	for id, l := range logs {
+		if err := l.Close(); err != nil {
+			log.Printf("Error closing logger for tenant %s: %v", id, err)
+		}
+	}
 }
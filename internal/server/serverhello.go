@@ -0,0 +1,121 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/psanford/tlsfingerprint/fingerprintlistener"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// maxServerHelloBytes bounds the handshake bytes buffered per connection
+// while waiting for a complete ServerHello record
+const maxServerHelloBytes = 16 << 10
+
+// serverHelloListener records the ServerHello written on each connection
+// accepted from a fingerprint listener, for JA3S and JA4S, and times the
+// client's answer to it, for JA4L
+type serverHelloListener struct {
+	net.Listener
+}
+
+// Accept wraps fingerprinted connections in a serverHelloConn
+func (l serverHelloListener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	if fpConn, ok := c.(fingerprintlistener.Conn); ok {
+		return &serverHelloConn{Conn: fpConn}, nil
+	}
+	return c, nil
+}
+
+// serverHelloConn parses the first ServerHello written to the connection.
+// It still exposes the ClientHello fingerprint of the wrapped connection.
+type serverHelloConn struct {
+	fingerprintlistener.Conn
+
+	mu      sync.Mutex
+	buf     []byte
+	done    bool
+	hello   *fingerprint.ServerHello
+	helloAt time.Time     // When the ServerHello flight was written
+	latency time.Duration // Half the time until the client's next flight
+	timed   bool
+}
+
+// Write records handshake bytes until the ServerHello is complete
+func (c *serverHelloConn) Write(b []byte) (int, error) {
+	c.mu.Lock()
+	if !c.done {
+		c.record(b)
+	}
+	c.mu.Unlock()
+	n, err := c.Conn.Write(b)
+	c.mu.Lock()
+	if c.hello != nil && c.helloAt.IsZero() {
+		c.helloAt = time.Now()
+	}
+	c.mu.Unlock()
+	return n, err
+}
+
+// Read times the first bytes the client sends after the ServerHello
+func (c *serverHelloConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	if n > 0 {
+		c.mu.Lock()
+		if !c.timed && !c.helloAt.IsZero() {
+			c.latency, c.timed = time.Since(c.helloAt)/2, true
+		}
+		c.mu.Unlock()
+	}
+	return n, err
+}
+
+// record buffers b and parses the ServerHello once its record is complete.
+// A HelloRetryRequest is skipped for the ServerHello that follows it.
+func (c *serverHelloConn) record(b []byte) {
+	c.buf = append(c.buf, b...)
+	for len(c.buf) >= 5 {
+		n := 5 + (int(c.buf[3])<<8 | int(c.buf[4]))
+		if len(c.buf) < n {
+			if len(c.buf) > maxServerHelloBytes {
+				c.done, c.buf = true, nil
+			}
+			return
+		}
+		if c.buf[0] == 0x14 {
+			// ChangeCipherSpec following a HelloRetryRequest
+			c.buf = c.buf[n:]
+			continue
+		}
+		sh, err := fingerprint.ParseServerHello(c.buf[:n])
+		if errors.Is(err, fingerprint.ErrHelloRetryRequest) {
+			c.buf = c.buf[n:]
+			continue
+		}
+		c.hello, c.done, c.buf = sh, true, nil
+		return
+	}
+}
+
+// ServerHello returns the ServerHello sent, or nil when the handshake has
+// not written one or it could not be parsed
+func (c *serverHelloConn) ServerHello() *fingerprint.ServerHello {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.hello
+}
+
+// HandshakeLatency returns the client's one-way latency estimated from the
+// handshake, or 0 before the client answered the ServerHello
+func (c *serverHelloConn) HandshakeLatency() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.latency
+}
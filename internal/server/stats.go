@@ -0,0 +1,52 @@
+package server
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+)
+
+// StatsResponse represents the classification statistics response
+type StatsResponse struct {
+	TotalRequests int64   `json:"total_requests"`
+	Browser       int64   `json:"browser"`
+	Bot           int64   `json:"bot"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	Version       string  `json:"version"`
+	Tenant        string  `json:"tenant,omitempty"` // Tenant the counters belong to (empty = all traffic)
+}
+
+// stats holds classification counters since process start
+type stats struct {
+	started time.Time
+	total   atomic.Int64
+	browser atomic.Int64
+	bot     atomic.Int64
+}
+
+func newStats() *stats {
+	return &stats{started: time.Now()}
+}
+
+// record counts a classification outcome
+func (s *stats) record(classification string) {
+	s.total.Add(1)
+	switch classification {
+	case classifier.ClassificationBrowser:
+		s.browser.Add(1)
+	case classifier.ClassificationBot:
+		s.bot.Add(1)
+	}
+}
+
+// snapshot returns current counters
+func (s *stats) snapshot() StatsResponse {
+	return StatsResponse{
+		TotalRequests: s.total.Load(),
+		Browser:       s.browser.Load(),
+		Bot:           s.bot.Load(),
+		UptimeSeconds: time.Since(s.started).Seconds(),
+		Version:       version,
+	}
+}
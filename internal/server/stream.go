@@ -0,0 +1,135 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// streamBuffer is the number of events queued per subscriber. Events for
+// a subscriber that falls further behind are dropped, so a slow client
+// never delays classification.
+const streamBuffer = 256
+
+// streamKeepAlive is the interval of SSE comments that keep idle
+// connections open through proxies
+const streamKeepAlive = 15 * time.Second
+
+// stream fans out log entries to live subscribers of /stream
+type stream struct {
+	mu     sync.Mutex
+	subs   map[chan []byte]struct{}
+	done   chan struct{} // closed when the server drains
+	closed bool
+}
+
+func newStream() *stream {
+	return &stream{subs: map[chan []byte]struct{}{}, done: make(chan struct{})}
+}
+
+// close ends every subscription so streams do not hold up a shutdown
+func (s *stream) close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.closed {
+		s.closed = true
+		close(s.done)
+	}
+}
+
+// subscribe registers a subscriber channel
+func (s *stream) subscribe() chan []byte {
+	ch := make(chan []byte, streamBuffer)
+	s.mu.Lock()
+	s.subs[ch] = struct{}{}
+	s.mu.Unlock()
+	return ch
+}
+
+// unsubscribe removes a subscriber channel
+func (s *stream) unsubscribe(ch chan []byte) {
+	s.mu.Lock()
+	delete(s.subs, ch)
+	s.mu.Unlock()
+}
+
+// publish sends an entry to every subscriber without blocking
+func (s *stream) publish(entry logger.LogEntry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.subs) == 0 {
+		return
+	}
+	data, err := json.Marshal(entry)
+	if err != nil {
+		log.Printf("Error encoding stream event: %v", err)
+		return
+	}
+	for ch := range s.subs {
+		select {
+		case ch <- data:
+		default:
+		}
+	}
+}
+
+// SetStreaming enables or disables the /stream endpoint. It must be set
+// before the router is built.
+func (h *Handler) SetStreaming(enabled bool) {
+	if enabled && h.stream == nil {
+		h.stream = newStream()
+	} else if !enabled {
+		h.stream = nil
+	}
+}
+
+// HandleStream streams every classification as a Server-Sent Event whose
+// data is a request log entry
+func (h *Handler) HandleStream(w http.ResponseWriter, r *http.Request) {
+	if h.stream == nil {
+		notFound(w, r)
+		return
+	}
+
+	// The stream outlives the server's write timeout
+	rc := http.NewResponseController(w)
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	ch := h.stream.subscribe()
+	defer h.stream.unsubscribe(ch)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	ticker := time.NewTicker(streamKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.stream.done:
+			return
+		case data := <-ch:
+			if _, err := fmt.Fprintf(w, "event: classification\ndata: %s\n\n", data); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+// errRateLimited is returned for requests over their tenant's rate limit
+var errRateLimited = errors.New("tenant rate limit exceeded")
+
+// scope is the classifier, log and counters a request is handled with:
+// those of its tenant, or the server defaults
+type scope struct {
+	tenant     string // empty for the default scope
+	keyID      string // KeyID of the API key the request presented
+	classifier *classifier.Classifier
+	logger     *logger.Logger
+	stats      *stats
+	limiter    *tenant.Limiter
+}
+
+// SetTenants attributes requests to tenants. Each tenant is logged to its
+// logger in logs, or to the default log when it has none.
+func (h *Handler) SetTenants(reg *tenant.Registry, logs map[string]*logger.Logger) {
+	h.tenants = reg
+	h.scopes = nil
+	if reg == nil {
+		return
+	}
+	h.scopes = make(map[string]*scope, len(reg.Tenants()))
+	for _, t := range reg.Tenants() {
+		l := logs[t.ID]
+		if l == nil {
+			l = h.logger
+		}
+		h.scopes[t.ID] = &scope{
+			tenant:     t.ID,
+			classifier: t.Classifier,
+			logger:     l,
+			stats:      newStats(),
+			limiter:    t.Limiter,
+		}
+	}
+}
+
+// defaultScope returns the scope of requests matching no tenant
+func (h *Handler) defaultScope() *scope {
+	return &scope{classifier: h.classifier, logger: h.logger, stats: h.stats}
+}
+
+// scopeFor returns a tenant's scope for a request presenting apiKey,
+// taking a token from the tenant's rate limit. The limit's state is
+// returned when the tenant has one, rate limited or not.
+func (h *Handler) scopeFor(t *tenant.Tenant, apiKey string) (*scope, *tenant.Status, error) {
+	if t == nil {
+		return h.defaultScope(), nil, nil
+	}
+	sc := h.scopes[t.ID]
+	keyID := KeyID(apiKey)
+	var st *tenant.Status
+	if sc.limiter != nil {
+		s := sc.limiter.Take(time.Now())
+		st = &s
+		if !s.Allowed {
+			h.usage.recordRateLimited(t.ID, keyID)
+			return nil, st, errRateLimited
+		}
+	}
+	if keyID != "" {
+		keyed := *sc
+		keyed.keyID = keyID
+		sc = &keyed
+	}
+	return sc, st, nil
+}
+
+// scope resolves the request's tenant. It responds with a 401 problem for
+// unknown API keys and a 429 problem for rate limited tenants, reporting
+// false when it did.
+func (h *Handler) scope(w http.ResponseWriter, r *http.Request) (*scope, bool) {
+	if h.tenants == nil {
+		return h.defaultScope(), true
+	}
+	t, err := h.tenants.Resolve(r)
+	if err != nil {
+		writeProblem(w, r, http.StatusUnauthorized, CodeUnknownAPIKey, "the "+tenant.APIKeyHeader+" header does not match any tenant")
+		return nil, false
+	}
+	sc, st, err := h.scopeFor(t, r.Header.Get(tenant.APIKeyHeader))
+	if st != nil {
+		setTenantRateLimit(w, t.ID, *st)
+	}
+	if err != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(max(1, ceilSeconds(st.Reset))))
+		writeProblem(w, r, http.StatusTooManyRequests, CodeRateLimited, "tenant "+t.ID+" exceeded its rate limit")
+		return nil, false
+	}
+	return sc, true
+}
+
+// keyScope resolves the scope of endpoints exposing logged or billed
+// data: a tenant's API key selects that tenant, and requests without one
+// need the admin token. Host never grants access. It responds with a 401
+// problem and reports false when neither credential is valid.
+func (h *Handler) keyScope(w http.ResponseWriter, r *http.Request) (*scope, bool) {
+	if key := r.Header.Get(tenant.APIKeyHeader); key != "" && h.tenants != nil {
+		t := h.tenants.ByAPIKey(key)
+		if t == nil {
+			writeProblem(w, r, http.StatusUnauthorized, CodeUnknownAPIKey, "the "+tenant.APIKeyHeader+" header does not match any tenant")
+			return nil, false
+		}
+		return h.scopes[t.ID], true
+	}
+	if !h.authorizeAdmin(w, r) {
+		return nil, false
+	}
+	return h.defaultScope(), true
+}
+
+// statsScope resolves the request's tenant for read-only endpoints,
+// without spending rate limit
+func (h *Handler) statsScope(r *http.Request) (*scope, error) {
+	if h.tenants == nil {
+		return h.defaultScope(), nil
+	}
+	t, err := h.tenants.Resolve(r)
+	if err != nil || t == nil {
+		return h.defaultScope(), err
+	}
+	return h.scopes[t.ID], nil
+}
@@ -0,0 +1,200 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/csv"
+	"encoding/hex"
+	"log"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+)
+
+// UsageRecord is the metered usage of one tenant API key. Requests
+// attributed by Host have an empty KeyID.
+type UsageRecord struct {
+	Tenant      string `json:"tenant"`              // Empty for requests matching no tenant
+	KeyID       string `json:"key_id,omitempty"`    // Non-secret API key identifier (see KeyID)
+	Requests    int64  `json:"requests"`            // Classified requests
+	Browser     int64  `json:"browser"`             // Requests classified as browser
+	Bot         int64  `json:"bot"`                 // Requests classified as bot
+	Challenged  int64  `json:"challenged"`          // Bots sent to the CAPTCHA or a Private Access Token challenge
+	RateLimited int64  `json:"rate_limited"`        // Requests rejected over the tenant's rate limit
+	LastSeen    string `json:"last_seen,omitempty"` // Last request time (RFC 3339)
+}
+
+// UsageResponse represents the usage accounting response
+type UsageResponse struct {
+	Since   time.Time     `json:"since"`
+	Records []UsageRecord `json:"records"`
+	Version string        `json:"version"`
+}
+
+// usageCSVHeader is the header row of the CSV usage export
+var usageCSVHeader = []string{"tenant", "key_id", "requests", "browser", "bot", "challenged", "rate_limited", "last_seen"}
+
+// KeyID returns the identifier an API key is reported under in usage
+// records: the first 12 hex digits of its SHA-256, so exports never
+// contain the key itself
+func KeyID(apiKey string) string {
+	if apiKey == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:6])
+}
+
+// usageKey identifies a usage row
+type usageKey struct {
+	tenant string
+	keyID  string
+}
+
+// usageCounters are the counters of one usage row
+type usageCounters struct {
+	requests    atomic.Int64
+	browser     atomic.Int64
+	bot         atomic.Int64
+	challenged  atomic.Int64
+	rateLimited atomic.Int64
+	lastSeen    atomic.Int64 // Unix nanoseconds
+}
+
+// usage holds per-tenant, per-key counters since process start
+type usage struct {
+	started time.Time
+	mu      sync.RWMutex
+	rows    map[usageKey]*usageCounters
+}
+
+func newUsage() *usage {
+	return &usage{started: time.Now(), rows: map[usageKey]*usageCounters{}}
+}
+
+// row returns the counters of a tenant key, creating them on first use
+func (u *usage) row(tenantID, keyID string) *usageCounters {
+	k := usageKey{tenantID, keyID}
+	u.mu.RLock()
+	c := u.rows[k]
+	u.mu.RUnlock()
+	if c != nil {
+		return c
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if c = u.rows[k]; c == nil {
+		c = &usageCounters{}
+		u.rows[k] = c
+	}
+	return c
+}
+
+// recordClassified counts a classified request
+func (u *usage) recordClassified(sc *scope, classification string) {
+	c := u.row(sc.tenant, sc.keyID)
+	c.requests.Add(1)
+	switch classification {
+	case classifier.ClassificationBrowser:
+		c.browser.Add(1)
+	case classifier.ClassificationBot:
+		c.bot.Add(1)
+	}
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// recordChallenged counts a CAPTCHA redirect or Private Access Token
+// challenge
+func (u *usage) recordChallenged(sc *scope) {
+	u.row(sc.tenant, sc.keyID).challenged.Add(1)
+}
+
+// recordRateLimited counts a request rejected by a tenant rate limit
+func (u *usage) recordRateLimited(tenantID, keyID string) {
+	c := u.row(tenantID, keyID)
+	c.rateLimited.Add(1)
+	c.lastSeen.Store(time.Now().UnixNano())
+}
+
+// snapshot returns the usage records of a tenant (all tenants when
+// tenantID is empty), sorted by tenant and key
+func (u *usage) snapshot(tenantID string) UsageResponse {
+	u.mu.RLock()
+	records := make([]UsageRecord, 0, len(u.rows))
+	for k, c := range u.rows {
+		if tenantID != "" && k.tenant != tenantID {
+			continue
+		}
+		rec := UsageRecord{
+			Tenant:      k.tenant,
+			KeyID:       k.keyID,
+			Requests:    c.requests.Load(),
+			Browser:     c.browser.Load(),
+			Bot:         c.bot.Load(),
+			Challenged:  c.challenged.Load(),
+			RateLimited: c.rateLimited.Load(),
+		}
+		if ns := c.lastSeen.Load(); ns != 0 {
+			rec.LastSeen = time.Unix(0, ns).UTC().Format(time.RFC3339)
+		}
+		records = append(records, rec)
+	}
+	u.mu.RUnlock()
+
+	slices.SortFunc(records, func(a, b UsageRecord) int {
+		if c := strings.Compare(a.Tenant, b.Tenant); c != 0 {
+			return c
+		}
+		return strings.Compare(a.KeyID, b.KeyID)
+	})
+	return UsageResponse{Since: u.started, Records: records, Version: version}
+}
+
+// HandleUsage returns usage counters per tenant and API key since server
+// start, as JSON or, with ?format=csv, as a CSV export. With tenants
+// configured, a tenant's API key only sees that tenant's records and the
+// records of all tenants require the admin token; Host grants nothing.
+func (h *Handler) HandleUsage(w http.ResponseWriter, r *http.Request) {
+	sc := h.defaultScope()
+	if h.tenants != nil {
+		var ok bool
+		if sc, ok = h.keyScope(w, r); !ok {
+			return
+		}
+	}
+	resp := h.usage.snapshot(sc.tenant)
+
+	switch format := r.URL.Query().Get("format"); format {
+	case "", "json":
+		writeJSON(w, resp)
+	case "csv":
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="usage.csv"`)
+		cw := csv.NewWriter(w)
+		_ = cw.Write(usageCSVHeader)
+		for _, rec := range resp.Records {
+			_ = cw.Write([]string{
+				rec.Tenant,
+				rec.KeyID,
+				strconv.FormatInt(rec.Requests, 10),
+				strconv.FormatInt(rec.Browser, 10),
+				strconv.FormatInt(rec.Bot, 10),
+				strconv.FormatInt(rec.Challenged, 10),
+				strconv.FormatInt(rec.RateLimited, 10),
+				rec.LastSeen,
+			})
+		}
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			log.Printf("Error writing usage export: %v", err)
+		}
+	default:
+		writeProblem(w, r, http.StatusBadRequest, CodeInvalidRequest, "unsupported format "+strconv.Quote(format)+", use json or csv")
+	}
+}
@@ -0,0 +1,112 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+)
+
+// RequestValidator validates incoming requests against the OpenAPI spec
+type RequestValidator struct {
+	router routers.Router
+}
+
+// NewRequestValidator creates a validator for the given OpenAPI document
+func NewRequestValidator(doc *openapi3.T) (*RequestValidator, error) {
+	// Match on paths only, regardless of the host the server is reached on
+	doc.Servers = nil
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OpenAPI router: %w", err)
+	}
+	return &RequestValidator{router: router}, nil
+}
+
+// Wrap returns a handler that rejects requests not matching the spec with
+// a 400 validation_failed problem before calling next. Bodies are limited
+// to maxRequestBodyBytes before the validator reads them.
+func (v *RequestValidator) Wrap(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The spec describes paths relative to the /v1 base; legacy aliases share them
+		lookup := r
+		if p := unversionedPath(r.URL.Path); p != r.URL.Path {
+			lookup = r.Clone(r.Context())
+			lookup.URL.Path = p
+		}
+
+		route, pathParams, err := v.router.FindRoute(lookup)
+		if err != nil {
+			// Unknown route or method: let the handler produce its usual 404/405
+			next(w, r)
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxRequestBodyBytes)
+		input := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options: &openapi3filter.Options{
+				MultiError:         true,
+				AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			},
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), input); err != nil {
+			writeValidationError(w, r, err)
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+// writeValidationError renders validation failures as a problem, and
+// oversized bodies as a 413
+func writeValidationError(w http.ResponseWriter, r *http.Request, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeProblem(w, r, http.StatusRequestEntityTooLarge, CodePayloadTooLarge,
+			fmt.Sprintf("request body exceeds %d bytes", tooLarge.Limit))
+		return
+	}
+	writeProblem(w, r, http.StatusBadRequest, CodeValidationFailed,
+		"request does not match the API specification", validationDetails(err)...)
+}
+
+// validationDetails flattens (multi-)errors from the validator into
+// concise "pointer: reason" messages
+func validationDetails(err error) []string {
+	var multi openapi3.MultiError
+	if errors.As(err, &multi) {
+		var details []string
+		for _, e := range multi {
+			details = append(details, validationDetails(e)...)
+		}
+		return details
+	}
+
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) && reqErr.Err != nil {
+		var inner openapi3.MultiError
+		if errors.As(reqErr.Err, &inner) {
+			return validationDetails(inner)
+		}
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		return []string{fmt.Sprintf("%s: %s", schemaErr.JSONPointer(), schemaErr.Reason)}
+	}
+	if errors.As(err, &reqErr) {
+		if reqErr.Err != nil {
+			return []string{reqErr.Err.Error()}
+		}
+		return []string{reqErr.Reason}
+	}
+	return []string{err.Error()}
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"log"
+	"net/http"
+	"slices"
+
+	"github.com/muliwe/go-client-classifier/internal/buildinfo"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+)
+
+// BuiltinRuleset is the ruleset version reported for the built-in patterns
+// and weights
+const BuiltinRuleset = "builtin"
+
+// version is the server version reported in responses
+var version = buildinfo.Version()
+
+// VersionResponse describes the running server build and configuration
+type VersionResponse struct {
+	buildinfo.Info
+	Ruleset        string            `json:"ruleset"`                   // Version of the default ruleset
+	TenantRulesets map[string]string `json:"tenant_rulesets,omitempty"` // Ruleset version by tenant ID
+	Features       []string          `json:"features"`                  // Enabled optional features
+}
+
+// SetRulesetVersion records the version of the ruleset the default
+// classifier was built from (empty = built-in rules)
+func (h *Handler) SetRulesetVersion(v string) {
+	h.ruleset = v
+}
+
+// SetFeatures records the enabled optional features reported by /version
+func (h *Handler) SetFeatures(features []string) {
+	h.features = features
+}
+
+// HandleVersion reports the build information, ruleset versions and
+// enabled features
+func (h *Handler) HandleVersion(w http.ResponseWriter, r *http.Request) {
+	resp := VersionResponse{
+		Info:     buildinfo.Get(),
+		Ruleset:  h.ruleset,
+		Features: h.features,
+	}
+	if resp.Ruleset == "" {
+		resp.Ruleset = BuiltinRuleset
+	}
+	if resp.Features == nil {
+		resp.Features = []string{}
+	}
+	if h.tenants != nil {
+		resp.TenantRulesets = make(map[string]string)
+		for _, t := range h.tenants.Tenants() {
+			switch {
+			case t.Ruleset == "":
+				resp.TenantRulesets[t.ID] = resp.Ruleset
+			case t.RulesetVersion == "":
+				resp.TenantRulesets[t.ID] = "unversioned"
+			default:
+				resp.TenantRulesets[t.ID] = t.RulesetVersion
+			}
+		}
+	}
+
+	w.Header()["Content-Type"] = jsonContentType
+	if err := encodeJSON(w, resp); err != nil {
+		log.Printf("Error encoding version response: %v", err)
+	}
+}
+
+// enabledFeatures lists the optional features cfg turns on, sorted
+func enabledFeatures(cfg Config) []string {
+	features := []string{}
+	for name, on := range map[string]bool{
+		"admin":                cfg.AdminToken != "",
+		"ai_policy_headers":    cfg.AIPolicyHeaders && cfg.CrawlDelay != nil,
+		"api_validation":       cfg.ValidateAPI,
+		"asn":                  cfg.ASN != nil,
+		"audit_log":            cfg.LoggerConfig.Audit.Enabled,
+		"bot_score_header":     cfg.BotScoreHeader != "",
+		"calibration":          cfg.ClassifierCfg.Calibration != nil,
+		"captcha":              cfg.Captcha != nil,
+		"capture":              cfg.Capture.Path != "",
+		"challenge_tokens":     cfg.ChallengeTokens != nil,
+		"crawl_delay":          cfg.CrawlDelay != nil,
+		"crawler_verification": cfg.CrawlerVerifier != nil,
+		"debug":                cfg.EnableDebug,
+		"enrichment_budget":    cfg.ClassifierCfg.EnrichmentTimeout > 0 || len(cfg.ClassifierCfg.EnrichmentBudgets) > 0,
+		"events":               cfg.Events != nil,
+		"feedback":             cfg.AdminToken != "" && (cfg.ClassifierCfg.Feedback != nil || cfg.ClassifierCfg.FeedbackAdjust),
+		"fingerprint_db":       cfg.FingerprintDB != "",
+		"geoip":                cfg.GeoIP != nil,
+		"grpc":                 cfg.GRPCAddr != "" || cfg.GRPCListener != nil,
+		"honeypot":             cfg.Honeypot != nil,
+		"http3":                cfg.HTTP3,
+		"ip_anonymization":     cfg.IPAnonymizer != nil,
+		"ip_reputation":        cfg.Reputation != nil,
+		"memory_budget":        cfg.MemoryBudget != nil,
+		"ml_backend":           cfg.ClassifierCfg.Backend == classifier.BackendML && cfg.ClassifierCfg.Model != nil,
+		"private_relay":        cfg.PrivateRelay != nil,
+		"private_tokens":       cfg.PrivateTokens != nil,
+		"rate_tracking":        cfg.RateTracking,
+		"repeat_offenders":     cfg.RepeatOffenders,
+		"session_tracking":     cfg.SessionTracking,
+		"socket_activation":    cfg.Listener != nil || cfg.GRPCListener != nil,
+		"stream":               cfg.EnableStream,
+		"tcp_fingerprint":      cfg.TCPFingerprint,
+		"tenants":              cfg.Tenants != nil,
+		"tls":                  cfg.TLSEnabled,
+		"verdict_cache":        cfg.ClassifierCfg.CacheSize > 0,
+	} {
+		if on {
+			features = append(features, name)
+		}
+	}
+	slices.Sort(features)
+	return features
+}
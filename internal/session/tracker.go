@@ -0,0 +1,226 @@
+package session
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Config holds session tracker configuration
+type Config struct {
+	MaxSessions int           // Maximum number of tracked sessions (oldest evicted first)
+	WindowSize  int           // Number of recent request timestamps kept per session
+	IdleTimeout time.Duration // Session expires after this long without requests
+}
+
+// DefaultConfig returns default session tracker configuration
+func DefaultConfig() Config {
+	return Config{
+		MaxSessions: 10000,
+		WindowSize:  16,
+		IdleTimeout: 30 * time.Minute,
+	}
+}
+
+// evictDivisor sets the batch evicted when the tracker is full: one
+// session in evictDivisor
+const evictDivisor = 16
+
+// Tracker records request timestamps per session and computes
+// inter-request timing statistics
+type Tracker struct {
+	mu       sync.Mutex
+	cfg      Config
+	sessions map[string]*entry
+}
+
+// entry holds recent request timestamps for a single session
+type entry struct {
+	times    []time.Time // Ring buffer of recent request times
+	next     int         // Next write position in times
+	count    int         // Total requests observed
+	pages    int         // Page requests observed
+	assets   int         // Subresource requests observed
+	lastSeen time.Time
+}
+
+// New creates a new session tracker
+func New(cfg Config) *Tracker {
+	if cfg.MaxSessions <= 0 {
+		cfg.MaxSessions = DefaultConfig().MaxSessions
+	}
+	if cfg.WindowSize < 2 {
+		cfg.WindowSize = DefaultConfig().WindowSize
+	}
+	if cfg.IdleTimeout <= 0 {
+		cfg.IdleTimeout = DefaultConfig().IdleTimeout
+	}
+	return &Tracker{
+		cfg:      cfg,
+		sessions: make(map[string]*entry),
+	}
+}
+
+// Key derives a session key from the request.
+// Sessions are identified by client IP and User-Agent.
+func Key(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return AddrKey(host, r.Header.Get("User-Agent"))
+}
+
+// AddrKey derives a session key from a client address, which may be
+// anonymized, and a User-Agent
+func AddrKey(addr, userAgent string) string {
+	return addr + "|" + userAgent
+}
+
+// Observe records a request for the session, a page's subresource if
+// asset is set, and returns timing statistics over the session's recent
+// request window along with its page and asset counts
+func (t *Tracker) Observe(key string, now time.Time, asset bool) fingerprint.SessionFingerprint {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	e, ok := t.sessions[key]
+	if ok && now.Sub(e.lastSeen) > t.cfg.IdleTimeout {
+		// Idle session expired, start over
+		ok = false
+	}
+	if !ok {
+		if _, exists := t.sessions[key]; !exists && len(t.sessions) >= t.cfg.MaxSessions {
+			t.evict(now)
+		}
+		e = &entry{times: make([]time.Time, t.cfg.WindowSize)}
+		t.sessions[key] = e
+	}
+
+	e.times[e.next] = now
+	e.next = (e.next + 1) % len(e.times)
+	e.count++
+	if asset {
+		e.assets++
+	} else {
+		e.pages++
+	}
+	e.lastSeen = now
+
+	return e.stats()
+}
+
+// Len returns the number of tracked sessions
+func (t *Tracker) Len() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.sessions)
+}
+
+// EntryBytes estimates the memory held per session: its timestamp window,
+// key and map overhead
+func (t *Tracker) EntryBytes() int {
+	return t.cfg.WindowSize*24 + 256
+}
+
+// SetCapacity changes the maximum number of tracked sessions, evicting the
+// least recently seen sessions beyond it
+func (t *Tracker) SetCapacity(n int) {
+	if n <= 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.cfg.MaxSessions = n
+	t.trim(n)
+}
+
+// evict makes room for new sessions: it removes expired sessions, then
+// the least recently seen ones until MaxSessions/evictDivisor slots are
+// free, so the full scan runs once per batch of new sessions rather than
+// for each. Caller must hold the lock.
+func (t *Tracker) evict(now time.Time) {
+	for k, e := range t.sessions {
+		if now.Sub(e.lastSeen) > t.cfg.IdleTimeout {
+			delete(t.sessions, k)
+		}
+	}
+	t.trim(t.cfg.MaxSessions - max(1, t.cfg.MaxSessions/evictDivisor))
+}
+
+// trim evicts the least recently seen sessions beyond n. Caller must hold
+// the lock.
+func (t *Tracker) trim(n int) {
+	excess := len(t.sessions) - n
+	if excess <= 0 {
+		return
+	}
+	keys := make([]string, 0, len(t.sessions))
+	for k := range t.sessions {
+		keys = append(keys, k)
+	}
+	slices.SortFunc(keys, func(a, b string) int {
+		return t.sessions[a].lastSeen.Compare(t.sessions[b].lastSeen)
+	})
+	for _, k := range keys[:excess] {
+		delete(t.sessions, k)
+	}
+}
+
+// stats computes inter-request interval statistics for the entry
+func (e *entry) stats() fingerprint.SessionFingerprint {
+	s := fingerprint.SessionFingerprint{
+		Available:     true,
+		RequestCount:  e.count,
+		PageRequests:  e.pages,
+		AssetRequests: e.assets,
+	}
+
+	// Collect timestamps in chronological order
+	n := min(e.count, len(e.times))
+	ordered := make([]time.Time, 0, n)
+	start := (e.next - n + len(e.times)) % len(e.times)
+	for i := 0; i < n; i++ {
+		ordered = append(ordered, e.times[(start+i)%len(e.times)])
+	}
+
+	if len(ordered) < 2 {
+		return s
+	}
+
+	intervals := make([]float64, 0, len(ordered)-1)
+	for i := 1; i < len(ordered); i++ {
+		intervals = append(intervals, float64(ordered[i].Sub(ordered[i-1]).Microseconds())/1000)
+	}
+
+	var sum float64
+	minInterval := intervals[0]
+	for _, iv := range intervals {
+		sum += iv
+		minInterval = min(minInterval, iv)
+	}
+	mean := sum / float64(len(intervals))
+
+	var variance float64
+	for _, iv := range intervals {
+		variance += (iv - mean) * (iv - mean)
+	}
+	variance /= float64(len(intervals))
+	stdDev := math.Sqrt(variance)
+
+	s.IntervalCount = len(intervals)
+	s.MeanIntervalMs = mean
+	s.MinIntervalMs = minInterval
+	s.IntervalStdDevMs = stdDev
+	if mean > 0 {
+		s.IntervalJitter = stdDev / mean
+	}
+
+	return s
+}
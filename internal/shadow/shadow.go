@@ -0,0 +1,249 @@
+// Package shadow replays logged requests through two classifier
+// configurations and reports the requests whose classification flips,
+// together with the scoring rules that changed between the two runs.
+//
+// It is meant for rule reviews: run the current ruleset as the base and
+// the proposed one as the candidate over production logs, and inspect
+// which real requests the change would reclassify and why.
+package shadow
+
+import (
+	"errors"
+	"io"
+	"slices"
+	"sort"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+)
+
+// Rule changes between the base and candidate runs of a request
+const (
+	ChangeAdded      = "added"      // Fired only with the candidate
+	ChangeRemoved    = "removed"    // Fired only with the base
+	ChangeReweighted = "reweighted" // Fired with both, for different points
+)
+
+// RuleChange is a scoring rule that contributed differently to a request
+type RuleChange struct {
+	Rule            string `json:"rule"`
+	Side            string `json:"side"` // Class the rule scores towards
+	Change          string `json:"change"`
+	BaseWeight      int    `json:"base_weight"`      // 0 when the rule did not fire
+	CandidateWeight int    `json:"candidate_weight"` // 0 when the rule did not fire
+}
+
+// Flip is a request classified differently by the two configurations
+type Flip struct {
+	File               string                `json:"file,omitempty"`
+	Line               int                   `json:"line"` // Line in the log file
+	RequestID          string                `json:"request_id,omitempty"`
+	UserAgent          string                `json:"user_agent"`
+	From               string                `json:"from"` // Base classification
+	To                 string                `json:"to"`   // Candidate classification
+	BaseScore          int                   `json:"base_score"`
+	CandidateScore     int                   `json:"candidate_score"`
+	Changes            []RuleChange          `json:"changes,omitempty"`
+	ThresholdOnly      bool                  `json:"threshold_only,omitempty"` // Same rules, flipped by the threshold alone
+	BaseBreakdown      fingerprint.Breakdown `json:"base_breakdown"`
+	CandidateBreakdown fingerprint.Breakdown `json:"candidate_breakdown"`
+}
+
+// RuleImpact counts the flips a changed rule took part in
+type RuleImpact struct {
+	Rule            string `json:"rule"`
+	Side            string `json:"side"`
+	Change          string `json:"change"`
+	BaseWeight      int    `json:"base_weight"`
+	CandidateWeight int    `json:"candidate_weight"`
+	Flips           int    `json:"flips"`
+	ToBot           int    `json:"to_bot"`
+	ToBrowser       int    `json:"to_browser"`
+}
+
+// Report is the result of a comparison
+type Report struct {
+	BaseThreshold      int          `json:"base_threshold"`
+	CandidateThreshold int          `json:"candidate_threshold"`
+	Requests           int          `json:"requests"`
+	Flipped            int          `json:"flipped"`
+	ToBot              int          `json:"to_bot"`        // browser -> bot
+	ToBrowser          int          `json:"to_browser"`    // bot -> browser
+	ScoreChanged       int          `json:"score_changed"` // Same classification, different score
+	Rules              []RuleImpact `json:"rules"`         // Most flips first
+	Flips              []Flip       `json:"flips"`
+}
+
+// Comparer classifies requests with both configurations and accumulates
+// a report
+type Comparer struct {
+	base, candidate           *classifier.Classifier
+	baseRules, candidateRules fingerprint.Rules
+	report                    Report
+	impact                    map[ruleKey]*RuleImpact
+}
+
+// ruleKey identifies a rule change in the impact table
+type ruleKey struct{ rule, side, change string }
+
+// New creates a comparer for the two configurations
+func New(base, candidate classifier.Config) *Comparer {
+	return &Comparer{
+		base:           classifier.New(base),
+		candidate:      classifier.New(candidate),
+		baseRules:      rulesOf(base),
+		candidateRules: rulesOf(candidate),
+		report:         Report{BaseThreshold: base.Threshold, CandidateThreshold: candidate.Threshold},
+		impact:         map[ruleKey]*RuleImpact{},
+	}
+}
+
+// rulesOf returns the rules a classifier built from cfg scores with
+func rulesOf(cfg classifier.Config) fingerprint.Rules {
+	if cfg.Rules != nil {
+		return *cfg.Rules
+	}
+	return fingerprint.DefaultRules()
+}
+
+// Add compares the classifications of one logged request, read from the
+// given line of file
+func (c *Comparer) Add(file string, line int, entry logger.LogEntry) {
+	base := c.base.Classify(entry.Fingerprint)
+	cand := c.candidate.Classify(entry.Fingerprint)
+
+	c.report.Requests++
+	if base.Classification == cand.Classification {
+		if base.Score != cand.Score {
+			c.report.ScoreChanged++
+		}
+		return
+	}
+
+	flip := Flip{
+		File:               file,
+		Line:               line,
+		RequestID:          entry.RequestID,
+		UserAgent:          entry.Fingerprint.HTTP.UserAgent,
+		From:               base.Classification,
+		To:                 cand.Classification,
+		BaseScore:          base.Score,
+		CandidateScore:     cand.Score,
+		Changes:            c.ruleChanges(base.Signals.ScoreBreakdown, cand.Signals.ScoreBreakdown),
+		BaseBreakdown:      base.Signals.ScoreBreakdown,
+		CandidateBreakdown: cand.Signals.ScoreBreakdown,
+	}
+	flip.ThresholdOnly = len(flip.Changes) == 0 && c.report.BaseThreshold != c.report.CandidateThreshold
+
+	c.report.Flipped++
+	toBot := cand.Classification == classifier.ClassificationBot
+	if toBot {
+		c.report.ToBot++
+	} else {
+		c.report.ToBrowser++
+	}
+	for _, ch := range flip.Changes {
+		key := ruleKey{ch.Rule, ch.Side, ch.Change}
+		imp := c.impact[key]
+		if imp == nil {
+			imp = &RuleImpact{Rule: ch.Rule, Side: ch.Side, Change: ch.Change, BaseWeight: ch.BaseWeight, CandidateWeight: ch.CandidateWeight}
+			c.impact[key] = imp
+		}
+		imp.Flips++
+		if toBot {
+			imp.ToBot++
+		} else {
+			imp.ToBrowser++
+		}
+	}
+	c.report.Flips = append(c.report.Flips, flip)
+}
+
+// ruleChanges lists the rules that fired differently in two breakdowns,
+// by side and rule name
+func (c *Comparer) ruleChanges(baseBreakdown, candidateBreakdown fingerprint.Breakdown) []RuleChange {
+	baseBrowser, baseBot := baseBreakdown.Rules()
+	candBrowser, candBot := candidateBreakdown.Rules()
+
+	var changes []RuleChange
+	for _, side := range []struct {
+		name      string
+		base, can []string
+	}{
+		{classifier.ClassificationBrowser, baseBrowser, candBrowser},
+		{classifier.ClassificationBot, baseBot, candBot},
+	} {
+		names := append(slices.Clone(side.base), side.can...)
+		slices.Sort(names)
+		for _, name := range slices.Compact(names) {
+			inBase, inCand := slices.Contains(side.base, name), slices.Contains(side.can, name)
+			ch := RuleChange{Rule: name, Side: side.name}
+			if inBase {
+				ch.BaseWeight = c.baseRules.Weight(name)
+			}
+			if inCand {
+				ch.CandidateWeight = c.candidateRules.Weight(name)
+			}
+			switch {
+			case !inBase:
+				ch.Change = ChangeAdded
+			case !inCand:
+				ch.Change = ChangeRemoved
+			case ch.BaseWeight != ch.CandidateWeight:
+				ch.Change = ChangeReweighted
+			default:
+				continue
+			}
+			changes = append(changes, ch)
+		}
+	}
+	return changes
+}
+
+// Report returns the comparison so far, with rules sorted by the number
+// of flips they took part in
+func (c *Comparer) Report() Report {
+	r := c.report
+	r.Rules = make([]RuleImpact, 0, len(c.impact))
+	for _, imp := range c.impact {
+		r.Rules = append(r.Rules, *imp)
+	}
+	sort.Slice(r.Rules, func(i, j int) bool {
+		a, b := r.Rules[i], r.Rules[j]
+		if a.Flips != b.Flips {
+			return a.Flips > b.Flips
+		}
+		if a.Rule != b.Rule {
+			return a.Rule < b.Rule
+		}
+		return a.Side < b.Side
+	})
+	r.Flips = slices.Clone(c.report.Flips)
+	return r
+}
+
+// Compare replays a JSONL request log through both configurations
+func Compare(r io.Reader, base, candidate classifier.Config) (Report, error) {
+	c := New(base, candidate)
+	if err := c.AddLog("", r); err != nil {
+		return Report{}, err
+	}
+	return c.Report(), nil
+}
+
+// AddLog compares every entry of a JSONL request log; file names the log
+// in the flips
+func (c *Comparer) AddLog(file string, r io.Reader) error {
+	lr := logger.NewReader(r)
+	for {
+		entry, err := lr.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		c.Add(file, lr.Line(), entry)
+	}
+}
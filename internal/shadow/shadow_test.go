@@ -0,0 +1,13 @@
+package shadow
+
+import "testing"
+
+// Tests are in tests/unit/shadow_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestShadowPackage(t *testing.T) {
+	// Verify package is testable
+	if ChangeAdded == ChangeRemoved {
+		t.Error("rule change kinds should be distinct")
+	}
+}
@@ -0,0 +1,363 @@
+package synth
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// languages are Accept-Language values sent by browsers
+var languages = []string{
+	"en-US,en;q=0.9",
+	"en-GB,en;q=0.9",
+	"de-DE,de;q=0.9,en-US;q=0.8,en;q=0.7",
+	"fr-FR,fr;q=0.9,en;q=0.8",
+	"es-ES,es;q=0.9",
+	"pt-BR,pt;q=0.9,en-US;q=0.8,en;q=0.7",
+	"ja-JP,ja;q=0.9",
+	"zh-CN,zh;q=0.9",
+	"pl-PL,pl;q=0.9,en-US;q=0.8",
+}
+
+// pages are request paths used across families
+var pages = []string{
+	"/", "/index.html", "/products", "/products/42", "/blog/2026/10/launch",
+	"/search?q=shoes", "/about", "/docs/getting-started", "/pricing", "/login",
+}
+
+// apiPaths are request paths of XHR and library traffic
+var apiPaths = []string{
+	"/api/v1/items", "/api/v1/items/42", "/api/session", "/graphql", "/v1/classify", "/feed.json",
+}
+
+// platform is a browser operating system
+type platform struct {
+	name    string // Short name used in client descriptions
+	ua      string // User-Agent platform token
+	chPlat  string // sec-ch-ua-platform value
+	mobile  bool
+	firefox string // Firefox platform token
+}
+
+var platforms = []platform{
+	{"windows", "Windows NT 10.0; Win64; x64", `"Windows"`, false, "Windows NT 10.0; Win64; x64; rv:%d.0"},
+	{"macos", "Macintosh; Intel Mac OS X 10_15_7", `"macOS"`, false, "Macintosh; Intel Mac OS X 10.15; rv:%d.0"},
+	{"linux", "X11; Linux x86_64", `"Linux"`, false, "X11; Linux x86_64; rv:%d.0"},
+	{"android", "Linux; Android 10; K", `"Android"`, true, "Android 14; Mobile; rv:%d.0"},
+}
+
+// browser generates a navigation, subresource or fetch request from a
+// parameterized Chrome, Edge, Firefox or Safari
+func (g *Generator) browser() request {
+	r := request{label: classifier.ClassificationBrowser, method: "GET", proto: "HTTP/2.0"}
+	kind := pick(g.rng, "chrome", "chrome", "chrome", "edge", "firefox", "safari")
+	plat := pick(g.rng, platforms...)
+	if kind == "safari" {
+		plat = pick(g.rng, platform{name: "macos", ua: "Macintosh; Intel Mac OS X 10_15_7"}, platform{name: "ios", ua: "iPhone; CPU iPhone OS 17_5 like Mac OS X", mobile: true})
+	}
+
+	var ua string
+	var version int
+	switch kind {
+	case "chrome", "edge":
+		version = g.between(110, 131)
+		ua = fmt.Sprintf("Mozilla/5.0 (%s) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/%d.0.0.0 %sSafari/537.36", plat.ua, version, mobileToken(plat.mobile))
+		if kind == "edge" {
+			ua += fmt.Sprintf(" Edg/%d.0.0.0", version)
+		}
+	case "firefox":
+		version = g.between(115, 132)
+		ua = fmt.Sprintf("Mozilla/5.0 (%s) Gecko/20100101 Firefox/%d.0", fmt.Sprintf(plat.firefox, version), version)
+	default:
+		version = g.between(15, 18)
+		ua = fmt.Sprintf("Mozilla/5.0 (%s) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/%d.0 %sSafari/604.1", plat.ua, version, mobileToken(plat.mobile))
+	}
+	r.name = fmt.Sprintf("%s-%d-%s", kind, version, plat.name)
+
+	dest := pick(g.rng, "document", "document", "document", "empty", "image", "script")
+	site := pick(g.rng, "none", "same-origin", "same-origin", "cross-site")
+	if dest != "document" {
+		site = pick(g.rng, "same-origin", "same-site")
+	}
+
+	chromium := kind == "chrome" || kind == "edge"
+	if chromium {
+		brand := "Google Chrome"
+		if kind == "edge" {
+			brand = "Microsoft Edge"
+		}
+		r.header("sec-ch-ua", fmt.Sprintf(`"Chromium";v="%d", "%s";v="%d", "Not?A_Brand";v="99"`, version, brand, version))
+		r.header("sec-ch-ua-mobile", map[bool]string{true: "?1", false: "?0"}[plat.mobile])
+		r.header("sec-ch-ua-platform", plat.chPlat)
+	}
+	if dest == "document" {
+		r.header("Upgrade-Insecure-Requests", "1")
+	}
+	r.header("User-Agent", ua)
+	switch dest {
+	case "document":
+		r.path = pick(g.rng, pages...)
+		if chromium {
+			r.header("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7")
+		} else {
+			r.header("Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8")
+		}
+	case "empty":
+		r.path = pick(g.rng, apiPaths...)
+		r.header("Accept", pick(g.rng, "application/json", "*/*", "application/json, text/plain, */*"))
+		if g.chance(0.3) {
+			r.method = "POST"
+			r.header("Content-Type", "application/json")
+		}
+	case "image":
+		r.path = fmt.Sprintf("/static/img/%d.webp", g.between(1, 500))
+		r.header("Accept", "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8")
+	default:
+		r.path = fmt.Sprintf("/static/js/app.%x.js", g.rng.Uint32())
+		r.header("Accept", "*/*")
+	}
+	r.header("Sec-Fetch-Site", site)
+	r.header("Sec-Fetch-Mode", map[string]string{"document": "navigate", "empty": "cors", "image": "no-cors", "script": "no-cors"}[dest])
+	if dest == "document" && site != "same-origin" {
+		r.header("Sec-Fetch-User", "?1")
+	}
+	r.header("Sec-Fetch-Dest", dest)
+	switch site {
+	case "same-origin":
+		r.header("Referer", "https://example.com"+pick(g.rng, pages...))
+	case "same-site":
+		r.header("Referer", "https://www.example.com"+pick(g.rng, pages...))
+	case "cross-site":
+		r.header("Referer", pick(g.rng, "https://www.google.com/", "https://www.bing.com/", "https://duckduckgo.com/", "https://news.ycombinator.com/"))
+	}
+	r.header("Accept-Encoding", pick(g.rng, "gzip, deflate, br, zstd", "gzip, deflate, br"))
+	r.header("Accept-Language", pick(g.rng, languages...))
+	if g.chance(0.7) {
+		r.header("Cookie", fmt.Sprintf("sid=%x; theme=%s", g.rng.Uint64(), pick(g.rng, "dark", "light")))
+	}
+	if g.chance(0.2) {
+		r.header("Priority", "u=0, i")
+	}
+
+	switch {
+	case g.chance(0.05):
+		// Plain HTTP, e.g. a local development server
+		r.proto = "HTTP/1.1"
+		r.header("Connection", "keep-alive")
+	case g.chance(0.1):
+		r.proto = "HTTP/1.1"
+		r.header("Connection", "keep-alive")
+		r.tls = g.tlsFingerprint(browserStacks[kind], false, version/4)
+	default:
+		r.tls = g.tlsFingerprint(browserStacks[kind], true, version/4)
+	}
+	if g.chance(0.4) {
+		r.session = g.humanSession()
+	}
+	return r
+}
+
+// mobileToken returns the "Mobile " UA token of mobile platforms
+func mobileToken(mobile bool) string {
+	if mobile {
+		return "Mobile "
+	}
+	return ""
+}
+
+// library is an HTTP client library or automation tool
+type library struct {
+	name    string
+	ua      string // Format with one %d version placeholder
+	min     int
+	max     int
+	headers [][2]string
+	stack   string // Key of tlsStacks
+	h2      bool   // Negotiates HTTP/2 over TLS
+}
+
+var libraries = []library{
+	{"curl", "curl/8.%d.0", 0, 11, [][2]string{{"Accept", "*/*"}}, "openssl", true},
+	{"wget", "Wget/1.21.%d", 1, 4, [][2]string{{"Accept", "*/*"}, {"Accept-Encoding", "identity"}, {"Connection", "Keep-Alive"}}, "openssl", false},
+	{"python-requests", "python-requests/2.%d.0", 25, 32, [][2]string{{"Accept-Encoding", "gzip, deflate"}, {"Accept", "*/*"}, {"Connection", "keep-alive"}}, "python", false},
+	{"httpx", "python-httpx/0.%d.0", 23, 28, [][2]string{{"Accept", "*/*"}, {"Accept-Encoding", "gzip, deflate"}, {"Connection", "keep-alive"}}, "python", true},
+	{"aiohttp", "Python/3.12 aiohttp/3.%d.0", 8, 10, [][2]string{{"Accept", "*/*"}, {"Accept-Encoding", "gzip, deflate"}}, "python", false},
+	{"go", "Go-http-client/%d.1", 1, 1, [][2]string{{"Accept-Encoding", "gzip"}}, "go", false},
+	{"go-h2", "Go-http-client/%d.0", 2, 2, [][2]string{{"Accept-Encoding", "gzip"}}, "go", true},
+	{"node-fetch", "node-fetch/1.0 (+https://github.com/bitinn/node-fetch) v%d", 1, 3, [][2]string{{"Accept", "*/*"}, {"Accept-Encoding", "gzip,deflate"}, {"Connection", "close"}}, "node", false},
+	{"undici", "undici/%d", 5, 6, [][2]string{{"Accept", "*/*"}, {"Accept-Language", "*"}, {"Sec-Fetch-Mode", "cors"}, {"Accept-Encoding", "gzip, deflate"}}, "node", false},
+	{"axios", "axios/1.%d.0", 4, 7, [][2]string{{"Accept", "application/json, text/plain, */*"}, {"Accept-Encoding", "gzip, compress, deflate, br"}}, "node", false},
+	{"okhttp", "okhttp/4.%d.0", 9, 12, [][2]string{{"Accept-Encoding", "gzip"}, {"Connection", "Keep-Alive"}}, "java", true},
+	{"java", "Java/%d.0.2", 11, 21, [][2]string{{"Accept", "*/*"}}, "java", false},
+	{"apache-httpclient", "Apache-HttpClient/5.%d (Java/17.0.9)", 1, 3, [][2]string{{"Accept-Encoding", "gzip, x-gzip, deflate"}, {"Connection", "keep-alive"}}, "java", false},
+	{"scrapy", "Scrapy/2.%d.0 (+https://scrapy.org)", 9, 11, [][2]string{{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"}, {"Accept-Language", "en"}, {"Accept-Encoding", "gzip, deflate"}}, "python", false},
+	{"postman", "PostmanRuntime/7.%d.0", 36, 42, [][2]string{{"Accept", "*/*"}, {"Cache-Control", "no-cache"}, {"Accept-Encoding", "gzip, deflate, br"}, {"Connection", "keep-alive"}}, "node", false},
+	{"headless-chrome", "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/%d.0.0.0 Safari/537.36", 118, 131, [][2]string{
+		{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"},
+		{"Sec-Fetch-Site", "none"}, {"Sec-Fetch-Mode", "navigate"}, {"Sec-Fetch-Dest", "document"},
+		{"Accept-Encoding", "gzip, deflate, br"}, {"Accept-Language", "en-US"},
+	}, "chrome", true},
+	{"python-urllib", "Python-urllib/3.%d", 9, 12, [][2]string{{"Accept-Encoding", "identity"}, {"Connection", "close"}}, "python", false},
+}
+
+// library generates a request from an HTTP library or automation tool
+func (g *Generator) library() request {
+	lib := pick(g.rng, libraries...)
+	version := g.between(lib.min, lib.max)
+	r := request{
+		name:   fmt.Sprintf("%s-%d", lib.name, version),
+		label:  classifier.ClassificationBot,
+		method: "GET",
+		proto:  "HTTP/1.1",
+		path:   pick(g.rng, append(apiPaths, pages...)...),
+	}
+	r.header("User-Agent", fmt.Sprintf(lib.ua, version))
+	r.headers = append(r.headers, lib.headers...)
+	if g.chance(0.2) {
+		r.method = pick(g.rng, "POST", "PUT", "DELETE")
+		if r.method != "DELETE" {
+			r.header("Content-Type", "application/json")
+		}
+	}
+	if g.chance(0.15) {
+		r.header("Authorization", fmt.Sprintf("Bearer %x", g.rng.Uint64()))
+	}
+
+	if g.chance(0.8) {
+		h2 := lib.h2 && g.chance(0.7)
+		if h2 {
+			r.proto = "HTTP/2.0"
+		}
+		r.tls = g.tlsFingerprint(tlsStacks[lib.stack], h2, version)
+	}
+	if g.chance(0.4) {
+		r.session = g.scriptedSession()
+	}
+	return r
+}
+
+// aiCrawler is an AI training or retrieval crawler
+type aiCrawler struct {
+	name  string
+	ua    string
+	stack string
+}
+
+var aiCrawlers = []aiCrawler{
+	{"gptbot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.%d; +https://openai.com/gptbot)", "python"},
+	{"chatgpt-user", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko); compatible; ChatGPT-User/1.%d; +https://openai.com/bot", "python"},
+	{"oai-searchbot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko); compatible; OAI-SearchBot/1.%d; +https://openai.com/searchbot", "python"},
+	{"claudebot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; ClaudeBot/1.%d; +claudebot@anthropic.com)", "go"},
+	{"perplexitybot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; PerplexityBot/1.%d; +https://perplexity.ai/perplexitybot)", "node"},
+	{"ccbot", "CCBot/2.%d (https://commoncrawl.org/faq/)", "java"},
+	{"bytespider", "Mozilla/5.0 (Linux; Android 5.0) AppleWebKit/537.36 (KHTML, like Gecko) Mobile Safari/537.36 (compatible; Bytespider; spider-feedback@bytedance.com) v%d", "openssl"},
+	{"amazonbot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; Amazonbot/0.%d; +https://developer.amazon.com/support/amazonbot) Chrome/119.0.6045.214 Safari/537.36", "java"},
+	{"meta-externalagent", "meta-externalagent/1.%d (+https://developers.facebook.com/docs/sharing/webmasters/crawler)", "openssl"},
+	{"cohere-ai", "cohere-ai/1.%d", "python"},
+	{"youbot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; YouBot/1.%d; +https://about.you.com/youbot/)", "go"},
+	{"ai2bot", "Mozilla/5.0 (compatible) AI2Bot/1.%d (+https://www.allenai.org/crawler)", "python"},
+}
+
+// aiCrawler generates a page fetch from an AI crawler
+func (g *Generator) aiCrawler() request {
+	c := pick(g.rng, aiCrawlers...)
+	version := g.between(0, 3)
+	r := request{
+		name:   fmt.Sprintf("%s-%d", c.name, version),
+		label:  classifier.ClassificationBot,
+		method: "GET",
+		proto:  "HTTP/1.1",
+		path:   pick(g.rng, append(pages, "/robots.txt", "/sitemap.xml", "/llms.txt")...),
+	}
+	r.header("User-Agent", fmt.Sprintf(c.ua, version))
+	r.header("Accept", pick(g.rng, "*/*", "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8", "text/html"))
+	if g.chance(0.7) {
+		r.header("Accept-Encoding", pick(g.rng, "gzip, deflate, br", "gzip", "gzip, deflate"))
+	}
+	if g.chance(0.2) {
+		r.header("Accept-Language", "en-US,en;q=0.5")
+	}
+	if g.chance(0.3) {
+		r.header("From", "crawler@example.org")
+	}
+	r.tls = g.tlsFingerprint(tlsStacks[c.stack], false, version)
+	if g.chance(0.6) {
+		r.session = g.scriptedSession()
+	}
+	return r
+}
+
+// malformed generates broken, contradictory or abusive requests that a
+// well-behaved client would never send; all are labeled bot
+func (g *Generator) malformed() request {
+	r := request{label: classifier.ClassificationBot, method: "GET", proto: "HTTP/1.1", path: "/"}
+	switch g.rng.IntN(10) {
+	case 0:
+		r.name = "no-headers"
+	case 1:
+		r.name = "empty-user-agent"
+		r.header("User-Agent", "")
+		r.header("Accept", "*/*")
+	case 2:
+		r.name = "truncated-user-agent"
+		r.header("User-Agent", pick(g.rng, "Mozilla/5.0 (", "Mozilla", "-", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWeb"))
+		r.header("Accept", "*/*")
+	case 3:
+		r.name = "http10"
+		r.proto = "HTTP/1.0"
+		r.header("User-Agent", "Mozilla/4.0 (compatible; MSIE 6.0; Windows NT 5.1)")
+	case 4:
+		r.name = "header-flood"
+		r.header("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36")
+		for i := range g.between(40, 120) {
+			r.header(fmt.Sprintf("X-Pad-%03d", i), strings.Repeat("a", g.between(1, 64)))
+		}
+	case 5:
+		r.name = "odd-method"
+		r.method = pick(g.rng, "PROPFIND", "TRACE", "FOO", "get", "CONNECT")
+		r.header("User-Agent", "Mozilla/5.0")
+	case 6:
+		r.name = "hostile-path"
+		r.path = pick(g.rng, "/../../etc/passwd", "/wp-login.php", "/.env", "/?id=1%27%20OR%201=1--", "/"+strings.Repeat("A", 2048), "/cgi-bin/test.cgi")
+		r.header("User-Agent", pick(g.rng, "Mozilla/5.0 (compatible; Nmap Scripting Engine)", "sqlmap/1.8", "Mozilla/5.0"))
+		r.header("Accept", "*/*")
+	case 7:
+		// Browser UA and client hints over a library TLS stack and HTTP/1.1
+		r.name = "spoofed-browser"
+		r.header("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36")
+		r.header("sec-ch-ua", `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`)
+		r.header("Accept", "*/*")
+		r.header("Accept-Encoding", "gzip")
+		r.tls = g.tlsFingerprint(tlsStacks["python"], false, 0)
+	case 8:
+		// Fields that cannot occur together in a collected fingerprint
+		r.name = "inconsistent-fingerprint"
+		r.header("User-Agent", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15")
+		r.header("Accept", "text/html")
+		r.header("Accept-Language", "en-US")
+		r.mutate = func(fp *fingerprint.Fingerprint) {
+			fp.HTTP.Version = "HTTP/2.0"
+			fp.HTTP.HeaderCount = 0
+			fp.HTTP.JA4HHash = "ge20nn99zzzz_000000000000_000000000000_000000000000"
+			fp.TLS.Available = false
+			fp.TLS.ALPN = "h2"
+		}
+	default:
+		// Garbage in fields that fuzzers and hand-written payloads produce
+		r.name = "garbage-fields"
+		r.header("User-Agent", "ÿþ"+strings.Repeat("%00", g.between(1, 8)))
+		r.header("Accept-Language", ";q=;;,")
+		r.header("Sec-Fetch-Mode", "navigate")
+		r.mutate = func(fp *fingerprint.Fingerprint) {
+			fp.HTTP.JA4HHash = pick(g.rng, "", "x", "ge11")
+			fp.HTTP.ContentLength = -1
+			fp.TLS.Version = pick(g.rng, "SSL 3.0", "TLS 1.0", "unknown")
+			fp.TLS.Available = true
+			fp.TLS.CipherSuitesCount = g.between(1, 3)
+			fp.Session = fingerprint.SessionFingerprint{Available: true, RequestCount: 1, IntervalCount: 5, IntervalJitter: -1}
+		}
+	}
+	return r
+}
@@ -0,0 +1,257 @@
+// Package synth generates large labeled corpora of synthetic fingerprints
+// for evaluation and fuzzing.
+//
+// Samples are drawn from four families: parameterized desktop and mobile
+// browsers, HTTP libraries and automation tools, AI crawlers, and
+// malformed requests. Each sample is built as a real request and run
+// through the fingerprint collector, so JA4H and derived fields match
+// what the server would record, and TLS and session timing details are
+// synthesized per client. The same seed and mix always yield the same
+// corpus.
+package synth
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Sample families
+const (
+	FamilyBrowser   = "browser"
+	FamilyLibrary   = "library"
+	FamilyAICrawler = "ai_crawler"
+	FamilyMalformed = "malformed"
+)
+
+// Families returns the known family names
+func Families() []string {
+	return []string{FamilyBrowser, FamilyLibrary, FamilyAICrawler, FamilyMalformed}
+}
+
+// Source is the dataset source recorded on generated samples
+const Source = "synth"
+
+// Mix is the relative weight of each family in a corpus
+type Mix map[string]int
+
+// DefaultMix returns a browser-heavy mix resembling public web traffic
+func DefaultMix() Mix {
+	return Mix{FamilyBrowser: 50, FamilyLibrary: 25, FamilyAICrawler: 15, FamilyMalformed: 10}
+}
+
+// ParseMix parses a mix of the form "browser=50,library=25". Families
+// that are not listed get a weight of 0.
+func ParseMix(s string) (Mix, error) {
+	mix := Mix{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid mix entry %q, want family=weight", part)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("invalid weight for %s: %w", name, err)
+		}
+		mix[strings.TrimSpace(name)] = weight
+	}
+	return mix, mix.validate()
+}
+
+// validate checks that the mix names known families with a positive total
+func (m Mix) validate() error {
+	total := 0
+	for name, w := range m {
+		if !isFamily(name) {
+			return fmt.Errorf("unknown family %q (available: %s)", name, strings.Join(Families(), ", "))
+		}
+		if w < 0 {
+			return fmt.Errorf("weight of %s must not be negative", name)
+		}
+		total += w
+	}
+	if total == 0 {
+		return fmt.Errorf("mix must give at least one family a positive weight")
+	}
+	return nil
+}
+
+func isFamily(name string) bool {
+	for _, f := range Families() {
+		if f == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Generator produces a reproducible stream of labeled samples
+type Generator struct {
+	rng       *rand.Rand
+	families  []string // Families with a positive weight, in a fixed order
+	weights   []int
+	total     int
+	seq       int
+	collector *fingerprint.Collector
+}
+
+// New creates a generator for the given seed and family mix
+func New(seed uint64, mix Mix) (*Generator, error) {
+	if err := mix.validate(); err != nil {
+		return nil, err
+	}
+	g := &Generator{
+		rng:       rand.New(rand.NewPCG(seed, seed^0x5eed)),
+		collector: fingerprint.NewCollector(),
+	}
+	names := make([]string, 0, len(mix))
+	for name, w := range mix {
+		if w > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.families = append(g.families, name)
+		g.weights = append(g.weights, mix[name])
+		g.total += mix[name]
+	}
+	return g, nil
+}
+
+// Next returns the next sample. The Note field names the family and the
+// generated client, e.g. "browser/chrome-124-windows".
+func (g *Generator) Next() dataset.Sample {
+	g.seq++
+	family := g.pickFamily()
+
+	var r request
+	switch family {
+	case FamilyBrowser:
+		r = g.browser()
+	case FamilyLibrary:
+		r = g.library()
+	case FamilyAICrawler:
+		r = g.aiCrawler()
+	default:
+		r = g.malformed()
+	}
+
+	return dataset.Sample{
+		RequestID:   fmt.Sprintf("synth-%07d", g.seq),
+		Label:       r.label,
+		Fingerprint: g.fingerprint(r),
+		Source:      Source,
+		Note:        family + "/" + r.name,
+	}
+}
+
+// Generate returns the next n samples
+func (g *Generator) Generate(n int) []dataset.Sample {
+	samples := make([]dataset.Sample, n)
+	for i := range samples {
+		samples[i] = g.Next()
+	}
+	return samples
+}
+
+// pickFamily chooses a family with probability proportional to its weight
+func (g *Generator) pickFamily() string {
+	n := g.rng.IntN(g.total)
+	for i, w := range g.weights {
+		if n < w {
+			return g.families[i]
+		}
+		n -= w
+	}
+	return g.families[len(g.families)-1]
+}
+
+// request is a synthetic client request before fingerprinting
+type request struct {
+	name    string // Client description, e.g. "curl-8.5"
+	label   string
+	method  string
+	proto   string
+	path    string
+	headers [][2]string // In wire order
+	tls     fingerprint.TLSFingerprint
+	session fingerprint.SessionFingerprint
+	mutate  func(*fingerprint.Fingerprint) // Post-collection corruption of malformed samples
+}
+
+// header appends a header in wire order
+func (r *request) header(name, value string) {
+	r.headers = append(r.headers, [2]string{name, value})
+}
+
+// fingerprint runs the request through the collector and attaches the
+// synthesized TLS and session details
+func (g *Generator) fingerprint(r request) fingerprint.Fingerprint {
+	meta := fingerprint.RequestMetadata{
+		Method:  r.method,
+		Proto:   r.proto,
+		Host:    "example.com",
+		Path:    r.path,
+		Headers: make(map[string][]string, len(r.headers)),
+	}
+	order := make([]string, 0, len(r.headers))
+	for _, h := range r.headers {
+		meta.Headers[h[0]] = append(meta.Headers[h[0]], h[1])
+		order = append(order, strings.ToLower(h[0]))
+	}
+
+	req, err := meta.HTTPRequest(context.Background())
+	if err != nil {
+		// Generators only emit parseable requests; corruption happens in mutate
+		panic(fmt.Sprintf("synth: invalid %s request: %v", r.name, err))
+	}
+	fp := g.collector.Collect(req)
+	// The collector reads headers from a map; keep the generated wire order
+	// so the corpus is reproducible
+	fp.HTTP.HeaderOrder = dedupe(order)
+	fp.TLS = r.tls
+	fp.Session = r.session
+	if r.mutate != nil {
+		r.mutate(&fp)
+	}
+	return fp
+}
+
+// dedupe removes repeated names, keeping the first occurrence
+func dedupe(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	out := names[:0]
+	for _, n := range names {
+		if !seen[n] {
+			seen[n] = true
+			out = append(out, n)
+		}
+	}
+	return out
+}
+
+// pick returns a random element of choices
+func pick[T any](rng *rand.Rand, choices ...T) T {
+	return choices[rng.IntN(len(choices))]
+}
+
+// chance reports true with probability p
+func (g *Generator) chance(p float64) bool {
+	return g.rng.Float64() < p
+}
+
+// between returns a random integer in [lo, hi]
+func (g *Generator) between(lo, hi int) int {
+	return lo + g.rng.IntN(hi-lo+1)
+}
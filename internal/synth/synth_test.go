@@ -0,0 +1,13 @@
+package synth
+
+import "testing"
+
+// Tests are in tests/unit/synth_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestSynthPackage(t *testing.T) {
+	// Verify package is testable
+	if _, err := New(1, DefaultMix()); err != nil {
+		t.Errorf("New() error = %v", err)
+	}
+}
@@ -0,0 +1,129 @@
+package synth
+
+import (
+	"crypto/md5" //nolint:gosec // JA3 is defined as an MD5 hash
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// tlsStack describes the ClientHello of a TLS implementation
+type tlsStack struct {
+	name    string
+	ciphers [2]int // Offered cipher suite count range
+	exts    [2]int // Extension count range
+	groups  []string
+	sigs    []string
+	ticket  bool
+	tls13   bool
+}
+
+var (
+	modernSigs = []string{"ecdsa_secp256r1_sha256", "rsa_pss_rsae_sha256", "rsa_pkcs1_sha256", "ecdsa_secp384r1_sha384", "rsa_pss_rsae_sha384", "rsa_pkcs1_sha384", "rsa_pss_rsae_sha512", "rsa_pkcs1_sha512"}
+	goSigs     = []string{"rsa_pss_rsae_sha256", "ecdsa_secp256r1_sha256", "ed25519", "rsa_pss_rsae_sha384", "rsa_pss_rsae_sha512", "rsa_pkcs1_sha256", "rsa_pkcs1_sha384", "rsa_pkcs1_sha512", "ecdsa_secp384r1_sha384", "ecdsa_secp521r1_sha512", "rsa_pkcs1_sha1", "ecdsa_sha1"}
+)
+
+// browserStacks are keyed by browser kind
+var browserStacks = map[string]tlsStack{
+	"chrome":  {"chrome", [2]int{15, 16}, [2]int{16, 18}, []string{"GREASE", "0x11ec", "x25519", "secp256r1", "secp384r1"}, modernSigs, true, true},
+	"edge":    {"edge", [2]int{15, 16}, [2]int{16, 18}, []string{"GREASE", "0x11ec", "x25519", "secp256r1", "secp384r1"}, modernSigs, true, true},
+	"firefox": {"firefox", [2]int{17, 17}, [2]int{15, 17}, []string{"0x11ec", "x25519", "secp256r1", "secp384r1", "secp521r1", "ffdhe2048", "ffdhe3072"}, modernSigs, true, true},
+	"safari":  {"safari", [2]int{20, 21}, [2]int{13, 14}, []string{"GREASE", "x25519", "secp256r1", "secp384r1", "secp521r1"}, modernSigs, false, true},
+}
+
+// tlsStacks are the TLS implementations of libraries and crawlers
+var tlsStacks = map[string]tlsStack{
+	"openssl": {"openssl", [2]int{30, 31}, [2]int{9, 11}, []string{"x25519", "secp256r1", "x448", "secp521r1", "secp384r1"}, modernSigs, true, true},
+	"python":  {"python", [2]int{17, 18}, [2]int{8, 9}, []string{"x25519", "secp256r1", "x448", "secp521r1", "secp384r1"}, modernSigs, true, true},
+	"go":      {"go", [2]int{13, 13}, [2]int{10, 11}, []string{"x25519", "secp256r1", "secp384r1", "secp521r1"}, goSigs, true, true},
+	"node":    {"node", [2]int{8, 9}, [2]int{8, 9}, []string{"x25519", "secp256r1", "secp384r1"}, modernSigs, false, true},
+	"java":    {"java", [2]int{29, 45}, [2]int{10, 12}, []string{"x25519", "secp256r1", "secp384r1", "secp521r1", "x448", "ffdhe2048"}, modernSigs, false, true},
+	"chrome":  browserStacks["chrome"],
+}
+
+// tlsFingerprint synthesizes the TLS fingerprint of a stack. Hashes are
+// derived from the stack and variant, so clients of the same release
+// share JA3/JA4 values as they would in real traffic.
+func (g *Generator) tlsFingerprint(st tlsStack, h2 bool, variant int) fingerprint.TLSFingerprint {
+	ciphers := g.between(st.ciphers[0], st.ciphers[1])
+	exts := g.between(st.exts[0], st.exts[1])
+
+	fp := fingerprint.TLSFingerprint{
+		Version:           "TLS 1.3",
+		CipherSuite:       pick(g.rng, "TLS_AES_128_GCM_SHA256", "TLS_AES_256_GCM_SHA384", "TLS_CHACHA20_POLY1305_SHA256"),
+		ALPN:              "http/1.1",
+		ServerName:        "example.com",
+		CipherSuitesCount: ciphers,
+		ExtensionsCount:   exts,
+		SupportedVersions: []string{"TLS 1.3", "raw: TLS 1.2"},
+		SignatureSchemes:  st.sigs,
+		SupportedGroups:   st.groups,
+		HasSessionTicket:  st.ticket,
+		Available:         true,
+	}
+	// Stacks sending GREASE send it in suites, extensions and groups alike
+	fp.GREASECiphers = slices.Contains(st.groups, "GREASE")
+	fp.GREASEExtensions = fp.GREASECiphers
+	if !st.tls13 {
+		fp.Version = "TLS 1.2"
+		fp.CipherSuite = "TLS_ECDHE_RSA_WITH_AES_128_GCM_SHA256"
+		fp.SupportedVersions = []string{"TLS 1.2"}
+	}
+	alpn := "h1"
+	if h2 {
+		fp.ALPN = "h2"
+		alpn = "h2"
+	}
+
+	seed := fmt.Sprintf("%s/%d/%d/%d", st.name, variant, ciphers, exts)
+	sum := md5.Sum([]byte(seed)) //nolint:gosec
+	fp.JA3Hash = hex.EncodeToString(sum[:])
+	fp.JA4Hash = fmt.Sprintf("t%sd%02d%02d%s_%s_%s", map[bool]string{true: "13", false: "12"}[st.tls13],
+		min(ciphers, 99), min(exts, 99), alpn, hash12("b/"+seed), hash12("c/"+seed))
+	return fp
+}
+
+// hash12 returns the first 12 hex characters of the SHA-256 of s
+func hash12(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// humanSession synthesizes the timing of a person browsing: gaps of
+// seconds with high variance
+func (g *Generator) humanSession() fingerprint.SessionFingerprint {
+	intervals := g.between(1, 30)
+	mean := 1500 + g.rng.Float64()*20000
+	jitter := 0.4 + g.rng.Float64()*1.2
+	return sessionFingerprint(intervals, mean, jitter, 150+g.rng.Float64()*800)
+}
+
+// scriptedSession synthesizes the timing of a loop: short, regular gaps,
+// occasionally a throttled crawler with longer but still regular gaps
+func (g *Generator) scriptedSession() fingerprint.SessionFingerprint {
+	intervals := g.between(4, 200)
+	mean := 5 + g.rng.Float64()*400
+	if g.chance(0.3) {
+		mean = 1000 + g.rng.Float64()*9000
+	}
+	jitter := g.rng.Float64() * 0.15
+	return sessionFingerprint(intervals, mean, jitter, mean*(1-2*jitter))
+}
+
+// sessionFingerprint builds a session summary from interval statistics
+func sessionFingerprint(intervals int, mean, jitter, minGap float64) fingerprint.SessionFingerprint {
+	round := func(v float64) float64 { return math.Round(v*100) / 100 }
+	return fingerprint.SessionFingerprint{
+		RequestCount:     intervals + 1,
+		IntervalCount:    intervals,
+		MeanIntervalMs:   round(mean),
+		MinIntervalMs:    round(max(minGap, 0)),
+		IntervalStdDevMs: round(mean * jitter),
+		IntervalJitter:   round(jitter),
+		Available:        true,
+	}
+}
@@ -0,0 +1,75 @@
+// Package systemd receives listening sockets passed by systemd socket
+// activation (sd_listen_fds).
+//
+// systemd opens the sockets of a .socket unit itself and hands them to the
+// service as file descriptors 3 and up, described by LISTEN_PID, LISTEN_FDS
+// and LISTEN_FDNAMES. The server can then bind privileged ports without
+// root, and connections arriving during a restart wait in the socket's
+// backlog instead of being refused.
+package systemd
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// firstFD is the first file descriptor passed by systemd (SD_LISTEN_FDS_START)
+const firstFD = 3
+
+// Listener is a socket passed by systemd
+type Listener struct {
+	net.Listener
+	Name string // FileDescriptorName= of the socket unit (systemd defaults to the unit name)
+}
+
+// Listeners returns the sockets passed to this process, in order, or nil
+// when it was not socket-activated. The activation variables are removed
+// from the environment so child processes do not inherit them.
+func Listeners() ([]Listener, error) {
+	pid, fds, names := os.Getenv("LISTEN_PID"), os.Getenv("LISTEN_FDS"), os.Getenv("LISTEN_FDNAMES")
+	if pid == "" || fds == "" {
+		return nil, nil
+	}
+	_ = os.Unsetenv("LISTEN_PID")
+	_ = os.Unsetenv("LISTEN_FDS")
+	_ = os.Unsetenv("LISTEN_FDNAMES")
+
+	// The variables were meant for another process (e.g. our parent)
+	if p, err := strconv.Atoi(pid); err != nil || p != os.Getpid() {
+		return nil, nil
+	}
+	n, err := strconv.Atoi(fds)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("invalid LISTEN_FDS %q", fds)
+	}
+
+	var nameList []string
+	if names != "" {
+		nameList = strings.Split(names, ":")
+	}
+	listeners := make([]Listener, 0, n)
+	for i := range n {
+		fd := firstFD + i
+		name := "LISTEN_FD_" + strconv.Itoa(fd)
+		if i < len(nameList) {
+			name = nameList[i]
+		}
+
+		// FileListener duplicates the descriptor (close-on-exec), so the
+		// original is closed
+		f := os.NewFile(uintptr(fd), name)
+		l, err := net.FileListener(f)
+		_ = f.Close()
+		if err != nil {
+			for _, prev := range listeners {
+				_ = prev.Close()
+			}
+			return nil, fmt.Errorf("socket %s (fd %d): %w", name, fd, err)
+		}
+		listeners = append(listeners, Listener{Listener: l, Name: name})
+	}
+	return listeners, nil
+}
@@ -0,0 +1,96 @@
+// Package tcpsyn records the SYN packet that opened each accepted TCP
+// connection, for TCP-level fingerprinting (JA4T).
+//
+// On Linux the kernel keeps the SYN of connections to a listening socket
+// with TCP_SAVE_SYN set, and hands it out once per connection through
+// TCP_SAVED_SYN: the IP and TCP headers, with the window size, options and
+// TTL chosen by the client's operating system. No packet capture or
+// elevated privileges are needed. Other platforms are not supported.
+package tcpsyn
+
+import (
+	"net"
+	"sync"
+)
+
+// Recorder keeps the SYN of every open connection accepted through the
+// listeners it wraps
+type Recorder struct {
+	mu   sync.Mutex
+	syns map[string][]byte // By remote address
+}
+
+// NewRecorder creates an empty Recorder
+func NewRecorder() *Recorder {
+	return &Recorder{syns: make(map[string][]byte)}
+}
+
+// Listen turns on SYN saving for l, which must be a TCP listener, and
+// returns a listener recording the SYN of each connection it accepts.
+// It fails with errors.ErrUnsupported on platforms other than Linux.
+func (r *Recorder) Listen(l net.Listener) (net.Listener, error) {
+	if err := saveSYN(l); err != nil {
+		return nil, err
+	}
+	return &listener{Listener: l, r: r}, nil
+}
+
+// SYN returns the SYN of the open connection from addr, or nil
+func (r *Recorder) SYN(addr net.Addr) []byte {
+	if addr == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.syns[addr.String()]
+}
+
+// Len returns the number of connections with a recorded SYN
+func (r *Recorder) Len() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.syns)
+}
+
+// listener records the SYN of accepted connections
+type listener struct {
+	net.Listener
+	r *Recorder
+}
+
+// Accept reads the saved SYN of the connection. Connections whose SYN is
+// unavailable, e.g. accepted before saving was turned on, are passed
+// through unrecorded.
+func (l *listener) Accept() (net.Conn, error) {
+	c, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	syn, err := savedSYN(c)
+	if err != nil || len(syn) == 0 {
+		return c, nil
+	}
+	key := c.RemoteAddr().String()
+	l.r.mu.Lock()
+	l.r.syns[key] = syn
+	l.r.mu.Unlock()
+	return &conn{Conn: c, r: l.r, key: key}, nil
+}
+
+// conn forgets its SYN when closed
+type conn struct {
+	net.Conn
+	r    *Recorder
+	key  string
+	once sync.Once
+}
+
+// Close closes the connection and drops its SYN
+func (c *conn) Close() error {
+	c.once.Do(func() {
+		c.r.mu.Lock()
+		delete(c.r.syns, c.key)
+		c.r.mu.Unlock()
+	})
+	return c.Conn.Close()
+}
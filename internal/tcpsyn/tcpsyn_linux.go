@@ -0,0 +1,70 @@
+//go:build linux && !386
+
+package tcpsyn
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"syscall"
+	"unsafe"
+)
+
+// Socket options from linux/tcp.h
+const (
+	tcpSaveSYN  = 27 // TCP_SAVE_SYN
+	tcpSavedSYN = 28 // TCP_SAVED_SYN
+)
+
+// maxSYN fits the largest IPv6 and TCP headers
+const maxSYN = 40 + 60 + 64
+
+// saveSYN sets TCP_SAVE_SYN on a listening socket. Kernels before 4.2
+// and some sandboxes lack it.
+func saveSYN(l net.Listener) error {
+	raw, err := rawConn(l)
+	if err != nil {
+		return err
+	}
+	var serr error
+	if err := raw.Control(func(fd uintptr) {
+		serr = syscall.SetsockoptInt(int(fd), syscall.IPPROTO_TCP, tcpSaveSYN, 1)
+	}); err != nil {
+		return err
+	}
+	if errors.Is(serr, syscall.ENOPROTOOPT) {
+		return fmt.Errorf("TCP_SAVE_SYN: %w", errors.ErrUnsupported)
+	}
+	return serr
+}
+
+// savedSYN reads TCP_SAVED_SYN of an accepted connection, which the
+// kernel returns only once
+func savedSYN(c net.Conn) ([]byte, error) {
+	raw, err := rawConn(c)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, maxSYN)
+	n := uint32(len(buf))
+	var errno syscall.Errno
+	if err := raw.Control(func(fd uintptr) {
+		_, _, errno = syscall.Syscall6(syscall.SYS_GETSOCKOPT, fd, syscall.IPPROTO_TCP, tcpSavedSYN,
+			uintptr(unsafe.Pointer(&buf[0])), uintptr(unsafe.Pointer(&n)), 0)
+	}); err != nil {
+		return nil, err
+	}
+	if errno != 0 {
+		return nil, errno
+	}
+	return buf[:n], nil
+}
+
+// rawConn returns the socket of a TCP listener or connection
+func rawConn(v any) (syscall.RawConn, error) {
+	sc, ok := v.(syscall.Conn)
+	if !ok {
+		return nil, errors.New("not a TCP socket")
+	}
+	return sc.SyscallConn()
+}
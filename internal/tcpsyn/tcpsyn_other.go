@@ -0,0 +1,18 @@
+//go:build !linux || 386
+
+package tcpsyn
+
+import (
+	"errors"
+	"net"
+)
+
+// saveSYN is not supported without TCP_SAVE_SYN
+func saveSYN(net.Listener) error {
+	return errors.ErrUnsupported
+}
+
+// savedSYN is not supported without TCP_SAVED_SYN
+func savedSYN(net.Conn) ([]byte, error) {
+	return nil, errors.ErrUnsupported
+}
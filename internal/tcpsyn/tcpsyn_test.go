@@ -0,0 +1,14 @@
+package tcpsyn
+
+import "testing"
+
+// Tests are in tests/unit/tcpsyn_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestTcpsynPackage(t *testing.T) {
+	// Verify package is testable
+	r := NewRecorder()
+	if r.SYN(nil) != nil || r.Len() != 0 {
+		t.Error("new Recorder should be empty")
+	}
+}
@@ -0,0 +1,68 @@
+package tenant
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// Limiter is a token bucket refilled at a fixed rate
+type Limiter struct {
+	mu     sync.Mutex
+	rate   float64 // Tokens added per second
+	burst  float64 // Bucket capacity
+	tokens float64
+	last   time.Time
+}
+
+// Status is a limiter's state after a request, as reported in RateLimit
+// response headers
+type Status struct {
+	Allowed   bool
+	Limit     int           // Bucket capacity: requests allowed per Window
+	Window    time.Duration // Time to refill an empty bucket
+	Remaining int           // Whole requests left in the bucket
+	Reset     time.Duration // Until the next request is allowed when none remain, until the bucket is full otherwise
+}
+
+// NewLimiter creates a full bucket allowing rate requests per second and
+// bursts of up to burst requests (0 = rate rounded up)
+func NewLimiter(rate float64, burst int) *Limiter {
+	if burst <= 0 {
+		burst = int(math.Ceil(rate))
+	}
+	return &Limiter{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// Allow takes a token at now, reporting false when the bucket is empty
+func (l *Limiter) Allow(now time.Time) bool {
+	return l.Take(now).Allowed
+}
+
+// Take takes a token at now and reports the bucket's state afterwards
+func (l *Limiter) Take(now time.Time) Status {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if !l.last.IsZero() {
+		l.tokens = min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	}
+	l.last = now
+	st := Status{Limit: int(l.burst), Window: l.seconds(l.burst)}
+	if l.tokens >= 1 {
+		l.tokens--
+		st.Allowed = true
+	}
+	st.Remaining = int(l.tokens)
+	if st.Remaining == 0 {
+		st.Reset = l.seconds(1 - l.tokens)
+	} else {
+		st.Reset = l.seconds(l.burst - l.tokens)
+	}
+	return st
+}
+
+// seconds returns the time to refill n tokens
+func (l *Limiter) seconds(n float64) time.Duration {
+	return time.Duration(n / l.rate * float64(time.Second))
+}
@@ -0,0 +1,249 @@
+// Package tenant attributes requests to tenants so one deployment can
+// serve many properties. Tenants are described in a YAML file:
+//
+//	tenants:
+//	  - id: shop
+//	    api_keys: [k-shop-1]
+//	    hosts: [shop.example.com]
+//	    ruleset: rules/shop.yaml
+//	    rate_limit: 50
+//	    burst: 100
+//	    log_profile: minimal
+//
+// A request belongs to the tenant owning its X-API-Key, or else the tenant
+// serving its Host. Each tenant classifies with its own ruleset (policy,
+// patterns and weights) and is rate limited on its own budget.
+package tenant
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
+)
+
+// APIKeyHeader is the request header carrying a tenant API key
+const APIKeyHeader = "X-API-Key"
+
+// ErrUnknownAPIKey is returned for requests presenting an API key no tenant owns
+var ErrUnknownAPIKey = errors.New("unknown API key")
+
+// File is a parsed tenants file
+type File struct {
+	Tenants []Config `yaml:"tenants"`
+}
+
+// Config describes one tenant
+type Config struct {
+	ID        string   `yaml:"id"`
+	APIKeys   []string `yaml:"api_keys"`
+	Hosts     []string `yaml:"hosts"`
+	Ruleset   string   `yaml:"ruleset"`    // Ruleset file, relative to the tenants file (empty = server defaults)
+	RateLimit float64  `yaml:"rate_limit"` // Classified requests per second (0 = unlimited)
+	Burst     int      `yaml:"burst"`      // Requests allowed at once (default: rate_limit rounded up)
+
+	// Log profile of the tenant's log file: full or minimal (empty = server default)
+	LogProfile string `yaml:"log_profile"`
+}
+
+// Tenant is a configured tenant with its classifier and rate limiter
+type Tenant struct {
+	Config
+	Classifier     *classifier.Classifier
+	Limiter        *Limiter // nil when unlimited
+	RulesetVersion string   // Version declared by the tenant's ruleset (empty without one)
+}
+
+// Registry resolves requests to tenants
+type Registry struct {
+	tenants []*Tenant
+	byID    map[string]*Tenant
+	byKey   map[string]*Tenant
+	byHost  map[string]*Tenant
+}
+
+// Load reads a tenants file. Tenants without a ruleset classify with base.
+func Load(path string, base classifier.Config) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	reg, err := Parse(data, filepath.Dir(path), base)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return reg, nil
+}
+
+// Parse decodes a tenants file, resolving ruleset paths against dir
+func Parse(data []byte, dir string, base classifier.Config) (*Registry, error) {
+	var f File
+	if err := decodeStrict(data, &f); err != nil {
+		return nil, err
+	}
+	if len(f.Tenants) == 0 {
+		return nil, errors.New("no tenants defined")
+	}
+
+	reg := &Registry{
+		byID:   map[string]*Tenant{},
+		byKey:  map[string]*Tenant{},
+		byHost: map[string]*Tenant{},
+	}
+	for i, cfg := range f.Tenants {
+		t, err := newTenant(cfg, dir, base)
+		if err != nil {
+			return nil, fmt.Errorf("tenants[%d]: %w", i, err)
+		}
+		if err := reg.add(t); err != nil {
+			return nil, fmt.Errorf("tenants[%d]: %w", i, err)
+		}
+	}
+	return reg, nil
+}
+
+// newTenant builds a tenant's classifier and limiter
+func newTenant(cfg Config, dir string, base classifier.Config) (*Tenant, error) {
+	if !validID(cfg.ID) {
+		return nil, fmt.Errorf("invalid tenant id %q (use letters, digits, '-' and '_')", cfg.ID)
+	}
+	if def := logger.DefaultConfig().FileName; strings.EqualFold(cfg.ID+".jsonl", def) {
+		return nil, fmt.Errorf("tenant id %q is reserved: its log would be the default log %s", cfg.ID, def)
+	}
+	if len(cfg.APIKeys) == 0 && len(cfg.Hosts) == 0 {
+		return nil, fmt.Errorf("tenant %s: api_keys or hosts is required", cfg.ID)
+	}
+	if cfg.RateLimit < 0 || cfg.Burst < 0 {
+		return nil, fmt.Errorf("tenant %s: rate_limit and burst must not be negative", cfg.ID)
+	}
+	if cfg.LogProfile != "" {
+		if _, err := logger.ParseProfile(cfg.LogProfile); err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", cfg.ID, err)
+		}
+	}
+
+	clsCfg := base
+	var rulesetVersion string
+	if cfg.Ruleset != "" {
+		path := cfg.Ruleset
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		rs, err := ruleset.LoadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("tenant %s: %w", cfg.ID, err)
+		}
+		if issues := rs.Lint(); ruleset.HasErrors(issues) {
+			return nil, fmt.Errorf("tenant %s: ruleset %s has lint errors", cfg.ID, cfg.Ruleset)
+		}
+		clsCfg = classifier.NewConfig(base, classifier.WithThreshold(rs.Policy.Threshold), classifier.WithRules(rs.Rules()))
+		rulesetVersion = rs.Version
+	}
+
+	t := &Tenant{Config: cfg, Classifier: classifier.New(clsCfg), RulesetVersion: rulesetVersion}
+	if cfg.RateLimit > 0 {
+		t.Limiter = NewLimiter(cfg.RateLimit, cfg.Burst)
+	}
+	return t, nil
+}
+
+// add indexes a tenant, rejecting duplicate IDs, keys and hosts
+func (reg *Registry) add(t *Tenant) error {
+	if reg.byID[t.ID] != nil {
+		return fmt.Errorf("duplicate tenant id %s", t.ID)
+	}
+	for _, k := range t.APIKeys {
+		if k == "" {
+			return fmt.Errorf("tenant %s: empty api key", t.ID)
+		}
+		if other := reg.byKey[k]; other != nil {
+			return fmt.Errorf("tenant %s: api key already used by %s", t.ID, other.ID)
+		}
+	}
+	for _, h := range t.Hosts {
+		h = normalizeHost(h)
+		if other := reg.byHost[h]; other != nil {
+			return fmt.Errorf("tenant %s: host %s already served by %s", t.ID, h, other.ID)
+		}
+	}
+
+	reg.tenants = append(reg.tenants, t)
+	reg.byID[t.ID] = t
+	for _, k := range t.APIKeys {
+		reg.byKey[k] = t
+	}
+	for _, h := range t.Hosts {
+		reg.byHost[normalizeHost(h)] = t
+	}
+	return nil
+}
+
+// Tenants returns the configured tenants in file order
+func (reg *Registry) Tenants() []*Tenant {
+	return reg.tenants
+}
+
+// Lookup returns the tenant with the given ID
+func (reg *Registry) Lookup(id string) *Tenant {
+	return reg.byID[id]
+}
+
+// ByAPIKey returns the tenant owning key
+func (reg *Registry) ByAPIKey(key string) *Tenant {
+	return reg.byKey[key]
+}
+
+// Resolve returns the tenant a request belongs to: the owner of its API
+// key, or else the tenant serving its Host. It returns nil for requests
+// matching no tenant, and ErrUnknownAPIKey for unrecognized keys.
+func (reg *Registry) Resolve(r *http.Request) (*Tenant, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" {
+		if t := reg.byKey[key]; t != nil {
+			return t, nil
+		}
+		return nil, ErrUnknownAPIKey
+	}
+	return reg.byHost[normalizeHost(r.Host)], nil
+}
+
+// normalizeHost lowercases a host and strips its port
+func normalizeHost(host string) string {
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	return strings.ToLower(strings.TrimSuffix(host, "."))
+}
+
+// validID reports whether id is safe to use in log file names
+func validID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_') {
+			return false
+		}
+	}
+	return true
+}
+
+// decodeStrict decodes a single YAML document, failing on unknown keys
+func decodeStrict(data []byte, v any) error {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(v); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+	return nil
+}
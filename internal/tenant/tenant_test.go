@@ -0,0 +1,13 @@
+package tenant
+
+import "testing"
+
+// Tests are in tests/unit/tenant_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestTenantPackage(t *testing.T) {
+	// Verify package is testable
+	if APIKeyHeader == "" {
+		t.Error("APIKeyHeader should not be empty")
+	}
+}
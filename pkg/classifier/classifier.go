@@ -0,0 +1,192 @@
+// Package classifier is the public, semver-stable API for classifying
+// clients as browsers or bots from a fingerprint.
+//
+// It re-exports the implementation in internal/classifier. Config fields
+// and options may be added in minor releases; zero values keep the
+// previous behavior.
+package classifier
+
+import (
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/model"
+	"github.com/muliwe/go-client-classifier/pkg/fingerprint"
+)
+
+// Classification labels
+const (
+	ClassificationBrowser = classifier.ClassificationBrowser
+	ClassificationBot     = classifier.ClassificationBot
+)
+
+// Client classes refining the classification
+const (
+	ClassBrowser         = classifier.ClassBrowser
+	ClassAICrawler       = classifier.ClassAICrawler
+	ClassSearchBot       = classifier.ClassSearchBot
+	ClassHeadlessBrowser = classifier.ClassHeadlessBrowser
+	ClassHTTPLibrary     = classifier.ClassHTTPLibrary
+	ClassUnknown         = classifier.ClassUnknown
+)
+
+// Scoring backends
+const (
+	BackendHeuristic = classifier.BackendHeuristic
+	BackendML        = classifier.BackendML
+)
+
+// Classifier performs client classification based on fingerprint signals
+type Classifier = classifier.Classifier
+
+// Config holds classifier configuration
+type Config = classifier.Config
+
+// DefaultConfig returns default classifier configuration
+func DefaultConfig() Config {
+	return classifier.DefaultConfig()
+}
+
+// Option configures a Classifier. Config is itself an Option.
+type Option = classifier.Option
+
+// WithThreshold sets the net score cutoff for browser classification
+func WithThreshold(threshold int) Option {
+	return classifier.WithThreshold(threshold)
+}
+
+// WithRules replaces the built-in User-Agent patterns and rule weights
+func WithRules(rules fingerprint.Rules) Option {
+	return classifier.WithRules(rules)
+}
+
+// WithWeights overrides the points of rules by name; 0 disables a rule
+func WithWeights(weights map[string]int) Option {
+	return classifier.WithWeights(weights)
+}
+
+// ParseWeights parses name=points pairs separated by commas
+func ParseWeights(s string) (map[string]int, error) {
+	return classifier.ParseWeights(s)
+}
+
+// Model is a trained model for the ml backend, written by cmd/train
+type Model = model.Model
+
+// LoadModel reads a model file
+func LoadModel(path string) (*Model, error) {
+	return model.Load(path)
+}
+
+// WithBackend selects the scoring backend, BackendHeuristic or BackendML
+func WithBackend(backend string) Option {
+	return classifier.WithBackend(backend)
+}
+
+// WithModel scores signals with a trained model, selecting BackendML
+func WithModel(m *Model) Option {
+	return classifier.WithModel(m)
+}
+
+// Calibration maps raw confidence to observed precision, written by
+// cmd/calibrate
+type Calibration = calibration.Calibration
+
+// LoadCalibration reads a calibration file
+func LoadCalibration(path string) (*Calibration, error) {
+	return calibration.Load(path)
+}
+
+// WithCalibration reports confidence mapped by a fitted calibration
+func WithCalibration(cal *Calibration) Option {
+	return classifier.WithCalibration(cal)
+}
+
+// Scorer adds company-specific evidence to the built-in rules
+type Scorer = classifier.Scorer
+
+// ScorerFunc adapts a function to the Scorer interface
+type ScorerFunc = classifier.ScorerFunc
+
+// NamedScorer is a scorer registered under its breakdown name
+type NamedScorer = classifier.NamedScorer
+
+// WithScorer adds a custom scorer whose points show in the score
+// breakdown under name
+func WithScorer(name string, s Scorer) Option {
+	return classifier.WithScorer(name, s)
+}
+
+// DefaultCacheTTL is how long cached verdicts are reused by default
+const DefaultCacheTTL = classifier.DefaultCacheTTL
+
+// VerdictCache caches verdicts of identical clients (Classifier.Cache)
+type VerdictCache = classifier.VerdictCache
+
+// CacheStats counts verdict cache lookups
+type CacheStats = classifier.CacheStats
+
+// WithVerdictCache caches up to size verdicts for ttl (0 = DefaultCacheTTL)
+func WithVerdictCache(size int, ttl time.Duration) Option {
+	return classifier.WithVerdictCache(size, ttl)
+}
+
+// Feedback is an operator's correction of a classification
+type Feedback = classifier.Feedback
+
+// FeedbackStore records corrected samples for review and retraining
+type FeedbackStore = classifier.FeedbackStore
+
+// FeedbackResult reports what a correction changed
+type FeedbackResult = classifier.FeedbackResult
+
+// ErrFeedbackDisabled is returned by Classifier.Feedback when feedback is
+// neither stored nor learned from
+var ErrFeedbackDisabled = classifier.ErrFeedbackDisabled
+
+// WithFeedback stores corrections submitted with Classifier.Feedback
+func WithFeedback(store FeedbackStore) Option {
+	return classifier.WithFeedback(store)
+}
+
+// WithFeedbackAdjust lets corrections lower the weights of misleading rules
+func WithFeedbackAdjust(enabled bool) Option {
+	return classifier.WithFeedbackAdjust(enabled)
+}
+
+// Enricher adds data from external lookups to a collected fingerprint
+type Enricher = classifier.Enricher
+
+// WithEnrichers appends enrichers run by ClassifyRequest
+func WithEnrichers(enrichers ...Enricher) Option {
+	return classifier.WithEnrichers(enrichers...)
+}
+
+// WithEnrichmentTimeout bounds the time spent in enrichers per request
+func WithEnrichmentTimeout(d time.Duration) Option {
+	return classifier.WithEnrichmentTimeout(d)
+}
+
+// WithEnrichmentBudget bounds the time spent in the named enricher per request
+func WithEnrichmentBudget(name string, d time.Duration) Option {
+	return classifier.WithEnrichmentBudget(name, d)
+}
+
+// Timings is the per-stage timing breakdown returned by
+// Classifier.ClassifyRequestTimed
+type Timings = classifier.Timings
+
+// BotScoreHeader is the conventional response header for BotScore
+const BotScoreHeader = classifier.BotScoreHeader
+
+// BotScore maps a result to Cloudflare's 1-99 bot score (1 = automated,
+// 99 = human; below 30 is likely automated)
+func BotScore(r fingerprint.ClassificationResult) int {
+	return classifier.BotScore(r)
+}
+
+// New creates a new classifier from DefaultConfig and the given options
+func New(opts ...Option) *Classifier {
+	return classifier.New(opts...)
+}
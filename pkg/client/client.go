@@ -0,0 +1,260 @@
+// Package client is a typed Go client for the classification server.
+//
+// It wraps the remote classification endpoints with retries, timeouts
+// and connection pooling. Services should depend on the API interface
+// so the client can be replaced with a mock in tests.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/pkg/fingerprint"
+)
+
+// API is the set of operations offered by the classification server
+type API interface {
+	// Classify sends the metadata of an incoming request for classification
+	Classify(ctx context.Context, r *http.Request) (*fingerprint.ClassificationResult, error)
+	// ClassifyFingerprint classifies an already collected fingerprint
+	ClassifyFingerprint(ctx context.Context, fp fingerprint.Fingerprint) (*fingerprint.ClassificationResult, error)
+	// Stats returns classification counters since server start
+	Stats(ctx context.Context) (*Stats, error)
+	// Health checks server availability
+	Health(ctx context.Context) (*Health, error)
+}
+
+// Stats represents the server's classification statistics
+type Stats struct {
+	TotalRequests int64   `json:"total_requests"`
+	Browser       int64   `json:"browser"`
+	Bot           int64   `json:"bot"`
+	UptimeSeconds float64 `json:"uptime_seconds"`
+	Version       string  `json:"version"`
+}
+
+// Health represents the server's health check response
+type Health struct {
+	Status  string `json:"status"`
+	Version string `json:"version"`
+}
+
+// RequestMetadata is the wire format for Classify
+type RequestMetadata = fingerprint.RequestMetadata
+
+// StatusError is returned when the server responds with a non-2xx status
+type StatusError struct {
+	StatusCode int
+	Code       string // Machine-readable error code from a problem+json body (see docs/ERRORS.md)
+	Body       string
+}
+
+func (e *StatusError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("classifier server returned %d (%s): %s", e.StatusCode, e.Code, e.Body)
+	}
+	return fmt.Sprintf("classifier server returned %d: %s", e.StatusCode, e.Body)
+}
+
+// newStatusError builds a StatusError, extracting the error code from
+// problem+json bodies
+func newStatusError(resp *http.Response, body []byte) *StatusError {
+	se := &StatusError{StatusCode: resp.StatusCode, Body: strings.TrimSpace(string(body))}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/problem+json") {
+		var problem struct {
+			Code string `json:"code"`
+		}
+		if json.Unmarshal(body, &problem) == nil {
+			se.Code = problem.Code
+		}
+	}
+	return se
+}
+
+// Config holds client configuration
+type Config struct {
+	BaseURL      string        // Server base URL, e.g. "http://localhost:8080"
+	Timeout      time.Duration // Per-attempt request timeout
+	MaxRetries   int           // Retries after the first attempt on network errors, 429 and 5xx
+	RetryBackoff time.Duration // Initial backoff, doubled after each retry
+	MaxIdleConns int           // Idle connections kept per host
+	HTTPClient   *http.Client  // Optional custom HTTP client (overrides Timeout and MaxIdleConns)
+}
+
+// DefaultConfig returns default client configuration
+func DefaultConfig() Config {
+	return Config{
+		BaseURL:      "http://localhost:8080",
+		Timeout:      2 * time.Second,
+		MaxRetries:   2,
+		RetryBackoff: 50 * time.Millisecond,
+		MaxIdleConns: 100,
+	}
+}
+
+// Client is an HTTP client for the classification server
+type Client struct {
+	baseURL      *url.URL
+	httpClient   *http.Client
+	maxRetries   int
+	retryBackoff time.Duration
+}
+
+var _ API = (*Client)(nil)
+
+// New creates a new client
+func New(cfg Config) (*Client, error) {
+	base, err := url.Parse(strings.TrimRight(cfg.BaseURL, "/"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if base.Scheme == "" || base.Host == "" {
+		return nil, fmt.Errorf("invalid base URL: %q", cfg.BaseURL)
+	}
+
+	httpClient := cfg.HTTPClient
+	if httpClient == nil {
+		tr := http.DefaultTransport.(*http.Transport).Clone()
+		tr.MaxIdleConns = cfg.MaxIdleConns
+		tr.MaxIdleConnsPerHost = cfg.MaxIdleConns
+		httpClient = &http.Client{
+			Transport: tr,
+			Timeout:   cfg.Timeout,
+		}
+	}
+
+	return &Client{
+		baseURL:      base,
+		httpClient:   httpClient,
+		maxRetries:   max(cfg.MaxRetries, 0),
+		retryBackoff: cfg.RetryBackoff,
+	}, nil
+}
+
+// Classify sends the metadata of an incoming request for classification
+func (c *Client) Classify(ctx context.Context, r *http.Request) (*fingerprint.ClassificationResult, error) {
+	meta := fingerprint.MetadataFromRequest(r)
+	var result fingerprint.ClassificationResult
+	if err := c.do(ctx, http.MethodPost, "/v1/classify", meta, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ClassifyFingerprint classifies an already collected fingerprint
+func (c *Client) ClassifyFingerprint(ctx context.Context, fp fingerprint.Fingerprint) (*fingerprint.ClassificationResult, error) {
+	var result fingerprint.ClassificationResult
+	if err := c.do(ctx, http.MethodPost, "/v1/classify/fingerprint", fp, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Stats returns classification counters since server start
+func (c *Client) Stats(ctx context.Context) (*Stats, error) {
+	var stats Stats
+	if err := c.do(ctx, http.MethodGet, "/v1/stats", nil, &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// Health checks server availability
+func (c *Client) Health(ctx context.Context) (*Health, error) {
+	var health Health
+	if err := c.do(ctx, http.MethodGet, "/v1/health", nil, &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// do performs a request with retries and decodes the JSON response into out
+func (c *Client) do(ctx context.Context, method, path string, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		body, err = json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("failed to encode request: %w", err)
+		}
+	}
+
+	endpoint := c.baseURL.String() + path
+	backoff := c.retryBackoff
+
+	var lastErr error
+	for attempt := 0; attempt <= c.maxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		retry, err := c.attempt(ctx, method, endpoint, body, out)
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if !retry || ctx.Err() != nil {
+			break
+		}
+	}
+	return lastErr
+}
+
+// attempt performs a single request. It reports whether a failure is retryable.
+func (c *Client) attempt(ctx context.Context, method, endpoint string, body []byte, out any) (bool, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reader)
+	if err != nil {
+		return false, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return true, fmt.Errorf("request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		retry := resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retry, newStatusError(resp, msg)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return false, nil
+}
+
+// IsStatus reports whether err is a StatusError with the given status code
+func IsStatus(err error, code int) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.StatusCode == code
+}
+
+// IsCode reports whether err is a StatusError with the given error code
+func IsCode(err error, code string) bool {
+	var se *StatusError
+	return errors.As(err, &se) && se.Code == code
+}
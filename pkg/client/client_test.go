@@ -0,0 +1,14 @@
+package client
+
+import "testing"
+
+// Tests are in tests/unit/client_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestClientPackage(t *testing.T) {
+	// Verify package is testable
+	c, err := New(DefaultConfig())
+	if err != nil || c == nil {
+		t.Errorf("New(DefaultConfig()) = %v, %v", c, err)
+	}
+}
@@ -0,0 +1,325 @@
+// Package fingerprint is the public, semver-stable API for collecting
+// request fingerprints and extracting classification signals.
+//
+// It re-exports the implementation in internal/fingerprint. Types are
+// aliases, so values can be passed freely between this package and the
+// classifier package. Fields may be added in minor releases; existing
+// fields and functions are not removed or changed before v2.
+package fingerprint
+
+import (
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// Fingerprint contains all collected signals from a request
+type Fingerprint = fingerprint.Fingerprint
+
+// TLSFingerprint contains TLS-level signals
+type TLSFingerprint = fingerprint.TLSFingerprint
+
+// TCPFingerprint contains the client's TCP SYN
+type TCPFingerprint = fingerprint.TCPFingerprint
+
+// QUICFingerprint contains the QUIC transport parameters of an HTTP/3
+// client's ClientHello
+type QUICFingerprint = fingerprint.QUICFingerprint
+
+// HTTPFingerprint contains HTTP-level signals
+type HTTPFingerprint = fingerprint.HTTPFingerprint
+
+// ClientHints contains the parsed User-Agent Client Hints of a request
+type ClientHints = fingerprint.ClientHints
+
+// ParsedUserAgent is the browser family, version, OS and device a
+// User-Agent names
+type ParsedUserAgent = fingerprint.ParsedUserAgent
+
+// SessionFingerprint contains behavioral timing signals across requests
+type SessionFingerprint = fingerprint.SessionFingerprint
+
+// NetworkFingerprint contains signals about the client's network origin
+type NetworkFingerprint = fingerprint.NetworkFingerprint
+
+// Signals contains extracted classification signals
+type Signals = fingerprint.Signals
+
+// ClassificationResult contains the final classification
+type ClassificationResult = fingerprint.ClassificationResult
+
+// RequestMetadata describes an HTTP request observed elsewhere
+type RequestMetadata = fingerprint.RequestMetadata
+
+// ChromeVersion is the Chrome version a request claims, aware of User-Agent reduction
+type ChromeVersion = fingerprint.ChromeVersion
+
+// BrandVersion is one entry of a Sec-CH-UA style brand list
+type BrandVersion = fingerprint.BrandVersion
+
+// Rules holds the User-Agent patterns and rule weights used for scoring
+type Rules = fingerprint.Rules
+
+// ScoringRule describes one rule of the scoring model
+type ScoringRule = fingerprint.ScoringRule
+
+// CustomRule is a scoring rule defined in configuration rather than code
+type CustomRule = fingerprint.CustomRule
+
+// Breakdown lists the scoring rules that fired for a request
+type Breakdown = fingerprint.Breakdown
+
+// SignalContribution is a scoring rule that fired, with its direction and points
+type SignalContribution = fingerprint.SignalContribution
+
+// RuleScore is a fired rule with the points it scored
+type RuleScore = fingerprint.RuleScore
+
+// Directions a scoring rule can push the decision
+const (
+	DirectionBrowser = fingerprint.DirectionBrowser
+	DirectionBot     = fingerprint.DirectionBot
+)
+
+// Collector extracts fingerprint data from HTTP requests
+type Collector = fingerprint.Collector
+
+// GoldenFingerprint is a curated real-world fingerprint of a known client
+type GoldenFingerprint = fingerprint.GoldenFingerprint
+
+// KnownFingerprint is a JA3 hash or JA4 fingerprint of a known client
+type KnownFingerprint = fingerprint.KnownFingerprint
+
+// FingerprintDB indexes known fingerprints by hash
+type FingerprintDB = fingerprint.FingerprintDB
+
+// KnownCrawler describes a crawler by its User-Agent product token
+type KnownCrawler = fingerprint.KnownCrawler
+
+// RecognizedCrawler is a known crawler with the patterns that recognize it
+type RecognizedCrawler = fingerprint.RecognizedCrawler
+
+// Crawler categories
+const (
+	CategoryAITraining = fingerprint.CategoryAITraining
+	CategoryAIFetch    = fingerprint.CategoryAIFetch
+	CategorySearch     = fingerprint.CategorySearch
+	CategoryMonitoring = fingerprint.CategoryMonitoring
+)
+
+// Crawler verification methods
+const (
+	VerifyReverseDNS = fingerprint.VerifyReverseDNS
+	VerifyIPRanges   = fingerprint.VerifyIPRanges
+	VerifyNone       = fingerprint.VerifyNone
+)
+
+// Device types of a ParsedUserAgent
+const (
+	DeviceDesktop = fingerprint.DeviceDesktop
+	DeviceMobile  = fingerprint.DeviceMobile
+	DeviceTablet  = fingerprint.DeviceTablet
+	DeviceBot     = fingerprint.DeviceBot
+)
+
+// GoldenVersion identifies the revision of the embedded golden corpus
+const GoldenVersion = fingerprint.GoldenVersion
+
+// Golden corpus families
+const (
+	GoldenBrowser   = fingerprint.GoldenBrowser
+	GoldenLibrary   = fingerprint.GoldenLibrary
+	GoldenAICrawler = fingerprint.GoldenAICrawler
+)
+
+// TLSFingerprintContextKey is the context key type for TLS fingerprint
+type TLSFingerprintContextKey = fingerprint.TLSFingerprintContextKey
+
+// ContextKeyTLSFingerprint is the key for storing the ClientHello
+// fingerprint (*tlsfingerprint.Fingerprint) in the request context
+const ContextKeyTLSFingerprint = fingerprint.ContextKeyTLSFingerprint
+
+// ContextKeyTCP is the key for the TCPFingerprint of the request's
+// connection
+const ContextKeyTCP = fingerprint.ContextKeyTCP
+
+// ContextKeyQUIC is the key for the QUICFingerprint of the request's
+// connection
+const ContextKeyQUIC = fingerprint.ContextKeyQUIC
+
+// ContextKeyLatency is the key for the LatencySource of the request's
+// connection
+const ContextKeyLatency = fingerprint.ContextKeyLatency
+
+// LatencySource returns the client's latency measured during the TLS
+// handshake, or 0 when it was not measured
+type LatencySource = fingerprint.LatencySource
+
+// ContextKeyHeaderOrder is the key for the HeaderOrderSource of the
+// request's connection
+const ContextKeyHeaderOrder = fingerprint.ContextKeyHeaderOrder
+
+// HeaderOrderSource returns the header names of a request as sent on the
+// wire, or nil when they were not captured
+type HeaderOrderSource = fingerprint.HeaderOrderSource
+
+// HeaderSniffer records the header names of HTTP/1.x requests, in wire
+// order and case, from the bytes read off a connection. It implements
+// HeaderOrderSource.
+type HeaderSniffer = fingerprint.HeaderSniffer
+
+// DefaultRules returns a copy of the built-in patterns with default weights
+func DefaultRules() Rules {
+	return fingerprint.DefaultRules()
+}
+
+// ScoringRules returns the built-in scoring rules with their default weights
+func ScoringRules() []ScoringRule {
+	return fingerprint.ScoringRules()
+}
+
+// DefaultFingerprintDB returns the built-in known fingerprints
+func DefaultFingerprintDB() *FingerprintDB {
+	return fingerprint.DefaultFingerprintDB()
+}
+
+// ReadFingerprintDB decodes known fingerprints in JSONL
+func ReadFingerprintDB(r io.Reader) (*FingerprintDB, error) {
+	return fingerprint.ReadFingerprintDB(r)
+}
+
+// LoadFingerprintDB reads known fingerprints from a file on top of the
+// built-in ones
+func LoadFingerprintDB(path string) (*FingerprintDB, error) {
+	return fingerprint.LoadFingerprintDB(path)
+}
+
+// NewCustomRule compiles a rule that fires when signal satisfies condition
+func NewCustomRule(rule ScoringRule, signal, condition string) (CustomRule, error) {
+	return fingerprint.NewCustomRule(rule, signal, condition)
+}
+
+// NewCollector creates a new fingerprint collector
+func NewCollector() *Collector {
+	return fingerprint.NewCollector()
+}
+
+// MetadataFromRequest captures the metadata of an HTTP request
+func MetadataFromRequest(r *http.Request) RequestMetadata {
+	return fingerprint.MetadataFromRequest(r)
+}
+
+// ExtractSignals analyzes fingerprint and extracts classification signals
+func ExtractSignals(fp Fingerprint) Signals {
+	return fingerprint.ExtractSignals(fp)
+}
+
+// ExtractSignalsWithRules extracts signals using the given patterns and weights
+func ExtractSignalsWithRules(fp Fingerprint, rules Rules) Signals {
+	return fingerprint.ExtractSignalsWithRules(fp, rules)
+}
+
+// VerdictKey identifies the clients a classification can be reused for;
+// ok is false when the fingerprint has no hash to tell clients apart by
+func VerdictKey(fp Fingerprint) (key [32]byte, ok bool) {
+	return fingerprint.VerdictKey(fp)
+}
+
+// ParseBreakdown reads a breakdown in the text form of older logs,
+// e.g. "BROWSER[http2(+2)] BOT[bot-ua(+3)]"
+func ParseBreakdown(text string) Breakdown {
+	return fingerprint.ParseBreakdown(text)
+}
+
+// ParseChromeVersion parses the Chrome version of a User-Agent, refined by
+// an optional Sec-CH-UA-Full-Version-List value
+func ParseChromeVersion(userAgent, fullVersionList string) (ChromeVersion, bool) {
+	return fingerprint.ParseChromeVersion(userAgent, fullVersionList)
+}
+
+// ParseUserAgent parses a User-Agent into the browser or client it names,
+// its major version, operating system and device type
+func ParseUserAgent(userAgent string) ParsedUserAgent {
+	return fingerprint.ParseUserAgent(userAgent)
+}
+
+// ParseBrandList parses a Sec-CH-UA style brand list, dropping GREASE brands
+func ParseBrandList(header string) []BrandVersion {
+	return fingerprint.ParseBrandList(header)
+}
+
+// KnownCrawlers returns the directory of documented crawlers. Use
+// Rules.Crawlers for those a rule set recognizes.
+func KnownCrawlers() []KnownCrawler {
+	return fingerprint.KnownCrawlers()
+}
+
+// Golden returns the embedded golden corpus of real-world fingerprints
+func Golden() ([]GoldenFingerprint, error) {
+	return fingerprint.Golden()
+}
+
+// GoldenFamily returns the golden corpus entries of one family
+func GoldenFamily(family string) ([]GoldenFingerprint, error) {
+	return fingerprint.GoldenFamily(family)
+}
+
+// ReadGolden decodes a corpus in the golden JSONL format
+func ReadGolden(r io.Reader) ([]GoldenFingerprint, error) {
+	return fingerprint.ReadGolden(r)
+}
+
+// JA4H computes the full JA4H fingerprint from an HTTP request
+func JA4H(req *http.Request) string {
+	return fingerprint.JA4H(req)
+}
+
+// JA4HOrdered computes JA4H with the header hash taken from the header
+// names in the order and case they were sent
+func JA4HOrdered(req *http.Request, names []string) string {
+	return fingerprint.JA4HOrdered(req, names)
+}
+
+// ParseTCPSYN parses a client SYN (IP and TCP headers) into a
+// TCPFingerprint with its JA4T
+func ParseTCPSYN(packet []byte) (TCPFingerprint, error) {
+	return fingerprint.ParseTCPSYN(packet)
+}
+
+// ParseQUICClientHello parses the transport parameters of a ClientHello
+// record read from the Initial packets of a QUIC version
+func ParseQUICClientHello(record []byte, version uint32) (QUICFingerprint, error) {
+	return fingerprint.ParseQUICClientHello(record, version)
+}
+
+// JA4L computes the JA4L-C fingerprint from the client's one-way latency
+// and the TTL of its SYN (0 when unknown)
+func JA4L(latency time.Duration, ttl int) string {
+	return fingerprint.JA4L(latency, ttl)
+}
+
+// ServerHello holds the fields of a TLS ServerHello that JA3S and JA4S
+// are computed from
+type ServerHello = fingerprint.ServerHello
+
+// ErrHelloRetryRequest is returned by ParseServerHello for a TLS 1.3
+// HelloRetryRequest
+var ErrHelloRetryRequest = fingerprint.ErrHelloRetryRequest
+
+// ParseServerHello parses a TLS handshake record that starts with a
+// ServerHello
+func ParseServerHello(record []byte) (*ServerHello, error) {
+	return fingerprint.ParseServerHello(record)
+}
+
+// JA3S computes the JA3S server fingerprint
+func JA3S(sh *ServerHello) string {
+	return fingerprint.JA3S(sh)
+}
+
+// JA4S computes the JA4S server fingerprint
+func JA4S(sh *ServerHello) string {
+	return fingerprint.JA4S(sh)
+}
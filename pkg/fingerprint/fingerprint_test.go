@@ -0,0 +1,13 @@
+package fingerprint
+
+import "testing"
+
+// Tests are in tests/unit/pkg_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestFingerprintPackage(t *testing.T) {
+	// Verify package is testable
+	if NewCollector() == nil {
+		t.Error("NewCollector should not return nil")
+	}
+}
@@ -0,0 +1,2715 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: classifier/v1/classifier.proto
+
+// Fingerprint and classification result types shared by the gRPC
+// service, Kafka serialization and non-Go consumers. Field names match
+// the JSON wire format of the HTTP API (api/openapi.yaml).
+
+package classifierv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Fingerprint contains all collected signals from a request
+type Fingerprint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tls           *TLSFingerprint        `protobuf:"bytes,1,opt,name=tls,proto3" json:"tls,omitempty"`
+	Http          *HTTPFingerprint       `protobuf:"bytes,2,opt,name=http,proto3" json:"http,omitempty"`
+	Session       *SessionFingerprint    `protobuf:"bytes,3,opt,name=session,proto3" json:"session,omitempty"`
+	Network       *NetworkFingerprint    `protobuf:"bytes,4,opt,name=network,proto3" json:"network,omitempty"`
+	Tcp           *TCPFingerprint        `protobuf:"bytes,5,opt,name=tcp,proto3" json:"tcp,omitempty"`
+	Quic          *QUICFingerprint       `protobuf:"bytes,6,opt,name=quic,proto3" json:"quic,omitempty"`
+	Rate          *RateFingerprint       `protobuf:"bytes,7,opt,name=rate,proto3" json:"rate,omitempty"`
+	Offender      *OffenderFingerprint   `protobuf:"bytes,8,opt,name=offender,proto3" json:"offender,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Fingerprint) Reset() {
+	*x = Fingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Fingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Fingerprint) ProtoMessage() {}
+
+func (x *Fingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Fingerprint.ProtoReflect.Descriptor instead.
+func (*Fingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Fingerprint) GetTls() *TLSFingerprint {
+	if x != nil {
+		return x.Tls
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetHttp() *HTTPFingerprint {
+	if x != nil {
+		return x.Http
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetSession() *SessionFingerprint {
+	if x != nil {
+		return x.Session
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetNetwork() *NetworkFingerprint {
+	if x != nil {
+		return x.Network
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetTcp() *TCPFingerprint {
+	if x != nil {
+		return x.Tcp
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetQuic() *QUICFingerprint {
+	if x != nil {
+		return x.Quic
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetRate() *RateFingerprint {
+	if x != nil {
+		return x.Rate
+	}
+	return nil
+}
+
+func (x *Fingerprint) GetOffender() *OffenderFingerprint {
+	if x != nil {
+		return x.Offender
+	}
+	return nil
+}
+
+// TLSFingerprint contains TLS-level signals
+type TLSFingerprint struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Version            string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                                                   // TLS version (e.g., "TLS 1.3")
+	CipherSuite        string                 `protobuf:"bytes,2,opt,name=cipher_suite,json=cipherSuite,proto3" json:"cipher_suite,omitempty"`                        // Negotiated cipher suite
+	Alpn               string                 `protobuf:"bytes,3,opt,name=alpn,proto3" json:"alpn,omitempty"`                                                         // Negotiated protocol (h2, http/1.1)
+	ServerName         string                 `protobuf:"bytes,4,opt,name=server_name,json=serverName,proto3" json:"server_name,omitempty"`                           // SNI hostname
+	CipherSuitesCount  int32                  `protobuf:"varint,5,opt,name=cipher_suites_count,json=cipherSuitesCount,proto3" json:"cipher_suites_count,omitempty"`   // Number of offered cipher suites
+	ExtensionsCount    int32                  `protobuf:"varint,6,opt,name=extensions_count,json=extensionsCount,proto3" json:"extensions_count,omitempty"`           // Number of TLS extensions
+	SupportedVersions  []string               `protobuf:"bytes,7,rep,name=supported_versions,json=supportedVersions,proto3" json:"supported_versions,omitempty"`      // Client-offered TLS versions
+	SignatureSchemes   []string               `protobuf:"bytes,8,rep,name=signature_schemes,json=signatureSchemes,proto3" json:"signature_schemes,omitempty"`         // Supported signature algorithms
+	SupportedGroups    []string               `protobuf:"bytes,9,rep,name=supported_groups,json=supportedGroups,proto3" json:"supported_groups,omitempty"`            // Supported elliptic curves
+	HasSessionTicket   bool                   `protobuf:"varint,10,opt,name=has_session_ticket,json=hasSessionTicket,proto3" json:"has_session_ticket,omitempty"`     // Session resumption support
+	HasEarlyData       bool                   `protobuf:"varint,11,opt,name=has_early_data,json=hasEarlyData,proto3" json:"has_early_data,omitempty"`                 // 0-RTT support
+	Ja3Hash            string                 `protobuf:"bytes,12,opt,name=ja3_hash,json=ja3Hash,proto3" json:"ja3_hash,omitempty"`                                   // JA3 fingerprint hash
+	Ja4Hash            string                 `protobuf:"bytes,13,opt,name=ja4_hash,json=ja4Hash,proto3" json:"ja4_hash,omitempty"`                                   // JA4 fingerprint hash
+	CertificateRequest bool                   `protobuf:"varint,14,opt,name=certificate_request,json=certificateRequest,proto3" json:"certificate_request,omitempty"` // Client cert requested
+	Available          bool                   `protobuf:"varint,15,opt,name=available,proto3" json:"available,omitempty"`                                             // TLS info was available
+	NoGrease           bool                   `protobuf:"varint,16,opt,name=no_grease,json=noGrease,proto3" json:"no_grease,omitempty"`                               // No GREASE values in cipher suites or extensions
+	LegacyCiphers      bool                   `protobuf:"varint,17,opt,name=legacy_ciphers,json=legacyCiphers,proto3" json:"legacy_ciphers,omitempty"`                // Offers DHE suites or the renegotiation SCSV
+	Ja4R               string                 `protobuf:"bytes,18,opt,name=ja4_r,json=ja4R,proto3" json:"ja4_r,omitempty"`                                            // JA4 with the sorted lists unhashed
+	Ja3SHash           string                 `protobuf:"bytes,19,opt,name=ja3s_hash,json=ja3sHash,proto3" json:"ja3s_hash,omitempty"`                                // JA3S fingerprint of the ServerHello
+	Ja4SHash           string                 `protobuf:"bytes,20,opt,name=ja4s_hash,json=ja4sHash,proto3" json:"ja4s_hash,omitempty"`                                // JA4S fingerprint of the ServerHello
+	LatencyUs          int32                  `protobuf:"varint,21,opt,name=latency_us,json=latencyUs,proto3" json:"latency_us,omitempty"`                            // Client's one-way latency from the handshake (0 = not measured)
+	Ja4L               string                 `protobuf:"bytes,22,opt,name=ja4l,proto3" json:"ja4l,omitempty"`                                                        // JA4L-C latency fingerprint
+	GreaseCiphers      bool                   `protobuf:"varint,23,opt,name=grease_ciphers,json=greaseCiphers,proto3" json:"grease_ciphers,omitempty"`                // GREASE values in cipher suites
+	GreaseExtensions   bool                   `protobuf:"varint,24,opt,name=grease_extensions,json=greaseExtensions,proto3" json:"grease_extensions,omitempty"`       // GREASE values in extensions
+	Extensions         []uint32               `protobuf:"varint,25,rep,packed,name=extensions,proto3" json:"extensions,omitempty"`                                    // Extension IDs in the order sent, GREASE included
+	Ech                bool                   `protobuf:"varint,26,opt,name=ech,proto3" json:"ech,omitempty"`                                                         // Encrypted Client Hello offered, real or GREASE
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *TLSFingerprint) Reset() {
+	*x = TLSFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TLSFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TLSFingerprint) ProtoMessage() {}
+
+func (x *TLSFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TLSFingerprint.ProtoReflect.Descriptor instead.
+func (*TLSFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *TLSFingerprint) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetCipherSuite() string {
+	if x != nil {
+		return x.CipherSuite
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetAlpn() string {
+	if x != nil {
+		return x.Alpn
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetServerName() string {
+	if x != nil {
+		return x.ServerName
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetCipherSuitesCount() int32 {
+	if x != nil {
+		return x.CipherSuitesCount
+	}
+	return 0
+}
+
+func (x *TLSFingerprint) GetExtensionsCount() int32 {
+	if x != nil {
+		return x.ExtensionsCount
+	}
+	return 0
+}
+
+func (x *TLSFingerprint) GetSupportedVersions() []string {
+	if x != nil {
+		return x.SupportedVersions
+	}
+	return nil
+}
+
+func (x *TLSFingerprint) GetSignatureSchemes() []string {
+	if x != nil {
+		return x.SignatureSchemes
+	}
+	return nil
+}
+
+func (x *TLSFingerprint) GetSupportedGroups() []string {
+	if x != nil {
+		return x.SupportedGroups
+	}
+	return nil
+}
+
+func (x *TLSFingerprint) GetHasSessionTicket() bool {
+	if x != nil {
+		return x.HasSessionTicket
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetHasEarlyData() bool {
+	if x != nil {
+		return x.HasEarlyData
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetJa3Hash() string {
+	if x != nil {
+		return x.Ja3Hash
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetJa4Hash() string {
+	if x != nil {
+		return x.Ja4Hash
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetCertificateRequest() bool {
+	if x != nil {
+		return x.CertificateRequest
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetNoGrease() bool {
+	if x != nil {
+		return x.NoGrease
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetLegacyCiphers() bool {
+	if x != nil {
+		return x.LegacyCiphers
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetJa4R() string {
+	if x != nil {
+		return x.Ja4R
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetJa3SHash() string {
+	if x != nil {
+		return x.Ja3SHash
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetJa4SHash() string {
+	if x != nil {
+		return x.Ja4SHash
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetLatencyUs() int32 {
+	if x != nil {
+		return x.LatencyUs
+	}
+	return 0
+}
+
+func (x *TLSFingerprint) GetJa4L() string {
+	if x != nil {
+		return x.Ja4L
+	}
+	return ""
+}
+
+func (x *TLSFingerprint) GetGreaseCiphers() bool {
+	if x != nil {
+		return x.GreaseCiphers
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetGreaseExtensions() bool {
+	if x != nil {
+		return x.GreaseExtensions
+	}
+	return false
+}
+
+func (x *TLSFingerprint) GetExtensions() []uint32 {
+	if x != nil {
+		return x.Extensions
+	}
+	return nil
+}
+
+func (x *TLSFingerprint) GetEch() bool {
+	if x != nil {
+		return x.Ech
+	}
+	return false
+}
+
+// HTTPFingerprint contains HTTP-level signals
+type HTTPFingerprint struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Version         string                 `protobuf:"bytes,1,opt,name=version,proto3" json:"version,omitempty"`                                                                           // HTTP version (HTTP/1.1, HTTP/2)
+	Method          string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"`                                                                             // Request method
+	Path            string                 `protobuf:"bytes,3,opt,name=path,proto3" json:"path,omitempty"`                                                                                 // Request path
+	Headers         map[string]string      `protobuf:"bytes,4,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // All headers (lowercased keys)
+	HeaderOrder     []string               `protobuf:"bytes,5,rep,name=header_order,json=headerOrder,proto3" json:"header_order,omitempty"`                                                // Order of headers as received
+	HeaderCount     int32                  `protobuf:"varint,6,opt,name=header_count,json=headerCount,proto3" json:"header_count,omitempty"`                                               // Total header count
+	UserAgent       string                 `protobuf:"bytes,7,opt,name=user_agent,json=userAgent,proto3" json:"user_agent,omitempty"`                                                      // User-Agent header
+	Accept          string                 `protobuf:"bytes,8,opt,name=accept,proto3" json:"accept,omitempty"`                                                                             // Accept header
+	AcceptLang      string                 `protobuf:"bytes,9,opt,name=accept_lang,json=acceptLang,proto3" json:"accept_lang,omitempty"`                                                   // Accept-Language header
+	AcceptEnc       string                 `protobuf:"bytes,10,opt,name=accept_enc,json=acceptEnc,proto3" json:"accept_enc,omitempty"`                                                     // Accept-Encoding header
+	Connection      string                 `protobuf:"bytes,11,opt,name=connection,proto3" json:"connection,omitempty"`                                                                    // Connection header
+	SecFetchSite    string                 `protobuf:"bytes,12,opt,name=sec_fetch_site,json=secFetchSite,proto3" json:"sec_fetch_site,omitempty"`                                          // Sec-Fetch-Site header
+	SecFetchMode    string                 `protobuf:"bytes,13,opt,name=sec_fetch_mode,json=secFetchMode,proto3" json:"sec_fetch_mode,omitempty"`                                          // Sec-Fetch-Mode header
+	SecFetchDest    string                 `protobuf:"bytes,14,opt,name=sec_fetch_dest,json=secFetchDest,proto3" json:"sec_fetch_dest,omitempty"`                                          // Sec-Fetch-Dest header
+	SecFetchUser    string                 `protobuf:"bytes,15,opt,name=sec_fetch_user,json=secFetchUser,proto3" json:"sec_fetch_user,omitempty"`                                          // Sec-Fetch-User header
+	SecChUa         string                 `protobuf:"bytes,16,opt,name=sec_ch_ua,json=secChUa,proto3" json:"sec_ch_ua,omitempty"`                                                         // Sec-CH-UA header
+	HasCookies      bool                   `protobuf:"varint,17,opt,name=has_cookies,json=hasCookies,proto3" json:"has_cookies,omitempty"`                                                 // Has Cookie header
+	HasReferer      bool                   `protobuf:"varint,18,opt,name=has_referer,json=hasReferer,proto3" json:"has_referer,omitempty"`                                                 // Has Referer header
+	ContentType     string                 `protobuf:"bytes,19,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`                                               // Content-Type header
+	ContentLength   int64                  `protobuf:"varint,20,opt,name=content_length,json=contentLength,proto3" json:"content_length,omitempty"`                                        // Content-Length value
+	Ja4HHash        string                 `protobuf:"bytes,21,opt,name=ja4h_hash,json=ja4hHash,proto3" json:"ja4h_hash,omitempty"`                                                        // JA4H HTTP fingerprint hash
+	PrivateToken    string                 `protobuf:"bytes,22,opt,name=private_token,json=privateToken,proto3" json:"private_token,omitempty"`                                            // Private Access Token outcome: "valid" or "invalid"
+	ChallengeToken  string                 `protobuf:"bytes,23,opt,name=challenge_token,json=challengeToken,proto3" json:"challenge_token,omitempty"`                                      // Challenge token outcome: "pass" or "fail" (empty = none)
+	Host            string                 `protobuf:"bytes,24,opt,name=host,proto3" json:"host,omitempty"`                                                                                // Host header
+	RawHeaderNames  []string               `protobuf:"bytes,25,rep,name=raw_header_names,json=rawHeaderNames,proto3" json:"raw_header_names,omitempty"`                                    // Header names in wire order and case (HTTP/1.x, when captured)
+	ClientHints     *ClientHints           `protobuf:"bytes,26,opt,name=client_hints,json=clientHints,proto3" json:"client_hints,omitempty"`                                               // Parsed User-Agent Client Hints
+	UserAgentParsed *ParsedUserAgent       `protobuf:"bytes,27,opt,name=user_agent_parsed,json=userAgentParsed,proto3" json:"user_agent_parsed,omitempty"`                                 // Browser family, version, OS and device of the User-Agent
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *HTTPFingerprint) Reset() {
+	*x = HTTPFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HTTPFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HTTPFingerprint) ProtoMessage() {}
+
+func (x *HTTPFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HTTPFingerprint.ProtoReflect.Descriptor instead.
+func (*HTTPFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *HTTPFingerprint) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetHeaders() map[string]string {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *HTTPFingerprint) GetHeaderOrder() []string {
+	if x != nil {
+		return x.HeaderOrder
+	}
+	return nil
+}
+
+func (x *HTTPFingerprint) GetHeaderCount() int32 {
+	if x != nil {
+		return x.HeaderCount
+	}
+	return 0
+}
+
+func (x *HTTPFingerprint) GetUserAgent() string {
+	if x != nil {
+		return x.UserAgent
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetAccept() string {
+	if x != nil {
+		return x.Accept
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetAcceptLang() string {
+	if x != nil {
+		return x.AcceptLang
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetAcceptEnc() string {
+	if x != nil {
+		return x.AcceptEnc
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetConnection() string {
+	if x != nil {
+		return x.Connection
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetSecFetchSite() string {
+	if x != nil {
+		return x.SecFetchSite
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetSecFetchMode() string {
+	if x != nil {
+		return x.SecFetchMode
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetSecFetchDest() string {
+	if x != nil {
+		return x.SecFetchDest
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetSecFetchUser() string {
+	if x != nil {
+		return x.SecFetchUser
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetSecChUa() string {
+	if x != nil {
+		return x.SecChUa
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetHasCookies() bool {
+	if x != nil {
+		return x.HasCookies
+	}
+	return false
+}
+
+func (x *HTTPFingerprint) GetHasReferer() bool {
+	if x != nil {
+		return x.HasReferer
+	}
+	return false
+}
+
+func (x *HTTPFingerprint) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetContentLength() int64 {
+	if x != nil {
+		return x.ContentLength
+	}
+	return 0
+}
+
+func (x *HTTPFingerprint) GetJa4HHash() string {
+	if x != nil {
+		return x.Ja4HHash
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetPrivateToken() string {
+	if x != nil {
+		return x.PrivateToken
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetChallengeToken() string {
+	if x != nil {
+		return x.ChallengeToken
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *HTTPFingerprint) GetRawHeaderNames() []string {
+	if x != nil {
+		return x.RawHeaderNames
+	}
+	return nil
+}
+
+func (x *HTTPFingerprint) GetClientHints() *ClientHints {
+	if x != nil {
+		return x.ClientHints
+	}
+	return nil
+}
+
+func (x *HTTPFingerprint) GetUserAgentParsed() *ParsedUserAgent {
+	if x != nil {
+		return x.UserAgentParsed
+	}
+	return nil
+}
+
+// ParsedUserAgent is the client a User-Agent names
+type ParsedUserAgent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Family        string                 `protobuf:"bytes,1,opt,name=family,proto3" json:"family,omitempty"`                        // Browser family or client name, e.g. "Chrome", "curl"
+	Major         int32                  `protobuf:"varint,2,opt,name=major,proto3" json:"major,omitempty"`                         // Major version
+	Os            string                 `protobuf:"bytes,3,opt,name=os,proto3" json:"os,omitempty"`                                // e.g. "Windows", "macOS", "iOS", "Android"
+	OsVersion     string                 `protobuf:"bytes,4,opt,name=os_version,json=osVersion,proto3" json:"os_version,omitempty"` // e.g. "10", "14.5"
+	Device        string                 `protobuf:"bytes,5,opt,name=device,proto3" json:"device,omitempty"`                        // "desktop", "mobile", "tablet" or "bot"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ParsedUserAgent) Reset() {
+	*x = ParsedUserAgent{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ParsedUserAgent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ParsedUserAgent) ProtoMessage() {}
+
+func (x *ParsedUserAgent) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ParsedUserAgent.ProtoReflect.Descriptor instead.
+func (*ParsedUserAgent) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ParsedUserAgent) GetFamily() string {
+	if x != nil {
+		return x.Family
+	}
+	return ""
+}
+
+func (x *ParsedUserAgent) GetMajor() int32 {
+	if x != nil {
+		return x.Major
+	}
+	return 0
+}
+
+func (x *ParsedUserAgent) GetOs() string {
+	if x != nil {
+		return x.Os
+	}
+	return ""
+}
+
+func (x *ParsedUserAgent) GetOsVersion() string {
+	if x != nil {
+		return x.OsVersion
+	}
+	return ""
+}
+
+func (x *ParsedUserAgent) GetDevice() string {
+	if x != nil {
+		return x.Device
+	}
+	return ""
+}
+
+// ClientHints contains the User-Agent Client Hints (Sec-CH-UA-*) of a request
+type ClientHints struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Brands          []*BrandVersion        `protobuf:"bytes,1,rep,name=brands,proto3" json:"brands,omitempty"`                                            // Sec-CH-UA, without GREASE brands
+	FullVersionList []*BrandVersion        `protobuf:"bytes,2,rep,name=full_version_list,json=fullVersionList,proto3" json:"full_version_list,omitempty"` // Sec-CH-UA-Full-Version-List
+	Platform        string                 `protobuf:"bytes,3,opt,name=platform,proto3" json:"platform,omitempty"`                                        // Sec-CH-UA-Platform
+	PlatformVersion string                 `protobuf:"bytes,4,opt,name=platform_version,json=platformVersion,proto3" json:"platform_version,omitempty"`   // Sec-CH-UA-Platform-Version
+	Mobile          bool                   `protobuf:"varint,5,opt,name=mobile,proto3" json:"mobile,omitempty"`                                           // Sec-CH-UA-Mobile is ?1
+	Model           string                 `protobuf:"bytes,6,opt,name=model,proto3" json:"model,omitempty"`                                              // Sec-CH-UA-Model
+	Arch            string                 `protobuf:"bytes,7,opt,name=arch,proto3" json:"arch,omitempty"`                                                // Sec-CH-UA-Arch
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ClientHints) Reset() {
+	*x = ClientHints{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClientHints) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClientHints) ProtoMessage() {}
+
+func (x *ClientHints) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClientHints.ProtoReflect.Descriptor instead.
+func (*ClientHints) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ClientHints) GetBrands() []*BrandVersion {
+	if x != nil {
+		return x.Brands
+	}
+	return nil
+}
+
+func (x *ClientHints) GetFullVersionList() []*BrandVersion {
+	if x != nil {
+		return x.FullVersionList
+	}
+	return nil
+}
+
+func (x *ClientHints) GetPlatform() string {
+	if x != nil {
+		return x.Platform
+	}
+	return ""
+}
+
+func (x *ClientHints) GetPlatformVersion() string {
+	if x != nil {
+		return x.PlatformVersion
+	}
+	return ""
+}
+
+func (x *ClientHints) GetMobile() bool {
+	if x != nil {
+		return x.Mobile
+	}
+	return false
+}
+
+func (x *ClientHints) GetModel() string {
+	if x != nil {
+		return x.Model
+	}
+	return ""
+}
+
+func (x *ClientHints) GetArch() string {
+	if x != nil {
+		return x.Arch
+	}
+	return ""
+}
+
+// BrandVersion is one brand of a Sec-CH-UA brand list
+type BrandVersion struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Brand         string                 `protobuf:"bytes,1,opt,name=brand,proto3" json:"brand,omitempty"`
+	Version       string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BrandVersion) Reset() {
+	*x = BrandVersion{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BrandVersion) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BrandVersion) ProtoMessage() {}
+
+func (x *BrandVersion) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BrandVersion.ProtoReflect.Descriptor instead.
+func (*BrandVersion) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *BrandVersion) GetBrand() string {
+	if x != nil {
+		return x.Brand
+	}
+	return ""
+}
+
+func (x *BrandVersion) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+// SessionFingerprint contains behavioral timing signals across requests
+// from the same client session
+type SessionFingerprint struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	RequestCount     int32                  `protobuf:"varint,1,opt,name=request_count,json=requestCount,proto3" json:"request_count,omitempty"`                // Requests observed in this session
+	IntervalCount    int32                  `protobuf:"varint,2,opt,name=interval_count,json=intervalCount,proto3" json:"interval_count,omitempty"`             // Inter-request intervals in the window
+	MeanIntervalMs   float64                `protobuf:"fixed64,3,opt,name=mean_interval_ms,json=meanIntervalMs,proto3" json:"mean_interval_ms,omitempty"`       // Mean gap between requests
+	MinIntervalMs    float64                `protobuf:"fixed64,4,opt,name=min_interval_ms,json=minIntervalMs,proto3" json:"min_interval_ms,omitempty"`          // Shortest gap between requests
+	IntervalStddevMs float64                `protobuf:"fixed64,5,opt,name=interval_stddev_ms,json=intervalStddevMs,proto3" json:"interval_stddev_ms,omitempty"` // Standard deviation of gaps
+	IntervalJitter   float64                `protobuf:"fixed64,6,opt,name=interval_jitter,json=intervalJitter,proto3" json:"interval_jitter,omitempty"`         // Coefficient of variation (stddev / mean)
+	Available        bool                   `protobuf:"varint,7,opt,name=available,proto3" json:"available,omitempty"`                                          // Session tracking was available
+	PageRequests     int32                  `protobuf:"varint,8,opt,name=page_requests,json=pageRequests,proto3" json:"page_requests,omitempty"`                // Page requests observed in this session
+	AssetRequests    int32                  `protobuf:"varint,9,opt,name=asset_requests,json=assetRequests,proto3" json:"asset_requests,omitempty"`             // Subresource requests (stylesheets, scripts, images, favicon)
+	HumanVerified    bool                   `protobuf:"varint,10,opt,name=human_verified,json=humanVerified,proto3" json:"human_verified,omitempty"`            // The session solved a CAPTCHA
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *SessionFingerprint) Reset() {
+	*x = SessionFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionFingerprint) ProtoMessage() {}
+
+func (x *SessionFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionFingerprint.ProtoReflect.Descriptor instead.
+func (*SessionFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *SessionFingerprint) GetRequestCount() int32 {
+	if x != nil {
+		return x.RequestCount
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetIntervalCount() int32 {
+	if x != nil {
+		return x.IntervalCount
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetMeanIntervalMs() float64 {
+	if x != nil {
+		return x.MeanIntervalMs
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetMinIntervalMs() float64 {
+	if x != nil {
+		return x.MinIntervalMs
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetIntervalStddevMs() float64 {
+	if x != nil {
+		return x.IntervalStddevMs
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetIntervalJitter() float64 {
+	if x != nil {
+		return x.IntervalJitter
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *SessionFingerprint) GetPageRequests() int32 {
+	if x != nil {
+		return x.PageRequests
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetAssetRequests() int32 {
+	if x != nil {
+		return x.AssetRequests
+	}
+	return 0
+}
+
+func (x *SessionFingerprint) GetHumanVerified() bool {
+	if x != nil {
+		return x.HumanVerified
+	}
+	return false
+}
+
+// RateFingerprint contains the request rates of the client's address and
+// JA4 fingerprint over a sliding one-minute window
+type RateFingerprint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	AddrRequests  int32                  `protobuf:"varint,1,opt,name=addr_requests,json=addrRequests,proto3" json:"addr_requests,omitempty"` // Requests from the client address in the last minute
+	AddrBurst     int32                  `protobuf:"varint,2,opt,name=addr_burst,json=addrBurst,proto3" json:"addr_burst,omitempty"`          // Requests from the client address in the current second
+	Ja4Requests   int32                  `protobuf:"varint,3,opt,name=ja4_requests,json=ja4Requests,proto3" json:"ja4_requests,omitempty"`    // Requests with the JA4 fingerprint in the last minute
+	Ja4Burst      int32                  `protobuf:"varint,4,opt,name=ja4_burst,json=ja4Burst,proto3" json:"ja4_burst,omitempty"`             // Requests with the JA4 fingerprint in the current second
+	Available     bool                   `protobuf:"varint,5,opt,name=available,proto3" json:"available,omitempty"`                           // Rate tracking was available
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RateFingerprint) Reset() {
+	*x = RateFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RateFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RateFingerprint) ProtoMessage() {}
+
+func (x *RateFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RateFingerprint.ProtoReflect.Descriptor instead.
+func (*RateFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RateFingerprint) GetAddrRequests() int32 {
+	if x != nil {
+		return x.AddrRequests
+	}
+	return 0
+}
+
+func (x *RateFingerprint) GetAddrBurst() int32 {
+	if x != nil {
+		return x.AddrBurst
+	}
+	return 0
+}
+
+func (x *RateFingerprint) GetJa4Requests() int32 {
+	if x != nil {
+		return x.Ja4Requests
+	}
+	return 0
+}
+
+func (x *RateFingerprint) GetJa4Burst() int32 {
+	if x != nil {
+		return x.Ja4Burst
+	}
+	return 0
+}
+
+func (x *RateFingerprint) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+// OffenderFingerprint contains the offenses on record for the client's
+// address or JA4 fingerprint
+type OffenderFingerprint struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Trapped        bool                   `protobuf:"varint,1,opt,name=trapped,proto3" json:"trapped,omitempty"`                                     // Requested a honeypot trap
+	TrapPath       string                 `protobuf:"bytes,2,opt,name=trap_path,json=trapPath,proto3" json:"trap_path,omitempty"`                    // The trap requested
+	Reputation     float64                `protobuf:"fixed64,3,opt,name=reputation,proto3" json:"reputation,omitempty"`                              // Past bot verdicts, decayed exponentially with their age
+	RepeatOffender bool                   `protobuf:"varint,4,opt,name=repeat_offender,json=repeatOffender,proto3" json:"repeat_offender,omitempty"` // Reputation at or above the store's threshold
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *OffenderFingerprint) Reset() {
+	*x = OffenderFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *OffenderFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*OffenderFingerprint) ProtoMessage() {}
+
+func (x *OffenderFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use OffenderFingerprint.ProtoReflect.Descriptor instead.
+func (*OffenderFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *OffenderFingerprint) GetTrapped() bool {
+	if x != nil {
+		return x.Trapped
+	}
+	return false
+}
+
+func (x *OffenderFingerprint) GetTrapPath() string {
+	if x != nil {
+		return x.TrapPath
+	}
+	return ""
+}
+
+func (x *OffenderFingerprint) GetReputation() float64 {
+	if x != nil {
+		return x.Reputation
+	}
+	return 0
+}
+
+func (x *OffenderFingerprint) GetRepeatOffender() bool {
+	if x != nil {
+		return x.RepeatOffender
+	}
+	return false
+}
+
+// NetworkFingerprint contains signals about the client's network origin
+type NetworkFingerprint struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	PrivateRelay        bool                   `protobuf:"varint,1,opt,name=private_relay,json=privateRelay,proto3" json:"private_relay,omitempty"`                     // Remote address is an iCloud Private Relay egress
+	RelayCountry        string                 `protobuf:"bytes,2,opt,name=relay_country,json=relayCountry,proto3" json:"relay_country,omitempty"`                      // Country the relay egress serves
+	Country             string                 `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`                                                    // Client country from GeoIP enrichment
+	Local               bool                   `protobuf:"varint,4,opt,name=local,proto3" json:"local,omitempty"`                                                       // Loopback, private or link-local remote address
+	ClaimedCrawler      string                 `protobuf:"bytes,5,opt,name=claimed_crawler,json=claimedCrawler,proto3" json:"claimed_crawler,omitempty"`                // Documented crawler the User-Agent names, when checked
+	CrawlerVerification string                 `protobuf:"bytes,6,opt,name=crawler_verification,json=crawlerVerification,proto3" json:"crawler_verification,omitempty"` // "verified" or "spoofed" (empty = not checked)
+	Asn                 uint32                 `protobuf:"varint,7,opt,name=asn,proto3" json:"asn,omitempty"`                                                           // Autonomous system announcing the address (0 = not looked up)
+	AsOrg               string                 `protobuf:"bytes,8,opt,name=as_org,json=asOrg,proto3" json:"as_org,omitempty"`                                           // Organization of the autonomous system
+	Hosting             string                 `protobuf:"bytes,9,opt,name=hosting,proto3" json:"hosting,omitempty"`                                                    // Hosting provider operating the AS (empty = not listed)
+	Anonymizer          string                 `protobuf:"bytes,10,opt,name=anonymizer,proto3" json:"anonymizer,omitempty"`                                             // VPN, proxy or Tor list category, e.g. "vpn" (empty = not listed)
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *NetworkFingerprint) Reset() {
+	*x = NetworkFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkFingerprint) ProtoMessage() {}
+
+func (x *NetworkFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkFingerprint.ProtoReflect.Descriptor instead.
+func (*NetworkFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *NetworkFingerprint) GetPrivateRelay() bool {
+	if x != nil {
+		return x.PrivateRelay
+	}
+	return false
+}
+
+func (x *NetworkFingerprint) GetRelayCountry() string {
+	if x != nil {
+		return x.RelayCountry
+	}
+	return ""
+}
+
+func (x *NetworkFingerprint) GetCountry() string {
+	if x != nil {
+		return x.Country
+	}
+	return ""
+}
+
+func (x *NetworkFingerprint) GetLocal() bool {
+	if x != nil {
+		return x.Local
+	}
+	return false
+}
+
+func (x *NetworkFingerprint) GetClaimedCrawler() string {
+	if x != nil {
+		return x.ClaimedCrawler
+	}
+	return ""
+}
+
+func (x *NetworkFingerprint) GetCrawlerVerification() string {
+	if x != nil {
+		return x.CrawlerVerification
+	}
+	return ""
+}
+
+func (x *NetworkFingerprint) GetAsn() uint32 {
+	if x != nil {
+		return x.Asn
+	}
+	return 0
+}
+
+func (x *NetworkFingerprint) GetAsOrg() string {
+	if x != nil {
+		return x.AsOrg
+	}
+	return ""
+}
+
+func (x *NetworkFingerprint) GetHosting() string {
+	if x != nil {
+		return x.Hosting
+	}
+	return ""
+}
+
+func (x *NetworkFingerprint) GetAnonymizer() string {
+	if x != nil {
+		return x.Anonymizer
+	}
+	return ""
+}
+
+// TCPFingerprint contains the client's TCP SYN
+type TCPFingerprint struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Available     bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"`                        // SYN was captured
+	WindowSize    int32                  `protobuf:"varint,2,opt,name=window_size,json=windowSize,proto3" json:"window_size,omitempty"`    // Initial receive window
+	Options       []uint32               `protobuf:"varint,3,rep,packed,name=options,proto3" json:"options,omitempty"`                     // Option kinds in the order sent
+	Mss           int32                  `protobuf:"varint,4,opt,name=mss,proto3" json:"mss,omitempty"`                                    // Maximum segment size
+	WindowScale   int32                  `protobuf:"varint,5,opt,name=window_scale,json=windowScale,proto3" json:"window_scale,omitempty"` // Window scale shift
+	Ttl           int32                  `protobuf:"varint,6,opt,name=ttl,proto3" json:"ttl,omitempty"`                                    // IP TTL or hop limit as received
+	Ja4T          string                 `protobuf:"bytes,7,opt,name=ja4t,proto3" json:"ja4t,omitempty"`                                   // JA4T fingerprint
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TCPFingerprint) Reset() {
+	*x = TCPFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TCPFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TCPFingerprint) ProtoMessage() {}
+
+func (x *TCPFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TCPFingerprint.ProtoReflect.Descriptor instead.
+func (*TCPFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *TCPFingerprint) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *TCPFingerprint) GetWindowSize() int32 {
+	if x != nil {
+		return x.WindowSize
+	}
+	return 0
+}
+
+func (x *TCPFingerprint) GetOptions() []uint32 {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *TCPFingerprint) GetMss() int32 {
+	if x != nil {
+		return x.Mss
+	}
+	return 0
+}
+
+func (x *TCPFingerprint) GetWindowScale() int32 {
+	if x != nil {
+		return x.WindowScale
+	}
+	return 0
+}
+
+func (x *TCPFingerprint) GetTtl() int32 {
+	if x != nil {
+		return x.Ttl
+	}
+	return 0
+}
+
+func (x *TCPFingerprint) GetJa4T() string {
+	if x != nil {
+		return x.Ja4T
+	}
+	return ""
+}
+
+// QUICFingerprint contains the QUIC transport parameters of an HTTP/3
+// client's ClientHello
+type QUICFingerprint struct {
+	state                 protoimpl.MessageState `protogen:"open.v1"`
+	Available             bool                   `protobuf:"varint,1,opt,name=available,proto3" json:"available,omitempty"`                                                          // Request came over QUIC
+	Version               string                 `protobuf:"bytes,2,opt,name=version,proto3" json:"version,omitempty"`                                                               // QUIC version (e.g., "v1")
+	TransportParameters   []uint64               `protobuf:"varint,3,rep,packed,name=transport_parameters,json=transportParameters,proto3" json:"transport_parameters,omitempty"`    // Parameter IDs in the order sent
+	MaxIdleTimeout        int64                  `protobuf:"varint,4,opt,name=max_idle_timeout,json=maxIdleTimeout,proto3" json:"max_idle_timeout,omitempty"`                        // Milliseconds
+	MaxUdpPayloadSize     int64                  `protobuf:"varint,5,opt,name=max_udp_payload_size,json=maxUdpPayloadSize,proto3" json:"max_udp_payload_size,omitempty"`             // Largest datagram accepted
+	InitialMaxData        int64                  `protobuf:"varint,6,opt,name=initial_max_data,json=initialMaxData,proto3" json:"initial_max_data,omitempty"`                        // Connection flow control window
+	InitialMaxStreamsBidi int64                  `protobuf:"varint,7,opt,name=initial_max_streams_bidi,json=initialMaxStreamsBidi,proto3" json:"initial_max_streams_bidi,omitempty"` // Bidirectional streams allowed
+	InitialMaxStreamsUni  int64                  `protobuf:"varint,8,opt,name=initial_max_streams_uni,json=initialMaxStreamsUni,proto3" json:"initial_max_streams_uni,omitempty"`    // Unidirectional streams allowed
+	unknownFields         protoimpl.UnknownFields
+	sizeCache             protoimpl.SizeCache
+}
+
+func (x *QUICFingerprint) Reset() {
+	*x = QUICFingerprint{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *QUICFingerprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*QUICFingerprint) ProtoMessage() {}
+
+func (x *QUICFingerprint) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use QUICFingerprint.ProtoReflect.Descriptor instead.
+func (*QUICFingerprint) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *QUICFingerprint) GetAvailable() bool {
+	if x != nil {
+		return x.Available
+	}
+	return false
+}
+
+func (x *QUICFingerprint) GetVersion() string {
+	if x != nil {
+		return x.Version
+	}
+	return ""
+}
+
+func (x *QUICFingerprint) GetTransportParameters() []uint64 {
+	if x != nil {
+		return x.TransportParameters
+	}
+	return nil
+}
+
+func (x *QUICFingerprint) GetMaxIdleTimeout() int64 {
+	if x != nil {
+		return x.MaxIdleTimeout
+	}
+	return 0
+}
+
+func (x *QUICFingerprint) GetMaxUdpPayloadSize() int64 {
+	if x != nil {
+		return x.MaxUdpPayloadSize
+	}
+	return 0
+}
+
+func (x *QUICFingerprint) GetInitialMaxData() int64 {
+	if x != nil {
+		return x.InitialMaxData
+	}
+	return 0
+}
+
+func (x *QUICFingerprint) GetInitialMaxStreamsBidi() int64 {
+	if x != nil {
+		return x.InitialMaxStreamsBidi
+	}
+	return 0
+}
+
+func (x *QUICFingerprint) GetInitialMaxStreamsUni() int64 {
+	if x != nil {
+		return x.InitialMaxStreamsUni
+	}
+	return 0
+}
+
+// Signals contains extracted classification signals
+type Signals struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// TLS signals (from ClientHello)
+	IsHttp2             bool `protobuf:"varint,1,opt,name=is_http2,json=isHttp2,proto3" json:"is_http2,omitempty"`
+	IsHttp3             bool `protobuf:"varint,55,opt,name=is_http3,json=isHttp3,proto3" json:"is_http3,omitempty"`
+	HasModernTls        bool `protobuf:"varint,2,opt,name=has_modern_tls,json=hasModernTls,proto3" json:"has_modern_tls,omitempty"`
+	HasAlpn             bool `protobuf:"varint,3,opt,name=has_alpn,json=hasAlpn,proto3" json:"has_alpn,omitempty"`
+	HighCipherCount     bool `protobuf:"varint,4,opt,name=high_cipher_count,json=highCipherCount,proto3" json:"high_cipher_count,omitempty"`
+	HasSessionSupport   bool `protobuf:"varint,5,opt,name=has_session_support,json=hasSessionSupport,proto3" json:"has_session_support,omitempty"`
+	HasTlsFingerprint   bool `protobuf:"varint,6,opt,name=has_tls_fingerprint,json=hasTlsFingerprint,proto3" json:"has_tls_fingerprint,omitempty"`
+	HasMultipleGroups   bool `protobuf:"varint,7,opt,name=has_multiple_groups,json=hasMultipleGroups,proto3" json:"has_multiple_groups,omitempty"`
+	HasModernCiphers    bool `protobuf:"varint,8,opt,name=has_modern_ciphers,json=hasModernCiphers,proto3" json:"has_modern_ciphers,omitempty"`
+	HasGreaseCiphers    bool `protobuf:"varint,50,opt,name=has_grease_ciphers,json=hasGreaseCiphers,proto3" json:"has_grease_ciphers,omitempty"`
+	HasGreaseExtensions bool `protobuf:"varint,51,opt,name=has_grease_extensions,json=hasGreaseExtensions,proto3" json:"has_grease_extensions,omitempty"`
+	HasGreaseGroups     bool `protobuf:"varint,52,opt,name=has_grease_groups,json=hasGreaseGroups,proto3" json:"has_grease_groups,omitempty"`
+	HasEch              bool `protobuf:"varint,56,opt,name=has_ech,json=hasEch,proto3" json:"has_ech,omitempty"`
+	// HTTP signals
+	HasSecFetchHeaders     bool     `protobuf:"varint,9,opt,name=has_sec_fetch_headers,json=hasSecFetchHeaders,proto3" json:"has_sec_fetch_headers,omitempty"`
+	HasAcceptLanguage      bool     `protobuf:"varint,10,opt,name=has_accept_language,json=hasAcceptLanguage,proto3" json:"has_accept_language,omitempty"`
+	HasUserAgent           bool     `protobuf:"varint,11,opt,name=has_user_agent,json=hasUserAgent,proto3" json:"has_user_agent,omitempty"`
+	HasAccept              bool     `protobuf:"varint,12,opt,name=has_accept,json=hasAccept,proto3" json:"has_accept,omitempty"`
+	HasAcceptEncoding      bool     `protobuf:"varint,13,opt,name=has_accept_encoding,json=hasAcceptEncoding,proto3" json:"has_accept_encoding,omitempty"`
+	HasSecChUa             bool     `protobuf:"varint,14,opt,name=has_sec_ch_ua,json=hasSecChUa,proto3" json:"has_sec_ch_ua,omitempty"`
+	SpoofedReferer         bool     `protobuf:"varint,40,opt,name=spoofed_referer,json=spoofedReferer,proto3" json:"spoofed_referer,omitempty"`
+	FetchMetadataMismatch  bool     `protobuf:"varint,62,opt,name=fetch_metadata_mismatch,json=fetchMetadataMismatch,proto3" json:"fetch_metadata_mismatch,omitempty"`
+	FetchMetadataConflict  string   `protobuf:"bytes,63,opt,name=fetch_metadata_conflict,json=fetchMetadataConflict,proto3" json:"fetch_metadata_conflict,omitempty"`
+	HeaderSpoofing         bool     `protobuf:"varint,64,opt,name=header_spoofing,json=headerSpoofing,proto3" json:"header_spoofing,omitempty"`
+	SpoofingCombos         []string `protobuf:"bytes,65,rep,name=spoofing_combos,json=spoofingCombos,proto3" json:"spoofing_combos,omitempty"`
+	NonCanonicalHeaderCase bool     `protobuf:"varint,45,opt,name=non_canonical_header_case,json=nonCanonicalHeaderCase,proto3" json:"non_canonical_header_case,omitempty"`
+	ClientHintsMismatch    bool     `protobuf:"varint,48,opt,name=client_hints_mismatch,json=clientHintsMismatch,proto3" json:"client_hints_mismatch,omitempty"`
+	ClientHintsConflict    string   `protobuf:"bytes,49,opt,name=client_hints_conflict,json=clientHintsConflict,proto3" json:"client_hints_conflict,omitempty"`
+	// JA4H signals (HTTP fingerprint)
+	HasJa4HFingerprint   bool   `protobuf:"varint,15,opt,name=has_ja4h_fingerprint,json=hasJa4hFingerprint,proto3" json:"has_ja4h_fingerprint,omitempty"`
+	Ja4HLanguageCode     string `protobuf:"bytes,16,opt,name=ja4h_language_code,json=ja4hLanguageCode,proto3" json:"ja4h_language_code,omitempty"`
+	Ja4HMissingLanguage  bool   `protobuf:"varint,17,opt,name=ja4h_missing_language,json=ja4hMissingLanguage,proto3" json:"ja4h_missing_language,omitempty"`
+	Ja4HLowHeaderCount   bool   `protobuf:"varint,18,opt,name=ja4h_low_header_count,json=ja4hLowHeaderCount,proto3" json:"ja4h_low_header_count,omitempty"`
+	Ja4HHighHeaderCount  bool   `protobuf:"varint,19,opt,name=ja4h_high_header_count,json=ja4hHighHeaderCount,proto3" json:"ja4h_high_header_count,omitempty"`
+	Ja4HHasCookies       bool   `protobuf:"varint,20,opt,name=ja4h_has_cookies,json=ja4hHasCookies,proto3" json:"ja4h_has_cookies,omitempty"`
+	Ja4HHasReferer       bool   `protobuf:"varint,21,opt,name=ja4h_has_referer,json=ja4hHasReferer,proto3" json:"ja4h_has_referer,omitempty"`
+	Ja4HIsHttp2          bool   `protobuf:"varint,22,opt,name=ja4h_is_http2,json=ja4hIsHttp2,proto3" json:"ja4h_is_http2,omitempty"`
+	Ja4HConsistentSignal bool   `protobuf:"varint,23,opt,name=ja4h_consistent_signal,json=ja4hConsistentSignal,proto3" json:"ja4h_consistent_signal,omitempty"`
+	// Request method signals
+	IsCorsPreflight    bool `protobuf:"varint,36,opt,name=is_cors_preflight,json=isCorsPreflight,proto3" json:"is_cors_preflight,omitempty"`
+	MalformedPreflight bool `protobuf:"varint,37,opt,name=malformed_preflight,json=malformedPreflight,proto3" json:"malformed_preflight,omitempty"`
+	IsHeadRequest      bool `protobuf:"varint,38,opt,name=is_head_request,json=isHeadRequest,proto3" json:"is_head_request,omitempty"`
+	// Heuristic signals
+	UaIsBot              bool  `protobuf:"varint,24,opt,name=ua_is_bot,json=uaIsBot,proto3" json:"ua_is_bot,omitempty"`
+	UaIsAiCrawler        bool  `protobuf:"varint,25,opt,name=ua_is_ai_crawler,json=uaIsAiCrawler,proto3" json:"ua_is_ai_crawler,omitempty"`
+	UaIsBrowser          bool  `protobuf:"varint,26,opt,name=ua_is_browser,json=uaIsBrowser,proto3" json:"ua_is_browser,omitempty"`
+	LowHeaderCount       bool  `protobuf:"varint,27,opt,name=low_header_count,json=lowHeaderCount,proto3" json:"low_header_count,omitempty"`
+	HasBrowserHeaders    bool  `protobuf:"varint,28,opt,name=has_browser_headers,json=hasBrowserHeaders,proto3" json:"has_browser_headers,omitempty"`
+	MissingTypicalHeader bool  `protobuf:"varint,29,opt,name=missing_typical_header,json=missingTypicalHeader,proto3" json:"missing_typical_header,omitempty"`
+	OutdatedBrowser      bool  `protobuf:"varint,66,opt,name=outdated_browser,json=outdatedBrowser,proto3" json:"outdated_browser,omitempty"`
+	BrowserVersionLag    int32 `protobuf:"varint,67,opt,name=browser_version_lag,json=browserVersionLag,proto3" json:"browser_version_lag,omitempty"`
+	// User-Agent patterns that matched
+	MatchedBotPatterns       []string `protobuf:"bytes,41,rep,name=matched_bot_patterns,json=matchedBotPatterns,proto3" json:"matched_bot_patterns,omitempty"`
+	MatchedAiCrawlerPatterns []string `protobuf:"bytes,42,rep,name=matched_ai_crawler_patterns,json=matchedAiCrawlerPatterns,proto3" json:"matched_ai_crawler_patterns,omitempty"`
+	MatchedBrowserPatterns   []string `protobuf:"bytes,43,rep,name=matched_browser_patterns,json=matchedBrowserPatterns,proto3" json:"matched_browser_patterns,omitempty"`
+	// Behavioral signals (from session timing)
+	RegularTiming    bool `protobuf:"varint,30,opt,name=regular_timing,json=regularTiming,proto3" json:"regular_timing,omitempty"`
+	SubHumanInterval bool `protobuf:"varint,31,opt,name=sub_human_interval,json=subHumanInterval,proto3" json:"sub_human_interval,omitempty"`
+	HighRequestRate  bool `protobuf:"varint,74,opt,name=high_request_rate,json=highRequestRate,proto3" json:"high_request_rate,omitempty"`
+	BurstPattern     bool `protobuf:"varint,75,opt,name=burst_pattern,json=burstPattern,proto3" json:"burst_pattern,omitempty"`
+	ContentOnly      bool `protobuf:"varint,76,opt,name=content_only,json=contentOnly,proto3" json:"content_only,omitempty"`
+	HoneypotHit      bool `protobuf:"varint,77,opt,name=honeypot_hit,json=honeypotHit,proto3" json:"honeypot_hit,omitempty"`
+	RepeatOffender   bool `protobuf:"varint,79,opt,name=repeat_offender,json=repeatOffender,proto3" json:"repeat_offender,omitempty"`
+	// Network signals
+	FromPrivateRelay        bool   `protobuf:"varint,33,opt,name=from_private_relay,json=fromPrivateRelay,proto3" json:"from_private_relay,omitempty"`
+	SpoofedCrawler          bool   `protobuf:"varint,68,opt,name=spoofed_crawler,json=spoofedCrawler,proto3" json:"spoofed_crawler,omitempty"`
+	ClaimedCrawler          string `protobuf:"bytes,69,opt,name=claimed_crawler,json=claimedCrawler,proto3" json:"claimed_crawler,omitempty"`
+	DatacenterAsn           bool   `protobuf:"varint,70,opt,name=datacenter_asn,json=datacenterAsn,proto3" json:"datacenter_asn,omitempty"`
+	HostingProvider         string `protobuf:"bytes,71,opt,name=hosting_provider,json=hostingProvider,proto3" json:"hosting_provider,omitempty"`
+	AnonymizedNetwork       bool   `protobuf:"varint,72,opt,name=anonymized_network,json=anonymizedNetwork,proto3" json:"anonymized_network,omitempty"`
+	Anonymizer              string `protobuf:"bytes,73,opt,name=anonymizer,proto3" json:"anonymizer,omitempty"`
+	TlsIntercepted          bool   `protobuf:"varint,39,opt,name=tls_intercepted,json=tlsIntercepted,proto3" json:"tls_intercepted,omitempty"`
+	GeoLanguageMismatch     bool   `protobuf:"varint,44,opt,name=geo_language_mismatch,json=geoLanguageMismatch,proto3" json:"geo_language_mismatch,omitempty"`
+	TcpOsMismatch           bool   `protobuf:"varint,46,opt,name=tcp_os_mismatch,json=tcpOsMismatch,proto3" json:"tcp_os_mismatch,omitempty"`
+	ColocatedClient         bool   `protobuf:"varint,47,opt,name=colocated_client,json=colocatedClient,proto3" json:"colocated_client,omitempty"`
+	LibraryExtensionOrder   bool   `protobuf:"varint,53,opt,name=library_extension_order,json=libraryExtensionOrder,proto3" json:"library_extension_order,omitempty"`
+	ExtensionOrderStack     string `protobuf:"bytes,54,opt,name=extension_order_stack,json=extensionOrderStack,proto3" json:"extension_order_stack,omitempty"`
+	TlsStack                string `protobuf:"bytes,57,opt,name=tls_stack,json=tlsStack,proto3" json:"tls_stack,omitempty"`
+	TlsImpersonation        bool   `protobuf:"varint,58,opt,name=tls_impersonation,json=tlsImpersonation,proto3" json:"tls_impersonation,omitempty"`
+	KnownBotFingerprint     bool   `protobuf:"varint,59,opt,name=known_bot_fingerprint,json=knownBotFingerprint,proto3" json:"known_bot_fingerprint,omitempty"`
+	KnownBrowserFingerprint bool   `protobuf:"varint,60,opt,name=known_browser_fingerprint,json=knownBrowserFingerprint,proto3" json:"known_browser_fingerprint,omitempty"`
+	KnownFingerprintClient  string `protobuf:"bytes,61,opt,name=known_fingerprint_client,json=knownFingerprintClient,proto3" json:"known_fingerprint_client,omitempty"`
+	// Attestation signals
+	HasValidPrivateToken bool `protobuf:"varint,32,opt,name=has_valid_private_token,json=hasValidPrivateToken,proto3" json:"has_valid_private_token,omitempty"`
+	ChallengeTokenPassed bool `protobuf:"varint,34,opt,name=challenge_token_passed,json=challengeTokenPassed,proto3" json:"challenge_token_passed,omitempty"`
+	ChallengeTokenFailed bool `protobuf:"varint,35,opt,name=challenge_token_failed,json=challengeTokenFailed,proto3" json:"challenge_token_failed,omitempty"`
+	CaptchaSolved        bool `protobuf:"varint,78,opt,name=captcha_solved,json=captchaSolved,proto3" json:"captcha_solved,omitempty"`
+	// Computed
+	BrowserScore int32 `protobuf:"varint,100,opt,name=browser_score,json=browserScore,proto3" json:"browser_score,omitempty"`
+	BotScore     int32 `protobuf:"varint,101,opt,name=bot_score,json=botScore,proto3" json:"bot_score,omitempty"`
+	// Text form of contributions, e.g. "BROWSER[http2(+2)] BOT[bot-ua(+3)]"
+	//
+	// Deprecated: Marked as deprecated in classifier/v1/classifier.proto.
+	ScoreBreakdown string `protobuf:"bytes,102,opt,name=score_breakdown,json=scoreBreakdown,proto3" json:"score_breakdown,omitempty"`
+	// Rules that fired, browser first (score_breakdown in JSON)
+	Contributions []*SignalContribution `protobuf:"bytes,103,rep,name=contributions,proto3" json:"contributions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Signals) Reset() {
+	*x = Signals{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Signals) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Signals) ProtoMessage() {}
+
+func (x *Signals) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Signals.ProtoReflect.Descriptor instead.
+func (*Signals) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Signals) GetIsHttp2() bool {
+	if x != nil {
+		return x.IsHttp2
+	}
+	return false
+}
+
+func (x *Signals) GetIsHttp3() bool {
+	if x != nil {
+		return x.IsHttp3
+	}
+	return false
+}
+
+func (x *Signals) GetHasModernTls() bool {
+	if x != nil {
+		return x.HasModernTls
+	}
+	return false
+}
+
+func (x *Signals) GetHasAlpn() bool {
+	if x != nil {
+		return x.HasAlpn
+	}
+	return false
+}
+
+func (x *Signals) GetHighCipherCount() bool {
+	if x != nil {
+		return x.HighCipherCount
+	}
+	return false
+}
+
+func (x *Signals) GetHasSessionSupport() bool {
+	if x != nil {
+		return x.HasSessionSupport
+	}
+	return false
+}
+
+func (x *Signals) GetHasTlsFingerprint() bool {
+	if x != nil {
+		return x.HasTlsFingerprint
+	}
+	return false
+}
+
+func (x *Signals) GetHasMultipleGroups() bool {
+	if x != nil {
+		return x.HasMultipleGroups
+	}
+	return false
+}
+
+func (x *Signals) GetHasModernCiphers() bool {
+	if x != nil {
+		return x.HasModernCiphers
+	}
+	return false
+}
+
+func (x *Signals) GetHasGreaseCiphers() bool {
+	if x != nil {
+		return x.HasGreaseCiphers
+	}
+	return false
+}
+
+func (x *Signals) GetHasGreaseExtensions() bool {
+	if x != nil {
+		return x.HasGreaseExtensions
+	}
+	return false
+}
+
+func (x *Signals) GetHasGreaseGroups() bool {
+	if x != nil {
+		return x.HasGreaseGroups
+	}
+	return false
+}
+
+func (x *Signals) GetHasEch() bool {
+	if x != nil {
+		return x.HasEch
+	}
+	return false
+}
+
+func (x *Signals) GetHasSecFetchHeaders() bool {
+	if x != nil {
+		return x.HasSecFetchHeaders
+	}
+	return false
+}
+
+func (x *Signals) GetHasAcceptLanguage() bool {
+	if x != nil {
+		return x.HasAcceptLanguage
+	}
+	return false
+}
+
+func (x *Signals) GetHasUserAgent() bool {
+	if x != nil {
+		return x.HasUserAgent
+	}
+	return false
+}
+
+func (x *Signals) GetHasAccept() bool {
+	if x != nil {
+		return x.HasAccept
+	}
+	return false
+}
+
+func (x *Signals) GetHasAcceptEncoding() bool {
+	if x != nil {
+		return x.HasAcceptEncoding
+	}
+	return false
+}
+
+func (x *Signals) GetHasSecChUa() bool {
+	if x != nil {
+		return x.HasSecChUa
+	}
+	return false
+}
+
+func (x *Signals) GetSpoofedReferer() bool {
+	if x != nil {
+		return x.SpoofedReferer
+	}
+	return false
+}
+
+func (x *Signals) GetFetchMetadataMismatch() bool {
+	if x != nil {
+		return x.FetchMetadataMismatch
+	}
+	return false
+}
+
+func (x *Signals) GetFetchMetadataConflict() string {
+	if x != nil {
+		return x.FetchMetadataConflict
+	}
+	return ""
+}
+
+func (x *Signals) GetHeaderSpoofing() bool {
+	if x != nil {
+		return x.HeaderSpoofing
+	}
+	return false
+}
+
+func (x *Signals) GetSpoofingCombos() []string {
+	if x != nil {
+		return x.SpoofingCombos
+	}
+	return nil
+}
+
+func (x *Signals) GetNonCanonicalHeaderCase() bool {
+	if x != nil {
+		return x.NonCanonicalHeaderCase
+	}
+	return false
+}
+
+func (x *Signals) GetClientHintsMismatch() bool {
+	if x != nil {
+		return x.ClientHintsMismatch
+	}
+	return false
+}
+
+func (x *Signals) GetClientHintsConflict() string {
+	if x != nil {
+		return x.ClientHintsConflict
+	}
+	return ""
+}
+
+func (x *Signals) GetHasJa4HFingerprint() bool {
+	if x != nil {
+		return x.HasJa4HFingerprint
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HLanguageCode() string {
+	if x != nil {
+		return x.Ja4HLanguageCode
+	}
+	return ""
+}
+
+func (x *Signals) GetJa4HMissingLanguage() bool {
+	if x != nil {
+		return x.Ja4HMissingLanguage
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HLowHeaderCount() bool {
+	if x != nil {
+		return x.Ja4HLowHeaderCount
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HHighHeaderCount() bool {
+	if x != nil {
+		return x.Ja4HHighHeaderCount
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HHasCookies() bool {
+	if x != nil {
+		return x.Ja4HHasCookies
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HHasReferer() bool {
+	if x != nil {
+		return x.Ja4HHasReferer
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HIsHttp2() bool {
+	if x != nil {
+		return x.Ja4HIsHttp2
+	}
+	return false
+}
+
+func (x *Signals) GetJa4HConsistentSignal() bool {
+	if x != nil {
+		return x.Ja4HConsistentSignal
+	}
+	return false
+}
+
+func (x *Signals) GetIsCorsPreflight() bool {
+	if x != nil {
+		return x.IsCorsPreflight
+	}
+	return false
+}
+
+func (x *Signals) GetMalformedPreflight() bool {
+	if x != nil {
+		return x.MalformedPreflight
+	}
+	return false
+}
+
+func (x *Signals) GetIsHeadRequest() bool {
+	if x != nil {
+		return x.IsHeadRequest
+	}
+	return false
+}
+
+func (x *Signals) GetUaIsBot() bool {
+	if x != nil {
+		return x.UaIsBot
+	}
+	return false
+}
+
+func (x *Signals) GetUaIsAiCrawler() bool {
+	if x != nil {
+		return x.UaIsAiCrawler
+	}
+	return false
+}
+
+func (x *Signals) GetUaIsBrowser() bool {
+	if x != nil {
+		return x.UaIsBrowser
+	}
+	return false
+}
+
+func (x *Signals) GetLowHeaderCount() bool {
+	if x != nil {
+		return x.LowHeaderCount
+	}
+	return false
+}
+
+func (x *Signals) GetHasBrowserHeaders() bool {
+	if x != nil {
+		return x.HasBrowserHeaders
+	}
+	return false
+}
+
+func (x *Signals) GetMissingTypicalHeader() bool {
+	if x != nil {
+		return x.MissingTypicalHeader
+	}
+	return false
+}
+
+func (x *Signals) GetOutdatedBrowser() bool {
+	if x != nil {
+		return x.OutdatedBrowser
+	}
+	return false
+}
+
+func (x *Signals) GetBrowserVersionLag() int32 {
+	if x != nil {
+		return x.BrowserVersionLag
+	}
+	return 0
+}
+
+func (x *Signals) GetMatchedBotPatterns() []string {
+	if x != nil {
+		return x.MatchedBotPatterns
+	}
+	return nil
+}
+
+func (x *Signals) GetMatchedAiCrawlerPatterns() []string {
+	if x != nil {
+		return x.MatchedAiCrawlerPatterns
+	}
+	return nil
+}
+
+func (x *Signals) GetMatchedBrowserPatterns() []string {
+	if x != nil {
+		return x.MatchedBrowserPatterns
+	}
+	return nil
+}
+
+func (x *Signals) GetRegularTiming() bool {
+	if x != nil {
+		return x.RegularTiming
+	}
+	return false
+}
+
+func (x *Signals) GetSubHumanInterval() bool {
+	if x != nil {
+		return x.SubHumanInterval
+	}
+	return false
+}
+
+func (x *Signals) GetHighRequestRate() bool {
+	if x != nil {
+		return x.HighRequestRate
+	}
+	return false
+}
+
+func (x *Signals) GetBurstPattern() bool {
+	if x != nil {
+		return x.BurstPattern
+	}
+	return false
+}
+
+func (x *Signals) GetContentOnly() bool {
+	if x != nil {
+		return x.ContentOnly
+	}
+	return false
+}
+
+func (x *Signals) GetHoneypotHit() bool {
+	if x != nil {
+		return x.HoneypotHit
+	}
+	return false
+}
+
+func (x *Signals) GetRepeatOffender() bool {
+	if x != nil {
+		return x.RepeatOffender
+	}
+	return false
+}
+
+func (x *Signals) GetFromPrivateRelay() bool {
+	if x != nil {
+		return x.FromPrivateRelay
+	}
+	return false
+}
+
+func (x *Signals) GetSpoofedCrawler() bool {
+	if x != nil {
+		return x.SpoofedCrawler
+	}
+	return false
+}
+
+func (x *Signals) GetClaimedCrawler() string {
+	if x != nil {
+		return x.ClaimedCrawler
+	}
+	return ""
+}
+
+func (x *Signals) GetDatacenterAsn() bool {
+	if x != nil {
+		return x.DatacenterAsn
+	}
+	return false
+}
+
+func (x *Signals) GetHostingProvider() string {
+	if x != nil {
+		return x.HostingProvider
+	}
+	return ""
+}
+
+func (x *Signals) GetAnonymizedNetwork() bool {
+	if x != nil {
+		return x.AnonymizedNetwork
+	}
+	return false
+}
+
+func (x *Signals) GetAnonymizer() string {
+	if x != nil {
+		return x.Anonymizer
+	}
+	return ""
+}
+
+func (x *Signals) GetTlsIntercepted() bool {
+	if x != nil {
+		return x.TlsIntercepted
+	}
+	return false
+}
+
+func (x *Signals) GetGeoLanguageMismatch() bool {
+	if x != nil {
+		return x.GeoLanguageMismatch
+	}
+	return false
+}
+
+func (x *Signals) GetTcpOsMismatch() bool {
+	if x != nil {
+		return x.TcpOsMismatch
+	}
+	return false
+}
+
+func (x *Signals) GetColocatedClient() bool {
+	if x != nil {
+		return x.ColocatedClient
+	}
+	return false
+}
+
+func (x *Signals) GetLibraryExtensionOrder() bool {
+	if x != nil {
+		return x.LibraryExtensionOrder
+	}
+	return false
+}
+
+func (x *Signals) GetExtensionOrderStack() string {
+	if x != nil {
+		return x.ExtensionOrderStack
+	}
+	return ""
+}
+
+func (x *Signals) GetTlsStack() string {
+	if x != nil {
+		return x.TlsStack
+	}
+	return ""
+}
+
+func (x *Signals) GetTlsImpersonation() bool {
+	if x != nil {
+		return x.TlsImpersonation
+	}
+	return false
+}
+
+func (x *Signals) GetKnownBotFingerprint() bool {
+	if x != nil {
+		return x.KnownBotFingerprint
+	}
+	return false
+}
+
+func (x *Signals) GetKnownBrowserFingerprint() bool {
+	if x != nil {
+		return x.KnownBrowserFingerprint
+	}
+	return false
+}
+
+func (x *Signals) GetKnownFingerprintClient() string {
+	if x != nil {
+		return x.KnownFingerprintClient
+	}
+	return ""
+}
+
+func (x *Signals) GetHasValidPrivateToken() bool {
+	if x != nil {
+		return x.HasValidPrivateToken
+	}
+	return false
+}
+
+func (x *Signals) GetChallengeTokenPassed() bool {
+	if x != nil {
+		return x.ChallengeTokenPassed
+	}
+	return false
+}
+
+func (x *Signals) GetChallengeTokenFailed() bool {
+	if x != nil {
+		return x.ChallengeTokenFailed
+	}
+	return false
+}
+
+func (x *Signals) GetCaptchaSolved() bool {
+	if x != nil {
+		return x.CaptchaSolved
+	}
+	return false
+}
+
+func (x *Signals) GetBrowserScore() int32 {
+	if x != nil {
+		return x.BrowserScore
+	}
+	return 0
+}
+
+func (x *Signals) GetBotScore() int32 {
+	if x != nil {
+		return x.BotScore
+	}
+	return 0
+}
+
+// Deprecated: Marked as deprecated in classifier/v1/classifier.proto.
+func (x *Signals) GetScoreBreakdown() string {
+	if x != nil {
+		return x.ScoreBreakdown
+	}
+	return ""
+}
+
+func (x *Signals) GetContributions() []*SignalContribution {
+	if x != nil {
+		return x.Contributions
+	}
+	return nil
+}
+
+// SignalContribution is a scoring rule that fired for a request
+type SignalContribution struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Direction     string                 `protobuf:"bytes,2,opt,name=direction,proto3" json:"direction,omitempty"` // "browser" or "bot"
+	Weight        int32                  `protobuf:"varint,3,opt,name=weight,proto3" json:"weight,omitempty"`      // Points in effect when the request was scored
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SignalContribution) Reset() {
+	*x = SignalContribution{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SignalContribution) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SignalContribution) ProtoMessage() {}
+
+func (x *SignalContribution) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SignalContribution.ProtoReflect.Descriptor instead.
+func (*SignalContribution) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SignalContribution) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *SignalContribution) GetDirection() string {
+	if x != nil {
+		return x.Direction
+	}
+	return ""
+}
+
+func (x *SignalContribution) GetWeight() int32 {
+	if x != nil {
+		return x.Weight
+	}
+	return 0
+}
+
+// ClassificationResult contains the final classification
+type ClassificationResult struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	RequestId       string                 `protobuf:"bytes,1,opt,name=request_id,json=requestId,proto3" json:"request_id,omitempty"`
+	Timestamp       *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Classification  string                 `protobuf:"bytes,3,opt,name=classification,proto3" json:"classification,omitempty"` // "browser" or "bot"
+	Confidence      float64                `protobuf:"fixed64,4,opt,name=confidence,proto3" json:"confidence,omitempty"`       // 0.0 to 1.0
+	Fingerprint     *Fingerprint           `protobuf:"bytes,5,opt,name=fingerprint,proto3" json:"fingerprint,omitempty"`
+	Signals         *Signals               `protobuf:"bytes,6,opt,name=signals,proto3" json:"signals,omitempty"`
+	Score           int32                  `protobuf:"varint,7,opt,name=score,proto3" json:"score,omitempty"` // Net score (positive = browser, negative = bot)
+	Reason          string                 `protobuf:"bytes,8,opt,name=reason,proto3" json:"reason,omitempty"`
+	Partial         bool                   `protobuf:"varint,9,opt,name=partial,proto3" json:"partial,omitempty"`                                                                                                                    // Some enrichment lookups did not complete
+	Incomplete      []string               `protobuf:"bytes,10,rep,name=incomplete,proto3" json:"incomplete,omitempty"`                                                                                                              // Enrichers that failed or timed out
+	Margin          int32                  `protobuf:"varint,11,opt,name=margin,proto3" json:"margin,omitempty"`                                                                                                                     // Net score minus the threshold (>= 0 browser, < 0 bot)
+	FlipSet         []string               `protobuf:"bytes,12,rep,name=flip_set,json=flipSet,proto3" json:"flip_set,omitempty"`                                                                                                     // Fewest fired rules whose removal flips the decision
+	Class           string                 `protobuf:"bytes,13,opt,name=class,proto3" json:"class,omitempty"`                                                                                                                        // browser, ai_crawler, search_bot, headless_browser, http_library or unknown
+	ClassConfidence map[string]float64     `protobuf:"bytes,14,rep,name=class_confidence,json=classConfidence,proto3" json:"class_confidence,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"fixed64,2,opt,name=value"` // Confidence of every class, summing to 1
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ClassificationResult) Reset() {
+	*x = ClassificationResult{}
+	mi := &file_classifier_v1_classifier_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassificationResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassificationResult) ProtoMessage() {}
+
+func (x *ClassificationResult) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_classifier_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassificationResult.ProtoReflect.Descriptor instead.
+func (*ClassificationResult) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_classifier_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ClassificationResult) GetRequestId() string {
+	if x != nil {
+		return x.RequestId
+	}
+	return ""
+}
+
+func (x *ClassificationResult) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *ClassificationResult) GetClassification() string {
+	if x != nil {
+		return x.Classification
+	}
+	return ""
+}
+
+func (x *ClassificationResult) GetConfidence() float64 {
+	if x != nil {
+		return x.Confidence
+	}
+	return 0
+}
+
+func (x *ClassificationResult) GetFingerprint() *Fingerprint {
+	if x != nil {
+		return x.Fingerprint
+	}
+	return nil
+}
+
+func (x *ClassificationResult) GetSignals() *Signals {
+	if x != nil {
+		return x.Signals
+	}
+	return nil
+}
+
+func (x *ClassificationResult) GetScore() int32 {
+	if x != nil {
+		return x.Score
+	}
+	return 0
+}
+
+func (x *ClassificationResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *ClassificationResult) GetPartial() bool {
+	if x != nil {
+		return x.Partial
+	}
+	return false
+}
+
+func (x *ClassificationResult) GetIncomplete() []string {
+	if x != nil {
+		return x.Incomplete
+	}
+	return nil
+}
+
+func (x *ClassificationResult) GetMargin() int32 {
+	if x != nil {
+		return x.Margin
+	}
+	return 0
+}
+
+func (x *ClassificationResult) GetFlipSet() []string {
+	if x != nil {
+		return x.FlipSet
+	}
+	return nil
+}
+
+func (x *ClassificationResult) GetClass() string {
+	if x != nil {
+		return x.Class
+	}
+	return ""
+}
+
+func (x *ClassificationResult) GetClassConfidence() map[string]float64 {
+	if x != nil {
+		return x.ClassConfidence
+	}
+	return nil
+}
+
+var File_classifier_v1_classifier_proto protoreflect.FileDescriptor
+
+const file_classifier_v1_classifier_proto_rawDesc = "" +
+	"\n" +
+	"\x1eclassifier/v1/classifier.proto\x12\rclassifier.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"\xc5\x03\n" +
+	"\vFingerprint\x12/\n" +
+	"\x03tls\x18\x01 \x01(\v2\x1d.classifier.v1.TLSFingerprintR\x03tls\x122\n" +
+	"\x04http\x18\x02 \x01(\v2\x1e.classifier.v1.HTTPFingerprintR\x04http\x12;\n" +
+	"\asession\x18\x03 \x01(\v2!.classifier.v1.SessionFingerprintR\asession\x12;\n" +
+	"\anetwork\x18\x04 \x01(\v2!.classifier.v1.NetworkFingerprintR\anetwork\x12/\n" +
+	"\x03tcp\x18\x05 \x01(\v2\x1d.classifier.v1.TCPFingerprintR\x03tcp\x122\n" +
+	"\x04quic\x18\x06 \x01(\v2\x1e.classifier.v1.QUICFingerprintR\x04quic\x122\n" +
+	"\x04rate\x18\a \x01(\v2\x1e.classifier.v1.RateFingerprintR\x04rate\x12>\n" +
+	"\boffender\x18\b \x01(\v2\".classifier.v1.OffenderFingerprintR\boffender\"\x89\a\n" +
+	"\x0eTLSFingerprint\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12!\n" +
+	"\fcipher_suite\x18\x02 \x01(\tR\vcipherSuite\x12\x12\n" +
+	"\x04alpn\x18\x03 \x01(\tR\x04alpn\x12\x1f\n" +
+	"\vserver_name\x18\x04 \x01(\tR\n" +
+	"serverName\x12.\n" +
+	"\x13cipher_suites_count\x18\x05 \x01(\x05R\x11cipherSuitesCount\x12)\n" +
+	"\x10extensions_count\x18\x06 \x01(\x05R\x0fextensionsCount\x12-\n" +
+	"\x12supported_versions\x18\a \x03(\tR\x11supportedVersions\x12+\n" +
+	"\x11signature_schemes\x18\b \x03(\tR\x10signatureSchemes\x12)\n" +
+	"\x10supported_groups\x18\t \x03(\tR\x0fsupportedGroups\x12,\n" +
+	"\x12has_session_ticket\x18\n" +
+	" \x01(\bR\x10hasSessionTicket\x12$\n" +
+	"\x0ehas_early_data\x18\v \x01(\bR\fhasEarlyData\x12\x19\n" +
+	"\bja3_hash\x18\f \x01(\tR\aja3Hash\x12\x19\n" +
+	"\bja4_hash\x18\r \x01(\tR\aja4Hash\x12/\n" +
+	"\x13certificate_request\x18\x0e \x01(\bR\x12certificateRequest\x12\x1c\n" +
+	"\tavailable\x18\x0f \x01(\bR\tavailable\x12\x1b\n" +
+	"\tno_grease\x18\x10 \x01(\bR\bnoGrease\x12%\n" +
+	"\x0elegacy_ciphers\x18\x11 \x01(\bR\rlegacyCiphers\x12\x13\n" +
+	"\x05ja4_r\x18\x12 \x01(\tR\x04ja4R\x12\x1b\n" +
+	"\tja3s_hash\x18\x13 \x01(\tR\bja3sHash\x12\x1b\n" +
+	"\tja4s_hash\x18\x14 \x01(\tR\bja4sHash\x12\x1d\n" +
+	"\n" +
+	"latency_us\x18\x15 \x01(\x05R\tlatencyUs\x12\x12\n" +
+	"\x04ja4l\x18\x16 \x01(\tR\x04ja4l\x12%\n" +
+	"\x0egrease_ciphers\x18\x17 \x01(\bR\rgreaseCiphers\x12+\n" +
+	"\x11grease_extensions\x18\x18 \x01(\bR\x10greaseExtensions\x12\x1e\n" +
+	"\n" +
+	"extensions\x18\x19 \x03(\rR\n" +
+	"extensions\x12\x10\n" +
+	"\x03ech\x18\x1a \x01(\bR\x03ech\"\xab\b\n" +
+	"\x0fHTTPFingerprint\x12\x18\n" +
+	"\aversion\x18\x01 \x01(\tR\aversion\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x12\n" +
+	"\x04path\x18\x03 \x01(\tR\x04path\x12E\n" +
+	"\aheaders\x18\x04 \x03(\v2+.classifier.v1.HTTPFingerprint.HeadersEntryR\aheaders\x12!\n" +
+	"\fheader_order\x18\x05 \x03(\tR\vheaderOrder\x12!\n" +
+	"\fheader_count\x18\x06 \x01(\x05R\vheaderCount\x12\x1d\n" +
+	"\n" +
+	"user_agent\x18\a \x01(\tR\tuserAgent\x12\x16\n" +
+	"\x06accept\x18\b \x01(\tR\x06accept\x12\x1f\n" +
+	"\vaccept_lang\x18\t \x01(\tR\n" +
+	"acceptLang\x12\x1d\n" +
+	"\n" +
+	"accept_enc\x18\n" +
+	" \x01(\tR\tacceptEnc\x12\x1e\n" +
+	"\n" +
+	"connection\x18\v \x01(\tR\n" +
+	"connection\x12$\n" +
+	"\x0esec_fetch_site\x18\f \x01(\tR\fsecFetchSite\x12$\n" +
+	"\x0esec_fetch_mode\x18\r \x01(\tR\fsecFetchMode\x12$\n" +
+	"\x0esec_fetch_dest\x18\x0e \x01(\tR\fsecFetchDest\x12$\n" +
+	"\x0esec_fetch_user\x18\x0f \x01(\tR\fsecFetchUser\x12\x1a\n" +
+	"\tsec_ch_ua\x18\x10 \x01(\tR\asecChUa\x12\x1f\n" +
+	"\vhas_cookies\x18\x11 \x01(\bR\n" +
+	"hasCookies\x12\x1f\n" +
+	"\vhas_referer\x18\x12 \x01(\bR\n" +
+	"hasReferer\x12!\n" +
+	"\fcontent_type\x18\x13 \x01(\tR\vcontentType\x12%\n" +
+	"\x0econtent_length\x18\x14 \x01(\x03R\rcontentLength\x12\x1b\n" +
+	"\tja4h_hash\x18\x15 \x01(\tR\bja4hHash\x12#\n" +
+	"\rprivate_token\x18\x16 \x01(\tR\fprivateToken\x12'\n" +
+	"\x0fchallenge_token\x18\x17 \x01(\tR\x0echallengeToken\x12\x12\n" +
+	"\x04host\x18\x18 \x01(\tR\x04host\x12(\n" +
+	"\x10raw_header_names\x18\x19 \x03(\tR\x0erawHeaderNames\x12=\n" +
+	"\fclient_hints\x18\x1a \x01(\v2\x1a.classifier.v1.ClientHintsR\vclientHints\x12J\n" +
+	"\x11user_agent_parsed\x18\x1b \x01(\v2\x1e.classifier.v1.ParsedUserAgentR\x0fuserAgentParsed\x1a:\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x86\x01\n" +
+	"\x0fParsedUserAgent\x12\x16\n" +
+	"\x06family\x18\x01 \x01(\tR\x06family\x12\x14\n" +
+	"\x05major\x18\x02 \x01(\x05R\x05major\x12\x0e\n" +
+	"\x02os\x18\x03 \x01(\tR\x02os\x12\x1d\n" +
+	"\n" +
+	"os_version\x18\x04 \x01(\tR\tosVersion\x12\x16\n" +
+	"\x06device\x18\x05 \x01(\tR\x06device\"\x94\x02\n" +
+	"\vClientHints\x123\n" +
+	"\x06brands\x18\x01 \x03(\v2\x1b.classifier.v1.BrandVersionR\x06brands\x12G\n" +
+	"\x11full_version_list\x18\x02 \x03(\v2\x1b.classifier.v1.BrandVersionR\x0ffullVersionList\x12\x1a\n" +
+	"\bplatform\x18\x03 \x01(\tR\bplatform\x12)\n" +
+	"\x10platform_version\x18\x04 \x01(\tR\x0fplatformVersion\x12\x16\n" +
+	"\x06mobile\x18\x05 \x01(\bR\x06mobile\x12\x14\n" +
+	"\x05model\x18\x06 \x01(\tR\x05model\x12\x12\n" +
+	"\x04arch\x18\a \x01(\tR\x04arch\">\n" +
+	"\fBrandVersion\x12\x14\n" +
+	"\x05brand\x18\x01 \x01(\tR\x05brand\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\"\x9a\x03\n" +
+	"\x12SessionFingerprint\x12#\n" +
+	"\rrequest_count\x18\x01 \x01(\x05R\frequestCount\x12%\n" +
+	"\x0einterval_count\x18\x02 \x01(\x05R\rintervalCount\x12(\n" +
+	"\x10mean_interval_ms\x18\x03 \x01(\x01R\x0emeanIntervalMs\x12&\n" +
+	"\x0fmin_interval_ms\x18\x04 \x01(\x01R\rminIntervalMs\x12,\n" +
+	"\x12interval_stddev_ms\x18\x05 \x01(\x01R\x10intervalStddevMs\x12'\n" +
+	"\x0finterval_jitter\x18\x06 \x01(\x01R\x0eintervalJitter\x12\x1c\n" +
+	"\tavailable\x18\a \x01(\bR\tavailable\x12#\n" +
+	"\rpage_requests\x18\b \x01(\x05R\fpageRequests\x12%\n" +
+	"\x0easset_requests\x18\t \x01(\x05R\rassetRequests\x12%\n" +
+	"\x0ehuman_verified\x18\n" +
+	" \x01(\bR\rhumanVerified\"\xb3\x01\n" +
+	"\x0fRateFingerprint\x12#\n" +
+	"\raddr_requests\x18\x01 \x01(\x05R\faddrRequests\x12\x1d\n" +
+	"\n" +
+	"addr_burst\x18\x02 \x01(\x05R\taddrBurst\x12!\n" +
+	"\fja4_requests\x18\x03 \x01(\x05R\vja4Requests\x12\x1b\n" +
+	"\tja4_burst\x18\x04 \x01(\x05R\bja4Burst\x12\x1c\n" +
+	"\tavailable\x18\x05 \x01(\bR\tavailable\"\x95\x01\n" +
+	"\x13OffenderFingerprint\x12\x18\n" +
+	"\atrapped\x18\x01 \x01(\bR\atrapped\x12\x1b\n" +
+	"\ttrap_path\x18\x02 \x01(\tR\btrapPath\x12\x1e\n" +
+	"\n" +
+	"reputation\x18\x03 \x01(\x01R\n" +
+	"reputation\x12'\n" +
+	"\x0frepeat_offender\x18\x04 \x01(\bR\x0erepeatOffender\"\xcd\x02\n" +
+	"\x12NetworkFingerprint\x12#\n" +
+	"\rprivate_relay\x18\x01 \x01(\bR\fprivateRelay\x12#\n" +
+	"\rrelay_country\x18\x02 \x01(\tR\frelayCountry\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12\x14\n" +
+	"\x05local\x18\x04 \x01(\bR\x05local\x12'\n" +
+	"\x0fclaimed_crawler\x18\x05 \x01(\tR\x0eclaimedCrawler\x121\n" +
+	"\x14crawler_verification\x18\x06 \x01(\tR\x13crawlerVerification\x12\x10\n" +
+	"\x03asn\x18\a \x01(\rR\x03asn\x12\x15\n" +
+	"\x06as_org\x18\b \x01(\tR\x05asOrg\x12\x18\n" +
+	"\ahosting\x18\t \x01(\tR\ahosting\x12\x1e\n" +
+	"\n" +
+	"anonymizer\x18\n" +
+	" \x01(\tR\n" +
+	"anonymizer\"\xc4\x01\n" +
+	"\x0eTCPFingerprint\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\x12\x1f\n" +
+	"\vwindow_size\x18\x02 \x01(\x05R\n" +
+	"windowSize\x12\x18\n" +
+	"\aoptions\x18\x03 \x03(\rR\aoptions\x12\x10\n" +
+	"\x03mss\x18\x04 \x01(\x05R\x03mss\x12!\n" +
+	"\fwindow_scale\x18\x05 \x01(\x05R\vwindowScale\x12\x10\n" +
+	"\x03ttl\x18\x06 \x01(\x05R\x03ttl\x12\x12\n" +
+	"\x04ja4t\x18\a \x01(\tR\x04ja4t\"\xf1\x02\n" +
+	"\x0fQUICFingerprint\x12\x1c\n" +
+	"\tavailable\x18\x01 \x01(\bR\tavailable\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\tR\aversion\x121\n" +
+	"\x14transport_parameters\x18\x03 \x03(\x04R\x13transportParameters\x12(\n" +
+	"\x10max_idle_timeout\x18\x04 \x01(\x03R\x0emaxIdleTimeout\x12/\n" +
+	"\x14max_udp_payload_size\x18\x05 \x01(\x03R\x11maxUdpPayloadSize\x12(\n" +
+	"\x10initial_max_data\x18\x06 \x01(\x03R\x0einitialMaxData\x127\n" +
+	"\x18initial_max_streams_bidi\x18\a \x01(\x03R\x15initialMaxStreamsBidi\x125\n" +
+	"\x17initial_max_streams_uni\x18\b \x01(\x03R\x14initialMaxStreamsUni\"\x90\x1d\n" +
+	"\aSignals\x12\x19\n" +
+	"\bis_http2\x18\x01 \x01(\bR\aisHttp2\x12\x19\n" +
+	"\bis_http3\x187 \x01(\bR\aisHttp3\x12$\n" +
+	"\x0ehas_modern_tls\x18\x02 \x01(\bR\fhasModernTls\x12\x19\n" +
+	"\bhas_alpn\x18\x03 \x01(\bR\ahasAlpn\x12*\n" +
+	"\x11high_cipher_count\x18\x04 \x01(\bR\x0fhighCipherCount\x12.\n" +
+	"\x13has_session_support\x18\x05 \x01(\bR\x11hasSessionSupport\x12.\n" +
+	"\x13has_tls_fingerprint\x18\x06 \x01(\bR\x11hasTlsFingerprint\x12.\n" +
+	"\x13has_multiple_groups\x18\a \x01(\bR\x11hasMultipleGroups\x12,\n" +
+	"\x12has_modern_ciphers\x18\b \x01(\bR\x10hasModernCiphers\x12,\n" +
+	"\x12has_grease_ciphers\x182 \x01(\bR\x10hasGreaseCiphers\x122\n" +
+	"\x15has_grease_extensions\x183 \x01(\bR\x13hasGreaseExtensions\x12*\n" +
+	"\x11has_grease_groups\x184 \x01(\bR\x0fhasGreaseGroups\x12\x17\n" +
+	"\ahas_ech\x188 \x01(\bR\x06hasEch\x121\n" +
+	"\x15has_sec_fetch_headers\x18\t \x01(\bR\x12hasSecFetchHeaders\x12.\n" +
+	"\x13has_accept_language\x18\n" +
+	" \x01(\bR\x11hasAcceptLanguage\x12$\n" +
+	"\x0ehas_user_agent\x18\v \x01(\bR\fhasUserAgent\x12\x1d\n" +
+	"\n" +
+	"has_accept\x18\f \x01(\bR\thasAccept\x12.\n" +
+	"\x13has_accept_encoding\x18\r \x01(\bR\x11hasAcceptEncoding\x12!\n" +
+	"\rhas_sec_ch_ua\x18\x0e \x01(\bR\n" +
+	"hasSecChUa\x12'\n" +
+	"\x0fspoofed_referer\x18( \x01(\bR\x0espoofedReferer\x126\n" +
+	"\x17fetch_metadata_mismatch\x18> \x01(\bR\x15fetchMetadataMismatch\x126\n" +
+	"\x17fetch_metadata_conflict\x18? \x01(\tR\x15fetchMetadataConflict\x12'\n" +
+	"\x0fheader_spoofing\x18@ \x01(\bR\x0eheaderSpoofing\x12'\n" +
+	"\x0fspoofing_combos\x18A \x03(\tR\x0espoofingCombos\x129\n" +
+	"\x19non_canonical_header_case\x18- \x01(\bR\x16nonCanonicalHeaderCase\x122\n" +
+	"\x15client_hints_mismatch\x180 \x01(\bR\x13clientHintsMismatch\x122\n" +
+	"\x15client_hints_conflict\x181 \x01(\tR\x13clientHintsConflict\x120\n" +
+	"\x14has_ja4h_fingerprint\x18\x0f \x01(\bR\x12hasJa4hFingerprint\x12,\n" +
+	"\x12ja4h_language_code\x18\x10 \x01(\tR\x10ja4hLanguageCode\x122\n" +
+	"\x15ja4h_missing_language\x18\x11 \x01(\bR\x13ja4hMissingLanguage\x121\n" +
+	"\x15ja4h_low_header_count\x18\x12 \x01(\bR\x12ja4hLowHeaderCount\x123\n" +
+	"\x16ja4h_high_header_count\x18\x13 \x01(\bR\x13ja4hHighHeaderCount\x12(\n" +
+	"\x10ja4h_has_cookies\x18\x14 \x01(\bR\x0eja4hHasCookies\x12(\n" +
+	"\x10ja4h_has_referer\x18\x15 \x01(\bR\x0eja4hHasReferer\x12\"\n" +
+	"\rja4h_is_http2\x18\x16 \x01(\bR\vja4hIsHttp2\x124\n" +
+	"\x16ja4h_consistent_signal\x18\x17 \x01(\bR\x14ja4hConsistentSignal\x12*\n" +
+	"\x11is_cors_preflight\x18$ \x01(\bR\x0fisCorsPreflight\x12/\n" +
+	"\x13malformed_preflight\x18% \x01(\bR\x12malformedPreflight\x12&\n" +
+	"\x0fis_head_request\x18& \x01(\bR\risHeadRequest\x12\x1a\n" +
+	"\tua_is_bot\x18\x18 \x01(\bR\auaIsBot\x12'\n" +
+	"\x10ua_is_ai_crawler\x18\x19 \x01(\bR\ruaIsAiCrawler\x12\"\n" +
+	"\rua_is_browser\x18\x1a \x01(\bR\vuaIsBrowser\x12(\n" +
+	"\x10low_header_count\x18\x1b \x01(\bR\x0elowHeaderCount\x12.\n" +
+	"\x13has_browser_headers\x18\x1c \x01(\bR\x11hasBrowserHeaders\x124\n" +
+	"\x16missing_typical_header\x18\x1d \x01(\bR\x14missingTypicalHeader\x12)\n" +
+	"\x10outdated_browser\x18B \x01(\bR\x0foutdatedBrowser\x12.\n" +
+	"\x13browser_version_lag\x18C \x01(\x05R\x11browserVersionLag\x120\n" +
+	"\x14matched_bot_patterns\x18) \x03(\tR\x12matchedBotPatterns\x12=\n" +
+	"\x1bmatched_ai_crawler_patterns\x18* \x03(\tR\x18matchedAiCrawlerPatterns\x128\n" +
+	"\x18matched_browser_patterns\x18+ \x03(\tR\x16matchedBrowserPatterns\x12%\n" +
+	"\x0eregular_timing\x18\x1e \x01(\bR\rregularTiming\x12,\n" +
+	"\x12sub_human_interval\x18\x1f \x01(\bR\x10subHumanInterval\x12*\n" +
+	"\x11high_request_rate\x18J \x01(\bR\x0fhighRequestRate\x12#\n" +
+	"\rburst_pattern\x18K \x01(\bR\fburstPattern\x12!\n" +
+	"\fcontent_only\x18L \x01(\bR\vcontentOnly\x12!\n" +
+	"\fhoneypot_hit\x18M \x01(\bR\vhoneypotHit\x12'\n" +
+	"\x0frepeat_offender\x18O \x01(\bR\x0erepeatOffender\x12,\n" +
+	"\x12from_private_relay\x18! \x01(\bR\x10fromPrivateRelay\x12'\n" +
+	"\x0fspoofed_crawler\x18D \x01(\bR\x0espoofedCrawler\x12'\n" +
+	"\x0fclaimed_crawler\x18E \x01(\tR\x0eclaimedCrawler\x12%\n" +
+	"\x0edatacenter_asn\x18F \x01(\bR\rdatacenterAsn\x12)\n" +
+	"\x10hosting_provider\x18G \x01(\tR\x0fhostingProvider\x12-\n" +
+	"\x12anonymized_network\x18H \x01(\bR\x11anonymizedNetwork\x12\x1e\n" +
+	"\n" +
+	"anonymizer\x18I \x01(\tR\n" +
+	"anonymizer\x12'\n" +
+	"\x0ftls_intercepted\x18' \x01(\bR\x0etlsIntercepted\x122\n" +
+	"\x15geo_language_mismatch\x18, \x01(\bR\x13geoLanguageMismatch\x12&\n" +
+	"\x0ftcp_os_mismatch\x18. \x01(\bR\rtcpOsMismatch\x12)\n" +
+	"\x10colocated_client\x18/ \x01(\bR\x0fcolocatedClient\x126\n" +
+	"\x17library_extension_order\x185 \x01(\bR\x15libraryExtensionOrder\x122\n" +
+	"\x15extension_order_stack\x186 \x01(\tR\x13extensionOrderStack\x12\x1b\n" +
+	"\ttls_stack\x189 \x01(\tR\btlsStack\x12+\n" +
+	"\x11tls_impersonation\x18: \x01(\bR\x10tlsImpersonation\x122\n" +
+	"\x15known_bot_fingerprint\x18; \x01(\bR\x13knownBotFingerprint\x12:\n" +
+	"\x19known_browser_fingerprint\x18< \x01(\bR\x17knownBrowserFingerprint\x128\n" +
+	"\x18known_fingerprint_client\x18= \x01(\tR\x16knownFingerprintClient\x125\n" +
+	"\x17has_valid_private_token\x18  \x01(\bR\x14hasValidPrivateToken\x124\n" +
+	"\x16challenge_token_passed\x18\" \x01(\bR\x14challengeTokenPassed\x124\n" +
+	"\x16challenge_token_failed\x18# \x01(\bR\x14challengeTokenFailed\x12%\n" +
+	"\x0ecaptcha_solved\x18N \x01(\bR\rcaptchaSolved\x12#\n" +
+	"\rbrowser_score\x18d \x01(\x05R\fbrowserScore\x12\x1b\n" +
+	"\tbot_score\x18e \x01(\x05R\bbotScore\x12+\n" +
+	"\x0fscore_breakdown\x18f \x01(\tB\x02\x18\x01R\x0escoreBreakdown\x12G\n" +
+	"\rcontributions\x18g \x03(\v2!.classifier.v1.SignalContributionR\rcontributions\"^\n" +
+	"\x12SignalContribution\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tdirection\x18\x02 \x01(\tR\tdirection\x12\x16\n" +
+	"\x06weight\x18\x03 \x01(\x05R\x06weight\"\x81\x05\n" +
+	"\x14ClassificationResult\x12\x1d\n" +
+	"\n" +
+	"request_id\x18\x01 \x01(\tR\trequestId\x128\n" +
+	"\ttimestamp\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12&\n" +
+	"\x0eclassification\x18\x03 \x01(\tR\x0eclassification\x12\x1e\n" +
+	"\n" +
+	"confidence\x18\x04 \x01(\x01R\n" +
+	"confidence\x12<\n" +
+	"\vfingerprint\x18\x05 \x01(\v2\x1a.classifier.v1.FingerprintR\vfingerprint\x120\n" +
+	"\asignals\x18\x06 \x01(\v2\x16.classifier.v1.SignalsR\asignals\x12\x14\n" +
+	"\x05score\x18\a \x01(\x05R\x05score\x12\x16\n" +
+	"\x06reason\x18\b \x01(\tR\x06reason\x12\x18\n" +
+	"\apartial\x18\t \x01(\bR\apartial\x12\x1e\n" +
+	"\n" +
+	"incomplete\x18\n" +
+	" \x03(\tR\n" +
+	"incomplete\x12\x16\n" +
+	"\x06margin\x18\v \x01(\x05R\x06margin\x12\x19\n" +
+	"\bflip_set\x18\f \x03(\tR\aflipSet\x12\x14\n" +
+	"\x05class\x18\r \x01(\tR\x05class\x12c\n" +
+	"\x10class_confidence\x18\x0e \x03(\v28.classifier.v1.ClassificationResult.ClassConfidenceEntryR\x0fclassConfidence\x1aB\n" +
+	"\x14ClassConfidenceEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value:\x028\x01BIZGgithub.com/muliwe/go-client-classifier/pkg/pb/classifierv1;classifierv1b\x06proto3"
+
+var (
+	file_classifier_v1_classifier_proto_rawDescOnce sync.Once
+	file_classifier_v1_classifier_proto_rawDescData []byte
+)
+
+func file_classifier_v1_classifier_proto_rawDescGZIP() []byte {
+	file_classifier_v1_classifier_proto_rawDescOnce.Do(func() {
+		file_classifier_v1_classifier_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_classifier_v1_classifier_proto_rawDesc), len(file_classifier_v1_classifier_proto_rawDesc)))
+	})
+	return file_classifier_v1_classifier_proto_rawDescData
+}
+
+var file_classifier_v1_classifier_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
+var file_classifier_v1_classifier_proto_goTypes = []any{
+	(*Fingerprint)(nil),           // 0: classifier.v1.Fingerprint
+	(*TLSFingerprint)(nil),        // 1: classifier.v1.TLSFingerprint
+	(*HTTPFingerprint)(nil),       // 2: classifier.v1.HTTPFingerprint
+	(*ParsedUserAgent)(nil),       // 3: classifier.v1.ParsedUserAgent
+	(*ClientHints)(nil),           // 4: classifier.v1.ClientHints
+	(*BrandVersion)(nil),          // 5: classifier.v1.BrandVersion
+	(*SessionFingerprint)(nil),    // 6: classifier.v1.SessionFingerprint
+	(*RateFingerprint)(nil),       // 7: classifier.v1.RateFingerprint
+	(*OffenderFingerprint)(nil),   // 8: classifier.v1.OffenderFingerprint
+	(*NetworkFingerprint)(nil),    // 9: classifier.v1.NetworkFingerprint
+	(*TCPFingerprint)(nil),        // 10: classifier.v1.TCPFingerprint
+	(*QUICFingerprint)(nil),       // 11: classifier.v1.QUICFingerprint
+	(*Signals)(nil),               // 12: classifier.v1.Signals
+	(*SignalContribution)(nil),    // 13: classifier.v1.SignalContribution
+	(*ClassificationResult)(nil),  // 14: classifier.v1.ClassificationResult
+	nil,                           // 15: classifier.v1.HTTPFingerprint.HeadersEntry
+	nil,                           // 16: classifier.v1.ClassificationResult.ClassConfidenceEntry
+	(*timestamppb.Timestamp)(nil), // 17: google.protobuf.Timestamp
+}
+var file_classifier_v1_classifier_proto_depIdxs = []int32{
+	1,  // 0: classifier.v1.Fingerprint.tls:type_name -> classifier.v1.TLSFingerprint
+	2,  // 1: classifier.v1.Fingerprint.http:type_name -> classifier.v1.HTTPFingerprint
+	6,  // 2: classifier.v1.Fingerprint.session:type_name -> classifier.v1.SessionFingerprint
+	9,  // 3: classifier.v1.Fingerprint.network:type_name -> classifier.v1.NetworkFingerprint
+	10, // 4: classifier.v1.Fingerprint.tcp:type_name -> classifier.v1.TCPFingerprint
+	11, // 5: classifier.v1.Fingerprint.quic:type_name -> classifier.v1.QUICFingerprint
+	7,  // 6: classifier.v1.Fingerprint.rate:type_name -> classifier.v1.RateFingerprint
+	8,  // 7: classifier.v1.Fingerprint.offender:type_name -> classifier.v1.OffenderFingerprint
+	15, // 8: classifier.v1.HTTPFingerprint.headers:type_name -> classifier.v1.HTTPFingerprint.HeadersEntry
+	4,  // 9: classifier.v1.HTTPFingerprint.client_hints:type_name -> classifier.v1.ClientHints
+	3,  // 10: classifier.v1.HTTPFingerprint.user_agent_parsed:type_name -> classifier.v1.ParsedUserAgent
+	5,  // 11: classifier.v1.ClientHints.brands:type_name -> classifier.v1.BrandVersion
+	5,  // 12: classifier.v1.ClientHints.full_version_list:type_name -> classifier.v1.BrandVersion
+	13, // 13: classifier.v1.Signals.contributions:type_name -> classifier.v1.SignalContribution
+	17, // 14: classifier.v1.ClassificationResult.timestamp:type_name -> google.protobuf.Timestamp
+	0,  // 15: classifier.v1.ClassificationResult.fingerprint:type_name -> classifier.v1.Fingerprint
+	12, // 16: classifier.v1.ClassificationResult.signals:type_name -> classifier.v1.Signals
+	16, // 17: classifier.v1.ClassificationResult.class_confidence:type_name -> classifier.v1.ClassificationResult.ClassConfidenceEntry
+	18, // [18:18] is the sub-list for method output_type
+	18, // [18:18] is the sub-list for method input_type
+	18, // [18:18] is the sub-list for extension type_name
+	18, // [18:18] is the sub-list for extension extendee
+	0,  // [0:18] is the sub-list for field type_name
+}
+
+func init() { file_classifier_v1_classifier_proto_init() }
+func file_classifier_v1_classifier_proto_init() {
+	if File_classifier_v1_classifier_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_classifier_v1_classifier_proto_rawDesc), len(file_classifier_v1_classifier_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   17,
+			NumExtensions: 0,
+			NumServices:   0,
+		},
+		GoTypes:           file_classifier_v1_classifier_proto_goTypes,
+		DependencyIndexes: file_classifier_v1_classifier_proto_depIdxs,
+		MessageInfos:      file_classifier_v1_classifier_proto_msgTypes,
+	}.Build()
+	File_classifier_v1_classifier_proto = out.File
+	file_classifier_v1_classifier_proto_goTypes = nil
+	file_classifier_v1_classifier_proto_depIdxs = nil
+}
@@ -0,0 +1,13 @@
+package classifierv1
+
+import "testing"
+
+// Tests are in tests/unit/proto_test.go
+// This file exists to satisfy go test ./... discovery
+
+func TestClassifierV1Package(t *testing.T) {
+	// Verify package is testable
+	if FromSignals(ToSignals(nil)) == nil {
+		t.Error("FromSignals should not return nil")
+	}
+}
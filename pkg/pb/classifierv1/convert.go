@@ -0,0 +1,725 @@
+package classifierv1
+
+import (
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// FromFingerprint converts a fingerprint to its protobuf representation
+func FromFingerprint(fp fingerprint.Fingerprint) *Fingerprint {
+	return &Fingerprint{
+		Tls:      fromTLS(fp.TLS),
+		Http:     fromHTTP(fp.HTTP),
+		Session:  fromSession(fp.Session),
+		Rate:     fromRate(fp.Rate),
+		Offender: fromOffender(fp.Offender),
+		Network:  fromNetwork(fp.Network),
+		Tcp:      fromTCP(fp.TCP),
+		Quic:     fromQUIC(fp.QUIC),
+	}
+}
+
+// ToFingerprint converts a protobuf fingerprint back to the native type.
+// Missing sub-messages yield zero values.
+func ToFingerprint(p *Fingerprint) fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		TLS:      toTLS(p.GetTls()),
+		HTTP:     toHTTP(p.GetHttp()),
+		Session:  toSession(p.GetSession()),
+		Rate:     toRate(p.GetRate()),
+		Offender: toOffender(p.GetOffender()),
+		Network:  toNetwork(p.GetNetwork()),
+		TCP:      toTCP(p.GetTcp()),
+		QUIC:     toQUIC(p.GetQuic()),
+	}
+}
+
+// FromSignals converts extracted signals to their protobuf representation
+func FromSignals(s fingerprint.Signals) *Signals {
+	return &Signals{
+		IsHttp2:           s.IsHTTP2,
+		IsHttp3:           s.IsHTTP3,
+		HasModernTls:      s.HasModernTLS,
+		HasAlpn:           s.HasALPN,
+		HighCipherCount:   s.HighCipherCount,
+		HasSessionSupport: s.HasSessionSupport,
+		HasTlsFingerprint: s.HasTLSFingerprint,
+		HasMultipleGroups: s.HasMultipleGroups,
+		HasModernCiphers:  s.HasModernCiphers,
+
+		HasGreaseCiphers:    s.HasGREASECiphers,
+		HasGreaseExtensions: s.HasGREASEExtensions,
+		HasGreaseGroups:     s.HasGREASEGroups,
+		HasEch:              s.HasECH,
+
+		HasSecFetchHeaders: s.HasSecFetchHeaders,
+		HasAcceptLanguage:  s.HasAcceptLanguage,
+		HasUserAgent:       s.HasUserAgent,
+		HasAccept:          s.HasAccept,
+		HasAcceptEncoding:  s.HasAcceptEncoding,
+		HasSecChUa:         s.HasSecClientHints,
+		SpoofedReferer:     s.SpoofedReferer,
+
+		FetchMetadataMismatch: s.FetchMetadataMismatch,
+		FetchMetadataConflict: s.FetchMetadataConflict,
+		HeaderSpoofing:        s.HeaderSpoofing,
+		SpoofingCombos:        s.SpoofingCombos,
+
+		NonCanonicalHeaderCase: s.NonCanonicalHeaderCase,
+		ClientHintsMismatch:    s.ClientHintsMismatch,
+		ClientHintsConflict:    s.ClientHintsConflict,
+
+		HasJa4HFingerprint:   s.HasJA4HFingerprint,
+		Ja4HLanguageCode:     s.JA4HLanguageCode,
+		Ja4HMissingLanguage:  s.JA4HMissingLanguage,
+		Ja4HLowHeaderCount:   s.JA4HLowHeaderCount,
+		Ja4HHighHeaderCount:  s.JA4HHighHeaderCount,
+		Ja4HHasCookies:       s.JA4HHasCookies,
+		Ja4HHasReferer:       s.JA4HHasReferer,
+		Ja4HIsHttp2:          s.JA4HIsHTTP2,
+		Ja4HConsistentSignal: s.JA4HConsistentSignal,
+
+		IsCorsPreflight:    s.IsCORSPreflight,
+		MalformedPreflight: s.MalformedPreflight,
+		IsHeadRequest:      s.IsHeadRequest,
+
+		UaIsBot:              s.UserAgentIsBot,
+		UaIsAiCrawler:        s.UserAgentIsAICrawler,
+		UaIsBrowser:          s.UserAgentIsBrowser,
+		LowHeaderCount:       s.LowHeaderCount,
+		HasBrowserHeaders:    s.HasBrowserHeaders,
+		MissingTypicalHeader: s.MissingTypicalHeader,
+
+		OutdatedBrowser:   s.OutdatedBrowser,
+		BrowserVersionLag: int32(s.BrowserVersionLag),
+
+		MatchedBotPatterns:       s.MatchedBotPatterns,
+		MatchedAiCrawlerPatterns: s.MatchedAICrawlerPatterns,
+		MatchedBrowserPatterns:   s.MatchedBrowserPatterns,
+
+		RegularTiming:    s.RegularTiming,
+		SubHumanInterval: s.SubHumanInterval,
+		HighRequestRate:  s.HighRequestRate,
+		BurstPattern:     s.BurstPattern,
+		ContentOnly:      s.ContentOnly,
+		HoneypotHit:      s.HoneypotHit,
+		RepeatOffender:   s.RepeatOffender,
+
+		FromPrivateRelay:  s.FromPrivateRelay,
+		SpoofedCrawler:    s.SpoofedCrawler,
+		ClaimedCrawler:    s.ClaimedCrawler,
+		DatacenterAsn:     s.DatacenterASN,
+		HostingProvider:   s.HostingProvider,
+		AnonymizedNetwork: s.AnonymizedNetwork,
+		Anonymizer:        s.Anonymizer,
+		TlsIntercepted:    s.TLSIntercepted,
+
+		GeoLanguageMismatch: s.GeoLanguageMismatch,
+		TcpOsMismatch:       s.TCPOSMismatch,
+		ColocatedClient:     s.ColocatedClient,
+
+		LibraryExtensionOrder: s.LibraryExtensionOrder,
+		ExtensionOrderStack:   s.ExtensionOrderStack,
+
+		TlsStack:         s.TLSStack,
+		TlsImpersonation: s.TLSImpersonation,
+
+		KnownBotFingerprint:     s.KnownBotFingerprint,
+		KnownBrowserFingerprint: s.KnownBrowserFingerprint,
+		KnownFingerprintClient:  s.KnownFingerprintClient,
+
+		HasValidPrivateToken: s.HasValidPrivateToken,
+		ChallengeTokenPassed: s.ChallengeTokenPassed,
+		ChallengeTokenFailed: s.ChallengeTokenFailed,
+		CaptchaSolved:        s.CaptchaSolved,
+
+		BrowserScore:   int32(s.BrowserScore),
+		BotScore:       int32(s.BotScore),
+		ScoreBreakdown: s.ScoreBreakdown.String(),
+		Contributions:  fromBreakdown(s.ScoreBreakdown),
+	}
+}
+
+// ToSignals converts protobuf signals back to the native type
+func ToSignals(p *Signals) fingerprint.Signals {
+	return fingerprint.Signals{
+		IsHTTP2:           p.GetIsHttp2(),
+		IsHTTP3:           p.GetIsHttp3(),
+		HasModernTLS:      p.GetHasModernTls(),
+		HasALPN:           p.GetHasAlpn(),
+		HighCipherCount:   p.GetHighCipherCount(),
+		HasSessionSupport: p.GetHasSessionSupport(),
+		HasTLSFingerprint: p.GetHasTlsFingerprint(),
+		HasMultipleGroups: p.GetHasMultipleGroups(),
+		HasModernCiphers:  p.GetHasModernCiphers(),
+
+		HasGREASECiphers:    p.GetHasGreaseCiphers(),
+		HasGREASEExtensions: p.GetHasGreaseExtensions(),
+		HasGREASEGroups:     p.GetHasGreaseGroups(),
+		HasECH:              p.GetHasEch(),
+
+		HasSecFetchHeaders: p.GetHasSecFetchHeaders(),
+		HasAcceptLanguage:  p.GetHasAcceptLanguage(),
+		HasUserAgent:       p.GetHasUserAgent(),
+		HasAccept:          p.GetHasAccept(),
+		HasAcceptEncoding:  p.GetHasAcceptEncoding(),
+		HasSecClientHints:  p.GetHasSecChUa(),
+		SpoofedReferer:     p.GetSpoofedReferer(),
+
+		FetchMetadataMismatch: p.GetFetchMetadataMismatch(),
+		FetchMetadataConflict: p.GetFetchMetadataConflict(),
+		HeaderSpoofing:        p.GetHeaderSpoofing(),
+		SpoofingCombos:        p.GetSpoofingCombos(),
+
+		NonCanonicalHeaderCase: p.GetNonCanonicalHeaderCase(),
+		ClientHintsMismatch:    p.GetClientHintsMismatch(),
+		ClientHintsConflict:    p.GetClientHintsConflict(),
+
+		HasJA4HFingerprint:   p.GetHasJa4HFingerprint(),
+		JA4HLanguageCode:     p.GetJa4HLanguageCode(),
+		JA4HMissingLanguage:  p.GetJa4HMissingLanguage(),
+		JA4HLowHeaderCount:   p.GetJa4HLowHeaderCount(),
+		JA4HHighHeaderCount:  p.GetJa4HHighHeaderCount(),
+		JA4HHasCookies:       p.GetJa4HHasCookies(),
+		JA4HHasReferer:       p.GetJa4HHasReferer(),
+		JA4HIsHTTP2:          p.GetJa4HIsHttp2(),
+		JA4HConsistentSignal: p.GetJa4HConsistentSignal(),
+
+		IsCORSPreflight:    p.GetIsCorsPreflight(),
+		MalformedPreflight: p.GetMalformedPreflight(),
+		IsHeadRequest:      p.GetIsHeadRequest(),
+
+		UserAgentIsBot:       p.GetUaIsBot(),
+		UserAgentIsAICrawler: p.GetUaIsAiCrawler(),
+		UserAgentIsBrowser:   p.GetUaIsBrowser(),
+		LowHeaderCount:       p.GetLowHeaderCount(),
+		HasBrowserHeaders:    p.GetHasBrowserHeaders(),
+		MissingTypicalHeader: p.GetMissingTypicalHeader(),
+
+		OutdatedBrowser:   p.GetOutdatedBrowser(),
+		BrowserVersionLag: int(p.GetBrowserVersionLag()),
+
+		MatchedBotPatterns:       p.GetMatchedBotPatterns(),
+		MatchedAICrawlerPatterns: p.GetMatchedAiCrawlerPatterns(),
+		MatchedBrowserPatterns:   p.GetMatchedBrowserPatterns(),
+
+		RegularTiming:    p.GetRegularTiming(),
+		SubHumanInterval: p.GetSubHumanInterval(),
+		HighRequestRate:  p.GetHighRequestRate(),
+		BurstPattern:     p.GetBurstPattern(),
+		ContentOnly:      p.GetContentOnly(),
+		HoneypotHit:      p.GetHoneypotHit(),
+		RepeatOffender:   p.GetRepeatOffender(),
+
+		FromPrivateRelay:  p.GetFromPrivateRelay(),
+		SpoofedCrawler:    p.GetSpoofedCrawler(),
+		ClaimedCrawler:    p.GetClaimedCrawler(),
+		DatacenterASN:     p.GetDatacenterAsn(),
+		HostingProvider:   p.GetHostingProvider(),
+		AnonymizedNetwork: p.GetAnonymizedNetwork(),
+		Anonymizer:        p.GetAnonymizer(),
+		TLSIntercepted:    p.GetTlsIntercepted(),
+
+		GeoLanguageMismatch: p.GetGeoLanguageMismatch(),
+		TCPOSMismatch:       p.GetTcpOsMismatch(),
+		ColocatedClient:     p.GetColocatedClient(),
+
+		LibraryExtensionOrder: p.GetLibraryExtensionOrder(),
+		ExtensionOrderStack:   p.GetExtensionOrderStack(),
+
+		TLSStack:         p.GetTlsStack(),
+		TLSImpersonation: p.GetTlsImpersonation(),
+
+		KnownBotFingerprint:     p.GetKnownBotFingerprint(),
+		KnownBrowserFingerprint: p.GetKnownBrowserFingerprint(),
+		KnownFingerprintClient:  p.GetKnownFingerprintClient(),
+
+		HasValidPrivateToken: p.GetHasValidPrivateToken(),
+		ChallengeTokenPassed: p.GetChallengeTokenPassed(),
+		ChallengeTokenFailed: p.GetChallengeTokenFailed(),
+		CaptchaSolved:        p.GetCaptchaSolved(),
+
+		BrowserScore:   int(p.GetBrowserScore()),
+		BotScore:       int(p.GetBotScore()),
+		ScoreBreakdown: toBreakdown(p),
+	}
+}
+
+// FromResult converts a classification result to its protobuf representation
+func FromResult(r fingerprint.ClassificationResult) *ClassificationResult {
+	return &ClassificationResult{
+		RequestId:      r.RequestID,
+		Timestamp:      timestamppb.New(r.Timestamp),
+		Classification: r.Classification,
+		Confidence:     r.Confidence,
+		Fingerprint:    FromFingerprint(r.Fingerprint),
+		Signals:        FromSignals(r.Signals),
+		Score:          int32(r.Score),
+		Reason:         r.Reason,
+		Partial:        r.Partial,
+		Incomplete:     r.Incomplete,
+		Margin:         int32(r.Margin),
+		FlipSet:        r.FlipSet,
+
+		Class:           r.Class,
+		ClassConfidence: r.ClassConfidence,
+	}
+}
+
+// ToResult converts a protobuf classification result back to the native type
+func ToResult(p *ClassificationResult) fingerprint.ClassificationResult {
+	r := fingerprint.ClassificationResult{
+		RequestID:      p.GetRequestId(),
+		Classification: p.GetClassification(),
+		Confidence:     p.GetConfidence(),
+		Fingerprint:    ToFingerprint(p.GetFingerprint()),
+		Signals:        ToSignals(p.GetSignals()),
+		Score:          int(p.GetScore()),
+		Reason:         p.GetReason(),
+		Partial:        p.GetPartial(),
+		Incomplete:     p.GetIncomplete(),
+		Margin:         int(p.GetMargin()),
+		FlipSet:        p.GetFlipSet(),
+
+		Class:           p.GetClass(),
+		ClassConfidence: p.GetClassConfidence(),
+	}
+	if ts := p.GetTimestamp(); ts != nil {
+		r.Timestamp = ts.AsTime()
+	}
+	return r
+}
+
+func fromTLS(t fingerprint.TLSFingerprint) *TLSFingerprint {
+	var extensions []uint32
+	for _, e := range t.Extensions {
+		extensions = append(extensions, uint32(e))
+	}
+	return &TLSFingerprint{
+		Version:            t.Version,
+		CipherSuite:        t.CipherSuite,
+		Alpn:               t.ALPN,
+		ServerName:         t.ServerName,
+		CipherSuitesCount:  int32(t.CipherSuitesCount),
+		ExtensionsCount:    int32(t.ExtensionsCount),
+		SupportedVersions:  t.SupportedVersions,
+		SignatureSchemes:   t.SignatureSchemes,
+		SupportedGroups:    t.SupportedGroups,
+		HasSessionTicket:   t.HasSessionTicket,
+		HasEarlyData:       t.HasEarlyData,
+		Ja3Hash:            t.JA3Hash,
+		Ja4Hash:            t.JA4Hash,
+		Ja4R:               t.JA4Raw,
+		Ja3SHash:           t.JA3SHash,
+		Ja4SHash:           t.JA4SHash,
+		CertificateRequest: t.CertificateRequest,
+		Available:          t.Available,
+		NoGrease:           t.NoGREASE,
+		LegacyCiphers:      t.LegacyCiphers,
+		LatencyUs:          int32(t.LatencyMicros),
+		Ja4L:               t.JA4L,
+		GreaseCiphers:      t.GREASECiphers,
+		GreaseExtensions:   t.GREASEExtensions,
+		Extensions:         extensions,
+		Ech:                t.ECH,
+	}
+}
+
+func toTLS(p *TLSFingerprint) fingerprint.TLSFingerprint {
+	var extensions []int
+	for _, e := range p.GetExtensions() {
+		extensions = append(extensions, int(e))
+	}
+	return fingerprint.TLSFingerprint{
+		Version:            p.GetVersion(),
+		CipherSuite:        p.GetCipherSuite(),
+		ALPN:               p.GetAlpn(),
+		ServerName:         p.GetServerName(),
+		CipherSuitesCount:  int(p.GetCipherSuitesCount()),
+		ExtensionsCount:    int(p.GetExtensionsCount()),
+		SupportedVersions:  p.GetSupportedVersions(),
+		SignatureSchemes:   p.GetSignatureSchemes(),
+		SupportedGroups:    p.GetSupportedGroups(),
+		HasSessionTicket:   p.GetHasSessionTicket(),
+		HasEarlyData:       p.GetHasEarlyData(),
+		JA3Hash:            p.GetJa3Hash(),
+		JA4Hash:            p.GetJa4Hash(),
+		JA4Raw:             p.GetJa4R(),
+		JA3SHash:           p.GetJa3SHash(),
+		JA4SHash:           p.GetJa4SHash(),
+		CertificateRequest: p.GetCertificateRequest(),
+		Available:          p.GetAvailable(),
+		NoGREASE:           p.GetNoGrease(),
+		LegacyCiphers:      p.GetLegacyCiphers(),
+		LatencyMicros:      int(p.GetLatencyUs()),
+		JA4L:               p.GetJa4L(),
+		GREASECiphers:      p.GetGreaseCiphers(),
+		GREASEExtensions:   p.GetGreaseExtensions(),
+		Extensions:         extensions,
+		ECH:                p.GetEch(),
+	}
+}
+
+func fromHTTP(h fingerprint.HTTPFingerprint) *HTTPFingerprint {
+	return &HTTPFingerprint{
+		Version:       h.Version,
+		Method:        h.Method,
+		Path:          h.Path,
+		Headers:       h.Headers,
+		HeaderOrder:   h.HeaderOrder,
+		HeaderCount:   int32(h.HeaderCount),
+		UserAgent:     h.UserAgent,
+		Accept:        h.Accept,
+		AcceptLang:    h.AcceptLang,
+		AcceptEnc:     h.AcceptEnc,
+		Connection:    h.Connection,
+		SecFetchSite:  h.SecFetchSite,
+		SecFetchMode:  h.SecFetchMode,
+		SecFetchDest:  h.SecFetchDest,
+		SecFetchUser:  h.SecFetchUser,
+		SecChUa:       h.SecChUA,
+		HasCookies:    h.HasCookies,
+		HasReferer:    h.HasReferer,
+		ContentType:   h.ContentType,
+		ContentLength: h.ContentLength,
+		Ja4HHash:      h.JA4HHash,
+		PrivateToken:  h.PrivateToken,
+
+		ChallengeToken: h.ChallengeToken,
+		Host:           h.Host,
+		RawHeaderNames: h.RawHeaderNames,
+		ClientHints:    fromClientHints(h.ClientHints),
+
+		UserAgentParsed: fromParsedUserAgent(h.UserAgentParsed),
+	}
+}
+
+func toHTTP(p *HTTPFingerprint) fingerprint.HTTPFingerprint {
+	return fingerprint.HTTPFingerprint{
+		Version:       p.GetVersion(),
+		Method:        p.GetMethod(),
+		Path:          p.GetPath(),
+		Headers:       p.GetHeaders(),
+		HeaderOrder:   p.GetHeaderOrder(),
+		HeaderCount:   int(p.GetHeaderCount()),
+		UserAgent:     p.GetUserAgent(),
+		Accept:        p.GetAccept(),
+		AcceptLang:    p.GetAcceptLang(),
+		AcceptEnc:     p.GetAcceptEnc(),
+		Connection:    p.GetConnection(),
+		SecFetchSite:  p.GetSecFetchSite(),
+		SecFetchMode:  p.GetSecFetchMode(),
+		SecFetchDest:  p.GetSecFetchDest(),
+		SecFetchUser:  p.GetSecFetchUser(),
+		SecChUA:       p.GetSecChUa(),
+		HasCookies:    p.GetHasCookies(),
+		HasReferer:    p.GetHasReferer(),
+		ContentType:   p.GetContentType(),
+		ContentLength: p.GetContentLength(),
+		JA4HHash:      p.GetJa4HHash(),
+		PrivateToken:  p.GetPrivateToken(),
+
+		ChallengeToken: p.GetChallengeToken(),
+		Host:           p.GetHost(),
+		RawHeaderNames: p.GetRawHeaderNames(),
+		ClientHints:    toClientHints(p.GetClientHints()),
+
+		UserAgentParsed: toParsedUserAgent(p.GetUserAgentParsed()),
+	}
+}
+
+func fromParsedUserAgent(u fingerprint.ParsedUserAgent) *ParsedUserAgent {
+	return &ParsedUserAgent{
+		Family:    u.Family,
+		Major:     int32(u.Major),
+		Os:        u.OS,
+		OsVersion: u.OSVersion,
+		Device:    u.Device,
+	}
+}
+
+func toParsedUserAgent(p *ParsedUserAgent) fingerprint.ParsedUserAgent {
+	return fingerprint.ParsedUserAgent{
+		Family:    p.GetFamily(),
+		Major:     int(p.GetMajor()),
+		OS:        p.GetOs(),
+		OSVersion: p.GetOsVersion(),
+		Device:    p.GetDevice(),
+	}
+}
+
+func fromClientHints(c fingerprint.ClientHints) *ClientHints {
+	return &ClientHints{
+		Brands:          fromBrands(c.Brands),
+		FullVersionList: fromBrands(c.FullVersionList),
+		Platform:        c.Platform,
+		PlatformVersion: c.PlatformVersion,
+		Mobile:          c.Mobile,
+		Model:           c.Model,
+		Arch:            c.Arch,
+	}
+}
+
+func toClientHints(p *ClientHints) fingerprint.ClientHints {
+	return fingerprint.ClientHints{
+		Brands:          toBrands(p.GetBrands()),
+		FullVersionList: toBrands(p.GetFullVersionList()),
+		Platform:        p.GetPlatform(),
+		PlatformVersion: p.GetPlatformVersion(),
+		Mobile:          p.GetMobile(),
+		Model:           p.GetModel(),
+		Arch:            p.GetArch(),
+	}
+}
+
+func fromBrands(b []fingerprint.BrandVersion) []*BrandVersion {
+	if len(b) == 0 {
+		return nil
+	}
+	out := make([]*BrandVersion, len(b))
+	for i, v := range b {
+		out[i] = &BrandVersion{Brand: v.Brand, Version: v.Version}
+	}
+	return out
+}
+
+func toBrands(p []*BrandVersion) []fingerprint.BrandVersion {
+	if len(p) == 0 {
+		return nil
+	}
+	out := make([]fingerprint.BrandVersion, len(p))
+	for i, v := range p {
+		out[i] = fingerprint.BrandVersion{Brand: v.GetBrand(), Version: v.GetVersion()}
+	}
+	return out
+}
+
+func fromSession(s fingerprint.SessionFingerprint) *SessionFingerprint {
+	return &SessionFingerprint{
+		RequestCount:     int32(s.RequestCount),
+		IntervalCount:    int32(s.IntervalCount),
+		MeanIntervalMs:   s.MeanIntervalMs,
+		MinIntervalMs:    s.MinIntervalMs,
+		IntervalStddevMs: s.IntervalStdDevMs,
+		IntervalJitter:   s.IntervalJitter,
+		PageRequests:     int32(s.PageRequests),
+		AssetRequests:    int32(s.AssetRequests),
+		HumanVerified:    s.HumanVerified,
+		Available:        s.Available,
+	}
+}
+
+func toSession(p *SessionFingerprint) fingerprint.SessionFingerprint {
+	return fingerprint.SessionFingerprint{
+		RequestCount:     int(p.GetRequestCount()),
+		IntervalCount:    int(p.GetIntervalCount()),
+		MeanIntervalMs:   p.GetMeanIntervalMs(),
+		MinIntervalMs:    p.GetMinIntervalMs(),
+		IntervalStdDevMs: p.GetIntervalStddevMs(),
+		IntervalJitter:   p.GetIntervalJitter(),
+		PageRequests:     int(p.GetPageRequests()),
+		AssetRequests:    int(p.GetAssetRequests()),
+		HumanVerified:    p.GetHumanVerified(),
+		Available:        p.GetAvailable(),
+	}
+}
+
+func fromRate(r fingerprint.RateFingerprint) *RateFingerprint {
+	return &RateFingerprint{
+		AddrRequests: int32(r.AddrRequests),
+		AddrBurst:    int32(r.AddrBurst),
+		Ja4Requests:  int32(r.JA4Requests),
+		Ja4Burst:     int32(r.JA4Burst),
+		Available:    r.Available,
+	}
+}
+
+func toRate(p *RateFingerprint) fingerprint.RateFingerprint {
+	return fingerprint.RateFingerprint{
+		AddrRequests: int(p.GetAddrRequests()),
+		AddrBurst:    int(p.GetAddrBurst()),
+		JA4Requests:  int(p.GetJa4Requests()),
+		JA4Burst:     int(p.GetJa4Burst()),
+		Available:    p.GetAvailable(),
+	}
+}
+
+func fromOffender(o fingerprint.OffenderFingerprint) *OffenderFingerprint {
+	return &OffenderFingerprint{
+		Trapped:        o.Trapped,
+		TrapPath:       o.TrapPath,
+		Reputation:     o.Reputation,
+		RepeatOffender: o.RepeatOffender,
+	}
+}
+
+func toOffender(p *OffenderFingerprint) fingerprint.OffenderFingerprint {
+	return fingerprint.OffenderFingerprint{
+		Trapped:        p.GetTrapped(),
+		TrapPath:       p.GetTrapPath(),
+		Reputation:     p.GetReputation(),
+		RepeatOffender: p.GetRepeatOffender(),
+	}
+}
+
+func fromNetwork(n fingerprint.NetworkFingerprint) *NetworkFingerprint {
+	return &NetworkFingerprint{
+		PrivateRelay: n.PrivateRelay,
+		RelayCountry: n.RelayCountry,
+		Country:      n.Country,
+		Local:        n.Local,
+
+		ClaimedCrawler:      n.ClaimedCrawler,
+		CrawlerVerification: n.CrawlerVerification,
+
+		Asn:        uint32(n.ASN),
+		AsOrg:      n.ASOrg,
+		Hosting:    n.Hosting,
+		Anonymizer: n.Anonymizer,
+	}
+}
+
+func toNetwork(p *NetworkFingerprint) fingerprint.NetworkFingerprint {
+	return fingerprint.NetworkFingerprint{
+		PrivateRelay: p.GetPrivateRelay(),
+		RelayCountry: p.GetRelayCountry(),
+		Country:      p.GetCountry(),
+		Local:        p.GetLocal(),
+
+		ClaimedCrawler:      p.GetClaimedCrawler(),
+		CrawlerVerification: p.GetCrawlerVerification(),
+
+		ASN:        int(p.GetAsn()),
+		ASOrg:      p.GetAsOrg(),
+		Hosting:    p.GetHosting(),
+		Anonymizer: p.GetAnonymizer(),
+	}
+}
+
+func fromTCP(t fingerprint.TCPFingerprint) *TCPFingerprint {
+	options := make([]uint32, len(t.Options))
+	for i, o := range t.Options {
+		options[i] = uint32(o)
+	}
+	return &TCPFingerprint{
+		Available:   t.Available,
+		WindowSize:  int32(t.WindowSize),
+		Options:     options,
+		Mss:         int32(t.MSS),
+		WindowScale: int32(t.WindowScale),
+		Ttl:         int32(t.TTL),
+		Ja4T:        t.JA4T,
+	}
+}
+
+func toTCP(p *TCPFingerprint) fingerprint.TCPFingerprint {
+	var options []int
+	for _, o := range p.GetOptions() {
+		options = append(options, int(o))
+	}
+	return fingerprint.TCPFingerprint{
+		Available:   p.GetAvailable(),
+		WindowSize:  int(p.GetWindowSize()),
+		Options:     options,
+		MSS:         int(p.GetMss()),
+		WindowScale: int(p.GetWindowScale()),
+		TTL:         int(p.GetTtl()),
+		JA4T:        p.GetJa4T(),
+	}
+}
+
+func fromQUIC(q fingerprint.QUICFingerprint) *QUICFingerprint {
+	params := make([]uint64, len(q.TransportParameters))
+	for i, id := range q.TransportParameters {
+		params[i] = uint64(id)
+	}
+	return &QUICFingerprint{
+		Available:             q.Available,
+		Version:               q.Version,
+		TransportParameters:   params,
+		MaxIdleTimeout:        int64(q.MaxIdleTimeout),
+		MaxUdpPayloadSize:     int64(q.MaxUDPPayloadSize),
+		InitialMaxData:        int64(q.InitialMaxData),
+		InitialMaxStreamsBidi: int64(q.InitialMaxStreamsBidi),
+		InitialMaxStreamsUni:  int64(q.InitialMaxStreamsUni),
+	}
+}
+
+func toQUIC(p *QUICFingerprint) fingerprint.QUICFingerprint {
+	var params []int
+	for _, id := range p.GetTransportParameters() {
+		params = append(params, int(id))
+	}
+	return fingerprint.QUICFingerprint{
+		Available:             p.GetAvailable(),
+		Version:               p.GetVersion(),
+		TransportParameters:   params,
+		MaxIdleTimeout:        int(p.GetMaxIdleTimeout()),
+		MaxUDPPayloadSize:     int(p.GetMaxUdpPayloadSize()),
+		InitialMaxData:        int(p.GetInitialMaxData()),
+		InitialMaxStreamsBidi: int(p.GetInitialMaxStreamsBidi()),
+		InitialMaxStreamsUni:  int(p.GetInitialMaxStreamsUni()),
+	}
+}
+
+func fromBreakdown(b fingerprint.Breakdown) []*SignalContribution {
+	if len(b) == 0 {
+		return nil
+	}
+	out := make([]*SignalContribution, len(b))
+	for i, c := range b {
+		out[i] = &SignalContribution{Name: c.Name, Direction: c.Direction, Weight: int32(c.Weight)}
+	}
+	return out
+}
+
+// toBreakdown reads the contributions, or the text breakdown of senders
+// predating them
+func toBreakdown(p *Signals) fingerprint.Breakdown {
+	cs := p.GetContributions()
+	if len(cs) == 0 {
+		return fingerprint.ParseBreakdown(p.GetScoreBreakdown())
+	}
+	out := make(fingerprint.Breakdown, len(cs))
+	for i, c := range cs {
+		out[i] = fingerprint.SignalContribution{Name: c.GetName(), Direction: c.GetDirection(), Weight: int(c.GetWeight())}
+	}
+	return out
+}
+
+// FromRequestMetadata converts request metadata to its protobuf representation
+func FromRequestMetadata(m fingerprint.RequestMetadata) *RequestMetadata {
+	p := &RequestMetadata{
+		Method:     m.Method,
+		Proto:      m.Proto,
+		Host:       m.Host,
+		Path:       m.Path,
+		RemoteAddr: m.RemoteAddr,
+	}
+	if m.Headers != nil {
+		p.Headers = make(map[string]*HeaderValues, len(m.Headers))
+		for name, values := range m.Headers {
+			p.Headers[name] = &HeaderValues{Values: values}
+		}
+	}
+	return p
+}
+
+// ToRequestMetadata converts protobuf request metadata back to the native type
+func ToRequestMetadata(p *RequestMetadata) fingerprint.RequestMetadata {
+	m := fingerprint.RequestMetadata{
+		Method:     p.GetMethod(),
+		Proto:      p.GetProto(),
+		Host:       p.GetHost(),
+		Path:       p.GetPath(),
+		RemoteAddr: p.GetRemoteAddr(),
+	}
+	if headers := p.GetHeaders(); headers != nil {
+		m.Headers = make(map[string][]string, len(headers))
+		for name, values := range headers {
+			m.Headers[name] = values.GetValues()
+		}
+	}
+	return m
+}
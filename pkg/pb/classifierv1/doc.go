@@ -0,0 +1,6 @@
+// Package classifierv1 contains the generated protobuf types for
+// api/proto/classifier/v1/classifier.proto and converters to and from
+// the fingerprint types used by the classifier.
+//
+// Regenerate with `task proto` after changing the .proto file.
+package classifierv1
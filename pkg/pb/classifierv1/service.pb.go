@@ -0,0 +1,551 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.10
+// 	protoc        (unknown)
+// source: classifier/v1/service.proto
+
+// gRPC classification service. Classify mirrors the HTTP remote
+// classification endpoints; ClassifyStream lets a sidecar push request
+// metadata continuously over one connection and receive decisions.
+
+package classifierv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// HeaderValues holds all values of a single header
+type HeaderValues struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Values        []string               `protobuf:"bytes,1,rep,name=values,proto3" json:"values,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HeaderValues) Reset() {
+	*x = HeaderValues{}
+	mi := &file_classifier_v1_service_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *HeaderValues) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HeaderValues) ProtoMessage() {}
+
+func (x *HeaderValues) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_service_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use HeaderValues.ProtoReflect.Descriptor instead.
+func (*HeaderValues) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_service_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *HeaderValues) GetValues() []string {
+	if x != nil {
+		return x.Values
+	}
+	return nil
+}
+
+// RequestMetadata describes an HTTP request observed by a remote service
+type RequestMetadata struct {
+	state         protoimpl.MessageState   `protogen:"open.v1"`
+	Method        string                   `protobuf:"bytes,1,opt,name=method,proto3" json:"method,omitempty"` // Request method (default GET)
+	Proto         string                   `protobuf:"bytes,2,opt,name=proto,proto3" json:"proto,omitempty"`   // HTTP/1.1, HTTP/2.0, HTTP/3.0 (default HTTP/1.1)
+	Host          string                   `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	Path          string                   `protobuf:"bytes,4,opt,name=path,proto3" json:"path,omitempty"`                                                                                 // Request path (default /)
+	Headers       map[string]*HeaderValues `protobuf:"bytes,5,rep,name=headers,proto3" json:"headers,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Header values keyed by name
+	RemoteAddr    string                   `protobuf:"bytes,6,opt,name=remote_addr,json=remoteAddr,proto3" json:"remote_addr,omitempty"`                                                   // Client address (ip:port)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RequestMetadata) Reset() {
+	*x = RequestMetadata{}
+	mi := &file_classifier_v1_service_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RequestMetadata) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RequestMetadata) ProtoMessage() {}
+
+func (x *RequestMetadata) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_service_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RequestMetadata.ProtoReflect.Descriptor instead.
+func (*RequestMetadata) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_service_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RequestMetadata) GetMethod() string {
+	if x != nil {
+		return x.Method
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetProto() string {
+	if x != nil {
+		return x.Proto
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetPath() string {
+	if x != nil {
+		return x.Path
+	}
+	return ""
+}
+
+func (x *RequestMetadata) GetHeaders() map[string]*HeaderValues {
+	if x != nil {
+		return x.Headers
+	}
+	return nil
+}
+
+func (x *RequestMetadata) GetRemoteAddr() string {
+	if x != nil {
+		return x.RemoteAddr
+	}
+	return ""
+}
+
+// ClassifyRequest carries a request description or a collected fingerprint
+type ClassifyRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Input:
+	//
+	//	*ClassifyRequest_Request
+	//	*ClassifyRequest_Fingerprint
+	Input         isClassifyRequest_Input `protobuf_oneof:"input"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassifyRequest) Reset() {
+	*x = ClassifyRequest{}
+	mi := &file_classifier_v1_service_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyRequest) ProtoMessage() {}
+
+func (x *ClassifyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_service_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyRequest.ProtoReflect.Descriptor instead.
+func (*ClassifyRequest) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_service_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ClassifyRequest) GetInput() isClassifyRequest_Input {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *ClassifyRequest) GetRequest() *RequestMetadata {
+	if x != nil {
+		if x, ok := x.Input.(*ClassifyRequest_Request); ok {
+			return x.Request
+		}
+	}
+	return nil
+}
+
+func (x *ClassifyRequest) GetFingerprint() *Fingerprint {
+	if x != nil {
+		if x, ok := x.Input.(*ClassifyRequest_Fingerprint); ok {
+			return x.Fingerprint
+		}
+	}
+	return nil
+}
+
+type isClassifyRequest_Input interface {
+	isClassifyRequest_Input()
+}
+
+type ClassifyRequest_Request struct {
+	Request *RequestMetadata `protobuf:"bytes,1,opt,name=request,proto3,oneof"`
+}
+
+type ClassifyRequest_Fingerprint struct {
+	Fingerprint *Fingerprint `protobuf:"bytes,2,opt,name=fingerprint,proto3,oneof"`
+}
+
+func (*ClassifyRequest_Request) isClassifyRequest_Input() {}
+
+func (*ClassifyRequest_Fingerprint) isClassifyRequest_Input() {}
+
+// ClassifyResponse carries the classification result
+type ClassifyResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        *ClassificationResult  `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassifyResponse) Reset() {
+	*x = ClassifyResponse{}
+	mi := &file_classifier_v1_service_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyResponse) ProtoMessage() {}
+
+func (x *ClassifyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_service_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyResponse.ProtoReflect.Descriptor instead.
+func (*ClassifyResponse) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_service_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ClassifyResponse) GetResult() *ClassificationResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+// ClassifyStreamRequest is a single streamed classification request
+type ClassifyStreamRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"` // Caller-chosen id echoed in the response
+	// Types that are valid to be assigned to Input:
+	//
+	//	*ClassifyStreamRequest_Request
+	//	*ClassifyStreamRequest_Fingerprint
+	Input         isClassifyStreamRequest_Input `protobuf_oneof:"input"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassifyStreamRequest) Reset() {
+	*x = ClassifyStreamRequest{}
+	mi := &file_classifier_v1_service_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyStreamRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyStreamRequest) ProtoMessage() {}
+
+func (x *ClassifyStreamRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_service_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyStreamRequest.ProtoReflect.Descriptor instead.
+func (*ClassifyStreamRequest) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_service_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *ClassifyStreamRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClassifyStreamRequest) GetInput() isClassifyStreamRequest_Input {
+	if x != nil {
+		return x.Input
+	}
+	return nil
+}
+
+func (x *ClassifyStreamRequest) GetRequest() *RequestMetadata {
+	if x != nil {
+		if x, ok := x.Input.(*ClassifyStreamRequest_Request); ok {
+			return x.Request
+		}
+	}
+	return nil
+}
+
+func (x *ClassifyStreamRequest) GetFingerprint() *Fingerprint {
+	if x != nil {
+		if x, ok := x.Input.(*ClassifyStreamRequest_Fingerprint); ok {
+			return x.Fingerprint
+		}
+	}
+	return nil
+}
+
+type isClassifyStreamRequest_Input interface {
+	isClassifyStreamRequest_Input()
+}
+
+type ClassifyStreamRequest_Request struct {
+	Request *RequestMetadata `protobuf:"bytes,2,opt,name=request,proto3,oneof"`
+}
+
+type ClassifyStreamRequest_Fingerprint struct {
+	Fingerprint *Fingerprint `protobuf:"bytes,3,opt,name=fingerprint,proto3,oneof"`
+}
+
+func (*ClassifyStreamRequest_Request) isClassifyStreamRequest_Input() {}
+
+func (*ClassifyStreamRequest_Fingerprint) isClassifyStreamRequest_Input() {}
+
+// ClassifyStreamResponse is the decision for a streamed request.
+// Invalid requests yield an error instead of closing the stream.
+type ClassifyStreamResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Result        *ClassificationResult  `protobuf:"bytes,2,opt,name=result,proto3" json:"result,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ClassifyStreamResponse) Reset() {
+	*x = ClassifyStreamResponse{}
+	mi := &file_classifier_v1_service_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ClassifyStreamResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ClassifyStreamResponse) ProtoMessage() {}
+
+func (x *ClassifyStreamResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_classifier_v1_service_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ClassifyStreamResponse.ProtoReflect.Descriptor instead.
+func (*ClassifyStreamResponse) Descriptor() ([]byte, []int) {
+	return file_classifier_v1_service_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ClassifyStreamResponse) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ClassifyStreamResponse) GetResult() *ClassificationResult {
+	if x != nil {
+		return x.Result
+	}
+	return nil
+}
+
+func (x *ClassifyStreamResponse) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+var File_classifier_v1_service_proto protoreflect.FileDescriptor
+
+const file_classifier_v1_service_proto_rawDesc = "" +
+	"\n" +
+	"\x1bclassifier/v1/service.proto\x12\rclassifier.v1\x1a\x1eclassifier/v1/classifier.proto\"&\n" +
+	"\fHeaderValues\x12\x16\n" +
+	"\x06values\x18\x01 \x03(\tR\x06values\"\xa8\x02\n" +
+	"\x0fRequestMetadata\x12\x16\n" +
+	"\x06method\x18\x01 \x01(\tR\x06method\x12\x14\n" +
+	"\x05proto\x18\x02 \x01(\tR\x05proto\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\x12\x12\n" +
+	"\x04path\x18\x04 \x01(\tR\x04path\x12E\n" +
+	"\aheaders\x18\x05 \x03(\v2+.classifier.v1.RequestMetadata.HeadersEntryR\aheaders\x12\x1f\n" +
+	"\vremote_addr\x18\x06 \x01(\tR\n" +
+	"remoteAddr\x1aW\n" +
+	"\fHeadersEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x121\n" +
+	"\x05value\x18\x02 \x01(\v2\x1b.classifier.v1.HeaderValuesR\x05value:\x028\x01\"\x96\x01\n" +
+	"\x0fClassifyRequest\x12:\n" +
+	"\arequest\x18\x01 \x01(\v2\x1e.classifier.v1.RequestMetadataH\x00R\arequest\x12>\n" +
+	"\vfingerprint\x18\x02 \x01(\v2\x1a.classifier.v1.FingerprintH\x00R\vfingerprintB\a\n" +
+	"\x05input\"O\n" +
+	"\x10ClassifyResponse\x12;\n" +
+	"\x06result\x18\x01 \x01(\v2#.classifier.v1.ClassificationResultR\x06result\"\xac\x01\n" +
+	"\x15ClassifyStreamRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12:\n" +
+	"\arequest\x18\x02 \x01(\v2\x1e.classifier.v1.RequestMetadataH\x00R\arequest\x12>\n" +
+	"\vfingerprint\x18\x03 \x01(\v2\x1a.classifier.v1.FingerprintH\x00R\vfingerprintB\a\n" +
+	"\x05input\"{\n" +
+	"\x16ClassifyStreamResponse\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12;\n" +
+	"\x06result\x18\x02 \x01(\v2#.classifier.v1.ClassificationResultR\x06result\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error2\xc3\x01\n" +
+	"\x11ClassifierService\x12K\n" +
+	"\bClassify\x12\x1e.classifier.v1.ClassifyRequest\x1a\x1f.classifier.v1.ClassifyResponse\x12a\n" +
+	"\x0eClassifyStream\x12$.classifier.v1.ClassifyStreamRequest\x1a%.classifier.v1.ClassifyStreamResponse(\x010\x01BIZGgithub.com/muliwe/go-client-classifier/pkg/pb/classifierv1;classifierv1b\x06proto3"
+
+var (
+	file_classifier_v1_service_proto_rawDescOnce sync.Once
+	file_classifier_v1_service_proto_rawDescData []byte
+)
+
+func file_classifier_v1_service_proto_rawDescGZIP() []byte {
+	file_classifier_v1_service_proto_rawDescOnce.Do(func() {
+		file_classifier_v1_service_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_classifier_v1_service_proto_rawDesc), len(file_classifier_v1_service_proto_rawDesc)))
+	})
+	return file_classifier_v1_service_proto_rawDescData
+}
+
+var file_classifier_v1_service_proto_msgTypes = make([]protoimpl.MessageInfo, 7)
+var file_classifier_v1_service_proto_goTypes = []any{
+	(*HeaderValues)(nil),           // 0: classifier.v1.HeaderValues
+	(*RequestMetadata)(nil),        // 1: classifier.v1.RequestMetadata
+	(*ClassifyRequest)(nil),        // 2: classifier.v1.ClassifyRequest
+	(*ClassifyResponse)(nil),       // 3: classifier.v1.ClassifyResponse
+	(*ClassifyStreamRequest)(nil),  // 4: classifier.v1.ClassifyStreamRequest
+	(*ClassifyStreamResponse)(nil), // 5: classifier.v1.ClassifyStreamResponse
+	nil,                            // 6: classifier.v1.RequestMetadata.HeadersEntry
+	(*Fingerprint)(nil),            // 7: classifier.v1.Fingerprint
+	(*ClassificationResult)(nil),   // 8: classifier.v1.ClassificationResult
+}
+var file_classifier_v1_service_proto_depIdxs = []int32{
+	6,  // 0: classifier.v1.RequestMetadata.headers:type_name -> classifier.v1.RequestMetadata.HeadersEntry
+	1,  // 1: classifier.v1.ClassifyRequest.request:type_name -> classifier.v1.RequestMetadata
+	7,  // 2: classifier.v1.ClassifyRequest.fingerprint:type_name -> classifier.v1.Fingerprint
+	8,  // 3: classifier.v1.ClassifyResponse.result:type_name -> classifier.v1.ClassificationResult
+	1,  // 4: classifier.v1.ClassifyStreamRequest.request:type_name -> classifier.v1.RequestMetadata
+	7,  // 5: classifier.v1.ClassifyStreamRequest.fingerprint:type_name -> classifier.v1.Fingerprint
+	8,  // 6: classifier.v1.ClassifyStreamResponse.result:type_name -> classifier.v1.ClassificationResult
+	0,  // 7: classifier.v1.RequestMetadata.HeadersEntry.value:type_name -> classifier.v1.HeaderValues
+	2,  // 8: classifier.v1.ClassifierService.Classify:input_type -> classifier.v1.ClassifyRequest
+	4,  // 9: classifier.v1.ClassifierService.ClassifyStream:input_type -> classifier.v1.ClassifyStreamRequest
+	3,  // 10: classifier.v1.ClassifierService.Classify:output_type -> classifier.v1.ClassifyResponse
+	5,  // 11: classifier.v1.ClassifierService.ClassifyStream:output_type -> classifier.v1.ClassifyStreamResponse
+	10, // [10:12] is the sub-list for method output_type
+	8,  // [8:10] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_classifier_v1_service_proto_init() }
+func file_classifier_v1_service_proto_init() {
+	if File_classifier_v1_service_proto != nil {
+		return
+	}
+	file_classifier_v1_classifier_proto_init()
+	file_classifier_v1_service_proto_msgTypes[2].OneofWrappers = []any{
+		(*ClassifyRequest_Request)(nil),
+		(*ClassifyRequest_Fingerprint)(nil),
+	}
+	file_classifier_v1_service_proto_msgTypes[4].OneofWrappers = []any{
+		(*ClassifyStreamRequest_Request)(nil),
+		(*ClassifyStreamRequest_Fingerprint)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_classifier_v1_service_proto_rawDesc), len(file_classifier_v1_service_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   7,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_classifier_v1_service_proto_goTypes,
+		DependencyIndexes: file_classifier_v1_service_proto_depIdxs,
+		MessageInfos:      file_classifier_v1_service_proto_msgTypes,
+	}.Build()
+	File_classifier_v1_service_proto = out.File
+	file_classifier_v1_service_proto_goTypes = nil
+	file_classifier_v1_service_proto_depIdxs = nil
+}
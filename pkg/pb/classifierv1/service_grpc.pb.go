@@ -0,0 +1,168 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: classifier/v1/service.proto
+
+// gRPC classification service. Classify mirrors the HTTP remote
+// classification endpoints; ClassifyStream lets a sidecar push request
+// metadata continuously over one connection and receive decisions.
+
+package classifierv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	ClassifierService_Classify_FullMethodName       = "/classifier.v1.ClassifierService/Classify"
+	ClassifierService_ClassifyStream_FullMethodName = "/classifier.v1.ClassifierService/ClassifyStream"
+)
+
+// ClassifierServiceClient is the client API for ClassifierService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// ClassifierService classifies clients as browsers or bots
+type ClassifierServiceClient interface {
+	// Classify classifies a single request or fingerprint
+	Classify(ctx context.Context, in *ClassifyRequest, opts ...grpc.CallOption) (*ClassifyResponse, error)
+	// ClassifyStream classifies a stream of requests or fingerprints.
+	// Responses carry the id of their request and may arrive out of order.
+	ClassifyStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ClassifyStreamRequest, ClassifyStreamResponse], error)
+}
+
+type classifierServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewClassifierServiceClient(cc grpc.ClientConnInterface) ClassifierServiceClient {
+	return &classifierServiceClient{cc}
+}
+
+func (c *classifierServiceClient) Classify(ctx context.Context, in *ClassifyRequest, opts ...grpc.CallOption) (*ClassifyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ClassifyResponse)
+	err := c.cc.Invoke(ctx, ClassifierService_Classify_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *classifierServiceClient) ClassifyStream(ctx context.Context, opts ...grpc.CallOption) (grpc.BidiStreamingClient[ClassifyStreamRequest, ClassifyStreamResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &ClassifierService_ServiceDesc.Streams[0], ClassifierService_ClassifyStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ClassifyStreamRequest, ClassifyStreamResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClassifierService_ClassifyStreamClient = grpc.BidiStreamingClient[ClassifyStreamRequest, ClassifyStreamResponse]
+
+// ClassifierServiceServer is the server API for ClassifierService service.
+// All implementations must embed UnimplementedClassifierServiceServer
+// for forward compatibility.
+//
+// ClassifierService classifies clients as browsers or bots
+type ClassifierServiceServer interface {
+	// Classify classifies a single request or fingerprint
+	Classify(context.Context, *ClassifyRequest) (*ClassifyResponse, error)
+	// ClassifyStream classifies a stream of requests or fingerprints.
+	// Responses carry the id of their request and may arrive out of order.
+	ClassifyStream(grpc.BidiStreamingServer[ClassifyStreamRequest, ClassifyStreamResponse]) error
+	mustEmbedUnimplementedClassifierServiceServer()
+}
+
+// UnimplementedClassifierServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedClassifierServiceServer struct{}
+
+func (UnimplementedClassifierServiceServer) Classify(context.Context, *ClassifyRequest) (*ClassifyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Classify not implemented")
+}
+func (UnimplementedClassifierServiceServer) ClassifyStream(grpc.BidiStreamingServer[ClassifyStreamRequest, ClassifyStreamResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method ClassifyStream not implemented")
+}
+func (UnimplementedClassifierServiceServer) mustEmbedUnimplementedClassifierServiceServer() {}
+func (UnimplementedClassifierServiceServer) testEmbeddedByValue()                           {}
+
+// UnsafeClassifierServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ClassifierServiceServer will
+// result in compilation errors.
+type UnsafeClassifierServiceServer interface {
+	mustEmbedUnimplementedClassifierServiceServer()
+}
+
+func RegisterClassifierServiceServer(s grpc.ServiceRegistrar, srv ClassifierServiceServer) {
+	// If the following call pancis, it indicates UnimplementedClassifierServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&ClassifierService_ServiceDesc, srv)
+}
+
+func _ClassifierService_Classify_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ClassifyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ClassifierServiceServer).Classify(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: ClassifierService_Classify_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ClassifierServiceServer).Classify(ctx, req.(*ClassifyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _ClassifierService_ClassifyStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(ClassifierServiceServer).ClassifyStream(&grpc.GenericServerStream[ClassifyStreamRequest, ClassifyStreamResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type ClassifierService_ClassifyStreamServer = grpc.BidiStreamingServer[ClassifyStreamRequest, ClassifyStreamResponse]
+
+// ClassifierService_ServiceDesc is the grpc.ServiceDesc for ClassifierService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var ClassifierService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "classifier.v1.ClassifierService",
+	HandlerType: (*ClassifierServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Classify",
+			Handler:    _ClassifierService_Classify_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "ClassifyStream",
+			Handler:       _ClassifierService_ClassifyStream_Handler,
+			ServerStreams: true,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "classifier/v1/service.proto",
+}
@@ -0,0 +1,307 @@
+package integration
+
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"testing"
+
+	utls "github.com/refraction-networking/utls"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/tests/tlsharness"
+)
+
+// chromeHeaders are the request headers of a Chrome navigation
+func chromeHeaders() http.Header {
+	return http.Header{
+		"User-Agent":      {"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36"},
+		"Accept":          {"text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"},
+		"Accept-Language": {"en-US,en;q=0.9"},
+		"Accept-Encoding": {"gzip, deflate, br"},
+	}
+}
+
+// requireClientHello fails unless the server captured the ClientHello
+func requireClientHello(t *testing.T, fp fingerprint.TLSFingerprint) {
+	t.Helper()
+	if !fp.Available {
+		t.Fatal("TLS fingerprint not available")
+	}
+	if fp.JA3Hash == "" || fp.JA4Hash == "" {
+		t.Fatalf("ClientHello not captured: JA3 %q, JA4 %q", fp.JA3Hash, fp.JA4Hash)
+	}
+}
+
+func TestTLS_GoDefaultClientHello(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	result := h.Debug(t, tlsharness.StdTLS(h.TLSConfig()), nil)
+	fp := result.Fingerprint.TLS
+	requireClientHello(t, fp)
+
+	if fp.Version != "TLS 1.3" {
+		t.Errorf("Version = %q, want TLS 1.3", fp.Version)
+	}
+	if fp.ServerName != tlsharness.ServerName {
+		t.Errorf("ServerName = %q, want %q", fp.ServerName, tlsharness.ServerName)
+	}
+	// JA4 a: TCP, TLS 1.3, SNI to a domain, no ALPN
+	if !strings.HasPrefix(fp.JA4Hash, "t13d") || !strings.HasSuffix(strings.Split(fp.JA4Hash, "_")[0], "00") {
+		t.Errorf("JA4 = %q, want t13d..00 prefix", fp.JA4Hash)
+	}
+	if !fp.NoGREASE {
+		t.Error("crypto/tls sends no GREASE, NoGREASE should be set")
+	}
+	if fp.ECH || result.Signals.HasECH {
+		t.Error("crypto/tls sends ECH only when configured, ECH should be unset")
+	}
+	if len(fp.Extensions) != fp.ExtensionsCount || fp.Extensions[0] != 0 {
+		t.Errorf("Extensions = %v, want %d IDs starting with server_name", fp.Extensions, fp.ExtensionsCount)
+	}
+	if !result.Signals.HasTLSFingerprint || !result.Signals.HasModernTLS {
+		t.Errorf("signals: HasTLSFingerprint=%t HasModernTLS=%t", result.Signals.HasTLSFingerprint, result.Signals.HasModernTLS)
+	}
+}
+
+func TestTLS_CipherSuites(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	full := h.Debug(t, tlsharness.StdTLS(h.TLSConfig()), nil).Fingerprint.TLS
+
+	cfg := h.TLSConfig()
+	cfg.MaxVersion = tls.VersionTLS12
+	cfg.CipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256, tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256}
+	result := h.Debug(t, tlsharness.StdTLS(cfg), nil)
+	narrow := result.Fingerprint.TLS
+	requireClientHello(t, narrow)
+
+	if narrow.Version != "TLS 1.2" {
+		t.Errorf("Version = %q, want TLS 1.2", narrow.Version)
+	}
+	if narrow.CipherSuitesCount != 2 {
+		t.Errorf("CipherSuitesCount = %d, want 2", narrow.CipherSuitesCount)
+	}
+	if !strings.HasPrefix(narrow.JA4Hash, "t12d02") {
+		t.Errorf("JA4 = %q, want t12d02 prefix", narrow.JA4Hash)
+	}
+	if narrow.JA3Hash == full.JA3Hash || narrow.JA4Hash == full.JA4Hash {
+		t.Errorf("restricted cipher set kept the default fingerprint: JA3 %s, JA4 %s", narrow.JA3Hash, narrow.JA4Hash)
+	}
+	if result.Signals.HighCipherCount {
+		t.Error("HighCipherCount set for two cipher suites")
+	}
+	if result.Signals.HasModernCiphers {
+		t.Error("HasModernCiphers set for TLS 1.2")
+	}
+}
+
+func TestTLS_ALPN(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	tests := []struct {
+		name      string
+		protos    []string
+		wantALPN  string
+		wantJA4   string // Last two characters of JA4 a
+		wantHTTP  string
+		wantHTTP2 bool
+	}{
+		{"h2", []string{"h2", "http/1.1"}, "h2", "h2", "HTTP/2.0", true},
+		{"http/1.1", []string{"http/1.1"}, "http/1.1", "h1", "HTTP/1.1", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := h.TLSConfig()
+			cfg.NextProtos = tt.protos
+			result := h.Debug(t, tlsharness.StdTLS(cfg), chromeHeaders())
+			fp := result.Fingerprint
+			requireClientHello(t, fp.TLS)
+
+			if fp.TLS.ALPN != tt.wantALPN {
+				t.Errorf("ALPN = %q, want %q", fp.TLS.ALPN, tt.wantALPN)
+			}
+			if a := strings.Split(fp.TLS.JA4Hash, "_")[0]; !strings.HasSuffix(a, tt.wantJA4) {
+				t.Errorf("JA4 = %q, want ALPN %q in part a", fp.TLS.JA4Hash, tt.wantJA4)
+			}
+			if fp.HTTP.Version != tt.wantHTTP {
+				t.Errorf("HTTP version = %q, want %q", fp.HTTP.Version, tt.wantHTTP)
+			}
+			if result.Signals.IsHTTP2 != tt.wantHTTP2 || !result.Signals.HasALPN {
+				t.Errorf("signals: IsHTTP2=%t HasALPN=%t", result.Signals.IsHTTP2, result.Signals.HasALPN)
+			}
+			if result.Signals.JA4HIsHTTP2 != tt.wantHTTP2 {
+				t.Errorf("JA4HIsHTTP2 = %t, want %t", result.Signals.JA4HIsHTTP2, tt.wantHTTP2)
+			}
+		})
+	}
+}
+
+func TestTLS_ServerHello(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	tests := []struct {
+		name    string
+		version uint16
+		want    string // JA4S up to the extension hash
+	}{
+		// TLS 1.3 sends ALPN encrypted, so JA4S shows none
+		{"TLS 1.3", tls.VersionTLS13, "t130200_1301_"},
+		{"TLS 1.2", tls.VersionTLS12, "t12"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := h.TLSConfig()
+			cfg.MaxVersion = tt.version
+			cfg.NextProtos = []string{"h2", "http/1.1"}
+			cfg.CipherSuites = []uint16{tls.TLS_ECDHE_ECDSA_WITH_AES_128_GCM_SHA256}
+			fp := h.Debug(t, tlsharness.StdTLS(cfg), chromeHeaders()).Fingerprint.TLS
+			requireClientHello(t, fp)
+
+			if len(fp.JA3SHash) != 32 {
+				t.Errorf("JA3S = %q, want an MD5 hash", fp.JA3SHash)
+			}
+			if !strings.HasPrefix(fp.JA4SHash, tt.want) {
+				t.Errorf("JA4S = %q, want prefix %q", fp.JA4SHash, tt.want)
+			}
+			if tt.version == tls.VersionTLS12 && !strings.HasSuffix(strings.Split(fp.JA4SHash, "_")[0], "h2") {
+				t.Errorf("JA4S = %q, want ALPN h2", fp.JA4SHash)
+			}
+		})
+	}
+}
+
+func TestTLS_Latency(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	for _, version := range []uint16{tls.VersionTLS13, tls.VersionTLS12} {
+		cfg := h.TLSConfig()
+		cfg.MaxVersion = version
+		result := h.Debug(t, tlsharness.StdTLS(cfg), chromeHeaders())
+		fp := result.Fingerprint
+
+		// No SYN is recorded, so the TTL part is empty
+		if fp.TLS.LatencyMicros <= 0 || !strings.HasSuffix(fp.TLS.JA4L, "_00") {
+			t.Errorf("TLS %x: latency = %d, JA4L = %q", version, fp.TLS.LatencyMicros, fp.TLS.JA4L)
+		}
+		// Loopback is as colocated as it gets, but local clients are not judged
+		if !fp.Network.Local || result.Signals.ColocatedClient {
+			t.Errorf("TLS %x: Local = %v, ColocatedClient = %v", version, fp.Network.Local, result.Signals.ColocatedClient)
+		}
+	}
+}
+
+func TestTLS_SessionResumption(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	cfg := h.TLSConfig()
+	cfg.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+	first := h.Debug(t, tlsharness.StdTLS(cfg), nil).Fingerprint.TLS
+	resumed := h.Debug(t, tlsharness.StdTLS(cfg), nil).Fingerprint.TLS
+	requireClientHello(t, resumed)
+
+	// A resuming ClientHello adds the pre_shared_key extension
+	if resumed.ExtensionsCount != first.ExtensionsCount+1 {
+		t.Errorf("ExtensionsCount = %d on resumption, want %d", resumed.ExtensionsCount, first.ExtensionsCount+1)
+	}
+	if resumed.JA3Hash == first.JA3Hash {
+		t.Error("JA3 unchanged on resumption")
+	}
+
+	// TLS 1.2 tickets ride on the session_ticket extension, which
+	// crypto/tls only offers with a session cache
+	cfg = h.TLSConfig()
+	cfg.MaxVersion = tls.VersionTLS12
+	cfg.ClientSessionCache = tls.NewLRUClientSessionCache(1)
+	if fp := h.Debug(t, tlsharness.StdTLS(cfg), nil); !fp.Fingerprint.TLS.HasSessionTicket || !fp.Signals.HasSessionSupport {
+		t.Error("session_ticket extension not detected")
+	}
+	cfg.SessionTicketsDisabled = true
+	if fp := h.Debug(t, tlsharness.StdTLS(cfg), nil); fp.Fingerprint.TLS.HasSessionTicket || fp.Signals.HasSessionSupport {
+		t.Error("session_ticket detected with tickets disabled")
+	}
+}
+
+func TestTLS_BrowserClientHellos(t *testing.T) {
+	h := tlsharness.Start(t)
+
+	tests := []struct {
+		name         string
+		hello        utls.ClientHelloID
+		wantNoGREASE bool
+		wantTicket   bool // Safari no longer offers session_ticket
+		wantECH      bool // Chrome and Firefox send ECH GREASE, Safari does not
+	}{
+		{"chrome", utls.HelloChrome_Auto, false, true, true},
+		{"firefox", utls.HelloFirefox_Auto, true, true, true},
+		{"safari", utls.HelloSafari_Auto, false, false, false},
+	}
+	seen := make(map[string]string)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := h.Debug(t, tlsharness.UTLS(tt.hello, h.Roots), chromeHeaders())
+			fp := result.Fingerprint.TLS
+			requireClientHello(t, fp)
+
+			if !strings.HasPrefix(fp.JA4Hash, "t13d") {
+				t.Errorf("JA4 = %q, want t13d prefix", fp.JA4Hash)
+			}
+			if fp.ALPN != "h2" {
+				t.Errorf("ALPN = %q, want h2", fp.ALPN)
+			}
+			if fp.NoGREASE != tt.wantNoGREASE {
+				t.Errorf("NoGREASE = %t, want %t", fp.NoGREASE, tt.wantNoGREASE)
+			}
+			if s := result.Signals; s.HasGREASEGroups == tt.wantNoGREASE || s.HasGREASECiphers == tt.wantNoGREASE {
+				t.Errorf("GREASE signals: ciphers=%t groups=%t, want %t", s.HasGREASECiphers, s.HasGREASEGroups, !tt.wantNoGREASE)
+			}
+			if fp.ECH != tt.wantECH || result.Signals.HasECH != tt.wantECH {
+				t.Errorf("ECH = %t, HasECH = %t, want %t", fp.ECH, result.Signals.HasECH, tt.wantECH)
+			}
+			if result.Signals.LibraryExtensionOrder {
+				t.Errorf("browser extension order %v taken for %s", fp.Extensions, result.Signals.ExtensionOrderStack)
+			}
+			if !result.Signals.HighCipherCount || !result.Signals.HasMultipleGroups {
+				t.Errorf("browser TLS signals: HighCipherCount=%t HasMultipleGroups=%t",
+					result.Signals.HighCipherCount, result.Signals.HasMultipleGroups)
+			}
+			if result.Signals.HasSessionSupport != tt.wantTicket {
+				t.Errorf("HasSessionSupport = %t, want %t", result.Signals.HasSessionSupport, tt.wantTicket)
+			}
+			if other, dup := seen[fp.JA4Hash]; dup {
+				t.Errorf("JA4 %s shared with %s", fp.JA4Hash, other)
+			}
+			seen[fp.JA4Hash] = tt.name
+		})
+	}
+}
+
+func TestHTTP3_QUICClientHello(t *testing.T) {
+	h := tlsharness.Start(t, server.WithHTTP3(true))
+
+	result := h.DebugHTTP3(t, chromeHeaders())
+	fp := result.Fingerprint
+	requireClientHello(t, fp.TLS)
+
+	if fp.HTTP.Version != "HTTP/3.0" || fp.TLS.ALPN != "h3" {
+		t.Errorf("HTTP version %q, ALPN %q, want HTTP/3.0 over h3", fp.HTTP.Version, fp.TLS.ALPN)
+	}
+	// JA4 a: QUIC, TLS 1.3, SNI to a domain, h3
+	if a := strings.Split(fp.TLS.JA4Hash, "_")[0]; !strings.HasPrefix(a, "q13d") || !strings.HasSuffix(a, "h3") {
+		t.Errorf("JA4 = %q, want q13d..h3 prefix", fp.TLS.JA4Hash)
+	}
+	if !fp.QUIC.Available || fp.QUIC.Version != "v1" || len(fp.QUIC.TransportParameters) == 0 {
+		t.Errorf("QUIC fingerprint = %+v, want v1 transport parameters", fp.QUIC)
+	}
+	if s := result.Signals; !s.IsHTTP3 || s.IsHTTP2 || !s.JA4HConsistentSignal {
+		t.Errorf("signals: IsHTTP3=%t IsHTTP2=%t JA4HConsistentSignal=%t, want HTTP/3 consistent with JA4H",
+			s.IsHTTP3, s.IsHTTP2, s.JA4HConsistentSignal)
+	}
+
+	// Requests over TCP on the same port are not taken for QUIC
+	tcp := h.Debug(t, tlsharness.StdTLS(h.TLSConfig()), nil)
+	if tcp.Fingerprint.QUIC.Available || tcp.Signals.IsHTTP3 {
+		t.Errorf("TCP request reported as QUIC: %+v", tcp.Fingerprint.QUIC)
+	}
+}
@@ -0,0 +1,267 @@
+// Package tlsharness runs the fingerprinting server on localhost TLS so
+// tests can drive it with real ClientHellos, from crypto/tls or uTLS, and
+// inspect the JA3/JA4 and signals the server derives from them.
+package tlsharness
+
+import (
+	"bufio"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+	utls "github.com/refraction-networking/utls"
+	"golang.org/x/net/http2"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+// ServerName is the SNI hostname the harness certificate is issued for
+const ServerName = "localhost"
+
+// Harness is a TLS fingerprinting server listening on 127.0.0.1
+type Harness struct {
+	Addr  string         // host:port the server listens on
+	Roots *x509.CertPool // Trusts the server's self-signed certificate
+}
+
+// Handshaker completes a client TLS handshake over raw and returns the
+// connection with the ALPN protocol it negotiated
+type Handshaker func(raw net.Conn) (net.Conn, string, error)
+
+// Start serves a TLS server with the debug endpoint enabled, logging to a
+// temporary directory, and shuts it down when the test ends. Options are
+// applied after the harness's own.
+func Start(t testing.TB, opts ...server.Option) *Harness {
+	t.Helper()
+
+	dir := t.TempDir()
+	certFile, keyFile, roots := writeCertificate(t, dir)
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+
+	lc := logger.DefaultConfig()
+	lc.LogDir = dir
+	base := []server.Option{
+		server.WithTLS(certFile, keyFile),
+		server.WithListener(lis),
+		server.WithDebug(true),
+		server.WithLogger(lc),
+	}
+	srv, err := server.New(append(base, opts...)...)
+	if err != nil {
+		_ = lis.Close()
+		t.Fatalf("server.New: %v", err)
+	}
+
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+	t.Cleanup(func() {
+		if err := srv.Close(); err != nil {
+			t.Errorf("shutdown: %v", err)
+		}
+		if err := <-served; err != nil && err != http.ErrServerClosed {
+			t.Errorf("serve: %v", err)
+		}
+	})
+
+	return &Harness{Addr: lis.Addr().String(), Roots: roots}
+}
+
+// TLSConfig returns a crypto/tls client config that trusts the server and
+// sends ServerName as SNI; callers vary ciphers, ALPN and resumption on it
+func (h *Harness) TLSConfig() *tls.Config {
+	return &tls.Config{
+		RootCAs:    h.Roots,
+		ServerName: ServerName,
+		MinVersion: tls.VersionTLS12,
+	}
+}
+
+// StdTLS returns a Handshaker using crypto/tls with cfg
+func StdTLS(cfg *tls.Config) Handshaker {
+	return func(raw net.Conn) (net.Conn, string, error) {
+		conn := tls.Client(raw, cfg)
+		if err := conn.Handshake(); err != nil {
+			return nil, "", err
+		}
+		return conn, conn.ConnectionState().NegotiatedProtocol, nil
+	}
+}
+
+// UTLS returns a Handshaker sending the uTLS ClientHello preset hello to a
+// server trusted through roots
+func UTLS(hello utls.ClientHelloID, roots *x509.CertPool) Handshaker {
+	return func(raw net.Conn) (net.Conn, string, error) {
+		conn := utls.UClient(raw, &utls.Config{RootCAs: roots, ServerName: ServerName}, hello)
+		if err := conn.Handshake(); err != nil {
+			return nil, "", err
+		}
+		return conn, conn.ConnectionState().NegotiatedProtocol, nil
+	}
+}
+
+// Debug connects with hs, sends GET /v1/debug with the given headers over
+// the negotiated HTTP version, and returns the server's classification
+func (h *Harness) Debug(t testing.TB, hs Handshaker, header http.Header) fingerprint.ClassificationResult {
+	t.Helper()
+
+	raw, err := net.DialTimeout("tcp", h.Addr, 5*time.Second)
+	if err != nil {
+		t.Fatalf("dial: %v", err)
+	}
+	defer func() { _ = raw.Close() }()
+	_ = raw.SetDeadline(time.Now().Add(10 * time.Second))
+
+	conn, proto, err := hs(raw)
+	if err != nil {
+		t.Fatalf("handshake: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	req, err := http.NewRequest(http.MethodGet, "https://"+ServerName+"/v1/debug", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := roundTrip(conn, proto, req)
+	if err != nil {
+		t.Fatalf("GET /v1/debug over %q: %v", proto, err)
+	}
+	return decodeDebug(t, resp)
+}
+
+// DebugHTTP3 sends GET /v1/debug with the given headers over HTTP/3 to the
+// UDP port of Addr, which the server serves when started with
+// server.WithHTTP3, and returns the server's classification
+func (h *Harness) DebugHTTP3(t testing.TB, header http.Header) fingerprint.ClassificationResult {
+	t.Helper()
+
+	tr := &http3.Transport{TLSClientConfig: h.TLSConfig()}
+	defer func() { _ = tr.Close() }()
+	client := &http.Client{Transport: tr, Timeout: 10 * time.Second}
+
+	req, err := http.NewRequest(http.MethodGet, "https://"+h.Addr+"/v1/debug", nil)
+	if err != nil {
+		t.Fatalf("request: %v", err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		t.Fatalf("GET /v1/debug over h3: %v", err)
+	}
+	return decodeDebug(t, resp)
+}
+
+// decodeDebug reads the classification of a /v1/debug response
+func decodeDebug(t testing.TB, resp *http.Response) fingerprint.ClassificationResult {
+	t.Helper()
+
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET /v1/debug: status %d", resp.StatusCode)
+	}
+
+	var result fingerprint.ClassificationResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode debug response: %v", err)
+	}
+	return result
+}
+
+// roundTrip sends req on conn as HTTP/2 when h2 was negotiated, else as
+// HTTP/1.1
+func roundTrip(conn net.Conn, proto string, req *http.Request) (*http.Response, error) {
+	if proto == http2.NextProtoTLS {
+		cc, err := (&http2.Transport{}).NewClientConn(conn)
+		if err != nil {
+			return nil, err
+		}
+		return cc.RoundTrip(req)
+	}
+	if err := req.Write(conn); err != nil {
+		return nil, err
+	}
+	return http.ReadResponse(bufio.NewReader(conn), req)
+}
+
+// writeCertificate creates a self-signed certificate for ServerName and
+// 127.0.0.1 in dir, returning its files and a pool trusting it
+func writeCertificate(t testing.TB, dir string) (certFile, keyFile string, roots *x509.CertPool) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: ServerName},
+		DNSNames:              []string{ServerName},
+		IPAddresses:           []net.IP{net.IPv4(127, 0, 0, 1)},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, "cert.pem")
+	keyFile = filepath.Join(dir, "key.pem")
+	if err := writePEM(certFile, "CERTIFICATE", der); err != nil {
+		t.Fatal(err)
+	}
+	if err := writePEM(keyFile, "EC PRIVATE KEY", keyDER); err != nil {
+		t.Fatal(err)
+	}
+
+	roots = x509.NewCertPool()
+	roots.AddCert(cert)
+	return certFile, keyFile, roots
+}
+
+// writePEM writes one PEM block to path
+func writePEM(path, blockType string, der []byte) error {
+	data := pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der})
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/anonymize"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/internal/session"
+)
+
+func TestAnonymizer_Truncate(t *testing.T) {
+	a, err := anonymize.New(anonymize.Config{Truncate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		in, want string
+	}{
+		{"203.0.113.77:51234", "203.0.113.0"},
+		{"203.0.113.77", "203.0.113.0"},
+		{"[::ffff:203.0.113.77]:443", "203.0.113.0"},
+		{"[2001:db8:1234:5678::1]:443", "2001:db8:1234::"},
+		{"[fe80::1%eth0]:443", "fe80::"},
+		{"not-an-ip", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := a.Addr(tt.in); got != tt.want {
+			t.Errorf("Addr(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+
+	a, err = anonymize.New(anonymize.Config{Truncate: true, IPv4Prefix: 16, IPv6Prefix: 32})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := a.Addr("203.0.113.77:1"); got != "203.0.0.0" {
+		t.Errorf("/16 Addr() = %q", got)
+	}
+	if got := a.Addr("[2001:db8:1234::1]:1"); got != "2001:db8::" {
+		t.Errorf("/32 Addr() = %q", got)
+	}
+}
+
+func TestAnonymizer_HMAC(t *testing.T) {
+	a, err := anonymize.New(anonymize.Config{HMACKey: []byte("secret")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	h1, h2 := a.Addr("203.0.113.77:1000"), a.Addr("203.0.113.77:2000")
+	if h1 != h2 {
+		t.Errorf("same IP, different ports: %q != %q", h1, h2)
+	}
+	if !strings.HasPrefix(h1, "h:") || len(h1) != 2+32 || strings.Contains(h1, "203.0.113") {
+		t.Errorf("Addr() = %q, want h: and 32 hex digits", h1)
+	}
+	if a.Addr("203.0.113.78:1000") == h1 {
+		t.Error("different IPs hash alike without truncation")
+	}
+
+	other, _ := anonymize.New(anonymize.Config{HMACKey: []byte("other")})
+	if other.Addr("203.0.113.77:1000") == h1 {
+		t.Error("different keys produce the same pseudonym")
+	}
+
+	both, _ := anonymize.New(anonymize.Config{Truncate: true, HMACKey: []byte("secret")})
+	if both.Addr("203.0.113.77:1") != both.Addr("203.0.113.200:1") {
+		t.Error("truncate+hmac should hash the /24")
+	}
+}
+
+func TestAnonymizer_ConfigErrors(t *testing.T) {
+	for _, cfg := range []anonymize.Config{
+		{},
+		{Truncate: true, IPv4Prefix: 33},
+		{Truncate: true, IPv6Prefix: 129},
+	} {
+		if _, err := anonymize.New(cfg); err == nil {
+			t.Errorf("New(%+v) should fail", cfg)
+		}
+	}
+}
+
+func TestHandler_IPAnonymizer(t *testing.T) {
+	dir := t.TempDir()
+	l, err := logger.New(logger.Config{LogDir: dir, FileName: "requests.jsonl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	a, _ := anonymize.New(anonymize.Config{Truncate: true})
+	h := server.NewHandler(fingerprint.NewCollector(), classifier.New(classifier.DefaultConfig()), l)
+	h.SetQuiet(true)
+	h.SetIPAnonymizer(a)
+	h.SetSessionTracker(session.New(session.DefaultConfig()))
+
+	// Two addresses in the same /24 share a session
+	for _, addr := range []string{"198.51.100.7:1000", "198.51.100.9:2000"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = addr
+		h.HandleClassify(httptest.NewRecorder(), r)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "requests.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("log has %d entries, want 2", len(lines))
+	}
+	var entry logger.LogEntry
+	if err := json.Unmarshal([]byte(lines[1]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.RemoteAddr != "198.51.100.0" {
+		t.Errorf("logged remote_addr = %q, want 198.51.100.0", entry.RemoteAddr)
+	}
+	if entry.Fingerprint.Session.RequestCount != 2 {
+		t.Errorf("session request count = %d, want 2 on the anonymized key", entry.Fingerprint.Session.RequestCount)
+	}
+}
@@ -0,0 +1,202 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/asn"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+const asnCSV = `network,autonomous_system_number,autonomous_system_organization
+# GeoLite2 ASN rows
+5.9.0.0/16,24940,Hetzner Online GmbH
+2a01:4f8::/32,24940,Hetzner Online GmbH
+# DB-IP rows
+84.128.0.0,84.191.255.255,AS3320,"Deutsche Telekom AG"
+10.0.0.0,10.255.255.255,0,Not routed
+`
+
+func TestASNParse_Lookup(t *testing.T) {
+	db, err := asn.Parse(strings.NewReader(asnCSV))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if db.Len() != 3 {
+		t.Errorf("Len() = %d, want 3 (AS 0 skipped)", db.Len())
+	}
+	tests := []struct {
+		addr string
+		want uint32
+		org  string
+	}{
+		{"5.9.100.1", 24940, "Hetzner Online GmbH"},
+		{"2a01:4f8:10::1", 24940, "Hetzner Online GmbH"},
+		{"84.150.3.4", 3320, "Deutsche Telekom AG"},
+		{"10.1.2.3", 0, ""},
+		{"5.10.0.1", 0, ""},
+		{"2a01:4f9::1", 0, ""},
+	}
+	for _, tt := range tests {
+		as, ok := db.Lookup(netip.MustParseAddr(tt.addr))
+		if as.Number != tt.want || as.Org != tt.org || ok != (tt.want != 0) {
+			t.Errorf("Lookup(%s) = %+v, %v, want AS%d %q", tt.addr, as, ok, tt.want, tt.org)
+		}
+	}
+
+	for name, in := range map[string]string{
+		"empty":       "",
+		"bad asn":     "5.9.0.0/16,24940\n6.0.0.0/16,ASx\n",
+		"bad network": "5.9.0.0/16,24940\n6.0.0.0/40,1\n",
+	} {
+		if _, err := asn.Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: Parse() error = nil, want error", name)
+		}
+	}
+}
+
+func TestHostingList(t *testing.T) {
+	def := asn.DefaultHosting()
+	for number, want := range map[uint32]string{16509: "AWS", 396982: "Google Cloud", 24940: "Hetzner", 16276: "OVH", 14061: "DigitalOcean"} {
+		if got, ok := def.Provider(number); !ok || got != want {
+			t.Errorf("DefaultHosting().Provider(%d) = %q, %v, want %q", number, got, ok, want)
+		}
+	}
+	if _, ok := def.Provider(3320); ok {
+		t.Error("a residential ISP is listed as hosting")
+	}
+
+	l, err := asn.ParseHosting(strings.NewReader("# custom\nAS3320 Telekom Cloud\n64500\n"))
+	if err != nil {
+		t.Fatalf("ParseHosting() error = %v", err)
+	}
+	if p, _ := l.Provider(3320); p != "Telekom Cloud" || l.Len() != 2 {
+		t.Errorf("Provider(3320) = %q, Len() = %d", p, l.Len())
+	}
+	if p, _ := l.Provider(64500); p != "AS64500" {
+		t.Errorf("unnamed provider = %q, want AS64500", p)
+	}
+	if _, err := asn.ParseHosting(strings.NewReader("AS24940 Hetzner\nHetzner\n")); err == nil {
+		t.Error("ParseHosting() of a line without ASN succeeded")
+	}
+}
+
+// enrichASN runs e on a request from addr
+func enrichASN(t *testing.T, e *asn.Enricher, addr string) fingerprint.NetworkFingerprint {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = addr
+	var fp fingerprint.Fingerprint
+	if err := e.Enrich(context.Background(), r, &fp); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	return fp.Network
+}
+
+func TestASNEnricher(t *testing.T) {
+	db, err := asn.Parse(strings.NewReader(asnCSV))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	e := asn.NewEnricher(db, nil)
+	if n := enrichASN(t, e, "5.9.100.1:4711"); n.ASN != 24940 || n.ASOrg != "Hetzner Online GmbH" || n.Hosting != "Hetzner" {
+		t.Errorf("Hetzner address: %+v", n)
+	}
+	if n := enrichASN(t, e, "84.150.3.4:4711"); n.ASN != 3320 || n.Hosting != "" {
+		t.Errorf("residential address: %+v", n)
+	}
+	if n := enrichASN(t, e, "10.1.2.3:4711"); n.ASN != 0 {
+		t.Errorf("private address looked up: %+v", n)
+	}
+
+	// The hosting list can be swapped while in use
+	l, err := asn.ParseHosting(strings.NewReader("AS3320 Telekom Cloud\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.SetHosting(l)
+	if n := enrichASN(t, e, "5.9.100.1:4711"); n.Hosting != "" {
+		t.Errorf("after SetHosting: Hetzner still hosting: %+v", n)
+	}
+	if n := enrichASN(t, e, "84.150.3.4:4711"); n.Hosting != "Telekom Cloud" {
+		t.Errorf("after SetHosting: %+v", n)
+	}
+}
+
+func TestExtractSignals_DatacenterASN(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP:    fingerprint.HTTPFingerprint{UserAgent: chromeUA},
+		Network: fingerprint.NetworkFingerprint{ASN: 24940, Hosting: "Hetzner"},
+	}
+	res := classifier.New().Classify(fp)
+	s := res.Signals
+	if !s.DatacenterASN || s.HostingProvider != "Hetzner" || !strings.Contains(s.ScoreBreakdown.String(), "datacenter(+2)") {
+		t.Errorf("DatacenterASN = %v, HostingProvider = %q: %s", s.DatacenterASN, s.HostingProvider, s.ScoreBreakdown)
+	}
+	if !strings.Contains(res.Reason, "datacenter network (Hetzner)") {
+		t.Errorf("reason %q lacks the hosting provider", res.Reason)
+	}
+
+	// Relay egress and verified crawlers run in datacenters legitimately
+	relay := fp
+	relay.Network.PrivateRelay = true
+	crawler := fp
+	crawler.Network.CrawlerVerification = fingerprint.CrawlerVerified
+	residential := fp
+	residential.Network.Hosting = ""
+	for name, fp := range map[string]fingerprint.Fingerprint{"relay": relay, "verified crawler": crawler, "residential": residential} {
+		if s := fingerprint.ExtractSignals(fp); s.DatacenterASN {
+			t.Errorf("%s: DatacenterASN = true", name)
+		}
+	}
+
+	// The weight is configurable
+	rules := fingerprint.DefaultRules()
+	rules.Weights = map[string]int{"datacenter": 5}
+	if s := fingerprint.ExtractSignalsWithRules(fp, rules); !strings.Contains(s.ScoreBreakdown.String(), "datacenter(+5)") {
+		t.Errorf("breakdown = %s", s.ScoreBreakdown)
+	}
+}
+
+func TestServer_ReloadHostingASNs(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "hosting.txt")
+	db, err := asn.Parse(strings.NewReader(asnCSV))
+	if err != nil {
+		t.Fatal(err)
+	}
+	opts := []server.Option{
+		server.WithLogger(logger.Config{LogDir: dir, FileName: "requests.jsonl"}),
+		server.WithASN(db),
+		server.WithHostingASNs(path),
+	}
+	if _, err := server.New(opts...); err == nil {
+		t.Fatal("New() with a missing hosting ASN file succeeded")
+	}
+
+	if err := os.WriteFile(path, []byte("AS24940 Hetzner\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv, err := server.New(opts...)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+	if err := srv.ReloadHostingASNs(); err != nil {
+		t.Errorf("ReloadHostingASNs() error = %v", err)
+	}
+	if err := os.WriteFile(path, []byte("not an asn\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := srv.ReloadHostingASNs(); err == nil {
+		t.Error("ReloadHostingASNs() of an invalid file succeeded")
+	}
+}
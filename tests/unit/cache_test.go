@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+// cachedClient returns a fingerprint with hashes, distinct per user agent
+func cachedClient(ua string) fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		TLS: fingerprint.TLSFingerprint{JA3Hash: "e7d705a3286e19ea42f587b344ee6865", JA4Hash: "t13d1516h2_8daaf6152771_02713d6af862"},
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			Method:      http.MethodGet,
+			UserAgent:   ua,
+			Accept:      "*/*",
+			HeaderCount: 3,
+			JA4HHash:    "ge11nn030000_a9a05b3f2d6e_000000000000_000000000000",
+		},
+	}
+}
+
+func TestVerdictKey(t *testing.T) {
+	fp := cachedClient("curl/8.0.1")
+	key, ok := fingerprint.VerdictKey(fp)
+	if !ok {
+		t.Fatal("VerdictKey() ok = false for a fingerprint with hashes")
+	}
+	if again, _ := fingerprint.VerdictKey(fp); again != key {
+		t.Error("VerdictKey() is not deterministic")
+	}
+
+	// Request details outside the hashes still tell clients apart
+	changes := map[string]func(*fingerprint.Fingerprint){
+		"user agent":      func(fp *fingerprint.Fingerprint) { fp.HTTP.UserAgent = "curl/8.1.0" },
+		"method":          func(fp *fingerprint.Fingerprint) { fp.HTTP.Method = http.MethodPost },
+		"private token":   func(fp *fingerprint.Fingerprint) { fp.HTTP.PrivateToken = "token" },
+		"challenge token": func(fp *fingerprint.Fingerprint) { fp.HTTP.ChallengeToken = "token" },
+		"private relay":   func(fp *fingerprint.Fingerprint) { fp.Network.PrivateRelay = true },
+		"ja4":             func(fp *fingerprint.Fingerprint) { fp.TLS.JA4Hash = "t13d1516h2_8daaf6152771_b0da82dd1658" },
+	}
+	for name, change := range changes {
+		changed := fp
+		change(&changed)
+		if k, _ := fingerprint.VerdictKey(changed); k == key {
+			t.Errorf("VerdictKey() ignores the %s", name)
+		}
+	}
+
+	if _, ok := fingerprint.VerdictKey(fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.0.1"}}); ok {
+		t.Error("VerdictKey() ok = true without hashes")
+	}
+}
+
+func TestVerdictCache_HitsAndMisses(t *testing.T) {
+	c := classifier.New(classifier.WithVerdictCache(10, 0))
+	cache := c.Cache()
+	if cache == nil {
+		t.Fatal("Cache() = nil with WithVerdictCache")
+	}
+	if cache.TTL() != classifier.DefaultCacheTTL {
+		t.Errorf("TTL() = %s, want %s", cache.TTL(), classifier.DefaultCacheTTL)
+	}
+
+	fp := cachedClient("curl/8.0.1")
+	first := c.Classify(fp)
+	second := c.Classify(fp)
+	if second.RequestID == "" || second.RequestID == first.RequestID {
+		t.Errorf("cached verdict RequestID = %q, want a new one (first %q)", second.RequestID, first.RequestID)
+	}
+	if second.Classification != first.Classification || second.Score != first.Score || second.Reason != first.Reason {
+		t.Errorf("cached verdict %+v differs from %+v", second, first)
+	}
+	if second.Fingerprint.HTTP.UserAgent != fp.HTTP.UserAgent {
+		t.Error("cached verdict lacks the request's fingerprint")
+	}
+
+	// Without hashes the cache is bypassed
+	c.Classify(fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.0.1"}})
+
+	st := cache.Stats()
+	if st.Hits != 1 || st.Misses != 1 || st.Bypassed != 1 || st.Size != 1 || st.Capacity != 10 {
+		t.Errorf("Stats() = %+v, want 1 hit, 1 miss, 1 bypass, 1 of 10 entries", st)
+	}
+
+	if classifier.New().Cache() != nil {
+		t.Error("Cache() != nil without WithVerdictCache")
+	}
+}
+
+func TestVerdictCache_TTL(t *testing.T) {
+	c := classifier.New(classifier.WithVerdictCache(10, 20*time.Millisecond))
+	fp := cachedClient("curl/8.0.1")
+	c.Classify(fp)
+	time.Sleep(40 * time.Millisecond)
+	c.Classify(fp)
+	if st := c.Cache().Stats(); st.Hits != 0 || st.Misses != 2 {
+		t.Errorf("Stats() after expiry = %+v, want 2 misses", st)
+	}
+}
+
+func TestVerdictCache_LRU(t *testing.T) {
+	c := classifier.New(classifier.WithVerdictCache(2, 0))
+	a, b, d := cachedClient("a/1.0"), cachedClient("b/1.0"), cachedClient("d/1.0")
+	c.Classify(a)
+	c.Classify(b)
+	c.Classify(a) // a is now the most recently used
+	c.Classify(d) // evicts b
+	c.Classify(a)
+	c.Classify(b)
+	if st := c.Cache().Stats(); st.Hits != 2 || st.Misses != 4 || st.Size != 2 {
+		t.Errorf("Stats() = %+v, want 2 hits, 4 misses, 2 entries", st)
+	}
+
+	c.Cache().SetCapacity(1)
+	if n := c.Cache().Len(); n != 1 {
+		t.Errorf("Len() after SetCapacity(1) = %d", n)
+	}
+}
+
+func TestVerdictCache_RuleChanges(t *testing.T) {
+	c := classifier.New(classifier.WithVerdictCache(10, 0))
+	fp := cachedClient("curl/8.0.1")
+	before := c.Classify(fp)
+
+	c.SetThreshold(before.Score)
+	if got := c.Classify(fp); got.Classification != classifier.ClassificationBrowser {
+		t.Errorf("Classify() after SetThreshold(%d) = %s, cached verdict reused", before.Score, got.Classification)
+	}
+
+	rules := c.Rules().Clone()
+	rules.Weights = map[string]int{"bot-ua": 10}
+	c.SetRules(rules)
+	if got := c.Classify(fp); got.Score == before.Score {
+		t.Error("Classify() after SetRules reused the cached score")
+	}
+	if st := c.Cache().Stats(); st.Hits != 0 {
+		t.Errorf("Stats().Hits = %d after rule changes, want 0", st.Hits)
+	}
+}
+
+func TestHandler_VerdictCacheMetrics(t *testing.T) {
+	cls := classifier.New(classifier.WithVerdictCache(10, 0))
+	h := server.NewHandler(fingerprint.NewCollector(), cls, nil)
+	h.SetQuiet(true)
+
+	for range 2 {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", "curl/8.0")
+		h.HandleClassify(httptest.NewRecorder(), req)
+	}
+
+	rr := httptest.NewRecorder()
+	h.HandleMetrics(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body := rr.Body.String()
+	for _, want := range []string{
+		"# TYPE classifier_verdict_cache_lookups_total counter",
+		`classifier_verdict_cache_lookups_total{result="hit"} 1`,
+		`classifier_verdict_cache_lookups_total{result="miss"} 1`,
+		"classifier_verdict_cache_entries 1",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+
+	rr = httptest.NewRecorder()
+	createTestHandler().HandleMetrics(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if strings.Contains(rr.Body.String(), "classifier_verdict_cache") {
+		t.Error("verdict cache metrics without a cache")
+	}
+}
@@ -0,0 +1,149 @@
+package unit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/calibration"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/evaluate"
+)
+
+func TestCalibrationParse(t *testing.T) {
+	c, err := calibration.Parse([]byte(`{"version":"v1","method":"isotonic","points":[{"raw":0.5,"calibrated":0.6},{"raw":0.9,"calibrated":1}]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for raw, want := range map[float64]float64{0.3: 0.6, 0.5: 0.6, 0.7: 0.8, 0.9: 1, 0.99: 1} {
+		if got := c.Apply(raw); math.Abs(got-want) > 1e-9 {
+			t.Errorf("isotonic Apply(%v) = %v, want %v", raw, got, want)
+		}
+	}
+
+	c, err = calibration.Parse([]byte(`{"method":"platt","a":4,"b":-2}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if got := c.Apply(0.5); math.Abs(got-0.5) > 1e-9 {
+		t.Errorf("platt Apply(0.5) = %v, want 0.5", got)
+	}
+
+	for name, data := range map[string]string{
+		"unknown method":         `{"method":"beta"}`,
+		"decreasing platt":       `{"method":"platt","a":-1}`,
+		"no points":              `{"method":"isotonic"}`,
+		"decreasing points":      `{"method":"isotonic","points":[{"raw":0.5,"calibrated":0.9},{"raw":0.9,"calibrated":0.6}]}`,
+		"unordered points":       `{"method":"isotonic","points":[{"raw":0.9,"calibrated":0.6},{"raw":0.5,"calibrated":0.9}]}`,
+		"probability out of 0-1": `{"method":"isotonic","points":[{"raw":0.5,"calibrated":1.5}]}`,
+	} {
+		if _, err := calibration.Parse([]byte(data)); err == nil {
+			t.Errorf("Parse(%s) error = nil", name)
+		}
+	}
+}
+
+// calibrationSamples returns samples right 60% of the time at confidence
+// 0.9 and 95% of the time at 0.6: an overconfident and an underconfident
+// level
+func calibrationSamples() []calibration.Sample {
+	var samples []calibration.Sample
+	for i := range 100 {
+		samples = append(samples,
+			calibration.Sample{Confidence: 0.9, Correct: i < 60},
+			calibration.Sample{Confidence: 0.6, Correct: i < 95},
+		)
+	}
+	return samples
+}
+
+func TestCalibrationFit(t *testing.T) {
+	samples := calibrationSamples()
+	before := calibration.ECE(samples, evaluate.CalibrationBins)
+	if math.Abs(before-0.325) > 1e-9 {
+		t.Errorf("ECE() = %v, want 0.325", before)
+	}
+
+	iso, err := calibration.Fit(samples, calibration.MethodIsotonic)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := iso.Validate(); err != nil {
+		t.Errorf("fitted isotonic calibration is invalid: %v", err)
+	}
+	// The violating levels are pooled into their joint accuracy
+	if got := iso.Apply(0.9); math.Abs(got-0.775) > 1e-9 {
+		t.Errorf("isotonic Apply(0.9) = %v, want 0.775", got)
+	}
+
+	if _, err := calibration.Fit(samples, calibration.MethodPlatt); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := calibration.Fit(nil, calibration.MethodPlatt); err == nil {
+		t.Error("Fit(no samples) error = nil")
+	}
+	if _, err := calibration.Fit(samples, "beta"); err == nil {
+		t.Error("Fit(unknown method) error = nil")
+	}
+
+	// Platt scaling of a monotone relation follows it without overshooting
+	var monotone []calibration.Sample
+	for i := range 200 {
+		monotone = append(monotone,
+			calibration.Sample{Confidence: 0.6, Correct: i%2 == 0},
+			calibration.Sample{Confidence: 0.95, Correct: i%20 != 0},
+		)
+	}
+	platt, err := calibration.Fit(monotone, calibration.MethodPlatt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lo, hi := platt.Apply(0.6), platt.Apply(0.95); math.Abs(lo-0.5) > 0.05 || math.Abs(hi-0.95) > 0.05 {
+		t.Errorf("platt Apply(0.6), Apply(0.95) = %.3f, %.3f, want about 0.5 and 0.95", lo, hi)
+	}
+	calibrated := make([]calibration.Sample, len(monotone))
+	for i, s := range monotone {
+		calibrated[i] = calibration.Sample{Confidence: platt.Apply(s.Confidence), Correct: s.Correct}
+	}
+	if calibration.Brier(calibrated) >= calibration.Brier(monotone) {
+		t.Error("platt calibration did not lower the Brier score")
+	}
+}
+
+func TestClassifierWithCalibration(t *testing.T) {
+	// Everything mapped to 0.7, then to the lower bound of the raw range
+	for cal, want := range map[*calibration.Calibration]float64{
+		{Method: calibration.MethodIsotonic, Points: []calibration.Point{{Raw: 0, Calibrated: 0.7}}}: 0.7,
+		{Method: calibration.MethodIsotonic, Points: []calibration.Point{{Raw: 0, Calibrated: 0.1}}}: 0.5,
+	} {
+		c := classifier.New(classifier.WithCalibration(cal))
+		if c.Calibration() != cal {
+			t.Error("Calibration() does not return the configured calibration")
+		}
+		for _, s := range evaluationSamples() {
+			raw := classifier.New().Classify(s.Fingerprint)
+			got := c.Classify(s.Fingerprint)
+			if got.Confidence != want {
+				t.Errorf("calibrated confidence = %v, want %v", got.Confidence, want)
+			}
+			if got.Classification != raw.Classification {
+				t.Errorf("calibration changed the classification from %s to %s", raw.Classification, got.Classification)
+			}
+		}
+	}
+}
+
+func TestEvaluateRun_Calibration(t *testing.T) {
+	samples := evaluationSamples()
+	raw := evaluate.Run(samples, classifier.DefaultConfig())
+	if raw.ECE <= 0 || raw.Brier <= 0 {
+		t.Errorf("ECE, Brier = %v, %v, want both above 0", raw.ECE, raw.Brier)
+	}
+
+	// Confidence equal to the accuracy (0.8, within the raw range) in a
+	// single bin is calibrated
+	cal := &calibration.Calibration{Method: calibration.MethodIsotonic, Points: []calibration.Point{{Raw: 0, Calibrated: raw.Accuracy}}}
+	report := evaluate.Run(samples, classifier.NewConfig(classifier.WithCalibration(cal)))
+	if report.ECE > 1e-9 {
+		t.Errorf("ECE with confidence at the accuracy = %v, want 0", report.ECE)
+	}
+}
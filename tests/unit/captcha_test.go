@@ -0,0 +1,304 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/challenge"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/session"
+)
+
+// newSiteverify serves a siteverify endpoint accepting the "ok" response
+func newSiteverify(t *testing.T) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil || r.PostForm.Get("secret") != "s3cret" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.PostForm.Get("response") == "ok" {
+			_, _ = w.Write([]byte(`{"success":true}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"success":false,"error-codes":["invalid-input-response"]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestCaptcha(t *testing.T, provider captcha.Provider, verifyURL string) *captcha.Verifier {
+	t.Helper()
+	v, err := captcha.New(captcha.Config{Provider: provider, SiteKey: "site", Secret: "s3cret", VerifyURL: verifyURL})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return v
+}
+
+func TestCaptcha_New(t *testing.T) {
+	if _, err := captcha.New(captcha.Config{Provider: "friendlycaptcha", SiteKey: "k", Secret: "s"}); err == nil {
+		t.Error("New() should reject unknown providers")
+	}
+	if _, err := captcha.New(captcha.Config{Provider: captcha.Turnstile}); err == nil {
+		t.Error("New() should require a site key and secret")
+	}
+	if got := newTestCaptcha(t, captcha.HCaptcha, "").ResponseField(); got != "h-captcha-response" {
+		t.Errorf("ResponseField() = %q", got)
+	}
+	if got := newTestCaptcha(t, captcha.ReCAPTCHA, "").ResponseField(); got != "g-recaptcha-response" {
+		t.Errorf("ResponseField(recaptcha) = %q", got)
+	}
+}
+
+// tokenVerifierFunc adapts a function to captcha.TokenVerifier
+type tokenVerifierFunc func(ctx context.Context, response, remoteIP string) error
+
+func (f tokenVerifierFunc) Verify(ctx context.Context, response, remoteIP string) error {
+	return f(ctx, response, remoteIP)
+}
+
+func TestCaptcha_PluggableProvider(t *testing.T) {
+	var gotIP string
+	verifier := tokenVerifierFunc(func(_ context.Context, response, remoteIP string) error {
+		gotIP = remoteIP
+		if response != "ok" {
+			return captcha.ErrRejected
+		}
+		return nil
+	})
+	widget := &captcha.Widget{ScriptURL: "https://captcha.example/widget.js", Class: "example-captcha", ResponseField: "example-response"}
+
+	if _, err := captcha.New(captcha.Config{Provider: "example", SiteKey: "site", Widget: widget}); err == nil {
+		t.Error("New() should require a token verifier for a provider that is not built in")
+	}
+	v, err := captcha.New(captcha.Config{Provider: "example", SiteKey: "site", Widget: widget, TokenVerifier: verifier})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if v.ResponseField() != "example-response" {
+		t.Errorf("ResponseField() = %q", v.ResponseField())
+	}
+	if err := v.Verify(context.Background(), "ok", "192.0.2.1"); err != nil || gotIP != "192.0.2.1" {
+		t.Errorf("Verify(ok) error = %v, remote IP = %q", err, gotIP)
+	}
+	if err := v.Verify(context.Background(), "forged", ""); !errors.Is(err, captcha.ErrRejected) {
+		t.Errorf("Verify(forged) error = %v", err)
+	}
+	var sb strings.Builder
+	if err := v.RenderPage(&sb, "/challenge", "/", ""); err != nil || !strings.Contains(sb.String(), `class="example-captcha"`) || !strings.Contains(sb.String(), "captcha.example/widget.js") {
+		t.Errorf("RenderPage() error = %v, page = %s", err, sb.String())
+	}
+
+	// A token verifier replaces siteverify for built-in providers too
+	enterprise, err := captcha.New(captcha.Config{Provider: captcha.ReCAPTCHA, SiteKey: "site", TokenVerifier: verifier})
+	if err != nil {
+		t.Fatalf("New(recaptcha with token verifier) error = %v", err)
+	}
+	if err := enterprise.Verify(context.Background(), "ok", ""); err != nil {
+		t.Errorf("Verify(ok) error = %v", err)
+	}
+}
+
+func TestCaptcha_Verify(t *testing.T) {
+	v := newTestCaptcha(t, captcha.Turnstile, newSiteverify(t).URL)
+	ctx := context.Background()
+
+	if err := v.Verify(ctx, "ok", "192.0.2.1"); err != nil {
+		t.Errorf("Verify(ok) error = %v", err)
+	}
+	if err := v.Verify(ctx, "forged", ""); !errors.Is(err, captcha.ErrRejected) || !strings.Contains(err.Error(), "invalid-input-response") {
+		t.Errorf("Verify(forged) error = %v, want ErrRejected with error codes", err)
+	}
+	if err := v.Verify(ctx, "", ""); !errors.Is(err, captcha.ErrMissingResponse) {
+		t.Errorf("Verify(empty) error = %v, want ErrMissingResponse", err)
+	}
+}
+
+func TestCaptcha_VerifyReCAPTCHA(t *testing.T) {
+	srv := newSiteverify(t)
+	v := newTestCaptcha(t, captcha.ReCAPTCHA, srv.URL)
+	if err := v.Verify(context.Background(), "ok", "192.0.2.1"); err != nil {
+		t.Errorf("Verify(ok) error = %v", err)
+	}
+	if err := v.Verify(context.Background(), "forged", ""); !errors.Is(err, captcha.ErrRejected) {
+		t.Errorf("Verify(forged) error = %v, want ErrRejected", err)
+	}
+
+	var sb strings.Builder
+	if err := v.RenderPage(&sb, "/challenge", "/", ""); err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+	for _, want := range []string{`class="g-recaptcha"`, "www.google.com/recaptcha/api.js"} {
+		if !strings.Contains(sb.String(), want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
+
+func TestCaptcha_RenderPage(t *testing.T) {
+	var sb strings.Builder
+	if err := newTestCaptcha(t, captcha.Turnstile, "").RenderPage(&sb, "/challenge", "/docs?q=<x>", ""); err != nil {
+		t.Fatalf("RenderPage() error = %v", err)
+	}
+	page := sb.String()
+	for _, want := range []string{`class="cf-turnstile"`, `data-sitekey="site"`, "challenges.cloudflare.com/turnstile", `action="/challenge"`, "&lt;x&gt;"} {
+		if !strings.Contains(page, want) {
+			t.Errorf("page missing %q", want)
+		}
+	}
+}
+
+func TestCaptcha_SafeReturn(t *testing.T) {
+	tests := map[string]string{
+		"/docs?page=2":        "/docs?page=2",
+		"":                    "/",
+		"https://evil.test/":  "/",
+		"//evil.test/":        "/",
+		"/\\evil.test":        "/",
+		"javascript:alert(1)": "/",
+	}
+	for in, want := range tests {
+		if got := captcha.SafeReturn(in); got != want {
+			t.Errorf("SafeReturn(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestCaptcha_Store(t *testing.T) {
+	s := captcha.NewStore(captcha.StoreConfig{TTL: time.Minute, MaxSessions: 2})
+	now := time.Now()
+
+	s.MarkVerified("a", now)
+	if !s.Verified("a", now.Add(30*time.Second)) {
+		t.Error("session should be verified within the TTL")
+	}
+	if s.Verified("a", now.Add(2*time.Minute)) {
+		t.Error("session should expire after the TTL")
+	}
+
+	s.MarkVerified("a", now)
+	s.MarkVerified("b", now.Add(time.Second))
+	s.MarkVerified("c", now.Add(2*time.Second))
+	if s.Len() != 2 || s.Verified("a", now.Add(3*time.Second)) {
+		t.Errorf("oldest session should be evicted, Len() = %d", s.Len())
+	}
+}
+
+func TestHandler_CaptchaFlow(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetCaptcha(newTestCaptcha(t, captcha.Turnstile, newSiteverify(t).URL), captcha.NewStore(captcha.DefaultStoreConfig()))
+	h.SetChallengeTokens(newTestSigner(t, time.Hour))
+
+	navigate := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/?ref=ad", nil)
+		req.Header.Set("User-Agent", "HeadlessChrome/120.0")
+		req.Header.Set("Accept", "text/html")
+		return req
+	}
+
+	// A bot navigating to a page is sent to the CAPTCHA
+	rr := httptest.NewRecorder()
+	h.HandleClassify(rr, navigate())
+	if rr.Code != http.StatusSeeOther {
+		t.Fatalf("status = %d, want 303", rr.Code)
+	}
+	loc, err := url.Parse(rr.Header().Get("Location"))
+	if err != nil || loc.Path != "/challenge" || loc.Query().Get("return") != "/?ref=ad" {
+		t.Fatalf("Location = %q", rr.Header().Get("Location"))
+	}
+
+	// API clients asking for JSON are not redirected
+	req := navigate()
+	req.Header.Set("Accept", "application/json")
+	rr = httptest.NewRecorder()
+	h.HandleClassify(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Errorf("JSON status = %d, want 200", rr.Code)
+	}
+
+	// The page renders the widget
+	rr = httptest.NewRecorder()
+	h.HandleCaptcha(rr, httptest.NewRequest(http.MethodGet, loc.String(), nil))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), "cf-turnstile") {
+		t.Fatalf("page status = %d", rr.Code)
+	}
+
+	// A rejected response re-renders the page
+	post := func(response string) *httptest.ResponseRecorder {
+		form := url.Values{"cf-turnstile-response": {response}, "return": {"/?ref=ad"}}
+		req := httptest.NewRequest(http.MethodPost, "/challenge", strings.NewReader(form.Encode()))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+		req.Header.Set("User-Agent", "HeadlessChrome/120.0")
+		rr := httptest.NewRecorder()
+		h.HandleCaptcha(rr, req)
+		return rr
+	}
+	if rr = post("forged"); rr.Code != http.StatusForbidden || !strings.Contains(rr.Body.String(), "role=\"alert\"") {
+		t.Fatalf("forged status = %d", rr.Code)
+	}
+
+	// A valid response verifies the session, hands out a challenge token
+	// and returns the client to where it was going
+	rr = post("ok")
+	if rr.Code != http.StatusSeeOther || rr.Header().Get("Location") != "/?ref=ad" {
+		t.Fatalf("verify status = %d, Location = %q", rr.Code, rr.Header().Get("Location"))
+	}
+	var issued bool
+	for _, c := range rr.Result().Cookies() {
+		issued = issued || c.Name == challenge.CookieName
+	}
+	if !issued {
+		t.Error("verified session should receive a challenge token")
+	}
+
+	// The verified session is not challenged again
+	rr = httptest.NewRecorder()
+	h.HandleClassify(rr, navigate())
+	if rr.Code == http.StatusSeeOther {
+		t.Error("verified session should bypass the CAPTCHA")
+	}
+}
+
+func TestHandler_CaptchaVerifiedSession(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	store := captcha.NewStore(captcha.DefaultStoreConfig())
+	h.SetCaptcha(newTestCaptcha(t, captcha.Turnstile, newSiteverify(t).URL), store)
+
+	debug := func() fingerprint.ClassificationResult {
+		req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+		req.Header.Set("User-Agent", chromeUA)
+		rr := httptest.NewRecorder()
+		h.HandleDebug(rr, req)
+		var result fingerprint.ClassificationResult
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return result
+	}
+
+	if res := debug(); res.Fingerprint.Session.HumanVerified || res.Signals.CaptchaSolved {
+		t.Error("unverified session marked human-verified")
+	}
+	req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+	req.Header.Set("User-Agent", chromeUA)
+	store.MarkVerified(session.Key(req), time.Now())
+	res := debug()
+	if !res.Fingerprint.Session.HumanVerified || !res.Signals.CaptchaSolved {
+		t.Fatalf("verified session: HumanVerified = %v, CaptchaSolved = %v", res.Fingerprint.Session.HumanVerified, res.Signals.CaptchaSolved)
+	}
+	if !strings.Contains(res.Signals.ScoreBreakdown.String(), "captcha-pass(+6)") {
+		t.Errorf("breakdown lacks captcha-pass: %s", res.Signals.ScoreBreakdown)
+	}
+}
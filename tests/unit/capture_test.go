@@ -0,0 +1,182 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/capture"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+func newTestCapturer(t *testing.T, cfg capture.Config) *capture.Capturer {
+	t.Helper()
+	c, err := capture.New(cfg)
+	if err != nil {
+		t.Fatalf("capture.New() error = %v", err)
+	}
+	t.Cleanup(func() { _ = c.Close() })
+	return c
+}
+
+func TestCapture_ConfigErrors(t *testing.T) {
+	for _, cfg := range []capture.Config{
+		{},
+		{Path: filepath.Join(t.TempDir(), "c.jsonl"), SampleRate: 1.5},
+		{Path: filepath.Join(t.TempDir(), "c.jsonl"), SampleRate: -0.1},
+	} {
+		if _, err := capture.New(cfg); err == nil {
+			t.Errorf("New(%+v) should fail", cfg)
+		}
+	}
+}
+
+func TestCapture_Sample(t *testing.T) {
+	c := newTestCapturer(t, capture.Config{Path: filepath.Join(t.TempDir(), "c.jsonl"), SampleRate: 1})
+	if c.Sample() {
+		t.Error("disabled capturer sampled a request")
+	}
+	c.SetEnabled(true)
+	if !c.Sample() {
+		t.Error("rate 1 should sample every request")
+	}
+	if err := c.SetSampleRate(0); err != nil {
+		t.Fatal(err)
+	}
+	for range 100 {
+		if c.Sample() {
+			t.Fatal("rate 0 sampled a request")
+		}
+	}
+	if err := c.SetSampleRate(2); err == nil {
+		t.Error("SetSampleRate(2) should fail")
+	}
+}
+
+func TestHandler_Capture(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "capture.jsonl")
+	c := newTestCapturer(t, capture.Config{Path: path, Enabled: true, SampleRate: 1, Raw: true})
+
+	// The request log is minimal; captures keep the full fingerprint
+	l, err := logger.New(logger.Config{LogDir: dir, FileName: "requests.jsonl", Profile: logger.ProfileMinimal})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	h := server.NewHandler(fingerprint.NewCollector(), classifier.New(classifier.DefaultConfig()), l)
+	h.SetQuiet(true)
+	h.SetCapture(c)
+
+	r := httptest.NewRequest(http.MethodGet, "/?q=1", nil)
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.Header.Set("Cookie", "a=b")
+	h.HandleClassify(httptest.NewRecorder(), r)
+
+	// Remote fingerprints are captured without a raw request
+	body := strings.NewReader(`{"http":{"user_agent":"python-requests/2.31"}}`)
+	h.HandleClassifyFingerprint(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/classify/fingerprint", body))
+
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+
+	// Capture files are request logs
+	reader := logger.NewReader(f)
+	entry, err := reader.Next()
+	if err != nil {
+		t.Fatalf("capture file is not a request log: %v", err)
+	}
+	if entry.Fingerprint.HTTP.UserAgent != "curl/8.0" {
+		t.Errorf("captured User-Agent = %q, want the full fingerprint", entry.Fingerprint.HTTP.UserAgent)
+	}
+
+	data, _ := os.ReadFile(path)
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("captured %d records, want 2", len(lines))
+	}
+	var first, second capture.Record
+	if err := json.Unmarshal([]byte(lines[0]), &first); err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal([]byte(lines[1]), &second); err != nil {
+		t.Fatal(err)
+	}
+	if first.Request == nil || first.Request.Path != "/?q=1" || first.Request.Headers["Cookie"] == nil {
+		t.Errorf("raw request = %+v", first.Request)
+	}
+	if second.Request != nil {
+		t.Errorf("fingerprint capture has a raw request: %+v", second.Request)
+	}
+	if c.State().Captured != 2 {
+		t.Errorf("Captured = %d, want 2", c.State().Captured)
+	}
+}
+
+func TestRouter_AdminCapture(t *testing.T) {
+	c := newTestCapturer(t, capture.Config{Path: filepath.Join(t.TempDir(), "c.jsonl"), SampleRate: 0.1})
+	h := createTestHandler()
+	h.SetCapture(c)
+	h.SetAdminToken("s3cret")
+	router := server.NewRouter(h, newTestValidator(t), false)
+
+	do := func(method, token, body string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(method, "/v1/admin/capture", strings.NewReader(body))
+		if body != "" {
+			r.Header.Set("Content-Type", "application/json")
+		}
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	if w := do(http.MethodGet, "", ""); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), server.CodeUnauthorized) {
+		t.Errorf("no token: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if w := do(http.MethodGet, "wrong", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status = %d", w.Code)
+	}
+
+	w := do(http.MethodPut, "s3cret", `{"enabled":true,"sample_rate":0.5}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("PUT status = %d, body = %s", w.Code, w.Body.String())
+	}
+	var st capture.State
+	if err := json.NewDecoder(w.Body).Decode(&st); err != nil {
+		t.Fatal(err)
+	}
+	if !st.Enabled || st.SampleRate != 0.5 || st.Raw {
+		t.Errorf("state = %+v", st)
+	}
+
+	if w := do(http.MethodPut, "s3cret", `{"sample_rate":3}`); w.Code != http.StatusBadRequest {
+		t.Errorf("invalid rate: status = %d", w.Code)
+	}
+	if w := do(http.MethodDelete, "s3cret", ""); w.Code != http.StatusMethodNotAllowed {
+		t.Errorf("DELETE: status = %d", w.Code)
+	}
+
+	// Without a token the endpoint is not served
+	h2 := createTestHandler()
+	h2.SetCapture(c)
+	r := httptest.NewRequest(http.MethodGet, "/v1/admin/capture", nil)
+	rr := httptest.NewRecorder()
+	server.NewRouter(h2, nil, false).ServeHTTP(rr, r)
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("no admin token: status = %d, want 404", rr.Code)
+	}
+}
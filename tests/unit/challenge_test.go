@@ -0,0 +1,197 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/challenge"
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/privatetoken"
+)
+
+var _ classifier.Enricher = (*challenge.Signer)(nil)
+
+const testSafariUA = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Version/17.0 Mobile/15E148 Safari/604.1"
+
+func newTestSigner(t *testing.T, ttl time.Duration) *challenge.Signer {
+	t.Helper()
+	s, err := challenge.NewSigner(challenge.Config{Key: []byte("0123456789abcdef0123456789abcdef"), TTL: ttl})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+	return s
+}
+
+func TestChallenge_NewSigner(t *testing.T) {
+	if _, err := challenge.NewSigner(challenge.Config{Key: []byte("short")}); err == nil {
+		t.Error("NewSigner() should reject short keys")
+	}
+	if _, err := challenge.NewSigner(challenge.Config{Key: make([]byte, 16), TTL: -time.Second}); err == nil {
+		t.Error("NewSigner() should reject a negative TTL")
+	}
+	if ttl := newTestSigner(t, 0).TTL(); ttl != challenge.DefaultTTL {
+		t.Errorf("TTL() = %s, want %s", ttl, challenge.DefaultTTL)
+	}
+}
+
+func TestChallenge_Verify(t *testing.T) {
+	s := newTestSigner(t, time.Minute)
+	now := time.Unix(1700000000, 0)
+	fp := fingerprint.Fingerprint{
+		TLS:  fingerprint.TLSFingerprint{JA4Hash: "t13d1516h2_8daaf6152771_e5627efa2ab1"},
+		HTTP: fingerprint.HTTPFingerprint{UserAgent: testSafariUA},
+	}
+	token := s.Issue(fp, now)
+
+	if err := s.Verify(token, fp, now.Add(30*time.Second)); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+
+	// The binding covers the TLS client and User-Agent, not cookies
+	withCookies := fp
+	withCookies.HTTP.HasCookies = true
+	withCookies.HTTP.JA4HHash = "ge11cr0800_abc"
+	if err := s.Verify(token, withCookies, now); err != nil {
+		t.Errorf("Verify() with cookies error = %v", err)
+	}
+
+	otherTLS := fp
+	otherTLS.TLS.JA4Hash = "t13d0910h1_aaaaaaaaaaaa_bbbbbbbbbbbb"
+	otherUA := fp
+	otherUA.HTTP.UserAgent = "python-requests/2.31"
+	other, err := challenge.NewSigner(challenge.Config{Key: []byte("another-key-of-32-bytes-length!!")})
+	if err != nil {
+		t.Fatalf("NewSigner() error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		fp    fingerprint.Fingerprint
+		at    time.Time
+		want  error
+	}{
+		{"malformed", "not-a-token", fp, now, challenge.ErrMalformed},
+		{"truncated", token[:20], fp, now, challenge.ErrMalformed},
+		{"wrong key", other.Issue(fp, now), fp, now, challenge.ErrBadSignature},
+		{"expired", token, fp, now.Add(time.Minute), challenge.ErrExpired},
+		{"other TLS client", token, otherTLS, now, challenge.ErrBindingMismatch},
+		{"other User-Agent", token, otherUA, now, challenge.ErrBindingMismatch},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := s.Verify(tt.token, tt.fp, tt.at); !errors.Is(err, tt.want) {
+				t.Errorf("Verify() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestChallenge_EnrichAndScore(t *testing.T) {
+	s := newTestSigner(t, time.Minute)
+	collector := fingerprint.NewCollector()
+
+	newRequest := func() *http.Request {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set("User-Agent", testSafariUA)
+		return r
+	}
+
+	// Absent: no token, no signal
+	r := newRequest()
+	fp := collector.Collect(r)
+	_ = s.Enrich(context.Background(), r, &fp)
+	if fp.HTTP.ChallengeToken != "" {
+		t.Errorf("ChallengeToken = %q, want empty", fp.HTTP.ChallengeToken)
+	}
+
+	// Pass: token in the cookie, bound to this client
+	token := s.Issue(fp, time.Now())
+	r = newRequest()
+	r.AddCookie(s.Cookie(token))
+	fp = collector.Collect(r)
+	if err := s.Enrich(context.Background(), r, &fp); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if fp.HTTP.ChallengeToken != fingerprint.ChallengeTokenPass {
+		t.Fatalf("ChallengeToken = %q, want pass", fp.HTTP.ChallengeToken)
+	}
+	sig := fingerprint.ExtractSignals(fp)
+	if !sig.ChallengeTokenPassed || !strings.Contains(sig.ScoreBreakdown.String(), "challenge-pass(+6)") {
+		t.Errorf("signals missing challenge-pass: %s", sig.ScoreBreakdown)
+	}
+
+	// Fail: the same token presented in the header by another client
+	r = newRequest()
+	r.Header.Set("User-Agent", "curl/8.0")
+	r.Header.Set(challenge.Header, token)
+	fp = collector.Collect(r)
+	_ = s.Enrich(context.Background(), r, &fp)
+	if fp.HTTP.ChallengeToken != fingerprint.ChallengeTokenFail {
+		t.Fatalf("ChallengeToken = %q, want fail", fp.HTTP.ChallengeToken)
+	}
+	sig = fingerprint.ExtractSignals(fp)
+	if sig.ChallengeTokenPassed || !sig.ChallengeTokenFailed || !strings.Contains(sig.ScoreBreakdown.String(), "challenge-fail(+4)") {
+		t.Errorf("signals missing challenge-fail: %s", sig.ScoreBreakdown)
+	}
+}
+
+func TestHandler_ChallengeTokenRoundtrip(t *testing.T) {
+	key, iss := newTestIssuer(t)
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetPrivateTokens(newTestVerifier(t))
+	h.SetChallengeTokens(newTestSigner(t, time.Hour))
+
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("User-Agent", testSafariUA)
+		req.Header.Set("Accept", "text/html")
+		req.Header.Set("Accept-Encoding", "gzip")
+		return req
+	}
+
+	// Without attestation the client is challenged
+	rr := httptest.NewRecorder()
+	h.HandleClassify(rr, newRequest())
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rr.Code)
+	}
+
+	// Redeeming a Private Access Token earns a challenge token cookie
+	tok := issueToken(t, key, iss, privatetoken.Challenge{TokenType: privatetoken.TypeBlindRSA, IssuerName: testIssuerName})
+	req := newRequest()
+	req.Header.Set("Authorization", tok.Authorization())
+	rr = httptest.NewRecorder()
+	h.HandleClassify(rr, req)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", rr.Code)
+	}
+	var cookie *http.Cookie
+	for _, c := range rr.Result().Cookies() {
+		if c.Name == challenge.CookieName {
+			cookie = c
+		}
+	}
+	if cookie == nil || !cookie.HttpOnly || !cookie.Secure {
+		t.Fatalf("challenge cookie = %+v", cookie)
+	}
+
+	// The cookie alone passes on the next request, without a new token
+	req = newRequest()
+	req.AddCookie(cookie)
+	rr = httptest.NewRecorder()
+	h.HandleClassify(rr, req)
+	if rr.Code != http.StatusOK || rr.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("status = %d, WWW-Authenticate = %q; want 200 without challenge", rr.Code, rr.Header().Get("WWW-Authenticate"))
+	}
+	if len(rr.Result().Cookies()) != 0 {
+		t.Error("a passing challenge token should not be reissued")
+	}
+}
@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+func TestParseChromeVersion(t *testing.T) {
+	const fullList = `"Chromium";v="124.0.6367.91", "Google Chrome";v="124.0.6367.91", "Not-A.Brand";v="99.0.0.0"`
+
+	tests := []struct {
+		name     string
+		ua       string
+		fullList string
+		want     fingerprint.ChromeVersion
+		ok       bool
+	}{
+		{
+			name: "reduced windows",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			want: fingerprint.ChromeVersion{Major: 124, Full: "124.0.0.0", Reduced: true, FrozenPlatform: true},
+			ok:   true,
+		},
+		{
+			name:     "reduced with full version list",
+			ua:       "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			fullList: fullList,
+			want:     fingerprint.ChromeVersion{Major: 124, Full: "124.0.6367.91", Reduced: true, FrozenPlatform: true, FromClientHints: true},
+			ok:       true,
+		},
+		{
+			name: "reduced android",
+			ua:   "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Mobile Safari/537.36",
+			want: fingerprint.ChromeVersion{Major: 131, Full: "131.0.0.0", Reduced: true, FrozenPlatform: true},
+			ok:   true,
+		},
+		{
+			name: "legacy full version",
+			ua:   "Mozilla/5.0 (Windows NT 6.1; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/90.0.4430.212 Safari/537.36",
+			want: fingerprint.ChromeVersion{Major: 90, Full: "90.0.4430.212"},
+			ok:   true,
+		},
+		{
+			name:     "chromium brand only",
+			ua:       "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/125.0.0.0 Safari/537.36",
+			fullList: `"Not/A)Brand";v="8.0.0.0", "Chromium";v="125.0.6422.60"`,
+			want:     fingerprint.ChromeVersion{Major: 125, Full: "125.0.6422.60", Reduced: true, FrozenPlatform: true, FromClientHints: true},
+			ok:       true,
+		},
+		{
+			name: "firefox",
+			ua:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0",
+			ok:   false,
+		},
+		{
+			name: "garbage version",
+			ua:   "Chrome/abc",
+			ok:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := fingerprint.ParseChromeVersion(tt.ua, tt.fullList)
+			if ok != tt.ok {
+				t.Fatalf("ParseChromeVersion() ok = %v, want %v", ok, tt.ok)
+			}
+			if got != tt.want {
+				t.Errorf("ParseChromeVersion() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHTTPFingerprint_ChromeVersion(t *testing.T) {
+	h := fingerprint.HTTPFingerprint{
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/130.0.0.0 Safari/537.36",
+		Headers:   map[string]string{"sec-ch-ua-full-version-list": `"Google Chrome";v="130.0.6723.117"`},
+	}
+	v, ok := h.ChromeVersion()
+	if !ok || v.Full != "130.0.6723.117" || !v.FromClientHints {
+		t.Errorf("ChromeVersion() = %+v, %v", v, ok)
+	}
+}
+
+func TestParseBrandList(t *testing.T) {
+	got := fingerprint.ParseBrandList(`"Not;A=Brand";v="24", "Chromium";v="128", "Google Chrome";v="128"`)
+	want := []fingerprint.BrandVersion{{Brand: "Chromium", Version: "128"}, {Brand: "Google Chrome", Version: "128"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseBrandList() = %+v, want %+v", got, want)
+	}
+	if got := fingerprint.ParseBrandList(""); len(got) != 0 {
+		t.Errorf("ParseBrandList(\"\") = %+v, want empty", got)
+	}
+}
+
+func TestGolden_ChromeUAReduced(t *testing.T) {
+	corpus, err := fingerprint.GoldenFamily(fingerprint.GoldenBrowser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, g := range corpus {
+		ua := g.Fingerprint.HTTP.UserAgent
+		if !strings.Contains(ua, "Chrome/") {
+			continue
+		}
+		v, ok := g.Fingerprint.HTTP.ChromeVersion()
+		if !ok {
+			t.Errorf("%s: Chrome UA not parsed: %s", g.ID, ua)
+			continue
+		}
+		if v.Major >= 113 && !v.Reduced {
+			t.Errorf("%s: Chrome %d UA should be reduced: %s", g.ID, v.Major, ua)
+		}
+	}
+}
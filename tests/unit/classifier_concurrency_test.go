@@ -0,0 +1,131 @@
+package unit
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// These tests are meant to run under the race detector (task test:race)
+
+func curlFingerprint() fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			HeaderCount: 3,
+		},
+	}
+}
+
+// lenientRules drops the bot patterns and the low header count penalty
+func lenientRules() fingerprint.Rules {
+	rules := fingerprint.DefaultRules()
+	rules.BotPatterns = nil
+	rules.Weights = map[string]int{"low-headers": 0}
+	return rules
+}
+
+func TestClassifier_SetRules(t *testing.T) {
+	c := classifier.New()
+	fp := curlFingerprint()
+	if !c.Classify(fp).Signals.UserAgentIsBot {
+		t.Fatal("curl should match the default bot patterns")
+	}
+
+	rules := lenientRules()
+	c.SetRules(rules)
+	if c.Classify(fp).Signals.UserAgentIsBot {
+		t.Error("curl matched bot patterns after they were removed")
+	}
+
+	// The classifier keeps its own copy
+	rules.BotPatterns = append(rules.BotPatterns, "curl")
+	rules.Weights["low-headers"] = 5
+	if c.Classify(fp).Signals.UserAgentIsBot {
+		t.Error("changing the rules after SetRules affected the classifier")
+	}
+	got := c.Rules()
+	got.Weights["low-headers"] = 7
+	if c.Rules().Weights["low-headers"] != 0 {
+		t.Error("changing the rules returned by Rules affected the classifier")
+	}
+}
+
+func TestClassifier_SetThreshold(t *testing.T) {
+	c := classifier.New()
+	fp := curlFingerprint()
+	score := c.Classify(fp).Score
+
+	c.SetThreshold(score)
+	if c.Threshold() != score {
+		t.Errorf("Threshold() = %d, want %d", c.Threshold(), score)
+	}
+	if got := c.Classify(fp).Classification; got != classifier.ClassificationBrowser {
+		t.Errorf("score %d at threshold %d classified as %s", score, score, got)
+	}
+}
+
+func TestClassifier_ConcurrentRuleSwaps(t *testing.T) {
+	fp := curlFingerprint()
+	strict, lenient := fingerprint.DefaultRules(), lenientRules()
+	valid := map[int]bool{
+		classifier.New(classifier.WithRules(strict)).Classify(fp).Score:  true,
+		classifier.New(classifier.WithRules(lenient)).Classify(fp).Score: true,
+	}
+	if len(valid) != 2 {
+		t.Fatal("rule sets should score the fingerprint differently")
+	}
+
+	c := classifier.New()
+	done := make(chan struct{})
+	var writers sync.WaitGroup
+	writers.Go(func() {
+		for i := 0; ; i++ {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			if i%2 == 0 {
+				c.SetRules(lenient)
+			} else {
+				c.SetRules(strict)
+			}
+		}
+	})
+
+	// Every request sees one whole rule set, never a mix of both
+	var readers sync.WaitGroup
+	for range 8 {
+		readers.Go(func() {
+			for range 500 {
+				result := c.Classify(fp)
+				if !valid[result.Score] {
+					t.Errorf("score %d matches neither rule set (%s)", result.Score, result.Signals.ScoreBreakdown)
+					return
+				}
+				_ = c.Rules()
+			}
+		})
+	}
+	readers.Wait()
+	close(done)
+	writers.Wait()
+}
+
+func TestClassifier_ConcurrentUpdatesAreNotLost(t *testing.T) {
+	for range 100 {
+		c := classifier.New()
+		var wg sync.WaitGroup
+		wg.Go(func() { c.SetRules(lenientRules()) })
+		wg.Go(func() { c.SetThreshold(7) })
+		wg.Wait()
+
+		if c.Threshold() != 7 || c.Rules().BotPatterns != nil {
+			t.Fatalf("concurrent updates lost a change: threshold %d, %d bot patterns", c.Threshold(), len(c.Rules().BotPatterns))
+		}
+	}
+}
@@ -1,8 +1,15 @@
 package unit
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/muliwe/go-client-classifier/internal/classifier"
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
@@ -23,6 +30,45 @@ func TestClassifierNew(t *testing.T) {
 	}
 }
 
+func TestClassifierNewConfig_Options(t *testing.T) {
+	tests := []struct {
+		name string
+		opts []classifier.Option
+		want int
+	}{
+		{"defaults", nil, 0},
+		{"with threshold", []classifier.Option{classifier.WithThreshold(3)}, 3},
+		{"config", []classifier.Option{classifier.Config{Threshold: 5}}, 5},
+		{"option after config", []classifier.Option{classifier.Config{Threshold: 5}, classifier.WithThreshold(-2)}, -2},
+		{"config after option", []classifier.Option{classifier.WithThreshold(-2), classifier.Config{Threshold: 5}}, 5},
+		{"nil option", []classifier.Option{nil, classifier.WithThreshold(1)}, 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifier.NewConfig(tt.opts...).Threshold; got != tt.want {
+				t.Errorf("NewConfig().Threshold = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClassifierNew_WithThreshold(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			HeaderCount: 3,
+		},
+	}
+
+	net := classifier.New().Classify(fp).Score
+	result := classifier.New(classifier.WithThreshold(net)).Classify(fp)
+	if result.Classification != classifier.ClassificationBrowser {
+		t.Errorf("Classify() with threshold %d = %s, want %s", net, result.Classification, classifier.ClassificationBrowser)
+	}
+}
+
 func TestClassify_CurlBot(t *testing.T) {
 	c := classifier.New(classifier.DefaultConfig())
 
@@ -112,6 +158,78 @@ func TestClassify_AICrawler(t *testing.T) {
 	}
 }
 
+func TestClassify_Class(t *testing.T) {
+	c := classifier.New()
+	bot := func(ua string) fingerprint.Fingerprint {
+		return fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{Version: "HTTP/1.1", UserAgent: ua, Accept: "*/*", HeaderCount: 3}}
+	}
+
+	tests := []struct {
+		name string
+		fp   fingerprint.Fingerprint
+		want string
+	}{
+		{"curl", bot("curl/8.0.1"), classifier.ClassHTTPLibrary},
+		{"python-requests", bot("python-requests/2.31.0"), classifier.ClassHTTPLibrary},
+		{"googlebot", bot("Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"), classifier.ClassSearchBot},
+		{"gptbot", bot("Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)"), classifier.ClassAICrawler},
+		{"perplexity searches", bot("Mozilla/5.0 (compatible; PerplexityBot/1.0)"), classifier.ClassSearchBot},
+		{"undocumented AI crawler", bot("Mozilla/5.0 (compatible; Google-Extended)"), classifier.ClassAICrawler},
+		{"headless chrome", bot("Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) HeadlessChrome/120.0.0.0 Safari/537.36"), classifier.ClassHeadlessBrowser},
+		{"generic bot", bot("acme-monitor-bot/1.0"), classifier.ClassUnknown},
+		{"no user agent", bot(""), classifier.ClassUnknown},
+	}
+	for _, tt := range tests {
+		result := c.Classify(tt.fp)
+		if result.Classification != classifier.ClassificationBot {
+			t.Errorf("%s: Classification = %s, want bot", tt.name, result.Classification)
+		}
+		if result.Class != tt.want {
+			t.Errorf("%s: Class = %s, want %s", tt.name, result.Class, tt.want)
+		}
+		checkClassConfidence(t, tt.name, result)
+	}
+
+	for _, g := range mustGolden(t) {
+		result := c.Classify(g.Fingerprint)
+		if result.Classification == classifier.ClassificationBrowser && result.Class != classifier.ClassBrowser {
+			t.Errorf("%s: browser decision with class %s", g.ID, result.Class)
+		}
+		if g.Family == fingerprint.GoldenAICrawler && result.Class != classifier.ClassAICrawler && result.Class != classifier.ClassSearchBot {
+			t.Errorf("%s: Class = %s, want a crawler class", g.ID, result.Class)
+		}
+		if g.Family == fingerprint.GoldenLibrary && result.Classification == classifier.ClassificationBot && result.Class != classifier.ClassHTTPLibrary {
+			t.Errorf("%s: Class = %s, want %s", g.ID, result.Class, classifier.ClassHTTPLibrary)
+		}
+		checkClassConfidence(t, g.ID, result)
+	}
+}
+
+// checkClassConfidence checks that every class has a confidence, that they
+// sum to 1 and that the reported class is the likeliest bot class
+func checkClassConfidence(t *testing.T, name string, result fingerprint.ClassificationResult) {
+	t.Helper()
+	sum := 0.0
+	for _, class := range classifier.Classes {
+		p, ok := result.ClassConfidence[class]
+		if !ok {
+			t.Errorf("%s: no confidence for %s", name, class)
+		}
+		sum += p
+	}
+	if len(result.ClassConfidence) != len(classifier.Classes) || sum < 0.999 || sum > 1.001 {
+		t.Errorf("%s: ClassConfidence = %v, sum %v", name, result.ClassConfidence, sum)
+	}
+	if result.ClassConfidence[result.Class] < 0.5 && result.Class == classifier.ClassBrowser {
+		t.Errorf("%s: browser class with confidence %v", name, result.ClassConfidence[result.Class])
+	}
+	for class, p := range result.ClassConfidence {
+		if class != classifier.ClassBrowser && p > result.ClassConfidence[result.Class] && result.Class != classifier.ClassBrowser {
+			t.Errorf("%s: %s (%v) is likelier than the reported %s", name, class, p, result.Class)
+		}
+	}
+}
+
 func TestClassify_JA4HSignals(t *testing.T) {
 	c := classifier.New(classifier.DefaultConfig())
 
@@ -175,7 +293,428 @@ func TestClassify_ReasonContainsJA4H(t *testing.T) {
 	result := c.Classify(fp)
 
 	// Should mention JA4H in breakdown
-	if !strings.Contains(result.Signals.ScoreBreakdown, "ja4h") {
+	if !strings.Contains(result.Signals.ScoreBreakdown.String(), "ja4h") {
 		t.Errorf("Score breakdown should mention JA4H, got: %s", result.Signals.ScoreBreakdown)
 	}
 }
+
+func TestClassify_MarginAndFlipSet(t *testing.T) {
+	fps := map[string]fingerprint.Fingerprint{
+		"curl": {HTTP: fingerprint.HTTPFingerprint{Version: "HTTP/1.1", UserAgent: "curl/8.0.1", Accept: "*/*", HeaderCount: 3}},
+		"browser": {HTTP: fingerprint.HTTPFingerprint{
+			Version:      "HTTP/2.0",
+			UserAgent:    "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+			Accept:       "text/html",
+			AcceptLang:   "en-US",
+			AcceptEnc:    "gzip, br",
+			SecFetchSite: "none",
+			SecFetchMode: "navigate",
+			SecChUA:      `"Chromium";v="129"`,
+			HeaderCount:  8,
+		}},
+	}
+	tests := []struct {
+		fp        string
+		threshold int
+		want      []string // nil = cannot flip
+	}{
+		{"curl", 0, []string{"bot-ua", "low-headers", "missing-typical", "http1.1", "accept-*/*-", "no-accept-lang"}},
+		{"curl", -3, []string{"bot-ua", "low-headers", "missing-typical"}},
+		{"curl", 5, nil}, // All bot rules together weigh less than the margin
+		{"browser", 10, []string{"sec-fetch"}},
+		{"browser", 5, []string{"sec-fetch", "http2", "browser-ua"}},
+		{"browser", 0, nil}, // Still browser at 0 without any browser rule
+	}
+	for _, tt := range tests {
+		fp := fps[tt.fp]
+		result := classifier.New(classifier.WithThreshold(tt.threshold)).Classify(fp)
+		if result.Margin != result.Score-tt.threshold {
+			t.Errorf("%s/%d: margin = %d, score = %d", tt.fp, tt.threshold, result.Margin, result.Score)
+		}
+		if !slices.Equal(result.FlipSet, tt.want) {
+			t.Errorf("%s/%d: flip set = %v, want %v (%s)", tt.fp, tt.threshold, result.FlipSet, tt.want, result.Signals.ScoreBreakdown)
+			continue
+		}
+		if tt.want == nil {
+			continue
+		}
+
+		// Disabling the flip set flips the decision; one rule less does not
+		flipped := func(disabled []string) bool {
+			rules := fingerprint.DefaultRules()
+			rules.Weights = map[string]int{}
+			for _, r := range disabled {
+				rules.Weights[r] = 0
+			}
+			c := classifier.New(classifier.WithThreshold(tt.threshold), classifier.WithRules(rules))
+			return c.Classify(fp).Classification != result.Classification
+		}
+		if !flipped(result.FlipSet) {
+			t.Errorf("%s/%d: removing %v does not flip %s", tt.fp, tt.threshold, result.FlipSet, result.Classification)
+		}
+		if flipped(result.FlipSet[:len(result.FlipSet)-1]) {
+			t.Errorf("%s/%d: flip set %v is not minimal", tt.fp, tt.threshold, result.FlipSet)
+		}
+	}
+}
+
+// testEnricher is a configurable classifier.Enricher
+type testEnricher struct {
+	name  string
+	delay time.Duration
+	err   error
+	ua    string // User-Agent written into the fingerprint
+}
+
+func (e testEnricher) Name() string { return e.name }
+
+func (e testEnricher) Enrich(ctx context.Context, _ *http.Request, fp *fingerprint.Fingerprint) error {
+	fp.HTTP.UserAgent = e.ua
+	if e.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(e.delay):
+		}
+	}
+	return e.err
+}
+
+func TestClassifyRequest_NoEnrichers(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", "curl/8.0.1")
+
+	result := classifier.New().ClassifyRequest(context.Background(), req)
+
+	if result.Classification != classifier.ClassificationBot {
+		t.Errorf("ClassifyRequest(curl) = %s, want %s", result.Classification, classifier.ClassificationBot)
+	}
+	if result.Partial || len(result.Incomplete) != 0 {
+		t.Errorf("ClassifyRequest() partial = %v %v, want complete", result.Partial, result.Incomplete)
+	}
+	if result.Fingerprint.HTTP.UserAgent != "curl/8.0.1" {
+		t.Errorf("ClassifyRequest() UserAgent = %q, want collected value", result.Fingerprint.HTTP.UserAgent)
+	}
+}
+
+func TestClassifyRequest_PartialOnTimeout(t *testing.T) {
+	c := classifier.New(
+		classifier.WithEnrichmentTimeout(20*time.Millisecond),
+		classifier.WithEnrichers(
+			testEnricher{name: "fast", ua: "enriched"},
+			testEnricher{name: "slow", delay: time.Second, ua: "half-written"},
+			testEnricher{name: "after"},
+		),
+	)
+
+	start := time.Now()
+	result := c.ClassifyRequest(context.Background(), httptest.NewRequest("GET", "/", nil))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("ClassifyRequest() took %v, want enrichment bounded by timeout", elapsed)
+	}
+
+	if !result.Partial {
+		t.Error("ClassifyRequest() should mark result partial")
+	}
+	if got := strings.Join(result.Incomplete, ","); got != "slow,after" {
+		t.Errorf("ClassifyRequest() Incomplete = %q, want %q", got, "slow,after")
+	}
+	if result.Fingerprint.HTTP.UserAgent != "enriched" {
+		t.Errorf("ClassifyRequest() UserAgent = %q, want completed enrichment kept and failed one discarded", result.Fingerprint.HTTP.UserAgent)
+	}
+}
+
+func TestClassifyRequest_EnricherError(t *testing.T) {
+	c := classifier.New(classifier.WithEnrichers(testEnricher{name: "geoip", err: errors.New("lookup failed")}))
+
+	result := c.ClassifyRequest(context.Background(), httptest.NewRequest("GET", "/", nil))
+	if !result.Partial || len(result.Incomplete) != 1 || result.Incomplete[0] != "geoip" {
+		t.Errorf("ClassifyRequest() partial = %v %v, want [geoip]", result.Partial, result.Incomplete)
+	}
+}
+
+func TestClassifyRequest_ContextDeadline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	c := classifier.New(classifier.WithEnrichers(testEnricher{name: "dns"}))
+	result := c.ClassifyRequest(ctx, httptest.NewRequest("GET", "/", nil))
+
+	if !result.Partial || len(result.Incomplete) != 1 {
+		t.Errorf("ClassifyRequest() with done ctx partial = %v %v, want [dns]", result.Partial, result.Incomplete)
+	}
+	if result.Classification == "" {
+		t.Error("ClassifyRequest() should still classify when enrichment is skipped")
+	}
+}
+
+func TestClassifyRequest_EnrichmentBudget(t *testing.T) {
+	c := classifier.New(
+		classifier.WithEnrichmentBudget("dns", 20*time.Millisecond),
+		classifier.WithEnrichers(
+			testEnricher{name: "dns", delay: time.Second, ua: "half-written"},
+			testEnricher{name: "geoip", ua: "enriched"},
+		),
+	)
+
+	start := time.Now()
+	result, timings := c.ClassifyRequestTimed(context.Background(), httptest.NewRequest("GET", "/", nil))
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("ClassifyRequestTimed() took %v, want the slow enricher cut at its budget", elapsed)
+	}
+
+	// The enricher after the one out of budget still runs
+	if got := strings.Join(result.Incomplete, ","); !result.Partial || got != "dns" {
+		t.Errorf("Incomplete = %q, want dns", got)
+	}
+	if result.Fingerprint.HTTP.UserAgent != "enriched" {
+		t.Errorf("UserAgent = %q, want geoip enrichment kept", result.Fingerprint.HTTP.UserAgent)
+	}
+	if timings.Enrichers["dns"] < 20*time.Millisecond || timings.Enrich < timings.Enrichers["dns"] {
+		t.Errorf("timings = %+v", timings)
+	}
+	if _, ok := timings.Enrichers["geoip"]; !ok {
+		t.Errorf("timings should include geoip: %+v", timings)
+	}
+	if timings.Total() != timings.Collect+timings.Enrich+timings.Classify {
+		t.Errorf("Total() = %v for %+v", timings.Total(), timings)
+	}
+}
+
+func TestClassifierConfig_Weights(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			Accept:      "*/*",
+			HeaderCount: 3,
+		},
+	}
+	def := classifier.New().Classify(fp).Signals
+
+	rules := fingerprint.DefaultRules()
+	rules.Weights = map[string]int{"bot-ua": 5, "low-headers": 4}
+	c := classifier.New(
+		classifier.WithRules(rules),
+		classifier.WithWeights(map[string]int{"bot-ua": 10}),
+		classifier.WithWeights(map[string]int{"accept-*/*-": 0}),
+	)
+	s := c.Classify(fp).Signals
+
+	// bot-ua from Weights over Rules, low-headers from Rules, accept-*/*- disabled
+	if want := def.BotScore + (10 - 3) + (4 - 2) - 1; s.BotScore != want {
+		t.Errorf("BotScore = %d, want %d (%s)", s.BotScore, want, s.ScoreBreakdown)
+	}
+	if strings.Contains(s.ScoreBreakdown.String(), "accept-*/*-") {
+		t.Errorf("zero weight should disable the rule: %s", s.ScoreBreakdown)
+	}
+	if w := c.Rules().Weights; w["bot-ua"] != 10 || w["low-headers"] != 4 {
+		t.Errorf("Rules().Weights = %v", w)
+	}
+	if rules.Weights["bot-ua"] != 5 {
+		t.Error("WithWeights modified the caller's rules")
+	}
+
+	cfg := classifier.Config{Weights: map[string]int{"http1.1": 3}}
+	if s := classifier.New(cfg).Classify(fp).Signals; !strings.Contains(s.ScoreBreakdown.String(), "http1.1(+3)") {
+		t.Errorf("Config.Weights not applied: %s", s.ScoreBreakdown)
+	}
+}
+
+func TestClassifierWithScorer(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			Accept:      "*/*",
+			HeaderCount: 3,
+		},
+	}
+	def := classifier.New().Classify(fp)
+
+	partner := classifier.ScorerFunc(func(fp fingerprint.Fingerprint) (int, int, []string) {
+		if strings.HasPrefix(fp.HTTP.UserAgent, "curl/") {
+			return 20, 0, []string{"partner monitoring client"}
+		}
+		return 0, 0, nil
+	})
+	silent := classifier.ScorerFunc(func(fingerprint.Fingerprint) (int, int, []string) {
+		return -5, 0, []string{"never shown"}
+	})
+	c := classifier.New(classifier.WithScorer("partner", partner), classifier.WithScorer("silent", silent))
+	result := c.Classify(fp)
+
+	if result.Classification != classifier.ClassificationBrowser {
+		t.Fatalf("Classification = %s, want browser", result.Classification)
+	}
+	if want := def.Signals.BrowserScore + 20; result.Signals.BrowserScore != want {
+		t.Errorf("BrowserScore = %d, want %d", result.Signals.BrowserScore, want)
+	}
+	if result.Signals.BotScore != def.Signals.BotScore {
+		t.Errorf("BotScore = %d, want %d", result.Signals.BotScore, def.Signals.BotScore)
+	}
+	if b := result.Signals.ScoreBreakdown.String(); !strings.Contains(b, "partner(+20)] BOT[") || strings.Contains(b, "silent") {
+		t.Errorf("ScoreBreakdown = %s, want partner(+20) last of the browser rules and no silent", b)
+	}
+	if !strings.HasSuffix(result.Reason, "partner monitoring client") {
+		t.Errorf("Reason = %q, want the scorer's reason", result.Reason)
+	}
+	if !slices.Equal(result.FlipSet, []string{"partner"}) {
+		t.Errorf("FlipSet = %v, want [partner]", result.FlipSet)
+	}
+
+	// Reasons of scorers arguing for the losing side are not shown
+	other := classifier.New(classifier.WithScorer("partner", classifier.ScorerFunc(func(fingerprint.Fingerprint) (int, int, []string) {
+		return 1, 0, []string{"weak hint"}
+	}))).Classify(fp)
+	if other.Classification != classifier.ClassificationBot || strings.Contains(other.Reason, "weak hint") {
+		t.Errorf("Classify() = %s (%q), want bot without the scorer's reason", other.Classification, other.Reason)
+	}
+}
+
+func TestParseWeights(t *testing.T) {
+	weights, err := classifier.ParseWeights("sec-fetch=4, http1.1=0,bot-ua=-1")
+	if err != nil {
+		t.Fatalf("ParseWeights() error = %v", err)
+	}
+	if weights["sec-fetch"] != 4 || weights["http1.1"] != 0 || weights["bot-ua"] != -1 || len(weights) != 3 {
+		t.Errorf("weights = %v", weights)
+	}
+	for _, s := range []string{"sec-fetch", "=4", "sec-fetch=high", "sec-fetch=1.5"} {
+		if _, err := classifier.ParseWeights(s); err == nil {
+			t.Errorf("ParseWeights(%q) should fail", s)
+		}
+	}
+}
+
+func TestParseEnrichmentBudgets(t *testing.T) {
+	budgets, err := classifier.ParseEnrichmentBudgets("private-relay=1ms, challenge=500us")
+	if err != nil {
+		t.Fatalf("ParseEnrichmentBudgets() error = %v", err)
+	}
+	if budgets["private-relay"] != time.Millisecond || budgets["challenge"] != 500*time.Microsecond {
+		t.Errorf("budgets = %v", budgets)
+	}
+	for _, s := range []string{"dns", "=1ms", "dns=fast", "dns=0s", "dns=-1ms"} {
+		if _, err := classifier.ParseEnrichmentBudgets(s); err == nil {
+			t.Errorf("ParseEnrichmentBudgets(%q) should fail", s)
+		}
+	}
+}
+
+func TestTimings_JSON(t *testing.T) {
+	data, err := json.Marshal(classifier.Timings{
+		Collect:   40 * time.Microsecond,
+		Enrich:    1500 * time.Microsecond,
+		Classify:  10 * time.Microsecond,
+		Enrichers: map[string]time.Duration{"dns": 1500 * time.Microsecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"collect_ms":0.04,"enrich_ms":1.5,"classify_ms":0.01,"log_ms":0,"total_ms":1.55,"enrichers_ms":{"dns":1.5}}`
+	if string(data) != want {
+		t.Errorf("JSON = %s, want %s", data, want)
+	}
+}
+
+func TestBotScore(t *testing.T) {
+	tests := []struct {
+		class      string
+		confidence float64
+		want       int
+	}{
+		{classifier.ClassificationBot, 0.99, 1},
+		{classifier.ClassificationBot, 0.5, 29},
+		{classifier.ClassificationBrowser, 0.5, 30},
+		{classifier.ClassificationBrowser, 0.99, 99},
+		{classifier.ClassificationBrowser, 1.5, 99},
+		{classifier.ClassificationBot, 0, 29},
+	}
+	for _, tt := range tests {
+		got := classifier.BotScore(fingerprint.ClassificationResult{Classification: tt.class, Confidence: tt.confidence})
+		if got != tt.want {
+			t.Errorf("BotScore(%s, %.2f) = %d, want %d", tt.class, tt.confidence, got, tt.want)
+		}
+	}
+
+	// Real classifications stay on their side of the likely-human boundary
+	c := classifier.New(classifier.DefaultConfig())
+	for _, g := range mustGolden(t) {
+		r := c.Classify(g.Fingerprint)
+		score := classifier.BotScore(r)
+		if (r.Classification == classifier.ClassificationBot) != (score < classifier.BotScoreLikelyHuman) {
+			t.Errorf("%s: %s scored %d", g.ID, r.Classification, score)
+		}
+	}
+}
+
+func mustGolden(t *testing.T) []fingerprint.GoldenFingerprint {
+	t.Helper()
+	corpus, err := fingerprint.Golden()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return corpus
+}
+
+// feedbackRecorder is a classifier.FeedbackStore keeping feedback in memory
+type feedbackRecorder []classifier.Feedback
+
+func (r *feedbackRecorder) Store(f classifier.Feedback) error {
+	*r = append(*r, f)
+	return nil
+}
+
+func TestClassifierFeedback(t *testing.T) {
+	curl := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+		Version:     "HTTP/1.1",
+		UserAgent:   "curl/8.0.1",
+		Accept:      "*/*",
+		HeaderCount: 3,
+	}}
+
+	if _, err := classifier.New().Feedback(classifier.Feedback{Label: "bot", Fingerprint: curl}); !errors.Is(err, classifier.ErrFeedbackDisabled) {
+		t.Errorf("Feedback() without store or adjustment: error = %v, want ErrFeedbackDisabled", err)
+	}
+
+	var stored feedbackRecorder
+	c := classifier.New(classifier.WithFeedback(&stored), classifier.WithFeedbackAdjust(true))
+	if !c.FeedbackEnabled() {
+		t.Fatal("FeedbackEnabled() = false")
+	}
+	if _, err := c.Feedback(classifier.Feedback{Label: "human", Fingerprint: curl}); err == nil {
+		t.Error("Feedback() with an invalid label: error = nil")
+	}
+
+	// Agreeing with the decision stores the sample without adjusting
+	res, err := c.Feedback(classifier.Feedback{RequestID: "r1", Label: "bot", Fingerprint: curl})
+	if err != nil || !res.Stored || res.Adjusted != nil || res.Predicted != classifier.ClassificationBot {
+		t.Errorf("Feedback(bot) = %+v, %v; want stored bot without adjustment", res, err)
+	}
+
+	browserWeight := c.Rules().Weight("sec-fetch")
+	res, err = c.Feedback(classifier.Feedback{RequestID: "r2", Label: "browser", Fingerprint: curl, Note: "uptime monitor"})
+	if err != nil {
+		t.Fatalf("Feedback(browser) error = %v", err)
+	}
+	if res.Adjusted["bot-ua"] != 2 || c.Rules().Weight("bot-ua") != 2 {
+		t.Errorf("bot-ua: adjusted %v, weight %d, want 2", res.Adjusted, c.Rules().Weight("bot-ua"))
+	}
+	if c.Rules().Weight("sec-fetch") != browserWeight {
+		t.Error("browser rules should not be adjusted by a browser correction")
+	}
+	if len(stored) != 2 || stored[1].Note != "uptime monitor" || stored[1].Predicted != classifier.ClassificationBot {
+		t.Errorf("stored = %+v", stored)
+	}
+
+	// Repeated corrections never take a rule below one point
+	for range 5 {
+		if _, err := c.Feedback(classifier.Feedback{Label: "browser", Fingerprint: curl}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if w := c.Rules().Weight("bot-ua"); w != 1 {
+		t.Errorf("bot-ua weight after repeated corrections = %d, want 1", w)
+	}
+}
@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/pkg/client"
+	"github.com/muliwe/go-client-classifier/pkg/fingerprint"
+)
+
+// newTestAPIServer serves the remote classification endpoints from a test handler,
+// validating requests against the OpenAPI spec like the real server
+func newTestAPIServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	h := createTestHandler()
+	h.SetQuiet(true)
+
+	validator := newTestValidator(t)
+
+	srv := httptest.NewServer(server.NewRouter(h, validator, false))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func newTestClient(t *testing.T, baseURL string) *client.Client {
+	t.Helper()
+	cfg := client.DefaultConfig()
+	cfg.BaseURL = baseURL
+	cfg.RetryBackoff = time.Millisecond
+	c, err := client.New(cfg)
+	if err != nil {
+		t.Fatalf("client.New() error = %v", err)
+	}
+	return c
+}
+
+func TestClientNew_InvalidURL(t *testing.T) {
+	if _, err := client.New(client.Config{BaseURL: "localhost"}); err == nil {
+		t.Error("New() should reject base URL without scheme")
+	}
+}
+
+func TestClientHealth(t *testing.T) {
+	srv := newTestAPIServer(t)
+	c := newTestClient(t, srv.URL)
+
+	health, err := c.Health(context.Background())
+	if err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if health.Status != "ok" {
+		t.Errorf("Health().Status = %q, want %q", health.Status, "ok")
+	}
+}
+
+func TestClientClassify(t *testing.T) {
+	srv := newTestAPIServer(t)
+	c := newTestClient(t, srv.URL)
+
+	incoming := httptest.NewRequest("GET", "/products?page=2", nil)
+	incoming.Header.Set("User-Agent", "curl/8.0.1")
+	incoming.Header.Set("Accept", "*/*")
+
+	result, err := c.Classify(context.Background(), incoming)
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if result.Classification != "bot" {
+		t.Errorf("Classify(curl) = %q, want %q", result.Classification, "bot")
+	}
+	if result.Fingerprint.HTTP.UserAgent != "curl/8.0.1" {
+		t.Errorf("Classify() fingerprint UA = %q, want %q", result.Fingerprint.HTTP.UserAgent, "curl/8.0.1")
+	}
+	if result.Fingerprint.HTTP.JA4HHash == "" {
+		t.Error("Classify() should compute JA4H server-side")
+	}
+}
+
+func TestClientClassifyFingerprint(t *testing.T) {
+	srv := newTestAPIServer(t)
+	c := newTestClient(t, srv.URL)
+
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "python-requests/2.31.0",
+			Accept:      "*/*",
+			HeaderCount: 3,
+		},
+	}
+
+	result, err := c.ClassifyFingerprint(context.Background(), fp)
+	if err != nil {
+		t.Fatalf("ClassifyFingerprint() error = %v", err)
+	}
+	if result.Classification != "bot" {
+		t.Errorf("ClassifyFingerprint(python) = %q, want %q", result.Classification, "bot")
+	}
+}
+
+func TestClientStats(t *testing.T) {
+	srv := newTestAPIServer(t)
+	c := newTestClient(t, srv.URL)
+
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.0"}}
+	for i := 0; i < 3; i++ {
+		if _, err := c.ClassifyFingerprint(context.Background(), fp); err != nil {
+			t.Fatalf("ClassifyFingerprint() error = %v", err)
+		}
+	}
+
+	stats, err := c.Stats(context.Background())
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	if stats.TotalRequests != 3 || stats.Bot != 3 {
+		t.Errorf("Stats() = %+v, want 3 total / 3 bot", stats)
+	}
+}
+
+func TestClientRetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok","version":"test"}`))
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+
+	if _, err := c.Health(context.Background()); err != nil {
+		t.Fatalf("Health() error = %v", err)
+	}
+	if calls.Load() != 3 {
+		t.Errorf("server calls = %d, want 3", calls.Load())
+	}
+}
+
+func TestClientNoRetryOnClientError(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "bad request", http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	c := newTestClient(t, srv.URL)
+
+	_, err := c.Health(context.Background())
+	if !client.IsStatus(err, http.StatusBadRequest) {
+		t.Errorf("Health() error = %v, want StatusError 400", err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("server calls = %d, want 1", calls.Load())
+	}
+}
+
+func TestClientProblemCode(t *testing.T) {
+	srv := newTestAPIServer(t)
+	c := newTestClient(t, srv.URL)
+
+	req := httptest.NewRequest("get", "/", nil)
+	_, err := c.Classify(context.Background(), req)
+
+	if !client.IsStatus(err, http.StatusBadRequest) {
+		t.Fatalf("Classify(lowercase method) error = %v, want StatusError 400", err)
+	}
+	if !client.IsCode(err, "validation_failed") {
+		t.Errorf("Classify(lowercase method) error = %v, want code validation_failed", err)
+	}
+}
@@ -0,0 +1,92 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+const (
+	chromeWindowsUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"
+	chromeAndroidUA = "Mozilla/5.0 (Linux; Android 10; K) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Mobile Safari/537.36"
+	chromeBrands    = `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`
+)
+
+func TestCollect_ClientHints(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/", nil)
+	req.Header.Set("User-Agent", chromeAndroidUA)
+	req.Header.Set("Sec-CH-UA", chromeBrands)
+	req.Header.Set("Sec-CH-UA-Full-Version-List", `"Chromium";v="124.0.6367.113", "Google Chrome";v="124.0.6367.113", "Not-A.Brand";v="99.0.0.0"`)
+	req.Header.Set("Sec-CH-UA-Mobile", "?1")
+	req.Header.Set("Sec-CH-UA-Platform", `"Android"`)
+	req.Header.Set("Sec-CH-UA-Platform-Version", `"14.0.0"`)
+	req.Header.Set("Sec-CH-UA-Model", `"Pixel 8"`)
+	req.Header.Set("Sec-CH-UA-Arch", `""`)
+
+	got := fingerprint.NewCollector().Collect(req).HTTP.ClientHints
+	want := fingerprint.ClientHints{
+		Brands:          []fingerprint.BrandVersion{{Brand: "Chromium", Version: "124"}, {Brand: "Google Chrome", Version: "124"}},
+		FullVersionList: []fingerprint.BrandVersion{{Brand: "Chromium", Version: "124.0.6367.113"}, {Brand: "Google Chrome", Version: "124.0.6367.113"}},
+		Platform:        "Android",
+		PlatformVersion: "14.0.0",
+		Mobile:          true,
+		Model:           "Pixel 8",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ClientHints = %+v, want %+v", got, want)
+	}
+	if !got.Sent() || (fingerprint.ClientHints{}).Sent() {
+		t.Error("Sent() does not tell sent hints from none")
+	}
+}
+
+func TestExtractSignals_ClientHintsMismatch(t *testing.T) {
+	brands := fingerprint.ParseBrandList(chromeBrands)
+	windows := fingerprint.ClientHints{Brands: brands, Platform: "Windows"}
+	android := fingerprint.ClientHints{Brands: brands, Platform: "Android", Mobile: true, Model: "Pixel 8"}
+	with := func(c fingerprint.ClientHints, edit func(*fingerprint.ClientHints)) fingerprint.ClientHints {
+		c.Brands = append([]fingerprint.BrandVersion(nil), c.Brands...)
+		edit(&c)
+		return c
+	}
+
+	tests := []struct {
+		name  string
+		ua    string
+		hints fingerprint.ClientHints
+		want  string
+	}{
+		{"Chrome on Windows", chromeWindowsUA, windows, ""},
+		{"Chrome on Android", chromeAndroidUA, android, ""},
+		{"no hints", chromeWindowsUA, fingerprint.ClientHints{}, ""},
+		{"Edge", chromeWindowsUA + " Edg/124.0.0.0", with(windows, func(c *fingerprint.ClientHints) {
+			c.Brands = append(c.Brands, fingerprint.BrandVersion{Brand: "Microsoft Edge", Version: "124"})
+		}), ""},
+		{"Android tablet", strings.Replace(chromeAndroidUA, " Mobile", "", 1), with(android, func(c *fingerprint.ClientHints) { c.Mobile = false }), ""},
+		{"Firefox with hints", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:133.0) Gecko/20100101 Firefox/133.0", windows, "sec-ch-ua"},
+		{"stale brand version", strings.Replace(chromeWindowsUA, "Chrome/124", "Chrome/131", 1), windows, "sec-ch-ua"},
+		{"full version list of another release", chromeWindowsUA, with(windows, func(c *fingerprint.ClientHints) {
+			c.FullVersionList = []fingerprint.BrandVersion{{Brand: "Google Chrome", Version: "120.0.6099.109"}}
+		}), "sec-ch-ua-full-version-list"},
+		{"Windows UA, macOS hints", chromeWindowsUA, with(windows, func(c *fingerprint.ClientHints) { c.Platform = "macOS" }), "sec-ch-ua-platform"},
+		{"Android UA, Linux hints", chromeAndroidUA, with(android, func(c *fingerprint.ClientHints) { c.Platform = "Linux" }), "sec-ch-ua-platform"},
+		{"desktop UA, mobile hint", chromeWindowsUA, with(windows, func(c *fingerprint.ClientHints) { c.Mobile = true }), "sec-ch-ua-mobile"},
+		{"phone UA, desktop hint", chromeAndroidUA, with(android, func(c *fingerprint.ClientHints) { c.Mobile = false }), "sec-ch-ua-mobile"},
+		{"model on Windows", chromeWindowsUA, with(windows, func(c *fingerprint.ClientHints) { c.Model = "Pixel 8" }), "sec-ch-ua-model"},
+		{"library UA", "python-requests/2.31.0", windows, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: tt.ua, ClientHints: tt.hints}})
+			if s.ClientHintsConflict != tt.want || s.ClientHintsMismatch != (tt.want != "") {
+				t.Errorf("ClientHintsMismatch = %v (%q), want %q", s.ClientHintsMismatch, s.ClientHintsConflict, tt.want)
+			}
+			if got := strings.Contains(s.ScoreBreakdown.String(), "client-hints-mismatch(+3)"); got != (tt.want != "") {
+				t.Errorf("breakdown = %s", s.ScoreBreakdown)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package unit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/crawlerverify"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+const testRobots = `# Pace crawlers
+User-agent: GPTBot
+User-agent: CCBot
+Crawl-delay: 10
+Disallow: /private
+
+User-agent: Googlebot
+Allow: /
+
+User-agent: *
+Crawl-delay: 2.5 # seconds
+`
+
+func TestParseRobots(t *testing.T) {
+	delays, err := crawldelay.ParseRobots(strings.NewReader(testRobots))
+	if err != nil {
+		t.Fatalf("ParseRobots() error = %v", err)
+	}
+	want := crawldelay.Delays{"gptbot": 10 * time.Second, "ccbot": 10 * time.Second, "*": 2500 * time.Millisecond}
+	if len(delays) != len(want) {
+		t.Fatalf("ParseRobots() = %v, want %v", delays, want)
+	}
+	for agent, d := range want {
+		if delays[agent] != d {
+			t.Errorf("delay[%s] = %v, want %v", agent, delays[agent], d)
+		}
+	}
+
+	if _, err := crawldelay.ParseRobots(strings.NewReader("User-agent: x\nCrawl-delay: soon\n")); err == nil {
+		t.Error("invalid Crawl-delay should fail")
+	}
+}
+
+func TestParseOverrides(t *testing.T) {
+	delays, err := crawldelay.ParseOverrides("GPTBot=10s, ccbot=2.5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if delays["gptbot"] != 10*time.Second || delays["ccbot"] != 2500*time.Millisecond {
+		t.Errorf("ParseOverrides() = %v", delays)
+	}
+	for _, bad := range []string{"gptbot", "=1s", "gptbot=x", "gptbot=-1s"} {
+		if _, err := crawldelay.ParseOverrides(bad); err == nil {
+			t.Errorf("ParseOverrides(%q) should fail", bad)
+		}
+	}
+}
+
+func TestPacer_Allow(t *testing.T) {
+	delays, _ := crawldelay.ParseRobots(strings.NewReader(testRobots))
+	p := crawldelay.NewPacer(delays, fingerprint.DefaultRules().AICrawlerPatterns)
+
+	const gptbot = "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)"
+	if name, d, ok := p.Crawler(gptbot); !ok || name != "gptbot" || d != 10*time.Second {
+		t.Errorf("Crawler(GPTBot) = %q, %v, %v", name, d, ok)
+	}
+	if _, _, ok := p.Crawler("curl/8.0"); ok {
+		t.Error("curl should not be paced")
+	}
+
+	now := time.Unix(1000, 0)
+	if _, ok := p.Allow(gptbot, now); !ok {
+		t.Fatal("first request denied")
+	}
+	wait, ok := p.Allow(gptbot, now.Add(4*time.Second))
+	if ok || wait != 6*time.Second {
+		t.Errorf("early request: wait = %v, ok = %v, want 6s denied", wait, ok)
+	}
+	if _, ok := p.Allow(gptbot, now.Add(10*time.Second)); !ok {
+		t.Error("request after the delay denied")
+	}
+
+	// Wildcard delay applies to other identified AI crawlers
+	const perplexity = "Mozilla/5.0 (compatible; PerplexityBot/1.0; +https://perplexity.ai/perplexitybot)"
+	if _, d, ok := p.Crawler(perplexity); !ok || d != 2500*time.Millisecond {
+		t.Errorf("Crawler(PerplexityBot) delay = %v, %v, want wildcard 2.5s", d, ok)
+	}
+}
+
+func TestHandler_CrawlDelay(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "robots.txt")
+	if err := os.WriteFile(path, []byte(testRobots), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := crawldelay.Load(path, crawldelay.Delays{"CCBot": time.Minute}, fingerprint.DefaultRules().AICrawlerPatterns)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if _, d, _ := p.Crawler("CCBot/2.0 (https://commoncrawl.org/faq/)"); d != time.Minute {
+		t.Errorf("override delay = %v, want 1m", d)
+	}
+
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetCrawlDelay(p)
+	router := server.NewRouter(h, nil, false)
+
+	get := func(path, ua string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("User-Agent", ua)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	const ccbot = "CCBot/2.0 (https://commoncrawl.org/faq/)"
+	if w := get("/v1/", ccbot); w.Code != http.StatusOK {
+		t.Fatalf("first crawl: status = %d", w.Code)
+	}
+	w := get("/v1/", ccbot)
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("second crawl: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if w := get("/v1/", "curl/8.0"); w.Code != http.StatusOK {
+		t.Errorf("unpaced bot: status = %d", w.Code)
+	}
+
+	w = get("/robots.txt", ccbot)
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusOK || string(body) != testRobots {
+		t.Errorf("robots.txt: status = %d, body = %q", w.Code, body)
+	}
+}
+
+func TestHandler_CrawlDelaySpoofedCrawler(t *testing.T) {
+	delays, _ := crawldelay.ParseRobots(strings.NewReader(testRobots))
+	delays["googlebot"] = time.Minute
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetCrawlDelay(crawldelay.NewPacer(delays, fingerprint.DefaultRules().AICrawlerPatterns))
+	h.SetCrawlerVerifier(crawlerverify.New(googleResolver()))
+	router := server.NewRouter(h, nil, false)
+
+	get := func(addr string) int {
+		r := httptest.NewRequest(http.MethodGet, "/v1/", nil)
+		r.RemoteAddr = addr + ":4711"
+		r.Header.Set("User-Agent", googlebotUA)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w.Code
+	}
+
+	// A spoofer exhausting its own budget leaves the genuine crawler's alone
+	if code := get("198.51.100.9"); code != http.StatusOK {
+		t.Fatalf("first spoofed crawl: status = %d", code)
+	}
+	if code := get("198.51.100.9"); code != http.StatusTooManyRequests {
+		t.Errorf("second spoofed crawl: status = %d, want 429", code)
+	}
+	if code := get("66.249.66.1"); code != http.StatusOK {
+		t.Errorf("verified crawl after spoofed ones: status = %d, want 200", code)
+	}
+	if code := get("66.249.66.1"); code != http.StatusTooManyRequests {
+		t.Errorf("second verified crawl: status = %d, want 429", code)
+	}
+}
+
+const testSignalRobots = `User-agent: Googlebot
+Content-Signal: search=yes, ai-train=yes
+Allow: /
+
+User-agent: GPTBot
+Crawl-delay: 10
+Content-Signal: search=no, ai-input=no, ai-train=no
+
+User-agent: *
+Content-Signal: search=yes, ai-train=no
+`
+
+func TestParseContentSignals(t *testing.T) {
+	signals, err := crawldelay.ParseContentSignals(strings.NewReader(testSignalRobots))
+	if err != nil {
+		t.Fatalf("ParseContentSignals() error = %v", err)
+	}
+	want := crawldelay.ContentSignals{
+		"googlebot": "search=yes, ai-train=yes",
+		"gptbot":    "search=no, ai-input=no, ai-train=no",
+		"*":         "search=yes, ai-train=no",
+	}
+	if len(signals) != len(want) {
+		t.Fatalf("ParseContentSignals() = %v, want %v", signals, want)
+	}
+	for agent, s := range want {
+		if signals[agent] != s {
+			t.Errorf("signal[%s] = %q, want %q", agent, signals[agent], s)
+		}
+	}
+
+	for _, bad := range []string{"ai-train", "ai-train=maybe", "=no"} {
+		if _, err := crawldelay.ParseContentSignals(strings.NewReader("User-agent: *\nContent-Signal: " + bad + "\n")); err == nil {
+			t.Errorf("Content-Signal %q should fail", bad)
+		}
+	}
+}
+
+func TestRobotsTag(t *testing.T) {
+	tests := map[string]string{
+		"search=yes, ai-train=no":  "noai, noimageai",
+		"ai-train=no":              "noai, noimageai",
+		"search=yes, ai-train=yes": "",
+		"ai-input=no":              "",
+		"":                         "",
+	}
+	for signal, want := range tests {
+		if got := crawldelay.RobotsTag(signal); got != want {
+			t.Errorf("RobotsTag(%q) = %q, want %q", signal, got, want)
+		}
+	}
+}
+
+func TestHandler_AIPolicyHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "robots.txt")
+	if err := os.WriteFile(path, []byte(testSignalRobots), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := crawldelay.Load(path, nil, fingerprint.DefaultRules().AICrawlerPatterns)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetCrawlDelay(p)
+	h.SetAIPolicyHeaders(true)
+	router := server.NewRouter(h, nil, false)
+
+	get := func(ua string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/", nil)
+		r.Header.Set("User-Agent", ua)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	tests := []struct {
+		name, ua, signal, tag string
+	}{
+		{"named group", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)", "search=yes, ai-train=yes", ""},
+		{"AI crawler", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)", "search=no, ai-input=no, ai-train=no", "noai, noimageai"},
+		{"wildcard", "curl/8.0", "search=yes, ai-train=no", "noai, noimageai"},
+	}
+	for _, tt := range tests {
+		w := get(tt.ua)
+		if got := w.Header().Get("Content-Signal"); got != tt.signal {
+			t.Errorf("%s: Content-Signal = %q, want %q", tt.name, got, tt.signal)
+		}
+		if got := w.Header().Get("X-Robots-Tag"); got != tt.tag {
+			t.Errorf("%s: X-Robots-Tag = %q, want %q", tt.name, got, tt.tag)
+		}
+	}
+
+	// Refusals carry the policy too
+	gptbot := tests[1].ua
+	if w := get(gptbot); w.Code != http.StatusTooManyRequests || w.Header().Get("Content-Signal") == "" {
+		t.Errorf("paced crawler: status = %d, Content-Signal = %q", w.Code, w.Header().Get("Content-Signal"))
+	}
+
+	h.SetAIPolicyHeaders(false)
+	if w := get("curl/8.0"); w.Header().Get("Content-Signal") != "" {
+		t.Error("Content-Signal should not be set when disabled")
+	}
+}
+
+func TestRules_Crawlers(t *testing.T) {
+	byName := map[string]fingerprint.RecognizedCrawler{}
+	for _, c := range fingerprint.DefaultRules().Crawlers() {
+		byName[c.Name] = c
+	}
+	if len(byName) != len(fingerprint.KnownCrawlers()) {
+		t.Errorf("default rules recognize %d of %d known crawlers", len(byName), len(fingerprint.KnownCrawlers()))
+	}
+	gpt := byName["GPTBot"]
+	if gpt.Operator != "OpenAI" || gpt.Category != fingerprint.CategoryAITraining || !gpt.AICrawler || strings.Join(gpt.Patterns, ",") != "bot,gptbot" {
+		t.Errorf("GPTBot = %+v", gpt)
+	}
+	if g := byName["Googlebot"]; g.AICrawler || g.Verification != fingerprint.VerifyReverseDNS {
+		t.Errorf("Googlebot = %+v", g)
+	}
+
+	// Crawlers only a removed pattern matched drop out of the directory
+	rules := fingerprint.DefaultRules()
+	rules.BotPatterns = []string{"gptbot"}
+	if got := rules.Crawlers(); len(got) != 1 || got[0].Name != "GPTBot" {
+		t.Errorf("Crawlers() with only gptbot = %+v", got)
+	}
+}
+
+func TestHandler_Crawlers(t *testing.T) {
+	h := createTestHandler()
+	delays, _ := crawldelay.ParseRobots(strings.NewReader(testRobots))
+	p := crawldelay.NewPacer(delays, fingerprint.DefaultRules().AICrawlerPatterns)
+	p.SetContentSignals(crawldelay.ContentSignals{"*": "search=yes, ai-train=no"})
+	h.SetCrawlDelay(p)
+
+	w := httptest.NewRecorder()
+	server.NewRouter(h, nil, false).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/crawlers", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d", w.Code)
+	}
+	var resp server.CrawlersResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	policies := map[string]server.CrawlerPolicy{}
+	for _, c := range resp.Crawlers {
+		policies[c.Name] = c.Policy
+	}
+	if got := policies["GPTBot"]; got.CrawlDelaySeconds != 10 || got.ContentSignal != "search=yes, ai-train=no" || got.Challenge != "" {
+		t.Errorf("GPTBot policy = %+v", got)
+	}
+	// The wildcard delay paces AI crawlers only
+	if got := policies["ClaudeBot"]; got.CrawlDelaySeconds != 2.5 {
+		t.Errorf("ClaudeBot policy = %+v", got)
+	}
+	if got := policies["Bingbot"]; got.CrawlDelaySeconds != 0 {
+		t.Errorf("Bingbot policy = %+v", got)
+	}
+}
@@ -0,0 +1,190 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/crawlerverify"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+const googlebotUA = "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)"
+
+// fakeResolver answers from fixed PTR and A records, counting lookups
+type fakeResolver struct {
+	ptr     map[string][]string
+	hosts   map[string][]string
+	err     error
+	lookups int
+}
+
+func (f *fakeResolver) LookupAddr(_ context.Context, addr string) ([]string, error) {
+	f.lookups++
+	if f.err != nil {
+		return nil, f.err
+	}
+	if names, ok := f.ptr[addr]; ok {
+		return names, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: addr, IsNotFound: true}
+}
+
+func (f *fakeResolver) LookupHost(_ context.Context, host string) ([]string, error) {
+	if addrs, ok := f.hosts[host]; ok {
+		return addrs, nil
+	}
+	return nil, &net.DNSError{Err: "no such host", Name: host, IsNotFound: true}
+}
+
+func googleResolver() *fakeResolver {
+	return &fakeResolver{
+		ptr: map[string][]string{
+			"66.249.66.1": {"crawl-66-249-66-1.googlebot.com."},
+			"203.0.113.7": {"crawl-fake.googlebot.com.evil.example."},
+			"203.0.113.8": {"crawl-66-249-66-1.googlebot.com."}, // forward lookup points elsewhere
+		},
+		hosts: map[string][]string{"crawl-66-249-66-1.googlebot.com": {"66.249.66.1"}},
+	}
+}
+
+// enrichCrawler runs the verifier on a request from addr with User-Agent ua
+func enrichCrawler(t *testing.T, v *crawlerverify.Verifier, addr, ua string) fingerprint.Fingerprint {
+	t.Helper()
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = addr + ":4711"
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: ua}}
+	if err := v.Enrich(context.Background(), r, &fp); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	return fp
+}
+
+func TestCrawlerVerifier_ReverseDNS(t *testing.T) {
+	tests := []struct {
+		name, addr, ua, want string
+	}{
+		{"genuine", "66.249.66.1", googlebotUA, fingerprint.CrawlerVerified},
+		{"no ptr", "198.51.100.9", googlebotUA, fingerprint.CrawlerSpoofed},
+		{"lookalike domain", "203.0.113.7", googlebotUA, fingerprint.CrawlerSpoofed},
+		{"forward mismatch", "203.0.113.8", googlebotUA, fingerprint.CrawlerSpoofed},
+		{"browser", "198.51.100.9", chromeUA, ""},
+		{"unverifiable crawler", "198.51.100.9", "Mozilla/5.0 (compatible; ClaudeBot/1.0)", ""},
+		{"behind a proxy", "10.0.0.2", googlebotUA, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := enrichCrawler(t, crawlerverify.New(googleResolver()), tt.addr, tt.ua)
+			if fp.Network.CrawlerVerification != tt.want {
+				t.Errorf("CrawlerVerification = %q, want %q", fp.Network.CrawlerVerification, tt.want)
+			}
+			if tt.want != "" && fp.Network.ClaimedCrawler != "Googlebot" {
+				t.Errorf("ClaimedCrawler = %q, want Googlebot", fp.Network.ClaimedCrawler)
+			}
+		})
+	}
+}
+
+func TestCrawlerVerifier_CachesOutcome(t *testing.T) {
+	res := googleResolver()
+	v := crawlerverify.New(res)
+	for range 3 {
+		enrichCrawler(t, v, "66.249.66.1", googlebotUA)
+	}
+	if res.lookups != 1 {
+		t.Errorf("reverse lookups = %d, want 1", res.lookups)
+	}
+
+	// A DNS outage leaves the claim unchecked and is reported
+	down := &fakeResolver{err: errors.New("i/o timeout")}
+	r := httptest.NewRequest("GET", "/", nil)
+	r.RemoteAddr = "66.249.66.1:4711"
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: googlebotUA}}
+	if err := crawlerverify.New(down).Enrich(context.Background(), r, &fp); err == nil || fp.Network.CrawlerVerification != "" {
+		t.Errorf("Enrich() during outage = %v, verification %q", err, fp.Network.CrawlerVerification)
+	}
+}
+
+func TestCrawlerVerifier_IPRanges(t *testing.T) {
+	prefixes, err := crawlerverify.ParseRanges(strings.NewReader(`{"creationTime":"2026-10-01","prefixes":[{"ipv4Prefix":"20.171.206.0/24"},{"ipv6Prefix":"2a03:2880::/32"}]}`))
+	if err != nil {
+		t.Fatalf("ParseRanges() error = %v", err)
+	}
+	v := crawlerverify.New(&fakeResolver{})
+	const gptbotUA = "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko); compatible; GPTBot/1.2; +https://openai.com/gptbot"
+
+	// Unchecked until the ranges are loaded
+	if fp := enrichCrawler(t, v, "198.51.100.9", gptbotUA); fp.Network.CrawlerVerification != "" {
+		t.Errorf("checked without ranges: %q", fp.Network.CrawlerVerification)
+	}
+	if err := v.SetRanges("gptbot", prefixes); err != nil {
+		t.Fatalf("SetRanges() error = %v", err)
+	}
+	if fp := enrichCrawler(t, v, "20.171.206.12", gptbotUA); fp.Network.CrawlerVerification != fingerprint.CrawlerVerified {
+		t.Errorf("in range: %q", fp.Network.CrawlerVerification)
+	}
+	if fp := enrichCrawler(t, v, "198.51.100.9", gptbotUA); fp.Network.CrawlerVerification != fingerprint.CrawlerSpoofed {
+		t.Errorf("out of range: %q", fp.Network.CrawlerVerification)
+	}
+
+	if err := v.SetRanges("Googlebot", prefixes); err == nil {
+		t.Error("SetRanges() accepted a crawler verified by reverse DNS")
+	}
+	if err := v.SetRanges("NoSuchBot", prefixes); err == nil {
+		t.Error("SetRanges() accepted an unknown crawler")
+	}
+}
+
+func TestParseRanges_Lines(t *testing.T) {
+	prefixes, err := crawlerverify.ParseRanges(strings.NewReader("# DuckDuckBot\n20.191.45.212\n40.88.21.0/24 # east\n\n"))
+	if err != nil {
+		t.Fatalf("ParseRanges() error = %v", err)
+	}
+	if len(prefixes) != 2 || !prefixes[0].Contains(netip.MustParseAddr("20.191.45.212")) || prefixes[0].Bits() != 32 {
+		t.Errorf("prefixes = %v", prefixes)
+	}
+	for _, data := range []string{"", "# nothing\n", "not-an-ip\n", `{"prefixes":[{"ipv4Prefix":"300.1.1.0/24"}]}`} {
+		if _, err := crawlerverify.ParseRanges(strings.NewReader(data)); err == nil {
+			t.Errorf("ParseRanges(%q) succeeded", data)
+		}
+	}
+}
+
+func TestExtractSignals_SpoofedCrawler(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP:    fingerprint.HTTPFingerprint{UserAgent: googlebotUA},
+		Network: fingerprint.NetworkFingerprint{ClaimedCrawler: "Googlebot", CrawlerVerification: fingerprint.CrawlerSpoofed},
+	}
+	res := classifier.New().Classify(fp)
+	s := res.Signals
+	if !s.SpoofedCrawler || s.ClaimedCrawler != "Googlebot" || !strings.Contains(s.ScoreBreakdown.String(), "spoofed-crawler(+8)") {
+		t.Errorf("SpoofedCrawler = %v, ClaimedCrawler = %q: %s", s.SpoofedCrawler, s.ClaimedCrawler, s.ScoreBreakdown)
+	}
+	if !strings.Contains(res.Reason, "spoofed Googlebot") {
+		t.Errorf("reason %q lacks the claimed crawler", res.Reason)
+	}
+
+	fp.Network.CrawlerVerification = fingerprint.CrawlerVerified
+	if s := fingerprint.ExtractSignals(fp); s.SpoofedCrawler || s.ClaimedCrawler != "Googlebot" {
+		t.Errorf("verified: SpoofedCrawler = %v, ClaimedCrawler = %q", s.SpoofedCrawler, s.ClaimedCrawler)
+	}
+}
+
+func TestClaimedCrawler(t *testing.T) {
+	for ua, want := range map[string]string{
+		googlebotUA: "Googlebot",
+		"Mozilla/5.0 (compatible; bingbot/2.0; +http://www.bing.com/bingbot.htm)": "Bingbot",
+		"Mozilla/5.0 (compatible; GoogleOther)":                                   "GoogleOther",
+		chromeUA:                                                                  "",
+	} {
+		c, ok := fingerprint.ClaimedCrawler(ua)
+		if c.Name != want || ok != (want != "") {
+			t.Errorf("ClaimedCrawler(%q) = %q, %v, want %q", ua, c.Name, ok, want)
+		}
+	}
+}
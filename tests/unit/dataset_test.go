@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+func TestDatasetAppendAndRead(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "labeled.jsonl")
+
+	for _, s := range []dataset.Sample{
+		{RequestID: "a", Label: "bot", Fingerprint: fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.0"}}},
+		{RequestID: "b", Label: "browser", Predicted: "bot", Note: "privacy browser"},
+	} {
+		w, err := dataset.Append(path)
+		if err != nil {
+			t.Fatalf("Append() error = %v", err)
+		}
+		if err := w.Write(s); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatalf("Close() error = %v", err)
+		}
+	}
+
+	samples, err := dataset.ReadFile(path)
+	if err != nil {
+		t.Fatalf("ReadFile() error = %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("ReadFile() returned %d samples, want 2", len(samples))
+	}
+	if samples[0].Fingerprint.HTTP.UserAgent != "curl/8.0" {
+		t.Errorf("samples[0] UA = %q, want curl/8.0", samples[0].Fingerprint.HTTP.UserAgent)
+	}
+	if samples[1].Note != "privacy browser" || samples[1].Predicted != "bot" {
+		t.Errorf("samples[1] = %+v, want note and predicted preserved", samples[1])
+	}
+	if samples[0].LabeledAt.IsZero() {
+		t.Error("Write() should set LabeledAt")
+	}
+
+	labeled := dataset.Labeled(samples)
+	if !labeled["a"] || !labeled["b"] || len(labeled) != 2 {
+		t.Errorf("Labeled() = %v, want {a, b}", labeled)
+	}
+}
+
+func TestDatasetReadFile_Missing(t *testing.T) {
+	samples, err := dataset.ReadFile(filepath.Join(t.TempDir(), "missing.jsonl"))
+	if err != nil || len(samples) != 0 {
+		t.Errorf("ReadFile(missing) = %d samples, %v; want empty, nil", len(samples), err)
+	}
+}
+
+func TestDatasetRejectsUnknownLabel(t *testing.T) {
+	w, err := dataset.Append(filepath.Join(t.TempDir(), "labeled.jsonl"))
+	if err != nil {
+		t.Fatalf("Append() error = %v", err)
+	}
+	defer func() { _ = w.Close() }()
+
+	if err := w.Write(dataset.Sample{Label: "human"}); err == nil {
+		t.Error("Write() should reject unknown label")
+	}
+
+	_, err = dataset.Read(strings.NewReader(`{"label":"bot"}` + "\n" + `{"label":"maybe"}` + "\n"))
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Read() error = %v, want invalid label on line 2", err)
+	}
+}
+
+func TestDatasetWriterStoresFeedback(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feedback.jsonl")
+	w, err := dataset.Append(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var store classifier.FeedbackStore = w
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.0"}}
+	if err := store.Store(classifier.Feedback{RequestID: "r1", Label: "browser", Fingerprint: fp, Predicted: "bot", Note: "monitor"}); err != nil {
+		t.Fatalf("Store() error = %v", err)
+	}
+	if err := store.Store(classifier.Feedback{RequestID: "r2", Label: "human"}); err == nil {
+		t.Error("Store() with an invalid label: error = nil")
+	}
+	_ = w.Close()
+
+	samples, err := dataset.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(samples) != 1 {
+		t.Fatalf("read %d samples, want 1", len(samples))
+	}
+	s := samples[0]
+	if s.RequestID != "r1" || s.Label != "browser" || s.Predicted != "bot" || s.Source != dataset.FeedbackSource ||
+		s.Note != "monitor" || s.Fingerprint.HTTP.UserAgent != "curl/8.0" || s.LabeledAt.IsZero() {
+		t.Errorf("sample = %+v", s)
+	}
+}
@@ -0,0 +1,82 @@
+package unit
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/edge"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+func TestEdgeClassifyRequestJSON(t *testing.T) {
+	e := edge.New(classifier.DefaultConfig())
+
+	input := `{"method":"GET","proto":"HTTP/1.1","path":"/","headers":{"User-Agent":["curl/8.0.1"],"Accept":["*/*"]}}`
+	out, err := e.ClassifyRequestJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("ClassifyRequestJSON() error = %v", err)
+	}
+
+	var result fingerprint.ClassificationResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if result.Classification != classifier.ClassificationBot {
+		t.Errorf("ClassifyRequestJSON(curl) = %s, want %s", result.Classification, classifier.ClassificationBot)
+	}
+	if result.Fingerprint.TLS.Available {
+		t.Error("Edge classification should not report TLS data")
+	}
+	if !strings.HasPrefix(result.Fingerprint.HTTP.JA4HHash, "ge11nn02") {
+		t.Errorf("JA4H = %q, want prefix ge11nn02", result.Fingerprint.HTTP.JA4HHash)
+	}
+}
+
+func TestEdgeClassifyFingerprintJSON(t *testing.T) {
+	e := edge.New(classifier.DefaultConfig())
+
+	input := `{"http":{"version":"HTTP/1.1","user_agent":"python-requests/2.31.0","header_count":3}}`
+	out, err := e.ClassifyFingerprintJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("ClassifyFingerprintJSON() error = %v", err)
+	}
+
+	var result fingerprint.ClassificationResult
+	if err := json.Unmarshal(out, &result); err != nil {
+		t.Fatalf("Failed to decode result: %v", err)
+	}
+	if result.Classification != classifier.ClassificationBot {
+		t.Errorf("ClassifyFingerprintJSON(python) = %s, want %s", result.Classification, classifier.ClassificationBot)
+	}
+}
+
+func TestEdgeClassifyRequestJSON_Invalid(t *testing.T) {
+	e := edge.New(classifier.DefaultConfig())
+
+	if _, err := e.ClassifyRequestJSON([]byte("not json")); err == nil {
+		t.Error("ClassifyRequestJSON() should fail on invalid JSON")
+	}
+	if _, err := e.ClassifyRequestJSON([]byte(`{"path":"no-slash"}`)); err == nil {
+		t.Error("ClassifyRequestJSON() should reject relative paths")
+	}
+}
+
+func TestEdgeErrorJSON(t *testing.T) {
+	out := edge.ErrorJSON(errTest("boom"))
+
+	var v struct {
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(out, &v); err != nil {
+		t.Fatalf("ErrorJSON() is not valid JSON: %v", err)
+	}
+	if v.Error != "boom" {
+		t.Errorf("ErrorJSON().error = %q, want %q", v.Error, "boom")
+	}
+}
+
+type errTest string
+
+func (e errTest) Error() string { return string(e) }
@@ -0,0 +1,93 @@
+package unit
+
+import (
+	"encoding/json"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/buildinfo"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+// plainResponse has Response's fields and tags but not its marshaler, so
+// encoding/json renders it by reflection
+type plainResponse server.Response
+
+func TestResponse_AppendJSONMatchesEncodingJSON(t *testing.T) {
+	ts := time.Date(2026, 3, 14, 15, 9, 26, 535897932, time.UTC)
+	tests := []server.Response{
+		{Classification: "bot", Confidence: 0.85, Message: "You appear to be using an automated client", RequestID: "req-1", Timestamp: ts, Version: "0.4.0"},
+		{Classification: "browser", Confidence: 1, Message: "You appear to be using a browser", Timestamp: ts.Truncate(time.Second)},
+		{Confidence: 0, Timestamp: ts.In(time.FixedZone("", -7*3600))},
+		{Confidence: 1e-7, Message: "tiny"},
+		{Confidence: 1.5e21, Message: "huge"},
+		{Confidence: -0.333333333333},
+		{Message: "quote \" backslash \\ html <b>&amp;</b> ctrl \x00\x1f\b\f\n\r\t"},
+		{Message: "unicode é ✓ 𝄞 line\u2028para\u2029 invalid \xff\xfe end"},
+		{Classification: "bot", Class: "search_bot", ClassConfidence: map[string]float64{
+			"unknown": 0, "search_bot": 0.9, "browser": 0.1, "ai_crawler": 0, "http_library": 0, "headless_browser": 0,
+		}},
+		{Class: "x", ClassConfidence: map[string]float64{"b": 1e-7, "a": 0.5, "<c>": 1}},
+	}
+
+	for i, resp := range tests {
+		want, err := json.Marshal(plainResponse(resp))
+		if err != nil {
+			t.Fatalf("case %d: json.Marshal: %v", i, err)
+		}
+		if got := resp.AppendJSON(nil); string(got) != string(want) {
+			t.Errorf("case %d:\n got %s\nwant %s", i, got, want)
+		}
+		// json.Marshal picks up the hand-written marshaler
+		if got, err := json.Marshal(resp); err != nil || string(got) != string(want) {
+			t.Errorf("case %d: Marshal = %s, %v", i, got, err)
+		}
+	}
+}
+
+func TestResponse_AppendJSONNonFinite(t *testing.T) {
+	for _, f := range []float64{math.NaN(), math.Inf(1), math.Inf(-1)} {
+		var v map[string]any
+		if err := json.Unmarshal(server.Response{Confidence: f}.AppendJSON(nil), &v); err != nil {
+			t.Fatalf("confidence %v: invalid JSON: %v", f, err)
+		}
+		if v["confidence"] != 0.0 {
+			t.Errorf("confidence %v encoded as %v, want 0", f, v["confidence"])
+		}
+	}
+}
+
+func TestResponse_AppendJSONAllocs(t *testing.T) {
+	resp := server.Response{
+		Classification: "browser",
+		Confidence:     0.92,
+		Message:        "You appear to be using a browser",
+		RequestID:      "5f0c8a4e-1d2b-4c3a-9e7f-0a1b2c3d4e5f",
+		Timestamp:      time.Now(),
+		Version:        "0.4.0",
+
+		Class:           "browser",
+		ClassConfidence: map[string]float64{"browser": 0.92, "unknown": 0.08, "ai_crawler": 0, "search_bot": 0, "headless_browser": 0, "http_library": 0},
+	}
+	buf := make([]byte, 0, 512)
+	if n := testing.AllocsPerRun(100, func() { buf = resp.AppendJSON(buf[:0]) }); n != 0 {
+		t.Errorf("AppendJSON allocated %v times per run, want 0", n)
+	}
+}
+
+func TestHandler_HealthBody(t *testing.T) {
+	h := createTestHandler()
+	rr := httptest.NewRecorder()
+	h.HandleHealth(rr, httptest.NewRequest(http.MethodGet, "/health", nil))
+
+	if ct := rr.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	want, _ := json.Marshal(server.HealthResponse{Status: "ok", Version: buildinfo.Version()})
+	if rr.Body.String() != string(want)+"\n" {
+		t.Errorf("body = %q, want %q", rr.Body.String(), want)
+	}
+}
@@ -0,0 +1,111 @@
+package unit
+
+import (
+	"math"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/evaluate"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+func evaluationSamples() []dataset.Sample {
+	browser := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+		Version:      "HTTP/2.0",
+		UserAgent:    "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
+		Accept:       "text/html,application/xhtml+xml",
+		AcceptLang:   "en-US,en;q=0.9",
+		SecFetchSite: "none",
+		SecFetchMode: "navigate",
+		HeaderCount:  12,
+	}}
+	curl := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+		Version:     "HTTP/1.1",
+		UserAgent:   "curl/8.0.1",
+		Accept:      "*/*",
+		HeaderCount: 3,
+	}}
+
+	return []dataset.Sample{
+		{Label: "browser", Fingerprint: browser},
+		{Label: "browser", Fingerprint: browser},
+		{Label: "bot", Fingerprint: curl},
+		{Label: "bot", Fingerprint: curl},
+		// A bot disguised as a browser is misclassified
+		{Label: "bot", Fingerprint: browser},
+	}
+}
+
+func TestEvaluateRun_Metrics(t *testing.T) {
+	report := evaluate.Run(evaluationSamples(), classifier.DefaultConfig())
+
+	if report.Samples != 5 {
+		t.Fatalf("Samples = %d, want 5", report.Samples)
+	}
+	if got := report.Confusion["bot"]["browser"]; got != 1 {
+		t.Errorf("Confusion[bot][browser] = %d, want 1", got)
+	}
+	if got := report.Confusion["bot"]["bot"]; got != 2 {
+		t.Errorf("Confusion[bot][bot] = %d, want 2", got)
+	}
+	if math.Abs(report.Accuracy-0.8) > 1e-9 {
+		t.Errorf("Accuracy = %v, want 0.8", report.Accuracy)
+	}
+
+	bot := report.Class("bot")
+	if bot.Precision != 1 || math.Abs(bot.Recall-2.0/3) > 1e-9 || bot.Support != 3 {
+		t.Errorf("bot metrics = %+v, want precision 1, recall 2/3, support 3", bot)
+	}
+	if math.Abs(bot.F1-0.8) > 1e-9 {
+		t.Errorf("bot F1 = %v, want 0.8", bot.F1)
+	}
+
+	browser := report.Class("browser")
+	if math.Abs(browser.Precision-2.0/3) > 1e-9 || browser.Recall != 1 {
+		t.Errorf("browser metrics = %+v, want precision 2/3, recall 1", browser)
+	}
+}
+
+func TestEvaluateRun_Ablation(t *testing.T) {
+	report := evaluate.Run(evaluationSamples(), classifier.DefaultConfig())
+
+	if len(report.Ablation) == 0 {
+		t.Fatal("Ablation should not be empty")
+	}
+
+	var botUA *evaluate.Ablation
+	for i := range report.Ablation {
+		if report.Ablation[i].Signal == "bot-ua" {
+			botUA = &report.Ablation[i]
+		}
+	}
+	if botUA == nil {
+		t.Fatal("Ablation should include bot-ua")
+	}
+	if botUA.Side != "bot" || botUA.Weight != 3 || botUA.Fired != 2 {
+		t.Errorf("bot-ua ablation = %+v, want side bot, weight 3, fired 2", *botUA)
+	}
+
+	// Sorted by largest loss first
+	for i := 1; i < len(report.Ablation); i++ {
+		if report.Ablation[i].DeltaF1 < report.Ablation[i-1].DeltaF1 {
+			t.Fatalf("Ablation not sorted by DeltaF1 at %d", i)
+		}
+	}
+}
+
+func TestEvaluateRun_Threshold(t *testing.T) {
+	// A threshold no browser can reach classifies everything as bot
+	report := evaluate.Run(evaluationSamples(), classifier.NewConfig(classifier.WithThreshold(100)))
+
+	if report.Threshold != 100 {
+		t.Errorf("Threshold = %d, want 100", report.Threshold)
+	}
+	if got := report.Class("browser").Recall; got != 0 {
+		t.Errorf("browser recall = %v, want 0", got)
+	}
+	if got := report.Class("bot").Recall; got != 1 {
+		t.Errorf("bot recall = %v, want 1", got)
+	}
+}
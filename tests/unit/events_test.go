@@ -0,0 +1,280 @@
+package unit
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/events"
+)
+
+// recordingSink collects the events it is sent
+type recordingSink struct {
+	mu     sync.Mutex
+	events []events.Event
+	closed bool
+}
+
+func (s *recordingSink) Send(e events.Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.events = append(s.events, e)
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.closed = true
+	return nil
+}
+
+func TestBus_SubscribeAndSinks(t *testing.T) {
+	b := events.NewBus()
+	sink := &recordingSink{}
+	b.AddSink(sink, 0)
+	ch, unsubscribe := b.Subscribe(1)
+	late, _ := b.Subscribe(10)
+
+	b.Publish(events.Event{Type: events.Classified, RequestID: "1"})
+	b.Publish(events.Event{Type: events.Blocked, RequestID: "1"})
+
+	// The one-event subscriber dropped the second event
+	if e := <-ch; e.Type != events.Classified {
+		t.Errorf("first event = %+v", e)
+	}
+	if b.Dropped() != 1 {
+		t.Errorf("Dropped() = %d, want 1", b.Dropped())
+	}
+	unsubscribe()
+	if _, ok := <-ch; ok {
+		t.Error("channel should be closed on unsubscribe")
+	}
+	unsubscribe()
+
+	if err := b.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	// Close flushes sinks in publishing order and closes them
+	if len(sink.events) != 2 || sink.events[0].Type != events.Classified || sink.events[1].Type != events.Blocked || !sink.closed {
+		t.Errorf("sink got %+v, closed = %v", sink.events, sink.closed)
+	}
+	n := 0
+	for range late {
+		n++
+	}
+	if n != 2 {
+		t.Errorf("subscriber received %d events before close, want 2", n)
+	}
+
+	// Publishing after close is a no-op
+	b.Publish(events.Event{Type: events.Classified})
+	if err := b.Close(); err != nil {
+		t.Errorf("second Close() error = %v", err)
+	}
+}
+
+func TestNATS_Publish(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = ln.Close() }()
+
+	type received struct {
+		connect, pong string
+		subject       string
+		payload       []byte
+		err           error
+	}
+	done := make(chan received, 1)
+	go func() {
+		var rec received
+		defer func() { done <- rec }()
+		conn, err := ln.Accept()
+		if err != nil {
+			rec.err = err
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		r := bufio.NewReader(conn)
+		_, _ = conn.Write([]byte(`INFO {"server_id":"test","max_payload":1048576}` + "\r\n"))
+		rec.connect, _ = r.ReadString('\n')
+
+		// The client answers keep-alive pings, possibly after publishing
+		_, _ = conn.Write([]byte("PING\r\n"))
+		for rec.pong == "" || rec.payload == nil {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				rec.err = err
+				return
+			}
+			fields := strings.Fields(line)
+			switch {
+			case line == "PONG\r\n":
+				rec.pong = line
+			case len(fields) == 3 && fields[0] == "PUB":
+				rec.subject = fields[1]
+				size, _ := strconv.Atoi(fields[2])
+				rec.payload = make([]byte, size+2)
+				if _, rec.err = io.ReadFull(r, rec.payload); rec.err != nil {
+					return
+				}
+				rec.payload = rec.payload[:size]
+			default:
+				rec.err = fmt.Errorf("unexpected line %q", line)
+				return
+			}
+		}
+	}()
+
+	n, err := events.NewNATS("nats://bob:secret@"+ln.Addr().String(), "bots.events")
+	if err != nil {
+		t.Fatalf("NewNATS() error = %v", err)
+	}
+	defer func() { _ = n.Close() }()
+
+	if err := n.Send(events.Event{Type: events.Blocked, RequestID: "r1", ClientAddr: "203.0.113.7"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	var rec received
+	select {
+	case rec = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("fake NATS server timed out")
+	}
+	if rec.err != nil {
+		t.Fatalf("fake NATS server: %v", rec.err)
+	}
+	if !strings.HasPrefix(rec.connect, "CONNECT ") || !strings.Contains(rec.connect, `"user":"bob"`) || !strings.Contains(rec.connect, `"pass":"secret"`) {
+		t.Errorf("CONNECT = %q", rec.connect)
+	}
+	if rec.pong != "PONG\r\n" {
+		t.Errorf("reply to PING = %q", rec.pong)
+	}
+	var e events.Event
+	if err := json.Unmarshal(rec.payload, &e); err != nil || rec.subject != "bots.events" || e.Type != events.Blocked || e.RequestID != "r1" {
+		t.Errorf("PUB %s %s (%v)", rec.subject, rec.payload, err)
+	}
+}
+
+func TestNATS_Config(t *testing.T) {
+	for _, tt := range []struct{ url, subject string }{
+		{"tls://host:4222", "s"},
+		{"nats://", "s"},
+		{"nats://host", ""},
+		{"nats://host", "two words"},
+	} {
+		if _, err := events.NewNATS(tt.url, tt.subject); err == nil {
+			t.Errorf("NewNATS(%q, %q) should fail", tt.url, tt.subject)
+		}
+	}
+
+	// Unreachable servers fail the send, not the constructor
+	n, err := events.NewNATS("nats://127.0.0.1:1", "s")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Send(events.Event{Type: events.Classified}); err == nil {
+		t.Error("Send() to an unreachable server should fail")
+	}
+}
+
+func TestKafkaREST_Send(t *testing.T) {
+	var got struct {
+		path, contentType string
+		body              []byte
+	}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got.path, got.contentType = r.URL.Path, r.Header.Get("Content-Type")
+		got.body, _ = io.ReadAll(r.Body)
+		if strings.HasSuffix(r.URL.Path, "/missing") {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"error_code":40401,"message":"Topic not found"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"offsets":[{"partition":0,"offset":1}]}`))
+	}))
+	defer ts.Close()
+
+	k, err := events.NewKafkaREST(ts.URL+"/", "bot-events")
+	if err != nil {
+		t.Fatalf("NewKafkaREST() error = %v", err)
+	}
+	defer func() { _ = k.Close() }()
+	if err := k.Send(events.Event{Type: events.Challenged, ClientAddr: "203.0.113.7", Action: events.ActionCaptcha}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if got.path != "/topics/bot-events" || got.contentType != "application/vnd.kafka.json.v2+json" {
+		t.Errorf("POST %s (%s)", got.path, got.contentType)
+	}
+	var body struct {
+		Records []struct {
+			Key   string       `json:"key"`
+			Value events.Event `json:"value"`
+		} `json:"records"`
+	}
+	if err := json.Unmarshal(got.body, &body); err != nil || len(body.Records) != 1 {
+		t.Fatalf("body = %s (%v)", got.body, err)
+	}
+	if r := body.Records[0]; r.Key != "203.0.113.7" || r.Value.Type != events.Challenged || r.Value.Action != events.ActionCaptcha {
+		t.Errorf("record = %+v", r)
+	}
+
+	missing, _ := events.NewKafkaREST(ts.URL, "missing")
+	if err := missing.Send(events.Event{Type: events.Classified}); err == nil || !strings.Contains(err.Error(), "Topic not found") {
+		t.Errorf("Send() to a missing topic error = %v", err)
+	}
+	if _, err := events.NewKafkaREST("kafka://broker:9092", "t"); err == nil {
+		t.Error("NewKafkaREST should require an http(s) URL")
+	}
+}
+
+func TestHandler_Events(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetCaptcha(newTestCaptcha(t, captcha.Turnstile, newSiteverify(t).URL), captcha.NewStore(captcha.DefaultStoreConfig()))
+	b := events.NewBus()
+	h.SetEvents(b)
+	ch, _ := b.Subscribe(10)
+
+	// A bot navigating to a page is classified, blocked and challenged
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "HeadlessChrome/120.0")
+	req.Header.Set("Accept", "text/html")
+	req.RemoteAddr = "203.0.113.7:4242"
+	h.HandleClassify(httptest.NewRecorder(), req)
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []events.Event
+	for e := range ch {
+		got = append(got, e)
+	}
+	if len(got) != 3 {
+		t.Fatalf("got %d events, want 3: %+v", len(got), got)
+	}
+	for i, typ := range []events.Type{events.Classified, events.Blocked, events.Challenged} {
+		e := got[i]
+		if e.Type != typ || e.Classification != "bot" || e.RequestID == "" || e.ClientAddr != "203.0.113.7:4242" {
+			t.Errorf("event %d = %+v, want %s", i, e, typ)
+		}
+		if e.BotScore < 1 || e.BotScore > 29 {
+			t.Errorf("event %d bot score = %d", i, e.BotScore)
+		}
+	}
+	if got[2].Action != events.ActionCaptcha {
+		t.Errorf("challenge action = %q, want captcha", got[2].Action)
+	}
+}
@@ -0,0 +1,52 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+func TestExtractSignals_LibraryExtensionOrder(t *testing.T) {
+	tests := []struct {
+		name string
+		exts []int
+		want string
+	}{
+		{"go", []int{0, 11, 65281, 23, 18, 5, 10, 13, 50, 16, 43, 51}, "go"},
+		{"go tls 1.2", []int{0, 11, 65281, 23, 18, 5, 10, 13, 50, 43}, "go"},
+		{"curl", []int{0, 11, 10, 35, 16, 22, 23, 13, 43, 45, 51}, "openssl"},
+		{"python", []int{0, 11, 10, 35, 16, 22, 23, 49, 13, 43, 45, 51, 21}, "openssl"},
+		{"firefox", []int{0, 23, 65281, 10, 11, 35, 16, 5, 34, 51, 43, 13, 45, 28, 27, 65037}, ""},
+		{"safari", []int{0x0a0a, 0, 23, 65281, 10, 11, 16, 5, 13, 18, 51, 45, 43, 27, 21, 0x2a2a}, ""},
+		{"chrome", []int{0x3a3a, 45, 51, 65281, 17513, 0, 5, 10, 11, 23, 35, 13, 18, 16, 27, 43, 65037, 0x8a8a}, ""},
+		{"go with grease", []int{0x1a1a, 0, 11, 65281, 23, 18, 5, 10, 13, 50, 43, 51}, "go"},
+		{"too few", []int{0, 10, 13, 43, 51}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := interceptedChrome()
+			fp.TLS.Extensions = tt.exts
+			fp.TLS.NoGREASE = false // Not taken for a proxy
+			s := fingerprint.ExtractSignals(fp)
+			if s.ExtensionOrderStack != tt.want || s.LibraryExtensionOrder != (tt.want != "") {
+				t.Errorf("LibraryExtensionOrder = %v (%q), want %q", s.LibraryExtensionOrder, s.ExtensionOrderStack, tt.want)
+			}
+			if scored := strings.Contains(s.ScoreBreakdown.String(), "library-ext-order(+2)"); scored != (tt.want != "") {
+				t.Errorf("library-ext-order scored = %v: %s", scored, s.ScoreBreakdown)
+			}
+		})
+	}
+
+	// A library User-Agent is not contradicted by its own TLS stack, and an
+	// intercepting proxy's order is not the browser's
+	fp := interceptedChrome()
+	fp.TLS.Extensions = tests[2].exts
+	if s := fingerprint.ExtractSignals(fp); !s.TLSIntercepted || s.LibraryExtensionOrder {
+		t.Errorf("intercepted: TLSIntercepted = %v, LibraryExtensionOrder = %v", s.TLSIntercepted, s.LibraryExtensionOrder)
+	}
+	fp.HTTP.UserAgent = "curl/8.4.0"
+	if s := fingerprint.ExtractSignals(fp); s.LibraryExtensionOrder {
+		t.Error("curl User-Agent flagged for OpenSSL's extension order")
+	}
+}
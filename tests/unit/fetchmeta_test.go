@@ -0,0 +1,84 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+const chromeNavigationAccept = "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8"
+
+func TestExtractSignals_FetchMetadataMismatch(t *testing.T) {
+	tests := []struct {
+		name                     string
+		mode, dest, user, accept string
+		xrw                      string
+		want                     string
+	}{
+		{"navigation", "navigate", "document", "?1", chromeNavigationAccept, "", ""},
+		{"iframe", "navigate", "iframe", "", chromeNavigationAccept, "", ""},
+		{"fetch", "cors", "empty", "", "*/*", "", ""},
+		{"json fetch", "cors", "empty", "", "application/json", "XMLHttpRequest", ""},
+		{"image", "no-cors", "image", "", "image/avif,image/webp,image/apng,image/svg+xml,image/*,*/*;q=0.8", "", ""},
+		{"style", "no-cors", "style", "", "text/css,*/*;q=0.1", "", ""},
+		{"script", "no-cors", "script", "", "*/*", "", ""},
+		{"webview navigation", "navigate", "document", "?1", chromeNavigationAccept, "com.example.app", ""},
+		{"no sec-fetch", "", "", "", "*/*", "", ""},
+
+		{"document with */*", "navigate", "document", "?1", "*/*", "", "accept"},
+		{"document without accept", "navigate", "document", "", "", "", "accept"},
+		{"navigate for json", "navigate", "", "", "application/json", "", "accept"},
+		{"navigate to script", "navigate", "script", "", "*/*", "", "sec-fetch-dest"},
+		{"document by fetch", "cors", "document", "", chromeNavigationAccept, "", "sec-fetch-mode"},
+		{"xhr navigation", "navigate", "document", "", chromeNavigationAccept, "XMLHttpRequest", "x-requested-with"},
+		{"user on fetch", "cors", "empty", "?1", "*/*", "", "sec-fetch-user"},
+		{"image with html accept", "no-cors", "image", "", "text/html", "", "accept"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+				UserAgent:    chromeUA,
+				Accept:       tt.accept,
+				SecFetchMode: tt.mode,
+				SecFetchDest: tt.dest,
+				SecFetchUser: tt.user,
+				Headers:      map[string]string{},
+			}}
+			if tt.xrw != "" {
+				fp.HTTP.Headers["x-requested-with"] = tt.xrw
+			}
+			s := fingerprint.ExtractSignals(fp)
+			if s.FetchMetadataConflict != tt.want || s.FetchMetadataMismatch != (tt.want != "") {
+				t.Errorf("FetchMetadataMismatch = %v (%q), want %q", s.FetchMetadataMismatch, s.FetchMetadataConflict, tt.want)
+			}
+			if scored := strings.Contains(s.ScoreBreakdown.String(), "fetch-metadata-mismatch(+2)"); scored != (tt.want != "") {
+				t.Errorf("fetch-metadata-mismatch scored = %v: %s", scored, s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestExtractSignals_FetchMetadata_GoldenBrowsers(t *testing.T) {
+	entries, err := fingerprint.GoldenFamily(fingerprint.GoldenBrowser)
+	if err != nil {
+		t.Fatalf("GoldenFamily() error = %v", err)
+	}
+	for _, e := range entries {
+		if s := fingerprint.ExtractSignals(e.Fingerprint); s.FetchMetadataMismatch {
+			t.Errorf("%s: Sec-Fetch metadata contradicted by %s", e.ID, s.FetchMetadataConflict)
+		}
+	}
+}
+
+func TestVerdictKey_FetchMetadata(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		TLS:  fingerprint.TLSFingerprint{JA4Hash: "t13d1516h2_8daaf6152771_02713d6af862"},
+		HTTP: fingerprint.HTTPFingerprint{UserAgent: chromeUA, Accept: chromeNavigationAccept, SecFetchMode: "navigate", SecFetchDest: "document"},
+	}
+	consistent, _ := fingerprint.VerdictKey(fp)
+	fp.HTTP.Accept = "*/*"
+	if spoofed, _ := fingerprint.VerdictKey(fp); spoofed == consistent {
+		t.Error("VerdictKey ignores a Sec-Fetch mismatch")
+	}
+}
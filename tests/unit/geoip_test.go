@@ -0,0 +1,130 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/geoip"
+)
+
+const geoCSV = `start_ip,end_ip,country
+# DB-IP Lite rows
+1.0.0.0,1.0.0.255,AU
+5.1.0.0,5.1.255.255,de
+2001:db8::,2001:db8::ffff,FR
+# network,country rows
+203.0.113.0/24,JP
+`
+
+func TestGeoIPParse_Lookup(t *testing.T) {
+	db, err := geoip.Parse(strings.NewReader(geoCSV))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if db.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", db.Len())
+	}
+	tests := []struct {
+		addr, want string
+	}{
+		{"1.0.0.0", "AU"},
+		{"1.0.0.255", "AU"},
+		{"5.1.200.3", "DE"},
+		{"::ffff:5.1.0.1", "DE"},
+		{"2001:db8::1234", "FR"},
+		{"203.0.113.77", "JP"},
+		{"1.0.1.0", ""},
+		{"8.8.8.8", ""},
+		{"0.0.0.1", ""},
+		{"2001:db8::1:0", ""},
+		{"::1", ""},
+	}
+	for _, tt := range tests {
+		got, ok := db.Country(netip.MustParseAddr(tt.addr))
+		if got != tt.want || ok != (tt.want != "") {
+			t.Errorf("Country(%s) = %q, %v, want %q", tt.addr, got, ok, tt.want)
+		}
+	}
+}
+
+func TestGeoIPParse_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"header only":    "network,country\n",
+		"bad country":    "1.0.0.0/24,AU\n2.0.0.0/24,AUS\n",
+		"bad network":    "1.0.0.0/24,AU\n2.0.0.0/33,DE\n",
+		"reversed range": "1.0.0.0/24,AU\n2.0.0.9,2.0.0.1,DE\n",
+		"mixed families": "1.0.0.0/24,AU\n2.0.0.0,2001:db8::,DE\n",
+	}
+	for name, in := range tests {
+		if _, err := geoip.Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: Parse() error = nil, want error", name)
+		}
+	}
+}
+
+func TestGeoIPEnricher(t *testing.T) {
+	db, err := geoip.Parse(strings.NewReader(geoCSV))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	e := geoip.NewEnricher(db)
+	for addr, want := range map[string]string{
+		"5.1.0.9:4711":      "DE",
+		"[2001:db8::9]:443": "FR",
+		"8.8.8.8:4711":      "",
+		"10.0.0.2:4711":     "", // Load balancer
+		"127.0.0.1:4711":    "",
+	} {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = addr
+		var fp fingerprint.Fingerprint
+		if err := e.Enrich(context.Background(), r, &fp); err != nil {
+			t.Fatalf("Enrich() error = %v", err)
+		}
+		if fp.Network.Country != want {
+			t.Errorf("%s: Country = %q, want %q", addr, fp.Network.Country, want)
+		}
+	}
+}
+
+func TestExtractSignals_GeoLangCorroborated(t *testing.T) {
+	browser := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:      "HTTP/1.1",
+			UserAgent:    chromeUA,
+			Accept:       chromeNavigationAccept,
+			AcceptLang:   "zh-CN,zh;q=0.9",
+			AcceptEnc:    "gzip, deflate, br",
+			SecFetchSite: "none",
+			SecFetchMode: "navigate",
+			SecFetchDest: "document",
+			HeaderCount:  12,
+		},
+		Network: fingerprint.NetworkFingerprint{Country: "DE"},
+	}
+	s := fingerprint.ExtractSignals(browser)
+	if !s.GeoLanguageMismatch {
+		t.Fatal("GeoLanguageMismatch = false, want true")
+	}
+	if strings.Contains(s.ScoreBreakdown.String(), "geo-lang-corroborated") {
+		t.Errorf("mismatch alone corroborated: %s", s.ScoreBreakdown)
+	}
+
+	scripted := browser
+	scripted.HTTP.UserAgent = "python-requests/2.31.0"
+	s = fingerprint.ExtractSignals(scripted)
+	if !strings.Contains(s.ScoreBreakdown.String(), "geo-lang-corroborated(+2)") {
+		t.Errorf("breakdown = %s, want geo-lang-corroborated(+2)", s.ScoreBreakdown)
+	}
+
+	scripted.HTTP.AcceptLang = "de-DE"
+	s = fingerprint.ExtractSignals(scripted)
+	if strings.Contains(s.ScoreBreakdown.String(), "geo-lang") {
+		t.Errorf("fitting language scored: %s", s.ScoreBreakdown)
+	}
+}
@@ -0,0 +1,108 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	pkgfingerprint "github.com/muliwe/go-client-classifier/pkg/fingerprint"
+)
+
+// goldenKnownMisses are golden entries the default rules misclassify.
+// Remove an entry once the rules get it right; the test fails if a listed
+// entry starts classifying correctly, so the list cannot go stale.
+var goldenKnownMisses = map[string]string{
+	// undici sends Sec-Fetch-Mode: cors and Accept-Language: *, and its
+	// OpenSSL hello scores like a browser
+	"node-fetch-20": "browser",
+}
+
+func TestGoldenCorpus_Loads(t *testing.T) {
+	all, err := fingerprint.Golden()
+	if err != nil {
+		t.Fatalf("Golden() error = %v", err)
+	}
+
+	families := map[string]int{}
+	for _, e := range all {
+		families[e.Family]++
+		if e.Source == "" || e.Client == "" {
+			t.Errorf("%s: source and client should be set", e.ID)
+		}
+		if e.Fingerprint.HTTP.UserAgent == "" || e.Fingerprint.HTTP.JA4HHash == "" {
+			t.Errorf("%s: HTTP fingerprint should carry a User-Agent and JA4H", e.ID)
+		}
+		if e.Family != fingerprint.GoldenAICrawler && !e.Fingerprint.TLS.Available {
+			t.Errorf("%s: browsers and libraries should carry a captured TLS fingerprint", e.ID)
+		}
+		if e.Fingerprint.TLS.Available && !strings.HasPrefix(e.Fingerprint.TLS.JA4Hash, "t13") {
+			t.Errorf("%s: JA4 = %q, want a TLS 1.3 fingerprint", e.ID, e.Fingerprint.TLS.JA4Hash)
+		}
+	}
+	for _, f := range []string{fingerprint.GoldenBrowser, fingerprint.GoldenLibrary, fingerprint.GoldenAICrawler} {
+		if families[f] < 5 {
+			t.Errorf("corpus has %d %s entries, want at least 5", families[f], f)
+		}
+	}
+
+	browsers, err := fingerprint.GoldenFamily(fingerprint.GoldenBrowser)
+	if err != nil {
+		t.Fatalf("GoldenFamily() error = %v", err)
+	}
+	if len(browsers) != families[fingerprint.GoldenBrowser] {
+		t.Errorf("GoldenFamily(browser) = %d entries, want %d", len(browsers), families[fingerprint.GoldenBrowser])
+	}
+
+	// Each call returns an independent copy
+	browsers[0].ID = "changed"
+	again, _ := fingerprint.GoldenFamily(fingerprint.GoldenBrowser)
+	if again[0].ID == "changed" {
+		t.Error("Golden() should not share entries between calls")
+	}
+}
+
+func TestGoldenCorpus_Classification(t *testing.T) {
+	all, err := pkgfingerprint.Golden()
+	if err != nil {
+		t.Fatalf("Golden() error = %v", err)
+	}
+	c := classifier.New()
+	for _, e := range all {
+		got := c.Classify(e.Fingerprint)
+		if miss, ok := goldenKnownMisses[e.ID]; ok {
+			if got.Classification != miss {
+				t.Errorf("%s: known miss now classified as %s, remove it from goldenKnownMisses", e.ID, got.Classification)
+			}
+			continue
+		}
+		if got.Classification != e.Expect {
+			t.Errorf("%s: classified as %s (score %+d), want %s\n  %s", e.ID, got.Classification, got.Score, e.Expect, got.Signals.ScoreBreakdown)
+		}
+	}
+}
+
+func TestReadGolden_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"not json", "{"},
+		{"missing id", `{"family":"browser","expect":"browser"}`},
+		{"unknown family", `{"id":"a","family":"robot","expect":"bot"}`},
+		{"bad expect", `{"id":"a","family":"library","expect":"crawler"}`},
+		{"duplicate id", `{"id":"a","family":"library","expect":"bot"}` + "\n" + `{"id":"a","family":"library","expect":"bot"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := fingerprint.ReadGolden(strings.NewReader(tt.input)); err == nil {
+				t.Error("ReadGolden() should fail")
+			}
+		})
+	}
+
+	entries, err := fingerprint.ReadGolden(strings.NewReader("\n" + `{"id":"a","family":"library","expect":"bot"}` + "\n\n"))
+	if err != nil || len(entries) != 1 {
+		t.Errorf("ReadGolden() = %d entries, %v; want 1 entry", len(entries), err)
+	}
+}
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"context"
+	"io"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/pkg/pb/classifierv1"
+)
+
+// newTestGRPCClient serves the gRPC service over an in-memory connection
+func newTestGRPCClient(t *testing.T) classifierv1.ClassifierServiceClient {
+	t.Helper()
+
+	h := createTestHandler()
+	h.SetQuiet(true)
+
+	lis := bufconn.Listen(1 << 20)
+	srv := grpc.NewServer()
+	server.NewGRPCService(h).Register(srv)
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return lis.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("grpc.NewClient() error = %v", err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return classifierv1.NewClassifierServiceClient(conn)
+}
+
+func curlMetadata() *classifierv1.RequestMetadata {
+	return classifierv1.FromRequestMetadata(fingerprint.RequestMetadata{
+		Method:  "GET",
+		Proto:   "HTTP/1.1",
+		Path:    "/",
+		Headers: map[string][]string{"User-Agent": {"curl/8.0.1"}, "Accept": {"*/*"}},
+	})
+}
+
+func TestGRPC_ClassifyRequest(t *testing.T) {
+	c := newTestGRPCClient(t)
+
+	resp, err := c.Classify(context.Background(), &classifierv1.ClassifyRequest{
+		Input: &classifierv1.ClassifyRequest_Request{Request: curlMetadata()},
+	})
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if got := resp.GetResult().GetClassification(); got != classifier.ClassificationBot {
+		t.Errorf("Classify(curl) = %s, want %s", got, classifier.ClassificationBot)
+	}
+	if ua := resp.GetResult().GetFingerprint().GetHttp().GetUserAgent(); ua != "curl/8.0.1" {
+		t.Errorf("Classify() UserAgent = %q, want %q", ua, "curl/8.0.1")
+	}
+}
+
+func TestGRPC_ClassifyFingerprint(t *testing.T) {
+	c := newTestGRPCClient(t)
+
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "python-requests/2.31.0",
+			HeaderCount: 3,
+		},
+	}
+	resp, err := c.Classify(context.Background(), &classifierv1.ClassifyRequest{
+		Input: &classifierv1.ClassifyRequest_Fingerprint{Fingerprint: classifierv1.FromFingerprint(fp)},
+	})
+	if err != nil {
+		t.Fatalf("Classify() error = %v", err)
+	}
+	if got := resp.GetResult().GetClassification(); got != classifier.ClassificationBot {
+		t.Errorf("Classify(python) = %s, want %s", got, classifier.ClassificationBot)
+	}
+}
+
+func TestGRPC_ClassifyInvalid(t *testing.T) {
+	c := newTestGRPCClient(t)
+
+	_, err := c.Classify(context.Background(), &classifierv1.ClassifyRequest{})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Classify(empty) code = %v, want %v", status.Code(err), codes.InvalidArgument)
+	}
+}
+
+func TestGRPC_ClassifyStream(t *testing.T) {
+	c := newTestGRPCClient(t)
+
+	stream, err := c.ClassifyStream(context.Background())
+	if err != nil {
+		t.Fatalf("ClassifyStream() error = %v", err)
+	}
+
+	const n = 50
+	for i := range n {
+		req := &classifierv1.ClassifyStreamRequest{
+			Id:    string(rune('a'+i%26)) + string(rune('0'+i/26)),
+			Input: &classifierv1.ClassifyStreamRequest_Request{Request: curlMetadata()},
+		}
+		if err := stream.Send(req); err != nil {
+			t.Fatalf("Send() error = %v", err)
+		}
+	}
+	if err := stream.Send(&classifierv1.ClassifyStreamRequest{Id: "invalid"}); err != nil {
+		t.Fatalf("Send() error = %v", err)
+	}
+	if err := stream.CloseSend(); err != nil {
+		t.Fatalf("CloseSend() error = %v", err)
+	}
+
+	seen := map[string]*classifierv1.ClassifyStreamResponse{}
+	for {
+		resp, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Recv() error = %v", err)
+		}
+		seen[resp.GetId()] = resp
+	}
+
+	if len(seen) != n+1 {
+		t.Fatalf("received %d responses, want %d", len(seen), n+1)
+	}
+	if resp := seen["invalid"]; resp.GetError() == "" || resp.GetResult() != nil {
+		t.Errorf("invalid message response = %v, want error only", resp)
+	}
+	if got := seen["a0"].GetResult().GetClassification(); got != classifier.ClassificationBot {
+		t.Errorf("stream Classify(curl) = %s, want %s", got, classifier.ClassificationBot)
+	}
+}
@@ -0,0 +1,154 @@
+package unit
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/har"
+)
+
+const testHAR = `{
+  "log": {
+    "version": "1.2",
+    "creator": {"name": "WebInspector", "version": "537.36"},
+    "entries": [
+      {
+        "startedDateTime": "2026-02-12T12:40:35.000Z",
+        "_resourceType": "document",
+        "request": {
+          "method": "GET",
+          "url": "https://example.com/products?page=2",
+          "httpVersion": "http/2.0",
+          "headers": [
+            {"name": ":authority", "value": "example.com"},
+            {"name": ":method", "value": "GET"},
+            {"name": "user-agent", "value": "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0.0.0"},
+            {"name": "accept-language", "value": "en-US,en;q=0.9"},
+            {"name": "sec-fetch-mode", "value": "navigate"}
+          ]
+        },
+        "response": {"status": 200}
+      }
+    ]
+  }
+}`
+
+func TestHARParse(t *testing.T) {
+	doc, err := har.Parse(strings.NewReader(testHAR))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(doc.Log.Entries) != 1 {
+		t.Fatalf("Parse() entries = %d, want 1", len(doc.Log.Entries))
+	}
+	if doc.Log.Entries[0].ResourceType != "document" {
+		t.Errorf("ResourceType = %q, want %q", doc.Log.Entries[0].ResourceType, "document")
+	}
+}
+
+func TestHARParse_Invalid(t *testing.T) {
+	if _, err := har.Parse(strings.NewReader("not json")); err == nil {
+		t.Error("Parse() should fail on invalid JSON")
+	}
+}
+
+func TestHARRequest_HTTPRequest(t *testing.T) {
+	doc, err := har.Parse(strings.NewReader(testHAR))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	req, err := doc.Log.Entries[0].Request.HTTPRequest()
+	if err != nil {
+		t.Fatalf("HTTPRequest() error = %v", err)
+	}
+
+	if req.Proto != "HTTP/2.0" || req.ProtoMajor != 2 {
+		t.Errorf("Proto = %q (%d), want HTTP/2.0", req.Proto, req.ProtoMajor)
+	}
+	if req.Host != "example.com" {
+		t.Errorf("Host = %q, want %q", req.Host, "example.com")
+	}
+	if req.Header.Get(":method") != "" {
+		t.Error("Pseudo-headers should be dropped")
+	}
+	if len(req.Header) != 3 {
+		t.Errorf("Header count = %d, want 3", len(req.Header))
+	}
+
+	fp := fingerprint.NewCollector().Collect(req)
+	if !strings.HasPrefix(fp.HTTP.JA4HHash, "ge20nn03enus") {
+		t.Errorf("JA4H = %q, want prefix ge20nn03enus", fp.HTTP.JA4HHash)
+	}
+}
+
+func TestHARNormalizeHTTPVersion(t *testing.T) {
+	tests := []struct {
+		in    string
+		proto string
+	}{
+		{"http/2.0", "HTTP/2.0"},
+		{"h2", "HTTP/2.0"},
+		{"h3", "HTTP/3.0"},
+		{"HTTP/1.1", "HTTP/1.1"},
+		{"http/1.0", "HTTP/1.0"},
+		{"", "HTTP/1.1"},
+	}
+
+	for _, tt := range tests {
+		if proto, _, _ := har.NormalizeHTTPVersion(tt.in); proto != tt.proto {
+			t.Errorf("NormalizeHTTPVersion(%q) = %q, want %q", tt.in, proto, tt.proto)
+		}
+	}
+}
+
+func TestHARAnalyze(t *testing.T) {
+	doc := &har.HAR{Log: har.Log{Entries: []har.Entry{
+		{
+			ResourceType: "document",
+			Request: har.Request{Method: "GET", URL: "https://example.com/", HTTPVersion: "h2", Headers: []har.Header{
+				{Name: "user-agent", Value: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0.0.0"},
+				{Name: "accept", Value: "text/html"},
+				{Name: "accept-language", Value: "en-US"},
+				{Name: "sec-fetch-mode", Value: "navigate"},
+			}},
+			Response: har.Response{Status: 200},
+		},
+		{
+			ResourceType: "ping",
+			Request: har.Request{Method: "POST", URL: "https://example.com/beacon", HTTPVersion: "http/1.1", Headers: []har.Header{
+				{Name: "User-Agent", Value: "Mozilla/5.0 HeadlessChrome/120.0.0.0"},
+			}},
+			Response: har.Response{Status: 204},
+		},
+		{Request: har.Request{URL: "://bad"}},
+	}}}
+
+	results, summary := har.Analyze(doc, classifier.New())
+
+	if len(results) != 3 {
+		t.Fatalf("Analyze() returned %d results, want 3", len(results))
+	}
+	if results[0].Classification != "browser" {
+		t.Errorf("document entry = %+v, want browser", results[0])
+	}
+	if results[1].Classification != "bot" || !slices.Contains(results[1].BotRules, "bot-ua") {
+		t.Errorf("headless beacon = %+v, want bot with bot-ua", results[1])
+	}
+	if results[2].Error == "" {
+		t.Error("invalid URL should be reported as an error")
+	}
+
+	if summary.Entries != 3 || summary.Browser != 1 || summary.Bot != 1 || summary.Errors != 1 {
+		t.Errorf("summary = %+v", summary)
+	}
+	if len(summary.Types) == 0 || summary.Types[0].ResourceType != "ping" || summary.Types[0].Bot != 1 {
+		t.Errorf("Types = %+v, want ping first", summary.Types)
+	}
+	if len(summary.Rules) == 0 {
+		t.Error("Rules should list the rules behind the bot verdict")
+	}
+}
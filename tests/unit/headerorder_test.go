@@ -0,0 +1,176 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+// keepAliveRequests is a POST whose body looks like a request line,
+// followed by a GET on the same connection
+const keepAliveRequests = "POST /form HTTP/1.1\r\n" +
+	"host: example.com\r\n" +
+	"content-length: 25\r\n" +
+	"USER-AGENT: test\r\n" +
+	"\r\n" +
+	"GET /x HTTP/1.1\r\nA: b\r\n\r\n" +
+	"GET /page?q=1 HTTP/1.1\r\n" +
+	"Host: example.com\r\n" +
+	"User-Agent: test\r\n" +
+	"Accept-Language: en\r\n" +
+	"Accept: */*\r\n" +
+	"\r\n"
+
+// readRequests parses every request of raw, skipping bodies
+func readRequests(t *testing.T, raw string) []*http.Request {
+	t.Helper()
+	br := bufio.NewReader(strings.NewReader(raw))
+	var reqs []*http.Request
+	for {
+		req, err := http.ReadRequest(br)
+		if err != nil {
+			return reqs
+		}
+		_, _ = br.Discard(int(req.ContentLength))
+		reqs = append(reqs, req)
+	}
+}
+
+func TestHeaderSniffer_KeepAlive(t *testing.T) {
+	var s fingerprint.HeaderSniffer
+	// One byte at a time, as a slow client would send it
+	for i := range len(keepAliveRequests) {
+		_, _ = s.Write([]byte{keepAliveRequests[i]})
+	}
+
+	reqs := readRequests(t, keepAliveRequests)
+	if len(reqs) != 2 {
+		t.Fatalf("parsed %d requests, want 2", len(reqs))
+	}
+	want := [][]string{
+		{"host", "content-length", "USER-AGENT"},
+		{"Host", "User-Agent", "Accept-Language", "Accept"},
+	}
+	for i, req := range reqs {
+		if got := s.HeaderNames(req); !slices.Equal(got, want[i]) {
+			t.Errorf("HeaderNames(%s %s) = %v, want %v", req.Method, req.RequestURI, got, want[i])
+		}
+	}
+
+	// The body's request line was skipped with the body
+	fake := reqs[1].Clone(context.Background())
+	fake.RequestURI, fake.Header = "/x", http.Header{"A": {"b"}}
+	if got := s.HeaderNames(fake); got != nil {
+		t.Errorf("HeaderNames(body) = %v, want nil", got)
+	}
+
+	// A request with other headers than those seen is not matched
+	other := reqs[1].Clone(context.Background())
+	other.Header.Set("Cookie", "a=b")
+	if got := s.HeaderNames(other); got != nil {
+		t.Errorf("HeaderNames(other headers) = %v, want nil", got)
+	}
+}
+
+func TestHeaderSniffer_HTTP2(t *testing.T) {
+	var s fingerprint.HeaderSniffer
+	_, _ = s.Write([]byte("PRI * HTTP/2.0\r\n\r\nSM\r\n\r\nGET / HTTP/1.1\r\nHost: a\r\n\r\n"))
+	req, _ := http.NewRequest(http.MethodGet, "/", nil)
+	req.RequestURI = "/"
+	if got := s.HeaderNames(req); got != nil {
+		t.Errorf("HeaderNames() after HTTP/2 preface = %v, want nil", got)
+	}
+}
+
+func TestCollector_HeaderOrder(t *testing.T) {
+	raw := "GET / HTTP/1.1\r\nHost: example.com\r\nUser-Agent: test\r\nAccept: */*\r\nAccept-Language: en\r\n\r\n"
+	var s fingerprint.HeaderSniffer
+	_, _ = s.Write([]byte(raw))
+	req := readRequests(t, raw)[0]
+	collector := fingerprint.NewCollector()
+
+	// Without a capture the order is sorted and JA4H hashes sorted names
+	fp := collector.Collect(req)
+	if want := []string{"accept", "accept-language", "user-agent"}; !slices.Equal(fp.HTTP.HeaderOrder, want) {
+		t.Errorf("HeaderOrder = %v, want %v", fp.HTTP.HeaderOrder, want)
+	}
+	if fp.HTTP.RawHeaderNames != nil || fp.HTTP.JA4HHash != fingerprint.JA4H(req) {
+		t.Errorf("uncaptured fingerprint = %v / %s", fp.HTTP.RawHeaderNames, fp.HTTP.JA4HHash)
+	}
+
+	req = req.WithContext(context.WithValue(req.Context(), fingerprint.ContextKeyHeaderOrder, fingerprint.HeaderOrderSource(&s)))
+	fp = collector.Collect(req)
+	if want := []string{"Host", "User-Agent", "Accept", "Accept-Language"}; !slices.Equal(fp.HTTP.RawHeaderNames, want) {
+		t.Errorf("RawHeaderNames = %v, want %v", fp.HTTP.RawHeaderNames, want)
+	}
+	if want := []string{"host", "user-agent", "accept", "accept-language"}; !slices.Equal(fp.HTTP.HeaderOrder, want) {
+		t.Errorf("HeaderOrder = %v, want %v", fp.HTTP.HeaderOrder, want)
+	}
+	if want := "ge11nn03en00_8ddaef5d77af_000000000000_000000000000"; fp.HTTP.JA4HHash != want {
+		t.Errorf("JA4HHash = %s, want %s", fp.HTTP.JA4HHash, want)
+	}
+}
+
+func TestServer_HeaderOrder(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	srv, err := server.New(
+		server.WithListener(ln),
+		server.WithLogger(logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"}),
+		server.WithDebug(true),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+		if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Serve() error = %v", err)
+		}
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_, _ = conn.Write([]byte("GET /v1/debug HTTP/1.1\r\n" +
+		"host: example.com\r\n" +
+		"user-agent: Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36\r\n" +
+		"accept: text/html\r\n" +
+		"Connection: close\r\n\r\n"))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("ReadResponse: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result fingerprint.ClassificationResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := []string{"host", "user-agent", "accept", "Connection"}
+	if got := result.Fingerprint.HTTP.RawHeaderNames; !slices.Equal(got, want) {
+		t.Errorf("RawHeaderNames = %v, want %v", got, want)
+	}
+	if !result.Signals.NonCanonicalHeaderCase {
+		t.Error("NonCanonicalHeaderCase = false for a lowercase Chrome request")
+	}
+}
@@ -0,0 +1,381 @@
+package unit
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/events"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/honeypot"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/offender"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+func TestHoneypot_New(t *testing.T) {
+	traps, err := honeypot.New([]string{" /old-admin/ ", "/feed-all", "", "/feed-all"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if got := traps.Paths(); len(got) != 2 || got[0] != "/old-admin/" || got[1] != "/feed-all" {
+		t.Errorf("Paths() = %q", got)
+	}
+	for _, bad := range [][]string{nil, {""}, {"/"}, {"old-admin"}, {"/a b"}, {"/{id}"}} {
+		if _, err := honeypot.New(bad); err == nil {
+			t.Errorf("New(%q) should fail", bad)
+		}
+	}
+}
+
+func TestHoneypot_Match(t *testing.T) {
+	traps, _ := honeypot.New([]string{"/old-admin/", "/feed-all"})
+	for path, want := range map[string]string{
+		"/old-admin/":           "/old-admin/",
+		"/old-admin/users.php":  "/old-admin/",
+		"/feed-all":             "/feed-all",
+		"/feed-all/more":        "",
+		"/old-admin":            "",
+		"/articles/old-admin/x": "",
+	} {
+		got, ok := traps.Match(path)
+		if got != want || ok != (want != "") {
+			t.Errorf("Match(%q) = %q, %v, want %q", path, got, ok, want)
+		}
+	}
+}
+
+func TestHoneypot_Robots(t *testing.T) {
+	traps, _ := honeypot.New([]string{"/old-admin/", "/feed-all"})
+
+	robots := "# Site policy\nUser-agent: Googlebot\nUser-agent: Bingbot\nAllow: /\n\nUser-agent: *\n# Slow down\nCrawl-delay: 5"
+	want := "# Site policy\nUser-agent: Googlebot\nUser-agent: Bingbot\nDisallow: /old-admin/\nDisallow: /feed-all\nAllow: /\n\n" +
+		"User-agent: *\n# Slow down\nDisallow: /old-admin/\nDisallow: /feed-all\nCrawl-delay: 5\n"
+	if got := string(traps.Robots([]byte(robots))); got != want {
+		t.Errorf("Robots() =\n%s\nwant\n%s", got, want)
+	}
+
+	// A group left open at the end still gets the traps
+	if got := string(traps.Robots([]byte("User-agent: *\n"))); got != "User-agent: *\nDisallow: /old-admin/\nDisallow: /feed-all\n" {
+		t.Errorf("Robots(open group) = %q", got)
+	}
+
+	// Without groups, or without a robots.txt, one is added
+	if got := string(traps.Robots(nil)); got != "User-agent: *\nDisallow: /old-admin/\nDisallow: /feed-all\n" {
+		t.Errorf("Robots(nil) = %q", got)
+	}
+	if got := string(traps.Robots([]byte("Sitemap: https://example.com/sitemap.xml"))); !strings.HasPrefix(got, "Sitemap: https://example.com/sitemap.xml\nUser-agent: *\nDisallow: /old-admin/\n") {
+		t.Errorf("Robots(sitemap only) = %q", got)
+	}
+}
+
+func TestHoneypot_Links(t *testing.T) {
+	traps, _ := honeypot.New([]string{"/feed-all"})
+	links := traps.Links()
+	for _, want := range []string{`href="/feed-all"`, `rel="nofollow"`, `style="display:none"`, `aria-hidden="true"`} {
+		if !strings.Contains(links, want) {
+			t.Errorf("Links() = %q, lacks %s", links, want)
+		}
+	}
+}
+
+func TestServerNew_HoneypotRouteConflicts(t *testing.T) {
+	dir := t.TempDir()
+	newServer := func(path string) (*server.Server, error) {
+		traps, err := honeypot.New([]string{path})
+		if err != nil {
+			t.Fatalf("honeypot.New(%q) error = %v", path, err)
+		}
+		return server.New(server.WithLogger(logger.Config{LogDir: dir, FileName: "requests.jsonl"}), server.WithHoneypot(traps))
+	}
+
+	for _, path := range []string{
+		"/health", "/robots.txt", "/metrics", "/classify", "/classify/", "/challenge", "/stream",
+		"/v1", "/v1/health", "/v1/old-admin/", "/admin/capture", "/admin/", "/explain/x",
+	} {
+		if _, err := newServer(path); err == nil {
+			t.Errorf("New() with trap %s succeeded", path)
+		}
+	}
+	for _, path := range []string{"/old-admin/", "/feed-all", "/healthz", "/adm"} {
+		srv, err := newServer(path)
+		if err != nil {
+			t.Errorf("New() with trap %s error = %v", path, err)
+			continue
+		}
+		_ = srv.Close()
+	}
+}
+
+func TestOffenderStore(t *testing.T) {
+	s := offender.New(offender.Config{TTL: time.Hour, MaxEntries: 100})
+	now := time.Now()
+
+	s.MarkTrapped("198.51.100.7", "t13d1812h1_85036bcba153_b26ce05bbdd6", "/feed-all", now)
+	s.MarkTrapped("", "", "/feed-all", now)
+	if s.Len() != 2 {
+		t.Errorf("Len() = %d, want 2 (empty keys are not recorded)", s.Len())
+	}
+
+	if o := s.Lookup("198.51.100.7", "", now.Add(time.Minute)); !o.Trapped || o.TrapPath != "/feed-all" {
+		t.Errorf("Lookup(address) = %+v", o)
+	}
+	if o := s.Lookup("203.0.113.9", "t13d1812h1_85036bcba153_b26ce05bbdd6", now.Add(time.Minute)); !o.Trapped {
+		t.Error("Lookup(JA4 from another address) should be trapped")
+	}
+	if o := s.Lookup("203.0.113.9", "", now); o.Trapped {
+		t.Error("Lookup(other client) should not be trapped")
+	}
+	if o := s.Lookup("198.51.100.7", "", now.Add(2*time.Hour)); o.Trapped {
+		t.Error("offense should expire after the TTL")
+	}
+
+	s.SetCapacity(1)
+	if s.Len() > 1 {
+		t.Errorf("Len() after SetCapacity(1) = %d", s.Len())
+	}
+}
+
+func TestExtractSignals_HoneypotHit(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:      "HTTP/2.0",
+			UserAgent:    chromeUA,
+			Accept:       "text/html,application/xhtml+xml",
+			AcceptLang:   "en-US,en;q=0.9",
+			AcceptEnc:    "gzip, deflate, br",
+			SecFetchSite: "none",
+			SecFetchMode: "navigate",
+			SecFetchDest: "document",
+			HeaderCount:  14,
+		},
+		Offender: fingerprint.OffenderFingerprint{Trapped: true, TrapPath: "/feed-all"},
+	}
+	result := classifier.New(classifier.DefaultConfig()).Classify(fp)
+	if !result.Signals.HoneypotHit || result.Classification != classifier.ClassificationBot {
+		t.Errorf("trapped browser: HoneypotHit = %v, classification = %s", result.Signals.HoneypotHit, result.Classification)
+	}
+	if !strings.Contains(result.Reason, "honeypot") {
+		t.Errorf("Reason = %q", result.Reason)
+	}
+}
+
+func TestHandler_Honeypot(t *testing.T) {
+	traps, _ := honeypot.New([]string{"/feed-all"})
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetHoneypot(traps, offender.New(offender.DefaultConfig()))
+	router := server.NewRouter(h, nil, false)
+
+	get := func(path, remoteAddr string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", chromeUA)
+		r.Header.Set("Accept-Language", "en-US,en;q=0.9")
+		r.Header.Set("Accept", "application/json")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+	classification := func(w *httptest.ResponseRecorder) string {
+		var resp server.Response
+		if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return resp.Classification
+	}
+
+	w := get("/robots.txt", "198.51.100.7:4000")
+	body, _ := io.ReadAll(w.Body)
+	if w.Code != http.StatusOK || !strings.Contains(string(body), "Disallow: /feed-all") {
+		t.Errorf("robots.txt: status = %d, body = %q", w.Code, body)
+	}
+
+	before := classification(get("/", "198.51.100.7:4000"))
+	if w := get("/feed-all", "198.51.100.7:4001"); w.Code != http.StatusNotFound {
+		t.Errorf("trap: status = %d, want 404", w.Code)
+	}
+	if got := classification(get("/", "198.51.100.7:4002")); got != classifier.ClassificationBot {
+		t.Errorf("after the trap: classification = %s (before: %s), want bot", got, before)
+	}
+	if got := classification(get("/", "203.0.113.9:4000")); got != before {
+		t.Errorf("other client: classification = %s, want %s", got, before)
+	}
+
+	// A load balancer's address is not remembered
+	get("/feed-all", "10.0.0.2:4000")
+	if got := classification(get("/", "10.0.0.2:4001")); got != classification(get("/", "10.0.0.3:4000")) {
+		t.Errorf("local address remembered as offender: %s", got)
+	}
+}
+
+func TestOffenderStore_Reputation(t *testing.T) {
+	s := offender.New(offender.Config{TTL: 24 * time.Hour, MaxEntries: 100, HalfLife: time.Hour, Threshold: 3})
+	now := time.Now()
+	const ja4 = "t13d1812h1_85036bcba153_b26ce05bbdd6"
+
+	if s.RecordBot("198.51.100.7", ja4, now) || s.RecordBot("198.51.100.7", ja4, now) {
+		t.Error("RecordBot() crossed the threshold before the third verdict")
+	}
+	if o := s.Lookup("198.51.100.7", "", now); o.Reputation != 2 || o.RepeatOffender {
+		t.Errorf("Lookup() after two verdicts = %+v", o)
+	}
+	if !s.RecordBot("198.51.100.7", ja4, now) {
+		t.Error("RecordBot() should report crossing the threshold")
+	}
+	if s.RecordBot("198.51.100.7", ja4, now) {
+		t.Error("RecordBot() above the threshold should not report crossing it again")
+	}
+	if o := s.Lookup("203.0.113.9", ja4, now); !o.RepeatOffender || o.Reputation != 4 || o.Trapped {
+		t.Errorf("Lookup(JA4 from another address) = %+v", o)
+	}
+
+	// One half-life halves the reputation, below the threshold
+	if o := s.Lookup("198.51.100.7", "", now.Add(time.Hour)); o.Reputation != 2 || o.RepeatOffender {
+		t.Errorf("Lookup() after one half-life = %+v", o)
+	}
+	// A verdict adds to the decayed reputation
+	if !s.RecordBot("198.51.100.7", "", now.Add(time.Hour)) {
+		t.Error("RecordBot() after decay should cross the threshold again")
+	}
+	if o := s.Lookup("198.51.100.7", "", now.Add(time.Hour)); o.Reputation != 3 || !o.RepeatOffender {
+		t.Errorf("Lookup() after a new verdict = %+v", o)
+	}
+
+	// Traps and verdicts share entries without overwriting each other
+	s.MarkTrapped("198.51.100.7", "", "/feed-all", now.Add(time.Hour))
+	if o := s.Lookup("198.51.100.7", "", now.Add(time.Hour)); !o.Trapped || !o.RepeatOffender {
+		t.Errorf("Lookup() after a trap = %+v", o)
+	}
+	if o := s.Lookup("198.51.100.7", "", now.Add(26*time.Hour)); o.Trapped || o.Reputation != 0 {
+		t.Errorf("Lookup() after the TTL = %+v", o)
+	}
+}
+
+func TestExtractSignals_RepeatOffender(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   chromeUA,
+			Accept:      "*/*",
+			HeaderCount: 3,
+		},
+	}
+	c := classifier.New(classifier.DefaultConfig())
+	first := c.Classify(fp)
+
+	fp.Offender = fingerprint.OffenderFingerprint{Reputation: 3.5, RepeatOffender: true}
+	again := c.Classify(fp)
+	if !again.Signals.RepeatOffender || !strings.Contains(again.Signals.ScoreBreakdown.String(), "repeat-offender(+4)") {
+		t.Errorf("repeat offender: signal = %v, breakdown = %s", again.Signals.RepeatOffender, again.Signals.ScoreBreakdown)
+	}
+	if again.Signals.BotScore != first.Signals.BotScore+4 || again.Confidence <= first.Confidence {
+		t.Errorf("bot score %d -> %d, confidence %.2f -> %.2f", first.Signals.BotScore, again.Signals.BotScore, first.Confidence, again.Confidence)
+	}
+	if !strings.Contains(again.Reason, "repeat offender") {
+		t.Errorf("Reason = %q", again.Reason)
+	}
+}
+
+func TestHandler_RepeatOffenders(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetRepeatOffenders(offender.New(offender.DefaultConfig()))
+	b := events.NewBus()
+	h.SetEvents(b)
+	ch, _ := b.Subscribe(100)
+
+	classify := func(remoteAddr string) {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", "curl/8.4.0")
+		h.HandleClassify(httptest.NewRecorder(), r)
+	}
+	debug := func(remoteAddr string) fingerprint.ClassificationResult {
+		r := httptest.NewRequest(http.MethodGet, "/debug", nil)
+		r.RemoteAddr = remoteAddr
+		r.Header.Set("User-Agent", "curl/8.4.0")
+		w := httptest.NewRecorder()
+		h.HandleDebug(w, r)
+		var result fingerprint.ClassificationResult
+		if err := json.NewDecoder(w.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return result
+	}
+
+	for range 3 {
+		if res := debug("198.51.100.7:4000"); res.Signals.RepeatOffender {
+			t.Fatal("repeat offender before three bot verdicts")
+		}
+		classify("198.51.100.7:4000")
+	}
+	res := debug("198.51.100.7:4000")
+	if !res.Signals.RepeatOffender || res.Fingerprint.Offender.Reputation < 2.99 {
+		t.Errorf("after three bot verdicts: RepeatOffender = %v, reputation = %v", res.Signals.RepeatOffender, res.Fingerprint.Offender.Reputation)
+	}
+	if res := debug("203.0.113.9:4000"); res.Signals.RepeatOffender {
+		t.Error("other client is a repeat offender")
+	}
+	// The debug endpoint records nothing, so the count is unchanged
+	if res := debug("198.51.100.7:4000"); res.Fingerprint.Offender.Reputation > 3.01 {
+		t.Errorf("debug requests recorded verdicts: reputation = %v", res.Fingerprint.Offender.Reputation)
+	}
+
+	if err := b.Close(); err != nil {
+		t.Fatal(err)
+	}
+	crossed := 0
+	for e := range ch {
+		if e.Type == events.ReputationThresholdCrossed {
+			crossed++
+			if e.ClientAddr != "198.51.100.7:4000" || e.Classification != classifier.ClassificationBot {
+				t.Errorf("threshold event = %+v", e)
+			}
+		}
+	}
+	if crossed != 1 {
+		t.Errorf("got %d reputation_threshold_crossed events, want 1", crossed)
+	}
+}
+
+func TestHandler_RepeatOffendersNotSelfSustaining(t *testing.T) {
+	store := offender.New(offender.DefaultConfig())
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetRepeatOffenders(store)
+
+	browser := func(path string) *http.Request {
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.RemoteAddr = "198.51.100.8:4000"
+		r.Header.Set("User-Agent", chromeUA)
+		r.Header.Set("Accept", "text/html")
+		r.Header.Set("Accept-Language", "en-US")
+		r.Header.Set("Accept-Encoding", "gzip")
+		return r
+	}
+	w := httptest.NewRecorder()
+	h.HandleDebug(w, browser("/debug"))
+	var own fingerprint.ClassificationResult
+	if err := json.NewDecoder(w.Body).Decode(&own); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if own.Classification != classifier.ClassificationBrowser {
+		t.Skipf("request classified %s on its own", own.Classification)
+	}
+
+	now := time.Now()
+	for range 3 {
+		store.RecordBot("198.51.100.8", "", now)
+	}
+	// Bot only through its reputation: the verdict is not added to it
+	h.HandleClassify(httptest.NewRecorder(), browser("/"))
+	if o := store.Lookup("198.51.100.8", "", time.Now()); o.Reputation > 3.01 {
+		t.Errorf("verdict tipped by reputation recorded: reputation = %v", o.Reputation)
+	}
+}
@@ -0,0 +1,87 @@
+package unit
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+func TestExtractSignals_ImpossibleCombinations(t *testing.T) {
+	const iosChromeUA = "Mozilla/5.0 (iPhone; CPU iPhone OS 17_5 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/126.0.6478.54 Mobile/15E148 Safari/604.1"
+	const webViewUA = "Mozilla/5.0 (Linux; Android 13; Pixel 7; wv) AppleWebKit/537.36 (KHTML, like Gecko) Version/4.0 Chrome/110.0.5481.65 Mobile Safari/537.36"
+	tests := []struct {
+		name string
+		http fingerprint.HTTPFingerprint
+		tls  bool
+		want []string
+	}{
+		{"chrome h2", fingerprint.HTTPFingerprint{Version: "HTTP/2.0", UserAgent: chromeUA, SecChUA: `"Chromium";v="131"`, SecFetchMode: "navigate"}, true, nil},
+		{"curl h1.0", fingerprint.HTTPFingerprint{Version: "HTTP/1.0", UserAgent: "curl/8.4.0", AcceptEnc: "deflate, gzip"}, false, nil},
+		{"chrome on ios", fingerprint.HTTPFingerprint{Version: "HTTP/2.0", UserAgent: iosChromeUA}, true, nil},
+		{"android webview", fingerprint.HTTPFingerprint{Version: "HTTP/2.0", UserAgent: webViewUA}, true, nil},
+		{"old chrome", fingerprint.HTTPFingerprint{Version: "HTTP/2.0", UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/85.0.4183.121 Safari/537.36"}, true, nil},
+		{"br over tls h1.0", fingerprint.HTTPFingerprint{Version: "HTTP/1.0", AcceptEnc: "gzip, br"}, true, nil},
+		{"navigation upgrade", fingerprint.HTTPFingerprint{Version: "HTTP/2.0", UserAgent: chromeUA, SecChUA: `"Chromium";v="131"`, SecFetchMode: "navigate", Headers: map[string]string{"upgrade-insecure-requests": "1"}}, true, nil},
+
+		{"sec-fetch on h1.0", fingerprint.HTTPFingerprint{Version: "HTTP/1.0", UserAgent: "Mozilla/5.0", SecFetchSite: "none"}, false, []string{"sec-fetch-http1.0"}},
+		{"sec-fetch without ua", fingerprint.HTTPFingerprint{Version: "HTTP/1.1", SecFetchMode: "cors"}, true, []string{"sec-fetch-no-user-agent"}},
+		{"chrome h2 without hints", fingerprint.HTTPFingerprint{Version: "HTTP/2.0", UserAgent: chromeUA}, true, []string{"chrome-no-client-hints"}},
+		{"br plaintext h1.0", fingerprint.HTTPFingerprint{Version: "HTTP/1.0", AcceptEnc: "gzip, deflate, br;q=0.9"}, false, []string{"br-plaintext-http1.0"}},
+		{"upgrade on fetch", fingerprint.HTTPFingerprint{Version: "HTTP/1.1", UserAgent: chromeUA, SecFetchMode: "cors", Headers: map[string]string{"upgrade-insecure-requests": "1"}}, true, []string{"upgrade-insecure-subresource"}},
+		{"several", fingerprint.HTTPFingerprint{Version: "HTTP/1.0", SecFetchDest: "document", AcceptEnc: "br"}, false, []string{"sec-fetch-http1.0", "sec-fetch-no-user-agent", "br-plaintext-http1.0"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := fingerprint.Fingerprint{HTTP: tt.http, TLS: fingerprint.TLSFingerprint{Available: tt.tls}}
+			s := fingerprint.ExtractSignals(fp)
+			if !slices.Equal(s.SpoofingCombos, tt.want) || s.HeaderSpoofing != (tt.want != nil) {
+				t.Errorf("HeaderSpoofing = %v %v, want %v", s.HeaderSpoofing, s.SpoofingCombos, tt.want)
+			}
+			// Scored once, however many combinations match
+			if got := strings.Count(s.ScoreBreakdown.String(), "spoofing(+3)"); got != min(len(tt.want), 1) {
+				t.Errorf("spoofing scored %d times: %s", got, s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestImpossibleCombinations_Explained(t *testing.T) {
+	for _, c := range fingerprint.ImpossibleCombinations() {
+		if c.Explanation == "" {
+			t.Errorf("%s has no explanation", c.Name)
+		}
+		if got, ok := fingerprint.LookupImpossibleCombination(c.Name); !ok || got.Name != c.Name {
+			t.Errorf("LookupImpossibleCombination(%q) = %v, %v", c.Name, got.Name, ok)
+		}
+	}
+
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{Version: "HTTP/1.0", UserAgent: chromeUA, SecFetchMode: "navigate"}}
+	res := classifier.New().Classify(fp)
+	if !strings.Contains(res.Reason, "impossible headers: Sec-Fetch headers on HTTP/1.0") {
+		t.Errorf("reason %q lacks the explanation", res.Reason)
+	}
+}
+
+func TestExtractSignals_ImpossibleCombinations_GoldenBrowsers(t *testing.T) {
+	entries, err := fingerprint.GoldenFamily(fingerprint.GoldenBrowser)
+	if err != nil {
+		t.Fatalf("GoldenFamily() error = %v", err)
+	}
+	for _, e := range entries {
+		if s := fingerprint.ExtractSignals(e.Fingerprint); s.HeaderSpoofing {
+			t.Errorf("%s: impossible combinations %v", e.ID, s.SpoofingCombos)
+		}
+	}
+}
+
+func TestVerdictKey_ImpossibleCombinations(t *testing.T) {
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{JA4HHash: "ge11nn05enus_aaaaaaaaaaaa_000000000000_000000000000", Version: "HTTP/1.0"}}
+	plain, _ := fingerprint.VerdictKey(fp)
+	fp.HTTP.AcceptEnc = "gzip, br"
+	if spoofed, _ := fingerprint.VerdictKey(fp); spoofed == plain {
+		t.Error("VerdictKey ignores an impossible combination")
+	}
+}
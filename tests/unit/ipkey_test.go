@@ -0,0 +1,112 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/ipkey"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/internal/session"
+)
+
+func TestKeyer_Key(t *testing.T) {
+	k, err := ipkey.New(ipkey.Config{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		addr string
+		want string
+	}{
+		{"203.0.113.7:443", "203.0.113.7"},
+		{"203.0.113.7", "203.0.113.7"},
+		{"[2001:db8:1:2:aaaa::1]:443", "2001:db8:1:2::/64"},
+		{"[2001:DB8:1:2:bbbb:0:0:2]:80", "2001:db8:1:2::/64"},
+		{"2001:0db8:0001:0002::3", "2001:db8:1:2::/64"},
+		{"[fe80::1%eth0]:80", "fe80::/64"},
+		{"[::ffff:203.0.113.7]:443", "203.0.113.7"},
+		{"pipe", "pipe"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := k.Key(tt.addr); got != tt.want {
+			t.Errorf("Key(%q) = %q, want %q", tt.addr, got, tt.want)
+		}
+	}
+}
+
+func TestKeyer_CustomPrefixes(t *testing.T) {
+	k, err := ipkey.New(ipkey.Config{IPv4Prefix: 24, IPv6Prefix: 128})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := k.Key("203.0.113.7:443"); got != "203.0.113.0/24" {
+		t.Errorf("IPv4 /24 key = %q", got)
+	}
+	if got := k.Key("[2001:db8::1]:443"); got != "2001:db8::1" {
+		t.Errorf("IPv6 /128 key = %q", got)
+	}
+
+	for _, cfg := range []ipkey.Config{{IPv4Prefix: 33}, {IPv6Prefix: 129}, {IPv4Prefix: -1}} {
+		if _, err := ipkey.New(cfg); err == nil {
+			t.Errorf("New(%+v) should fail", cfg)
+		}
+	}
+	if _, err := server.New(server.WithIPKeys(ipkey.Config{IPv6Prefix: 200})); err == nil {
+		t.Error("server.New should reject an invalid IPv6 prefix")
+	}
+}
+
+func TestHandler_IPKeyer(t *testing.T) {
+	dir := t.TempDir()
+	l, err := logger.New(logger.Config{LogDir: dir, FileName: "requests.jsonl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = l.Close() }()
+
+	k, _ := ipkey.New(ipkey.DefaultConfig())
+	h := server.NewHandler(fingerprint.NewCollector(), classifier.New(classifier.DefaultConfig()), l)
+	h.SetQuiet(true)
+	h.SetIPKeyer(k)
+	h.SetSessionTracker(session.New(session.DefaultConfig()))
+
+	// A client rotating through its /64 keeps one session; the full
+	// addresses are still logged
+	for _, addr := range []string{"[2001:db8:1:2::7]:1000", "[2001:db8:1:2:ffff::9]:2000", "[2001:db8:1:3::7]:3000"} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.RemoteAddr = addr
+		h.HandleClassify(httptest.NewRecorder(), r)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "requests.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("log has %d entries, want 3", len(lines))
+	}
+	want := []int{1, 2, 1}
+	for i, line := range lines {
+		var entry logger.LogEntry
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			t.Fatal(err)
+		}
+		if entry.Fingerprint.Session.RequestCount != want[i] {
+			t.Errorf("request %d: session request count = %d, want %d", i, entry.Fingerprint.Session.RequestCount, want[i])
+		}
+		if i == 1 && entry.RemoteAddr != "[2001:db8:1:2:ffff::9]:2000" {
+			t.Errorf("logged remote_addr = %q, want the full address", entry.RemoteAddr)
+		}
+	}
+}
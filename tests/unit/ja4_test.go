@@ -0,0 +1,79 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+
+	"github.com/psanford/tlsfingerprint"
+)
+
+// specClientHello is the ClientHello of the JA4 specification's example,
+// with GREASE values and ciphers and extensions in offered order
+func specClientHello() *tlsfingerprint.Fingerprint {
+	return &tlsfingerprint.Fingerprint{
+		Version:      0x0304,
+		RawVersion:   0x0303,
+		CipherSuites: []uint16{0x1a1a, 0x1301, 0x1302, 0x1303, 0xc02b, 0xc02f, 0xc02c, 0xc030, 0xcca9, 0xcca8, 0xc013, 0xc014, 0x009c, 0x009d, 0x002f, 0x0035},
+		Extensions: []uint16{0x2a2a, 0x0000, 0x0017, 0xff01, 0x000a, 0x000b, 0x0023, 0x0010, 0x0005, 0x000d, 0x0012,
+			0x0033, 0x002d, 0x002b, 0x001b, 0x4469, 0x0015, 0x3a3a},
+		SignatureAlgorithms: []uint16{0x0403, 0x0804, 0x0401, 0x0503, 0x0805, 0x0501, 0x0806, 0x0601},
+		ALPNProtocols:       []string{"h2", "http/1.1"},
+		HasSNI:              true,
+	}
+}
+
+func TestJA4_SpecExample(t *testing.T) {
+	ch := specClientHello()
+	if got, want := fingerprint.JA4(ch), "t13d1516h2_8daaf6152771_e5627efa2ab1"; got != want {
+		t.Errorf("JA4() = %q, want %q", got, want)
+	}
+
+	want := "t13d1516h2_002f,0035,009c,009d,1301,1302,1303,c013,c014,c02b,c02c,c02f,c030,cca8,cca9_" +
+		"0005,000a,000b,000d,0012,0015,0017,001b,0023,002b,002d,0033,4469,ff01_0403,0804,0401,0503,0805,0501,0806,0601"
+	if got := fingerprint.JA4Raw(ch); got != want {
+		t.Errorf("JA4Raw() = %q, want %q", got, want)
+	}
+
+	fp := fingerprint.ClientHelloFingerprint(ch)
+	if fp.JA4Hash != fingerprint.JA4(ch) || fp.JA4Raw != want {
+		t.Errorf("ClientHelloFingerprint() JA4 = %q / %q", fp.JA4Hash, fp.JA4Raw)
+	}
+}
+
+func TestJA4_Prefix(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*tlsfingerprint.Fingerprint)
+		want   string
+	}{
+		{"no SNI", func(ch *tlsfingerprint.Fingerprint) { ch.HasSNI = false }, "t13i1516h2"},
+		{"TLS 1.2", func(ch *tlsfingerprint.Fingerprint) { ch.Version = 0x0303 }, "t12d1516h2"},
+		{"no ALPN", func(ch *tlsfingerprint.Fingerprint) { ch.ALPNProtocols = nil }, "t13d151600"},
+		{"http/1.1 first", func(ch *tlsfingerprint.Fingerprint) { ch.ALPNProtocols = []string{"http/1.1"} }, "t13d1516h1"},
+		{"one character", func(ch *tlsfingerprint.Fingerprint) { ch.ALPNProtocols = []string{"x"} }, "t13d1516xx"},
+		// Non-alphanumeric ALPN is coded by the hex digits of its bytes
+		{"non-alphanumeric ALPN", func(ch *tlsfingerprint.Fingerprint) { ch.ALPNProtocols = []string{"\xab\x01"} }, "t13d1516a1"},
+		{"unknown version", func(ch *tlsfingerprint.Fingerprint) { ch.Version = 0x0505 }, "t00d1516h2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ch := specClientHello()
+			tt.modify(ch)
+			if got := fingerprint.JA4(ch); !strings.HasPrefix(got, tt.want+"_") {
+				t.Errorf("JA4() = %q, want prefix %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJA4_Empty(t *testing.T) {
+	ch := &tlsfingerprint.Fingerprint{Version: 0x0303}
+	if got, want := fingerprint.JA4(ch), "t12i000000_000000000000_000000000000"; got != want {
+		t.Errorf("JA4() = %q, want %q", got, want)
+	}
+	if got, want := fingerprint.JA4Raw(ch), "t12i000000__"; got != want {
+		t.Errorf("JA4Raw() = %q, want %q", got, want)
+	}
+}
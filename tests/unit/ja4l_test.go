@@ -0,0 +1,51 @@
+package unit
+
+import (
+	"context"
+	"crypto/tls"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// fixedLatency is a LatencySource reporting a constant
+type fixedLatency time.Duration
+
+func (l fixedLatency) HandshakeLatency() time.Duration { return time.Duration(l) }
+
+func TestJA4L(t *testing.T) {
+	if got, want := fingerprint.JA4L(11324*time.Microsecond, 117), "11324_117"; got != want {
+		t.Errorf("JA4L() = %q, want %q", got, want)
+	}
+	if got, want := fingerprint.JA4L(850*time.Microsecond, 0), "850_00"; got != want {
+		t.Errorf("JA4L(no SYN) = %q, want %q", got, want)
+	}
+}
+
+func TestCollect_Latency(t *testing.T) {
+	collect := func(remote string, latency time.Duration, tcp *fingerprint.TCPFingerprint) fingerprint.Fingerprint {
+		req := httptest.NewRequest("GET", "https://example.com/", nil)
+		req.RemoteAddr = remote
+		req.TLS = &tls.ConnectionState{Version: tls.VersionTLS13}
+		ctx := context.WithValue(req.Context(), fingerprint.ContextKeyLatency, fingerprint.LatencySource(fixedLatency(latency)))
+		if tcp != nil {
+			ctx = context.WithValue(ctx, fingerprint.ContextKeyTCP, *tcp)
+		}
+		return fingerprint.NewCollector().Collect(req.WithContext(ctx))
+	}
+
+	fp := collect("203.0.113.7:51000", 2500*time.Microsecond, &fingerprint.TCPFingerprint{Available: true, TTL: 52})
+	if fp.TLS.LatencyMicros != 2500 || fp.TLS.JA4L != "2500_52" || fp.Network.Local {
+		t.Errorf("public client: latency = %d, JA4L = %q, local = %v", fp.TLS.LatencyMicros, fp.TLS.JA4L, fp.Network.Local)
+	}
+	if fp := collect("10.1.2.3:51000", 0, nil); fp.TLS.JA4L != "" || !fp.Network.Local {
+		t.Errorf("unmeasured client: JA4L = %q, local = %v", fp.TLS.JA4L, fp.Network.Local)
+	}
+	for _, remote := range []string{"127.0.0.1:1", "[::1]:1", "192.168.1.5:1", "[fd00::1]:1", "[fe80::1]:1"} {
+		if !collect(remote, 0, nil).Network.Local {
+			t.Errorf("%s: Local = false", remote)
+		}
+	}
+}
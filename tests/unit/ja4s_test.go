@@ -0,0 +1,97 @@
+package unit
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// serverHelloRecord builds a handshake record holding a ServerHello with
+// the given random, cipher suite and raw extensions
+func serverHelloRecord(random []byte, cipher uint16, exts ...[]byte) []byte {
+	body := []byte{0x03, 0x03}
+	body = append(body, random...)
+	body = append(body, 0) // Empty session ID
+	body = append(body, byte(cipher>>8), byte(cipher), 0)
+	var extBytes []byte
+	for _, e := range exts {
+		extBytes = append(extBytes, e...)
+	}
+	body = append(body, byte(len(extBytes)>>8), byte(len(extBytes)))
+	body = append(body, extBytes...)
+
+	msg := append([]byte{0x02, 0, byte(len(body) >> 8), byte(len(body))}, body...)
+	return append([]byte{0x16, 0x03, 0x03, byte(len(msg) >> 8), byte(len(msg))}, msg...)
+}
+
+// extension encodes a ServerHello extension
+func extension(typ uint16, data ...byte) []byte {
+	return append([]byte{byte(typ >> 8), byte(typ), byte(len(data) >> 8), byte(len(data))}, data...)
+}
+
+func TestJA4S_SpecExample(t *testing.T) {
+	record := serverHelloRecord(make([]byte, 32), 0x1301,
+		extension(0x0033, make([]byte, 36)...), // key_share
+		extension(0x002b, 0x03, 0x04),          // supported_versions: TLS 1.3
+	)
+	sh, err := fingerprint.ParseServerHello(record)
+	if err != nil {
+		t.Fatalf("ParseServerHello() error = %v", err)
+	}
+	if sh.Version != 0x0304 || sh.LegacyVersion != 0x0303 || sh.CipherSuite != 0x1301 {
+		t.Errorf("ServerHello = %+v", sh)
+	}
+	if got, want := fingerprint.JA4S(sh), "t130200_1301_234ea6891581"; got != want {
+		t.Errorf("JA4S() = %q, want %q", got, want)
+	}
+	if got, want := fingerprint.JA3S(sh), "eb1d94daa7e0344597e756a1fb6e7054"; got != want {
+		t.Errorf("JA3S() = %q, want %q", got, want)
+	}
+}
+
+func TestJA4S_TLS12WithALPN(t *testing.T) {
+	record := serverHelloRecord(make([]byte, 32), 0xc02f,
+		extension(0xff01, 0),                 // renegotiation_info
+		extension(0x0000),                    // server_name
+		extension(0x000b, 1, 0),              // ec_point_formats
+		extension(0x0023),                    // session_ticket
+		extension(0x0010, 0, 3, 2, 'h', '2'), // ALPN
+		extension(0x0017),                    // extended_master_secret
+	)
+	sh, err := fingerprint.ParseServerHello(record)
+	if err != nil {
+		t.Fatalf("ParseServerHello() error = %v", err)
+	}
+	if sh.ALPN != "h2" {
+		t.Errorf("ALPN = %q, want h2", sh.ALPN)
+	}
+	if got, want := fingerprint.JA4S(sh), "t1206h2_c02f_17136cd5846b"; got != want {
+		t.Errorf("JA4S() = %q, want %q", got, want)
+	}
+	if got, want := fingerprint.JA3S(sh), "00447ab319e9d94ba2b4c1248e155917"; got != want {
+		t.Errorf("JA3S() = %q, want %q", got, want)
+	}
+}
+
+func TestParseServerHello_Errors(t *testing.T) {
+	retry := []byte{
+		0xcf, 0x21, 0xad, 0x74, 0xe5, 0x9a, 0x61, 0x11, 0xbe, 0x1d, 0x8c, 0x02, 0x1e, 0x65, 0xb8, 0x91,
+		0xc2, 0xa2, 0x11, 0x16, 0x7a, 0xbb, 0x8c, 0x5e, 0x07, 0x9e, 0x09, 0xe2, 0xc8, 0xa8, 0x33, 0x9c,
+	}
+	if _, err := fingerprint.ParseServerHello(serverHelloRecord(retry, 0x1301)); !errors.Is(err, fingerprint.ErrHelloRetryRequest) {
+		t.Errorf("ParseServerHello(HelloRetryRequest) error = %v, want ErrHelloRetryRequest", err)
+	}
+
+	record := serverHelloRecord(make([]byte, 32), 0x1301, extension(0x002b, 0x03, 0x04))
+	for name, data := range map[string][]byte{
+		"empty":        nil,
+		"alert":        {0x15, 0x03, 0x03, 0x00, 0x02, 0x02, 0x28},
+		"client hello": append([]byte{0x16, 0x03, 0x01, 0x00, 0x04, 0x01}, 0, 0, 0),
+		"truncated":    record[:len(record)-3],
+	} {
+		if _, err := fingerprint.ParseServerHello(data); err == nil {
+			t.Errorf("ParseServerHello(%s) error = nil", name)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package unit
+
+import (
+	"encoding/binary"
+	"net"
+	"slices"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+// SYN options of the default Windows, Linux and macOS stacks
+var (
+	windowsSYNOptions = []byte{2, 4, 0x05, 0xb4, 1, 3, 3, 8, 1, 1, 4, 2}
+	linuxSYNOptions   = []byte{2, 4, 0x05, 0xb4, 4, 2, 8, 10, 0, 0, 0, 1, 0, 0, 0, 0, 1, 3, 3, 7}
+	appleSYNOptions   = []byte{2, 4, 0x05, 0xb4, 1, 3, 3, 6, 1, 1, 8, 10, 0, 0, 0, 1, 0, 0, 0, 0, 4, 2, 0, 0}
+)
+
+// synPacket builds the IPv4 and TCP headers of a SYN
+func synPacket(src, dst string, sport, dport uint16, ttl uint8, window uint16, options []byte) []byte {
+	tcp := make([]byte, 20, 20+len(options))
+	binary.BigEndian.PutUint16(tcp[0:2], sport)
+	binary.BigEndian.PutUint16(tcp[2:4], dport)
+	binary.BigEndian.PutUint32(tcp[4:8], 1000)
+	tcp[12] = byte(5+len(options)/4) << 4
+	tcp[13] = 0x02
+	binary.BigEndian.PutUint16(tcp[14:16], window)
+	tcp = append(tcp, options...)
+
+	ip := make([]byte, 20, 20+len(tcp))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(tcp)))
+	ip[8] = ttl
+	ip[9] = 6
+	copy(ip[12:16], net.ParseIP(src).To4())
+	copy(ip[16:20], net.ParseIP(dst).To4())
+	return append(ip, tcp...)
+}
+
+func TestParseTCPSYN(t *testing.T) {
+	tests := []struct {
+		name    string
+		ttl     uint8
+		window  uint16
+		options []byte
+		want    string
+	}{
+		{"windows", 128, 64240, windowsSYNOptions, "64240_2-1-3-1-1-4_1460_8"},
+		{"linux", 64, 64240, linuxSYNOptions, "64240_2-4-8-1-3_1460_7"},
+		{"macos", 64, 65535, appleSYNOptions, "65535_2-1-3-1-1-8-4-0-0_1460_6"},
+		{"no options", 255, 1024, nil, "1024_00_00_00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tcp, err := fingerprint.ParseTCPSYN(synPacket("10.0.0.2", "10.0.0.1", 50000, 443, tt.ttl, tt.window, tt.options))
+			if err != nil {
+				t.Fatalf("ParseTCPSYN() error = %v", err)
+			}
+			if !tcp.Available || tcp.JA4T != tt.want || tcp.TTL != int(tt.ttl) || tcp.WindowSize != int(tt.window) {
+				t.Errorf("ParseTCPSYN() = %+v, want JA4T %s", tcp, tt.want)
+			}
+		})
+	}
+
+	tcp, _ := fingerprint.ParseTCPSYN(synPacket("10.0.0.2", "10.0.0.1", 50000, 443, 128, 64240, windowsSYNOptions))
+	if tcp.MSS != 1460 || tcp.WindowScale != 8 || !slices.Equal(tcp.Options, []int{2, 1, 3, 1, 1, 4}) {
+		t.Errorf("options = %+v", tcp)
+	}
+}
+
+func TestParseTCPSYN_Errors(t *testing.T) {
+	syn := synPacket("10.0.0.2", "10.0.0.1", 50000, 443, 64, 64240, linuxSYNOptions)
+	synACK := slices.Clone(syn)
+	synACK[20+13] = 0x12
+	udp := slices.Clone(syn)
+	udp[9] = 17
+	for name, packet := range map[string][]byte{
+		"empty":            nil,
+		"SYN-ACK":          synACK,
+		"UDP":              udp,
+		"truncated header": syn[:30],
+		"truncated option": syn[:len(syn)-2],
+		"not IP":           {0x00, 0x01},
+	} {
+		if tcp, err := fingerprint.ParseTCPSYN(packet); err == nil || tcp.Available {
+			t.Errorf("ParseTCPSYN(%s) = %+v, %v; want error", name, tcp, err)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package unit
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+func TestDefaultFingerprintDB_Golden(t *testing.T) {
+	entries, err := fingerprint.Golden()
+	if err != nil {
+		t.Fatalf("Golden() error = %v", err)
+	}
+	for _, e := range entries {
+		if !e.Fingerprint.TLS.Available {
+			continue
+		}
+		t.Run(e.ID, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(e.Fingerprint)
+			if e.Family == fingerprint.GoldenLibrary {
+				if !s.KnownBotFingerprint || !strings.Contains(s.ScoreBreakdown.String(), "known-bot-fp(+6)") {
+					t.Errorf("library not known: %s", s.ScoreBreakdown)
+				}
+				return
+			}
+			if !s.KnownBrowserFingerprint || !strings.Contains(s.ScoreBreakdown.String(), "known-browser-fp(+3)") {
+				t.Errorf("browser not known: %s", s.ScoreBreakdown)
+			}
+			if s.KnownFingerprintClient == "" {
+				t.Error("KnownFingerprintClient is empty")
+			}
+		})
+	}
+}
+
+func TestExtractSignals_KnownFingerprint(t *testing.T) {
+	chromeTLS := fingerprint.TLSFingerprint{Available: true, JA4Hash: "t13d1516h2_8daaf6152771_02713d6af862"}
+
+	// curl-impersonate naming itself gets no credit for Chrome's ClientHello
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.4.0"}, TLS: chromeTLS}
+	if s := fingerprint.ExtractSignals(fp); s.KnownBrowserFingerprint || s.KnownFingerprintClient != "" {
+		t.Errorf("library User-Agent credited with %q", s.KnownFingerprintClient)
+	}
+
+	// A proxy's ClientHello is not the client's
+	fp = interceptedChrome()
+	fp.TLS.JA3Hash = "0149f47eabf9a20d0893e2a44e5a6323"
+	if s := fingerprint.ExtractSignals(fp); !s.TLSIntercepted || s.KnownBotFingerprint {
+		t.Errorf("intercepted: TLSIntercepted = %v, KnownBotFingerprint = %v", s.TLSIntercepted, s.KnownBotFingerprint)
+	}
+
+	// Hashes match case-insensitively, JA4 before JA3
+	fp = fingerprint.Fingerprint{TLS: fingerprint.TLSFingerprint{Available: true, JA3Hash: "03117A8ED39EF02427EBBC39F121275C"}}
+	if s := fingerprint.ExtractSignals(fp); !s.KnownBotFingerprint || s.KnownFingerprintClient != "Go net/http 1.26" {
+		t.Errorf("JA3 lookup: KnownBotFingerprint = %v, client %q", s.KnownBotFingerprint, s.KnownFingerprintClient)
+	}
+}
+
+func TestReadFingerprintDB_Invalid(t *testing.T) {
+	tests := []struct {
+		name, data, want string
+	}{
+		{"json", `{"hash":`, "line 1"},
+		{"hash", `{"hash":"t13d_abc","label":"bot","client":"x"}`, "neither a JA3 hash nor a JA4"},
+		{"label", `{"hash":"0149f47eabf9a20d0893e2a44e5a6323","label":"human","client":"x"}`, "label must be"},
+		{"client", "\n" + `{"hash":"0149f47eabf9a20d0893e2a44e5a6323","label":"bot"}`, "line 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := fingerprint.ReadFingerprintDB(strings.NewReader(tt.data))
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("ReadFingerprintDB() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// writeFingerprintDB writes a fingerprint database file
+func writeFingerprintDB(t *testing.T, path, data string) {
+	t.Helper()
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadFingerprintDB_Overrides(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fingerprints.jsonl")
+	writeFingerprintDB(t, path, `{"hash":"T13D1516H2_8DAAF6152771_02713D6AF862","label":"bot","client":"scraper farm"}`+"\n"+
+		`{"hash":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","label":"bot","client":"aiohttp"}`+"\n")
+	db, err := fingerprint.LoadFingerprintDB(path)
+	if err != nil {
+		t.Fatalf("LoadFingerprintDB() error = %v", err)
+	}
+	if want := fingerprint.DefaultFingerprintDB().Len() + 1; db.Len() != want {
+		t.Errorf("Len() = %d, want %d", db.Len(), want)
+	}
+	k, ok := db.Lookup(fingerprint.TLSFingerprint{JA4Hash: "t13d1516h2_8daaf6152771_02713d6af862"})
+	if !ok || k.Label != "bot" || k.Client != "scraper farm" {
+		t.Errorf("overridden entry = %+v, %v", k, ok)
+	}
+
+	// Applied at runtime to a running classifier
+	clf := classifier.New()
+	fp := fingerprint.Fingerprint{TLS: fingerprint.TLSFingerprint{Available: true, JA3Hash: "aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa"}}
+	if clf.Classify(fp).Signals.KnownBotFingerprint {
+		t.Fatal("unknown hash flagged before SetFingerprints")
+	}
+	clf.SetFingerprints(db)
+	res := clf.Classify(fp)
+	if !res.Signals.KnownBotFingerprint || !strings.Contains(res.Reason, "known aiohttp TLS fingerprint") {
+		t.Errorf("after SetFingerprints: KnownBotFingerprint = %v, reason %q", res.Signals.KnownBotFingerprint, res.Reason)
+	}
+	if clf.Rules().Fingerprints != db {
+		t.Error("Rules().Fingerprints is not the database set")
+	}
+}
+
+func TestServer_ReloadFingerprints(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fingerprints.jsonl")
+	if _, err := server.New(server.WithLogger(logger.Config{LogDir: dir, FileName: "requests.jsonl"}),
+		server.WithFingerprintDB(path)); err == nil {
+		t.Fatal("New() with a missing fingerprint database succeeded")
+	}
+
+	writeFingerprintDB(t, path, `{"hash":"aaaaaaaaaaaaaaaaaaaaaaaaaaaaaaaa","label":"bot","client":"aiohttp"}`+"\n")
+	srv, err := server.New(server.WithLogger(logger.Config{LogDir: dir, FileName: "requests.jsonl"}),
+		server.WithFingerprintDB(path))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer func() { _ = srv.Close() }()
+	if err := srv.ReloadFingerprints(); err != nil {
+		t.Errorf("ReloadFingerprints() error = %v", err)
+	}
+	writeFingerprintDB(t, path, "not json\n")
+	if err := srv.ReloadFingerprints(); err == nil {
+		t.Error("ReloadFingerprints() of an invalid file succeeded")
+	}
+}
@@ -1,13 +1,23 @@
 package unit
 
 import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/x509"
 	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/muliwe/go-client-classifier/internal/classifier"
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
 	"github.com/muliwe/go-client-classifier/internal/logger"
 )
@@ -208,3 +218,394 @@ func TestLoggerClose(t *testing.T) {
 		t.Errorf("Close() error = %v", err)
 	}
 }
+
+func TestValidateEntry_ClassifiedRequest(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept-Language", "en-US")
+
+	fp := fingerprint.NewCollector().Collect(req)
+	result := classifier.New(classifier.DefaultConfig()).Classify(fp)
+
+	entry := logger.LogEntry{
+		Timestamp:      result.Timestamp,
+		RequestID:      result.RequestID,
+		RemoteAddr:     req.RemoteAddr,
+		Classification: result.Classification,
+		Confidence:     result.Confidence,
+		Fingerprint:    result.Fingerprint,
+		Signals:        result.Signals,
+		Score:          result.Score,
+		Reason:         result.Reason,
+		ResponseTimeMs: 1,
+	}
+
+	if err := logger.ValidateEntry(entry); err != nil {
+		t.Errorf("ValidateEntry() error = %v", err)
+	}
+}
+
+func TestValidateEntry_SpoofingCombos(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Proto, req.ProtoMajor, req.ProtoMinor = "HTTP/1.0", 1, 0
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Sec-Fetch-Mode", "navigate")
+
+	fp := fingerprint.NewCollector().Collect(req)
+	result := classifier.New(classifier.DefaultConfig()).Classify(fp)
+	if len(result.Signals.SpoofingCombos) == 0 {
+		t.Fatal("request matched no impossible combination")
+	}
+
+	entry := logger.NewEntry(result, req.RemoteAddr, 1)
+	if err := logger.ValidateEntry(entry); err != nil {
+		t.Errorf("ValidateEntry() error = %v", err)
+	}
+}
+
+func TestValidateJSON_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"not json", `{"timestamp":`},
+		{"missing fields", `{"timestamp":"2026-02-12T12:40:35Z","request_id":"x"}`},
+		{"wrong type", `{"timestamp":"2026-02-12T12:40:35Z","request_id":"x","remote_addr":"","classification":"bot","confidence":"high",` +
+			`"fingerprint":{"tls":{"available":false},"http":{"version":"HTTP/1.1","method":"GET","path":"/","header_count":0},"session":{"available":false}},` +
+			`"signals":{"browser_score":0,"bot_score":0,"score_breakdown":""},"score":0,"reason":"","response_time_ms":0}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := logger.ValidateJSON([]byte(tt.line)); err == nil {
+				t.Error("ValidateJSON() should fail")
+			}
+		})
+	}
+}
+
+func TestLoggerLog_ValidateRejectsInvalid(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	l, err := logger.New(logger.Config{
+		LogDir:   tmpDir,
+		FileName: "test.jsonl",
+		Validate: true,
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	entry := logger.LogEntry{
+		Timestamp:      time.Now().UTC(),
+		RequestID:      "test-123",
+		Classification: "unknown",
+		Confidence:     0.5,
+	}
+	if err := l.Log(entry); err == nil {
+		t.Error("Log() should reject entry with invalid classification")
+	}
+
+	entry.Classification = "bot"
+	if err := l.Log(entry); err != nil {
+		t.Errorf("Log() error = %v", err)
+	}
+
+	if err := l.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "test.jsonl"))
+	if err != nil {
+		t.Fatalf("Failed to read log file: %v", err)
+	}
+	if lines := strings.Count(string(data), "\n"); lines != 1 {
+		t.Errorf("log has %d lines, want 1 (invalid entry must not be written)", lines)
+	}
+}
+
+func TestReader_Next(t *testing.T) {
+	input := `{"request_id":"a","classification":"bot"}
+
+{"request_id":"b","classification":"browser"}
+`
+	r := logger.NewReader(strings.NewReader(input))
+
+	first, err := r.Next()
+	if err != nil || first.RequestID != "a" || r.Line() != 1 {
+		t.Fatalf("Next() = %q line %d, %v; want a line 1", first.RequestID, r.Line(), err)
+	}
+	second, err := r.Next()
+	if err != nil || second.RequestID != "b" || r.Line() != 3 {
+		t.Fatalf("Next() = %q line %d, %v; want b line 3", second.RequestID, r.Line(), err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("Next() at end error = %v, want io.EOF", err)
+	}
+}
+
+func TestReader_InvalidLine(t *testing.T) {
+	r := logger.NewReader(strings.NewReader("{\"request_id\":\"a\"}\nnot json\n"))
+
+	if _, err := r.Next(); err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	_, err := r.Next()
+	if err == nil || !strings.Contains(err.Error(), "line 2") {
+		t.Errorf("Next() error = %v, want error mentioning line 2", err)
+	}
+}
+
+func TestFindEntry(t *testing.T) {
+	// Enough lines to span several read chunks, with a long line across a
+	// chunk boundary, a duplicate ID and a torn trailing write
+	var b strings.Builder
+	for i := range 3000 {
+		fmt.Fprintf(&b, "{\"request_id\":\"req-%d\",\"classification\":\"bot\"}\n", i)
+	}
+	fmt.Fprintf(&b, "{\"request_id\":\"long\",\"reason\":%q}\n", strings.Repeat("x", 150<<10))
+	b.WriteString("not json \"req-7\"\n")
+	b.WriteString("{\"request_id\":\"req-7\",\"classification\":\"browser\"}\n")
+	b.WriteString(`{"request_id":"req-9","classifi`)
+	path := filepath.Join(t.TempDir(), "log.jsonl")
+	if err := os.WriteFile(path, []byte(b.String()), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		id             string
+		found          bool
+		classification string
+	}{
+		{"req-0", true, "bot"},
+		{"req-9", true, "bot"},
+		{"req-7", true, "browser"},
+		{"long", true, ""},
+		{"missing", false, ""},
+	}
+	for _, tt := range tests {
+		e, found, err := logger.FindEntry(path, tt.id)
+		if err != nil || found != tt.found || e.Classification != tt.classification {
+			t.Errorf("FindEntry(%q) = %q, %v, %v; want %q, %v", tt.id, e.Classification, found, err, tt.classification, tt.found)
+		}
+	}
+	if e, _, _ := logger.FindEntry(path, "long"); len(e.Reason) != 150<<10 {
+		t.Errorf("long entry reason has %d bytes, want %d", len(e.Reason), 150<<10)
+	}
+	if _, _, err := logger.FindEntry(filepath.Join(t.TempDir(), "none.jsonl"), "req-0"); err == nil {
+		t.Error("FindEntry() on a missing file succeeded")
+	}
+}
+
+func TestLoggerLog_MinimalProfile(t *testing.T) {
+	req := httptest.NewRequest("GET", "/account?email=jane@example.com", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Cookie", "session=secret-cookie")
+	req.Header.Set("Accept-Language", "en-US")
+
+	result := classifier.New(classifier.DefaultConfig()).Classify(fingerprint.NewCollector().Collect(req))
+
+	dir := t.TempDir()
+	l, err := logger.New(logger.Config{LogDir: dir, FileName: "min.jsonl", Profile: logger.ProfileMinimal, Validate: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if l.Profile() != logger.ProfileMinimal {
+		t.Errorf("Profile() = %q", l.Profile())
+	}
+	if err := l.LogResult(result, "203.0.113.7:4000", 1); err != nil {
+		t.Fatalf("LogResult() error = %v", err)
+	}
+	_ = l.Close()
+
+	data, err := os.ReadFile(filepath.Join(dir, "min.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	line := string(data)
+	for _, leak := range []string{"203.0.113.7", "Mozilla", "secret-cookie", "jane@example.com", "en-US"} {
+		if strings.Contains(line, leak) {
+			t.Errorf("minimal entry contains %q: %s", leak, line)
+		}
+	}
+
+	var entry logger.LogEntry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Classification != result.Classification || entry.Score != result.Score {
+		t.Errorf("minimal entry lost the classification: %+v", entry)
+	}
+	if entry.Fingerprint.HTTP.JA4HHash != result.Fingerprint.HTTP.JA4HHash || !entry.Fingerprint.HTTP.HasCookies {
+		t.Errorf("minimal entry lost hashes or coarse metadata: %+v", entry.Fingerprint.HTTP)
+	}
+}
+
+func TestParseProfile(t *testing.T) {
+	for in, want := range map[string]logger.Profile{"": logger.ProfileFull, "full": logger.ProfileFull, "minimal": logger.ProfileMinimal} {
+		if got, err := logger.ParseProfile(in); err != nil || got != want {
+			t.Errorf("ParseProfile(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := logger.ParseProfile("none"); err == nil {
+		t.Error("ParseProfile(none) should fail")
+	}
+	if _, err := logger.New(logger.Config{LogDir: t.TempDir(), FileName: "x.jsonl", Profile: "verbose"}); err == nil {
+		t.Error("New() with unknown profile should fail")
+	}
+}
+
+// writeAuditLog logs n classified entries in audit mode, validating each
+func writeAuditLog(t *testing.T, cfg logger.Config, first, n int) {
+	t.Helper()
+	l, err := logger.New(cfg)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	for i := range n {
+		req := httptest.NewRequest("GET", "/", nil)
+		req.Header.Set("User-Agent", "curl/8.0.1")
+		result := classifier.New(classifier.DefaultConfig()).Classify(fingerprint.NewCollector().Collect(req))
+		result.RequestID = "req-" + strconv.Itoa(first+i)
+		if err := l.LogResult(result, "127.0.0.1:1234", 1); err != nil {
+			t.Fatalf("LogResult() error = %v", err)
+		}
+	}
+	if err := l.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+}
+
+// verifyAuditLog verifies a log and its checkpoints with pub
+func verifyAuditLog(t *testing.T, path string, pub ed25519.PublicKey) (logger.AuditReport, error) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cps, err := os.ReadFile(logger.CheckpointPath(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return logger.VerifyAudit(bytes.NewReader(data), bytes.NewReader(cps), pub)
+}
+
+func TestLoggerAudit(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := logger.Config{
+		LogDir:   t.TempDir(),
+		FileName: "audit.jsonl",
+		Validate: true,
+		Audit:    logger.AuditConfig{Enabled: true, SigningKey: priv, CheckpointEvery: 2},
+	}
+	path := filepath.Join(cfg.LogDir, cfg.FileName)
+
+	// The chain resumes across restarts
+	writeAuditLog(t, cfg, 1, 3)
+	writeAuditLog(t, cfg, 4, 2)
+
+	report, err := verifyAuditLog(t, path, pub)
+	if err != nil {
+		t.Fatalf("VerifyAudit() error = %v", err)
+	}
+	// Checkpoints at 2, 3 (on close) and 5
+	if report.Audited != 5 || report.Head.Seq != 5 || report.Checkpoints != 3 || report.Signed != 5 {
+		t.Errorf("report = %+v, want 5 audited entries and 3 checkpoints up to seq 5", report)
+	}
+
+	// Entries still read as ordinary log entries
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = f.Close() }()
+	e, err := logger.NewReader(f).Next()
+	if err != nil || e.RequestID != "req-1" || e.Audit == nil || e.Audit.Seq != 1 || e.Audit.Prev != "" {
+		t.Errorf("first entry = %+v (%v), want req-1 at seq 1", e.Audit, err)
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.SplitAfter(string(original), "\n")
+	tests := []struct {
+		name string
+		log  string
+		want string
+	}{
+		{"altered", strings.Replace(string(original), `"req-2","remote_addr":"127.0.0.1:1234","classification":"bot"`, `"req-2","remote_addr":"127.0.0.1:1234","classification":"browser"`, 1), "seq 2 was altered"},
+		{"removed", lines[0] + lines[2] + lines[3] + lines[4], "chain broken"},
+		{"truncated", strings.Join(lines[:4], ""), "before a signed checkpoint"},
+		{"unaudited insert", lines[0] + `{"request_id":"forged"}` + "\n" + strings.Join(lines[1:], ""), "without audit record"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(path, []byte(tt.log), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if _, err := verifyAuditLog(t, path, pub); err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("VerifyAudit() error = %v, want %q", err, tt.want)
+			}
+		})
+	}
+
+	if err := os.WriteFile(path, original, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	otherPub, _, _ := ed25519.GenerateKey(nil)
+	if _, err := verifyAuditLog(t, path, otherPub); err == nil || !strings.Contains(err.Error(), "invalid signature") {
+		t.Errorf("VerifyAudit() with another key error = %v, want invalid signature", err)
+	}
+}
+
+func TestLoggerAudit_UnauditedPrefix(t *testing.T) {
+	cfg := logger.Config{LogDir: t.TempDir(), FileName: "audit.jsonl"}
+	path := filepath.Join(cfg.LogDir, cfg.FileName)
+	writeAuditLog(t, cfg, 1, 2)
+
+	data, _ := os.ReadFile(path)
+	if _, err := logger.VerifyAudit(bytes.NewReader(data), nil, nil); err == nil {
+		t.Error("VerifyAudit() accepted a log without audited entries")
+	}
+
+	// Enabling audit mode on an existing log starts the chain after it
+	cfg.Audit.Enabled = true
+	writeAuditLog(t, cfg, 3, 2)
+	data, _ = os.ReadFile(path)
+	report, err := logger.VerifyAudit(bytes.NewReader(data), nil, nil)
+	if err != nil {
+		t.Fatalf("VerifyAudit() error = %v", err)
+	}
+	if report.Unaudited != 2 || report.Audited != 2 || report.Checkpoints != 0 {
+		t.Errorf("report = %+v, want 2 unaudited and 2 audited entries", report)
+	}
+	if _, err := os.Stat(logger.CheckpointPath(path)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("checkpoint file written without a signing key: %v", err)
+	}
+}
+
+func TestLoadAuditKeys(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	dir := t.TempDir()
+	privDER, _ := x509.MarshalPKCS8PrivateKey(priv)
+	pubDER, _ := x509.MarshalPKIXPublicKey(pub)
+	privPath, pubPath := filepath.Join(dir, "audit.key"), filepath.Join(dir, "audit.pub")
+	_ = os.WriteFile(privPath, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privDER}), 0o600)
+	_ = os.WriteFile(pubPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pubDER}), 0o644)
+
+	gotPriv, err := logger.LoadSigningKey(privPath)
+	if err != nil || !gotPriv.Equal(priv) {
+		t.Errorf("LoadSigningKey() = %v, want the written key", err)
+	}
+	gotPub, err := logger.LoadVerifyKey(pubPath)
+	if err != nil || !gotPub.Equal(pub) {
+		t.Errorf("LoadVerifyKey() = %v, want the written key", err)
+	}
+	if _, err := logger.LoadSigningKey(pubPath); err == nil {
+		t.Error("LoadSigningKey() accepted a public key")
+	}
+}
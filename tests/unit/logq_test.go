@@ -0,0 +1,165 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/logq"
+)
+
+var logqStart = time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+// logqLog returns a request log with a browser, curl and two GPTBot
+// requests, one minute apart
+func logqLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	entry := func(i int, class, addr, ua, ja3 string) logger.LogEntry {
+		return logger.LogEntry{
+			Timestamp:      logqStart.Add(time.Duration(i) * time.Minute),
+			RequestID:      strings.Repeat(string(rune('a'+i)), 4),
+			RemoteAddr:     addr,
+			Classification: class,
+			Fingerprint: fingerprint.Fingerprint{
+				TLS:  fingerprint.TLSFingerprint{JA3Hash: ja3, JA4Hash: "t13d1516h2_8daaf6152771_02713d6af862"},
+				HTTP: fingerprint.HTTPFingerprint{UserAgent: ua},
+			},
+		}
+	}
+	entries := []logger.LogEntry{
+		entry(0, "browser", "198.51.100.7:50000", "Mozilla/5.0 Chrome/131.0.0.0", "aaa"),
+		entry(1, "bot", "203.0.113.5:41000", "curl/8.0.1", "bbb"),
+		entry(2, "bot", "203.0.113.9:41001", "Mozilla/5.0 (compatible; GPTBot/1.2)", "ccc"),
+		entry(3, "bot", "[2001:db8::1]:443", "Mozilla/5.0 (compatible; GPTBot/1.2)", "ccc"),
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+	return &buf
+}
+
+func TestLogq_Filters(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter logq.Filter
+		want   []string // request IDs
+	}{
+		{"all", logq.Filter{}, []string{"aaaa", "bbbb", "cccc", "dddd"}},
+		{"class", logq.Filter{Class: "bot"}, []string{"bbbb", "cccc", "dddd"}},
+		{"since and until", logq.Filter{Since: logqStart.Add(time.Minute), Until: logqStart.Add(3 * time.Minute)}, []string{"bbbb", "cccc"}},
+		{"ip", logq.Filter{IP: "203.0.113.9"}, []string{"cccc"}},
+		{"cidr", logq.Filter{IP: "203.0.113.0/24"}, []string{"bbbb", "cccc"}},
+		{"ipv6 cidr", logq.Filter{IP: "2001:db8::/32"}, []string{"dddd"}},
+		{"ja3", logq.Filter{JA3: "CCC"}, []string{"cccc", "dddd"}},
+		{"ja4 prefix", logq.Filter{JA4: "t13d1516h2"}, []string{"aaaa", "bbbb", "cccc", "dddd"}},
+		{"ua", logq.Filter{UserAgent: "gptbot"}, []string{"cccc", "dddd"}},
+		{"combined", logq.Filter{Class: "bot", UserAgent: "gptbot", IP: "203.0.113.0/24"}, []string{"cccc"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := logq.Run(logqLog(t), logq.Query{Filter: tt.filter})
+			if err != nil {
+				t.Fatalf("Run() error = %v", err)
+			}
+			var got []string
+			for _, e := range res.Entries {
+				got = append(got, e.RequestID)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+			if res.Scanned != 4 || res.Matched != len(tt.want) {
+				t.Errorf("scanned %d, matched %d; want 4, %d", res.Scanned, res.Matched, len(tt.want))
+			}
+		})
+	}
+}
+
+func TestLogq_LimitKeepsMostRecent(t *testing.T) {
+	res, err := logq.Run(logqLog(t), logq.Query{Limit: 1})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(res.Entries) != 1 || res.Entries[0].RequestID != "dddd" || res.Matched != 4 {
+		t.Errorf("result = %+v, want only the last entry of 4 matches", res)
+	}
+}
+
+func TestLogq_CountByCrawler(t *testing.T) {
+	res, err := logq.Run(logqLog(t), logq.Query{GroupBy: logq.ByCrawler})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := []logq.Group{
+		{Key: "gptbot", Count: 2, Bot: 2, FirstSeen: logqStart.Add(2 * time.Minute), LastSeen: logqStart.Add(3 * time.Minute)},
+		{Key: logq.NoValue, Count: 1, Browser: 1, FirstSeen: logqStart, LastSeen: logqStart},
+		{Key: "curl", Count: 1, Bot: 1, FirstSeen: logqStart.Add(time.Minute), LastSeen: logqStart.Add(time.Minute)},
+	}
+	if len(res.Groups) != len(want) || res.TotalGroups != len(want) {
+		t.Fatalf("groups = %+v, want %+v", res.Groups, want)
+	}
+	for i := range want {
+		g := res.Groups[i]
+		if g.Key != want[i].Key || g.Count != want[i].Count || g.Bot != want[i].Bot || g.Browser != want[i].Browser ||
+			!g.FirstSeen.Equal(want[i].FirstSeen) || !g.LastSeen.Equal(want[i].LastSeen) {
+			t.Errorf("groups[%d] = %+v, want %+v", i, g, want[i])
+		}
+	}
+
+	res, err = logq.Run(logqLog(t), logq.Query{GroupBy: logq.ByIP, Limit: 2})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(res.Groups) != 2 || res.TotalGroups != 4 || res.Groups[0].Key != "198.51.100.7" {
+		t.Errorf("groups = %+v (total %d), want the first 2 of 4 IPs by key", res.Groups, res.TotalGroups)
+	}
+}
+
+func TestLogq_InvalidQuery(t *testing.T) {
+	tests := []struct {
+		name string
+		q    logq.Query
+	}{
+		{"class", logq.Query{Filter: logq.Filter{Class: "crawler"}}},
+		{"ip", logq.Query{Filter: logq.Filter{IP: "203.0.113"}}},
+		{"cidr", logq.Query{Filter: logq.Filter{IP: "203.0.113.0/33"}}},
+		{"time range", logq.Query{Filter: logq.Filter{Since: logqStart, Until: logqStart}}},
+		{"group field", logq.Query{GroupBy: "country"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := logq.New(tt.q); err == nil {
+				t.Error("New() should fail")
+			}
+		})
+	}
+}
+
+func TestLogq_ParseTime(t *testing.T) {
+	now := time.Date(2026, 10, 15, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		in   string
+		want time.Time
+	}{
+		{"90m", now.Add(-90 * time.Minute)},
+		{"2026-10-01", time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)},
+		{"2026-10-01T08:30:00+02:00", time.Date(2026, 10, 1, 6, 30, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		got, err := logq.ParseTime(tt.in, now)
+		if err != nil || !got.Equal(tt.want) {
+			t.Errorf("ParseTime(%q) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	if _, err := logq.ParseTime("yesterday", now); err == nil {
+		t.Error("ParseTime(yesterday) should fail")
+	}
+}
@@ -0,0 +1,150 @@
+package unit
+
+import (
+	"fmt"
+	"runtime/debug"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/membudget"
+	"github.com/muliwe/go-client-classifier/internal/session"
+)
+
+func TestMembudget_ParseBytes(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+		ok   bool
+	}{
+		{"1048576", 1 << 20, true},
+		{"512B", 512, true},
+		{"64KiB", 64 << 10, true},
+		{"64MiB", 64 << 20, true},
+		{"2GiB", 2 << 30, true},
+		{"1TiB", 1 << 40, true},
+		{"", 0, false},
+		{"64MB", 0, false},
+		{"-1MiB", 0, false},
+		{"lots", 0, false},
+	}
+
+	for _, tt := range tests {
+		got, err := membudget.ParseBytes(tt.in)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("ParseBytes(%q) = %d, %v; want %d (ok=%v)", tt.in, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMembudget_New(t *testing.T) {
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(-1))
+
+	if _, err := membudget.New(membudget.Config{Bytes: -1}); err == nil {
+		t.Error("negative budget should be rejected")
+	}
+	if _, err := membudget.New(membudget.Config{Bytes: 1 << 20, Pressure: 1.5}); err == nil {
+		t.Error("pressure above 1 should be rejected")
+	}
+
+	b, err := membudget.New(membudget.Config{Bytes: 8 << 20})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if b.Bytes() != 8<<20 {
+		t.Errorf("Bytes() = %d, want %d", b.Bytes(), 8<<20)
+	}
+
+	// Without an explicit budget a fraction of GOMEMLIMIT is used
+	debug.SetMemoryLimit(1 << 30)
+	b, err = membudget.New(membudget.Config{Fraction: 0.5})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if b.Bytes() != 1<<29 {
+		t.Errorf("Bytes() = %d, want half of GOMEMLIMIT", b.Bytes())
+	}
+}
+
+func TestMembudget_RegisterSplitsBudget(t *testing.T) {
+	tracker := session.New(session.DefaultConfig())
+	store := captcha.NewStore(captcha.DefaultStoreConfig())
+
+	b, err := membudget.New(membudget.Config{Bytes: 1 << 20})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b.Register("sessions", tracker)
+	b.Register("captcha", store)
+
+	states := b.States()
+	if len(states) != 2 {
+		t.Fatalf("States() = %+v, want 2 stores", states)
+	}
+	share := (1 << 20) / 2
+	want := map[string]int{
+		"sessions": share / tracker.EntryBytes(),
+		"captcha":  share / store.EntryBytes(),
+	}
+	for _, s := range states {
+		if s.Capacity != want[s.Name] {
+			t.Errorf("%s capacity = %d, want %d", s.Name, s.Capacity, want[s.Name])
+		}
+	}
+
+	// Capacity is enforced on the store
+	now := time.Now()
+	for i := range want["captcha"] + 50 {
+		store.MarkVerified(fmt.Sprintf("10.0.0.%d|curl", i), now.Add(time.Duration(i)*time.Millisecond))
+	}
+	if store.Len() != want["captcha"] {
+		t.Errorf("captcha store Len() = %d, want capacity %d", store.Len(), want["captcha"])
+	}
+}
+
+func TestMembudget_CheckShrinksUnderPressure(t *testing.T) {
+	defer debug.SetMemoryLimit(debug.SetMemoryLimit(1 << 30))
+
+	tracker := session.New(session.DefaultConfig())
+	b, err := membudget.New(membudget.Config{Bytes: 4 << 20, Pressure: 0.5})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	b.Register("sessions", tracker)
+
+	now := time.Now()
+	for i := range 1000 {
+		tracker.Observe(fmt.Sprintf("10.0.%d.%d|curl", i/256, i%256), now.Add(time.Duration(i)*time.Millisecond), false)
+	}
+
+	if b.Check(100 << 20) {
+		t.Error("stores should not shrink below the pressure threshold")
+	}
+	if tracker.Len() != 1000 {
+		t.Errorf("Len() = %d, want 1000", tracker.Len())
+	}
+
+	// Above half of the 1GiB limit the store halves
+	if !b.Check(600 << 20) {
+		t.Error("stores should shrink above the pressure threshold")
+	}
+	if tracker.Len() != 500 {
+		t.Errorf("Len() after shrink = %d, want 500", tracker.Len())
+	}
+
+	// Least recently seen sessions are evicted first
+	if got := tracker.Observe("10.0.3.231|curl", now.Add(2*time.Second), false); got.RequestCount != 2 {
+		t.Errorf("most recent session was evicted (RequestCount = %d)", got.RequestCount)
+	}
+
+	// Still under pressure just below the threshold (hysteresis)
+	if !b.Check(450 << 20) {
+		t.Error("stores should stay shrunk until use falls well below the threshold")
+	}
+	if b.Check(100 << 20) {
+		t.Error("stores should be restored once pressure is gone")
+	}
+	if got := b.States()[0]; got.Capacity != (4<<20)/tracker.EntryBytes() {
+		t.Errorf("capacity = %d after restore", got.Capacity)
+	}
+}
@@ -0,0 +1,86 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandler_Metrics(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+
+	// One bot (bare curl) and one browser-like request
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	h.HandleClassify(httptest.NewRecorder(), req)
+
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Accept-Language", "en-US")
+	req.Header.Set("Sec-Fetch-Site", "none")
+	req.Header.Set("Sec-Fetch-Mode", "navigate")
+	h.HandleClassify(httptest.NewRecorder(), req)
+
+	rr := httptest.NewRecorder()
+	h.HandleMetrics(rr, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	body := rr.Body.String()
+	for _, want := range []string{
+		"# TYPE classifier_net_score histogram",
+		`classifier_net_score_count{classification="bot"} 1`,
+		`classifier_net_score_count{classification="browser"} 1`,
+		`classifier_net_score_bucket{classification="bot",le="+Inf"} 1`,
+		`classifier_confidence_count{classification="bot"} 1`,
+		"# TYPE classifier_rule_fired_total counter",
+		`classifier_rule_fired_total{rule="bot-ua",side="bot",classification="bot"} 1`,
+		`classifier_rule_fired_total{rule="sec-fetch",side="browser",classification="browser"} 1`,
+		`classifier_rule_fired_total{rule="sec-fetch",side="browser",classification="bot"} 0`,
+		"# TYPE classifier_stage_duration_seconds histogram",
+		`classifier_stage_duration_seconds_count{stage="collect"} 2`,
+		`classifier_stage_duration_seconds_count{stage="enrich"} 2`,
+		`classifier_stage_duration_seconds_count{stage="classify"} 2`,
+		`classifier_stage_duration_seconds_count{stage="log"} 2`,
+		"# TYPE classifier_enrichment_skipped_total counter",
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q", want)
+		}
+	}
+
+	// Buckets are cumulative: the bot's negative net score falls in le="0"
+	if !strings.Contains(body, `classifier_net_score_bucket{classification="bot",le="0"} 1`) {
+		t.Errorf("bot net score not counted at or below 0:\n%s", body)
+	}
+}
+
+func TestHandler_DebugTimings(t *testing.T) {
+	h := createTestHandler()
+	rr := httptest.NewRecorder()
+	h.HandleDebug(rr, httptest.NewRequest(http.MethodGet, "/debug", nil))
+
+	for _, stage := range []string{"collect;dur=", "enrich;dur=", "classify;dur=", "log;dur="} {
+		if st := rr.Header().Get("Server-Timing"); !strings.Contains(st, stage) {
+			t.Errorf("Server-Timing = %q, missing %s", st, stage)
+		}
+	}
+	var body struct {
+		Classification string `json:"classification"`
+		Timings        struct {
+			CollectMs *float64 `json:"collect_ms"`
+			TotalMs   *float64 `json:"total_ms"`
+		} `json:"timings"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if body.Classification == "" || body.Timings.CollectMs == nil || body.Timings.TotalMs == nil {
+		t.Errorf("debug body = %+v", body)
+	}
+}
@@ -0,0 +1,170 @@
+package unit
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/evaluate"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/model"
+)
+
+func mustModel(t *testing.T, data string) *model.Model {
+	t.Helper()
+	m, err := model.Parse([]byte(data))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return m
+}
+
+func TestModelParse(t *testing.T) {
+	m := mustModel(t, `{"version":"v1","intercept":-1,"weights":{"has_sec_fetch_headers":3}}`)
+	if m.Threshold != model.DefaultThreshold {
+		t.Errorf("Threshold = %v, want default %v", m.Threshold, model.DefaultThreshold)
+	}
+	if got := m.Predict(fingerprint.Signals{HasSecFetchHeaders: true}); math.Abs(got-1/(1+math.Exp(-2))) > 1e-9 {
+		t.Errorf("Predict() = %v, want sigmoid(2)", got)
+	}
+	if got := m.Predict(fingerprint.Signals{}); got >= 0.5 {
+		t.Errorf("Predict(no signals) = %v, want < 0.5", got)
+	}
+
+	for name, data := range map[string]string{
+		"unknown signal":     `{"weights":{"no_such_signal":1}}`,
+		"non-boolean signal": `{"weights":{"bot_score":1}}`,
+		"threshold above 1":  `{"threshold":1.5}`,
+		"negative threshold": `{"threshold":-0.2}`,
+		"invalid JSON":       `{"weights":`,
+	} {
+		if _, err := model.Parse([]byte(data)); err == nil {
+			t.Errorf("%s: Parse() error = nil", name)
+		}
+	}
+}
+
+func TestModelFeatures(t *testing.T) {
+	features := model.Features()
+	for _, name := range []string{"has_sec_fetch_headers", "is_http2", "ua_is_bot"} {
+		if !slices.Contains(features, name) {
+			t.Errorf("Features() is missing %s", name)
+		}
+	}
+	if slices.Contains(features, "browser_score") {
+		t.Error("Features() includes the numeric browser_score")
+	}
+}
+
+func TestModelTrain(t *testing.T) {
+	var examples []model.Example
+	for _, s := range evaluationSamples()[:4] {
+		examples = append(examples, model.Example{
+			Signals: fingerprint.ExtractSignals(s.Fingerprint),
+			Browser: s.Label == classifier.ClassificationBrowser,
+		})
+	}
+	m, err := model.Train(examples, model.TrainOptions{Version: "trained"})
+	if err != nil {
+		t.Fatalf("Train() error = %v", err)
+	}
+	if m.Version != "trained" || len(m.Weights) == 0 {
+		t.Errorf("Train() = version %q with %d weights", m.Version, len(m.Weights))
+	}
+	for _, e := range examples {
+		if p := m.Predict(e.Signals); (p >= m.Threshold) != e.Browser {
+			t.Errorf("Predict() = %.3f for browser=%v", p, e.Browser)
+		}
+	}
+
+	if _, err := model.Train(nil, model.TrainOptions{}); err == nil {
+		t.Error("Train(nil) error = nil")
+	}
+}
+
+func TestClassify_MLBackend(t *testing.T) {
+	browser := evaluationSamples()[0].Fingerprint
+	curl := evaluationSamples()[2].Fingerprint
+
+	// A model that only trusts Sec-Fetch headers
+	m := mustModel(t, `{"version":"v1","intercept":-1,"weights":{"has_sec_fetch_headers":3}}`)
+	c := classifier.New(classifier.WithModel(m))
+	if c.Backend() != classifier.BackendML {
+		t.Fatalf("Backend() = %s, want ml", c.Backend())
+	}
+
+	result := c.Classify(browser)
+	if result.Classification != classifier.ClassificationBrowser {
+		t.Fatalf("Classification = %s, want browser", result.Classification)
+	}
+	if want := 1 / (1 + math.Exp(-2)); math.Abs(result.Confidence-want) > 1e-9 {
+		t.Errorf("Confidence = %v, want %v", result.Confidence, want)
+	}
+	if result.Margin != 38 {
+		t.Errorf("Margin = %d, want 38 percentage points", result.Margin)
+	}
+	if !slices.Equal(result.FlipSet, []string{"has_sec_fetch_headers"}) {
+		t.Errorf("FlipSet = %v, want [has_sec_fetch_headers]", result.FlipSet)
+	}
+	if !strings.Contains(result.Reason, "Model v1") || !strings.Contains(result.Reason, "has_sec_fetch_headers") {
+		t.Errorf("Reason = %q", result.Reason)
+	}
+	if len(result.Signals.ScoreBreakdown) == 0 {
+		t.Error("rule signals should still be reported with the ml backend")
+	}
+
+	result = c.Classify(curl)
+	if result.Classification != classifier.ClassificationBot || result.Margin >= 0 {
+		t.Errorf("curl: Classification = %s, Margin = %d, want bot below 0", result.Classification, result.Margin)
+	}
+	if result.FlipSet != nil {
+		t.Errorf("curl: FlipSet = %v, want none: no signal pushes towards bot", result.FlipSet)
+	}
+	if result.Class != classifier.ClassHTTPLibrary {
+		t.Errorf("curl: Class = %s, want http_library", result.Class)
+	}
+}
+
+func TestClassify_MLBackendFallback(t *testing.T) {
+	c := classifier.New(classifier.WithBackend(classifier.BackendML))
+	if c.Backend() != classifier.BackendHeuristic {
+		t.Errorf("Backend() = %s, want heuristic without a model", c.Backend())
+	}
+	heuristic := classifier.New()
+	fp := evaluationSamples()[2].Fingerprint
+	if got, want := c.Classify(fp), heuristic.Classify(fp); got.Classification != want.Classification || got.Margin != want.Margin {
+		t.Errorf("fallback = %s (margin %d), want %s (margin %d)", got.Classification, got.Margin, want.Classification, want.Margin)
+	}
+
+	if !classifier.ValidBackend("") || !classifier.ValidBackend("ml") || classifier.ValidBackend("gbt") {
+		t.Error("ValidBackend() accepts the wrong names")
+	}
+}
+
+func TestEvaluateRun_MLBackend(t *testing.T) {
+	m := mustModel(t, `{"intercept":-1,"weights":{"has_sec_fetch_headers":3}}`)
+	report := evaluate.Run(evaluationSamples(), classifier.NewConfig(classifier.WithModel(m)))
+	if report.Backend != classifier.BackendML {
+		t.Errorf("Backend = %s, want ml", report.Backend)
+	}
+	if report.Ablation != nil {
+		t.Errorf("Ablation = %v, want none for a model", report.Ablation)
+	}
+	if math.Abs(report.Accuracy-0.8) > 1e-9 {
+		t.Errorf("Accuracy = %v, want 0.8", report.Accuracy)
+	}
+}
+
+func TestClassifierFeedback_MLBackend(t *testing.T) {
+	m := mustModel(t, `{"intercept":-1,"weights":{"has_sec_fetch_headers":3}}`)
+	c := classifier.New(classifier.WithModel(m), classifier.WithFeedbackAdjust(true))
+	res, err := c.Feedback(classifier.Feedback{Label: "browser", Fingerprint: evaluationSamples()[2].Fingerprint})
+	if err != nil {
+		t.Fatalf("Feedback() error = %v", err)
+	}
+	if res.Adjusted != nil {
+		t.Errorf("Adjusted = %v, want no rule weights adjusted for a model", res.Adjusted)
+	}
+}
@@ -0,0 +1,199 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers/legacy"
+
+	"github.com/muliwe/go-client-classifier/api"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+func newTestValidator(t *testing.T) *server.RequestValidator {
+	t.Helper()
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("api.Load() error = %v", err)
+	}
+	v, err := server.NewRequestValidator(doc)
+	if err != nil {
+		t.Fatalf("NewRequestValidator() error = %v", err)
+	}
+	return v
+}
+
+func TestOpenAPILoad(t *testing.T) {
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("api.Load() error = %v", err)
+	}
+
+	for _, path := range []string{"/", "/classify", "/classify/fingerprint", "/stats", "/crawlers", "/explain/{request_id}", "/health", "/debug", "/stream", "/openapi.yaml"} {
+		if doc.Paths.Find(path) == nil {
+			t.Errorf("spec is missing path %s", path)
+		}
+	}
+}
+
+func TestRequestValidator_RejectsMalformedPayloads(t *testing.T) {
+	h := createTestHandler()
+	handler := newTestValidator(t).Wrap(h.HandleClassifyRequest)
+
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"relative path", `{"path":"products"}`},
+		{"unknown proto", `{"proto":"SPDY/3"}`},
+		{"lowercase method", `{"method":"get"}`},
+		{"headers not arrays", `{"headers":{"User-Agent":"curl"}}`},
+		{"not an object", `[1,2,3]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/classify", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+
+			handler(w, req)
+
+			if w.Code != http.StatusBadRequest {
+				t.Fatalf("status = %d, want %d", w.Code, http.StatusBadRequest)
+			}
+			var resp server.Problem
+			if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+				t.Fatalf("Failed to decode error: %v", err)
+			}
+			if resp.Code != server.CodeValidationFailed {
+				t.Errorf("code = %q, want %q", resp.Code, server.CodeValidationFailed)
+			}
+			if len(resp.Errors) == 0 {
+				t.Error("problem errors should not be empty")
+			}
+		})
+	}
+}
+
+func TestRequestValidator_RejectsOversizedBody(t *testing.T) {
+	h := createTestHandler()
+	handler := newTestValidator(t).Wrap(h.HandleClassifyRequest)
+
+	body := `{"method":"GET","path":"/","headers":{"X-Pad":["` + strings.Repeat("a", 2<<20) + `"]}}`
+	req := httptest.NewRequest("POST", "/classify", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusRequestEntityTooLarge {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusRequestEntityTooLarge, w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), `"payload_too_large"`) {
+		t.Errorf("body = %s, want payload_too_large", w.Body.String())
+	}
+}
+
+func TestRequestValidator_AcceptsValidPayload(t *testing.T) {
+	h := createTestHandler()
+	handler := newTestValidator(t).Wrap(h.HandleClassifyRequest)
+
+	body := `{"method":"GET","proto":"HTTP/2.0","path":"/a?b=c","headers":{"User-Agent":["curl/8.0"]},"remote_addr":"192.0.2.1:1234"}`
+	req := httptest.NewRequest("POST", "/classify", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestRequestValidator_AcceptsCollectedFingerprint(t *testing.T) {
+	h := createTestHandler()
+	handler := newTestValidator(t).Wrap(h.HandleClassifyFingerprint)
+
+	// A fingerprint as returned by /debug must round-trip through validation
+	debugReq := httptest.NewRequest("GET", "/debug", nil)
+	debugReq.Header.Set("User-Agent", "Mozilla/5.0 Chrome/120")
+	dw := httptest.NewRecorder()
+	h.HandleDebug(dw, debugReq)
+
+	var result struct {
+		Fingerprint json.RawMessage `json:"fingerprint"`
+	}
+	if err := json.NewDecoder(dw.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode debug response: %v", err)
+	}
+
+	req := httptest.NewRequest("POST", "/classify/fingerprint", strings.NewReader(string(result.Fingerprint)))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	handler(w, req)
+
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body.String())
+	}
+}
+
+func TestOpenAPI_ResponsesMatchSpec(t *testing.T) {
+	doc, err := api.Load()
+	if err != nil {
+		t.Fatalf("api.Load() error = %v", err)
+	}
+	doc.Servers = nil
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		t.Fatalf("NewRouter() error = %v", err)
+	}
+
+	h := createTestHandler()
+	h.SetQuiet(true)
+
+	tests := []struct {
+		name    string
+		req     *http.Request
+		handler http.HandlerFunc
+	}{
+		{"classify self", httptest.NewRequest("GET", "/", nil), h.HandleClassify},
+		{"health", httptest.NewRequest("GET", "/health", nil), h.HandleHealth},
+		{"version", httptest.NewRequest("GET", "/version", nil), h.HandleVersion},
+		{"stats", httptest.NewRequest("GET", "/stats", nil), h.HandleStats},
+		{"debug", httptest.NewRequest("GET", "/debug", nil), h.HandleDebug},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			tt.handler(w, tt.req)
+
+			route, params, err := router.FindRoute(tt.req)
+			if err != nil {
+				t.Fatalf("FindRoute() error = %v", err)
+			}
+			resp := w.Result()
+			input := &openapi3filter.ResponseValidationInput{
+				RequestValidationInput: &openapi3filter.RequestValidationInput{
+					Request:    tt.req,
+					PathParams: params,
+					Route:      route,
+				},
+				Status: resp.StatusCode,
+				Header: resp.Header,
+				Body:   io.NopCloser(resp.Body),
+			}
+			if err := openapi3filter.ValidateResponse(context.Background(), input); err != nil {
+				t.Errorf("response does not match spec: %v", err)
+			}
+		})
+	}
+}
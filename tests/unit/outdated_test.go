@@ -0,0 +1,76 @@
+package unit
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
+)
+
+func TestExtractSignals_OutdatedBrowser(t *testing.T) {
+	tests := []struct {
+		name, ua string
+		outdated bool
+	}{
+		{"current chrome", chromeUA, false},
+		{"chrome 120", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36", false},
+		{"chrome 72", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/72.0.3626.121 Safari/537.36", true},
+		{"edge 85", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/85.0.4183.102 Safari/537.36 Edg/85.0.564.51", true},
+		{"firefox 68", "Mozilla/5.0 (X11; Linux x86_64; rv:68.0) Gecko/20100101 Firefox/68.0", true},
+		{"future chrome", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/190.0.0.0 Safari/537.36", false},
+		{"safari 13", "Mozilla/5.0 (iPhone; CPU iPhone OS 13_7 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/13.1.2 Mobile/15E148 Safari/604.1", false},
+		{"crawler on old chrome", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html) Chrome/60.0.3112.90 Safari/537.36", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: tt.ua}})
+			if s.OutdatedBrowser != tt.outdated {
+				t.Errorf("OutdatedBrowser = %v (lag %d), want %v", s.OutdatedBrowser, s.BrowserVersionLag, tt.outdated)
+			}
+			if scored := strings.Contains(s.ScoreBreakdown.String(), "outdated-browser(+2)"); scored != tt.outdated {
+				t.Errorf("outdated-browser scored = %v: %s", scored, s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestExtractSignals_OutdatedBrowser_MaxBrowserLag(t *testing.T) {
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+		UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0.0.0 Safari/537.36",
+	}}
+	rules := fingerprint.DefaultRules()
+	rules.MaxBrowserLag = 6
+	s := fingerprint.ExtractSignalsWithRules(fp, rules)
+	if !s.OutdatedBrowser || s.BrowserVersionLag <= 6 {
+		t.Fatalf("OutdatedBrowser = %v, lag %d with MaxBrowserLag 6", s.OutdatedBrowser, s.BrowserVersionLag)
+	}
+	if rules.Clone().MaxBrowserLag != 6 {
+		t.Error("Clone() drops MaxBrowserLag")
+	}
+
+	res := classifier.New(classifier.WithRules(rules)).Classify(fp)
+	if want := "browser " + strconv.Itoa(s.BrowserVersionLag) + " major releases out of date"; !strings.Contains(res.Reason, want) {
+		t.Errorf("reason %q lacks %q", res.Reason, want)
+	}
+}
+
+func TestRuleset_MaxBrowserLag(t *testing.T) {
+	rs, err := ruleset.Parse([]byte("policy:\n  max_browser_lag: 6\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if issues := rs.Lint(); len(issues) != 0 {
+		t.Errorf("Lint() = %v, want no issues", issues)
+	}
+	if rs.Rules().MaxBrowserLag != 6 {
+		t.Errorf("Rules().MaxBrowserLag = %d, want 6", rs.Rules().MaxBrowserLag)
+	}
+
+	rs, _ = ruleset.Parse([]byte("policy:\n  max_browser_lag: -1\n"))
+	if issues := rs.Lint(); !ruleset.HasErrors(issues) {
+		t.Errorf("Lint() of a negative lag = %v, want an error", issues)
+	}
+}
@@ -0,0 +1,231 @@
+package unit
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/binary"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/pcap"
+)
+
+// captureClientHello returns the first TLS record a Go client sends
+func captureClientHello(t *testing.T, serverName string) []byte {
+	t.Helper()
+	client, server := net.Pipe()
+	defer func() { _ = server.Close() }()
+
+	go func() {
+		conn := tls.Client(client, &tls.Config{ServerName: serverName, NextProtos: []string{"h2", "http/1.1"}})
+		_ = conn.Handshake()
+		_ = client.Close()
+	}()
+
+	buf := make([]byte, 0, 4096)
+	tmp := make([]byte, 4096)
+	_ = server.SetReadDeadline(time.Now().Add(2 * time.Second))
+	for len(buf) < 5 || len(buf) < 5+int(binary.BigEndian.Uint16(buf[3:5])) {
+		n, err := server.Read(tmp)
+		if err != nil {
+			t.Fatalf("reading ClientHello: %v", err)
+		}
+		buf = append(buf, tmp[:n]...)
+	}
+	return buf[:5+int(binary.BigEndian.Uint16(buf[3:5]))]
+}
+
+// tcpFrame builds an Ethernet/IPv4/TCP frame
+func tcpFrame(src, dst string, sport, dport uint16, seq uint32, flags uint8, payload []byte) []byte {
+	tcp := make([]byte, 20, 20+len(payload))
+	binary.BigEndian.PutUint16(tcp[0:2], sport)
+	binary.BigEndian.PutUint16(tcp[2:4], dport)
+	binary.BigEndian.PutUint32(tcp[4:8], seq)
+	tcp[12] = 5 << 4
+	tcp[13] = flags
+	tcp = append(tcp, payload...)
+
+	ip := make([]byte, 20, 20+len(tcp))
+	ip[0] = 0x45
+	binary.BigEndian.PutUint16(ip[2:4], uint16(20+len(tcp)))
+	ip[8] = 64
+	ip[9] = 6
+	copy(ip[12:16], net.ParseIP(src).To4())
+	copy(ip[16:20], net.ParseIP(dst).To4())
+	ip = append(ip, tcp...)
+
+	eth := make([]byte, 14, 14+len(ip))
+	binary.BigEndian.PutUint16(eth[12:14], 0x0800)
+	return append(eth, ip...)
+}
+
+// writePcap encodes frames as a classic little-endian microsecond pcap
+func writePcap(frames [][]byte, start time.Time) []byte {
+	var buf bytes.Buffer
+	hdr := make([]byte, 24)
+	binary.LittleEndian.PutUint32(hdr[0:4], 0xa1b2c3d4)
+	binary.LittleEndian.PutUint16(hdr[4:6], 2)
+	binary.LittleEndian.PutUint16(hdr[6:8], 4)
+	binary.LittleEndian.PutUint32(hdr[16:20], 65535)
+	binary.LittleEndian.PutUint32(hdr[20:24], pcap.LinkTypeEthernet)
+	buf.Write(hdr)
+
+	for i, f := range frames {
+		ts := start.Add(time.Duration(i) * time.Millisecond)
+		rec := make([]byte, 16)
+		binary.LittleEndian.PutUint32(rec[0:4], uint32(ts.Unix()))
+		binary.LittleEndian.PutUint32(rec[4:8], uint32(ts.Nanosecond()/1000))
+		binary.LittleEndian.PutUint32(rec[8:12], uint32(len(f)))
+		binary.LittleEndian.PutUint32(rec[12:16], uint32(len(f)))
+		buf.Write(rec)
+		buf.Write(f)
+	}
+	return buf.Bytes()
+}
+
+// writePcapNG encodes frames as a little-endian pcapng section
+func writePcapNG(frames [][]byte, start time.Time) []byte {
+	var buf bytes.Buffer
+	block := func(typ uint32, body []byte) {
+		for len(body)%4 != 0 {
+			body = append(body, 0)
+		}
+		total := uint32(12 + len(body))
+		_ = binary.Write(&buf, binary.LittleEndian, typ)
+		_ = binary.Write(&buf, binary.LittleEndian, total)
+		buf.Write(body)
+		_ = binary.Write(&buf, binary.LittleEndian, total)
+	}
+
+	shb := make([]byte, 16)
+	binary.LittleEndian.PutUint32(shb[0:4], 0x1a2b3c4d)
+	binary.LittleEndian.PutUint16(shb[4:6], 1)
+	binary.LittleEndian.PutUint64(shb[8:16], ^uint64(0))
+	block(0x0a0d0d0a, shb)
+
+	idb := make([]byte, 8)
+	binary.LittleEndian.PutUint16(idb[0:2], pcap.LinkTypeEthernet)
+	block(1, idb)
+
+	for i, f := range frames {
+		ts := uint64(start.Add(time.Duration(i) * time.Millisecond).UnixMicro())
+		epb := make([]byte, 20, 20+len(f))
+		binary.LittleEndian.PutUint32(epb[4:8], uint32(ts>>32))
+		binary.LittleEndian.PutUint32(epb[8:12], uint32(ts))
+		binary.LittleEndian.PutUint32(epb[12:16], uint32(len(f)))
+		binary.LittleEndian.PutUint32(epb[16:20], uint32(len(f)))
+		block(6, append(epb, f...))
+	}
+	return buf.Bytes()
+}
+
+func pcapFrames(t *testing.T) [][]byte {
+	hello := captureClientHello(t, "Example.com")
+	req1 := []byte("GET /index.html HTTP/1.1\r\nHost: example.com\r\nUser-Agent: curl/8.0.1\r\nAccept: */*\r\n\r\n")
+	req2 := []byte("POST /api HTTP/1.1\r\nHost: example.com\r\nUser-Agent: python-requests/2.31.0\r\nContent-Length: 4\r\n\r\nbody")
+
+	const c, s = "10.0.0.2", "10.0.0.1"
+	return [][]byte{
+		// TLS connection: a Windows SYN, then the ClientHello split in two
+		// segments
+		append(tcpFrame(c, s, 0, 0, 0, 0, nil)[:14], synPacket(c, s, 50000, 443, 128, 64240, windowsSYNOptions)...),
+		tcpFrame(c, s, 50000, 443, 1001, 0x18, hello[:100]),
+		tcpFrame(c, s, 50000, 443, 1001+100, 0x18, hello[100:]),
+		// The ServerHello in the other direction is paired with it, and
+		// the client answers 1ms later
+		tcpFrame(s, c, 443, 50000, 9000, 0x18, serverHelloRecord(make([]byte, 32), 0x1301,
+			extension(0x0033, make([]byte, 36)...), extension(0x002b, 0x03, 0x04))),
+		tcpFrame(c, s, 50000, 443, 1001+uint32(len(hello)), 0x18, []byte{0x14, 0x03, 0x03, 0x00, 0x01, 0x01}),
+
+		// Plaintext keep-alive connection without a captured SYN: second
+		// segment arrives first and the first one is retransmitted
+		tcpFrame(c, s, 50001, 80, 5000+uint32(len(req1)), 0x18, req2),
+		tcpFrame(c, s, 50001, 80, 5000, 0x18, req1),
+		tcpFrame(c, s, 50001, 80, 5000, 0x18, req1),
+		tcpFrame(s, c, 80, 50001, 7000, 0x18, []byte("HTTP/1.1 200 OK\r\nContent-Length: 0\r\n\r\n")),
+	}
+}
+
+func checkPcapRecords(t *testing.T, records []pcap.Record, stats pcap.Stats) {
+	t.Helper()
+	if stats.ClientHellos != 1 || stats.HTTPRequests != 2 || stats.Malformed != 0 {
+		t.Fatalf("stats = %+v, want 1 ClientHello, 2 HTTP requests", stats)
+	}
+	if len(records) != 3 {
+		t.Fatalf("got %d records, want 3", len(records))
+	}
+
+	tlsRec := records[0]
+	if tlsRec.Kind != pcap.KindTLS || tlsRec.Client != "10.0.0.2:50000" || tlsRec.Server != "10.0.0.1:443" {
+		t.Errorf("TLS record = %+v", tlsRec)
+	}
+	if tlsRec.TLS.JA3Hash == "" || !strings.HasPrefix(tlsRec.TLS.JA4Hash, "t13d") {
+		t.Errorf("missing JA3/JA4: %q %q", tlsRec.TLS.JA3Hash, tlsRec.TLS.JA4Hash)
+	}
+	if tlsRec.TLS.JA4SHash != "t130200_1301_234ea6891581" || tlsRec.TLS.JA3SHash == "" {
+		t.Errorf("JA3S/JA4S of the ServerHello = %q %q", tlsRec.TLS.JA3SHash, tlsRec.TLS.JA4SHash)
+	}
+	if tlsRec.TCP.JA4T != "64240_2-1-3-1-1-4_1460_8" {
+		t.Errorf("JA4T of the SYN = %q", tlsRec.TCP.JA4T)
+	}
+	if tlsRec.TLS.LatencyMicros != 500 || tlsRec.TLS.JA4L != "500_128" {
+		t.Errorf("latency = %d, JA4L = %q, want 500_128", tlsRec.TLS.LatencyMicros, tlsRec.TLS.JA4L)
+	}
+	if tlsRec.TLS.ServerName != "example.com" || tlsRec.TLS.ALPN != "h2" || !tlsRec.TLS.Available {
+		t.Errorf("TLS fields = %+v", tlsRec.TLS)
+	}
+
+	get, post := records[1], records[2]
+	if get.Kind != pcap.KindHTTP || get.HTTP.UserAgent != "curl/8.0.1" || get.HTTP.Path != "/index.html" {
+		t.Errorf("first HTTP record = %+v", get.HTTP)
+	}
+	if !strings.HasPrefix(get.HTTP.JA4HHash, "ge11") {
+		t.Errorf("JA4H = %q, want ge11 prefix", get.HTTP.JA4HHash)
+	}
+	if want := []string{"Host", "User-Agent", "Accept"}; !slices.Equal(get.HTTP.RawHeaderNames, want) {
+		t.Errorf("RawHeaderNames = %v, want %v", get.HTTP.RawHeaderNames, want)
+	}
+	if get.TCP.Available {
+		t.Errorf("TCP = %+v, want none without a captured SYN", get.TCP)
+	}
+	if post.HTTP.Method != "POST" || post.HTTP.UserAgent != "python-requests/2.31.0" {
+		t.Errorf("second HTTP record = %+v", post.HTTP)
+	}
+	if post.Timestamp.Before(get.Timestamp) {
+		t.Errorf("second request should not be timestamped before the first: %v < %v", post.Timestamp, get.Timestamp)
+	}
+}
+
+func TestPcapExtract_Classic(t *testing.T) {
+	data := writePcap(pcapFrames(t), time.Unix(1700000000, 0))
+	records, stats, err := pcap.Extract(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if stats.Packets != 9 {
+		t.Errorf("Packets = %d, want 9", stats.Packets)
+	}
+	checkPcapRecords(t, records, stats)
+	if !records[0].Timestamp.Equal(time.Unix(1700000000, 1e6)) {
+		t.Errorf("TLS timestamp = %v, want first payload packet time", records[0].Timestamp)
+	}
+}
+
+func TestPcapExtract_PcapNG(t *testing.T) {
+	data := writePcapNG(pcapFrames(t), time.Unix(1700000000, 0))
+	records, stats, err := pcap.Extract(bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	checkPcapRecords(t, records, stats)
+}
+
+func TestPcapExtract_NotACapture(t *testing.T) {
+	_, _, err := pcap.Extract(strings.NewReader("GET / HTTP/1.1\r\n\r\n and more bytes"))
+	if err == nil {
+		t.Fatal("Extract() should reject non-capture input")
+	}
+}
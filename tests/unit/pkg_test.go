@@ -0,0 +1,47 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/pkg/classifier"
+	"github.com/muliwe/go-client-classifier/pkg/fingerprint"
+)
+
+func TestPublicAPI_ClassifyRequest(t *testing.T) {
+	collector := fingerprint.NewCollector()
+	c := classifier.New(classifier.DefaultConfig())
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", "curl/8.0.1")
+	req.Header.Set("Accept", "*/*")
+
+	fp := collector.Collect(req)
+	result := c.Classify(fp)
+
+	if result.Classification != classifier.ClassificationBot {
+		t.Errorf("Classify(curl) = %s, want %s", result.Classification, classifier.ClassificationBot)
+	}
+	if fp.HTTP.JA4HHash != fingerprint.JA4H(req) {
+		t.Errorf("Collect() JA4H = %q, want %q", fp.HTTP.JA4HHash, fingerprint.JA4H(req))
+	}
+}
+
+func TestPublicAPI_ExtractSignals(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "python-requests/2.31.0",
+			HeaderCount: 3,
+		},
+	}
+
+	s := fingerprint.ExtractSignals(fp)
+
+	if !s.UserAgentIsBot {
+		t.Error("python-requests should be detected as bot")
+	}
+	if s.BotScore <= s.BrowserScore {
+		t.Errorf("BotScore = %d, BrowserScore = %d, want bot-leaning", s.BotScore, s.BrowserScore)
+	}
+}
@@ -0,0 +1,167 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/privaterelay"
+)
+
+var _ classifier.Enricher = (*privaterelay.Ranges)(nil)
+
+const testRelayCSV = `172.224.226.0/27,GB,GB-EN,London,
+172.224.226.0/24,GB,GB-EN,,
+2a02:26f7:b3c0:4000::/64,DE,DE-BE,Berlin,
+104.28.0.0/16,US,US-CA,Los Angeles
+`
+
+func loadTestRelay(t *testing.T) *privaterelay.Ranges {
+	t.Helper()
+	rg, err := privaterelay.Parse(strings.NewReader(testRelayCSV))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	return rg
+}
+
+func TestPrivateRelay_Lookup(t *testing.T) {
+	rg := loadTestRelay(t)
+	if rg.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", rg.Len())
+	}
+
+	tests := []struct {
+		addr   string
+		want   bool
+		prefix string
+		city   string
+	}{
+		{"172.224.226.5", true, "172.224.226.0/27", "London"},
+		{"172.224.226.200", true, "172.224.226.0/24", ""},
+		{"::ffff:172.224.226.5", true, "172.224.226.0/27", "London"},
+		{"2a02:26f7:b3c0:4000::1", true, "2a02:26f7:b3c0:4000::/64", "Berlin"},
+		{"104.28.12.34", true, "104.28.0.0/16", "Los Angeles"},
+		{"2a02:26f7:b3c0:4001::1", false, "", ""},
+		{"8.8.8.8", false, "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.addr, func(t *testing.T) {
+			e, ok := rg.Lookup(netip.MustParseAddr(tt.addr))
+			if ok != tt.want {
+				t.Fatalf("Lookup() ok = %v, want %v", ok, tt.want)
+			}
+			if ok && (e.Prefix.String() != tt.prefix || e.City != tt.city) {
+				t.Errorf("Lookup() = %s %q, want %s %q", e.Prefix, e.City, tt.prefix, tt.city)
+			}
+		})
+	}
+}
+
+func TestPrivateRelay_ParseErrors(t *testing.T) {
+	for _, in := range []string{"", "not-a-prefix,US,,", "\n\n"} {
+		if _, err := privaterelay.Parse(strings.NewReader(in)); err == nil {
+			t.Errorf("Parse(%q) should fail", in)
+		}
+	}
+}
+
+func TestPrivateRelay_Enrich(t *testing.T) {
+	rg := loadTestRelay(t)
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.RemoteAddr = "172.224.226.9:51234"
+	fp := fingerprint.NewCollector().Collect(r)
+	if err := rg.Enrich(context.Background(), r, &fp); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if !fp.Network.PrivateRelay || fp.Network.RelayCountry != "GB" {
+		t.Errorf("Network = %+v, want relay in GB", fp.Network)
+	}
+	if !fingerprint.ExtractSignals(fp).FromPrivateRelay {
+		t.Error("FromPrivateRelay should be set")
+	}
+
+	r.RemoteAddr = "203.0.113.7:443"
+	fp = fingerprint.NewCollector().Collect(r)
+	_ = rg.Enrich(context.Background(), r, &fp)
+	if fp.Network.PrivateRelay {
+		t.Error("non-relay address marked as relay")
+	}
+}
+
+func TestHandler_PrivateRelay(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetPrivateRelay(loadTestRelay(t))
+
+	req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+	req.RemoteAddr = "[2a02:26f7:b3c0:4000::42]:443"
+	rr := httptest.NewRecorder()
+	h.HandleDebug(rr, req)
+
+	var result fingerprint.ClassificationResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if !result.Fingerprint.Network.PrivateRelay || !result.Signals.FromPrivateRelay {
+		t.Errorf("debug result not marked as Private Relay: %+v", result.Fingerprint.Network)
+	}
+}
+
+func TestPrivateRelay_Fetch(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/egress-ip-ranges.csv" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte(testRelayCSV))
+	}))
+	defer srv.Close()
+
+	rg, err := privaterelay.Fetch(context.Background(), srv.URL+"/egress-ip-ranges.csv")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if rg.Len() != 4 {
+		t.Errorf("Len() = %d, want 4", rg.Len())
+	}
+	if _, err := privaterelay.Fetch(context.Background(), srv.URL+"/missing.csv"); err == nil {
+		t.Error("Fetch() of a missing URL succeeded")
+	}
+}
+
+func TestPrivateRelay_NotPenalizedAsDatacenter(t *testing.T) {
+	// Relay egress sits in hosting networks that may also be on VPN lists
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{UserAgent: "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15"},
+		Network: fingerprint.NetworkFingerprint{
+			PrivateRelay: true,
+			RelayCountry: "GB",
+			ASN:          36183,
+			Hosting:      "Akamai",
+			Anonymizer:   "vpn",
+		},
+	}
+	res := classifier.New().Classify(fp)
+	if !res.Signals.FromPrivateRelay || res.Signals.DatacenterASN || res.Signals.AnonymizedNetwork {
+		t.Errorf("FromPrivateRelay = %v, DatacenterASN = %v, AnonymizedNetwork = %v",
+			res.Signals.FromPrivateRelay, res.Signals.DatacenterASN, res.Signals.AnonymizedNetwork)
+	}
+	for _, rule := range []string{"datacenter", "anonymized-network"} {
+		if strings.Contains(res.Signals.ScoreBreakdown.String(), rule) {
+			t.Errorf("relay traffic scored %s: %s", rule, res.Signals.ScoreBreakdown)
+		}
+	}
+
+	fp.Network.PrivateRelay = false
+	if s := fingerprint.ExtractSignals(fp); !s.DatacenterASN || !s.AnonymizedNetwork {
+		t.Errorf("without relay: DatacenterASN = %v, AnonymizedNetwork = %v", s.DatacenterASN, s.AnonymizedNetwork)
+	}
+}
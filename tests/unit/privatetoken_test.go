@@ -0,0 +1,267 @@
+package unit
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/privatetoken"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+const testIssuerName = "issuer.example.com"
+
+var _ classifier.Enricher = (*privatetoken.Verifier)(nil)
+
+var (
+	testIssuerOnce sync.Once
+	testIssuerKey  *rsa.PrivateKey
+	testIssuer     privatetoken.Issuer
+)
+
+// newTestIssuer returns a shared issuer key (RSA key generation is slow)
+func newTestIssuer(t *testing.T) (*rsa.PrivateKey, privatetoken.Issuer) {
+	t.Helper()
+	testIssuerOnce.Do(func() {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("GenerateKey() error = %v", err)
+		}
+		der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+		if err != nil {
+			t.Fatalf("MarshalPKIXPublicKey() error = %v", err)
+		}
+		iss, err := privatetoken.NewIssuer(testIssuerName, der)
+		if err != nil {
+			t.Fatalf("NewIssuer() error = %v", err)
+		}
+		testIssuerKey, testIssuer = key, iss
+	})
+	if testIssuerKey == nil {
+		t.Fatal("test issuer unavailable")
+	}
+	return testIssuerKey, testIssuer
+}
+
+// issueToken signs a token for the challenge the way a blind RSA issuer's
+// finalized signature verifies: RSA-PSS with SHA-384 and a 48-byte salt
+func issueToken(t *testing.T, key *rsa.PrivateKey, iss privatetoken.Issuer, c privatetoken.Challenge) privatetoken.Token {
+	t.Helper()
+	tok := privatetoken.Token{
+		TokenType:       privatetoken.TypeBlindRSA,
+		ChallengeDigest: c.Digest(),
+		TokenKeyID:      iss.KeyID,
+	}
+	if _, err := rand.Read(tok.Nonce[:]); err != nil {
+		t.Fatal(err)
+	}
+	input := tok.Marshal()
+	digest := sha512.Sum384(input)
+	sig, err := rsa.SignPSS(rand.Reader, key, crypto.SHA384, digest[:], &rsa.PSSOptions{SaltLength: 48})
+	if err != nil {
+		t.Fatalf("SignPSS() error = %v", err)
+	}
+	tok.Authenticator = sig
+	return tok
+}
+
+func newTestVerifier(t *testing.T, origins ...string) *privatetoken.Verifier {
+	t.Helper()
+	_, iss := newTestIssuer(t)
+	v, err := privatetoken.NewVerifier(privatetoken.Config{Issuers: []privatetoken.Issuer{iss}, Origins: origins})
+	if err != nil {
+		t.Fatalf("NewVerifier() error = %v", err)
+	}
+	return v
+}
+
+func TestPrivateToken_ChallengeMarshal(t *testing.T) {
+	c := privatetoken.Challenge{
+		TokenType:  privatetoken.TypeBlindRSA,
+		IssuerName: "a.example",
+		OriginInfo: []string{"b.example", "c.example"},
+	}
+	b := c.Marshal()
+
+	if got := binary.BigEndian.Uint16(b); got != 0x0002 {
+		t.Errorf("token_type = %#04x, want 0x0002", got)
+	}
+	if n := binary.BigEndian.Uint16(b[2:]); n != 9 || string(b[4:13]) != "a.example" {
+		t.Errorf("issuer_name = %q (len %d)", b[4:4+n], n)
+	}
+	if b[13] != 0 {
+		t.Errorf("redemption_context length = %d, want 0", b[13])
+	}
+	if n := binary.BigEndian.Uint16(b[14:]); string(b[16:16+n]) != "b.example,c.example" {
+		t.Errorf("origin_info = %q", b[16:16+n])
+	}
+}
+
+func TestPrivateToken_Verify(t *testing.T) {
+	key, iss := newTestIssuer(t)
+	v := newTestVerifier(t, "origin.example")
+	challenge := privatetoken.Challenge{TokenType: privatetoken.TypeBlindRSA, IssuerName: testIssuerName, OriginInfo: []string{"origin.example"}}
+
+	tok := issueToken(t, key, iss, challenge)
+	if err := v.Verify(tok); err != nil {
+		t.Fatalf("Verify() error = %v", err)
+	}
+	if err := v.Verify(tok); !errors.Is(err, privatetoken.ErrReplayed) {
+		t.Errorf("Verify() replay error = %v, want ErrReplayed", err)
+	}
+
+	other := privatetoken.Challenge{TokenType: privatetoken.TypeBlindRSA, IssuerName: testIssuerName, OriginInfo: []string{"other.example"}}
+	if err := v.Verify(issueToken(t, key, iss, other)); !errors.Is(err, privatetoken.ErrUnknownChallenge) {
+		t.Errorf("Verify() other origin error = %v, want ErrUnknownChallenge", err)
+	}
+
+	forged := issueToken(t, key, iss, challenge)
+	forged.Authenticator[0] ^= 0xff
+	if err := v.Verify(forged); !errors.Is(err, privatetoken.ErrBadSignature) {
+		t.Errorf("Verify() forged error = %v, want ErrBadSignature", err)
+	}
+
+	unknown := issueToken(t, key, iss, challenge)
+	unknown.TokenKeyID[0] ^= 0xff
+	if err := v.Verify(unknown); !errors.Is(err, privatetoken.ErrUnknownKey) {
+		t.Errorf("Verify() unknown key error = %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestPrivateToken_Authorization(t *testing.T) {
+	key, iss := newTestIssuer(t)
+	tok := issueToken(t, key, iss, privatetoken.Challenge{TokenType: privatetoken.TypeBlindRSA, IssuerName: testIssuerName})
+	raw := base64.RawURLEncoding.EncodeToString(tok.Marshal())
+
+	tests := []struct {
+		name    string
+		header  string
+		ok      bool
+		wantErr bool
+	}{
+		{"quoted", tok.Authorization(), true, false},
+		{"unpadded lowercase scheme", "privatetoken token=" + raw, true, false},
+		{"other scheme", "Bearer abc", false, false},
+		{"empty", "", false, false},
+		{"missing token", `PrivateToken foo="bar"`, true, true},
+		{"bad encoding", `PrivateToken token="!!!"`, true, true},
+		{"wrong type", `PrivateToken token="AAEA"`, true, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok, err := privatetoken.TokenFromAuthorization(tt.header)
+			if ok != tt.ok || (err != nil) != tt.wantErr {
+				t.Fatalf("TokenFromAuthorization() ok = %v, err = %v", ok, err)
+			}
+			if ok && !tt.wantErr && got.Nonce != tok.Nonce {
+				t.Error("TokenFromAuthorization() nonce mismatch")
+			}
+		})
+	}
+}
+
+func TestPrivateToken_ReadDirectory(t *testing.T) {
+	_, iss := newTestIssuer(t)
+	key := base64.URLEncoding.EncodeToString(iss.TokenKey)
+	dir := `{"issuer-request-uri":"https://issuer.example.com/token-request","token-keys":[` +
+		`{"token-type":1,"token-key":"AAAA"},{"token-type":2,"token-key":"` + key + `"}]}`
+
+	issuers, err := privatetoken.ReadDirectory(testIssuerName, strings.NewReader(dir))
+	if err != nil {
+		t.Fatalf("ReadDirectory() error = %v", err)
+	}
+	if len(issuers) != 1 || issuers[0].KeyID != iss.KeyID {
+		t.Errorf("ReadDirectory() = %d issuers, want the type 2 key", len(issuers))
+	}
+
+	if _, err := privatetoken.ReadDirectory(testIssuerName, strings.NewReader(`{"token-keys":[]}`)); err == nil {
+		t.Error("ReadDirectory() should reject a directory without type 2 keys")
+	}
+}
+
+func TestPrivateToken_EnrichAndScore(t *testing.T) {
+	key, iss := newTestIssuer(t)
+	v := newTestVerifier(t)
+	tok := issueToken(t, key, iss, privatetoken.Challenge{TokenType: privatetoken.TypeBlindRSA, IssuerName: testIssuerName})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("Authorization", tok.Authorization())
+	fp := fingerprint.NewCollector().Collect(r)
+	if err := v.Enrich(context.Background(), r, &fp); err != nil {
+		t.Fatalf("Enrich() error = %v", err)
+	}
+	if fp.HTTP.PrivateToken != fingerprint.PrivateTokenValid {
+		t.Fatalf("PrivateToken = %q, want valid", fp.HTTP.PrivateToken)
+	}
+
+	s := fingerprint.ExtractSignals(fp)
+	if !s.HasValidPrivateToken || !strings.Contains(s.ScoreBreakdown.String(), "private-token") {
+		t.Errorf("signals missing private-token: %s", s.ScoreBreakdown)
+	}
+
+	// Redeeming the same token again is recorded as invalid
+	fp = fingerprint.NewCollector().Collect(r)
+	_ = v.Enrich(context.Background(), r, &fp)
+	if fp.HTTP.PrivateToken != fingerprint.PrivateTokenInvalid {
+		t.Errorf("PrivateToken = %q, want invalid", fp.HTTP.PrivateToken)
+	}
+	if fingerprint.ExtractSignals(fp).HasValidPrivateToken {
+		t.Error("invalid token should not score")
+	}
+}
+
+func TestHandler_PrivateTokenChallenge(t *testing.T) {
+	key, iss := newTestIssuer(t)
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetPrivateTokens(newTestVerifier(t))
+
+	// A bare curl-like request is classified as bot and challenged
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	rr := httptest.NewRecorder()
+	h.HandleClassify(rr, req)
+
+	if rr.Code != http.StatusUnauthorized {
+		t.Fatalf("status = %d, want 401", rr.Code)
+	}
+	if got := rr.Header().Get("WWW-Authenticate"); !strings.HasPrefix(got, `PrivateToken challenge="`) || !strings.Contains(got, "token-key=") {
+		t.Errorf("WWW-Authenticate = %q", got)
+	}
+
+	// A browser-like request redeeming a valid token is not challenged
+	tok := issueToken(t, key, iss, privatetoken.Challenge{TokenType: privatetoken.TypeBlindRSA, IssuerName: testIssuerName})
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("User-Agent", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 Version/17.0 Mobile/15E148 Safari/604.1")
+	req.Header.Set("Accept", "text/html")
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Authorization", tok.Authorization())
+	rr = httptest.NewRecorder()
+	h.HandleClassify(rr, req)
+
+	if rr.Code != http.StatusOK || rr.Header().Get("WWW-Authenticate") != "" {
+		t.Errorf("status = %d, WWW-Authenticate = %q; want 200 without challenge", rr.Code, rr.Header().Get("WWW-Authenticate"))
+	}
+}
+
+func TestServerNew_WithPrivateTokens(t *testing.T) {
+	lc := logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"}
+	srv, err := server.New(server.WithLogger(lc), server.WithPrivateTokens(newTestVerifier(t)))
+	if err != nil || srv == nil {
+		t.Fatalf("New() error = %v", err)
+	}
+}
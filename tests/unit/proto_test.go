@@ -0,0 +1,119 @@
+package unit
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/pkg/pb/classifierv1"
+)
+
+// fillStruct sets every field of v (a pointer to struct) to a non-zero value
+func fillStruct(t *testing.T, v any) {
+	t.Helper()
+	rv := reflect.ValueOf(v).Elem()
+	for i := 0; i < rv.NumField(); i++ {
+		f := rv.Field(i)
+		switch f.Kind() {
+		case reflect.Bool:
+			f.SetBool(true)
+		case reflect.Int, reflect.Int64:
+			f.SetInt(int64(i + 1))
+		case reflect.Float64:
+			f.SetFloat(float64(i) + 0.5)
+		case reflect.String:
+			f.SetString(rv.Type().Field(i).Name)
+		case reflect.Slice:
+			if f.Type() == reflect.TypeFor[fingerprint.Breakdown]() {
+				f.Set(reflect.ValueOf(fingerprint.Breakdown{{Name: "rule", Direction: fingerprint.DirectionBot, Weight: i + 1}}))
+				continue
+			}
+			if f.Type() == reflect.TypeFor[[]int]() {
+				f.Set(reflect.ValueOf([]int{i + 1}))
+				continue
+			}
+			f.Set(reflect.ValueOf([]string{rv.Type().Field(i).Name}))
+		case reflect.Map:
+			f.Set(reflect.ValueOf(map[string]string{"k": rv.Type().Field(i).Name}))
+		case reflect.Struct:
+			// Nested structs are filled separately
+		default:
+			t.Fatalf("unhandled field kind %s for %s", f.Kind(), rv.Type().Field(i).Name)
+		}
+	}
+}
+
+func TestProto_SignalsRoundTripAllFields(t *testing.T) {
+	var s fingerprint.Signals
+	fillStruct(t, &s)
+
+	got := classifierv1.ToSignals(classifierv1.FromSignals(s))
+	if !reflect.DeepEqual(got, s) {
+		t.Errorf("Signals round trip mismatch:\n got  %+v\n want %+v", got, s)
+	}
+}
+
+func TestProto_FingerprintRoundTripAllFields(t *testing.T) {
+	var fp fingerprint.Fingerprint
+	fillStruct(t, &fp.TLS)
+	fillStruct(t, &fp.HTTP)
+	fillStruct(t, &fp.Session)
+	fillStruct(t, &fp.Rate)
+	fillStruct(t, &fp.Offender)
+	fillStruct(t, &fp.Network)
+
+	got := classifierv1.ToFingerprint(classifierv1.FromFingerprint(fp))
+	if !reflect.DeepEqual(got, fp) {
+		t.Errorf("Fingerprint round trip mismatch:\n got  %+v\n want %+v", got, fp)
+	}
+}
+
+func TestProto_ResultWireRoundTrip(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			Method:      "GET",
+			UserAgent:   "curl/8.0.1",
+			Headers:     map[string]string{"user-agent": "curl/8.0.1"},
+			HeaderOrder: []string{"user-agent"},
+			HeaderCount: 1,
+		},
+	}
+	result := classifier.New(classifier.DefaultConfig()).Classify(fp)
+	result.Timestamp = time.Date(2026, 3, 1, 12, 0, 0, 123000000, time.UTC)
+
+	data, err := proto.Marshal(classifierv1.FromResult(result))
+	if err != nil {
+		t.Fatalf("proto.Marshal() error = %v", err)
+	}
+
+	var decoded classifierv1.ClassificationResult
+	if err := proto.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("proto.Unmarshal() error = %v", err)
+	}
+
+	got := classifierv1.ToResult(&decoded)
+	if !reflect.DeepEqual(got, result) {
+		t.Errorf("ClassificationResult round trip mismatch:\n got  %+v\n want %+v", got, result)
+	}
+}
+
+func TestProto_ToResultNil(t *testing.T) {
+	got := classifierv1.ToResult(nil)
+	if got.Classification != "" || !got.Timestamp.IsZero() {
+		t.Errorf("ToResult(nil) = %+v, want zero value", got)
+	}
+}
+
+func TestProto_ToSignalsTextBreakdown(t *testing.T) {
+	// Senders predating contributions only set the text breakdown
+	got := classifierv1.ToSignals(&classifierv1.Signals{ScoreBreakdown: "BROWSER[] BOT[bot-ua(+3)]"})
+	want := fingerprint.Breakdown{{Name: "bot-ua", Direction: fingerprint.DirectionBot, Weight: 3}}
+	if !reflect.DeepEqual(got.ScoreBreakdown, want) {
+		t.Errorf("ScoreBreakdown = %v, want %v", got.ScoreBreakdown, want)
+	}
+}
@@ -0,0 +1,113 @@
+package unit
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/psanford/tlsfingerprint"
+	"github.com/quic-go/quic-go"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/quichello"
+)
+
+// quicClientHello reads the Initial packets a quic-go client sends to a
+// socket wrapped by a quichello.Recorder until its ClientHello is complete.
+// Nothing answers, so the handshake never finishes.
+func quicClientHello(t *testing.T) (quichello.Hello, net.Addr) {
+	t.Helper()
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = pc.Close() }()
+	rec := quichello.NewRecorder()
+	wrapped := rec.PacketConn(pc)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	go func() {
+		_, _ = quic.DialAddr(ctx, pc.LocalAddr().String(),
+			&tls.Config{ServerName: "localhost", NextProtos: []string{"h3"}}, nil)
+	}()
+
+	buf := make([]byte, 1500)
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		_, addr, err := wrapped.ReadFrom(buf)
+		if err != nil {
+			t.Fatalf("no ClientHello recorded: %v", err)
+		}
+		if rec.Len() == 0 {
+			continue
+		}
+		hello, ok := rec.Take(addr)
+		if !ok {
+			t.Fatalf("Take(%v) found nothing, Len() = %d", addr, rec.Len())
+		}
+		if rec.Len() != 0 {
+			t.Errorf("Len() after Take = %d, want 0", rec.Len())
+		}
+		return hello, addr
+	}
+}
+
+func TestQUICHello_RecordsClientHello(t *testing.T) {
+	hello, addr := quicClientHello(t)
+	if hello.Version != quichello.Version1 {
+		t.Errorf("Version = %#x, want QUIC v1", hello.Version)
+	}
+
+	ch, err := tlsfingerprint.ParseClientHello(hello.Record())
+	if err != nil {
+		t.Fatalf("ParseClientHello() error = %v", err)
+	}
+	if !slices.Contains(ch.Extensions, 57) {
+		t.Errorf("extensions %v lack quic_transport_parameters", ch.Extensions)
+	}
+	if !slices.Equal(ch.ALPNProtocols, []string{"h3"}) {
+		t.Errorf("ALPN = %v, want [h3]", ch.ALPNProtocols)
+	}
+	if got := fingerprint.JA4(ch); !strings.HasPrefix(got, "q13d") {
+		t.Errorf("JA4 = %s, want q13d prefix", got)
+	}
+
+	rec := quichello.NewRecorder()
+	if _, ok := rec.Take(addr); ok {
+		t.Error("Take() on an empty recorder found a ClientHello")
+	}
+}
+
+func TestParseQUICClientHello(t *testing.T) {
+	hello, _ := quicClientHello(t)
+
+	fp, err := fingerprint.ParseQUICClientHello(hello.Record(), hello.Version)
+	if err != nil {
+		t.Fatalf("ParseQUICClientHello() error = %v", err)
+	}
+	if !fp.Available || fp.Version != "v1" {
+		t.Errorf("Available, Version = %t, %q, want true, v1", fp.Available, fp.Version)
+	}
+	if len(fp.TransportParameters) == 0 {
+		t.Fatalf("no transport parameters: %+v", fp)
+	}
+	// quic-go's defaults
+	if fp.MaxIdleTimeout != 30000 {
+		t.Errorf("MaxIdleTimeout = %d, want 30000", fp.MaxIdleTimeout)
+	}
+	if fp.InitialMaxData == 0 || fp.InitialMaxStreamsBidi == 0 {
+		t.Errorf("flow control parameters missing: %+v", fp)
+	}
+}
+
+func TestParseQUICClientHello_TCPClientHello(t *testing.T) {
+	if _, err := fingerprint.ParseQUICClientHello(captureClientHello(t, "example.com"), quichello.Version1); err == nil {
+		t.Error("ParseQUICClientHello() of a TLS-over-TCP ClientHello succeeded")
+	}
+}
@@ -0,0 +1,159 @@
+package unit
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/rate"
+)
+
+func TestRateTracker_SlidingWindow(t *testing.T) {
+	tr := rate.New(rate.DefaultConfig())
+	start := time.Unix(1700000000, 0)
+
+	var r fingerprint.RateFingerprint
+	for i := range 5 {
+		r = tr.Observe("198.51.100.1", "t13d1516h2_8daaf6152771_02713d6af862", start.Add(time.Duration(i)*100*time.Millisecond))
+	}
+	if !r.Available || r.AddrRequests != 5 || r.AddrBurst != 5 || r.JA4Requests != 5 || r.JA4Burst != 5 {
+		t.Errorf("after 5 requests in a second: %+v", r)
+	}
+
+	// The burst resets each second; the window keeps a minute
+	r = tr.Observe("198.51.100.1", "", start.Add(30*time.Second))
+	if r.AddrRequests != 6 || r.AddrBurst != 1 || r.JA4Requests != 0 {
+		t.Errorf("30s later: %+v", r)
+	}
+	r = tr.Observe("198.51.100.1", "", start.Add(61*time.Second))
+	if r.AddrRequests != 2 {
+		t.Errorf("61s later: AddrRequests = %d, want 2 (first second slid out)", r.AddrRequests)
+	}
+	r = tr.Observe("198.51.100.1", "", start.Add(10*time.Minute))
+	if r.AddrRequests != 1 {
+		t.Errorf("after idling: AddrRequests = %d, want 1", r.AddrRequests)
+	}
+
+	// Addresses are counted apart, fingerprints across addresses
+	tr.Observe("203.0.113.1", "ja4-a", start)
+	r = tr.Observe("203.0.113.2", "ja4-a", start)
+	if r.AddrRequests != 1 || r.JA4Requests != 2 {
+		t.Errorf("second address: %+v", r)
+	}
+}
+
+func TestRateTracker_Bounded(t *testing.T) {
+	tr := rate.New(rate.Config{MaxKeys: 100})
+	now := time.Unix(1700000000, 0)
+	for i := range 1000 {
+		tr.Observe(fmt.Sprintf("198.51.%d.%d", i/256, i%256), "", now)
+	}
+	if tr.Len() > 100 {
+		t.Errorf("Len() = %d, want <= 100", tr.Len())
+	}
+
+	tr.SetCapacity(10)
+	if tr.Len() != 10 {
+		t.Errorf("Len() after SetCapacity(10) = %d", tr.Len())
+	}
+	if tr.EntryBytes() <= 0 {
+		t.Error("EntryBytes() should be positive")
+	}
+}
+
+func TestExtractSignals_RequestRate(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{UserAgent: chromeUA},
+		Rate: fingerprint.RateFingerprint{Available: true, AddrRequests: fingerprint.HighAddrRequestRate + 1, AddrBurst: fingerprint.BurstRequests + 1},
+	}
+	s := fingerprint.ExtractSignals(fp)
+	if !s.HighRequestRate || !s.BurstPattern {
+		t.Fatalf("HighRequestRate = %v, BurstPattern = %v", s.HighRequestRate, s.BurstPattern)
+	}
+	for _, rule := range []string{"high-rate(+3)", "burst(+2)"} {
+		if !strings.Contains(s.ScoreBreakdown.String(), rule) {
+			t.Errorf("breakdown lacks %s: %s", rule, s.ScoreBreakdown)
+		}
+	}
+
+	at := fp
+	at.Rate = fingerprint.RateFingerprint{Available: true, AddrRequests: fingerprint.HighAddrRequestRate, AddrBurst: fingerprint.BurstRequests}
+	if s := fingerprint.ExtractSignals(at); s.HighRequestRate || s.BurstPattern {
+		t.Error("rates at the limits flagged")
+	}
+
+	// Addresses shared by many clients are not judged
+	proxied := fp
+	proxied.Network.Local = true
+	relay := fp
+	relay.Network.PrivateRelay = true
+	for name, fp := range map[string]fingerprint.Fingerprint{"load balancer": proxied, "private relay": relay} {
+		if s := fingerprint.ExtractSignals(fp); s.HighRequestRate || s.BurstPattern {
+			t.Errorf("%s: rate signals set", name)
+		}
+	}
+
+	// A fingerprint a whole corporate proxy shares is not judged by its volume
+	chrome := interceptedChrome()
+	chrome.Rate = fingerprint.RateFingerprint{Available: true, JA4Requests: fingerprint.HighJA4RequestRate + 1}
+	if s := fingerprint.ExtractSignals(chrome); !s.TLSIntercepted || s.HighRequestRate {
+		t.Errorf("intercepted JA4 judged by volume: TLSIntercepted = %v, HighRequestRate = %v", s.TLSIntercepted, s.HighRequestRate)
+	}
+	script := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{UserAgent: "python-requests/2.31.0"},
+		TLS:  fingerprint.TLSFingerprint{Available: true, JA4Hash: "t13d1812h1_85036bcba153_b26ce05bbdd6"},
+		Rate: fingerprint.RateFingerprint{Available: true, JA4Requests: fingerprint.HighJA4RequestRate + 1},
+	}
+	if s := fingerprint.ExtractSignals(script); !s.HighRequestRate {
+		t.Error("rare JA4 over the limit not flagged")
+	}
+}
+
+func TestIsSubresourceFetch(t *testing.T) {
+	for dest, want := range map[string]bool{"": false, "document": false, "iframe": false, "image": true, "script": true, "empty": true} {
+		if got := fingerprint.IsSubresourceFetch(fingerprint.HTTPFingerprint{SecFetchDest: dest}); got != want {
+			t.Errorf("IsSubresourceFetch(%q) = %v, want %v", dest, got, want)
+		}
+	}
+}
+
+func TestHandler_RequestRate(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetRateTracker(rate.New(rate.DefaultConfig()))
+
+	debug := func(dest string) fingerprint.ClassificationResult {
+		req := httptest.NewRequest(http.MethodGet, "/debug", nil)
+		req.RemoteAddr = "198.51.100.23:4711"
+		if dest != "" {
+			req.Header.Set("Sec-Fetch-Dest", dest)
+		}
+		rr := httptest.NewRecorder()
+		h.HandleDebug(rr, req)
+		var result fingerprint.ClassificationResult
+		if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+			t.Fatalf("decode: %v", err)
+		}
+		return result
+	}
+
+	// Subresource fetches are not counted
+	if res := debug("image"); res.Fingerprint.Rate.Available {
+		t.Errorf("subresource fetch counted: %+v", res.Fingerprint.Rate)
+	}
+	var res fingerprint.ClassificationResult
+	for range fingerprint.BurstRequests + 1 {
+		res = debug("")
+	}
+	if res.Fingerprint.Rate.AddrRequests != fingerprint.BurstRequests+1 {
+		t.Errorf("AddrRequests = %d, want %d", res.Fingerprint.Rate.AddrRequests, fingerprint.BurstRequests+1)
+	}
+	if !res.Signals.BurstPattern {
+		t.Errorf("burst not flagged: %+v", res.Fingerprint.Rate)
+	}
+}
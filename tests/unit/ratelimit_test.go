@@ -0,0 +1,104 @@
+package unit
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/crawldelay"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+// rateLimitHeaders returns the RateLimit header fields of a response
+func rateLimitHeaders(w *httptest.ResponseRecorder) (limit, remaining, reset, policy string) {
+	h := w.Header()
+	return h.Get("RateLimit-Limit"), h.Get("RateLimit-Remaining"), h.Get("RateLimit-Reset"), h.Get("RateLimit-Policy")
+}
+
+func TestHandler_TenantRateLimitHeaders(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetTenants(loadTestTenants(t), nil)
+
+	classify := func(key, host string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = host
+		if key != "" {
+			r.Header.Set(tenant.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		h.HandleClassify(w, r)
+		return w
+	}
+
+	// shop allows 1 request/s with bursts of 2
+	tests := []struct {
+		status                   int
+		remaining, reset, policy string
+	}{
+		{http.StatusOK, "1", "1", `"tenant/shop";q=2;w=2`},
+		{http.StatusOK, "0", "1", `"tenant/shop";q=2;w=2`},
+		{http.StatusTooManyRequests, "0", "1", `"tenant/shop";q=2;w=2`},
+	}
+	for i, tt := range tests {
+		w := classify("k-shop", "")
+		limit, remaining, reset, policy := rateLimitHeaders(w)
+		if w.Code != tt.status || limit != "2" || remaining != tt.remaining || reset != tt.reset || policy != tt.policy {
+			t.Errorf("request %d: status %d, RateLimit-Limit %q, -Remaining %q, -Reset %q, -Policy %q", i, w.Code, limit, remaining, reset, policy)
+		}
+	}
+	if w := classify("k-shop", ""); w.Header().Get("Retry-After") != "1" {
+		t.Errorf("Retry-After = %q, want 1", w.Header().Get("Retry-After"))
+	}
+
+	// Tenants without a rate limit get no headers
+	if _, _, _, policy := rateLimitHeaders(classify("", "blog.example.com")); policy != "" {
+		t.Errorf("unlimited tenant RateLimit-Policy = %q", policy)
+	}
+}
+
+func TestHandler_CrawlDelayRateLimitHeaders(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "robots.txt")
+	if err := os.WriteFile(path, []byte(testRobots), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	p, err := crawldelay.Load(path, nil, fingerprint.DefaultRules().AICrawlerPatterns)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetCrawlDelay(p)
+	router := server.NewRouter(h, nil, false)
+
+	get := func(ua string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/v1/", nil)
+		r.Header.Set("User-Agent", ua)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, r)
+		return w
+	}
+
+	const gptbot = "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)"
+	w := get(gptbot)
+	limit, remaining, reset, policy := rateLimitHeaders(w)
+	if w.Code != http.StatusOK || limit != "1" || remaining != "0" || reset != "10" || policy != `"crawl-delay/gptbot";q=1;w=10` {
+		t.Errorf("allowed crawl: status %d, RateLimit-Limit %q, -Remaining %q, -Reset %q, -Policy %q", w.Code, limit, remaining, reset, policy)
+	}
+
+	w = get(gptbot)
+	_, _, reset, policy = rateLimitHeaders(w)
+	if n, _ := strconv.Atoi(reset); w.Code != http.StatusTooManyRequests || n < 1 || n > 10 || reset != w.Header().Get("Retry-After") || policy == "" {
+		t.Errorf("paced crawl: status %d, RateLimit-Reset %q, Retry-After %q, -Policy %q", w.Code, reset, w.Header().Get("Retry-After"), policy)
+	}
+
+	// Unpaced clients get no headers
+	if _, _, _, policy := rateLimitHeaders(get("curl/8.0")); policy != "" {
+		t.Errorf("unpaced client RateLimit-Policy = %q", policy)
+	}
+}
@@ -0,0 +1,156 @@
+package unit
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/reputation"
+)
+
+// countingProvider answers from a fixed result, counting lookups
+type countingProvider struct {
+	result  reputation.Result
+	err     error
+	lookups int
+}
+
+func (p *countingProvider) Lookup(context.Context, netip.Addr) (reputation.Result, error) {
+	p.lookups++
+	return p.result, p.err
+}
+
+func TestReputationLists(t *testing.T) {
+	vpn, err := reputation.ParseList(strings.NewReader("# VPN exits\n198.51.100.0/24\n2001:db8:1::/48 # v6 pool\n"))
+	if err != nil {
+		t.Fatalf("ParseList() error = %v", err)
+	}
+	lists := reputation.NewLists()
+	lists.Add("vpn", vpn)
+	lists.Add("tor", []netip.Prefix{netip.MustParsePrefix("198.51.100.7/32")})
+	if lists.Len() != 3 {
+		t.Errorf("Len() = %d, want 3", lists.Len())
+	}
+
+	tests := []struct {
+		addr, want string
+	}{
+		{"198.51.100.1", "vpn"},
+		{"198.51.100.7", "tor"}, // Most specific prefix wins
+		{"::ffff:198.51.100.1", "vpn"},
+		{"2001:db8:1:5::1", "vpn"},
+		{"203.0.113.1", ""},
+		{"2001:db8:2::1", ""},
+	}
+	for _, tt := range tests {
+		res, err := lists.Lookup(context.Background(), netip.MustParseAddr(tt.addr))
+		if err != nil || res.Category != tt.want || res.Anonymized != (tt.want != "") {
+			t.Errorf("Lookup(%s) = %+v, %v, want %q", tt.addr, res, err, tt.want)
+		}
+	}
+
+	for name, in := range map[string]string{"empty": "# nothing\n", "invalid": "198.51.100.0/24\nnot-an-address\n"} {
+		if _, err := reputation.ParseList(strings.NewReader(in)); err == nil {
+			t.Errorf("%s: ParseList() error = nil, want error", name)
+		}
+	}
+}
+
+func TestReputationLists_Load(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "proxies.txt")
+	if err := os.WriteFile(path, []byte("203.0.113.9\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/exits.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("192.0.2.0/28\n"))
+	}))
+	defer srv.Close()
+
+	lists := reputation.NewLists()
+	ctx := context.Background()
+	if err := lists.Load(ctx, "proxy", path); err != nil {
+		t.Fatalf("Load(file) error = %v", err)
+	}
+	if err := lists.Load(ctx, "tor", srv.URL+"/exits.txt"); err != nil {
+		t.Fatalf("Load(URL) error = %v", err)
+	}
+	for addr, want := range map[string]string{"203.0.113.9": "proxy", "192.0.2.15": "tor", "192.0.2.16": ""} {
+		if res, _ := lists.Lookup(ctx, netip.MustParseAddr(addr)); res.Category != want {
+			t.Errorf("Lookup(%s) = %q, want %q", addr, res.Category, want)
+		}
+	}
+	if err := lists.Load(ctx, "tor", srv.URL+"/missing.txt"); err == nil {
+		t.Error("Load() of a missing URL succeeded")
+	}
+	if err := lists.Load(ctx, "vpn", filepath.Join(t.TempDir(), "missing.txt")); err == nil {
+		t.Error("Load() of a missing file succeeded")
+	}
+}
+
+func TestReputationEnricher(t *testing.T) {
+	p := &countingProvider{result: reputation.Result{Anonymized: true, Category: "vpn"}}
+	e := reputation.NewEnricher(p)
+	enrich := func(addr string) (fingerprint.NetworkFingerprint, error) {
+		r := httptest.NewRequest("GET", "/", nil)
+		r.RemoteAddr = addr
+		var fp fingerprint.Fingerprint
+		err := e.Enrich(context.Background(), r, &fp)
+		return fp.Network, err
+	}
+
+	for range 3 {
+		if n, err := enrich("198.51.100.1:4711"); err != nil || n.Anonymizer != "vpn" {
+			t.Fatalf("Enrich() = %+v, %v", n, err)
+		}
+	}
+	if p.lookups != 1 {
+		t.Errorf("provider looked up %d times, want 1 (cached)", p.lookups)
+	}
+	if n, _ := enrich("10.0.0.2:4711"); n.Anonymizer != "" || p.lookups != 1 {
+		t.Errorf("private address looked up: %+v", n)
+	}
+
+	// Provider failures are reported and not cached
+	p.err = errors.New("reputation service unavailable")
+	if _, err := enrich("203.0.113.5:4711"); err == nil {
+		t.Error("Enrich() error = nil, want the provider's")
+	}
+	p.err = nil
+	p.result = reputation.Result{}
+	if n, err := enrich("203.0.113.5:4711"); err != nil || n.Anonymizer != "" {
+		t.Errorf("after failure: %+v, %v", n, err)
+	}
+}
+
+func TestExtractSignals_AnonymizedNetwork(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP:    fingerprint.HTTPFingerprint{UserAgent: chromeUA},
+		Network: fingerprint.NetworkFingerprint{Anonymizer: "tor"},
+	}
+	res := classifier.New().Classify(fp)
+	s := res.Signals
+	if !s.AnonymizedNetwork || s.Anonymizer != "tor" || !strings.Contains(s.ScoreBreakdown.String(), "anonymized-network(+2)") {
+		t.Errorf("AnonymizedNetwork = %v, Anonymizer = %q: %s", s.AnonymizedNetwork, s.Anonymizer, s.ScoreBreakdown)
+	}
+	if !strings.Contains(res.Reason, "anonymized network (tor)") {
+		t.Errorf("reason %q lacks the anonymizer", res.Reason)
+	}
+
+	// Private Relay is an anonymizer of genuine Safari users
+	fp.Network.PrivateRelay = true
+	if s := fingerprint.ExtractSignals(fp); s.AnonymizedNetwork {
+		t.Error("Private Relay egress scored as anonymized network")
+	}
+}
@@ -0,0 +1,211 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/ruleset"
+)
+
+func TestRulesetParse_UnknownKey(t *testing.T) {
+	_, err := ruleset.Parse([]byte("policy:\n  treshold: 1\n"))
+	if err == nil {
+		t.Fatal("Parse() should reject unknown keys")
+	}
+	if !strings.Contains(err.Error(), "treshold") {
+		t.Errorf("error should name the unknown key, got %v", err)
+	}
+}
+
+func TestRulesetLint(t *testing.T) {
+	rs, err := ruleset.Parse([]byte(`
+weights:
+  sec-fetch: 4
+  sec-fetsh: 2
+  http1.1: 0
+patterns:
+  bot: [curl, bot, Wget, curl, ""]
+  browser: [mozilla, chromebot]
+  tls_interceptor: [t13d1812h1_85036bcba153_b26ce05bbdd6, T13D1812H1_85036BCBA153_B26CE05BBDD6, " "]
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{
+		"weights.sec-fetsh":   ruleset.SeverityError,
+		"weights.http1.1":     ruleset.SeverityWarning,
+		"patterns.bot[2]":     ruleset.SeverityError,   // not lowercase
+		"patterns.bot[3]":     ruleset.SeverityWarning, // duplicate
+		"patterns.bot[4]":     ruleset.SeverityError,   // empty
+		"patterns.browser[1]": ruleset.SeverityError,   // contains "bot"
+
+		"patterns.tls_interceptor[1]": ruleset.SeverityWarning, // duplicate, ignoring case
+		"patterns.tls_interceptor[2]": ruleset.SeverityError,   // empty
+	}
+
+	issues := rs.Lint()
+	got := map[string]string{}
+	for _, i := range issues {
+		got[i.Path] = i.Severity
+	}
+	for path, severity := range want {
+		if got[path] != severity {
+			t.Errorf("issue at %s = %q, want %q (all: %v)", path, got[path], severity, issues)
+		}
+	}
+	if !ruleset.HasErrors(issues) {
+		t.Error("HasErrors() should be true")
+	}
+}
+
+func TestRulesetLint_Clean(t *testing.T) {
+	rs, err := ruleset.Parse([]byte("policy:\n  threshold: 2\nweights:\n  bot-ua: 5\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if issues := rs.Lint(); len(issues) != 0 {
+		t.Errorf("Lint() = %v, want no issues", issues)
+	}
+	if rs.Config().Threshold != 2 {
+		t.Errorf("Config().Threshold = %d, want 2", rs.Config().Threshold)
+	}
+}
+
+func TestRulesetCustomRules(t *testing.T) {
+	// JSON is accepted too, being a subset of YAML
+	rs, err := ruleset.Parse([]byte(`{
+  "version": "custom",
+  "rules": [
+    {"name": "api-no-cookies", "label": "bot", "signal": "http.has_cookies", "condition": "== false", "weight": 2},
+    {"name": "partner-key", "label": "browser", "signal": "http.headers.x-partner-key", "weight": 4}
+  ]
+}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if issues := rs.Lint(); len(issues) != 0 {
+		t.Fatalf("Lint() = %v, want no issues", issues)
+	}
+	rules := rs.Rules()
+	if len(rules.Custom) != 2 || rules.Custom[0].Name != "api-no-cookies" || !rules.Custom[0].Bot || rules.Custom[1].Bot {
+		t.Fatalf("Rules().Custom = %+v", rules.Custom)
+	}
+
+	cases := []ruleset.TestCase{
+		{Name: "curl", Headers: map[string]string{"User-Agent": "curl/8.0.1"}, Expect: "bot", Fires: []string{"bot-ua", "api-no-cookies"}},
+		{Name: "partner", Headers: map[string]string{"User-Agent": "curl/8.0.1", "X-Partner-Key": "k"}, Expect: "bot", Fires: []string{"partner-key"}},
+	}
+	if issues := rs.LintTests(cases); len(issues) != 0 {
+		t.Fatalf("LintTests() = %v", issues)
+	}
+	if issues := ruleset.LintTests(cases); len(issues) != 2 {
+		t.Errorf("LintTests() without the ruleset = %v, want 2 unknown rules", issues)
+	}
+	for _, res := range ruleset.Run(rs.Config(), cases) {
+		if !res.Passed {
+			t.Errorf("%s failed: %+v", res.Name, res)
+		}
+	}
+}
+
+func TestRulesetLint_CustomRules(t *testing.T) {
+	rs, err := ruleset.Parse([]byte(`
+weights:
+  partner-key: 3
+rules:
+  - {name: partner-key, label: browser, signal: http.headers.x-partner-key, weight: 4}
+  - {name: partner-key, label: browser, signal: http.headers.x-partner-id, weight: 4}
+  - {name: sec-fetch, label: browser, signal: has_sec_fetch_headers, weight: 1}
+  - {name: no-label, signal: ua_is_bot, weight: 1}
+  - {name: bad-signal, label: bot, signal: ua_is_robot, weight: 1}
+  - {name: bad-condition, label: bot, signal: http.header_count, condition: "~ 3", weight: 1}
+  - {name: off, label: bot, signal: ua_is_bot, weight: 0}
+  - {name: flipped, label: bot, signal: ua_is_bot, weight: -1}
+`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	want := map[string]string{
+		"rules[1]": ruleset.SeverityError,   // duplicate name
+		"rules[2]": ruleset.SeverityError,   // built-in name
+		"rules[3]": ruleset.SeverityError,   // no label
+		"rules[4]": ruleset.SeverityError,   // unknown signal
+		"rules[5]": ruleset.SeverityError,   // unknown operator
+		"rules[6]": ruleset.SeverityWarning, // zero weight
+		"rules[7]": ruleset.SeverityWarning, // negative weight
+	}
+	issues := rs.Lint()
+	got := map[string]string{}
+	for _, i := range issues {
+		got[i.Path] = i.Severity
+	}
+	for path, severity := range want {
+		if got[path] != severity {
+			t.Errorf("issue at %s = %q, want %q (all: %v)", path, got[path], severity, issues)
+		}
+	}
+	if _, ok := got["rules[0]"]; ok {
+		t.Errorf("valid rule reported: %v", issues)
+	}
+	if _, ok := got["weights.partner-key"]; ok {
+		t.Errorf("weight of a custom rule reported as unknown: %v", issues)
+	}
+}
+
+func TestRulesetRun(t *testing.T) {
+	cases, err := ruleset.ParseTests([]byte(`
+tests:
+  - name: curl
+    headers:
+      User-Agent: curl/8.0.1
+    expect: bot
+    fires: [bot-ua]
+  - name: curl as browser
+    headers:
+      User-Agent: curl/8.0.1
+    expect: browser
+  - name: custom pattern
+    headers:
+      User-Agent: acme-monitor/1.0
+    expect: bot
+    fires: [bot-ua]
+`))
+	if err != nil {
+		t.Fatalf("ParseTests() error = %v", err)
+	}
+	if issues := ruleset.LintTests(cases); len(issues) != 0 {
+		t.Fatalf("LintTests() = %v", issues)
+	}
+
+	rs, err := ruleset.Parse([]byte("patterns:\n  bot: [curl, acme-monitor]\n"))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	results := ruleset.Run(rs.Config(), cases)
+	if len(results) != 3 {
+		t.Fatalf("Run() returned %d results, want 3", len(results))
+	}
+	if !results[0].Passed {
+		t.Errorf("curl should pass: %+v", results[0])
+	}
+	if results[1].Passed || results[1].Got != "bot" {
+		t.Errorf("curl as browser should fail with got=bot: %+v", results[1])
+	}
+	if !results[2].Passed {
+		t.Errorf("custom bot pattern should match: %+v", results[2])
+	}
+}
+
+func TestRulesetLintTests(t *testing.T) {
+	issues := ruleset.LintTests([]ruleset.TestCase{
+		{Name: "a", Expect: "robot"},
+		{Name: "a", Expect: "bot", Fires: []string{"no-such-rule"}},
+		{Expect: "bot"},
+	})
+	if len(issues) != 4 {
+		t.Errorf("LintTests() = %v, want 4 issues", issues)
+	}
+}
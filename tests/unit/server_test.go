@@ -1,13 +1,27 @@
 package unit
 
 import (
+	"bufio"
+	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"time"
 
+	"github.com/muliwe/go-client-classifier/internal/captcha"
+	"github.com/muliwe/go-client-classifier/internal/capture"
 	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/events"
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/ipkey"
+	"github.com/muliwe/go-client-classifier/internal/logger"
 	"github.com/muliwe/go-client-classifier/internal/server"
 )
 
@@ -24,6 +38,60 @@ func TestServerNewHandler(t *testing.T) {
 	}
 }
 
+func TestServerNew_Options(t *testing.T) {
+	lc := logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"}
+
+	tests := []struct {
+		name string
+		opts []server.Option
+	}{
+		{"config", []server.Option{func() server.Config {
+			cfg := server.DefaultConfig()
+			cfg.LoggerConfig = lc
+			return cfg
+		}()}},
+		{"options", []server.Option{
+			server.WithAddr("127.0.0.1:0"),
+			server.WithLogger(lc),
+			server.WithTimeouts(time.Second, time.Second, time.Second),
+			server.WithShutdownTimeout(5 * time.Second),
+			server.WithDrainDelay(0),
+			server.WithDebug(false),
+			server.WithStream(true),
+			server.WithBotScoreHeader(classifier.BotScoreHeader),
+			server.WithTenants(nil),
+			server.WithIPAnonymizer(nil),
+			server.WithIPKeys(ipkey.DefaultConfig()),
+			server.WithCapture(capture.Config{Path: filepath.Join(lc.LogDir, "capture.jsonl")}),
+			server.WithAdminToken("token"),
+			server.WithCrawlDelay(nil),
+			server.WithAIPolicyHeaders(true),
+			server.WithEvents(events.NewBus()),
+			server.WithChallengeTokens(nil),
+			server.WithCaptcha(nil, captcha.DefaultStoreConfig()),
+			server.WithMemoryBudget(nil),
+			server.WithListener(nil),
+			server.WithGRPCListener(nil),
+			server.WithRulesetVersion("2026-10"),
+			server.WithAPIValidation(false),
+			server.WithSessionTracking(false),
+			server.WithClassifier(classifier.WithThreshold(2)),
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv, err := server.New(tt.opts...)
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+			if err := srv.Close(); err != nil {
+				t.Errorf("Close() error = %v", err)
+			}
+		})
+	}
+}
+
 func TestServerHandleHealth(t *testing.T) {
 	h := createTestHandler()
 
@@ -103,6 +171,46 @@ func TestServerHandleClassify_NotFound(t *testing.T) {
 	}
 }
 
+func TestServerHandleClassify_Methods(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetBotScoreHeader(classifier.BotScoreHeader)
+
+	// HEAD: headers of GET, no body
+	req := httptest.NewRequest(http.MethodHead, "/", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	w := httptest.NewRecorder()
+	h.HandleClassify(w, req)
+	if w.Code != http.StatusOK || w.Body.Len() != 0 {
+		t.Errorf("HEAD status = %d, body = %q; want 200 without body", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Content-Type") != "application/json" || w.Header().Get(classifier.BotScoreHeader) == "" {
+		t.Errorf("HEAD headers = %v", w.Header())
+	}
+
+	// OPTIONS preflight: 204 with Allow and the bot score
+	req = httptest.NewRequest(http.MethodOptions, "/v1/", nil)
+	req.Header.Set("User-Agent", testSafariUA)
+	req.Header.Set("Origin", "https://shop.example.com")
+	req.Header.Set("Access-Control-Request-Method", "GET")
+	req.Header.Set("Accept", "*/*")
+	w = httptest.NewRecorder()
+	h.HandleClassify(w, req)
+	if w.Code != http.StatusNoContent || w.Body.Len() != 0 {
+		t.Errorf("OPTIONS status = %d, body = %q; want 204 without body", w.Code, w.Body.String())
+	}
+	if w.Header().Get("Allow") != "GET, HEAD, OPTIONS" || w.Header().Get(classifier.BotScoreHeader) == "" {
+		t.Errorf("OPTIONS headers = %v", w.Header())
+	}
+
+	// Other methods are rejected
+	w = httptest.NewRecorder()
+	h.HandleClassify(w, httptest.NewRequest(http.MethodPost, "/", nil))
+	if w.Code != http.StatusMethodNotAllowed || w.Header().Get("Allow") != "GET, HEAD, OPTIONS" {
+		t.Errorf("POST status = %d, Allow = %q", w.Code, w.Header().Get("Allow"))
+	}
+}
+
 func TestServerHandleDebug(t *testing.T) {
 	h := createTestHandler()
 
@@ -154,3 +262,503 @@ func TestServerHandleClassify_BrowserHeaders(t *testing.T) {
 		t.Errorf("HandleClassify(browser headers) classification = %q, want %q", response.Classification, "browser")
 	}
 }
+
+func TestServerHandleClassifyRequest_MethodNotAllowed(t *testing.T) {
+	h := createTestHandler()
+
+	req := httptest.NewRequest("GET", "/classify", nil)
+	w := httptest.NewRecorder()
+
+	h.HandleClassifyRequest(w, req)
+
+	resp := w.Result()
+	if resp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("HandleClassifyRequest(GET) status = %d, want %d", resp.StatusCode, http.StatusMethodNotAllowed)
+	}
+	if allow := resp.Header.Get("Allow"); allow != http.MethodPost {
+		t.Errorf("HandleClassifyRequest(GET) Allow = %q, want %q", allow, http.MethodPost)
+	}
+}
+
+func TestServerHandleClassifyRequest_InvalidBody(t *testing.T) {
+	h := createTestHandler()
+
+	req := httptest.NewRequest("POST", "/classify", strings.NewReader("{not json"))
+	w := httptest.NewRecorder()
+
+	h.HandleClassifyRequest(w, req)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("HandleClassifyRequest(invalid) status = %d, want %d", w.Code, http.StatusBadRequest)
+	}
+}
+
+func TestHandler_Hooks(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+
+	var classified, blocked []string
+	h.OnClassified(func(r fingerprint.ClassificationResult) { classified = append(classified, r.Classification) })
+	h.OnBlocked(func(r fingerprint.ClassificationResult) { blocked = append(blocked, r.Classification) })
+	h.OnClassified(func(fingerprint.ClassificationResult) { panic("faulty hook") })
+
+	bot := httptest.NewRequest("GET", "/", nil)
+	bot.Header.Set("User-Agent", "curl/8.0.1")
+
+	browser := httptest.NewRequest("GET", "/", nil)
+	browser.Proto, browser.ProtoMajor, browser.ProtoMinor = "HTTP/2.0", 2, 0
+	browser.Header.Set("User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36")
+	browser.Header.Set("Accept", "text/html,application/xhtml+xml")
+	browser.Header.Set("Accept-Language", "en-US,en;q=0.9")
+	browser.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	browser.Header.Set("Sec-Fetch-Site", "none")
+	browser.Header.Set("Sec-Fetch-Mode", "navigate")
+	browser.Header.Set("Sec-Fetch-Dest", "document")
+	browser.Header.Set("Sec-CH-UA", `"Chromium";v="120"`)
+
+	for _, req := range []*http.Request{bot, browser} {
+		w := httptest.NewRecorder()
+		h.HandleClassify(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("HandleClassify() status = %d, want %d (panicking hook must not fail the request)", w.Code, http.StatusOK)
+		}
+	}
+
+	if got := strings.Join(classified, ","); got != "bot,browser" {
+		t.Errorf("OnClassified results = %q, want %q", got, "bot,browser")
+	}
+	if got := strings.Join(blocked, ","); got != "bot" {
+		t.Errorf("OnBlocked results = %q, want %q", got, "bot")
+	}
+}
+
+func TestNewRouter_VersionedAndLegacyRoutes(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	router := server.NewRouter(h, newTestValidator(t), true)
+
+	tests := []struct {
+		method string
+		path   string
+		body   string
+		want   int
+	}{
+		{"GET", "/v1/", "", http.StatusOK},
+		{"GET", "/", "", http.StatusOK},
+		{"GET", "/v1/health", "", http.StatusOK},
+		{"GET", "/health", "", http.StatusOK},
+		{"GET", "/v1/stats", "", http.StatusOK},
+		{"GET", "/v1/debug", "", http.StatusOK},
+		{"GET", "/v1/openapi.yaml", "", http.StatusOK},
+		{"POST", "/v1/classify", `{"method":"GET","path":"/","headers":{"User-Agent":["curl/8.0.1"]}}`, http.StatusOK},
+		{"POST", "/classify", `{"method":"GET","path":"/","headers":{"User-Agent":["curl/8.0.1"]}}`, http.StatusOK},
+		{"POST", "/v1/classify", `{"path":"no-slash"}`, http.StatusBadRequest},
+		{"POST", "/v1/classify/fingerprint", `{"http":{"user_agent":"curl/8.0.1"}}`, http.StatusOK},
+		{"GET", "/v1/unknown", "", http.StatusNotFound},
+		{"GET", "/v2/health", "", http.StatusNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d (body %s)", w.Code, tt.want, w.Body.String())
+			}
+			if got := w.Header().Get("API-Version"); got != server.APIVersion {
+				t.Errorf("API-Version = %q, want %q", got, server.APIVersion)
+			}
+		})
+	}
+}
+
+func TestHandler_Stream(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetStreaming(true)
+	srv := httptest.NewServer(server.NewRouter(h, nil, false))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	req, _ := http.NewRequestWithContext(ctx, "GET", srv.URL+"/v1/stream", nil)
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatalf("GET /v1/stream error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Content-Type = %q, want text/event-stream", ct)
+	}
+
+	// The subscription is registered before the headers are flushed
+	classify, _ := http.NewRequest("GET", srv.URL+"/v1/", nil)
+	classify.Header.Set("User-Agent", "curl/8.0.1")
+	cresp, err := srv.Client().Do(classify)
+	if err != nil {
+		t.Fatalf("GET /v1/ error = %v", err)
+	}
+	_ = cresp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	var event, data string
+	for scanner.Scan() && data == "" {
+		line := scanner.Text()
+		if v, ok := strings.CutPrefix(line, "event: "); ok {
+			event = v
+		}
+		if v, ok := strings.CutPrefix(line, "data: "); ok {
+			data = v
+		}
+	}
+	if event != "classification" {
+		t.Errorf("event = %q, want classification", event)
+	}
+	var entry logger.LogEntry
+	if err := json.Unmarshal([]byte(data), &entry); err != nil {
+		t.Fatalf("event data is not a log entry: %v (%q)", err, data)
+	}
+	if entry.Classification != classifier.ClassificationBot || entry.Fingerprint.HTTP.UserAgent != "curl/8.0.1" || entry.RemoteAddr == "" {
+		t.Errorf("entry = %+v, want the curl request classified as bot", entry)
+	}
+}
+
+func TestHandler_Drain(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetStreaming(true)
+	release := make(chan struct{})
+	h.OnClassified(func(fingerprint.ClassificationResult) { <-release })
+	srv := httptest.NewServer(server.NewRouter(h, nil, false))
+	defer srv.Close()
+
+	resp, err := srv.Client().Get(srv.URL + "/v1/stream")
+	if err != nil {
+		t.Fatalf("GET /v1/stream error = %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	// A classification held up by its hook is in flight, like the stream
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if r, err := srv.Client().Get(srv.URL + "/v1/"); err == nil {
+			_ = r.Body.Close()
+		}
+	}()
+	deadline := time.Now().Add(5 * time.Second)
+	for h.InFlight() < 2 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := h.InFlight(); got != 2 {
+		t.Fatalf("InFlight() = %d, want 2", got)
+	}
+
+	h.StartDrain()
+	if !h.Draining() {
+		t.Error("Draining() = false after StartDrain")
+	}
+	w := httptest.NewRecorder()
+	h.HandleHealth(w, httptest.NewRequest("GET", "/v1/health", nil))
+	var health server.HealthResponse
+	if err := json.NewDecoder(w.Body).Decode(&health); err != nil || w.Code != http.StatusServiceUnavailable || health.Status != "draining" {
+		t.Errorf("health while draining = %d %+v (%v)", w.Code, health, err)
+	}
+
+	// Draining ends the stream, and the classification still completes
+	if _, err := io.Copy(io.Discard, resp.Body); err != nil {
+		t.Errorf("stream read error = %v", err)
+	}
+	close(release)
+	<-done
+	for h.InFlight() > 0 && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if got := h.InFlight(); got != 0 {
+		t.Errorf("InFlight() = %d after requests finished", got)
+	}
+}
+
+func TestHandler_StreamDisabled(t *testing.T) {
+	h := createTestHandler()
+	router := server.NewRouter(h, nil, false)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("GET", "/v1/stream", nil))
+	if w.Code != http.StatusNotFound {
+		t.Errorf("status = %d, want %d when streaming is disabled", w.Code, http.StatusNotFound)
+	}
+}
+
+func TestHandler_BotScoreHeader(t *testing.T) {
+	lc := logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"}
+	l, err := logger.New(lc)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	h := server.NewHandler(fingerprint.NewCollector(), classifier.New(classifier.DefaultConfig()), l)
+	h.SetQuiet(true)
+	h.SetBotScoreHeader(classifier.BotScoreHeader)
+	router := server.NewRouter(h, nil, false)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v1/", nil)
+	req.Header.Set("User-Agent", "curl/8.0")
+	router.ServeHTTP(w, req)
+	if got, _ := strconv.Atoi(w.Header().Get("Cf-Bot-Score")); got < 1 || got >= classifier.BotScoreLikelyHuman {
+		t.Errorf("Cf-Bot-Score = %d, want 1-29 for curl", got)
+	}
+
+	body := `{"method":"GET","proto":"HTTP/2.0","headers":{"User-Agent":["Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"],"Accept":["text/html"],"Accept-Language":["en-US"],"Sec-Fetch-Mode":["navigate"]}}`
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest("POST", "/v1/classify", strings.NewReader(body)))
+	if got, _ := strconv.Atoi(w.Header().Get("Cf-Bot-Score")); got < classifier.BotScoreLikelyHuman || got > 99 {
+		t.Errorf("Cf-Bot-Score = %d, want 30-99 for a browser", got)
+	}
+
+	data, err := os.ReadFile(l.LogPath())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(data), `"cf_bot_score":`); n != 2 {
+		t.Errorf("log has %d cf_bot_score fields, want 2", n)
+	}
+}
+
+func TestHandler_Explain(t *testing.T) {
+	lc := logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"}
+	l, err := logger.New(lc)
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	h := server.NewHandler(fingerprint.NewCollector(), classifier.New(classifier.DefaultConfig()), l)
+	h.SetQuiet(true)
+	h.SetAdminToken("s3cret")
+	router := server.NewRouter(h, nil, false)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v1/", nil)
+	req.Header.Set("User-Agent", "curl/8.0.1")
+	router.ServeHTTP(w, req)
+	var classified server.Response
+	if err := json.NewDecoder(w.Body).Decode(&classified); err != nil {
+		t.Fatal(err)
+	}
+
+	explainReq := func(path string) *http.Request {
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Authorization", "Bearer s3cret")
+		return req
+	}
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, explainReq("/v1/explain/"+classified.RequestID))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+	var resp server.ExplainResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.RequestID != classified.RequestID || resp.Classification != classifier.ClassificationBot {
+		t.Errorf("explained %s as %s, want %s as bot", resp.RequestID, resp.Classification, classified.RequestID)
+	}
+	if !slices.Contains(resp.BotRules, fingerprint.RuleScore{Name: "bot-ua", Weight: 3}) {
+		t.Errorf("bot_rules = %v, want bot-ua (+3)", resp.BotRules)
+	}
+	if !slices.Contains(resp.Patterns.Bot, "curl") {
+		t.Errorf("matched bot patterns = %v, want curl", resp.Patterns.Bot)
+	}
+	if resp.Policy == nil {
+		t.Error("bot decision has no policy")
+	}
+	for _, want := range []string{"classified as bot", "bot-ua (+3)", `bot patterns: "curl"`, "policy for this client as a bot: allowed"} {
+		if !strings.Contains(resp.Explanation, want) {
+			t.Errorf("explanation lacks %q:\n%s", want, resp.Explanation)
+		}
+	}
+
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, explainReq("/explain/"+classified.RequestID+"?format=text"))
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain") || w.Body.String() != resp.Explanation {
+		t.Errorf("format=text: Content-Type %q, body %q", ct, w.Body.String())
+	}
+
+	// A torn trailing write must not hide earlier decisions
+	f, err := os.OpenFile(l.LogPath(), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _ = f.WriteString(`{"request_id":"torn","classifi`)
+	_ = f.Close()
+	w = httptest.NewRecorder()
+	router.ServeHTTP(w, explainReq("/v1/explain/"+classified.RequestID))
+	if w.Code != http.StatusOK {
+		t.Errorf("after a torn line: status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+
+	unlogged := createTestHandler()
+	unlogged.SetAdminToken("s3cret")
+	tests := []struct {
+		name    string
+		handler *server.Handler
+		path    string
+		auth    bool
+		want    int
+		code    string
+	}{
+		{"unknown id", h, "/v1/explain/no-such-request", true, http.StatusNotFound, server.CodeNotFound},
+		{"bad format", h, "/v1/explain/" + classified.RequestID + "?format=xml", true, http.StatusBadRequest, server.CodeInvalidRequest},
+		{"no log", unlogged, "/v1/explain/" + classified.RequestID, true, http.StatusNotFound, server.CodeNotFound},
+		{"anonymous", h, "/v1/explain/" + classified.RequestID, false, http.StatusUnauthorized, server.CodeUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", tt.path, nil)
+			if tt.auth {
+				req.Header.Set("Authorization", "Bearer s3cret")
+			}
+			w := httptest.NewRecorder()
+			server.NewRouter(tt.handler, nil, false).ServeHTTP(w, req)
+			var p server.Problem
+			_ = json.NewDecoder(w.Body).Decode(&p)
+			if w.Code != tt.want || p.Code != tt.code {
+				t.Errorf("status %d code %q, want %d %q", w.Code, p.Code, tt.want, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandler_Feedback(t *testing.T) {
+	l, err := logger.New(logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"})
+	if err != nil {
+		t.Fatalf("logger.New() error = %v", err)
+	}
+	defer func() { _ = l.Close() }()
+
+	var stored feedbackRecorder
+	h := server.NewHandler(fingerprint.NewCollector(),
+		classifier.New(classifier.WithFeedback(&stored), classifier.WithFeedbackAdjust(true)), l)
+	h.SetQuiet(true)
+	h.SetAdminToken("token")
+	router := server.NewRouter(h, nil, false)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/v1/", nil)
+	req.Header.Set("User-Agent", "curl/8.0.1")
+	router.ServeHTTP(w, req)
+	var classified server.Response
+	if err := json.NewDecoder(w.Body).Decode(&classified); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(router http.Handler, token, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/v1/feedback", strings.NewReader(body))
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w = post(router, "token", `{"request_id":"`+classified.RequestID+`","label":"browser","note":"uptime monitor"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200 (body %s)", w.Code, w.Body.String())
+	}
+	var resp server.FeedbackResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.RequestID != classified.RequestID || resp.Label != "browser" || resp.Predicted != "bot" || !resp.Stored {
+		t.Errorf("response = %+v", resp)
+	}
+	if resp.Adjusted["bot-ua"] != 2 {
+		t.Errorf("adjusted = %v, want bot-ua lowered to 2", resp.Adjusted)
+	}
+	if len(stored) != 1 || stored[0].Fingerprint.HTTP.UserAgent != "curl/8.0.1" || stored[0].Note != "uptime monitor" {
+		t.Errorf("stored = %+v, want the logged fingerprint", stored)
+	}
+
+	tests := []struct {
+		name   string
+		router http.Handler
+		token  string
+		body   string
+		want   int
+		code   string
+	}{
+		{"no token", router, "", `{"request_id":"x","label":"bot"}`, http.StatusUnauthorized, server.CodeUnauthorized},
+		{"wrong token", router, "nope", `{"request_id":"x","label":"bot"}`, http.StatusUnauthorized, server.CodeUnauthorized},
+		{"invalid label", router, "token", `{"request_id":"x","label":"human"}`, http.StatusBadRequest, server.CodeInvalidRequest},
+		{"no request id", router, "token", `{"label":"bot"}`, http.StatusBadRequest, server.CodeInvalidRequest},
+		{"unknown id", router, "token", `{"request_id":"no-such-request","label":"bot"}`, http.StatusNotFound, server.CodeNotFound},
+		{"disabled", server.NewRouter(createTestHandler(), nil, false), "token", `{"request_id":"x","label":"bot"}`, http.StatusNotFound, server.CodeNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := post(tt.router, tt.token, tt.body)
+			var p server.Problem
+			_ = json.NewDecoder(w.Body).Decode(&p)
+			if w.Code != tt.want || p.Code != tt.code {
+				t.Errorf("status %d code %q, want %d %q", w.Code, p.Code, tt.want, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandler_ProblemResponses(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	router := server.NewRouter(h, nil, false)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		body   string
+		status int
+		code   string
+	}{
+		{"not found", "GET", "/v1/nope", "", http.StatusNotFound, server.CodeNotFound},
+		{"method not allowed", "GET", "/v1/classify", "", http.StatusMethodNotAllowed, server.CodeMethodNotAllowed},
+		{"invalid json", "POST", "/v1/classify", `{"method":`, http.StatusBadRequest, server.CodeInvalidBody},
+		{"invalid fingerprint", "POST", "/v1/classify/fingerprint", `[]`, http.StatusBadRequest, server.CodeInvalidBody},
+		{"invalid request", "POST", "/v1/classify", `{"method":"BAD METHOD"}`, http.StatusBadRequest, server.CodeInvalidRequest},
+		{"too large", "POST", "/v1/classify", `{"host":"` + strings.Repeat("a", 2<<20) + `"}`, http.StatusRequestEntityTooLarge, server.CodePayloadTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			if w.Code != tt.status {
+				t.Fatalf("status = %d, want %d", w.Code, tt.status)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Content-Type = %q, want application/problem+json", ct)
+			}
+
+			var p server.Problem
+			if err := json.NewDecoder(w.Body).Decode(&p); err != nil {
+				t.Fatalf("Failed to decode problem: %v", err)
+			}
+			if p.Code != tt.code || p.Status != tt.status || p.Instance != tt.path {
+				t.Errorf("problem = %+v, want code %q status %d instance %q", p, tt.code, tt.status, tt.path)
+			}
+			if !strings.HasSuffix(p.Type, "#"+tt.code) || p.Title == "" {
+				t.Errorf("problem type/title = %q/%q, want type ending in #%s", p.Type, p.Title, tt.code)
+			}
+		})
+	}
+}
@@ -0,0 +1,249 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/session"
+)
+
+func TestSessionKey(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.RemoteAddr = "192.0.2.1:54321"
+	req.Header.Set("User-Agent", "curl/8.0")
+
+	if got, want := session.Key(req), "192.0.2.1|curl/8.0"; got != want {
+		t.Errorf("Key() = %q, want %q", got, want)
+	}
+}
+
+func TestTrackerObserve_FirstRequest(t *testing.T) {
+	tr := session.New(session.DefaultConfig())
+
+	s := tr.Observe("client", time.Now(), false)
+
+	if !s.Available {
+		t.Error("Observe() should mark session available")
+	}
+	if s.RequestCount != 1 {
+		t.Errorf("RequestCount = %d, want 1", s.RequestCount)
+	}
+	if s.IntervalCount != 0 {
+		t.Errorf("IntervalCount = %d, want 0", s.IntervalCount)
+	}
+}
+
+func TestTrackerObserve_RegularIntervals(t *testing.T) {
+	tr := session.New(session.DefaultConfig())
+	start := time.Now()
+
+	var s fingerprint.SessionFingerprint
+	for i := 0; i < 6; i++ {
+		s = tr.Observe("client", start.Add(time.Duration(i)*100*time.Millisecond), false)
+	}
+
+	if s.IntervalCount != 5 {
+		t.Errorf("IntervalCount = %d, want 5", s.IntervalCount)
+	}
+	if s.MeanIntervalMs != 100 {
+		t.Errorf("MeanIntervalMs = %f, want 100", s.MeanIntervalMs)
+	}
+	if s.IntervalJitter != 0 {
+		t.Errorf("IntervalJitter = %f, want 0", s.IntervalJitter)
+	}
+}
+
+func TestTrackerObserve_WindowLimit(t *testing.T) {
+	tr := session.New(session.Config{WindowSize: 4})
+	start := time.Now()
+
+	var s fingerprint.SessionFingerprint
+	for i := 0; i < 10; i++ {
+		s = tr.Observe("client", start.Add(time.Duration(i)*time.Second), false)
+	}
+
+	if s.RequestCount != 10 {
+		t.Errorf("RequestCount = %d, want 10", s.RequestCount)
+	}
+	if s.IntervalCount != 3 {
+		t.Errorf("IntervalCount = %d, want 3 (window of 4)", s.IntervalCount)
+	}
+}
+
+func TestTrackerObserve_IdleTimeoutResets(t *testing.T) {
+	tr := session.New(session.Config{IdleTimeout: time.Minute})
+	start := time.Now()
+
+	tr.Observe("client", start, false)
+	tr.Observe("client", start.Add(time.Second), false)
+	s := tr.Observe("client", start.Add(time.Hour), false)
+
+	if s.RequestCount != 1 {
+		t.Errorf("RequestCount after idle = %d, want 1", s.RequestCount)
+	}
+}
+
+func TestTrackerEviction(t *testing.T) {
+	tr := session.New(session.Config{MaxSessions: 2})
+	now := time.Now()
+
+	tr.Observe("a", now, false)
+	tr.Observe("b", now.Add(time.Second), false)
+	tr.Observe("c", now.Add(2*time.Second), false)
+
+	if tr.Len() != 2 {
+		t.Errorf("Len() = %d, want 2", tr.Len())
+	}
+}
+
+func TestTrackerEviction_Batch(t *testing.T) {
+	tr := session.New(session.Config{MaxSessions: 64})
+	now := time.Now()
+	for i := range 64 {
+		tr.Observe(strconv.Itoa(i), now.Add(time.Duration(i)*time.Second), false)
+	}
+
+	// A full tracker frees a batch of the least recently seen sessions
+	tr.Observe("new", now.Add(time.Minute), false)
+	if tr.Len() != 61 {
+		t.Errorf("Len() = %d, want 61 (64 - 4 evicted + 1)", tr.Len())
+	}
+	for i, want := range map[int]int{0: 1, 3: 1, 4: 2, 63: 2} {
+		if s := tr.Observe(strconv.Itoa(i), now.Add(2*time.Minute), false); s.RequestCount != want {
+			t.Errorf("session %d RequestCount = %d, want %d", i, s.RequestCount, want)
+		}
+	}
+}
+
+func TestExtractSignals_RegularTiming(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		Session: fingerprint.SessionFingerprint{
+			Available:        true,
+			RequestCount:     6,
+			IntervalCount:    5,
+			MeanIntervalMs:   100,
+			IntervalStdDevMs: 2,
+			IntervalJitter:   0.02,
+		},
+	}
+
+	s := fingerprint.ExtractSignals(fp)
+
+	if !s.RegularTiming {
+		t.Error("Low jitter should set RegularTiming")
+	}
+	if !s.SubHumanInterval {
+		t.Error("100ms mean interval should set SubHumanInterval")
+	}
+	if !strings.Contains(s.ScoreBreakdown.String(), "regular-timing") {
+		t.Errorf("Breakdown should mention regular-timing, got: %s", s.ScoreBreakdown)
+	}
+}
+
+func TestExtractSignals_HumanTiming(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		Session: fingerprint.SessionFingerprint{
+			Available:        true,
+			RequestCount:     6,
+			IntervalCount:    5,
+			MeanIntervalMs:   4200,
+			IntervalStdDevMs: 2900,
+			IntervalJitter:   0.69,
+		},
+	}
+
+	s := fingerprint.ExtractSignals(fp)
+
+	if s.RegularTiming {
+		t.Error("High jitter should not set RegularTiming")
+	}
+	if s.SubHumanInterval {
+		t.Error("Multi-second intervals should not set SubHumanInterval")
+	}
+}
+
+func TestExtractSignals_TimingNeedsSamples(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		Session: fingerprint.SessionFingerprint{
+			Available:      true,
+			RequestCount:   2,
+			IntervalCount:  1,
+			MeanIntervalMs: 50,
+		},
+	}
+
+	s := fingerprint.ExtractSignals(fp)
+
+	if s.RegularTiming || s.SubHumanInterval {
+		t.Error("Timing signals should require a minimum number of intervals")
+	}
+}
+
+func TestTrackerObserve_PagesAndAssets(t *testing.T) {
+	tr := session.New(session.DefaultConfig())
+	now := time.Now()
+
+	tr.Observe("client", now, false)
+	tr.Observe("client", now.Add(50*time.Millisecond), true)
+	s := tr.Observe("client", now.Add(80*time.Millisecond), true)
+
+	if s.PageRequests != 1 || s.AssetRequests != 2 {
+		t.Errorf("PageRequests = %d, AssetRequests = %d, want 1 and 2", s.PageRequests, s.AssetRequests)
+	}
+}
+
+func TestIsAssetRequest(t *testing.T) {
+	tests := []struct {
+		name string
+		h    fingerprint.HTTPFingerprint
+		want bool
+	}{
+		{"navigation", fingerprint.HTTPFingerprint{Path: "/articles/42", SecFetchDest: "document"}, false},
+		{"image by Sec-Fetch-Dest", fingerprint.HTTPFingerprint{Path: "/img/42", SecFetchDest: "image"}, true},
+		{"Sec-Fetch-Dest wins over path", fingerprint.HTTPFingerprint{Path: "/report.json", SecFetchDest: "document"}, false},
+		{"favicon", fingerprint.HTTPFingerprint{Path: "/favicon.ico"}, true},
+		{"stylesheet with query", fingerprint.HTTPFingerprint{Path: "/static/app.CSS?v=3"}, true},
+		{"image Accept", fingerprint.HTTPFingerprint{Path: "/thumb", Accept: "image/avif,image/webp,*/*"}, true},
+		{"page without headers", fingerprint.HTTPFingerprint{Path: "/products/7"}, false},
+	}
+	for _, tt := range tests {
+		if got := fingerprint.IsAssetRequest(tt.h); got != tt.want {
+			t.Errorf("%s: IsAssetRequest() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractSignals_ContentOnly(t *testing.T) {
+	scraper := fingerprint.SessionFingerprint{
+		Available:        true,
+		RequestCount:     8,
+		IntervalCount:    7,
+		MeanIntervalMs:   900,
+		IntervalStdDevMs: 600,
+		IntervalJitter:   0.67,
+		PageRequests:     8,
+	}
+	s := fingerprint.ExtractSignals(fingerprint.Fingerprint{Session: scraper})
+	if !s.ContentOnly {
+		t.Fatal("rapid pages without assets should set ContentOnly")
+	}
+	if !strings.Contains(s.ScoreBreakdown.String(), "content-only(+2)") {
+		t.Errorf("Breakdown should mention content-only, got: %s", s.ScoreBreakdown)
+	}
+
+	browser := scraper
+	browser.RequestCount, browser.AssetRequests = 20, 12
+	reader := scraper
+	reader.MeanIntervalMs = 45000
+	early := scraper
+	early.PageRequests = fingerprint.MinNavigationPages - 1
+	for name, sess := range map[string]fingerprint.SessionFingerprint{"with assets": browser, "slow reading": reader, "few pages": early} {
+		if s := fingerprint.ExtractSignals(fingerprint.Fingerprint{Session: sess}); s.ContentOnly {
+			t.Errorf("%s: ContentOnly set", name)
+		}
+	}
+}
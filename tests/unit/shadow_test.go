@@ -0,0 +1,118 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/shadow"
+)
+
+// shadowLog returns a request log with an HTTP/1.1 browser and curl
+func shadowLog(t *testing.T) *bytes.Buffer {
+	t.Helper()
+	entries := []logger.LogEntry{
+		{RequestID: "browser-1", Fingerprint: fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 Chrome/120.0.0.0 Safari/537.36",
+			Accept:      "text/html",
+			AcceptLang:  "en-US",
+			HeaderCount: 6,
+		}}},
+		{RequestID: "curl-1", Fingerprint: fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			Accept:      "*/*",
+			HeaderCount: 3,
+		}}},
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, e := range entries {
+		if err := enc.Encode(e); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+	return &buf
+}
+
+func TestShadowCompare_RuleChanges(t *testing.T) {
+	rules := fingerprint.DefaultRules()
+	rules.Weights = map[string]int{"browser-ua": 0, "http1.1": 2}
+	candidate := classifier.NewConfig(classifier.WithRules(rules))
+
+	report, err := shadow.Compare(shadowLog(t), classifier.DefaultConfig(), candidate)
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if report.Requests != 2 || report.Flipped != 1 || report.ToBot != 1 || report.ToBrowser != 0 {
+		t.Fatalf("report = %+v, want 2 requests with 1 browser -> bot flip", report)
+	}
+	if report.ScoreChanged != 1 {
+		t.Errorf("ScoreChanged = %d, want 1 (curl stays bot with a lower score)", report.ScoreChanged)
+	}
+
+	flip := report.Flips[0]
+	if flip.RequestID != "browser-1" || flip.Line != 1 || flip.From != "browser" || flip.To != "bot" {
+		t.Errorf("flip = %+v, want browser-1 on line 1 flipping browser -> bot", flip)
+	}
+	if flip.ThresholdOnly {
+		t.Error("a flip caused by rule changes should not be threshold-only")
+	}
+	want := []shadow.RuleChange{
+		{Rule: "browser-ua", Side: "browser", Change: shadow.ChangeRemoved, BaseWeight: 2},
+		{Rule: "http1.1", Side: "bot", Change: shadow.ChangeReweighted, BaseWeight: 1, CandidateWeight: 2},
+	}
+	if len(flip.Changes) != len(want) {
+		t.Fatalf("Changes = %+v, want %+v", flip.Changes, want)
+	}
+	for i := range want {
+		if flip.Changes[i] != want[i] {
+			t.Errorf("Changes[%d] = %+v, want %+v", i, flip.Changes[i], want[i])
+		}
+	}
+
+	if len(report.Rules) != 2 {
+		t.Fatalf("Rules = %+v, want 2 entries", report.Rules)
+	}
+	for _, imp := range report.Rules {
+		if imp.Flips != 1 || imp.ToBot != 1 {
+			t.Errorf("impact %+v, want 1 flip to bot", imp)
+		}
+	}
+}
+
+func TestShadowCompare_ThresholdOnly(t *testing.T) {
+	report, err := shadow.Compare(shadowLog(t), classifier.DefaultConfig(), classifier.NewConfig(classifier.WithThreshold(3)))
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if report.Flipped != 1 || report.ScoreChanged != 0 {
+		t.Fatalf("report = %+v, want 1 flip and no score changes", report)
+	}
+	if f := report.Flips[0]; !f.ThresholdOnly || len(f.Changes) != 0 {
+		t.Errorf("flip = %+v, want a threshold-only flip without rule changes", f)
+	}
+	if len(report.Rules) != 0 {
+		t.Errorf("Rules = %+v, want none", report.Rules)
+	}
+}
+
+func TestShadowCompare_SameConfig(t *testing.T) {
+	report, err := shadow.Compare(shadowLog(t), classifier.DefaultConfig(), classifier.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Compare() error = %v", err)
+	}
+	if report.Requests != 2 || report.Flipped != 0 || report.ScoreChanged != 0 {
+		t.Errorf("report = %+v, want no differences", report)
+	}
+}
+
+func TestShadowCompare_InvalidLog(t *testing.T) {
+	if _, err := shadow.Compare(bytes.NewBufferString("not json\n"), classifier.DefaultConfig(), classifier.DefaultConfig()); err == nil {
+		t.Error("Compare() should fail on an invalid log line")
+	}
+}
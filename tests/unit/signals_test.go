@@ -1,8 +1,15 @@
 package unit
 
 import (
+	"crypto/tls"
+	"encoding/json"
+	"net/http"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/psanford/tlsfingerprint"
 
 	"github.com/muliwe/go-client-classifier/internal/fingerprint"
 )
@@ -232,16 +239,16 @@ func TestCalculateScores_Breakdown(t *testing.T) {
 
 	s := fingerprint.ExtractSignals(fp)
 
-	if !strings.Contains(s.ScoreBreakdown, "BROWSER[") {
+	if !strings.Contains(s.ScoreBreakdown.String(), "BROWSER[") {
 		t.Error("Breakdown should contain BROWSER section")
 	}
-	if !strings.Contains(s.ScoreBreakdown, "BOT[") {
+	if !strings.Contains(s.ScoreBreakdown.String(), "BOT[") {
 		t.Error("Breakdown should contain BOT section")
 	}
-	if !strings.Contains(s.ScoreBreakdown, "http2") {
+	if !strings.Contains(s.ScoreBreakdown.String(), "http2") {
 		t.Error("Breakdown should mention http2")
 	}
-	if !strings.Contains(s.ScoreBreakdown, "sec-fetch") {
+	if !strings.Contains(s.ScoreBreakdown.String(), "sec-fetch") {
 		t.Error("Breakdown should mention sec-fetch")
 	}
 }
@@ -263,7 +270,711 @@ func TestCalculateScores_JA4HInconsistent(t *testing.T) {
 		t.Error("Should detect inconsistent JA4H signals")
 	}
 
-	if !strings.Contains(s.ScoreBreakdown, "ja4h-inconsistent") {
+	if !strings.Contains(s.ScoreBreakdown.String(), "ja4h-inconsistent") {
 		t.Error("Breakdown should mention JA4H inconsistency")
 	}
 }
+
+func TestExtractSignalsWithRules_Weights(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			Accept:      "*/*",
+			HeaderCount: 3,
+		},
+	}
+
+	rules := fingerprint.DefaultRules()
+	rules.Weights = map[string]int{"bot-ua": 10, "low-headers": 0}
+	s := fingerprint.ExtractSignalsWithRules(fp, rules)
+	def := fingerprint.ExtractSignals(fp)
+
+	if want := def.BotScore + 10 - 3 - 2; s.BotScore != want {
+		t.Errorf("BotScore = %d, want %d", s.BotScore, want)
+	}
+	if !strings.Contains(s.ScoreBreakdown.String(), "bot-ua(+10)") {
+		t.Errorf("breakdown should show overridden weight: %s", s.ScoreBreakdown)
+	}
+	if strings.Contains(s.ScoreBreakdown.String(), "low-headers") {
+		t.Errorf("zero weight should disable the rule: %s", s.ScoreBreakdown)
+	}
+}
+
+func TestExtractSignalsWithRules_CustomRules(t *testing.T) {
+	fp := fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:     "HTTP/1.1",
+			UserAgent:   "curl/8.0.1",
+			Accept:      "*/*",
+			HeaderCount: 3,
+			Headers:     map[string]string{"x-partner-key": "k1"},
+			HeaderOrder: []string{"host", "user-agent", "accept"},
+		},
+		TLS: fingerprint.TLSFingerprint{JA4Hash: "t13d1516h2_8daaf6152771_02713d6af862"},
+	}
+
+	tests := []struct {
+		signal    string
+		condition string
+		fires     bool
+	}{
+		{"ua_is_bot", "", true},
+		{"ua_is_bot", "== false", false},
+		{"has_sec_fetch_headers", "!= true", true},
+		{"http.header_count", "< 5", true},
+		{"http.header_count", ">= 5", false},
+		{"http.version", `== "HTTP/1.1"`, true},
+		{"http.user_agent", "contains curl", true},
+		{"http.header_order", "contains user-agent", true},
+		{"http.header_order", "contains cookie", false},
+		{"http.headers.x-partner-key", "== k1", true},
+		{"http.headers.X-Partner-Key", "", true},
+		{"http.headers.x-missing", "", false},
+		{"tls.ja4_hash", "contains t13d", true},
+		{"session.mean_interval_ms", "> 0", false},
+		{"network.private_relay", "", false},
+	}
+	for _, tt := range tests {
+		rule, err := fingerprint.NewCustomRule(fingerprint.ScoringRule{Name: "custom", Bot: true, Weight: 2}, tt.signal, tt.condition)
+		if err != nil {
+			t.Errorf("NewCustomRule(%q, %q) error = %v", tt.signal, tt.condition, err)
+			continue
+		}
+		if got := rule.Matches(fingerprint.ExtractSignals(fp), fp); got != tt.fires {
+			t.Errorf("%s %s: Matches() = %v, want %v", tt.signal, tt.condition, got, tt.fires)
+		}
+	}
+
+	rule, err := fingerprint.NewCustomRule(fingerprint.ScoringRule{Name: "lib-ua", Bot: true, Weight: 4}, "http.user_agent", "contains curl")
+	if err != nil {
+		t.Fatalf("NewCustomRule() error = %v", err)
+	}
+	rules := fingerprint.DefaultRules()
+	rules.Custom = []fingerprint.CustomRule{rule}
+	s := fingerprint.ExtractSignalsWithRules(fp, rules)
+	if want := fingerprint.ExtractSignals(fp).BotScore + 4; s.BotScore != want {
+		t.Errorf("BotScore = %d, want %d", s.BotScore, want)
+	}
+	if !strings.HasSuffix(s.ScoreBreakdown.String(), "lib-ua(+4)]") {
+		t.Errorf("custom rule should follow the built-in bot rules: %s", s.ScoreBreakdown)
+	}
+	if rules.Weight("lib-ua") != 4 {
+		t.Errorf("Weight(lib-ua) = %d, want 4", rules.Weight("lib-ua"))
+	}
+	if r, ok := rules.Lookup("lib-ua"); !ok || !r.Bot {
+		t.Errorf("Lookup(lib-ua) = %+v, %v", r, ok)
+	}
+
+	rules.Weights = map[string]int{"lib-ua": 0}
+	if s := fingerprint.ExtractSignalsWithRules(fp, rules); strings.Contains(s.ScoreBreakdown.String(), "lib-ua") {
+		t.Errorf("zero weight should disable the custom rule: %s", s.ScoreBreakdown)
+	}
+}
+
+func TestNewCustomRule_Invalid(t *testing.T) {
+	tests := []struct {
+		name      string
+		signal    string
+		condition string
+	}{
+		{"", "ua_is_bot", ""},
+		{"two words", "ua_is_bot", ""},
+		{"r", "no_such_signal", ""},
+		{"r", "bot_score", "> 3"},
+		{"r", "dns.ptr", ""},
+		{"r", "http.headers", "== x"},
+		{"r", "http.accept.x", ""},
+		{"r", "ua_is_bot", "== maybe"},
+		{"r", "ua_is_bot", "> true"},
+		{"r", "http.header_count", "== many"},
+		{"r", "http.header_count", "contains 1"},
+		{"r", "http.user_agent", "< b"},
+		{"r", "http.user_agent", `== "unterminated`},
+		{"r", "http.header_order", "== host"},
+	}
+	for _, tt := range tests {
+		if _, err := fingerprint.NewCustomRule(fingerprint.ScoringRule{Name: tt.name, Weight: 1}, tt.signal, tt.condition); err == nil {
+			t.Errorf("NewCustomRule(%q, %q, %q) should fail", tt.name, tt.signal, tt.condition)
+		}
+	}
+
+	if (fingerprint.CustomRule{}).Matches(fingerprint.Signals{}, fingerprint.Fingerprint{}) {
+		t.Error("zero CustomRule should never fire")
+	}
+}
+
+func TestExtractSignalsWithRules_Patterns(t *testing.T) {
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "AcmeMonitor/1.0"}}
+
+	if fingerprint.ExtractSignals(fp).UserAgentIsBot {
+		t.Fatal("AcmeMonitor should not match default bot patterns")
+	}
+	rules := fingerprint.DefaultRules()
+	rules.BotPatterns = append(rules.BotPatterns, "acmemonitor")
+	if !fingerprint.ExtractSignalsWithRules(fp, rules).UserAgentIsBot {
+		t.Error("custom bot pattern should match")
+	}
+}
+
+func TestExtractSignals_MatchedPatterns(t *testing.T) {
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+		UserAgent: "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; ClaudeBot/1.0; +claudebot@anthropic.com)",
+	}}
+	s := fingerprint.ExtractSignals(fp)
+
+	// Every matching pattern is recorded in list order, including the
+	// generic "bot" that the operator name also contains
+	if got, want := strings.Join(s.MatchedBotPatterns, ","), "bot,claudebot,anthropic"; got != want {
+		t.Errorf("MatchedBotPatterns = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(s.MatchedAICrawlerPatterns, ","), "claudebot,anthropic"; got != want {
+		t.Errorf("MatchedAICrawlerPatterns = %q, want %q", got, want)
+	}
+	if got, want := strings.Join(s.MatchedBrowserPatterns, ","), "mozilla"; got != want {
+		t.Errorf("MatchedBrowserPatterns = %q, want %q", got, want)
+	}
+
+	// An over-broad substring shows up by name
+	fp.HTTP.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/120.0.0.0 Safari/537.36 ForgotPassword/2.1"
+	if s := fingerprint.ExtractSignals(fp); strings.Join(s.MatchedBotPatterns, ",") != "got" || s.UserAgentIsBrowser {
+		t.Errorf("MatchedBotPatterns = %q, UserAgentIsBrowser = %v", s.MatchedBotPatterns, s.UserAgentIsBrowser)
+	}
+
+	fp.HTTP.UserAgent = "Mozilla/5.0 (Macintosh) Firefox/121.0"
+	if s := fingerprint.ExtractSignals(fp); s.MatchedBotPatterns != nil || s.MatchedAICrawlerPatterns != nil {
+		t.Errorf("browser matched %q / %q", s.MatchedBotPatterns, s.MatchedAICrawlerPatterns)
+	}
+}
+
+func TestScoringRules_MatchBreakdown(t *testing.T) {
+	for _, r := range fingerprint.ScoringRules() {
+		if r.Weight <= 0 {
+			t.Errorf("rule %s has non-positive default weight %d", r.Name, r.Weight)
+		}
+	}
+}
+
+func TestParseBreakdown(t *testing.T) {
+	const text = "BROWSER[http2(+2) sec-fetch(+3)] BOT[accept-*/*-(+1) bot-ua(-1)]"
+	b := fingerprint.ParseBreakdown(text)
+	browser, bot := b.Rules()
+	if strings.Join(browser, ",") != "http2,sec-fetch" {
+		t.Errorf("browser rules = %v", browser)
+	}
+	if strings.Join(bot, ",") != "accept-*/*-,bot-ua" {
+		t.Errorf("bot rules = %v", bot)
+	}
+	if _, botScores := b.Scores(); botScores[1] != (fingerprint.RuleScore{Name: "bot-ua", Weight: -1}) {
+		t.Errorf("bot scores = %v", botScores)
+	}
+	if got := b.String(); got != text {
+		t.Errorf("String() = %q, want %q", got, text)
+	}
+
+	b = fingerprint.ParseBreakdown("BROWSER[] BOT[]")
+	if len(b) != 0 || b.String() != "BROWSER[] BOT[]" {
+		t.Errorf("empty breakdown = %v (%q)", b, b.String())
+	}
+}
+
+func TestBreakdown_JSON(t *testing.T) {
+	s := fingerprint.ExtractSignals(fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: "curl/8.0.1", HeaderCount: 2}})
+	data, err := json.Marshal(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), `"score_breakdown":[{"name":"bot-ua","direction":"bot","weight":3}`) {
+		t.Errorf("score_breakdown is not a list of contributions: %s", data)
+	}
+	var back fingerprint.Signals
+	if err := json.Unmarshal(data, &back); err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(back.ScoreBreakdown, s.ScoreBreakdown) {
+		t.Errorf("round trip = %v, want %v", back.ScoreBreakdown, s.ScoreBreakdown)
+	}
+
+	// Empty breakdowns encode as an empty list
+	if data, _ := json.Marshal(fingerprint.Signals{}); !strings.Contains(string(data), `"score_breakdown":[]`) {
+		t.Errorf("empty breakdown = %s", data)
+	}
+
+	// Logs written before contributions hold the text form
+	if err := json.Unmarshal([]byte(`{"score_breakdown":"BROWSER[http2(+2)] BOT[bot-ua(+3)]"}`), &back); err != nil {
+		t.Fatal(err)
+	}
+	want := fingerprint.Breakdown{
+		{Name: "http2", Direction: fingerprint.DirectionBrowser, Weight: 2},
+		{Name: "bot-ua", Direction: fingerprint.DirectionBot, Weight: 3},
+	}
+	if !slices.Equal(back.ScoreBreakdown, want) {
+		t.Errorf("legacy breakdown = %v, want %v", back.ScoreBreakdown, want)
+	}
+}
+
+// safariPreflight is a CORS preflight as Safari sends it: few headers, */*
+func safariPreflight() fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version: "HTTP/1.1",
+			Method:  http.MethodOptions,
+			Headers: map[string]string{
+				"origin":                         "https://shop.example.com",
+				"access-control-request-method":  "POST",
+				"access-control-request-headers": "content-type",
+				"accept":                         "*/*",
+				"user-agent":                     testSafariUA,
+			},
+			HeaderCount: 5,
+			UserAgent:   testSafariUA,
+			Accept:      "*/*",
+		},
+	}
+}
+
+func TestExtractSignals_CORSPreflight(t *testing.T) {
+	fp := safariPreflight()
+	s := fingerprint.ExtractSignals(fp)
+	if !s.IsCORSPreflight || s.MalformedPreflight {
+		t.Fatalf("IsCORSPreflight = %v, MalformedPreflight = %v", s.IsCORSPreflight, s.MalformedPreflight)
+	}
+	if !strings.Contains(s.ScoreBreakdown.String(), "cors-preflight(+2)") {
+		t.Errorf("breakdown should credit the preflight: %s", s.ScoreBreakdown)
+	}
+	for _, rule := range []string{"accept-*/*-", "missing-typical"} {
+		if strings.Contains(s.ScoreBreakdown.String(), rule) {
+			t.Errorf("preflight penalized by %s: %s", rule, s.ScoreBreakdown)
+		}
+	}
+
+	// The same headers on a GET are penalized as before
+	fp.HTTP.Method = http.MethodGet
+	get := fingerprint.ExtractSignals(fp)
+	if get.IsCORSPreflight || !strings.Contains(get.ScoreBreakdown.String(), "accept-*/*-") {
+		t.Errorf("GET should not be treated as a preflight: %s", get.ScoreBreakdown)
+	}
+	if get.BrowserScore-get.BotScore >= s.BrowserScore-s.BotScore {
+		t.Errorf("preflight net score %d should beat GET %d", s.BrowserScore-s.BotScore, get.BrowserScore-get.BotScore)
+	}
+}
+
+func TestExtractSignals_MalformedPreflight(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*fingerprint.HTTPFingerprint)
+	}{
+		{"no origin", func(h *fingerprint.HTTPFingerprint) { delete(h.Headers, "origin") }},
+		{"headers without method", func(h *fingerprint.HTTPFingerprint) { delete(h.Headers, "access-control-request-method") }},
+		{"navigate mode", func(h *fingerprint.HTTPFingerprint) { h.SecFetchMode = "navigate" }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := safariPreflight()
+			tt.modify(&fp.HTTP)
+			s := fingerprint.ExtractSignals(fp)
+			if s.IsCORSPreflight || !s.MalformedPreflight {
+				t.Errorf("IsCORSPreflight = %v, MalformedPreflight = %v", s.IsCORSPreflight, s.MalformedPreflight)
+			}
+			if !strings.Contains(s.ScoreBreakdown.String(), "bad-preflight(+2)") {
+				t.Errorf("breakdown = %s", s.ScoreBreakdown)
+			}
+		})
+	}
+
+	// OPTIONS without Access-Control-Request-* is not a preflight at all
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{Method: http.MethodOptions, UserAgent: "curl/8.0"}}
+	if s := fingerprint.ExtractSignals(fp); s.IsCORSPreflight || s.MalformedPreflight {
+		t.Error("plain OPTIONS should set neither preflight signal")
+	}
+}
+
+func TestExtractSignals_HeadRequest(t *testing.T) {
+	fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{Method: http.MethodHead, UserAgent: "curl/8.0"}}
+	s := fingerprint.ExtractSignals(fp)
+	if !s.IsHeadRequest || !strings.Contains(s.ScoreBreakdown.String(), "head(+1)") {
+		t.Errorf("IsHeadRequest = %v, breakdown = %s", s.IsHeadRequest, s.ScoreBreakdown)
+	}
+}
+
+// interceptedChrome is Chrome's HTTP layer re-encrypted by a corporate
+// proxy: a short ClientHello without GREASE or session tickets
+func interceptedChrome() fingerprint.Fingerprint {
+	return fingerprint.Fingerprint{
+		HTTP: fingerprint.HTTPFingerprint{
+			Version:      "HTTP/1.1",
+			UserAgent:    "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36",
+			Accept:       "text/html,application/xhtml+xml",
+			AcceptLang:   "en-US,en;q=0.9",
+			AcceptEnc:    "gzip, deflate, br",
+			SecFetchSite: "none",
+			SecFetchMode: "navigate",
+			SecFetchDest: "document",
+			HeaderCount:  12,
+		},
+		TLS: fingerprint.TLSFingerprint{
+			Version:           "TLS 1.2",
+			CipherSuitesCount: 8,
+			ExtensionsCount:   6,
+			JA3Hash:           "0f14538e1c9070becdad7739c67d6363",
+			JA4Hash:           "t12d0806h1_1d37bd780c83_23c8bb3f2ffa",
+			Available:         true,
+			NoGREASE:          true,
+		},
+	}
+}
+
+func TestExtractSignals_TLSIntercepted(t *testing.T) {
+	fp := interceptedChrome()
+	s := fingerprint.ExtractSignals(fp)
+	if !s.TLSIntercepted {
+		t.Fatal("Chrome without GREASE should be seen as intercepted")
+	}
+	for _, rule := range []string{"low-ciphers", "few-tls-ext", "no-session"} {
+		if strings.Contains(s.ScoreBreakdown.String(), rule) {
+			t.Errorf("intercepted TLS should not score %s: %s", rule, s.ScoreBreakdown)
+		}
+	}
+
+	// The same ClientHello from a client without a browser HTTP layer is
+	// penalized as usual
+	bare := fp
+	bare.HTTP.UserAgent = "python-requests/2.32"
+	bare.HTTP.SecFetchSite, bare.HTTP.SecFetchMode, bare.HTTP.SecFetchDest = "", "", ""
+	if s := fingerprint.ExtractSignals(bare); s.TLSIntercepted || !strings.Contains(s.ScoreBreakdown.String(), "low-ciphers") {
+		t.Errorf("TLSIntercepted = %v, breakdown = %s", s.TLSIntercepted, s.ScoreBreakdown)
+	}
+
+	// Firefox sends no GREASE of its own
+	firefox := fp
+	firefox.HTTP.UserAgent = "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:131.0) Gecko/20100101 Firefox/131.0"
+	if fingerprint.ExtractSignals(firefox).TLSIntercepted {
+		t.Error("Firefox without GREASE should not be seen as intercepted")
+	}
+	firefox.TLS.LegacyCiphers = true
+	if !fingerprint.ExtractSignals(firefox).TLSIntercepted {
+		t.Error("Firefox offering DHE suites should be seen as intercepted")
+	}
+
+	// Known proxy fingerprints match whatever the ClientHello looks like
+	listed := fp
+	listed.TLS.NoGREASE = false
+	rules := fingerprint.DefaultRules()
+	if fingerprint.ExtractSignalsWithRules(listed, rules).TLSIntercepted {
+		t.Error("Chrome with GREASE should not be seen as intercepted")
+	}
+	rules.InterceptorFingerprints = []string{"T12D0806H1_1D37BD780C83_23C8BB3F2FFA"}
+	if !fingerprint.ExtractSignalsWithRules(listed, rules).TLSIntercepted {
+		t.Error("listed JA4 should be seen as intercepted")
+	}
+}
+
+func TestClientHelloFingerprint_InterceptionAnomalies(t *testing.T) {
+	browser := fingerprint.ClientHelloFingerprint(&tlsfingerprint.Fingerprint{
+		Version:      tls.VersionTLS13,
+		CipherSuites: []uint16{0x3a3a, 0x1301, 0x1302, 0x1303, 0xc02b},
+		Extensions:   []uint16{0x8a8a, 0, 23, 65281, 10, 11, 35, 16, 5, 13},
+	})
+	if browser.NoGREASE || browser.LegacyCiphers || !browser.GREASECiphers || !browser.GREASEExtensions {
+		t.Errorf("browser ClientHello: NoGREASE = %v, LegacyCiphers = %v, GREASECiphers = %v, GREASEExtensions = %v",
+			browser.NoGREASE, browser.LegacyCiphers, browser.GREASECiphers, browser.GREASEExtensions)
+	}
+
+	proxy := fingerprint.ClientHelloFingerprint(&tlsfingerprint.Fingerprint{
+		Version:      tls.VersionTLS12,
+		CipherSuites: []uint16{0xc02f, 0xc030, 0x009e, 0x009f, 0x00ff},
+		Extensions:   []uint16{0, 11, 10, 13},
+	})
+	if !proxy.NoGREASE || !proxy.LegacyCiphers {
+		t.Errorf("proxy ClientHello: NoGREASE = %v, LegacyCiphers = %v", proxy.NoGREASE, proxy.LegacyCiphers)
+	}
+}
+
+func TestExtractSignals_GREASE(t *testing.T) {
+	hello := func(ciphers, exts, groups []uint16) fingerprint.Fingerprint {
+		return fingerprint.Fingerprint{TLS: fingerprint.ClientHelloFingerprint(&tlsfingerprint.Fingerprint{
+			Version:         tls.VersionTLS13,
+			CipherSuites:    ciphers,
+			Extensions:      exts,
+			SupportedGroups: groups,
+		})}
+	}
+	ciphers := []uint16{0x1301, 0x1302, 0x1303}
+	exts := []uint16{0, 10, 11, 13, 16, 43, 51}
+	groups := []uint16{uint16(tls.X25519), uint16(tls.CurveP256)}
+	grease := func(v []uint16) []uint16 { return append([]uint16{0x4a4a}, v...) }
+
+	tests := []struct {
+		name                    string
+		fp                      fingerprint.Fingerprint
+		ciphers, exts, inGroups bool
+	}{
+		{"chrome", hello(grease(ciphers), grease(exts), grease(groups)), true, true, true},
+		{"ciphers only", hello(grease(ciphers), exts, groups), true, false, false},
+		{"go", hello(ciphers, exts, groups), false, false, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(tt.fp)
+			if s.HasGREASECiphers != tt.ciphers || s.HasGREASEExtensions != tt.exts || s.HasGREASEGroups != tt.inGroups {
+				t.Errorf("GREASE ciphers, extensions, groups = %v, %v, %v; want %v, %v, %v",
+					s.HasGREASECiphers, s.HasGREASEExtensions, s.HasGREASEGroups, tt.ciphers, tt.exts, tt.inGroups)
+			}
+			scored := strings.Contains(s.ScoreBreakdown.String(), "grease(+1)")
+			if want := tt.ciphers && tt.exts && tt.inGroups; scored != want {
+				t.Errorf("grease scored = %v, want %v: %s", scored, want, s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestExtractSignals_ECH(t *testing.T) {
+	hello := func(exts ...uint16) fingerprint.Fingerprint {
+		return fingerprint.Fingerprint{TLS: fingerprint.ClientHelloFingerprint(&tlsfingerprint.Fingerprint{
+			Version:      tls.VersionTLS13,
+			CipherSuites: []uint16{0x1301, 0x1302, 0x1303},
+			Extensions:   exts,
+		})}
+	}
+	tests := []struct {
+		name string
+		fp   fingerprint.Fingerprint
+		want bool
+	}{
+		{"ech grease", hello(0, 10, 13, 43, 51, 0xfe0d), true},
+		{"no ech", hello(0, 10, 13, 43, 51), false},
+		{"no clienthello", fingerprint.Fingerprint{}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(tt.fp)
+			if tt.fp.TLS.ECH != tt.want || s.HasECH != tt.want {
+				t.Errorf("ECH = %v, HasECH = %v, want %v", tt.fp.TLS.ECH, s.HasECH, tt.want)
+			}
+			if scored := strings.Contains(s.ScoreBreakdown.String(), "ech(+1)"); scored != tt.want {
+				t.Errorf("ech scored = %v, want %v: %s", scored, tt.want, s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestExtractSignals_SpoofedReferer(t *testing.T) {
+	for _, tt := range []struct {
+		name, referer, host, site string
+		spoofed                   bool
+	}{
+		{"same-origin page", "https://shop.example.com/cart", "shop.example.com", "same-origin", false},
+		{"same-origin with port", "https://localhost:8443/", "localhost:8443", "same-origin", false},
+		{"search engine", "https://www.google.com/", "shop.example.com", "cross-site", false},
+		{"android app", "android-app://com.google.android.gm/", "shop.example.com", "cross-site", false},
+		{"no Sec-Fetch-Site", "https://www.google.com/", "shop.example.com", "", false},
+		{"unknown host", "https://www.google.com/", "", "same-origin", false},
+		{"templated origin", "http://google.com", "shop.example.com", "cross-site", true},
+		{"relative", "/products/1", "shop.example.com", "same-origin", true},
+		{"not a URL", "google", "shop.example.com", "", true},
+		{"fragment", "https://www.google.com/#q=shoes", "shop.example.com", "cross-site", true},
+		{"credentials", "https://user:pw@www.google.com/", "shop.example.com", "cross-site", true},
+		{"typed URL", "https://www.google.com/", "shop.example.com", "none", true},
+		{"same-origin elsewhere", "https://www.google.com/", "shop.example.com", "same-origin", true},
+		{"cross-site from itself", "https://shop.example.com/", "shop.example.com", "cross-site", true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+				Host:         tt.host,
+				SecFetchSite: tt.site,
+				Headers:      map[string]string{"referer": tt.referer},
+				HasReferer:   true,
+			}}
+			s := fingerprint.ExtractSignals(fp)
+			if s.SpoofedReferer != tt.spoofed {
+				t.Errorf("SpoofedReferer = %v, want %v", s.SpoofedReferer, tt.spoofed)
+			}
+			if fired := strings.Contains(s.ScoreBreakdown.String(), "spoofed-referer(+2)"); fired != tt.spoofed {
+				t.Errorf("breakdown = %s", s.ScoreBreakdown)
+			}
+		})
+	}
+
+	if fingerprint.ExtractSignals(fingerprint.Fingerprint{}).SpoofedReferer {
+		t.Error("requests without Referer should not be flagged")
+	}
+}
+
+func TestExtractSignals_GeoLanguageMismatch(t *testing.T) {
+	tests := []struct {
+		country, acceptLang string
+		want                bool
+	}{
+		{"DE", "zh-CN,zh;q=0.9", true},
+		{"de", "zh-CN", true},
+		{"DE", "de-DE,de;q=0.9", false},
+		{"DE", "en-US,en;q=0.9", false}, // English fits everywhere
+		{"DE", "pt-DE,pt;q=0.9", false}, // Region matches
+		{"DE", "zh-CN,de;q=0.5", false}, // Any fitting tag suffices
+		{"LV", "ru-RU", false},          // Widely spoken
+		{"AQ", "zh-CN", false},          // Country not judged
+		{"", "zh-CN", false},            // Not looked up
+		{"DE", "", false},               // No Accept-Language
+		{"DE", "*", false},              // No language to judge
+	}
+	for _, tt := range tests {
+		fp := fingerprint.Fingerprint{
+			HTTP:    fingerprint.HTTPFingerprint{UserAgent: "Mozilla/5.0 (Windows NT 10.0; Win64; x64) Chrome/129.0.0.0", AcceptLang: tt.acceptLang},
+			Network: fingerprint.NetworkFingerprint{Country: tt.country},
+		}
+		s := fingerprint.ExtractSignals(fp)
+		if s.GeoLanguageMismatch != tt.want {
+			t.Errorf("%s / %q: GeoLanguageMismatch = %v, want %v", tt.country, tt.acceptLang, s.GeoLanguageMismatch, tt.want)
+		}
+		if got := strings.Contains(s.ScoreBreakdown.String(), "geo-lang-mismatch(+1)"); got != tt.want {
+			t.Errorf("%s / %q: breakdown = %s", tt.country, tt.acceptLang, s.ScoreBreakdown)
+		}
+	}
+
+	// The weight is configurable
+	rules := fingerprint.DefaultRules()
+	rules.Weights = map[string]int{"geo-lang-mismatch": 4}
+	fp := fingerprint.Fingerprint{
+		HTTP:    fingerprint.HTTPFingerprint{AcceptLang: "zh-CN"},
+		Network: fingerprint.NetworkFingerprint{Country: "DE"},
+	}
+	if s := fingerprint.ExtractSignalsWithRules(fp, rules); !strings.Contains(s.ScoreBreakdown.String(), "geo-lang-mismatch(+4)") {
+		t.Errorf("breakdown = %s", s.ScoreBreakdown)
+	}
+}
+
+func TestExtractSignals_NonCanonicalHeaderCase(t *testing.T) {
+	const chrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36"
+	tests := []struct {
+		name    string
+		version string
+		ua      string
+		names   []string
+		want    bool
+	}{
+		{"lowercase", "HTTP/1.1", chrome, []string{"host", "user-agent", "accept"}, true},
+		{"uppercase Host", "HTTP/1.1", chrome, []string{"HOST", "User-Agent", "Accept"}, true},
+		{"canonical", "HTTP/1.1", chrome, []string{"Host", "User-Agent", "Accept"}, false},
+		{"client hints", "HTTP/1.1", chrome, []string{"Host", "sec-ch-ua", "User-Agent"}, false}, // Chrome lowercases them
+		{"not captured", "HTTP/1.1", chrome, nil, false},
+		{"HTTP/2", "HTTP/2.0", chrome, []string{"user-agent"}, false},
+		{"library UA", "HTTP/1.1", "python-httpx/0.27.0", []string{"host", "user-agent"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{
+				Version:        tt.version,
+				UserAgent:      tt.ua,
+				RawHeaderNames: tt.names,
+			}}
+			s := fingerprint.ExtractSignals(fp)
+			if s.NonCanonicalHeaderCase != tt.want {
+				t.Errorf("NonCanonicalHeaderCase = %v, want %v", s.NonCanonicalHeaderCase, tt.want)
+			}
+			if got := strings.Contains(s.ScoreBreakdown.String(), "header-case(+2)"); got != tt.want {
+				t.Errorf("breakdown = %s", s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestExtractSignals_TCPOSMismatch(t *testing.T) {
+	const (
+		windowsChrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36"
+		macSafari     = "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.5 Safari/605.1.15"
+		androidChrome = "Mozilla/5.0 (Linux; Android 14; Pixel 8) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Mobile Safari/537.36"
+	)
+	syn := func(ttl uint8, window uint16, options []byte) fingerprint.TCPFingerprint {
+		tcp, err := fingerprint.ParseTCPSYN(synPacket("10.0.0.2", "10.0.0.1", 50000, 443, ttl, window, options))
+		if err != nil {
+			t.Fatalf("ParseTCPSYN() error = %v", err)
+		}
+		return tcp
+	}
+	windows := syn(117, 64240, windowsSYNOptions) // 11 hops from 128
+	linux := syn(52, 64240, linuxSYNOptions)
+	apple := syn(60, 65535, appleSYNOptions)
+
+	tests := []struct {
+		name string
+		ua   string
+		tcp  fingerprint.TCPFingerprint
+		want bool
+	}{
+		{"Windows Chrome from Linux", windowsChrome, linux, true},
+		{"Safari from Linux", macSafari, linux, true},
+		{"Windows Chrome from macOS", windowsChrome, apple, true},
+		{"Windows Chrome", windowsChrome, windows, false},
+		{"Safari", macSafari, apple, false},
+		{"Android", androidChrome, linux, false},
+		{"unknown stack", windowsChrome, syn(64, 1024, nil), false},
+		{"not captured", windowsChrome, fingerprint.TCPFingerprint{}, false},
+		{"library UA", "python-requests/2.31.0", linux, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: tt.ua}, TCP: tt.tcp})
+			if s.TCPOSMismatch != tt.want {
+				t.Errorf("TCPOSMismatch = %v, want %v", s.TCPOSMismatch, tt.want)
+			}
+			if got := strings.Contains(s.ScoreBreakdown.String(), "tcp-os-mismatch(+2)"); got != tt.want {
+				t.Errorf("breakdown = %s", s.ScoreBreakdown)
+			}
+		})
+	}
+
+	// A relay or intercepting proxy opened the connection
+	fp := fingerprint.Fingerprint{
+		HTTP:    fingerprint.HTTPFingerprint{UserAgent: macSafari},
+		Network: fingerprint.NetworkFingerprint{PrivateRelay: true},
+		TCP:     linux,
+	}
+	if fingerprint.ExtractSignals(fp).TCPOSMismatch {
+		t.Error("Private Relay connections should not be judged by their SYN")
+	}
+}
+
+func TestExtractSignals_ColocatedClient(t *testing.T) {
+	const chrome = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/129.0.0.0 Safari/537.36"
+	fp := func(ua string, latency int, local bool) fingerprint.Fingerprint {
+		tlsFP := fingerprint.TLSFingerprint{Available: true}
+		if latency > 0 {
+			tlsFP.LatencyMicros, tlsFP.JA4L = latency, fingerprint.JA4L(time.Duration(latency)*time.Microsecond, 0)
+		}
+		return fingerprint.Fingerprint{
+			TLS:     tlsFP,
+			HTTP:    fingerprint.HTTPFingerprint{UserAgent: ua},
+			Network: fingerprint.NetworkFingerprint{Local: local},
+		}
+	}
+
+	tests := []struct {
+		name string
+		fp   fingerprint.Fingerprint
+		want bool
+	}{
+		{"browser next door", fp(chrome, 300, false), true},
+		{"browser far away", fp(chrome, 18000, false), false},
+		{"not measured", fp(chrome, 0, false), false},
+		{"behind a load balancer", fp(chrome, 300, true), false},
+		{"library UA", fp("python-requests/2.31.0", 300, false), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := fingerprint.ExtractSignals(tt.fp)
+			if s.ColocatedClient != tt.want {
+				t.Errorf("ColocatedClient = %v, want %v", s.ColocatedClient, tt.want)
+			}
+			if got := strings.Contains(s.ScoreBreakdown.String(), "colocated(+1)"); got != tt.want {
+				t.Errorf("breakdown = %s", s.ScoreBreakdown)
+			}
+		})
+	}
+
+	relayed := fp(chrome, 300, false)
+	relayed.Network.PrivateRelay = true
+	if fingerprint.ExtractSignals(relayed).ColocatedClient {
+		t.Error("Private Relay egresses should not be judged by their latency")
+	}
+}
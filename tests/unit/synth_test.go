@@ -0,0 +1,105 @@
+package unit
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/dataset"
+	"github.com/muliwe/go-client-classifier/internal/synth"
+)
+
+func generate(t *testing.T, seed uint64, mix synth.Mix, n int) []dataset.Sample {
+	t.Helper()
+	g, err := synth.New(seed, mix)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	return g.Generate(n)
+}
+
+func TestSynthReproducible(t *testing.T) {
+	a := generate(t, 42, synth.DefaultMix(), 300)
+	b := generate(t, 42, synth.DefaultMix(), 300)
+	if !reflect.DeepEqual(a, b) {
+		t.Fatal("same seed and mix should generate the same corpus")
+	}
+
+	c := generate(t, 43, synth.DefaultMix(), 300)
+	if reflect.DeepEqual(a, c) {
+		t.Error("different seeds should generate different corpora")
+	}
+}
+
+func TestSynthDatasetRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, s := range generate(t, 1, synth.DefaultMix(), 500) {
+		if err := enc.Encode(s); err != nil {
+			t.Fatalf("Encode() error = %v", err)
+		}
+	}
+
+	samples, err := dataset.Read(&buf)
+	if err != nil {
+		t.Fatalf("dataset.Read() error = %v", err)
+	}
+	if len(samples) != 500 {
+		t.Fatalf("read %d samples, want 500", len(samples))
+	}
+
+	families := map[string]int{}
+	for _, s := range samples {
+		family, client, ok := strings.Cut(s.Note, "/")
+		if !ok || client == "" {
+			t.Fatalf("note %q should be family/client", s.Note)
+		}
+		families[family]++
+		if want := family == synth.FamilyBrowser; (s.Label == classifier.ClassificationBrowser) != want {
+			t.Errorf("%s labeled %s", s.Note, s.Label)
+		}
+		if s.Source != synth.Source {
+			t.Errorf("source = %q, want %q", s.Source, synth.Source)
+		}
+	}
+	for _, f := range synth.Families() {
+		if families[f] == 0 {
+			t.Errorf("default mix produced no %s samples", f)
+		}
+	}
+}
+
+func TestSynthMix(t *testing.T) {
+	mix, err := synth.ParseMix("ai_crawler=3, malformed=1")
+	if err != nil {
+		t.Fatalf("ParseMix() error = %v", err)
+	}
+	for _, s := range generate(t, 7, mix, 200) {
+		if !strings.HasPrefix(s.Note, synth.FamilyAICrawler+"/") && !strings.HasPrefix(s.Note, synth.FamilyMalformed+"/") {
+			t.Fatalf("sample %s outside the mix", s.Note)
+		}
+	}
+
+	for _, bad := range []string{"robots=1", "browser", "browser=x", "browser=-1", "browser=0", ""} {
+		if _, err := synth.ParseMix(bad); err == nil {
+			t.Errorf("ParseMix(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSynthBrowsersClassifyAsBrowser(t *testing.T) {
+	c := classifier.New()
+	samples := generate(t, 3, synth.Mix{synth.FamilyBrowser: 1}, 500)
+	browsers := 0
+	for _, s := range samples {
+		if c.Classify(s.Fingerprint).Classification == classifier.ClassificationBrowser {
+			browsers++
+		}
+	}
+	if browsers < len(samples)*95/100 {
+		t.Errorf("%d/%d synthetic browsers classified as browser, want at least 95%%", browsers, len(samples))
+	}
+}
@@ -0,0 +1,114 @@
+package unit
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/systemd"
+)
+
+// systemdChildEnv makes TestSystemd_Listeners act as the socket-activated
+// child process
+const systemdChildEnv = "CLASSIFIER_TEST_SYSTEMD_CHILD"
+
+func TestSystemd_NotActivated(t *testing.T) {
+	t.Setenv("LISTEN_PID", "")
+	t.Setenv("LISTEN_FDS", "")
+	if ls, err := systemd.Listeners(); ls != nil || err != nil {
+		t.Errorf("Listeners() = %v, %v; want nil without activation", ls, err)
+	}
+
+	// Variables meant for another process are ignored and cleared
+	t.Setenv("LISTEN_PID", strconv.Itoa(os.Getppid()))
+	t.Setenv("LISTEN_FDS", "1")
+	if ls, err := systemd.Listeners(); ls != nil || err != nil {
+		t.Errorf("Listeners() = %v, %v; want nil for another PID", ls, err)
+	}
+	if _, ok := os.LookupEnv("LISTEN_FDS"); ok {
+		t.Error("LISTEN_FDS should be removed from the environment")
+	}
+}
+
+func TestSystemd_Listeners(t *testing.T) {
+	if os.Getenv(systemdChildEnv) == "1" {
+		systemdChild()
+		return
+	}
+	if runtime.GOOS == "windows" {
+		t.Skip("socket activation is not available on Windows")
+	}
+
+	// Open two sockets and pass them as fds 3 and 4, as systemd does
+	var files []*os.File
+	var addrs []string
+	for range 2 {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			t.Fatalf("Listen: %v", err)
+		}
+		f, err := ln.(*net.TCPListener).File()
+		if err != nil {
+			t.Fatalf("File: %v", err)
+		}
+		_ = ln.Close()
+		defer func() { _ = f.Close() }()
+		files = append(files, f)
+		addrs = append(addrs, ln.Addr().String())
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=^TestSystemd_Listeners$")
+	cmd.ExtraFiles = files
+	// LISTEN_PID is set by the child itself: it is unknown before the fork
+	cmd.Env = append(os.Environ(), systemdChildEnv+"=1", "LISTEN_FDS=2", "LISTEN_FDNAMES=http:grpc")
+	out, err := cmd.Output()
+	if err != nil {
+		t.Fatalf("child failed: %v\n%s", err, out)
+	}
+
+	want := []string{"http " + addrs[0], "grpc " + addrs[1]}
+	var got []string
+	sc := bufio.NewScanner(strings.NewReader(string(out)))
+	for sc.Scan() {
+		if line, ok := strings.CutPrefix(sc.Text(), "socket "); ok {
+			got = append(got, line)
+		}
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("child received %v, want %v", got, want)
+	}
+}
+
+// systemdChild reports the sockets it received, and checks they accept
+// connections
+func systemdChild() {
+	_ = os.Setenv("LISTEN_PID", strconv.Itoa(os.Getpid()))
+	ls, err := systemd.Listeners()
+	if err != nil {
+		fmt.Println("error", err)
+		os.Exit(1)
+	}
+	for _, l := range ls {
+		conn, err := net.Dial("tcp", l.Addr().String())
+		if err != nil {
+			fmt.Println("error", err)
+			os.Exit(1)
+		}
+		_ = conn.Close()
+		if c, err := l.Accept(); err == nil {
+			_ = c.Close()
+		}
+		fmt.Println("socket", l.Name, l.Addr())
+		_ = l.Close()
+	}
+	if os.Getenv("LISTEN_FDS") != "" {
+		fmt.Println("error LISTEN_FDS still set")
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,116 @@
+package unit
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/internal/tcpsyn"
+)
+
+// synListener listens on loopback with SYN saving, skipping the test where
+// the platform lacks it
+func synListener(t *testing.T, r *tcpsyn.Recorder) net.Listener {
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	wrapped, err := r.Listen(ln)
+	if errors.Is(err, errors.ErrUnsupported) {
+		_ = ln.Close()
+		t.Skip("TCP_SAVE_SYN is not available on this platform")
+	}
+	if err != nil {
+		t.Fatalf("Recorder.Listen() error = %v", err)
+	}
+	return wrapped
+}
+
+func TestTCPSYN_Recorder(t *testing.T) {
+	r := tcpsyn.NewRecorder()
+	ln := synListener(t, r)
+	defer func() { _ = ln.Close() }()
+
+	client, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = client.Close() }()
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	tcp, err := fingerprint.ParseTCPSYN(r.SYN(conn.RemoteAddr()))
+	if err != nil {
+		t.Fatalf("ParseTCPSYN(saved SYN) error = %v", err)
+	}
+	if tcp.JA4T == "" || tcp.MSS == 0 || tcp.TTL == 0 {
+		t.Errorf("saved SYN = %+v", tcp)
+	}
+
+	_ = conn.Close()
+	if r.SYN(conn.RemoteAddr()) != nil || r.Len() != 0 {
+		t.Error("SYN kept after the connection closed")
+	}
+}
+
+func TestServer_TCPFingerprint(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	if _, err := tcpsyn.NewRecorder().Listen(ln); errors.Is(err, errors.ErrUnsupported) {
+		_ = ln.Close()
+		t.Skip("TCP_SAVE_SYN is not available on this platform")
+	}
+	srv, err := server.New(
+		server.WithListener(ln),
+		server.WithLogger(logger.Config{LogDir: t.TempDir(), FileName: "test.jsonl"}),
+		server.WithDebug(true),
+		server.WithTCPFingerprint(true),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	served := make(chan error, 1)
+	go func() { served <- srv.Serve() }()
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = srv.Shutdown(ctx)
+		if err := <-served; !errors.Is(err, http.ErrServerClosed) {
+			t.Errorf("Serve() error = %v", err)
+		}
+	}()
+
+	conn, err := net.Dial("tcp", ln.Addr().String())
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+	_, _ = conn.Write([]byte("GET /v1/debug HTTP/1.1\r\nHost: example.com\r\nUser-Agent: curl/8.0.1\r\nConnection: close\r\n\r\n"))
+	resp, err := http.ReadResponse(bufio.NewReader(conn), nil)
+	if err != nil {
+		t.Fatalf("ReadResponse: %v", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var result fingerprint.ClassificationResult
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if tcp := result.Fingerprint.TCP; !tcp.Available || tcp.JA4T == "" {
+		t.Errorf("TCP = %+v, want the SYN's JA4T", tcp)
+	}
+}
@@ -0,0 +1,423 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/muliwe/go-client-classifier/internal/classifier"
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logger"
+	"github.com/muliwe/go-client-classifier/internal/server"
+	"github.com/muliwe/go-client-classifier/internal/tenant"
+)
+
+const testTenantsYAML = `
+tenants:
+  - id: shop
+    api_keys: [k-shop]
+    hosts: [shop.example.com]
+    ruleset: strict.yaml
+    rate_limit: 1
+    burst: 2
+  - id: blog
+    hosts: [Blog.Example.com]
+    log_profile: minimal
+`
+
+// loadTestTenants writes a tenants file and a strict ruleset to a temp dir
+func loadTestTenants(t *testing.T) *tenant.Registry {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "strict.yaml"), []byte("version: \"1\"\npolicy:\n  threshold: 50\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "tenants.yaml")
+	if err := os.WriteFile(path, []byte(testTenantsYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	reg, err := tenant.Load(path, classifier.DefaultConfig())
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	return reg
+}
+
+func TestTenant_Resolve(t *testing.T) {
+	reg := loadTestTenants(t)
+	if len(reg.Tenants()) != 2 {
+		t.Fatalf("Tenants() = %d, want 2", len(reg.Tenants()))
+	}
+
+	tests := []struct {
+		name    string
+		host    string
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{"api key", "other.example.com", "k-shop", "shop", false},
+		{"host with port", "shop.example.com:8443", "", "shop", false},
+		{"host case-insensitive", "blog.example.COM", "", "blog", false},
+		{"no match", "other.example.com", "", "", false},
+		{"unknown key", "shop.example.com", "nope", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Host = tt.host
+			if tt.key != "" {
+				r.Header.Set(tenant.APIKeyHeader, tt.key)
+			}
+			got, err := reg.Resolve(r)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Resolve() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			id := ""
+			if got != nil {
+				id = got.ID
+			}
+			if id != tt.want {
+				t.Errorf("Resolve() = %q, want %q", id, tt.want)
+			}
+		})
+	}
+}
+
+func TestTenant_Ruleset(t *testing.T) {
+	reg := loadTestTenants(t)
+	browsers, err := fingerprint.GoldenFamily(fingerprint.GoldenBrowser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp := browsers[0].Fingerprint
+
+	if got := reg.Lookup("blog").Classifier.Classify(fp).Classification; got != classifier.ClassificationBrowser {
+		t.Errorf("blog (default rules) = %s, want browser", got)
+	}
+	if got := reg.Lookup("shop").Classifier.Classify(fp).Classification; got != classifier.ClassificationBot {
+		t.Errorf("shop (threshold 50) = %s, want bot", got)
+	}
+}
+
+func TestTenant_ParseErrors(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "tenants: []",
+		"unknown field":  "tenants:\n  - id: a\n    hosts: [a]\n    colour: red",
+		"bad id":         "tenants:\n  - id: ../a\n    hosts: [a]",
+		"default log id": "tenants:\n  - id: requests\n    hosts: [a]",
+		"default log ci": "tenants:\n  - id: Requests\n    hosts: [a]",
+		"no keys":        "tenants:\n  - id: a",
+		"duplicate id":   "tenants:\n  - id: a\n    hosts: [a]\n  - id: a\n    hosts: [b]",
+		"duplicate key":  "tenants:\n  - id: a\n    api_keys: [k]\n  - id: b\n    api_keys: [k]",
+		"duplicate host": "tenants:\n  - id: a\n    hosts: [x.com]\n  - id: b\n    hosts: [X.com:80]",
+		"negative rate":  "tenants:\n  - id: a\n    hosts: [a]\n    rate_limit: -1",
+		"bad profile":    "tenants:\n  - id: a\n    hosts: [a]\n    log_profile: verbose",
+		"missing rules":  "tenants:\n  - id: a\n    hosts: [a]\n    ruleset: missing.yaml",
+	}
+	for name, in := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := tenant.Parse([]byte(in), t.TempDir(), classifier.DefaultConfig()); err == nil {
+				t.Errorf("Parse() should fail")
+			}
+		})
+	}
+}
+
+func TestTenant_Limiter(t *testing.T) {
+	l := tenant.NewLimiter(2, 3)
+	now := time.Unix(1000, 0)
+	for i := range 3 {
+		if !l.Allow(now) {
+			t.Fatalf("request %d within burst denied", i)
+		}
+	}
+	if l.Allow(now) {
+		t.Error("request over burst allowed")
+	}
+	if !l.Allow(now.Add(500 * time.Millisecond)) {
+		t.Error("refilled token denied")
+	}
+	if l.Allow(now.Add(500 * time.Millisecond)) {
+		t.Error("second request after partial refill allowed")
+	}
+
+	// Default burst is the rate rounded up
+	l = tenant.NewLimiter(0.5, 0)
+	if !l.Allow(now) || l.Allow(now) {
+		t.Error("default burst should be 1")
+	}
+}
+
+func TestTenant_LimiterTake(t *testing.T) {
+	l := tenant.NewLimiter(2, 3)
+	now := time.Unix(1000, 0)
+
+	st := l.Take(now)
+	want := tenant.Status{Allowed: true, Limit: 3, Window: 1500 * time.Millisecond, Remaining: 2, Reset: 500 * time.Millisecond}
+	if st != want {
+		t.Errorf("first Take() = %+v, want %+v", st, want)
+	}
+	l.Take(now)
+	// The last token: none remain until the next refill
+	if st := l.Take(now); !st.Allowed || st.Remaining != 0 || st.Reset != 500*time.Millisecond {
+		t.Errorf("last token Take() = %+v", st)
+	}
+	if st := l.Take(now.Add(100 * time.Millisecond)); st.Allowed || st.Remaining != 0 || st.Reset != 400*time.Millisecond {
+		t.Errorf("empty bucket Take() = %+v", st)
+	}
+}
+
+func TestHandler_Tenants(t *testing.T) {
+	reg := loadTestTenants(t)
+	dir := t.TempDir()
+	shopLog, err := logger.New(logger.Config{LogDir: dir, FileName: "shop.jsonl"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = shopLog.Close() }()
+
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetTenants(reg, map[string]*logger.Logger{"shop": shopLog})
+
+	classify := func(key, host string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = host
+		if key != "" {
+			r.Header.Set(tenant.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		h.HandleClassify(w, r)
+		return w
+	}
+
+	// Burst of 2, then rate limited
+	for i := range 2 {
+		if w := classify("k-shop", ""); w.Code != http.StatusOK {
+			t.Fatalf("request %d: status = %d, want 200", i, w.Code)
+		}
+	}
+	w := classify("k-shop", "")
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") == "" {
+		t.Errorf("over limit: status = %d, Retry-After = %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if !strings.Contains(w.Body.String(), server.CodeRateLimited) {
+		t.Errorf("over limit body = %s", w.Body.String())
+	}
+
+	if w := classify("nope", ""); w.Code != http.StatusUnauthorized || !strings.Contains(w.Body.String(), server.CodeUnknownAPIKey) {
+		t.Errorf("unknown key: status = %d, body = %s", w.Code, w.Body.String())
+	}
+	if w := classify("", "blog.example.com"); w.Code != http.StatusOK {
+		t.Errorf("blog: status = %d", w.Code)
+	}
+
+	stats := func(key string) server.StatsResponse {
+		r := httptest.NewRequest(http.MethodGet, "/stats", nil)
+		if key != "" {
+			r.Header.Set(tenant.APIKeyHeader, key)
+		}
+		w := httptest.NewRecorder()
+		h.HandleStats(w, r)
+		var s server.StatsResponse
+		if err := json.NewDecoder(w.Body).Decode(&s); err != nil {
+			t.Fatalf("decode stats: %v", err)
+		}
+		return s
+	}
+	if s := stats("k-shop"); s.Tenant != "shop" || s.TotalRequests != 2 {
+		t.Errorf("shop stats = %+v, want 2 requests", s)
+	}
+	if s := stats(""); s.Tenant != "" || s.TotalRequests != 3 {
+		t.Errorf("global stats = %+v, want 3 requests", s)
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, "shop.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("shop log has %d entries, want 2", len(lines))
+	}
+	var entry logger.LogEntry
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry.Tenant != "shop" {
+		t.Errorf("log entry tenant = %q, want shop", entry.Tenant)
+	}
+	if err := logger.ValidateEntry(entry); err != nil {
+		t.Errorf("tenant log entry fails schema: %v", err)
+	}
+}
+
+func TestServerNew_TenantLogs(t *testing.T) {
+	dir := t.TempDir()
+	srv, err := server.New(
+		server.WithLogger(logger.Config{LogDir: dir, FileName: "requests.jsonl"}),
+		server.WithTenants(loadTestTenants(t)),
+	)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	if err := srv.Close(); err != nil {
+		t.Errorf("Close() error = %v", err)
+	}
+	for _, name := range []string{"requests.jsonl", "shop.jsonl", "blog.jsonl"} {
+		if _, err := os.Stat(filepath.Join(dir, name)); err != nil {
+			t.Errorf("log file %s: %v", name, err)
+		}
+	}
+
+	// A tenant must not share the default log
+	_, err = server.New(
+		server.WithLogger(logger.Config{LogDir: t.TempDir(), FileName: "shop.jsonl"}),
+		server.WithTenants(loadTestTenants(t)),
+	)
+	if err == nil || !strings.Contains(err.Error(), "tenant shop") {
+		t.Errorf("New() with a tenant logging to the default log: error = %v", err)
+	}
+}
+
+func TestHandler_Usage(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetTenants(loadTestTenants(t), nil)
+	h.SetAdminToken("s3cret")
+
+	for _, key := range []string{"k-shop", "k-shop", "k-shop", ""} {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Host = "shop.example.com"
+		if key != "" {
+			r.Header.Set(tenant.APIKeyHeader, key)
+		}
+		h.HandleClassify(httptest.NewRecorder(), r)
+	}
+
+	usage := func(key, format string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest(http.MethodGet, "/usage?format="+format, nil)
+		if key != "" {
+			r.Header.Set(tenant.APIKeyHeader, key)
+		} else {
+			r.Header.Set("Authorization", "Bearer s3cret")
+		}
+		w := httptest.NewRecorder()
+		h.HandleUsage(w, r)
+		return w
+	}
+
+	var resp server.UsageResponse
+	if err := json.NewDecoder(usage("k-shop", "").Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	// Burst of 2: two classified with the key, one rate limited; the Host
+	// request without a key is limited too
+	want := map[string]server.UsageRecord{
+		server.KeyID("k-shop"): {Requests: 2, RateLimited: 1},
+		"":                     {RateLimited: 1},
+	}
+	if len(resp.Records) != len(want) {
+		t.Fatalf("records = %+v, want %d", resp.Records, len(want))
+	}
+	for _, rec := range resp.Records {
+		w := want[rec.KeyID]
+		if rec.Tenant != "shop" || rec.Requests != w.Requests || rec.RateLimited != w.RateLimited || rec.Browser+rec.Bot != rec.Requests {
+			t.Errorf("record %+v, want %+v", rec, w)
+		}
+		if strings.Contains(rec.KeyID, "k-shop") {
+			t.Errorf("key_id %q leaks the API key", rec.KeyID)
+		}
+	}
+
+	w := usage("", "csv")
+	if ct := w.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/csv") {
+		t.Errorf("Content-Type = %q", ct)
+	}
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 3 || lines[0] != "tenant,key_id,requests,browser,bot,challenged,rate_limited,last_seen" {
+		t.Errorf("csv = %q", w.Body.String())
+	}
+
+	if w := usage("", "xml"); w.Code != http.StatusBadRequest {
+		t.Errorf("format=xml status = %d, want 400", w.Code)
+	}
+	if w := usage("nope", ""); w.Code != http.StatusUnauthorized {
+		t.Errorf("unknown key status = %d, want 401", w.Code)
+	}
+}
+
+func TestHandler_UsageAnonymous(t *testing.T) {
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetTenants(loadTestTenants(t), nil)
+	h.SetAdminToken("s3cret")
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(tenant.APIKeyHeader, "k-shop")
+	h.HandleClassify(httptest.NewRecorder(), r)
+
+	// The tenant's host alone must not expose its billing records
+	for _, host := range []string{"example.com", "shop.example.com"} {
+		for _, auth := range []string{"", "Bearer wrong"} {
+			r := httptest.NewRequest(http.MethodGet, "/usage", nil)
+			r.Host = host
+			if auth != "" {
+				r.Header.Set("Authorization", auth)
+			}
+			w := httptest.NewRecorder()
+			h.HandleUsage(w, r)
+			if w.Code != http.StatusUnauthorized {
+				t.Errorf("Host %s, Authorization %q: status = %d, want 401", host, auth, w.Code)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+				t.Errorf("Host %s, Authorization %q: Content-Type = %q", host, auth, ct)
+			}
+			if strings.Contains(w.Body.String(), "k-shop") || strings.Contains(w.Body.String(), `"records"`) {
+				t.Errorf("Host %s, Authorization %q: body leaks tenant records: %s", host, auth, w.Body.String())
+			}
+		}
+	}
+
+	// Without tenants there is nothing to separate
+	h = createTestHandler()
+	w := httptest.NewRecorder()
+	h.HandleUsage(w, httptest.NewRequest(http.MethodGet, "/usage", nil))
+	if w.Code != http.StatusOK {
+		t.Errorf("single-tenant status = %d, want 200", w.Code)
+	}
+}
+
+func TestHandler_TenantLogProfile(t *testing.T) {
+	reg := loadTestTenants(t)
+	dir := t.TempDir()
+	blogLog, err := logger.New(logger.Config{LogDir: dir, FileName: "blog.jsonl", Profile: logger.Profile(reg.Lookup("blog").LogProfile)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = blogLog.Close() }()
+
+	h := createTestHandler()
+	h.SetQuiet(true)
+	h.SetTenants(reg, map[string]*logger.Logger{"blog": blogLog})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Host = "blog.example.com"
+	r.RemoteAddr = "198.51.100.23:5555"
+	r.Header.Set("User-Agent", "curl/8.0")
+	h.HandleClassify(httptest.NewRecorder(), r)
+
+	data, err := os.ReadFile(filepath.Join(dir, "blog.jsonl"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) == 0 || strings.Contains(string(data), "198.51.100.23") || strings.Contains(string(data), "curl/8.0") {
+		t.Errorf("minimal tenant log = %s", data)
+	}
+}
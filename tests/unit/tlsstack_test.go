@@ -0,0 +1,96 @@
+package unit
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+)
+
+const chromeUA = "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/131.0.0.0 Safari/537.36"
+
+func TestExtractSignals_TLSStack_Golden(t *testing.T) {
+	entries, err := fingerprint.Golden()
+	if err != nil {
+		t.Fatalf("Golden() error = %v", err)
+	}
+	wantStacks := map[string]string{
+		"chrome": "chromium", "edge": "chromium", "firefox": "firefox", "safari": "safari",
+		"curl": "openssl", "python": "openssl", "node": "openssl", "go": "go", "wget": "gnutls",
+	}
+	for _, e := range entries {
+		if !e.Fingerprint.TLS.Available {
+			continue
+		}
+		t.Run(e.ID, func(t *testing.T) {
+			client, _, _ := strings.Cut(e.ID, "-")
+			s := fingerprint.ExtractSignals(e.Fingerprint)
+			if s.TLSStack != wantStacks[client] {
+				t.Errorf("TLSStack = %q, want %q", s.TLSStack, wantStacks[client])
+			}
+			if s.TLSImpersonation {
+				t.Error("client flagged for impersonating itself")
+			}
+		})
+	}
+}
+
+func TestExtractSignals_TLSImpersonation(t *testing.T) {
+	entries, err := fingerprint.Golden()
+	if err != nil {
+		t.Fatalf("Golden() error = %v", err)
+	}
+	for _, e := range entries {
+		if e.Family != fingerprint.GoldenLibrary {
+			continue
+		}
+		// A scraper sending a browser's User-Agent from its library
+		t.Run(e.ID, func(t *testing.T) {
+			fp := e.Fingerprint
+			fp.HTTP.UserAgent = chromeUA
+			s := fingerprint.ExtractSignals(fp)
+			if !s.TLSImpersonation {
+				t.Fatalf("TLSImpersonation = false, TLSStack = %q", s.TLSStack)
+			}
+			if !strings.Contains(s.ScoreBreakdown.String(), "tls-impersonation(+4)") {
+				t.Errorf("tls-impersonation not scored: %s", s.ScoreBreakdown)
+			}
+		})
+	}
+}
+
+func TestExtractSignals_TLSImpersonation_NotVouched(t *testing.T) {
+	goTLS := fingerprint.TLSFingerprint{
+		Available: true,
+		JA3Hash:   "03117a8ed39ef02427ebbc39f121275c",
+		JA4Hash:   "t13d1312h2_f57a46bbacb6_f50d94e863eb",
+	}
+	tests := []struct {
+		name string
+		ua   string
+		tls  fingerprint.TLSFingerprint
+		want bool
+	}{
+		{"chrome over go", chromeUA, goTLS, true},
+		{"old chrome over go", "Mozilla/5.0 (Windows NT 6.1) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/49.0.2623.112 Safari/537.36", goTLS, false},
+		{"go client", "Go-http-client/2.0", goTLS, false},
+		{"unknown stack", chromeUA, fingerprint.TLSFingerprint{Available: true, JA4Hash: "t13d1516h2_000000000000_000000000000"}, false},
+		{"chrome on ios", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) CriOS/120.0.6099.119 Mobile/15E148 Safari/604.1",
+			fingerprint.TLSFingerprint{Available: true, JA4Hash: "t13d2014h2_a09f3c656075_14788d8d241b"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fp := fingerprint.Fingerprint{HTTP: fingerprint.HTTPFingerprint{UserAgent: tt.ua}, TLS: tt.tls}
+			if s := fingerprint.ExtractSignals(fp); s.TLSImpersonation != tt.want {
+				t.Errorf("TLSImpersonation = %v (stack %q), want %v", s.TLSImpersonation, s.TLSStack, tt.want)
+			}
+		})
+	}
+
+	// A corporate proxy's ClientHello is not the browser's
+	fp := interceptedChrome()
+	fp.TLS.JA4Hash = "t13d3112h2_e8f1e7e78f70_b26ce05bbdd6"
+	if s := fingerprint.ExtractSignals(fp); !s.TLSIntercepted || s.TLSImpersonation {
+		t.Errorf("intercepted: TLSIntercepted = %v, TLSImpersonation = %v", s.TLSIntercepted, s.TLSImpersonation)
+	}
+}
@@ -0,0 +1,81 @@
+package unit
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/fingerprint"
+	"github.com/muliwe/go-client-classifier/internal/logq"
+)
+
+func TestParseUserAgent(t *testing.T) {
+	tests := []struct {
+		name string
+		ua   string
+		want fingerprint.ParsedUserAgent
+	}{
+		{"chrome windows", chromeWindowsUA,
+			fingerprint.ParsedUserAgent{Family: "Chrome", Major: 124, OS: "Windows", OSVersion: "10", Device: fingerprint.DeviceDesktop}},
+		{"chrome android", chromeAndroidUA,
+			fingerprint.ParsedUserAgent{Family: "Chrome", Major: 124, OS: "Android", OSVersion: "10", Device: fingerprint.DeviceMobile}},
+		{"edge", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36 Edg/124.0.2478.80",
+			fingerprint.ParsedUserAgent{Family: "Edge", Major: 124, OS: "Windows", OSVersion: "10", Device: fingerprint.DeviceDesktop}},
+		{"firefox linux", "Mozilla/5.0 (X11; Linux x86_64; rv:125.0) Gecko/20100101 Firefox/125.0",
+			fingerprint.ParsedUserAgent{Family: "Firefox", Major: 125, OS: "Linux", Device: fingerprint.DeviceDesktop}},
+		{"safari macos", "Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4.1 Safari/605.1.15",
+			fingerprint.ParsedUserAgent{Family: "Safari", Major: 17, OS: "macOS", OSVersion: "10.15.7", Device: fingerprint.DeviceDesktop}},
+		{"safari iphone", "Mozilla/5.0 (iPhone; CPU iPhone OS 17_4 like Mac OS X) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.4 Mobile/15E148 Safari/604.1",
+			fingerprint.ParsedUserAgent{Family: "Safari", Major: 17, OS: "iOS", OSVersion: "17.4", Device: fingerprint.DeviceMobile}},
+		{"android tablet", "Mozilla/5.0 (Linux; Android 13; SM-X700) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36",
+			fingerprint.ParsedUserAgent{Family: "Chrome", Major: 124, OS: "Android", OSVersion: "13", Device: fingerprint.DeviceTablet}},
+		{"internet explorer", "Mozilla/5.0 (Windows NT 6.1; Trident/7.0; rv:11.0) like Gecko",
+			fingerprint.ParsedUserAgent{Family: "Internet Explorer", Major: 11, OS: "Windows", OSVersion: "7", Device: fingerprint.DeviceDesktop}},
+		{"googlebot", "Mozilla/5.0 (compatible; Googlebot/2.1; +http://www.google.com/bot.html)",
+			fingerprint.ParsedUserAgent{Family: "Googlebot", Major: 2, Device: fingerprint.DeviceBot}},
+		{"gptbot", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)",
+			fingerprint.ParsedUserAgent{Family: "GPTBot", Major: 1, Device: fingerprint.DeviceBot}},
+		{"curl", "curl/8.4.0", fingerprint.ParsedUserAgent{Family: "curl", Major: 8}},
+		{"empty", "", fingerprint.ParsedUserAgent{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fingerprint.ParseUserAgent(tt.ua); got != tt.want {
+				t.Errorf("ParseUserAgent() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCollect_UserAgentParsed(t *testing.T) {
+	req := httptest.NewRequest("GET", "https://example.com/", nil)
+	req.Header.Set("User-Agent", chromeWindowsUA)
+
+	got := fingerprint.NewCollector().Collect(req).HTTP.UserAgentParsed
+	if got.Family != "Chrome" || got.Major != 124 || got.OS != "Windows" {
+		t.Errorf("UserAgentParsed = %+v, want Chrome 124 on Windows", got)
+	}
+}
+
+func TestLogq_CountByBrowserAndOS(t *testing.T) {
+	res, err := logq.Run(logqLog(t), logq.Query{GroupBy: logq.ByBrowser})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	want := map[string]int{"GPTBot 1": 2, "Chrome 131": 1, "curl 8": 1}
+	if len(res.Groups) != len(want) {
+		t.Fatalf("groups = %+v, want %v", res.Groups, want)
+	}
+	for _, g := range res.Groups {
+		if want[g.Key] != g.Count {
+			t.Errorf("group %q count = %d, want %d", g.Key, g.Count, want[g.Key])
+		}
+	}
+
+	res, err = logq.Run(logqLog(t), logq.Query{GroupBy: logq.ByOS})
+	if err != nil {
+		t.Fatalf("Run() error = %v", err)
+	}
+	if len(res.Groups) != 1 || res.Groups[0].Key != logq.NoValue || res.Groups[0].Count != 4 {
+		t.Errorf("groups = %+v, want all entries without an OS", res.Groups)
+	}
+}
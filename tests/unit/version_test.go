@@ -0,0 +1,56 @@
+package unit
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"slices"
+	"testing"
+
+	"github.com/muliwe/go-client-classifier/internal/buildinfo"
+	"github.com/muliwe/go-client-classifier/internal/server"
+)
+
+func TestBuildinfo_Get(t *testing.T) {
+	info := buildinfo.Get()
+	if info.Version == "" {
+		t.Error("Version should fall back to dev, not be empty")
+	}
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("GoVersion = %q, want %q", info.GoVersion, runtime.Version())
+	}
+	if buildinfo.Version() != info.Version {
+		t.Errorf("Version() = %q, Get().Version = %q", buildinfo.Version(), info.Version)
+	}
+}
+
+func TestHandler_Version(t *testing.T) {
+	h := createTestHandler()
+	h.SetTenants(loadTestTenants(t), nil)
+	h.SetFeatures([]string{"session_tracking", "tls"})
+
+	rr := httptest.NewRecorder()
+	h.HandleVersion(rr, httptest.NewRequest(http.MethodGet, "/v1/version", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("status = %d", rr.Code)
+	}
+
+	var resp server.VersionResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if resp.Version != buildinfo.Version() || resp.GoVersion != runtime.Version() {
+		t.Errorf("build info = %+v", resp.Info)
+	}
+	if resp.Ruleset != server.BuiltinRuleset {
+		t.Errorf("Ruleset = %q, want %q", resp.Ruleset, server.BuiltinRuleset)
+	}
+	// shop has a ruleset with version "1"; blog uses the server defaults
+	if resp.TenantRulesets["shop"] != "1" || resp.TenantRulesets["blog"] != server.BuiltinRuleset {
+		t.Errorf("TenantRulesets = %v", resp.TenantRulesets)
+	}
+	if !slices.Equal(resp.Features, []string{"session_tracking", "tls"}) {
+		t.Errorf("Features = %v", resp.Features)
+	}
+}
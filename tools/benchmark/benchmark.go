@@ -1,97 +1,204 @@
-// Package main provides a simple HTTP benchmark tool
+// Package main provides a simple HTTP benchmark tool.
+//
+// Requests carry the header set of a client profile (-profile), and the
+// classification returned by the server is tallied per label. With
+// -tls-hello, TLS handshakes emulate a real browser ClientHello via uTLS.
+// With -rate, requests are sent on a fixed schedule (open loop) and
+// latencies are also reported from the scheduled send time, so a server
+// that stalls cannot hide the stall by slowing the load down.
+// With -scenario, a YAML file defines a weighted mix of clients with
+// request sequences, think times and ramping concurrency stages, and the
+// results are broken down per client.
+// With -output json or csv, the full results are written to stdout in a
+// machine-readable form for comparing runs across versions; the progress
+// output then goes to stderr.
 package main
 
 import (
-	"crypto/tls"
 	"flag"
 	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"os"
+	"slices"
+	"sort"
+	"strings"
 	"sync"
-	"sync/atomic"
 	"time"
 )
 
+// distribution counts classification labels returned by the server
+type distribution struct {
+	mu     sync.Mutex
+	counts map[string]int64
+}
+
+func (d *distribution) add(label string) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.counts[label]++
+}
+
+// snapshot returns the labels sorted by count (descending) with their counts
+func (d *distribution) snapshot() ([]string, map[string]int64, int64) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	counts := make(map[string]int64, len(d.counts))
+	labels := make([]string, 0, len(d.counts))
+	var total int64
+	for label, n := range d.counts {
+		counts[label] = n
+		labels = append(labels, label)
+		total += n
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if counts[labels[i]] != counts[labels[j]] {
+			return counts[labels[i]] > counts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	return labels, counts, total
+}
+
+// classifyResponse is the part of the server response we tally
+type classifyResponse struct {
+	Classification string `json:"classification"`
+}
+
+// newRequest builds a request carrying the profile's headers
+func newRequest(method, url, body string, p profile) (*http.Request, error) {
+	var rd io.Reader = http.NoBody
+	if body != "" {
+		rd = strings.NewReader(body)
+	}
+	req, err := http.NewRequest(method, url, rd)
+	if err != nil {
+		return nil, err
+	}
+	for _, h := range p.Headers {
+		req.Header.Add(h.Name, h.Value)
+	}
+	if req.Header.Get("User-Agent") == "" && p.Name != "go" {
+		// Suppress Go's default User-Agent for profiles that don't set one
+		req.Header.Set("User-Agent", "")
+	}
+	return req, nil
+}
+
 func main() {
 	url := flag.String("url", "http://localhost:8080/", "Target URL")
 	duration := flag.Duration("duration", 10*time.Second, "Test duration")
 	concurrency := flag.Int("c", 10, "Number of concurrent workers")
 	insecure := flag.Bool("insecure", false, "Skip TLS certificate verification")
+	profileName := flag.String("profile", "go", "Client profile ("+strings.Join(profileNames(), ", ")+") or a header file with 'Name: value' lines")
+	tlsHello := flag.String("tls-hello", "", "Emulate a browser ClientHello with uTLS ("+strings.Join(tlsHelloNames(), ", ")+"); https only, protocol follows ALPN")
+	proto := flag.String("proto", protoHTTP1, "HTTP protocol ("+strings.Join(protoNames, ", ")+"); h2 uses h2c for http URLs, h3 requires https")
+	rate := flag.Float64("rate", 0, "Send at a constant rate of N requests/s (open loop, -c caps requests in flight); 0 sends back to back per worker")
+	keepAlive := flag.Bool("keepalive", true, "Reuse connections; false opens a new connection per request")
+	output := flag.String("output", outputText, "Result format ("+strings.Join(outputNames, ", ")+"); json and csv write the report to stdout and progress to stderr")
+	scenarioFile := flag.String("scenario", "", "Scenario YAML file (overrides -duration, -c, -rate, -profile, -tls-hello, -proto and -keepalive; -url overrides the scenario url)")
 	flag.Parse()
 
-	fmt.Printf("Benchmarking %s\n", *url)
-	fmt.Printf("Duration: %v, Concurrency: %d\n\n", *duration, *concurrency)
+	if !slices.Contains(outputNames, *output) {
+		log.Fatalf("Error: unknown output format %q (available: %s)", *output, strings.Join(outputNames, ", "))
+	}
+	if *output != outputText {
+		console = os.Stderr
+	}
 
-	// Create HTTP client
-	tr := &http.Transport{
-		MaxIdleConns:        *concurrency * 2,
-		MaxIdleConnsPerHost: *concurrency * 2,
-		IdleConnTimeout:     90 * time.Second,
+	if *scenarioFile != "" {
+		baseURL := ""
+		flag.Visit(func(f *flag.Flag) {
+			if f.Name == "url" {
+				baseURL = *url
+			}
+		})
+		sc, err := loadScenario(*scenarioFile, baseURL)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if err := runScenario(sc, *insecure); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		rep := scenarioReport(sc, sc.duration())
+		if *output == outputText {
+			printScenarioResults(os.Stdout, sc, sc.duration())
+		} else if err := writeReport(os.Stdout, *output, rep); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if rep.Errors > 0 {
+			os.Exit(1)
+		}
+		return
 	}
-	if *insecure {
-		tr.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} //nolint:gosec
+
+	if *rate < 0 {
+		log.Fatalf("Error: -rate must not be negative")
+	}
+
+	prof, err := loadProfile(*profileName)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	probe, err := newRequest(http.MethodGet, *url, "", prof)
+	if err != nil {
+		log.Fatalf("Error: invalid request: %v", err)
+	}
+	opts := transportOptions{
+		proto:     *proto,
+		tlsHello:  *tlsHello,
+		insecure:  *insecure,
+		keepAlive: *keepAlive,
+		maxIdle:   *concurrency * 2,
+	}
+	if err := opts.validate(probe.URL.Scheme); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+
+	fmt.Fprintf(console, "Benchmarking %s\n", *url)
+	fmt.Fprintf(console, "Duration: %v, Concurrency: %d, Profile: %s", *duration, *concurrency, prof.Name)
+	if *rate > 0 {
+		fmt.Fprintf(console, ", Rate: %.2f req/s", *rate)
+	}
+	if *tlsHello != "" {
+		fmt.Fprintf(console, ", TLS hello: %s", *tlsHello)
+	} else {
+		fmt.Fprintf(console, ", Protocol: %s", *proto)
+	}
+	if !*keepAlive {
+		fmt.Fprint(console, ", new connection per request")
+	}
+	fmt.Fprint(console, "\n\n")
+
+	transport, err := newTransport(opts)
+	if err != nil {
+		log.Fatalf("Error: %v", err)
 	}
 	client := &http.Client{
-		Transport: tr,
+		Transport: transport,
 		Timeout:   5 * time.Second,
 	}
 
-	var (
-		totalRequests int64
-		totalErrors   int64
-		totalLatency  int64 // in microseconds
-		minLatency    int64 = 1<<63 - 1
-		maxLatency    int64
-		wg            sync.WaitGroup
-		stop          = make(chan struct{})
-	)
-
-	// Start workers
-	for i := 0; i < *concurrency; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for {
-				select {
-				case <-stop:
-					return
-				default:
-					start := time.Now()
-					resp, err := client.Get(*url)
-					latency := time.Since(start).Microseconds()
-
-					if err != nil {
-						atomic.AddInt64(&totalErrors, 1)
-					} else {
-						_, _ = io.Copy(io.Discard, resp.Body)
-						_ = resp.Body.Close()
-
-						if resp.StatusCode == http.StatusOK {
-							atomic.AddInt64(&totalRequests, 1)
-							atomic.AddInt64(&totalLatency, latency)
-
-							// Update min/max (approximate, not perfectly thread-safe)
-							for {
-								old := atomic.LoadInt64(&minLatency)
-								if latency >= old || atomic.CompareAndSwapInt64(&minLatency, old, latency) {
-									break
-								}
-							}
-							for {
-								old := atomic.LoadInt64(&maxLatency)
-								if latency <= old || atomic.CompareAndSwapInt64(&maxLatency, old, latency) {
-									break
-								}
-							}
-						} else {
-							atomic.AddInt64(&totalErrors, 1)
-						}
-					}
-				}
-			}
-		}()
+	reportServerFingerprint(client, *url, prof)
+
+	rec := newRecorder()
+	newReq := func() *http.Request {
+		req, _ := newRequest(http.MethodGet, *url, "", prof)
+		return req
 	}
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	start := time.Now()
+	go func() {
+		defer close(done)
+		if *rate > 0 {
+			runOpenLoop(client, newReq, *rate, *concurrency, start, stop, rec)
+		} else {
+			runClosedLoop(client, newReq, *concurrency, stop, rec)
+		}
+	}()
 
 	// Progress ticker
 	ticker := time.NewTicker(time.Second)
@@ -99,10 +206,12 @@ func main() {
 		elapsed := 0
 		for range ticker.C {
 			elapsed++
-			reqs := atomic.LoadInt64(&totalRequests)
-			errs := atomic.LoadInt64(&totalErrors)
-			fmt.Printf("[%ds] Requests: %d, Errors: %d, RPS: %.0f\n",
-				elapsed, reqs, errs, float64(reqs)/float64(elapsed))
+			reqs, errs := rec.requests.Load(), rec.errors.Load()
+			fmt.Fprintf(console, "[%ds] Requests: %d, Errors: %d, RPS: %.0f", elapsed, reqs, errs, float64(reqs)/float64(elapsed))
+			if *rate > 0 {
+				fmt.Fprintf(console, ", Behind schedule: %d", max(int64(float64(elapsed)**rate)-rec.started.Load(), 0))
+			}
+			fmt.Fprintln(console)
 		}
 	}()
 
@@ -110,37 +219,81 @@ func main() {
 	time.Sleep(*duration)
 	close(stop)
 	ticker.Stop()
-	wg.Wait()
-
-	// Results
-	reqs := atomic.LoadInt64(&totalRequests)
-	errs := atomic.LoadInt64(&totalErrors)
-	latencyTotal := atomic.LoadInt64(&totalLatency)
-	minLat := atomic.LoadInt64(&minLatency)
-	maxLat := atomic.LoadInt64(&maxLatency)
+	<-done
 
-	avgLatency := float64(0)
-	if reqs > 0 {
-		avgLatency = float64(latencyTotal) / float64(reqs)
+	rep := singleReport(rec, *url, *duration, *concurrency, *rate, prof, opts)
+	if *output == outputText {
+		printResults(os.Stdout, rec, *duration, *concurrency, *rate, prof.Name)
+	} else if err := writeReport(os.Stdout, *output, rep); err != nil {
+		log.Fatalf("Error: %v", err)
 	}
+	if rep.Errors > 0 {
+		os.Exit(1)
+	}
+}
 
+// printResults writes the results of a single-profile run
+func printResults(w io.Writer, rec *recorder, duration time.Duration, concurrency int, rate float64, profileName string) {
+	reqs, errs := rec.requests.Load(), rec.errors.Load()
+	service, corrected := rec.latencies()
 	rps := float64(reqs) / duration.Seconds()
-	rpm := rps * 60
-
-	fmt.Println("\n========== RESULTS ==========")
-	fmt.Printf("Total requests:  %d\n", reqs)
-	fmt.Printf("Total errors:    %d\n", errs)
-	fmt.Printf("Duration:        %v\n", *duration)
-	fmt.Printf("Concurrency:     %d\n", *concurrency)
-	fmt.Println()
-	fmt.Printf("RPS:             %.2f\n", rps)
-	fmt.Printf("RPM:             %.0f\n", rpm)
-	fmt.Println()
-	fmt.Printf("Latency avg:     %.2f µs (%.3f ms)\n", avgLatency, avgLatency/1000)
-	fmt.Printf("Latency min:     %d µs (%.3f ms)\n", minLat, float64(minLat)/1000)
-	fmt.Printf("Latency max:     %d µs (%.3f ms)\n", maxLat, float64(maxLat)/1000)
-
-	if errs > 0 {
-		os.Exit(1)
+
+	fmt.Fprintln(w, "\n========== RESULTS ==========")
+	fmt.Fprintf(w, "Total requests:  %d\n", reqs)
+	fmt.Fprintf(w, "Total errors:    %d\n", errs)
+	if _, counts, total := rec.errClasses.snapshot(); total > 0 {
+		fmt.Fprintf(w, "Error classes:   %s\n", formatErrorClasses(counts))
+	}
+	fmt.Fprintf(w, "Duration:        %v\n", duration)
+	fmt.Fprintf(w, "Concurrency:     %d\n", concurrency)
+	if rate > 0 {
+		scheduled := int64(duration.Seconds() * rate)
+		fmt.Fprintf(w, "Target rate:     %.2f req/s (open loop)\n", rate)
+		fmt.Fprintf(w, "Scheduled:       %d, not sent: %d\n", scheduled, max(scheduled-rec.started.Load(), 0))
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "RPS:             %.2f\n", rps)
+	fmt.Fprintf(w, "RPM:             %.0f\n", rps*60)
+	fmt.Fprintln(w)
+
+	if rate > 0 {
+		fmt.Fprintln(w, "Service time (send to response):")
+	}
+	printLatency(w, service)
+	if rate > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintln(w, "Corrected latency (scheduled send to response, includes queueing):")
+		printLatency(w, corrected)
 	}
+
+	if labels, counts, total := rec.protos.snapshot(); total > 0 {
+		parts := make([]string, 0, len(labels))
+		for _, label := range labels {
+			parts = append(parts, fmt.Sprintf("%s %.1f%%", label, float64(counts[label])*100/float64(total)))
+		}
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Protocols:       %s\n", strings.Join(parts, ", "))
+	}
+	if n, r := rec.newConns.Load(), rec.reusedConns.Load(); n+r > 0 {
+		fmt.Fprintf(w, "Connections:     %d new, %d reused\n", n, r)
+	}
+
+	if labels, counts, total := rec.classes.snapshot(); total > 0 {
+		fmt.Fprintln(w)
+		fmt.Fprintf(w, "Classification (%s):\n", profileName)
+		for _, label := range labels {
+			fmt.Fprintf(w, "  %-14s %d (%.1f%%)\n", label+":", counts[label], float64(counts[label])*100/float64(total))
+		}
+	}
+}
+
+// printLatency writes the latency statistics of a run
+func printLatency(w io.Writer, l latencySummary) {
+	us := func(d time.Duration) float64 { return float64(d) / float64(time.Microsecond) }
+	fmt.Fprintf(w, "Latency avg:     %.2f µs (%.3f ms)\n", us(l.Avg), us(l.Avg)/1000)
+	fmt.Fprintf(w, "Latency min:     %.0f µs (%.3f ms)\n", us(l.Min), us(l.Min)/1000)
+	fmt.Fprintf(w, "Latency max:     %.0f µs (%.3f ms)\n", us(l.Max), us(l.Max)/1000)
+	fmt.Fprintf(w, "Latency p50:     %.3f ms\n", us(l.P50)/1000)
+	fmt.Fprintf(w, "Latency p90:     %.3f ms\n", us(l.P90)/1000)
+	fmt.Fprintf(w, "Latency p99:     %.3f ms\n", us(l.P99)/1000)
 }
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// recorder collects the outcome of the requests of a single-profile run
+type recorder struct {
+	mu        sync.Mutex
+	service   []time.Duration // Send to response, per successful request
+	corrected []time.Duration // Intended send to response; open loop only
+
+	requests    atomic.Int64 // Successful requests
+	errors      atomic.Int64
+	started     atomic.Int64
+	newConns    atomic.Int64
+	reusedConns atomic.Int64
+
+	errClasses *distribution
+	classes    *distribution
+	protos     *distribution
+	trace      *httptrace.ClientTrace
+}
+
+func newRecorder() *recorder {
+	r := &recorder{
+		errClasses: &distribution{counts: make(map[string]int64)},
+		classes:    &distribution{counts: make(map[string]int64)},
+		protos:     &distribution{counts: make(map[string]int64)},
+	}
+	// Count connection reuse; HTTP/3 transports do not report it
+	r.trace = &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				r.reusedConns.Add(1)
+			} else {
+				r.newConns.Add(1)
+			}
+		},
+	}
+	return r
+}
+
+// do sends a request and records its outcome. For open-loop runs,
+// intended is the time the request was scheduled to be sent; latency
+// measured from it includes any time spent waiting for a free worker.
+func (r *recorder) do(client *http.Client, req *http.Request, intended time.Time) {
+	r.started.Add(1)
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), r.trace))
+	start := time.Now()
+	resp, err := client.Do(req)
+	var body []byte
+	if err == nil {
+		body, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+	end := time.Now()
+
+	if err != nil {
+		r.fail(errorClass(err))
+		return
+	}
+	r.protos.add(resp.Proto)
+	var cr classifyResponse
+	if json.Unmarshal(body, &cr) == nil && cr.Classification != "" {
+		r.classes.add(cr.Classification)
+	}
+	if resp.StatusCode != http.StatusOK {
+		r.fail(statusClass(resp.StatusCode))
+		return
+	}
+
+	r.requests.Add(1)
+	r.mu.Lock()
+	r.service = append(r.service, end.Sub(start))
+	if !intended.IsZero() {
+		r.corrected = append(r.corrected, end.Sub(intended))
+	}
+	r.mu.Unlock()
+}
+
+// fail counts a failed request under its error class
+func (r *recorder) fail(class string) {
+	r.errors.Add(1)
+	r.errClasses.add(class)
+}
+
+// latencies returns summaries of the service and corrected latencies
+func (r *recorder) latencies() (service, corrected latencySummary) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return summarize(r.service), summarize(r.corrected)
+}
+
+// runClosedLoop keeps workers busy sending requests back to back until
+// stop is closed: each worker waits for a response before the next send,
+// so a slow server also slows the offered load
+func runClosedLoop(client *http.Client, newReq func() *http.Request, workers int, stop <-chan struct{}, rec *recorder) {
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					rec.do(client, newReq(), time.Time{})
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// runOpenLoop schedules requests at a fixed rate from start, independent
+// of response times, until stop is closed. Up to workers requests are in
+// flight; when all are busy, scheduled requests queue and are sent late,
+// and the delay is charged to their corrected latency instead of being
+// silently omitted.
+func runOpenLoop(client *http.Client, newReq func() *http.Request, rate float64, workers int, start time.Time, stop <-chan struct{}, rec *recorder) {
+	interval := time.Duration(float64(time.Second) / rate)
+	jobs := make(chan time.Time, workers)
+
+	var wg sync.WaitGroup
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				case intended := <-jobs:
+					rec.do(client, newReq(), intended)
+				}
+			}
+		}()
+	}
+
+	// Send times are computed from the start rather than the previous
+	// send, so falling behind is followed by catching up, not drift
+	for i := int64(0); ; i++ {
+		intended := start.Add(time.Duration(i) * interval)
+		if wait := time.Until(intended); wait > 0 {
+			t := time.NewTimer(wait)
+			select {
+			case <-stop:
+				t.Stop()
+				wg.Wait()
+				return
+			case <-t.C:
+			}
+		}
+		select {
+		case <-stop:
+			wg.Wait()
+			return
+		case jobs <- intended:
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// header is a single request header
+type header struct {
+	Name  string
+	Value string
+}
+
+// profile is a named client header set sent with every request
+type profile struct {
+	Name    string
+	Headers []header
+}
+
+// builtinProfiles are realistic header sets of common clients
+var builtinProfiles = map[string]profile{
+	"go": {Name: "go"}, // Go's default client headers only
+	"curl": {Name: "curl", Headers: []header{
+		{"User-Agent", "curl/8.5.0"},
+		{"Accept", "*/*"},
+	}},
+	"python": {Name: "python", Headers: []header{
+		{"User-Agent", "python-requests/2.31.0"},
+		{"Accept-Encoding", "gzip, deflate"},
+		{"Accept", "*/*"},
+		{"Connection", "keep-alive"},
+	}},
+	"chrome": {Name: "chrome", Headers: []header{
+		{"sec-ch-ua", `"Chromium";v="124", "Google Chrome";v="124", "Not-A.Brand";v="99"`},
+		{"sec-ch-ua-mobile", "?0"},
+		{"sec-ch-ua-platform", `"Windows"`},
+		{"Upgrade-Insecure-Requests", "1"},
+		{"User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/124.0.0.0 Safari/537.36"},
+		{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,image/apng,*/*;q=0.8,application/signed-exchange;v=b3;q=0.7"},
+		{"Sec-Fetch-Site", "none"},
+		{"Sec-Fetch-Mode", "navigate"},
+		{"Sec-Fetch-User", "?1"},
+		{"Sec-Fetch-Dest", "document"},
+		{"Accept-Encoding", "gzip, deflate, br, zstd"},
+		{"Accept-Language", "en-US,en;q=0.9"},
+	}},
+	"firefox": {Name: "firefox", Headers: []header{
+		{"User-Agent", "Mozilla/5.0 (Windows NT 10.0; Win64; x64; rv:125.0) Gecko/20100101 Firefox/125.0"},
+		{"Accept", "text/html,application/xhtml+xml,application/xml;q=0.9,image/avif,image/webp,*/*;q=0.8"},
+		{"Accept-Language", "en-US,en;q=0.5"},
+		{"Accept-Encoding", "gzip, deflate, br"},
+		{"Upgrade-Insecure-Requests", "1"},
+		{"Sec-Fetch-Dest", "document"},
+		{"Sec-Fetch-Mode", "navigate"},
+		{"Sec-Fetch-Site", "none"},
+		{"Sec-Fetch-User", "?1"},
+	}},
+	"gptbot": {Name: "gptbot", Headers: []header{
+		{"User-Agent", "Mozilla/5.0 AppleWebKit/537.36 (KHTML, like Gecko; compatible; GPTBot/1.2; +https://openai.com/gptbot)"},
+		{"Accept", "*/*"},
+		{"Accept-Encoding", "gzip, br"},
+	}},
+}
+
+// profileNames returns the sorted names of the built-in profiles
+func profileNames() []string {
+	names := make([]string, 0, len(builtinProfiles))
+	for name := range builtinProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// loadProfile returns a built-in profile by name, or reads a custom
+// header file with one "Name: value" header per line
+func loadProfile(name string) (profile, error) {
+	if p, ok := builtinProfiles[name]; ok {
+		return p, nil
+	}
+
+	f, err := os.Open(name)
+	if err != nil {
+		return profile{}, fmt.Errorf("unknown profile %q (built-in: %s) and not a header file: %w",
+			name, strings.Join(profileNames(), ", "), err)
+	}
+	defer func() { _ = f.Close() }()
+
+	p := profile{Name: name}
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		hname, value, ok := strings.Cut(line, ":")
+		if !ok {
+			return profile{}, fmt.Errorf("%s:%d: header must be in 'Name: value' form", name, n)
+		}
+		p.Headers = append(p.Headers, header{strings.TrimSpace(hname), strings.TrimSpace(value)})
+	}
+	if err := scanner.Err(); err != nil {
+		return profile{}, err
+	}
+	return p, nil
+}
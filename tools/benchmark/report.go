@@ -0,0 +1,350 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// Result formats selectable with -output
+const (
+	outputText = "text"
+	outputJSON = "json"
+	outputCSV  = "csv"
+)
+
+// outputNames lists the -output values
+var outputNames = []string{outputText, outputJSON, outputCSV}
+
+// console receives the run header and progress lines. With -output json
+// or csv it is stderr, so stdout carries nothing but the report.
+var console io.Writer = os.Stdout
+
+// Run modes reported in report.Mode
+const (
+	modeClosedLoop = "closed_loop"
+	modeOpenLoop   = "open_loop"
+	modeScenario   = "scenario"
+)
+
+// report is the machine-readable result of a run (-output json|csv), meant
+// for comparing runs across versions. Requests counts successful requests
+// only; latencies are in milliseconds.
+type report struct {
+	Mode             string           `json:"mode"`
+	URL              string           `json:"url"`
+	Scenario         string           `json:"scenario,omitempty"`
+	DurationSeconds  float64          `json:"duration_seconds"`
+	Concurrency      int              `json:"concurrency"` // Workers, or peak users of a scenario
+	Rate             float64          `json:"rate,omitempty"`
+	Scheduled        int64            `json:"scheduled,omitempty"`
+	NotSent          int64            `json:"not_sent,omitempty"`
+	Sessions         int64            `json:"sessions,omitempty"`
+	Requests         int64            `json:"requests"`
+	Errors           int64            `json:"errors"`
+	RPS              float64          `json:"rps"`
+	ErrorClasses     map[string]int64 `json:"error_classes"`
+	Latency          latencyReport    `json:"latency"`
+	CorrectedLatency *latencyReport   `json:"corrected_latency,omitempty"` // Open loop only
+	Protocols        map[string]int64 `json:"protocols,omitempty"`
+	Connections      *connReport      `json:"connections,omitempty"`
+	Classification   map[string]int64 `json:"classification"`
+	Profiles         []profileReport  `json:"profiles"`
+}
+
+// profileReport is the result of one client profile
+type profileReport struct {
+	Name           string           `json:"name"`
+	Profile        string           `json:"profile"`
+	Weight         int              `json:"weight,omitempty"`
+	Proto          string           `json:"proto,omitempty"`
+	TLSHello       string           `json:"tls_hello,omitempty"`
+	KeepAlive      bool             `json:"keepalive"`
+	Sessions       int64            `json:"sessions,omitempty"`
+	Requests       int64            `json:"requests"`
+	Errors         int64            `json:"errors"`
+	RPS            float64          `json:"rps"`
+	ErrorClasses   map[string]int64 `json:"error_classes"`
+	Latency        latencyReport    `json:"latency"`
+	Classification map[string]int64 `json:"classification"`
+}
+
+// latencyReport holds latency statistics in milliseconds
+type latencyReport struct {
+	Avg float64 `json:"avg_ms"`
+	Min float64 `json:"min_ms"`
+	Max float64 `json:"max_ms"`
+	P50 float64 `json:"p50_ms"`
+	P90 float64 `json:"p90_ms"`
+	P99 float64 `json:"p99_ms"`
+}
+
+// connReport counts the connections opened and reused by the client
+type connReport struct {
+	New    int64 `json:"new"`
+	Reused int64 `json:"reused"`
+}
+
+func newLatencyReport(l latencySummary) latencyReport {
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return latencyReport{Avg: ms(l.Avg), Min: ms(l.Min), Max: ms(l.Max), P50: ms(l.P50), P90: ms(l.P90), P99: ms(l.P99)}
+}
+
+// singleReport builds the report of a single-profile run
+func singleReport(rec *recorder, target string, duration time.Duration, concurrency int, rate float64, p profile, opts transportOptions) report {
+	reqs, errs := rec.requests.Load(), rec.errors.Load()
+	service, corrected := rec.latencies()
+	_, errClasses, _ := rec.errClasses.snapshot()
+	_, classes, _ := rec.classes.snapshot()
+	rps := float64(reqs) / duration.Seconds()
+
+	r := report{
+		Mode:            modeClosedLoop,
+		URL:             target,
+		DurationSeconds: duration.Seconds(),
+		Concurrency:     concurrency,
+		Requests:        reqs,
+		Errors:          errs,
+		RPS:             rps,
+		ErrorClasses:    errClasses,
+		Latency:         newLatencyReport(service),
+		Classification:  classes,
+	}
+	if rate > 0 {
+		scheduled := int64(duration.Seconds() * rate)
+		lat := newLatencyReport(corrected)
+		r.Mode = modeOpenLoop
+		r.Rate = rate
+		r.Scheduled = scheduled
+		r.NotSent = max(scheduled-rec.started.Load(), 0)
+		r.CorrectedLatency = &lat
+	}
+	if _, protos, total := rec.protos.snapshot(); total > 0 {
+		r.Protocols = protos
+	}
+	if n, reused := rec.newConns.Load(), rec.reusedConns.Load(); n+reused > 0 {
+		r.Connections = &connReport{New: n, Reused: reused}
+	}
+
+	proto := opts.proto
+	if opts.tlsHello != "" {
+		proto = ""
+	}
+	r.Profiles = []profileReport{{
+		Name:           p.Name,
+		Profile:        p.Name,
+		Proto:          proto,
+		TLSHello:       opts.tlsHello,
+		KeepAlive:      opts.keepAlive,
+		Requests:       reqs,
+		Errors:         errs,
+		RPS:            rps,
+		ErrorClasses:   errClasses,
+		Latency:        r.Latency,
+		Classification: classes,
+	}}
+	return r
+}
+
+// scenarioReport builds the report of a scenario run with one profile
+// entry per client
+func scenarioReport(sc *scenario, duration time.Duration) report {
+	r := report{
+		Mode:            modeScenario,
+		URL:             sc.URL,
+		Scenario:        sc.Name,
+		DurationSeconds: duration.Seconds(),
+		Concurrency:     sc.maxConcurrency(),
+		ErrorClasses:    map[string]int64{},
+		Classification:  map[string]int64{},
+	}
+	var all []time.Duration
+	for i := range sc.Clients {
+		c := &sc.Clients[i]
+		lat, errs, sessions := c.stats.snapshot()
+		_, errClasses, _ := c.stats.errClasses.snapshot()
+		_, classes, _ := c.stats.dist.snapshot()
+		opts := c.transportOptions(false, 0)
+
+		all = append(all, lat...)
+		r.Sessions += sessions
+		r.Errors += errs
+		addCounts(r.ErrorClasses, errClasses)
+		addCounts(r.Classification, classes)
+
+		proto := opts.proto
+		if opts.tlsHello != "" {
+			proto = ""
+		}
+		r.Profiles = append(r.Profiles, profileReport{
+			Name:           c.Name,
+			Profile:        c.prof.Name,
+			Weight:         c.Weight,
+			Proto:          proto,
+			TLSHello:       opts.tlsHello,
+			KeepAlive:      opts.keepAlive,
+			Sessions:       sessions,
+			Requests:       int64(len(lat)),
+			Errors:         errs,
+			RPS:            float64(len(lat)) / duration.Seconds(),
+			ErrorClasses:   errClasses,
+			Latency:        newLatencyReport(summarize(lat)),
+			Classification: classes,
+		})
+	}
+	r.Requests = int64(len(all))
+	r.RPS = float64(r.Requests) / duration.Seconds()
+	r.Latency = newLatencyReport(summarize(all))
+	return r
+}
+
+func addCounts(dst, src map[string]int64) {
+	for k, n := range src {
+		dst[k] += n
+	}
+}
+
+// writeReport writes the report in the given format
+func writeReport(w io.Writer, format string, r report) error {
+	switch format {
+	case outputJSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(r)
+	case outputCSV:
+		return writeCSV(w, r)
+	default:
+		return fmt.Errorf("unknown output format %q (available: %s)", format, strings.Join(outputNames, ", "))
+	}
+}
+
+// csvHeader lists the columns of the CSV report. Error classes and
+// classifications are label=count pairs separated by semicolons, so the
+// columns stay the same whatever labels a run produces.
+var csvHeader = []string{
+	"mode", "url", "scenario", "profile", "duration_seconds", "concurrency", "rate",
+	"sessions", "requests", "errors", "rps",
+	"avg_ms", "min_ms", "max_ms", "p50_ms", "p90_ms", "p99_ms",
+	"corrected_p50_ms", "corrected_p90_ms", "corrected_p99_ms",
+	"error_classes", "classification",
+}
+
+// writeCSV writes one row per profile followed by an "all" row with the
+// run totals
+func writeCSV(w io.Writer, r report) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+
+	num := func(f float64) string { return strconv.FormatFloat(f, 'f', 3, 64) }
+	row := func(name string, sessions, reqs, errs int64, rps float64, lat latencyReport, corrected *latencyReport, errClasses, classes map[string]int64) []string {
+		cols := []string{
+			r.Mode, r.URL, r.Scenario, name, num(r.DurationSeconds), strconv.Itoa(r.Concurrency), num(r.Rate),
+			strconv.FormatInt(sessions, 10), strconv.FormatInt(reqs, 10), strconv.FormatInt(errs, 10), num(rps),
+			num(lat.Avg), num(lat.Min), num(lat.Max), num(lat.P50), num(lat.P90), num(lat.P99),
+		}
+		if corrected != nil {
+			cols = append(cols, num(corrected.P50), num(corrected.P90), num(corrected.P99))
+		} else {
+			cols = append(cols, "", "", "")
+		}
+		return append(cols, formatCounts(errClasses), formatCounts(classes))
+	}
+
+	for _, p := range r.Profiles {
+		var corrected *latencyReport
+		if len(r.Profiles) == 1 {
+			corrected = r.CorrectedLatency
+		}
+		if err := cw.Write(row(p.Name, p.Sessions, p.Requests, p.Errors, p.RPS, p.Latency, corrected, p.ErrorClasses, p.Classification)); err != nil {
+			return err
+		}
+	}
+	if err := cw.Write(row("all", r.Sessions, r.Requests, r.Errors, r.RPS, r.Latency, r.CorrectedLatency, r.ErrorClasses, r.Classification)); err != nil {
+		return err
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatCounts formats counts as label=count pairs sorted by label
+func formatCounts(counts map[string]int64) string {
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Strings(labels)
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s=%d", label, counts[label])
+	}
+	return strings.Join(parts, ";")
+}
+
+// formatErrorClasses formats error counts for the text results, most
+// frequent first
+func formatErrorClasses(counts map[string]int64) string {
+	labels := make([]string, 0, len(counts))
+	for label := range counts {
+		labels = append(labels, label)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if counts[labels[i]] != counts[labels[j]] {
+			return counts[labels[i]] > counts[labels[j]]
+		}
+		return labels[i] < labels[j]
+	})
+	parts := make([]string, len(labels))
+	for i, label := range labels {
+		parts[i] = fmt.Sprintf("%s %d", label, counts[label])
+	}
+	return strings.Join(parts, ", ")
+}
+
+// errorClass names the cause of a failed request for the error breakdown
+func errorClass(err error) string {
+	var (
+		netErr   net.Error
+		dnsErr   *net.DNSError
+		alertErr tls.AlertError
+		recErr   tls.RecordHeaderError
+		verErr   *tls.CertificateVerificationError
+		authErr  x509.UnknownAuthorityError
+		hostErr  x509.HostnameError
+		certErr  x509.CertificateInvalidError
+	)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return "timeout"
+	case errors.As(err, &dnsErr):
+		return "dns"
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return "connection_refused"
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, syscall.EPIPE),
+		errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return "connection_reset"
+	case errors.As(err, &alertErr), errors.As(err, &recErr), errors.As(err, &verErr),
+		errors.As(err, &authErr), errors.As(err, &hostErr), errors.As(err, &certErr):
+		return "tls"
+	default:
+		return "transport"
+	}
+}
+
+// statusClass names an unexpected HTTP status for the error breakdown
+func statusClass(code int) string {
+	return "http_" + strconv.Itoa(code)
+}
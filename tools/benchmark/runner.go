@@ -0,0 +1,230 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+)
+
+// runScenario executes a scenario, printing progress every second. The
+// results are collected in the clients' stats.
+func runScenario(sc *scenario, insecure bool) error {
+	maxIdle := max(sc.maxConcurrency()*2, 2)
+	for i := range sc.Clients {
+		c := &sc.Clients[i]
+		transport, err := newTransport(c.transportOptions(insecure, maxIdle))
+		if err != nil {
+			return fmt.Errorf("client %s: %w", c.Name, err)
+		}
+		c.client = &http.Client{Transport: transport, Timeout: 5 * time.Second}
+		c.stats = newProfileStats()
+	}
+
+	total := sc.duration()
+	fmt.Fprintf(console, "Scenario %s against %s\n", sc.Name, sc.URL)
+	fmt.Fprintf(console, "Duration: %v, Stages: %d, Peak users: %d, Clients: %d\n\n", total, len(sc.Stages), sc.maxConcurrency(), len(sc.Clients))
+
+	var (
+		wg    sync.WaitGroup
+		stops []chan struct{}
+	)
+	start := time.Now()
+	tick := time.NewTicker(100 * time.Millisecond)
+	defer tick.Stop()
+	nextReport := time.Second
+	for {
+		elapsed := time.Since(start)
+		if elapsed >= total {
+			break
+		}
+
+		// Adjust the number of virtual users to the ramp; stopped users
+		// finish their in-flight request first
+		target := sc.concurrencyAt(elapsed)
+		for len(stops) < target {
+			stop := make(chan struct{})
+			stops = append(stops, stop)
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sc.runUser(stop)
+			}()
+		}
+		for len(stops) > target {
+			close(stops[len(stops)-1])
+			stops = stops[:len(stops)-1]
+		}
+
+		if elapsed >= nextReport {
+			reqs, errs := sc.counts()
+			fmt.Fprintf(console, "[%ds] Users: %d, Requests: %d, Errors: %d, RPS: %.0f\n",
+				int(nextReport.Seconds()), len(stops), reqs, errs, float64(reqs)/elapsed.Seconds())
+			nextReport += time.Second
+		}
+		<-tick.C
+	}
+	for _, stop := range stops {
+		close(stop)
+	}
+	wg.Wait()
+	return nil
+}
+
+// runUser is a virtual user: it picks a client by weight and runs its
+// request sequence with think times, until stop is closed
+func (sc *scenario) runUser(stop <-chan struct{}) {
+	for {
+		c := sc.pickClient()
+		c.stats.session()
+		tt := sc.thinkTimeFor(c)
+		for _, s := range c.Steps {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.send(s)
+			if !pause(tt.pick(), stop) {
+				return
+			}
+		}
+	}
+}
+
+// send performs one step and records its outcome
+func (c *client) send(s step) {
+	req, err := newRequest(s.Method, s.url, s.Body, c.prof)
+	if err != nil {
+		c.stats.fail("request")
+		return
+	}
+	start := time.Now()
+	resp, err := c.client.Do(req)
+	var body []byte
+	if err == nil {
+		body, err = io.ReadAll(resp.Body)
+		_ = resp.Body.Close()
+	}
+	latency := time.Since(start)
+
+	if err != nil {
+		c.stats.fail(errorClass(err))
+		return
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		c.stats.fail(statusClass(resp.StatusCode))
+		return
+	}
+	var cr classifyResponse
+	_ = json.Unmarshal(body, &cr)
+	c.stats.record(latency, cr.Classification)
+}
+
+// pause sleeps for d and reports false if stop was closed meanwhile
+func pause(d time.Duration, stop <-chan struct{}) bool {
+	if d <= 0 {
+		select {
+		case <-stop:
+			return false
+		default:
+			return true
+		}
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-stop:
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// counts returns the successful and failed requests over all clients
+func (sc *scenario) counts() (int64, int64) {
+	var reqs, errs int64
+	for i := range sc.Clients {
+		r, e := sc.Clients[i].stats.counts()
+		reqs += r
+		errs += e
+	}
+	return reqs, errs
+}
+
+// printScenarioResults writes the overall and per-client results
+func printScenarioResults(w io.Writer, sc *scenario, duration time.Duration) {
+	var (
+		all          []time.Duration
+		errs, totalS int64
+		errClasses   = map[string]int64{}
+	)
+	type row struct {
+		c        *client
+		lat      latencySummary
+		errs     int64
+		sessions int64
+	}
+	rows := make([]row, 0, len(sc.Clients))
+	for i := range sc.Clients {
+		c := &sc.Clients[i]
+		lat, e, s := c.stats.snapshot()
+		all = append(all, lat...)
+		errs += e
+		totalS += s
+		_, classes, _ := c.stats.errClasses.snapshot()
+		addCounts(errClasses, classes)
+		rows = append(rows, row{c: c, lat: summarize(lat), errs: e, sessions: s})
+	}
+	overall := summarize(all)
+	rps := float64(overall.Count) / duration.Seconds()
+
+	fmt.Fprintln(w, "\n========== RESULTS ==========")
+	fmt.Fprintf(w, "Scenario:        %s\n", sc.Name)
+	fmt.Fprintf(w, "Duration:        %v\n", duration)
+	fmt.Fprintf(w, "Peak users:      %d\n", sc.maxConcurrency())
+	fmt.Fprintf(w, "Total sessions:  %d\n", totalS)
+	fmt.Fprintf(w, "Total requests:  %d\n", overall.Count)
+	fmt.Fprintf(w, "Total errors:    %d\n", errs)
+	if len(errClasses) > 0 {
+		fmt.Fprintf(w, "Error classes:   %s\n", formatErrorClasses(errClasses))
+	}
+	fmt.Fprintf(w, "RPS:             %.2f\n", rps)
+	fmt.Fprintf(w, "RPM:             %.0f\n", rps*60)
+	fmt.Fprintln(w)
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CLIENT\tWEIGHT\tSESSIONS\tREQUESTS\tERRORS\tAVG\tP50\tP90\tP99\tMAX")
+	for _, r := range rows {
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t%s\n", r.c.Name, r.c.Weight, r.sessions, r.lat.Count, r.errs, latencyColumns(r.lat))
+	}
+	fmt.Fprintf(tw, "all\t\t%d\t%d\t%d\t%s\n", totalS, overall.Count, errs, latencyColumns(overall))
+	_ = tw.Flush()
+
+	fmt.Fprintln(w, "\nClassification by client:")
+	for _, r := range rows {
+		labels, counts, total := r.c.stats.dist.snapshot()
+		if total == 0 {
+			fmt.Fprintf(w, "  %-14s -\n", r.c.Name+":")
+			continue
+		}
+		parts := make([]string, 0, len(labels))
+		for _, label := range labels {
+			parts = append(parts, fmt.Sprintf("%s %d (%.1f%%)", label, counts[label], float64(counts[label])*100/float64(total)))
+		}
+		fmt.Fprintf(w, "  %-14s %s\n", r.c.Name+":", strings.Join(parts, ", "))
+	}
+}
+
+// latencyColumns formats avg/p50/p90/p99/max as tab-separated milliseconds
+func latencyColumns(l latencySummary) string {
+	if l.Count == 0 {
+		return "-\t-\t-\t-\t-"
+	}
+	ms := func(d time.Duration) string { return fmt.Sprintf("%.2fms", float64(d.Microseconds())/1000) }
+	return strings.Join([]string{ms(l.Avg), ms(l.P50), ms(l.P90), ms(l.P99), ms(l.Max)}, "\t")
+}
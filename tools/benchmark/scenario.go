@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// scenario is a load test definition read from a YAML file (-scenario).
+// Virtual users repeatedly pick a client by weight and run its request
+// sequence, pausing for a think time between requests, while the number
+// of virtual users follows the stages.
+type scenario struct {
+	Name      string    `yaml:"name"`
+	URL       string    `yaml:"url"`        // Base URL the step paths are resolved against
+	ThinkTime thinkTime `yaml:"think_time"` // Default pause between requests
+	Stages    []stage   `yaml:"stages"`
+	Clients   []client  `yaml:"clients"`
+}
+
+// stage ramps the number of virtual users linearly from the previous
+// stage's target (0 for the first stage) to Concurrency over Duration
+type stage struct {
+	Duration    time.Duration `yaml:"duration"`
+	Concurrency int           `yaml:"concurrency"`
+}
+
+// thinkTime is a uniformly distributed pause between Min and Max
+type thinkTime struct {
+	Min time.Duration `yaml:"min"`
+	Max time.Duration `yaml:"max"`
+}
+
+// pick returns a random pause within the range
+func (t thinkTime) pick() time.Duration {
+	if t.Max <= t.Min {
+		return t.Min
+	}
+	return t.Min + rand.N(t.Max-t.Min+1)
+}
+
+// client is one weighted entry of the traffic mix
+type client struct {
+	Name      string     `yaml:"name"`      // Report label, defaults to the profile name
+	Profile   string     `yaml:"profile"`   // Built-in profile or header file
+	TLSHello  string     `yaml:"tls_hello"` // Optional uTLS ClientHello preset
+	Proto     string     `yaml:"proto"`     // h1 (default), h2 or h3
+	KeepAlive *bool      `yaml:"keepalive"` // Reuse connections, default true
+	Weight    int        `yaml:"weight"`    // Relative share of sessions, default 1
+	ThinkTime *thinkTime `yaml:"think_time"`
+	Steps     []step     `yaml:"steps"` // Request sequence, default a single GET of the base URL
+
+	prof   profile
+	client *http.Client
+	stats  *profileStats
+}
+
+// transportOptions returns the connection settings of the client
+func (c *client) transportOptions(insecure bool, maxIdle int) transportOptions {
+	return transportOptions{
+		proto:     c.Proto,
+		tlsHello:  c.TLSHello,
+		insecure:  insecure,
+		keepAlive: c.KeepAlive == nil || *c.KeepAlive,
+		maxIdle:   maxIdle,
+	}
+}
+
+// step is a single request of a client's sequence
+type step struct {
+	Method string `yaml:"method"`
+	Path   string `yaml:"path"`
+	Body   string `yaml:"body"`
+
+	url string
+}
+
+// loadScenario reads and validates a scenario file. A non-empty baseURL
+// overrides the file's url.
+func loadScenario(path, baseURL string) (*scenario, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var sc scenario
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&sc); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if baseURL != "" {
+		sc.URL = baseURL
+	}
+	if sc.Name == "" {
+		sc.Name = path
+	}
+	if err := sc.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return &sc, nil
+}
+
+// validate checks the scenario and resolves profiles and step URLs
+func (sc *scenario) validate() error {
+	base, err := url.Parse(sc.URL)
+	if err != nil || base.Scheme == "" || base.Host == "" {
+		return fmt.Errorf("url must be an absolute URL, got %q", sc.URL)
+	}
+	if err := sc.ThinkTime.validate(); err != nil {
+		return fmt.Errorf("think_time: %w", err)
+	}
+
+	if len(sc.Stages) == 0 {
+		return errors.New("at least one stage is required")
+	}
+	for i, st := range sc.Stages {
+		if st.Duration <= 0 {
+			return fmt.Errorf("stages[%d]: duration must be positive", i)
+		}
+		if st.Concurrency < 0 {
+			return fmt.Errorf("stages[%d]: concurrency must not be negative", i)
+		}
+	}
+
+	if len(sc.Clients) == 0 {
+		return errors.New("at least one client is required")
+	}
+	names := map[string]bool{}
+	for i := range sc.Clients {
+		c := &sc.Clients[i]
+		if c.Weight < 0 {
+			return fmt.Errorf("clients[%d]: weight must not be negative", i)
+		}
+		if c.Weight == 0 {
+			c.Weight = 1
+		}
+		if c.ThinkTime != nil {
+			if err := c.ThinkTime.validate(); err != nil {
+				return fmt.Errorf("clients[%d].think_time: %w", i, err)
+			}
+		}
+		if c.Profile == "" {
+			c.Profile = "go"
+		}
+		if c.Proto == "" {
+			c.Proto = protoHTTP1
+		}
+		if err := c.transportOptions(false, 0).validate(base.Scheme); err != nil {
+			return fmt.Errorf("clients[%d]: %w", i, err)
+		}
+		if c.prof, err = loadProfile(c.Profile); err != nil {
+			return fmt.Errorf("clients[%d]: %w", i, err)
+		}
+		if c.Name == "" {
+			c.Name = c.prof.Name
+		}
+		if names[c.Name] {
+			return fmt.Errorf("clients[%d]: duplicate name %q, set distinct names", i, c.Name)
+		}
+		names[c.Name] = true
+
+		if len(c.Steps) == 0 {
+			c.Steps = []step{{}}
+		}
+		for j := range c.Steps {
+			s := &c.Steps[j]
+			if s.Method == "" {
+				s.Method = http.MethodGet
+			}
+			s.Method = strings.ToUpper(s.Method)
+			ref, err := url.Parse(s.Path)
+			if err != nil {
+				return fmt.Errorf("clients[%d].steps[%d]: %w", i, j, err)
+			}
+			s.url = base.ResolveReference(ref).String()
+			if _, err := newRequest(s.Method, s.url, s.Body, c.prof); err != nil {
+				return fmt.Errorf("clients[%d].steps[%d]: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+// validate checks that the range is well-formed
+func (t thinkTime) validate() error {
+	if t.Min < 0 || t.Max < 0 {
+		return errors.New("durations must not be negative")
+	}
+	if t.Max != 0 && t.Max < t.Min {
+		return errors.New("max must not be less than min")
+	}
+	return nil
+}
+
+// duration returns the total length of all stages
+func (sc *scenario) duration() time.Duration {
+	var d time.Duration
+	for _, st := range sc.Stages {
+		d += st.Duration
+	}
+	return d
+}
+
+// maxConcurrency returns the highest stage target
+func (sc *scenario) maxConcurrency() int {
+	n := 0
+	for _, st := range sc.Stages {
+		n = max(n, st.Concurrency)
+	}
+	return n
+}
+
+// concurrencyAt returns the number of virtual users elapsed into the run
+func (sc *scenario) concurrencyAt(elapsed time.Duration) int {
+	from := 0
+	for _, st := range sc.Stages {
+		if elapsed < st.Duration {
+			frac := float64(elapsed) / float64(st.Duration)
+			return from + int(float64(st.Concurrency-from)*frac+0.5)
+		}
+		elapsed -= st.Duration
+		from = st.Concurrency
+	}
+	return from
+}
+
+// pickClient chooses a client with probability proportional to its weight
+func (sc *scenario) pickClient() *client {
+	total := 0
+	for _, c := range sc.Clients {
+		total += c.Weight
+	}
+	n := rand.N(total)
+	for i := range sc.Clients {
+		if n < sc.Clients[i].Weight {
+			return &sc.Clients[i]
+		}
+		n -= sc.Clients[i].Weight
+	}
+	return &sc.Clients[len(sc.Clients)-1]
+}
+
+// thinkTimeFor returns the pause range of a client
+func (sc *scenario) thinkTimeFor(c *client) thinkTime {
+	if c.ThinkTime != nil {
+		return *c.ThinkTime
+	}
+	return sc.ThinkTime
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// profileStats collects the latencies, errors and classifications of
+// the requests sent by one client of a scenario
+type profileStats struct {
+	mu         sync.Mutex
+	latencies  []time.Duration
+	errors     int64
+	sessions   int64
+	errClasses *distribution
+	dist       *distribution
+}
+
+func newProfileStats() *profileStats {
+	return &profileStats{
+		errClasses: &distribution{counts: make(map[string]int64)},
+		dist:       &distribution{counts: make(map[string]int64)},
+	}
+}
+
+// record adds a successful request
+func (s *profileStats) record(latency time.Duration, classification string) {
+	s.mu.Lock()
+	s.latencies = append(s.latencies, latency)
+	s.mu.Unlock()
+	if classification != "" {
+		s.dist.add(classification)
+	}
+}
+
+// fail counts a failed request under its error class
+func (s *profileStats) fail(class string) {
+	s.mu.Lock()
+	s.errors++
+	s.mu.Unlock()
+	s.errClasses.add(class)
+}
+
+// session counts a started request sequence
+func (s *profileStats) session() {
+	s.mu.Lock()
+	s.sessions++
+	s.mu.Unlock()
+}
+
+// counts returns the number of successful and failed requests
+func (s *profileStats) counts() (int64, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return int64(len(s.latencies)), s.errors
+}
+
+// latencySummary holds latency statistics of a set of requests
+type latencySummary struct {
+	Count         int
+	Avg           time.Duration
+	Min, Max      time.Duration
+	P50, P90, P99 time.Duration
+}
+
+// summarize computes latency statistics over the given samples
+func summarize(latencies []time.Duration) latencySummary {
+	if len(latencies) == 0 {
+		return latencySummary{}
+	}
+	sorted := append([]time.Duration(nil), latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, l := range sorted {
+		total += l
+	}
+	quantile := func(q float64) time.Duration {
+		return sorted[int(q*float64(len(sorted)-1)+0.5)]
+	}
+	return latencySummary{
+		Count: len(sorted),
+		Avg:   total / time.Duration(len(sorted)),
+		Min:   sorted[0],
+		Max:   sorted[len(sorted)-1],
+		P50:   quantile(0.50),
+		P90:   quantile(0.90),
+		P99:   quantile(0.99),
+	}
+}
+
+// snapshot returns a copy of the latencies and the error and session counts
+func (s *profileStats) snapshot() ([]time.Duration, int64, int64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]time.Duration(nil), s.latencies...), s.errors, s.sessions
+}
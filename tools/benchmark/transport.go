@@ -0,0 +1,131 @@
+package main
+
+import (
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/quic-go/quic-go/http3"
+)
+
+// HTTP protocols selectable with -proto
+const (
+	protoHTTP1 = "h1"
+	protoHTTP2 = "h2"
+	protoHTTP3 = "h3"
+)
+
+// protoNames lists the -proto values
+var protoNames = []string{protoHTTP1, protoHTTP2, protoHTTP3}
+
+// transportOptions configure the benchmark client connections
+type transportOptions struct {
+	proto     string // h1, h2 or h3; ignored with tlsHello, which follows ALPN
+	tlsHello  string // uTLS ClientHello preset
+	insecure  bool
+	keepAlive bool // Reuse connections; otherwise one connection per request
+	maxIdle   int
+}
+
+// validate checks the protocol against the target URL scheme
+func (o transportOptions) validate(scheme string) error {
+	switch o.proto {
+	case protoHTTP1, protoHTTP2:
+	case protoHTTP3:
+		if scheme != "https" {
+			return errors.New("h3 requires an https URL")
+		}
+	default:
+		return fmt.Errorf("unknown protocol %q (available: %s)", o.proto, strings.Join(protoNames, ", "))
+	}
+	if o.tlsHello != "" && o.proto != protoHTTP1 {
+		return fmt.Errorf("uTLS hellos negotiate the protocol via ALPN and cannot be combined with proto %s", o.proto)
+	}
+	return nil
+}
+
+// newTransport creates the HTTP transport. HTTP/2 is spoken over TLS for
+// https URLs and with prior knowledge (h2c) for http URLs.
+func newTransport(o transportOptions) (http.RoundTripper, error) {
+	if o.tlsHello != "" {
+		tr, err := newUTLSTransport(o.tlsHello, o.insecure, o.keepAlive, o.maxIdle)
+		if err != nil {
+			return nil, err
+		}
+		return tr, nil
+	}
+
+	tlsConfig := &tls.Config{InsecureSkipVerify: o.insecure} //nolint:gosec
+	if o.proto == protoHTTP3 {
+		newH3 := func() http.RoundTripper {
+			return &http3.Transport{TLSClientConfig: tlsConfig.Clone()}
+		}
+		if !o.keepAlive {
+			return &singleUseTransport{newTransport: newH3}, nil
+		}
+		return newH3(), nil
+	}
+
+	tr := &http.Transport{
+		MaxIdleConns:        o.maxIdle,
+		MaxIdleConnsPerHost: o.maxIdle,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   !o.keepAlive,
+		TLSClientConfig:     tlsConfig,
+		Protocols:           new(http.Protocols),
+	}
+	if o.proto == protoHTTP2 {
+		tr.Protocols.SetHTTP2(true)
+		tr.Protocols.SetUnencryptedHTTP2(true)
+	} else {
+		tr.Protocols.SetHTTP1(true)
+	}
+	return tr, nil
+}
+
+// singleUseTransport sends every request over a new connection, for
+// transports without a keep-alive switch. Each request gets a fresh
+// transport that is closed together with the response body.
+type singleUseTransport struct {
+	newTransport func() http.RoundTripper
+}
+
+// RoundTrip sends the request over a transport of its own
+func (t *singleUseTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	rt := t.newTransport()
+	resp, err := rt.RoundTrip(req)
+	if err != nil {
+		closeTransport(rt)
+		return nil, err
+	}
+	resp.Body = &transportBody{ReadCloser: resp.Body, rt: rt}
+	return resp, nil
+}
+
+// transportBody closes its transport once the body is closed
+type transportBody struct {
+	io.ReadCloser
+	rt   http.RoundTripper
+	once sync.Once
+}
+
+func (b *transportBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(func() { closeTransport(b.rt) })
+	return err
+}
+
+// closeTransport releases the connections held by a transport
+func closeTransport(rt http.RoundTripper) {
+	switch t := rt.(type) {
+	case io.Closer:
+		_ = t.Close()
+	case interface{ CloseIdleConnections() }:
+		t.CloseIdleConnections()
+	}
+}
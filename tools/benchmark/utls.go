@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	utls "github.com/refraction-networking/utls"
+	"golang.org/x/net/http2"
+)
+
+// tlsHellos maps -tls-hello names to uTLS ClientHello presets
+var tlsHellos = map[string]utls.ClientHelloID{
+	"chrome":  utls.HelloChrome_Auto,
+	"firefox": utls.HelloFirefox_Auto,
+	"safari":  utls.HelloSafari_Auto,
+	"edge":    utls.HelloEdge_Auto,
+	"ios":     utls.HelloIOS_Auto,
+}
+
+// tlsHelloNames returns the sorted -tls-hello preset names
+func tlsHelloNames() []string {
+	names := make([]string, 0, len(tlsHellos))
+	for name := range tlsHellos {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// utlsTransport is a RoundTripper that performs TLS handshakes with an
+// emulated browser ClientHello. Browser hellos offer h2 via ALPN, so the
+// negotiated protocol is probed once and requests are sent over an HTTP/2
+// or HTTP/1.1 transport accordingly.
+type utlsTransport struct {
+	hello     utls.ClientHelloID
+	insecure  bool
+	keepAlive bool
+	maxIdle   int
+
+	once sync.Once
+	rt   http.RoundTripper
+	err  error
+}
+
+// newUTLSTransport creates a transport emulating the named browser hello
+func newUTLSTransport(name string, insecure, keepAlive bool, maxIdle int) (*utlsTransport, error) {
+	hello, ok := tlsHellos[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown TLS hello %q (available: %s)", name, strings.Join(tlsHelloNames(), ", "))
+	}
+	return &utlsTransport{hello: hello, insecure: insecure, keepAlive: keepAlive, maxIdle: maxIdle}, nil
+}
+
+// dial opens a TCP connection and completes a uTLS handshake
+func (t *utlsTransport) dial(ctx context.Context, network, addr string) (*utls.UConn, error) {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	d := net.Dialer{Timeout: 5 * time.Second}
+	raw, err := d.DialContext(ctx, network, addr)
+	if err != nil {
+		return nil, err
+	}
+
+	conn := utls.UClient(raw, &utls.Config{
+		ServerName:         host,
+		InsecureSkipVerify: t.insecure, //nolint:gosec
+	}, t.hello)
+	if err := conn.HandshakeContext(ctx); err != nil {
+		_ = raw.Close()
+		return nil, fmt.Errorf("uTLS handshake failed: %w", err)
+	}
+	return conn, nil
+}
+
+// RoundTrip sends the request over the HTTP version negotiated via ALPN
+func (t *utlsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.URL.Scheme != "https" {
+		return nil, fmt.Errorf("-tls-hello requires an https URL, got %s", req.URL.Scheme)
+	}
+
+	t.once.Do(func() {
+		t.rt, t.err = t.probe(req)
+	})
+	if t.err != nil {
+		return nil, t.err
+	}
+	return t.rt.RoundTrip(req)
+}
+
+// probe performs one handshake to learn the negotiated protocol and
+// builds the matching transport
+func (t *utlsTransport) probe(req *http.Request) (http.RoundTripper, error) {
+	conn, err := t.dial(req.Context(), "tcp", hostPort(req))
+	if err != nil {
+		return nil, err
+	}
+	proto := conn.ConnectionState().NegotiatedProtocol
+	_ = conn.Close()
+
+	if proto == http2.NextProtoTLS {
+		newH2 := func() http.RoundTripper {
+			return &http2.Transport{
+				DialTLSContext: func(ctx context.Context, network, addr string, _ *tls.Config) (net.Conn, error) {
+					return t.dial(ctx, network, addr)
+				},
+			}
+		}
+		if !t.keepAlive {
+			return &singleUseTransport{newTransport: newH2}, nil
+		}
+		return newH2(), nil
+	}
+
+	return &http.Transport{
+		MaxIdleConns:        t.maxIdle,
+		MaxIdleConnsPerHost: t.maxIdle,
+		IdleConnTimeout:     90 * time.Second,
+		DisableKeepAlives:   !t.keepAlive,
+		DialTLSContext: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			return t.dial(ctx, network, addr)
+		},
+	}, nil
+}
+
+// hostPort returns the request's host with the default https port
+func hostPort(req *http.Request) string {
+	if req.URL.Port() != "" {
+		return req.URL.Host
+	}
+	return net.JoinHostPort(req.URL.Hostname(), "443")
+}
+
+// debugResponse is the part of the /debug response shown before the run
+type debugResponse struct {
+	Classification string `json:"classification"`
+	Fingerprint    struct {
+		TLS struct {
+			Version   string `json:"version"`
+			ALPN      string `json:"alpn"`
+			JA3Hash   string `json:"ja3_hash"`
+			JA4Hash   string `json:"ja4_hash"`
+			Available bool   `json:"available"`
+		} `json:"tls"`
+		HTTP struct {
+			Version string `json:"version"`
+		} `json:"http"`
+	} `json:"fingerprint"`
+}
+
+// reportServerFingerprint fetches /v1/debug once and prints the TLS
+// fingerprint the server observed, so emulated hellos can be verified
+// end-to-end. Servers without the debug endpoint are skipped silently.
+func reportServerFingerprint(client *http.Client, target string, p profile) {
+	req, err := newRequest(http.MethodGet, target, "", p)
+	if err != nil {
+		return
+	}
+	req.URL.Path = "/v1/debug"
+	req.URL.RawQuery = ""
+
+	resp, err := client.Do(req)
+	if err != nil {
+		fmt.Fprintf(console, "Server fingerprint: unavailable (%v)\n\n", err)
+		return
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	var dr debugResponse
+	if resp.StatusCode != http.StatusOK || json.NewDecoder(resp.Body).Decode(&dr) != nil {
+		return
+	}
+
+	tlsInfo := dr.Fingerprint.TLS
+	fmt.Fprintf(console, "Server fingerprint (%s, %s):\n", dr.Fingerprint.HTTP.Version, dr.Classification)
+	if tlsInfo.Available {
+		fmt.Fprintf(console, "  TLS:  %s %s\n", tlsInfo.Version, tlsInfo.ALPN)
+		fmt.Fprintf(console, "  JA3:  %s\n", tlsInfo.JA3Hash)
+		fmt.Fprintf(console, "  JA4:  %s\n", tlsInfo.JA4Hash)
+	} else {
+		fmt.Fprintln(console, "  TLS:  not available (plain HTTP)")
+	}
+	fmt.Fprintln(console)
+}